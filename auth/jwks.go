@@ -0,0 +1,238 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"DemoServer_ConnectionManager/helper"
+)
+
+// minRefetchInterval limits how often key set is fetched again because of token signed with unknown key.
+const minRefetchInterval = 30 * time.Second
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+type jwkSet struct {
+	Keys []jwk `json:"keys"`
+}
+
+// KeySet holds public keys of JSON Web Key Set loaded from local file or URL. Keys are loaded again once
+// refresh interval has passed or token is signed with key which is not known yet, so that keys rotated by
+// identity provider are picked up.
+type KeySet struct {
+	source  string
+	refresh time.Duration
+	hc      *http.Client
+
+	mu        sync.RWMutex
+	keys      map[string]crypto.PublicKey
+	fetchedAt time.Time
+}
+
+// NewKeySet loads key set from source, which is either http(s) URL or path of local file.
+func NewKeySet(source string, refresh time.Duration, hc *http.Client) (*KeySet, error) {
+	if source == "" {
+		return nil, fmt.Errorf("%w: jwks not configured", helper.ErrJWKSLoadFailed)
+	}
+
+	ks := &KeySet{source: source, refresh: refresh, hc: hc}
+
+	if err := ks.load(context.Background()); err != nil {
+		return nil, err
+	}
+
+	return ks, nil
+}
+
+// Key returns public key with kid. Only key of key set is returned if kid is empty.
+func (ks *KeySet) Key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	ks.mu.RLock()
+	key, found := ks.lookup(kid)
+	stale := ks.refresh > 0 && time.Since(ks.fetchedAt) > ks.refresh
+	refetch := !found && time.Since(ks.fetchedAt) > minRefetchInterval
+	ks.mu.RUnlock()
+
+	if stale || refetch {
+		// Keys loaded before stay in use if identity provider can not be reached
+		if err := ks.load(ctx); err != nil && !found {
+			return nil, err
+		}
+
+		ks.mu.RLock()
+		key, found = ks.lookup(kid)
+		ks.mu.RUnlock()
+	}
+
+	if !found {
+		return nil, fmt.Errorf("%w: unknown key %s", helper.ErrInvalidToken, kid)
+	}
+
+	return key, nil
+}
+
+// lookup returns key with kid. Caller must hold mu.
+func (ks *KeySet) lookup(kid string) (crypto.PublicKey, bool) {
+	if kid == "" && len(ks.keys) == 1 {
+		for _, key := range ks.keys {
+			return key, true
+		}
+	}
+
+	key, found := ks.keys[kid]
+	return key, found
+}
+
+// load fetches key set from source and replaces keys held.
+func (ks *KeySet) load(ctx context.Context) error {
+	b, err := ks.read(ctx)
+	if err != nil {
+		return fmt.Errorf("%w: %s", helper.ErrJWKSLoadFailed, err.Error())
+	}
+
+	var set jwkSet
+
+	if err := json.Unmarshal(b, &set); err != nil {
+		return fmt.Errorf("%w: %s", helper.ErrJWKSLoadFailed, err.Error())
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+
+	for _, k := range set.Keys {
+		// Keys meant for encryption can not verify signatures
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+
+		key, err := k.publicKey()
+		if err != nil {
+			return fmt.Errorf("%w: key %s: %s", helper.ErrJWKSLoadFailed, k.Kid, err.Error())
+		}
+
+		keys[k.Kid] = key
+	}
+
+	if len(keys) == 0 {
+		return fmt.Errorf("%w: no signing keys found", helper.ErrJWKSLoadFailed)
+	}
+
+	ks.mu.Lock()
+	ks.keys = keys
+	ks.fetchedAt = time.Now()
+	ks.mu.Unlock()
+
+	return nil
+}
+
+func (ks *KeySet) read(ctx context.Context) ([]byte, error) {
+	if !strings.HasPrefix(ks.source, "http://") && !strings.HasPrefix(ks.source, "https://") {
+		return os.ReadFile(ks.source)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", ks.source, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := ks.hc.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	return io.ReadAll(resp.Body)
+}
+
+// publicKey converts RSA or EC JSON Web Key to public key.
+func (k jwk) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() {
+			return nil, fmt.Errorf("invalid exponent")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		var ecdhCurve ecdh.Curve
+		switch k.Crv {
+		case "P-256":
+			curve, ecdhCurve = elliptic.P256(), ecdh.P256()
+		case "P-384":
+			curve, ecdhCurve = elliptic.P384(), ecdh.P384()
+		case "P-521":
+			curve, ecdhCurve = elliptic.P521(), ecdh.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %s", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+
+		// Point is validated by parsing it in uncompressed form
+		size := (curve.Params().BitSize + 7) / 8
+		if len(x.Bytes()) > size || len(y.Bytes()) > size {
+			return nil, fmt.Errorf("invalid point for curve %s", k.Crv)
+		}
+		point := make([]byte, 1+2*size)
+		point[0] = 4
+		x.FillBytes(point[1 : 1+size])
+		y.FillBytes(point[1+size:])
+		if _, err := ecdhCurve.NewPublicKey(point); err != nil {
+			return nil, fmt.Errorf("invalid point for curve %s: %s", k.Crv, err.Error())
+		}
+
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %s", k.Kty)
+	}
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		return nil, fmt.Errorf("empty value")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
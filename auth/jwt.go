@@ -0,0 +1,299 @@
+package auth
+
+import (
+	"DemoServer_ConnectionManager/configuration"
+	"DemoServer_ConnectionManager/helper"
+	"DemoServer_ConnectionManager/utilities"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// exemptPaths are served without bearer token so that probes and API documentation keep working.
+var exemptPaths = map[string]bool{
+	"/v1/connectionmgmt/status": true,
+	"/docs":                     true,
+	"/swagger.yaml":             true,
+}
+
+// exemptPathPatterns are served without bearer token as they only expose public material, such as public keys of
+// CAs or revocation status of certificates, which has to be retrievable by hosts and TLS clients that hold no token.
+var exemptPathPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`^/v1/connectionmgmt/connection/ssh/[a-fA-F0-9-]{36}/public_key$`),
+	regexp.MustCompile(`^/v1/connectionmgmt/connection/pki/[a-fA-F0-9-]{36}/(ca|crl|ocsp)$`),
+}
+
+// isExempt tells whether path is served without bearer token.
+func isExempt(path string) bool {
+	if exemptPaths[path] {
+		return true
+	}
+	for _, pattern := range exemptPathPatterns {
+		if pattern.MatchString(path) {
+			return true
+		}
+	}
+	return false
+}
+
+type header struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	Typ string `json:"typ"`
+}
+
+// Authenticator verifies bearer JWTs against configured key set and puts principal of caller in request context.
+type Authenticator struct {
+	l      *slog.Logger
+	cfg    *configuration.Config
+	keys   *KeySet
+	leeway time.Duration
+	now    func() time.Time
+}
+
+func NewAuthenticator(cfg *configuration.Config, l *slog.Logger) (*Authenticator, error) {
+	var a Authenticator
+
+	hc := &http.Client{Timeout: 10 * time.Second}
+
+	keys, err := NewKeySet(cfg.Auth.JWKS, time.Duration(cfg.Auth.JWKSRefresh)*time.Second, hc)
+	if err != nil {
+		return nil, err
+	}
+
+	a.cfg = cfg
+	a.l = l
+	a.keys = keys
+	a.leeway = time.Duration(cfg.Auth.ClockSkewLeeway) * time.Second
+	a.now = time.Now
+
+	return &a, nil
+}
+
+// Middleware rejects requests without valid bearer token with 401 status. Principal of caller is available to
+// handlers through helper.PrincipalFromContext.
+func (a *Authenticator) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+
+		if isExempt(r.URL.Path) {
+			next.ServeHTTP(rw, r)
+			return
+		}
+
+		ctx, span, requestid, cl := utilities.SetupTraceAndLogger(r, rw, a.l, utilities.GetFunctionName(), a.cfg.Server.PrefixMain)
+		defer span.End()
+
+		token, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !found || strings.TrimSpace(token) == "" {
+			rw.Header().Set("WWW-Authenticate", `Bearer realm="connectionmgmt"`)
+			helper.ReturnError(cl, http.StatusUnauthorized, helper.ErrorAuthenticationRequired, helper.ErrAuthenticationRequired, requestid, r, &rw, span)
+			return
+		}
+
+		p, err := a.Authenticate(ctx, strings.TrimSpace(token))
+		if err != nil {
+			rw.Header().Set("WWW-Authenticate", `Bearer realm="connectionmgmt", error="invalid_token"`)
+			helper.ReturnError(cl, http.StatusUnauthorized, helper.ErrorInvalidToken, err, requestid, r, &rw, span)
+			return
+		}
+
+		// Call the next handler, which can be another middleware in the chain, or the final handler.
+		next.ServeHTTP(rw, helper.WithPrincipal(r, p))
+	})
+}
+
+// Authenticate verifies signature and claims of token and returns principal it was issued to.
+func (a *Authenticator) Authenticate(ctx context.Context, token string) (*helper.Principal, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: malformed token", helper.ErrInvalidToken)
+	}
+
+	var h header
+	if err := decodeSegment(parts[0], &h); err != nil {
+		return nil, fmt.Errorf("%w: malformed header: %s", helper.ErrInvalidToken, err.Error())
+	}
+
+	key, err := a.keys.Key(ctx, h.Kid)
+	if err != nil {
+		return nil, err
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed signature", helper.ErrInvalidToken)
+	}
+
+	if err := verify(h.Alg, key, []byte(parts[0]+"."+parts[1]), sig); err != nil {
+		return nil, fmt.Errorf("%w: %s", helper.ErrInvalidToken, err.Error())
+	}
+
+	var claims map[string]interface{}
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("%w: malformed claims: %s", helper.ErrInvalidToken, err.Error())
+	}
+
+	if err := a.validateClaims(claims); err != nil {
+		return nil, fmt.Errorf("%w: %s", helper.ErrInvalidToken, err.Error())
+	}
+
+	var p helper.Principal
+	p.Subject, _ = claims["sub"].(string)
+	p.Issuer, _ = claims["iss"].(string)
+	p.Scopes = scopes(claims)
+	p.Claims = claims
+
+	return &p, nil
+}
+
+// validateClaims checks expiry, not before, issuer and audience of token. Issuer and audience are only checked
+// when configured.
+func (a *Authenticator) validateClaims(claims map[string]interface{}) error {
+	now := a.now()
+
+	exp, ok := numericDate(claims["exp"])
+	if !ok {
+		return fmt.Errorf("exp claim missing")
+	}
+	if now.After(exp.Add(a.leeway)) {
+		return fmt.Errorf("token expired at %s", exp.UTC().Format(time.RFC3339))
+	}
+
+	if nbf, ok := numericDate(claims["nbf"]); ok && now.Add(a.leeway).Before(nbf) {
+		return fmt.Errorf("token not valid before %s", nbf.UTC().Format(time.RFC3339))
+	}
+
+	if sub, _ := claims["sub"].(string); sub == "" {
+		return fmt.Errorf("sub claim missing")
+	}
+
+	if a.cfg.Auth.Issuer != "" {
+		if iss, _ := claims["iss"].(string); iss != a.cfg.Auth.Issuer {
+			return fmt.Errorf("unexpected issuer %s", iss)
+		}
+	}
+
+	if a.cfg.Auth.Audience != "" && !hasAudience(claims["aud"], a.cfg.Auth.Audience) {
+		return fmt.Errorf("token not issued for audience %s", a.cfg.Auth.Audience)
+	}
+
+	return nil
+}
+
+// verify checks signature of signing input with key. Only asymmetric algorithms are accepted so that tokens
+// can not be forged with public key used as HMAC secret or without signature at all.
+func verify(alg string, key crypto.PublicKey, input []byte, sig []byte) error {
+	var hash crypto.Hash
+
+	switch alg[min(len(alg), 2):] {
+	case "256":
+		hash = crypto.SHA256
+	case "384":
+		hash = crypto.SHA384
+	case "512":
+		hash = crypto.SHA512
+	default:
+		return fmt.Errorf("unsupported algorithm %s", alg)
+	}
+
+	h := hash.New()
+	h.Write(input)
+	digest := h.Sum(nil)
+
+	switch alg[:2] {
+	case "RS":
+		k, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("algorithm %s does not match key", alg)
+		}
+		return rsa.VerifyPKCS1v15(k, hash, digest, sig)
+	case "PS":
+		k, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("algorithm %s does not match key", alg)
+		}
+		return rsa.VerifyPSS(k, hash, digest, sig, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+	case "ES":
+		k, ok := key.(*ecdsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("algorithm %s does not match key", alg)
+		}
+		// Signature is r and s concatenated, each padded to size of curve
+		size := (k.Curve.Params().BitSize + 7) / 8
+		if len(sig) != 2*size {
+			return fmt.Errorf("invalid signature length")
+		}
+		r := new(big.Int).SetBytes(sig[:size])
+		s := new(big.Int).SetBytes(sig[size:])
+		if !ecdsa.Verify(k, digest, r, s) {
+			return fmt.Errorf("signature verification failed")
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported algorithm %s", alg)
+	}
+}
+
+func decodeSegment(seg string, v interface{}) error {
+	b, err := base64.RawURLEncoding.DecodeString(seg)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+func numericDate(v interface{}) (time.Time, bool) {
+	f, ok := v.(float64)
+	if !ok {
+		return time.Time{}, false
+	}
+	return time.Unix(int64(f), 0), true
+}
+
+// hasAudience tells whether aud claim, which is either single string or array of strings, contains audience.
+func hasAudience(aud interface{}, audience string) bool {
+	switch v := aud.(type) {
+	case string:
+		return v == audience
+	case []interface{}:
+		for _, a := range v {
+			if s, _ := a.(string); s == audience {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// scopes returns scopes granted through space separated scope claim or scp claim, which is either string or
+// array of strings.
+func scopes(claims map[string]interface{}) []string {
+	if s, ok := claims["scope"].(string); ok {
+		return strings.Fields(s)
+	}
+
+	switch v := claims["scp"].(type) {
+	case string:
+		return strings.Fields(v)
+	case []interface{}:
+		var result []string
+		for _, a := range v {
+			if s, ok := a.(string); ok {
+				result = append(result, s)
+			}
+		}
+		return result
+	}
+
+	return nil
+}
@@ -0,0 +1,206 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"DemoServer_ConnectionManager/configuration"
+	"DemoServer_ConnectionManager/helper"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testAudience = "demoserver-connectionmanager"
+
+func b64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// newTestAuthenticator writes JWKS with one RSA and one EC key to temporary file and returns authenticator
+// using it along with private keys.
+func newTestAuthenticator(t *testing.T) (*Authenticator, *rsa.PrivateKey, *ecdsa.PrivateKey) {
+	rk, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	ek, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	set := map[string]interface{}{
+		"keys": []map[string]string{
+			{"kty": "RSA", "kid": "rsa", "use": "sig", "n": b64(rk.N.Bytes()), "e": b64(big.NewInt(int64(rk.E)).Bytes())},
+			{"kty": "EC", "kid": "ec", "crv": "P-256", "x": b64(ek.X.FillBytes(make([]byte, 32))), "y": b64(ek.Y.FillBytes(make([]byte, 32)))},
+		},
+	}
+
+	b, err := json.Marshal(set)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(path, b, 0600))
+
+	var cfg configuration.Config
+	cfg.Auth.JWKS = path
+	cfg.Auth.Issuer = "https://idp.example.com"
+	cfg.Auth.Audience = testAudience
+	cfg.Auth.ClockSkewLeeway = 30
+
+	a, err := NewAuthenticator(&cfg, slog.New(slog.NewTextHandler(os.Stderr, nil)))
+	require.NoError(t, err)
+
+	return a, rk, ek
+}
+
+func testClaims() map[string]interface{} {
+	return map[string]interface{}{
+		"sub":   "user-1",
+		"iss":   "https://idp.example.com",
+		"aud":   []string{"other", testAudience},
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"scope": "connectionmgmt:read connectionmgmt:reveal",
+	}
+}
+
+func sign(t *testing.T, alg string, kid string, key crypto.Signer, claims map[string]interface{}) string {
+	h, err := json.Marshal(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"})
+	require.NoError(t, err)
+	c, err := json.Marshal(claims)
+	require.NoError(t, err)
+
+	input := b64(h) + "." + b64(c)
+	if key == nil {
+		return input + "."
+	}
+
+	digest := crypto.SHA256.New()
+	digest.Write([]byte(input))
+	sum := digest.Sum(nil)
+
+	var sig []byte
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		sig, err = rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, sum)
+		require.NoError(t, err)
+	case *ecdsa.PrivateKey:
+		r, s, err := ecdsa.Sign(rand.Reader, k, sum)
+		require.NoError(t, err)
+		sig = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	}
+
+	return input + "." + b64(sig)
+}
+
+func TestAuthenticator_ValidTokens(t *testing.T) {
+	a, rk, ek := newTestAuthenticator(t)
+
+	p, err := a.Authenticate(context.Background(), sign(t, "RS256", "rsa", rk, testClaims()))
+	require.NoError(t, err)
+	require.Equal(t, "user-1", p.Subject)
+	require.True(t, p.HasScope("connectionmgmt:reveal"))
+	require.False(t, p.HasScope("connectionmgmt:write"))
+
+	p, err = a.Authenticate(context.Background(), sign(t, "ES256", "ec", ek, testClaims()))
+	require.NoError(t, err)
+	require.Equal(t, "https://idp.example.com", p.Issuer)
+}
+
+func TestAuthenticator_RejectedTokens(t *testing.T) {
+	a, rk, ek := newTestAuthenticator(t)
+
+	expired := testClaims()
+	expired["exp"] = time.Now().Add(-time.Hour).Unix()
+
+	wrongAudience := testClaims()
+	wrongAudience["aud"] = "other"
+
+	wrongIssuer := testClaims()
+	wrongIssuer["iss"] = "https://evil.example.com"
+
+	notYetValid := testClaims()
+	notYetValid["nbf"] = time.Now().Add(time.Hour).Unix()
+
+	tests := map[string]string{
+		"expired":        sign(t, "RS256", "rsa", rk, expired),
+		"wrong audience": sign(t, "RS256", "rsa", rk, wrongAudience),
+		"wrong issuer":   sign(t, "RS256", "rsa", rk, wrongIssuer),
+		"not yet valid":  sign(t, "RS256", "rsa", rk, notYetValid),
+		"unknown kid":    sign(t, "RS256", "missing", rk, testClaims()),
+		"alg none":       sign(t, "none", "rsa", nil, testClaims()),
+		"alg mismatch":   sign(t, "RS256", "ec", ek, testClaims()),
+		"malformed":      "not-a-token",
+	}
+
+	for name, token := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := a.Authenticate(context.Background(), token)
+			require.True(t, errors.Is(err, helper.ErrInvalidToken), err)
+		})
+	}
+}
+
+func TestAuthenticator_Middleware(t *testing.T) {
+	a, rk, _ := newTestAuthenticator(t)
+
+	var subject string
+	h := a.Middleware(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if p, ok := helper.PrincipalFromContext(r.Context()); ok {
+			subject = p.Subject
+		}
+	}))
+
+	var e helper.ErrorResponse
+
+	rw := httptest.NewRecorder()
+	h.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/v1/connectionmgmt/connections", nil))
+	require.NotEmpty(t, rw.Header().Get("WWW-Authenticate"))
+	require.NoError(t, json.NewDecoder(rw.Body).Decode(&e))
+	require.Equal(t, http.StatusUnauthorized, e.Status)
+	require.Equal(t, helper.ErrorDictionary[helper.ErrorAuthenticationRequired].Code, e.ErrorCode)
+
+	rw = httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/v1/connectionmgmt/connections", nil)
+	r.Header.Set("Authorization", "Bearer "+sign(t, "RS256", "rsa", rk, testClaims()))
+	h.ServeHTTP(rw, r)
+	require.Equal(t, http.StatusOK, rw.Code)
+	require.Equal(t, "user-1", subject)
+
+	rw = httptest.NewRecorder()
+	h.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/v1/connectionmgmt/status", nil))
+	require.Equal(t, http.StatusOK, rw.Code)
+
+	// Public key of SSH CA is served without token, but signing is not
+	rw = httptest.NewRecorder()
+	h.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/v1/connectionmgmt/connection/ssh/6f1b9c1e-2d3a-4b5c-8d7e-9f0a1b2c3d4e/public_key", nil))
+	require.Equal(t, http.StatusOK, rw.Code)
+
+	rw = httptest.NewRecorder()
+	h.ServeHTTP(rw, httptest.NewRequest(http.MethodPost, "/v1/connectionmgmt/connection/ssh/6f1b9c1e-2d3a-4b5c-8d7e-9f0a1b2c3d4e/sign", nil))
+	require.NoError(t, json.NewDecoder(rw.Body).Decode(&e))
+	require.Equal(t, http.StatusUnauthorized, e.Status)
+
+	// CA certificate, CRL and OCSP responder of PKI connection are served without token, but issuance is not
+	for _, path := range []string{"ca", "crl", "ocsp"} {
+		rw = httptest.NewRecorder()
+		h.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/v1/connectionmgmt/connection/pki/6f1b9c1e-2d3a-4b5c-8d7e-9f0a1b2c3d4e/"+path, nil))
+		require.Equal(t, http.StatusOK, rw.Code)
+	}
+
+	rw = httptest.NewRecorder()
+	h.ServeHTTP(rw, httptest.NewRequest(http.MethodPost, "/v1/connectionmgmt/connection/pki/6f1b9c1e-2d3a-4b5c-8d7e-9f0a1b2c3d4e/issue", nil))
+	require.NoError(t, json.NewDecoder(rw.Body).Decode(&e))
+	require.Equal(t, http.StatusUnauthorized, e.Status)
+}
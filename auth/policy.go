@@ -0,0 +1,140 @@
+package auth
+
+import (
+	"DemoServer_ConnectionManager/configuration"
+	"DemoServer_ConnectionManager/data"
+	"DemoServer_ConnectionManager/datalayer"
+	"DemoServer_ConnectionManager/helper"
+	"DemoServer_ConnectionManager/utilities"
+	"context"
+	"log/slog"
+	"slices"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+)
+
+// Permission represents operation on connections which is granted through roles.
+type Permission string
+
+const (
+	PermissionView           Permission = "view"
+	PermissionTest           Permission = "test"
+	PermissionLink           Permission = "link"
+	PermissionCreate         Permission = "create"
+	PermissionUpdate         Permission = "update"
+	PermissionDelete         Permission = "delete"
+	PermissionIssueCreds     Permission = "issue_creds"
+	PermissionManageLeases   Permission = "manage_leases"
+	PermissionManageBindings Permission = "manage_bindings"
+)
+
+// rolePermissions lists permissions granted by each role.
+var rolePermissions = map[string][]Permission{
+	data.RoleViewer:             {PermissionView},
+	data.RoleOperator:           {PermissionView, PermissionTest, PermissionLink},
+	data.RoleAdmin:              {PermissionView, PermissionTest, PermissionLink, PermissionCreate, PermissionUpdate, PermissionDelete, PermissionManageLeases, PermissionManageBindings},
+	data.RoleCredentialConsumer: {PermissionIssueCreds},
+}
+
+// RoleGrants tells whether role grants permission.
+func RoleGrants(role string, p Permission) bool {
+	return slices.Contains(rolePermissions[role], p)
+}
+
+// PolicyEngine decides whether subject holds permission on connection based on role bindings stored in datastore.
+// Subjects listed in Authorization.Admins hold admin role globally without binding so that bindings can be managed
+// on fresh installation. Every permission is granted if authorization is disabled.
+type PolicyEngine struct {
+	l   *slog.Logger
+	cfg *configuration.Config
+	pd  *datalayer.PostgresDataSource
+}
+
+// NewPolicyEngine returns error if authorization is enabled without authentication, as role bindings would then be
+// matched against identity claimed by caller.
+func NewPolicyEngine(cfg *configuration.Config, l *slog.Logger, pd *datalayer.PostgresDataSource) (*PolicyEngine, error) {
+	if cfg.Authorization.Enabled && !cfg.Auth.Enabled {
+		return nil, helper.ErrAuthorizationRequiresAuthentication
+	}
+
+	var pe PolicyEngine
+
+	pe.cfg = cfg
+	pe.l = l
+	pe.pd = pd
+
+	return &pe, nil
+}
+
+// Enabled tells whether permissions are enforced.
+func (pe *PolicyEngine) Enabled() bool {
+	return pe.cfg.Authorization.Enabled
+}
+
+// Authorize tells whether subject holds permission on connection through binding scoped globally or to connection.
+// Only global bindings are considered if connectionID is uuid.Nil.
+func (pe *PolicyEngine) Authorize(ctx context.Context, subject string, p Permission, connectionID uuid.UUID) (bool, error) {
+	global, connectionIDs, err := pe.Scope(ctx, subject, p)
+	if err != nil {
+		return false, err
+	}
+
+	return covers(global, connectionIDs, connectionID), nil
+}
+
+// covers tells whether scope returned by Scope includes connection. Only global scope includes uuid.Nil.
+func covers(global bool, connectionIDs []uuid.UUID, connectionID uuid.UUID) bool {
+	return global || (connectionID != uuid.Nil && slices.Contains(connectionIDs, connectionID))
+}
+
+// Scope returns whether subject holds permission on all connections and, if not, IDs of connections it holds
+// permission on. It is used to filter lists of connections.
+func (pe *PolicyEngine) Scope(ctx context.Context, subject string, p Permission) (bool, []uuid.UUID, error) {
+	if !pe.Enabled() {
+		return true, nil, nil
+	}
+
+	if subject == "" {
+		return false, nil, nil
+	}
+
+	if slices.Contains(pe.cfg.Authorization.Admins, subject) && RoleGrants(data.RoleAdmin, p) {
+		return true, nil, nil
+	}
+
+	tr := otel.Tracer(pe.cfg.Server.PrefixMain)
+	_, span := tr.Start(ctx, utilities.GetFunctionName())
+	defer span.End()
+
+	var bindings []data.RoleBinding
+
+	result := pe.pd.RODB().Where("subject = ?", subject).Find(&bindings)
+	if result.Error != nil {
+		return false, nil, result.Error
+	}
+
+	global, connectionIDs := bindingsScope(bindings, p)
+
+	return global, connectionIDs, nil
+}
+
+// bindingsScope returns whether bindings grant permission on all connections and, if not, IDs of connections they
+// grant permission on.
+func bindingsScope(bindings []data.RoleBinding, p Permission) (bool, []uuid.UUID) {
+	var connectionIDs []uuid.UUID
+
+	for _, b := range bindings {
+		if !RoleGrants(b.Role, p) {
+			continue
+		}
+
+		if b.IsGlobal() {
+			return true, nil
+		}
+
+		connectionIDs = append(connectionIDs, *b.ConnectionID)
+	}
+
+	return false, connectionIDs
+}
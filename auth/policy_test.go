@@ -0,0 +1,121 @@
+package auth
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"testing"
+
+	"DemoServer_ConnectionManager/configuration"
+	"DemoServer_ConnectionManager/data"
+	"DemoServer_ConnectionManager/helper"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPolicy_RoleGrants(t *testing.T) {
+	require.True(t, RoleGrants(data.RoleViewer, PermissionView))
+	require.False(t, RoleGrants(data.RoleViewer, PermissionTest))
+
+	require.True(t, RoleGrants(data.RoleOperator, PermissionTest))
+	require.True(t, RoleGrants(data.RoleOperator, PermissionLink))
+	require.False(t, RoleGrants(data.RoleOperator, PermissionUpdate))
+
+	require.True(t, RoleGrants(data.RoleAdmin, PermissionCreate))
+	require.True(t, RoleGrants(data.RoleAdmin, PermissionManageBindings))
+	require.False(t, RoleGrants(data.RoleAdmin, PermissionIssueCreds))
+
+	require.True(t, RoleGrants(data.RoleAdmin, PermissionManageLeases))
+
+	require.True(t, RoleGrants(data.RoleCredentialConsumer, PermissionIssueCreds))
+	require.False(t, RoleGrants(data.RoleCredentialConsumer, PermissionView))
+	// Consumer must not revoke leases and certificates issued to other applications
+	require.False(t, RoleGrants(data.RoleCredentialConsumer, PermissionManageLeases))
+
+	require.False(t, RoleGrants("unknown", PermissionView))
+}
+
+func TestPolicy_NewPolicyEngineRequiresAuthentication(t *testing.T) {
+	var cfg configuration.Config
+	cfg.Authorization.Enabled = true
+
+	_, err := NewPolicyEngine(&cfg, slog.New(slog.NewTextHandler(os.Stderr, nil)), nil)
+	require.ErrorIs(t, err, helper.ErrAuthorizationRequiresAuthentication)
+
+	cfg.Auth.Enabled = true
+
+	_, err = NewPolicyEngine(&cfg, slog.New(slog.NewTextHandler(os.Stderr, nil)), nil)
+	require.NoError(t, err)
+}
+
+// Cases below are decided without consulting role bindings so no datastore is needed.
+func TestPolicy_AuthorizeWithoutBindings(t *testing.T) {
+	var cfg configuration.Config
+	pe, err := NewPolicyEngine(&cfg, slog.New(slog.NewTextHandler(os.Stderr, nil)), nil)
+	require.NoError(t, err)
+
+	// Every permission is granted while authorization is disabled
+	allowed, err := pe.Authorize(context.Background(), "", PermissionDelete, uuid.New())
+	require.NoError(t, err)
+	require.True(t, allowed)
+
+	cfg.Authorization.Enabled = true
+	cfg.Authorization.Admins = []string{"bootstrap-admin"}
+
+	// Callers without identity are denied
+	allowed, err = pe.Authorize(context.Background(), "", PermissionView, uuid.Nil)
+	require.NoError(t, err)
+	require.False(t, allowed)
+
+	// Configured admins hold admin role globally
+	allowed, err = pe.Authorize(context.Background(), "bootstrap-admin", PermissionManageBindings, uuid.Nil)
+	require.NoError(t, err)
+	require.True(t, allowed)
+}
+
+func TestPolicy_BindingsScope(t *testing.T) {
+	connectionA := uuid.New()
+	connectionB := uuid.New()
+
+	authorized := func(bindings []data.RoleBinding, p Permission, connectionID uuid.UUID) bool {
+		global, connectionIDs := bindingsScope(bindings, p)
+		return covers(global, connectionIDs, connectionID)
+	}
+
+	// Binding scoped to connection allows permissions of its role on that connection only
+	scoped := []data.RoleBinding{*data.NewRoleBinding("alice", data.RoleOperator, &connectionA, "admin")}
+
+	require.True(t, authorized(scoped, PermissionTest, connectionA))
+	require.False(t, authorized(scoped, PermissionDelete, connectionA))
+	require.False(t, authorized(scoped, PermissionTest, connectionB))
+	require.False(t, authorized(scoped, PermissionView, uuid.Nil))
+
+	global, connectionIDs := bindingsScope(scoped, PermissionView)
+	require.False(t, global)
+	require.Equal(t, []uuid.UUID{connectionA}, connectionIDs)
+
+	// Global binding allows permissions of its role on every connection
+	unscoped := []data.RoleBinding{*data.NewRoleBinding("alice", data.RoleViewer, nil, "admin")}
+
+	require.True(t, authorized(unscoped, PermissionView, connectionA))
+	require.True(t, authorized(unscoped, PermissionView, connectionB))
+	require.True(t, authorized(unscoped, PermissionView, uuid.Nil))
+	require.False(t, authorized(unscoped, PermissionLink, connectionA))
+
+	// Roles of several bindings are combined per connection
+	mixed := []data.RoleBinding{
+		*data.NewRoleBinding("alice", data.RoleViewer, &connectionA, "admin"),
+		*data.NewRoleBinding("alice", data.RoleAdmin, &connectionB, "admin"),
+		*data.NewRoleBinding("alice", data.RoleCredentialConsumer, &connectionA, "admin"),
+	}
+
+	require.True(t, authorized(mixed, PermissionIssueCreds, connectionA))
+	require.False(t, authorized(mixed, PermissionManageLeases, connectionA))
+	require.True(t, authorized(mixed, PermissionManageLeases, connectionB))
+	require.False(t, authorized(mixed, PermissionIssueCreds, connectionB))
+	require.False(t, authorized(mixed, PermissionDelete, connectionA))
+
+	// Subject without bindings holds no permission
+	require.False(t, authorized(nil, PermissionView, connectionA))
+}
@@ -0,0 +1,114 @@
+// Package client contains helpers for consumers of Microservice endpoints.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"DemoServer_ConnectionManager/data"
+)
+
+// wrapCreationPath is creation path of wrapping tokens issued by connection manager.
+const wrapCreationPath = "sys/wrapping/wrap"
+
+var (
+	//ErrUnwrapFailed wrapping token could not be unwrapped
+	ErrUnwrapFailed = errors.New("failed to unwrap response")
+
+	//ErrUnexpectedCreationPath wrapping token was not created by connection manager
+	ErrUnexpectedCreationPath = errors.New("wrapping token has unexpected creation path")
+)
+
+type vaultWrapLookup struct {
+	Data struct {
+		CreationPath string `json:"creation_path"`
+	} `json:"data"`
+}
+
+type vaultUnwrapResponse struct {
+	Data data.CredsAWSConnectionResponse `json:"data"`
+}
+
+// UnwrapAWSCreds exchanges wrapping token returned by creds endpoint when wrap_ttl is requested for AWS
+// credentials. Token is looked up first to make sure it was created through sys/wrapping/wrap, so that a
+// substituted token wrapping another secret is not accepted. Token can only be unwrapped once.
+func UnwrapAWSCreds(ctx context.Context, hc *http.Client, vaultAddress string, w data.WrapInfo) (*data.CredsAWSConnectionResponse, error) {
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+
+	vaultAddress = strings.TrimSuffix(vaultAddress, "/")
+
+	body, err := post(ctx, hc, vaultAddress+"/v1/sys/wrapping/lookup", w.Namespace, "", map[string]string{"token": w.Token})
+	if err != nil {
+		return nil, err
+	}
+
+	var lookup vaultWrapLookup
+
+	if err := json.Unmarshal(body, &lookup); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnwrapFailed, err.Error())
+	}
+
+	if lookup.Data.CreationPath != wrapCreationPath {
+		return nil, fmt.Errorf("%w: %s", ErrUnexpectedCreationPath, lookup.Data.CreationPath)
+	}
+
+	body, err = post(ctx, hc, vaultAddress+"/v1/sys/wrapping/unwrap", w.Namespace, w.Token, map[string]string{})
+	if err != nil {
+		return nil, err
+	}
+
+	var unwrapped vaultUnwrapResponse
+
+	if err := json.Unmarshal(body, &unwrapped); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnwrapFailed, err.Error())
+	}
+
+	return &unwrapped.Data, nil
+}
+
+// post sends payload to Vault endpoint and returns response body. Token is only set if not empty.
+func post(ctx context.Context, hc *http.Client, url string, namespace string, token string, payload interface{}) ([]byte, error) {
+	payloadJSON, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(payloadJSON))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	if token != "" {
+		req.Header.Set("X-Vault-Token", token)
+	}
+
+	if namespace != "" {
+		req.Header.Set("X-Vault-Namespace", namespace)
+	}
+
+	resp, err := hc.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: %s", ErrUnwrapFailed, string(body))
+	}
+
+	return body, nil
+}
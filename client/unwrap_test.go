@@ -0,0 +1,73 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"DemoServer_ConnectionManager/data"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fakeVault serves single wrapping token created at creationPath in namespace bu1.
+func fakeVault(creationPath string) *httptest.Server {
+	unwrapped := false
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Namespace") != "bu1" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		switch r.URL.Path {
+		case "/v1/sys/wrapping/lookup":
+			var payload map[string]string
+			if err := json.NewDecoder(r.Body).Decode(&payload); err != nil || payload["token"] != "hvs.wrapping" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+
+			_, _ = fmt.Fprintf(w, `{"data":{"creation_path":"%s","creation_ttl":300}}`, creationPath)
+		case "/v1/sys/wrapping/unwrap":
+			if unwrapped || r.Header.Get("X-Vault-Token") != "hvs.wrapping" {
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = fmt.Fprint(w, `{"errors":["wrapping token is not valid or does not exist"]}`)
+				return
+			}
+			unwrapped = true
+
+			_, _ = fmt.Fprint(w, `{"data":{"connectionid":"c1","lease_id":"demoserver/aws_1/creds/default/abc","lease_duration":900,"renewable":true,"role_name":"default","latency":0,"data":{"access_key":"AKIAEXAMPLE","secret_key":"secret","session_token":""}}}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestUnwrapAWSCreds(t *testing.T) {
+	srv := fakeVault("sys/wrapping/wrap")
+	defer srv.Close()
+
+	w := data.WrapInfo{Token: "hvs.wrapping", Namespace: "bu1"}
+
+	creds, err := UnwrapAWSCreds(context.Background(), srv.Client(), srv.URL+"/", w)
+	require.NoError(t, err)
+	require.Equal(t, "demoserver/aws_1/creds/default/abc", creds.LeaseID)
+	require.Equal(t, "AKIAEXAMPLE", creds.Data.AccessKey)
+	require.Equal(t, "secret", creds.Data.SecretKey)
+
+	// Wrapping token is single-use
+	_, err = UnwrapAWSCreds(context.Background(), srv.Client(), srv.URL, w)
+	require.ErrorIs(t, err, ErrUnwrapFailed)
+}
+
+func TestUnwrapAWSCreds_UnexpectedCreationPath(t *testing.T) {
+	srv := fakeVault("demoserver/aws_1/creds/default")
+	defer srv.Close()
+
+	_, err := UnwrapAWSCreds(context.Background(), srv.Client(), srv.URL, data.WrapInfo{Token: "hvs.wrapping", Namespace: "bu1"})
+	require.ErrorIs(t, err, ErrUnexpectedCreationPath)
+}
@@ -51,8 +51,35 @@ type Config struct {
 		HTTPS         bool   `yaml:"https" env:"DEMOSERVER_CONNECTIONMANAGER_VAULT_HTTPS"`
 		TLSSkipVerify bool   `yaml:"tlsskipverify" env:"DEMOSERVER_CONNECTIONMANAGER_VAULT_TLSSKIPVERIFY"`
 		PathPrefix    string `yaml:"pathprefix" env:"DEMOSERVER_CONNECTIONMANAGER_VAULT_PATH_PREFIX"`
+		Namespace     string `yaml:"namespace" env:"DEMOSERVER_CONNECTIONMANAGER_VAULT_NAMESPACE"`
+
+		AuthMethod struct {
+			Type    string `yaml:"type" env:"DEMOSERVER_CONNECTIONMANAGER_VAULT_AUTH_METHOD_TYPE"`
+			Mount   string `yaml:"mount" env:"DEMOSERVER_CONNECTIONMANAGER_VAULT_AUTH_METHOD_MOUNT"`
+			Role    string `yaml:"role" env:"DEMOSERVER_CONNECTIONMANAGER_VAULT_AUTH_METHOD_ROLE"`
+			JWTPath string `yaml:"jwt_path" env:"DEMOSERVER_CONNECTIONMANAGER_VAULT_AUTH_METHOD_JWT_PATH"`
+		} `yaml:"auth_method"`
 	} `yaml:"vault"`
 
+	Auth struct {
+		Enabled          bool   `yaml:"enabled" env:"DEMOSERVER_CONNECTIONMANAGER_AUTH_ENABLED"`
+		JWKS             string `yaml:"jwks" env:"DEMOSERVER_CONNECTIONMANAGER_AUTH_JWKS"`
+		JWKSRefresh      int    `yaml:"jwks_refresh" env:"DEMOSERVER_CONNECTIONMANAGER_AUTH_JWKS_REFRESH"`
+		Issuer           string `yaml:"issuer" env:"DEMOSERVER_CONNECTIONMANAGER_AUTH_ISSUER"`
+		Audience         string `yaml:"audience" env:"DEMOSERVER_CONNECTIONMANAGER_AUTH_AUDIENCE"`
+		ClockSkewLeeway  int    `yaml:"clock_skew_leeway" env:"DEMOSERVER_CONNECTIONMANAGER_AUTH_CLOCK_SKEW_LEEWAY"`
+		ApplicationClaim string `yaml:"application_claim" env:"DEMOSERVER_CONNECTIONMANAGER_AUTH_APPLICATION_CLAIM"`
+	} `yaml:"auth"`
+
+	Authorization struct {
+		Enabled bool     `yaml:"enabled" env:"DEMOSERVER_CONNECTIONMANAGER_AUTHORIZATION_ENABLED"`
+		Admins  []string `yaml:"admins" env:"DEMOSERVER_CONNECTIONMANAGER_AUTHORIZATION_ADMINS" env-separator:","`
+	} `yaml:"authorization"`
+
+	SecretsBackend struct {
+		Type string `yaml:"type" env:"DEMOSERVER_CONNECTIONMANAGER_SECRETSBACKEND_TYPE"`
+	} `yaml:"secrets_backend"`
+
 	OTLP struct {
 		Host          string `yaml:"host" env:"DEMOSERVER_CONNECTIONMANAGER_OTLP_HOST"`
 		Port          int    `yaml:"port" env:"DEMOSERVER_CONNECTIONMANAGER_OTLP_PORT"`
@@ -71,6 +98,32 @@ type Config struct {
 		IAMUserLatency  int `yaml:"iam_user_latency" env:"DEMOSERVER_CONNECTIONMANAGER_AWS_IAMUSER_LATENCY"`
 		DefaultStsTTL   int `yaml:"default_sts_ttl" env:"DEMOSERVER_CONNECTIONMANAGER_AWS_STS_TTL"`
 	} `yaml:"aws"`
+
+	Reconciler struct {
+		Enabled bool `yaml:"enabled" env:"DEMOSERVER_CONNECTIONMANAGER_RECONCILER_ENABLED"`
+		Repair  bool `yaml:"repair" env:"DEMOSERVER_CONNECTIONMANAGER_RECONCILER_REPAIR"`
+	} `yaml:"reconciler"`
+
+	ConnectionTest struct {
+		Enabled     bool `yaml:"enabled" env:"DEMOSERVER_CONNECTIONMANAGER_CONNECTIONTEST_ENABLED"`
+		Interval    int  `yaml:"interval" env:"DEMOSERVER_CONNECTIONMANAGER_CONNECTIONTEST_INTERVAL"`
+		Concurrency int  `yaml:"concurrency" env:"DEMOSERVER_CONNECTIONMANAGER_CONNECTIONTEST_CONCURRENCY"`
+	} `yaml:"connection_test"`
+
+	RootRotation struct {
+		Enabled     bool `yaml:"enabled" env:"DEMOSERVER_CONNECTIONMANAGER_ROOTROTATION_ENABLED"`
+		Interval    int  `yaml:"interval" env:"DEMOSERVER_CONNECTIONMANAGER_ROOTROTATION_INTERVAL"`
+		Concurrency int  `yaml:"concurrency" env:"DEMOSERVER_CONNECTIONMANAGER_ROOTROTATION_CONCURRENCY"`
+	} `yaml:"root_rotation"`
+
+	LinkSweeper struct {
+		Enabled  bool `yaml:"enabled" env:"DEMOSERVER_CONNECTIONMANAGER_LINKSWEEPER_ENABLED"`
+		Interval int  `yaml:"interval" env:"DEMOSERVER_CONNECTIONMANAGER_LINKSWEEPER_INTERVAL"`
+	} `yaml:"link_sweeper"`
+
+	PKI struct {
+		BaseURL string `yaml:"base_url" env:"DEMOSERVER_CONNECTIONMANAGER_PKI_BASE_URL"`
+	} `yaml:"pki"`
 }
 
 // Args is the struct for pass .
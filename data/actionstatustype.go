@@ -31,6 +31,16 @@ var actionstatus_toID = map[string]ActionStatusTypeEnum{
 	strings.ToLower("Failed"):     Failed,
 }
 
+// ParseActionStatusType returns action status matching its string representation.
+func ParseActionStatusType(s string) (ActionStatusTypeEnum, error) {
+	id, found := actionstatus_toID[strings.ToLower(s)]
+	if !found {
+		return NoStatus, helper.ErrNotFound
+	}
+
+	return id, nil
+}
+
 // MarshalJSON marshals the enum as a quoted json string
 func (o ActionStatusTypeEnum) MarshalJSON() ([]byte, error) {
 	buffer := bytes.NewBufferString(`"`)
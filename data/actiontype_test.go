@@ -0,0 +1,18 @@
+package data
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestActionType_IDsUnique(t *testing.T) {
+	// Every action has own id and none of them reuses retired id of application management
+	require.Len(t, action_toID, len(action_toString))
+
+	for _, id := range retiredActions {
+		_, found := action_toString[id]
+		require.False(t, found, "action id %s is retired", id)
+		require.Equal(t, "", ActionTypeEnum(id).String())
+	}
+}
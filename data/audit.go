@@ -1,35 +1,67 @@
 package data
 
 import (
+	"DemoServer_ConnectionManager/helper"
 	"time"
 
 	"github.com/google/uuid"
 )
 
-// AuditRecord represents schema for Audit Trial kept by application manager
+// AuditRecord represents schema for Audit Trial kept by connection manager
 //
 // swagger:model
 type AuditRecord struct {
 	ID           uuid.UUID            `json:"id" gorm:"primaryKey"`
 	CreatedAt    time.Time            `json:"createdat" gorm:"autoCreateTime;index;not null"`
-	RequestID    uuid.UUID            `json:"request_id" gorm:"index;not null"`
+	RequestID    string               `json:"request_id" gorm:"index;not null"`
 	ConnectionID uuid.UUID            `json:"connection_id" gorm:"index"`
-	Action       uuid.UUID            `json:"action" gorm:"not null;index"`
-	UserID       uuid.UUID            `json:"userid" validate:"required" gorm:"index;not null"`
+	Action       ActionTypeEnum       `json:"action" gorm:"type:uuid;not null;index"`
+	UserID       string               `json:"userid" gorm:"index"`
 	Status       ActionStatusTypeEnum `json:"status" validate:"required" gorm:"index;not null"`
-	Details      string               `json:"details" validate:"required"`
+	Details      string               `json:"details"`
 }
 
-// AuditRecordWrapper represents schema for response of command executed by application manager including output
+// AuditRecordsResponse represents AuditRecord resources which are returned in response of GET on audit endpoint.
 //
 // swagger:model
-type AuditRecordWrapper struct {
-	ID           uuid.UUID            `json:"id" gorm:"primaryKey"`
-	CreatedAt    time.Time            `json:"createdat" gorm:"autoCreateTime;index;not null"`
-	RequestID    uuid.UUID            `json:"request_id" gorm:"index;not null"`
-	ConnectionID uuid.UUID            `json:"connection_id" gorm:"index"`
-	Action       uuid.UUID            `json:"action" gorm:"not null;index"`
-	UserID       uuid.UUID            `json:"userid" validate:"required" gorm:"index;not null"`
-	Status       ActionStatusTypeEnum `json:"status" validate:"required" gorm:"index;not null"`
-	Details      string               `json:"details" validate:"required"`
+type AuditRecordsResponse struct {
+	// Number of skipped resources
+	// required: true
+	Skip int `json:"skip"`
+
+	// Limit applied on resources returned
+	// required: true
+	Limit int `json:"limit"`
+
+	// Total number of resources returned
+	// required: true
+	Total int `json:"total"`
+
+	// AuditRecord resource objects
+	// required: true
+	AuditRecords []AuditRecord `json:"auditrecords"`
+}
+
+func NewAuditRecord(requestID string, connectionID uuid.UUID, action uuid.UUID, userID string) *AuditRecord {
+	var a AuditRecord
+
+	a.ID = uuid.New()
+	a.RequestID = requestID
+	a.ConnectionID = connectionID
+	a.Action = ActionTypeEnum(action)
+	a.UserID = userID
+
+	return &a
+}
+
+// SetSuccessful marks audited action as successful. Access keys and secrets are masked in details.
+func (a *AuditRecord) SetSuccessful(details string) {
+	a.Status = Successful
+	a.Details = helper.Scrub(details)
+}
+
+// SetFailed marks audited action as failed. Access keys and secrets are masked in details.
+func (a *AuditRecord) SetFailed(details string) {
+	a.Status = Failed
+	a.Details = helper.Scrub(details)
 }
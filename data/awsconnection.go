@@ -6,12 +6,18 @@ import (
 	"encoding/json"
 	"io"
 	"net/http"
+	"regexp"
+	"strings"
 	"time"
 
 	"github.com/go-playground/validator"
 	"github.com/google/uuid"
 )
 
+// vaultPathPattern restricts Vault mount paths and namespaces to slash separated segments of characters
+// which are safe to use in Vault paths.
+var vaultPathPattern = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,64}(/[a-zA-Z0-9_-]{1,64})*$`)
+
 // AWSConnectionPostWrapper represents AWSConnection attributes for POST request body schema.
 // swagger:model
 type AWSConnectionPostWrapper struct {
@@ -43,11 +49,39 @@ type AWSConnectionPostWrapper struct {
 
 	// CredentialType CredentialType for AWS Account Role
 	// required: true
-	CredentialType string `json:"credential_type" validate:"required,oneof=iam_user session_token" gorm:"-"`
+	CredentialType string `json:"credential_type" validate:"required,oneof=iam_user session_token assumed_role federation_token" gorm:"-"`
 
 	// PolicyARNs PolicyARNs for AWS Account
 	// required: true
 	PolicyARNs []string `json:"policy_arns" gorm:"-"`
+
+	// PolicyDocument IAM policy document in JSON format for AWS Account Role
+	// required: only if credential_type is set to federation_token and policy_arns are not provided
+	PolicyDocument string `json:"policy_document" gorm:"-"`
+
+	// RoleARNs ARNs of AWS roles to be assumed
+	// required: only if credential_type is set to assumed_role
+	RoleARNs []string `json:"role_arns" gorm:"-"`
+
+	// SessionTags session tags to be set for assumed role session
+	// required: false. only allowed if credential_type is set to assumed_role
+	SessionTags map[string]string `json:"session_tags" gorm:"-"`
+
+	// ExternalID external id to be passed to assumed role
+	// required: false. only allowed if credential_type is set to assumed_role
+	ExternalID string `json:"external_id" gorm:"-"`
+
+	// VaultPath path of secrets engine mount for AWS Account. Has to be under path prefix configured for service
+	// required: false. generated from id of connection if not provided
+	VaultPath string `json:"vaultpath" gorm:"-"`
+
+	// VaultNamespace Vault namespace of secrets engine mount for AWS Account
+	// required: false. namespace configured for service is used if not provided
+	VaultNamespace string `json:"vault_namespace" gorm:"-"`
+
+	// RootRotationInterval seconds between scheduled rotations of root credentials for AWS Account. 0 disables scheduled rotation
+	// required: false
+	RootRotationInterval int `json:"root_rotation_interval" validate:"min=0" gorm:"-"`
 }
 
 // AWSConnectionPatchWrapper represents AWSConnection attributes for PATCH request body schema.
@@ -77,11 +111,31 @@ type AWSConnectionPatchWrapper struct {
 
 	// CredentialType CredentialType for AWS Account Role
 	// required: true
-	CredentialType *string `json:"credential_type,omitempty" validate:"omitempty,oneof=iam_user session_token" gorm:"-"`
+	CredentialType *string `json:"credential_type,omitempty" validate:"omitempty,oneof=iam_user session_token assumed_role federation_token" gorm:"-"`
 
 	// PolicyARNs PolicyARNs for AWS Account
 	// required: true
 	PolicyARNs []string `json:"policy_arns,omitempty" validate:"omitempty" gorm:"-"`
+
+	// PolicyDocument IAM policy document in JSON format for AWS Account Role
+	// required: false
+	PolicyDocument *string `json:"policy_document,omitempty" validate:"omitempty" gorm:"-"`
+
+	// RoleARNs ARNs of AWS roles to be assumed
+	// required: false
+	RoleARNs []string `json:"role_arns,omitempty" validate:"omitempty" gorm:"-"`
+
+	// SessionTags session tags to be set for assumed role session
+	// required: false
+	SessionTags map[string]string `json:"session_tags,omitempty" validate:"omitempty" gorm:"-"`
+
+	// ExternalID external id to be passed to assumed role
+	// required: false
+	ExternalID *string `json:"external_id,omitempty" validate:"omitempty" gorm:"-"`
+
+	// RootRotationInterval seconds between scheduled rotations of root credentials for AWS Account. 0 disables scheduled rotation
+	// required: false
+	RootRotationInterval *int `json:"root_rotation_interval,omitempty" validate:"omitempty,min=0" gorm:"-"`
 }
 
 // AWSConnection represents AWSConnection resource serialized by Microservice endpoints
@@ -95,15 +149,20 @@ type AWSConnection struct {
 
 	// VaultPath for AWS Account
 	// required: true
-	VaultPath string `json:"vaultpath" validate:"required" gorm:"not null"`
+	VaultPath string `json:"vaultpath" validate:"required" gorm:"not null;uniqueIndex:idx_aws_connections_vault_mount"`
 
-	// AccessKey for AWS Account
+	// VaultNamespace Vault namespace of secrets engine mount for AWS Account. Empty for root namespace
+	// or for connections created before namespaces were supported, which use namespace configured for service.
+	// required: false
+	VaultNamespace string `json:"vault_namespace" gorm:"not null;default:'';uniqueIndex:idx_aws_connections_vault_mount"`
+
+	// AccessKey for AWS Account. Masked in responses unless revealed
 	// required: true
-	AccessKey string `json:"accesskey" validate:"required" gorm:"-"`
+	AccessKey string `json:"accesskey" validate:"required" gorm:"-" redact:"true"`
 
-	// SecretAccessKey for AWS Account
+	// SecretAccessKey for AWS Account. Masked in responses
 	// required: true
-	SecretAccessKey string `json:"secretaccesskey" validate:"required" gorm:"-"`
+	SecretAccessKey string `json:"secretaccesskey" validate:"required" gorm:"-" redact:"true"`
 
 	// DefaultRegion for AWS Account
 	// required: false
@@ -117,17 +176,53 @@ type AWSConnection struct {
 	// required: false
 	MaxLeaseTTL string `json:"max_lease_ttl" gorm:"-"`
 
-	// RoleName RoleName for AWS Account
+	// RoleName name of default role for AWS Account
 	// required: true
-	RoleName string `json:"role_name" validate:"required" gorm:"-"`
+	RoleName string `json:"role_name" validate:"required"`
 
 	// CredentialType CredentialType for AWS Account Role
 	// required: true
-	CredentialType string `json:"credential_type" validate:"required,oneof=iam_user session_token" gorm:"-"`
+	CredentialType string `json:"credential_type" validate:"required,oneof=iam_user session_token assumed_role federation_token" gorm:"-"`
 
 	// PolicyARNs PolicyARNs for AWS Account
 	// required: only if credential_type is set to iam_user
 	PolicyARNs []string `json:"policy_arns" gorm:"-"`
+
+	// PolicyDocument IAM policy document in JSON format for AWS Account Role
+	// required: only if credential_type is set to federation_token and policy_arns are not provided
+	PolicyDocument string `json:"policy_document" gorm:"-"`
+
+	// RoleARNs ARNs of AWS roles to be assumed
+	// required: only if credential_type is set to assumed_role
+	RoleARNs []string `json:"role_arns" gorm:"-"`
+
+	// SessionTags session tags to be set for assumed role session
+	// required: false
+	SessionTags map[string]string `json:"session_tags" gorm:"-"`
+
+	// ExternalID external id to be passed to assumed role
+	// required: false
+	ExternalID string `json:"external_id" gorm:"-"`
+
+	// RootRotationInterval seconds between scheduled rotations of root credentials for AWS Account. 0 disables scheduled rotation
+	// required: false
+	RootRotationInterval int `json:"root_rotation_interval" validate:"min=0" gorm:"not null;default:0"`
+
+	// RootRotationSuccessful 1 = last rotation of root credentials successful. 0 = last rotation failed or root credentials not rotated yet
+	// required: false
+	RootRotationSuccessful int `json:"root_rotation_successful" gorm:"not null;default:0"`
+
+	// RootRotationError error of last rotation of root credentials
+	// required: false
+	RootRotationError string `json:"root_rotation_error"`
+
+	// RootRotatedOn time of last rotation of root credentials, successful or not
+	// required: false
+	RootRotatedOn time.Time `json:"root_rotated_on"`
+
+	// LastSuccessfulRootRotation time of last successful rotation of root credentials
+	// required: false
+	LastSuccessfulRootRotation time.Time `json:"last_successful_root_rotation" gorm:"index"`
 }
 
 // AWSConnectionResponseWrapper represents limited information AWSConnection resource returned by Post, Get and List endpoints
@@ -139,9 +234,17 @@ type AWSConnectionResponseWrapper struct {
 	ConnectionID uuid.UUID  `json:"connectionid" gorm:"not null;index"`
 	Connection   Connection `json:"connection" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
 
-	// AccessKey for AWS Account
+	// VaultPath path of secrets engine mount for AWS Account
 	// required: true
-	AccessKey string `json:"accesskey" validate:"required" gorm:"-"`
+	VaultPath string `json:"vaultpath" gorm:"-"`
+
+	// VaultNamespace Vault namespace of secrets engine mount for AWS Account
+	// required: false
+	VaultNamespace string `json:"vault_namespace" gorm:"-"`
+
+	// AccessKey for AWS Account. Masked i.e. AKIA****WXYZ unless revealed
+	// required: true
+	AccessKey string `json:"accesskey" validate:"required" gorm:"-" redact:"true"`
 
 	// DefaultRegion for AWS Account
 	// required: false
@@ -161,11 +264,47 @@ type AWSConnectionResponseWrapper struct {
 
 	// CredentialType CredentialType for AWS Account Role
 	// required: true
-	CredentialType string `json:"credential_type" validate:"required,oneof=iam_user" gorm:"-"`
+	CredentialType string `json:"credential_type" validate:"required,oneof=iam_user session_token assumed_role federation_token" gorm:"-"`
 
 	// PolicyARNs PolicyARNs for AWS Account
 	// required: true
 	PolicyARNs []string `json:"policy_arns" validate:"required" gorm:"-"`
+
+	// PolicyDocument IAM policy document in JSON format for AWS Account Role
+	// required: false
+	PolicyDocument string `json:"policy_document" gorm:"-"`
+
+	// RoleARNs ARNs of AWS roles to be assumed
+	// required: false
+	RoleARNs []string `json:"role_arns" gorm:"-"`
+
+	// SessionTags session tags to be set for assumed role session
+	// required: false
+	SessionTags map[string]string `json:"session_tags" gorm:"-"`
+
+	// ExternalID external id to be passed to assumed role
+	// required: false
+	ExternalID string `json:"external_id" gorm:"-"`
+
+	// RootRotationInterval seconds between scheduled rotations of root credentials for AWS Account. 0 disables scheduled rotation
+	// required: false
+	RootRotationInterval int `json:"root_rotation_interval" gorm:"-"`
+
+	// RootRotationSuccessful 1 = last rotation of root credentials successful. 0 = last rotation failed or root credentials not rotated yet
+	// required: false
+	RootRotationSuccessful int `json:"root_rotation_successful" gorm:"-"`
+
+	// RootRotationError error of last rotation of root credentials
+	// required: false
+	RootRotationError string `json:"root_rotation_error" gorm:"-"`
+
+	// RootRotatedOn time of last rotation of root credentials, successful or not
+	// required: false
+	RootRotatedOn time.Time `json:"root_rotated_on" gorm:"-"`
+
+	// LastSuccessfulRootRotation time of last successful rotation of root credentials
+	// required: false
+	LastSuccessfulRootRotation time.Time `json:"last_successful_root_rotation" gorm:"-"`
 }
 
 // DeleteAWSConnectionResponse represents Response schema for DELETE - DeleteAWSConnection
@@ -187,6 +326,10 @@ type TestAWSConnectionResponse struct {
 	// in: id
 	ID string `json:"id"`
 
+	// role_name of role which was tested.
+	// in: role_name
+	RoleName string `json:"role_name"`
+
 	// test status descriptive human readable message.
 	// in: test_status
 	TestStatus string `json:"testStatus"`
@@ -196,6 +339,22 @@ type TestAWSConnectionResponse struct {
 	TestStatusCode int `json:"testStatusCode"`
 }
 
+// RotateRootAWSConnectionResponse Response schema for POST - RotateRootAWSConnection
+// swagger:model
+type RotateRootAWSConnectionResponse struct {
+	// connectionid for AWSConnection whose root credentials were rotated.
+	// in: id
+	ID string `json:"id"`
+
+	// root_rotated_on time of rotation.
+	// in: root_rotated_on
+	RootRotatedOn time.Time `json:"root_rotated_on"`
+
+	// root_rotation_successful. 1 = rotation successful. 0 = rotation failed.
+	// in: root_rotation_successful
+	RootRotationSuccessful int `json:"root_rotation_successful"`
+}
+
 // CredsAWSConnectionResponse Response schema for GET - /aws/creds
 // swagger:model
 type CredsAWSConnectionResponse struct {
@@ -211,6 +370,14 @@ type CredsAWSConnectionResponse struct {
 	// out: lease_duration
 	LeaseDuration int `json:"lease_duration"`
 
+	// Renewable tells whether lease for generated access can be renewed
+	// out: renewable
+	Renewable bool `json:"renewable"`
+
+	// RoleName of role which was used to generate credentials
+	// out: role_name
+	RoleName string `json:"role_name"`
+
 	// Latency in seconds before credentials can be used with AWS
 	// out: latency
 	Latency int `json:"latency"`
@@ -230,6 +397,39 @@ type CredsAWSConnectionResponse struct {
 	} `json:"data"`
 }
 
+// WrappedCredsAWSConnectionResponse Response schema for GET - /aws/creds when response wrapping is requested.
+// Credentials are only available by unwrapping wrap_info token, which yields CredsAWSConnectionResponse.
+// swagger:model
+type WrappedCredsAWSConnectionResponse struct {
+	// connectionid for AWSConnection which was used to generate credentials
+	// out: id
+	ConnectionID string `json:"connectionid"`
+
+	// LeaseID for generated access
+	// out: lease_id
+	LeaseID string `json:"lease_id"`
+
+	// LeaseDuration for generated access
+	// out: lease_duration
+	LeaseDuration int `json:"lease_duration"`
+
+	// Renewable tells whether lease for generated access can be renewed
+	// out: renewable
+	Renewable bool `json:"renewable"`
+
+	// RoleName of role which was used to generate credentials
+	// out: role_name
+	RoleName string `json:"role_name"`
+
+	// Latency in seconds before credentials can be used with AWS
+	// out: latency
+	Latency int `json:"latency"`
+
+	// WrapInfo response-wrapping token for generated credentials
+	// out: wrap_info
+	WrapInfo WrapInfo `json:"wrap_info"`
+}
+
 // AWSConnectionsResponse represents AWS Connection attributes which are returned in response of GET on connections/aws endpoint.
 // swagger:model
 type AWSConnectionsResponse struct {
@@ -259,11 +459,76 @@ func NewAWSConnection(cfg *configuration.Config) *AWSConnection {
 	c.Connection.ID = uuid.New()
 	c.ConnectionID = c.Connection.ID
 	c.Connection.ConnectionType = AWSConnectionType
-	c.VaultPath = cfg.Vault.PathPrefix + "/aws_" + c.ID.String()
+	c.SetVaultMount("", "", cfg)
 
 	return &c
 }
 
+// SetVaultMount sets path and namespace of secrets engine mount of connection. Path is generated from id of
+// connection and namespace configured for service is used for values not provided.
+func (c *AWSConnection) SetVaultMount(path string, namespace string, cfg *configuration.Config) {
+	c.VaultPath, c.VaultNamespace = vaultMount(path, namespace, "aws", c.ID, cfg)
+}
+
+// vaultMount returns path and namespace of secrets engine mount of a connection. Path is generated from engine
+// type and id of connection and namespace configured for service is used for values not provided.
+func vaultMount(path string, namespace string, engine string, id uuid.UUID, cfg *configuration.Config) (string, string) {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		path = cfg.Vault.PathPrefix + "/" + engine + "_" + id.String()
+	}
+
+	namespace = strings.Trim(namespace, "/")
+	if namespace == "" {
+		namespace = cfg.Vault.Namespace
+	}
+
+	return path, namespace
+}
+
+// IsValidVaultPath tells whether path can be used as path of secrets engine mount. Mounts have to be under
+// path prefix configured for service so that they are tracked by reconciler.
+func IsValidVaultPath(path string, cfg *configuration.Config) bool {
+	return vaultPathPattern.MatchString(path) && strings.HasPrefix(path, cfg.Vault.PathPrefix+"/")
+}
+
+// IsValidVaultNamespace tells whether namespace can be used as Vault namespace of secrets engine mount.
+func IsValidVaultNamespace(namespace string) bool {
+	return namespace == "" || vaultPathPattern.MatchString(namespace)
+}
+
+// SetRootRotationFailed records failed rotation of root credentials.
+func (c *AWSConnection) SetRootRotationFailed(e string) {
+	c.RootRotationSuccessful = 0
+	c.RootRotatedOn = time.Now().UTC()
+	c.RootRotationError = e
+}
+
+// SetRootRotationPassed records successful rotation of root credentials.
+func (c *AWSConnection) SetRootRotationPassed() {
+	c.RootRotationSuccessful = 1
+	c.RootRotatedOn = time.Now().UTC()
+	c.LastSuccessfulRootRotation = c.RootRotatedOn
+	c.RootRotationError = ""
+}
+
+// ResetRootRotationStatus marks root credentials as never rotated.
+func (c *AWSConnection) ResetRootRotationStatus() {
+	c.RootRotationSuccessful = 0
+	c.RootRotationError = ""
+	c.LastSuccessfulRootRotation = time.Time{}
+}
+
+// IsRootRotationDue tells whether root credentials are due for scheduled rotation at now. Root credentials
+// supplied by user which were never rotated are due right away.
+func (c *AWSConnection) IsRootRotationDue(now time.Time) bool {
+	if c.RootRotationInterval <= 0 {
+		return false
+	}
+
+	return !now.Before(c.LastSuccessfulRootRotation.Add(time.Duration(c.RootRotationInterval) * time.Second))
+}
+
 func InitAWSConnection(id string, cfg *configuration.Config) *AWSConnection {
 	var c AWSConnection
 
@@ -0,0 +1,183 @@
+package data
+
+import (
+	"regexp"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// awsRoleNamePattern restricts role names to characters which are safe to use in Vault paths.
+var awsRoleNamePattern = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,64}$`)
+
+// AWSRolePostWrapper represents AWSRole attributes for POST request body schema.
+// swagger:model
+type AWSRolePostWrapper struct {
+	// RoleName RoleName for AWS Account Role
+	// required: true
+	RoleName string `json:"role_name" validate:"required"`
+
+	// CredentialType CredentialType for AWS Account Role
+	// required: true
+	CredentialType string `json:"credential_type" validate:"required,oneof=iam_user session_token assumed_role federation_token"`
+
+	// PolicyARNs PolicyARNs for AWS Account Role
+	// required: only if credential_type is set to iam_user and policy_document is not provided
+	PolicyARNs []string `json:"policy_arns"`
+
+	// PolicyDocument IAM policy document in JSON format for AWS Account Role
+	// required: only if credential_type is set to federation_token and policy_arns are not provided
+	PolicyDocument string `json:"policy_document"`
+
+	// RoleARNs ARNs of AWS roles to be assumed
+	// required: only if credential_type is set to assumed_role
+	RoleARNs []string `json:"role_arns"`
+
+	// SessionTags session tags to be set for assumed role session
+	// required: false. only allowed if credential_type is set to assumed_role
+	SessionTags map[string]string `json:"session_tags"`
+
+	// ExternalID external id to be passed to assumed role
+	// required: false. only allowed if credential_type is set to assumed_role
+	ExternalID string `json:"external_id"`
+}
+
+// AWSRolePatchWrapper represents AWSRole attributes for PATCH request body schema.
+// swagger:model
+type AWSRolePatchWrapper struct {
+	// CredentialType CredentialType for AWS Account Role
+	// required: false
+	CredentialType *string `json:"credential_type,omitempty" validate:"omitempty,oneof=iam_user session_token assumed_role federation_token"`
+
+	// PolicyARNs PolicyARNs for AWS Account Role
+	// required: false
+	PolicyARNs []string `json:"policy_arns,omitempty" validate:"omitempty"`
+
+	// PolicyDocument IAM policy document in JSON format for AWS Account Role
+	// required: false
+	PolicyDocument *string `json:"policy_document,omitempty" validate:"omitempty"`
+
+	// RoleARNs ARNs of AWS roles to be assumed
+	// required: false
+	RoleARNs []string `json:"role_arns,omitempty" validate:"omitempty"`
+
+	// SessionTags session tags to be set for assumed role session
+	// required: false
+	SessionTags map[string]string `json:"session_tags,omitempty" validate:"omitempty"`
+
+	// ExternalID external id to be passed to assumed role
+	// required: false
+	ExternalID *string `json:"external_id,omitempty" validate:"omitempty"`
+}
+
+// AWSRole represents named Vault role of AWSConnection. Each role has its own credential type and
+// policies. Role attributes are kept in Vault, datastore only tracks roles of each AWSConnection.
+// swagger:model
+type AWSRole struct {
+	ID        uuid.UUID `json:"id" gorm:"primaryKey"`
+	CreatedAt time.Time `json:"createdat" gorm:"autoCreateTime;index;not null"`
+	UpdatedAt time.Time `json:"updatedat" gorm:"autoUpdateTime;index"`
+
+	// AWSConnectionID id of AWSConnection this role belongs to
+	// required: true
+	AWSConnectionID uuid.UUID `json:"awsconnectionid" gorm:"not null;uniqueIndex:idx_aws_roles_connection_role"`
+
+	// RoleName RoleName for AWS Account Role
+	// required: true
+	RoleName string `json:"role_name" validate:"required" gorm:"not null;uniqueIndex:idx_aws_roles_connection_role"`
+
+	// CredentialType CredentialType for AWS Account Role
+	// required: true
+	CredentialType string `json:"credential_type" validate:"required,oneof=iam_user session_token assumed_role federation_token" gorm:"-"`
+
+	// PolicyARNs PolicyARNs for AWS Account Role
+	// required: false
+	PolicyARNs []string `json:"policy_arns" gorm:"-"`
+
+	// PolicyDocument IAM policy document in JSON format for AWS Account Role
+	// required: false
+	PolicyDocument string `json:"policy_document" gorm:"-"`
+
+	// RoleARNs ARNs of AWS roles to be assumed
+	// required: false
+	RoleARNs []string `json:"role_arns" gorm:"-"`
+
+	// SessionTags session tags to be set for assumed role session
+	// required: false
+	SessionTags map[string]string `json:"session_tags" gorm:"-"`
+
+	// ExternalID external id to be passed to assumed role
+	// required: false
+	ExternalID string `json:"external_id" gorm:"-"`
+
+	// VaultRoleMissing tells whether role was lost along with secrets engine mount re-created by reconciler.
+	// Role is configured in Vault again once AWSConnection is patched.
+	// required: false
+	VaultRoleMissing bool `json:"vault_role_missing" gorm:"not null;default:false"`
+}
+
+// AWSRolesResponse represents AWSRole resources which are returned in response of GET on roles endpoint.
+// swagger:model
+type AWSRolesResponse struct {
+	// Number of skipped resources
+	// required: true
+	Skip int `json:"skip"`
+
+	// Limit applied on resources returned
+	// required: true
+	Limit int `json:"limit"`
+
+	// Total number of resources returned
+	// required: true
+	Total int `json:"total"`
+
+	// AWSRole resource objects
+	// required: true
+	Roles []AWSRole `json:"roles"`
+}
+
+// DeleteAWSRoleResponse represents Response schema for DELETE - DeleteAWSConnectionRole
+// swagger:model
+type DeleteAWSRoleResponse struct {
+	// Descriptive human readable HTTP status of delete operation.
+	// in: status
+	Status string `json:"status"`
+
+	// HTTP status code for delete operation.
+	// in: statusCode
+	StatusCode int `json:"statusCode"`
+}
+
+func NewAWSRole(awsConnectionID uuid.UUID) *AWSRole {
+	var r AWSRole
+
+	r.ID = uuid.New()
+	r.AWSConnectionID = awsConnectionID
+
+	return &r
+}
+
+// DefaultRole returns role defined by AWSConnection's own role attributes.
+func (c *AWSConnection) DefaultRole() *AWSRole {
+	r := NewAWSRole(c.ID)
+
+	r.RoleName = c.RoleName
+	r.CredentialType = c.CredentialType
+	r.PolicyARNs = c.PolicyARNs
+	r.PolicyDocument = c.PolicyDocument
+	r.RoleARNs = c.RoleARNs
+	r.SessionTags = c.SessionTags
+	r.ExternalID = c.ExternalID
+
+	return r
+}
+
+// Name returns name of Vault role AWSRole configures.
+func (r *AWSRole) Name() string {
+	return r.RoleName
+}
+
+// IsValidAWSRoleName tells whether name can be used as name of AWSRole.
+func IsValidAWSRoleName(name string) bool {
+	return awsRoleNamePattern.MatchString(name)
+}
@@ -0,0 +1,342 @@
+package data
+
+import (
+	"DemoServer_ConnectionManager/configuration"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AzureRoleAssignment represents Azure role assigned to service principals generated through AzureConnection.
+// swagger:model
+type AzureRoleAssignment struct {
+	// RoleName name of Azure role i.e. Contributor. Either role_name or role_id is required
+	// required: false
+	RoleName string `json:"role_name,omitempty" validate:"required_without=RoleID"`
+
+	// RoleID id of Azure role. Takes precedence over role_name
+	// required: false
+	RoleID string `json:"role_id,omitempty"`
+
+	// Scope scope of role assignment i.e. /subscriptions/<subscription_id>/resourceGroups/<resource_group>
+	// required: true
+	Scope string `json:"scope" validate:"required"`
+}
+
+// AzureConnectionPostWrapper represents AzureConnection attributes for POST request body schema.
+// swagger:model
+type AzureConnectionPostWrapper struct {
+	Connection ConnectionPostWrapper `json:"connection" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+
+	// SubscriptionID of Azure subscription
+	// required: true
+	SubscriptionID string `json:"subscription_id" validate:"required,uuid" gorm:"-"`
+
+	// TenantID of Azure Active Directory tenant
+	// required: true
+	TenantID string `json:"tenant_id" validate:"required,uuid" gorm:"-"`
+
+	// ClientID of service principal Vault uses to manage Azure
+	// required: true
+	ClientID string `json:"client_id" validate:"required,uuid" gorm:"-"`
+
+	// ClientSecret of service principal Vault uses to manage Azure
+	// required: true
+	ClientSecret string `json:"client_secret" validate:"required" gorm:"-"`
+
+	// Environment Azure cloud environment. AzurePublicCloud is used if not provided
+	// required: false
+	Environment string `json:"environment" validate:"omitempty,oneof=AzurePublicCloud AzureUSGovernmentCloud AzureChinaCloud AzureGermanCloud" gorm:"-"`
+
+	// DefaultLeaseTTL default lease ttl of secrets engine mount i.e. 1h
+	// required: false
+	DefaultLeaseTTL string `json:"default_lease_ttl" gorm:"-"`
+
+	// MaxLeaseTTL max lease ttl of secrets engine mount i.e. 24h
+	// required: false
+	MaxLeaseTTL string `json:"max_lease_ttl" gorm:"-"`
+
+	// RoleName name of Vault role credentials are generated through
+	// required: true
+	RoleName string `json:"role_name" validate:"required" gorm:"-"`
+
+	// AzureRoles Azure roles assigned to service principals generated for role
+	// required: only if application_object_id is not provided
+	AzureRoles []AzureRoleAssignment `json:"azure_roles" validate:"omitempty,dive" gorm:"-"`
+
+	// ApplicationObjectID object id of existing Azure application whose credentials are generated instead of service principals
+	// required: only if azure_roles are not provided
+	ApplicationObjectID string `json:"application_object_id" gorm:"-"`
+
+	// VaultPath path of secrets engine mount for Azure subscription. Has to be under path prefix configured for service
+	// required: false. generated from id of connection if not provided
+	VaultPath string `json:"vaultpath" gorm:"-"`
+
+	// VaultNamespace Vault namespace of secrets engine mount for Azure subscription
+	// required: false. namespace configured for service is used if not provided
+	VaultNamespace string `json:"vault_namespace" gorm:"-"`
+}
+
+// AzureConnectionPatchWrapper represents AzureConnection attributes for PATCH request body schema.
+// swagger:model
+type AzureConnectionPatchWrapper struct {
+	Connection *ConnectionPatchWrapper `json:"connection,omitempty" validate:"omitempty" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+
+	// SubscriptionID of Azure subscription
+	// required: false
+	SubscriptionID *string `json:"subscription_id,omitempty" validate:"omitempty,uuid" gorm:"-"`
+
+	// TenantID of Azure Active Directory tenant
+	// required: false
+	TenantID *string `json:"tenant_id,omitempty" validate:"omitempty,uuid" gorm:"-"`
+
+	// ClientID of service principal Vault uses to manage Azure
+	// required: false
+	ClientID *string `json:"client_id,omitempty" validate:"omitempty,uuid" gorm:"-"`
+
+	// ClientSecret of service principal Vault uses to manage Azure
+	// required: false
+	ClientSecret *string `json:"client_secret,omitempty" validate:"omitempty" gorm:"-"`
+
+	// Environment Azure cloud environment
+	// required: false
+	Environment *string `json:"environment,omitempty" validate:"omitempty,oneof=AzurePublicCloud AzureUSGovernmentCloud AzureChinaCloud AzureGermanCloud" gorm:"-"`
+
+	// DefaultLeaseTTL default lease ttl of secrets engine mount
+	// required: false
+	DefaultLeaseTTL *string `json:"default_lease_ttl,omitempty" validate:"omitempty" gorm:"-"`
+
+	// MaxLeaseTTL max lease ttl of secrets engine mount
+	// required: false
+	MaxLeaseTTL *string `json:"max_lease_ttl,omitempty" validate:"omitempty" gorm:"-"`
+
+	// AzureRoles Azure roles assigned to service principals generated for role
+	// required: false
+	AzureRoles []AzureRoleAssignment `json:"azure_roles,omitempty" validate:"omitempty,dive" gorm:"-"`
+
+	// ApplicationObjectID object id of existing Azure application whose credentials are generated instead of service principals
+	// required: false
+	ApplicationObjectID *string `json:"application_object_id,omitempty" validate:"omitempty" gorm:"-"`
+}
+
+// AzureConnection represents AzureConnection resource serialized by Microservice endpoints
+// swagger:model
+type AzureConnection struct {
+	ID           uuid.UUID  `json:"id" gorm:"primaryKey"`
+	CreatedAt    time.Time  `json:"createdat" gorm:"autoCreateTime;index;not null"`
+	UpdatedAt    time.Time  `json:"updatedat" gorm:"autoUpdateTime;index"`
+	ConnectionID uuid.UUID  `json:"connectionid" gorm:"not null;index;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Connection   Connection `json:"connection" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+
+	// VaultPath for Azure subscription
+	// required: true
+	VaultPath string `json:"vaultpath" validate:"required" gorm:"not null;uniqueIndex:idx_azure_connections_vault_mount"`
+
+	// VaultNamespace Vault namespace of secrets engine mount for Azure subscription
+	// required: false
+	VaultNamespace string `json:"vault_namespace" gorm:"not null;default:'';uniqueIndex:idx_azure_connections_vault_mount"`
+
+	// SubscriptionID of Azure subscription
+	// required: true
+	SubscriptionID string `json:"subscription_id" gorm:"-"`
+
+	// TenantID of Azure Active Directory tenant
+	// required: true
+	TenantID string `json:"tenant_id" gorm:"-"`
+
+	// ClientID of service principal Vault uses to manage Azure
+	// required: true
+	ClientID string `json:"client_id" gorm:"-"`
+
+	// ClientSecret of service principal Vault uses to manage Azure. Masked in responses
+	// required: true
+	ClientSecret string `json:"client_secret" gorm:"-" redact:"true"`
+
+	// Environment Azure cloud environment
+	// required: false
+	Environment string `json:"environment" gorm:"-"`
+
+	// DefaultLeaseTTL default lease ttl of secrets engine mount
+	// required: false
+	DefaultLeaseTTL string `json:"default_lease_ttl" gorm:"-"`
+
+	// MaxLeaseTTL max lease ttl of secrets engine mount
+	// required: false
+	MaxLeaseTTL string `json:"max_lease_ttl" gorm:"-"`
+
+	// RoleName name of Vault role credentials are generated through
+	// required: true
+	RoleName string `json:"role_name" validate:"required"`
+
+	// AzureRoles Azure roles assigned to service principals generated for role
+	// required: false
+	AzureRoles []AzureRoleAssignment `json:"azure_roles" gorm:"-"`
+
+	// ApplicationObjectID object id of existing Azure application whose credentials are generated instead of service principals
+	// required: false
+	ApplicationObjectID string `json:"application_object_id" gorm:"-"`
+}
+
+// AzureConnectionResponseWrapper represents limited information AzureConnection resource returned by Post, Get and List endpoints
+// swagger:model
+type AzureConnectionResponseWrapper struct {
+	ID           uuid.UUID  `json:"id" gorm:"primaryKey"`
+	CreatedAt    time.Time  `json:"createdat" gorm:"autoCreateTime;index;not null"`
+	UpdatedAt    time.Time  `json:"updatedat" gorm:"autoUpdateTime;index"`
+	ConnectionID uuid.UUID  `json:"connectionid" gorm:"not null;index"`
+	Connection   Connection `json:"connection" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+
+	// VaultPath path of secrets engine mount for Azure subscription
+	// required: true
+	VaultPath string `json:"vaultpath" gorm:"-"`
+
+	// VaultNamespace Vault namespace of secrets engine mount for Azure subscription
+	// required: false
+	VaultNamespace string `json:"vault_namespace" gorm:"-"`
+
+	// SubscriptionID of Azure subscription
+	// required: true
+	SubscriptionID string `json:"subscription_id" gorm:"-"`
+
+	// TenantID of Azure Active Directory tenant
+	// required: true
+	TenantID string `json:"tenant_id" gorm:"-"`
+
+	// ClientID of service principal Vault uses to manage Azure
+	// required: true
+	ClientID string `json:"client_id" gorm:"-"`
+
+	// Environment Azure cloud environment
+	// required: false
+	Environment string `json:"environment" gorm:"-"`
+
+	// DefaultLeaseTTL default lease ttl of secrets engine mount
+	// required: false
+	DefaultLeaseTTL string `json:"default_lease_ttl" gorm:"-"`
+
+	// MaxLeaseTTL max lease ttl of secrets engine mount
+	// required: false
+	MaxLeaseTTL string `json:"max_lease_ttl" gorm:"-"`
+
+	// RoleName name of Vault role credentials are generated through
+	// required: true
+	RoleName string `json:"role_name" gorm:"-"`
+
+	// AzureRoles Azure roles assigned to service principals generated for role
+	// required: false
+	AzureRoles []AzureRoleAssignment `json:"azure_roles" gorm:"-"`
+
+	// ApplicationObjectID object id of existing Azure application whose credentials are generated instead of service principals
+	// required: false
+	ApplicationObjectID string `json:"application_object_id" gorm:"-"`
+}
+
+// DeleteAzureConnectionResponse represents Response schema for DELETE - DeleteAzureConnection
+// swagger:model
+type DeleteAzureConnectionResponse struct {
+	// Descriptive human readable HTTP status of delete operation.
+	// in: status
+	Status string `json:"status"`
+
+	// HTTP status code for delete operation.
+	// in: statusCode
+	StatusCode int `json:"statusCode"`
+}
+
+// TestAzureConnectionResponse Response schema for GET - TestAzureConnection
+// swagger:model
+type TestAzureConnectionResponse struct {
+	// connectionid for AzureConnection which was tested.
+	// in: id
+	ID string `json:"id"`
+
+	// role_name of role which was tested.
+	// in: role_name
+	RoleName string `json:"role_name"`
+
+	// test status descriptive human readable message.
+	// in: test_status
+	TestStatus string `json:"testStatus"`
+
+	// test_status_code. 1 = connectivity test successful. 0 = connectivity test failed.
+	// in: test_status_code
+	TestStatusCode int `json:"testStatusCode"`
+}
+
+// CredsAzureConnectionResponse Response schema for GET - /azure/creds
+// swagger:model
+type CredsAzureConnectionResponse struct {
+	// connectionid for AzureConnection which was used to generate credentials
+	// out: id
+	ConnectionID string `json:"connectionid"`
+
+	// LeaseID for generated access
+	// out: lease_id
+	LeaseID string `json:"lease_id"`
+
+	// LeaseDuration for generated access
+	// out: lease_duration
+	LeaseDuration int `json:"lease_duration"`
+
+	// Renewable tells whether lease for generated access can be renewed
+	// out: renewable
+	Renewable bool `json:"renewable"`
+
+	// RoleName of role which was used to generate credentials
+	// out: role_name
+	RoleName string `json:"role_name"`
+
+	Data struct {
+		// ClientID of generated service principal
+		// out: client_id
+		ClientID string `json:"client_id"`
+
+		// ClientSecret of generated service principal
+		// out: client_secret
+		ClientSecret string `json:"client_secret"`
+	} `json:"data"`
+}
+
+// AzureConnectionsResponse represents Azure Connection attributes which are returned in response of GET on connections/azure endpoint.
+// swagger:model
+type AzureConnectionsResponse struct {
+	// Number of skipped resources
+	// required: true
+	Skip int `json:"skip"`
+
+	// Limit applied on resources returned
+	// required: true
+	Limit int `json:"limit"`
+
+	// Total number of resources returned
+	// required: true
+	Total int `json:"total"`
+
+	// Connection resource objects
+	// required: true
+	AzureConnections []AzureConnectionResponseWrapper `json:"azureconnections"`
+}
+
+func NewAzureConnection(cfg *configuration.Config) *AzureConnection {
+	var c AzureConnection
+
+	c.ID = uuid.New()
+	c.Connection.ID = uuid.New()
+	c.ConnectionID = c.Connection.ID
+	c.Connection.ConnectionType = AzureConnectionType
+	c.SetVaultMount("", "", cfg)
+
+	return &c
+}
+
+// SetVaultMount sets path and namespace of secrets engine mount of connection. Path is generated from id of
+// connection and namespace configured for service is used for values not provided.
+func (c *AzureConnection) SetVaultMount(path string, namespace string, cfg *configuration.Config) {
+	c.VaultPath, c.VaultNamespace = vaultMount(path, namespace, "azure", c.ID, cfg)
+}
+
+// HasRole tells whether connection defines what generated credentials grant, either through Azure roles or an
+// existing application. Vault refuses roles without either.
+func (c *AzureConnection) HasRole() bool {
+	return len(c.AzureRoles) > 0 || c.ApplicationObjectID != ""
+}
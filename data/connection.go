@@ -7,7 +7,6 @@ import (
 	"time"
 
 	"github.com/google/uuid"
-	"gorm.io/gorm"
 )
 
 // Connection represents generic Connection attributes which are allowed in POST request.
@@ -90,12 +89,13 @@ type Connection struct {
 	// required: false
 	LastSuccessfulTest string `json:"lastsuccessfultest"`
 
-	// Applications consuming the connection
+	// IDs of applications linked to the connection. Populated from connection links
 	// required: false
-	Applications JSONStringArray `json:"applications" gorm:"type:json"`
+	Applications []string `json:"applications" gorm:"-"`
 }
 
-// JSONStringArray is a custom type for handling []string as JSON
+// JSONStringArray is a custom type for handling []string as JSON. It is used to read applications column
+// which held links of connections before they were moved to connection_links table.
 type JSONStringArray []string
 
 // MarshalJSON converts the slice to JSON
@@ -113,23 +113,6 @@ func (a *JSONStringArray) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
-func (c *Connection) BeforeSave(tx *gorm.DB) (err error) {
-	// Ensure Applications is properly serialized
-	data, err := json.Marshal([]string(c.Applications))
-	if err != nil {
-		return fmt.Errorf("failed to serialize applications: %w", err)
-	}
-
-	// Deserialize JSON back into the Applications field as JSONStringArray
-	var serialized JSONStringArray
-	if err := json.Unmarshal(data, &serialized); err != nil {
-		return fmt.Errorf("failed to deserialize applications: %w", err)
-	}
-
-	c.Applications = serialized
-	return nil
-}
-
 // Value implements the driver.Valuer interface to save JSONStringArray as JSON
 func (a JSONStringArray) Value() (driver.Value, error) {
 	return json.Marshal([]string(a))
@@ -194,3 +177,60 @@ func (c *Connection) ResetTestStatus() {
 	c.TestSuccessful = 0
 	c.TestError = ""
 }
+
+// Accessors of attributes every connection type shares, used by handlers to serve connection types through
+// a common flow.
+
+// GenericConnection returns generic Connection of AWSConnection.
+func (c *AWSConnection) GenericConnection() *Connection { return &c.Connection }
+
+// Mount returns path and namespace of secrets engine mount of AWSConnection.
+func (c *AWSConnection) Mount() (string, string) { return c.VaultPath, c.VaultNamespace }
+
+// DefaultRoleName returns name of role AWSConnection is tested through.
+func (c *AWSConnection) DefaultRoleName() string { return c.RoleName }
+
+// GenericConnection returns generic Connection of AzureConnection.
+func (c *AzureConnection) GenericConnection() *Connection { return &c.Connection }
+
+// Mount returns path and namespace of secrets engine mount of AzureConnection.
+func (c *AzureConnection) Mount() (string, string) { return c.VaultPath, c.VaultNamespace }
+
+// DefaultRoleName returns name of role AzureConnection is tested through.
+func (c *AzureConnection) DefaultRoleName() string { return c.RoleName }
+
+// GenericConnection returns generic Connection of GCPConnection.
+func (c *GCPConnection) GenericConnection() *Connection { return &c.Connection }
+
+// Mount returns path and namespace of secrets engine mount of GCPConnection.
+func (c *GCPConnection) Mount() (string, string) { return c.VaultPath, c.VaultNamespace }
+
+// DefaultRoleName returns name of role GCPConnection is tested through.
+func (c *GCPConnection) DefaultRoleName() string { return c.RoleName }
+
+// GenericConnection returns generic Connection of DatabaseConnection.
+func (c *DatabaseConnection) GenericConnection() *Connection { return &c.Connection }
+
+// Mount returns path and namespace of secrets engine mount of DatabaseConnection.
+func (c *DatabaseConnection) Mount() (string, string) { return c.VaultPath, c.VaultNamespace }
+
+// DefaultRoleName returns name of role DatabaseConnection is tested through.
+func (c *DatabaseConnection) DefaultRoleName() string { return c.RoleName }
+
+// GenericConnection returns generic Connection of SSHConnection.
+func (c *SSHConnection) GenericConnection() *Connection { return &c.Connection }
+
+// Mount returns path and namespace of secrets engine mount of SSHConnection.
+func (c *SSHConnection) Mount() (string, string) { return c.VaultPath, c.VaultNamespace }
+
+// DefaultRoleName returns name of role SSHConnection signs certificates through.
+func (c *SSHConnection) DefaultRoleName() string { return c.RoleName }
+
+// GenericConnection returns generic Connection of PKIConnection.
+func (c *PKIConnection) GenericConnection() *Connection { return &c.Connection }
+
+// Mount returns path and namespace of secrets engine mount of PKIConnection.
+func (c *PKIConnection) Mount() (string, string) { return c.VaultPath, c.VaultNamespace }
+
+// DefaultRoleName returns name of role PKIConnection issues certificates through.
+func (c *PKIConnection) DefaultRoleName() string { return c.RoleName }
@@ -0,0 +1,96 @@
+package data
+
+import (
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ConnectionLinkPostWrapper represents ConnectionLink attributes which are allowed in POST request.
+//
+// swagger:model
+type ConnectionLinkPostWrapper struct {
+	// Environment application runs in. One of dev, stage, prod
+	// required: false
+	Environment string `json:"environment,omitempty" validate:"omitempty,oneof=dev stage prod"`
+
+	// Purpose free-form reason for link
+	// required: false
+	Purpose string `json:"purpose,omitempty"`
+
+	// AllowedRoles names of roles application may obtain credentials for. Every role of connection is allowed if empty
+	// required: false
+	AllowedRoles []string `json:"allowed_roles,omitempty" validate:"omitempty,dive,required"`
+
+	// ExpiresAt date and time in RFC3339 format after which link is removed. Link does not expire if omitted
+	// required: false
+	ExpiresAt *time.Time `json:"expiresat,omitempty"`
+
+	// Metadata free-form attributes of link
+	// required: false
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// ConnectionLink links application to generic Connection. Application can only obtain credentials
+// through connections it is linked to.
+//
+// swagger:model
+type ConnectionLink struct {
+	ID        uuid.UUID `json:"id" gorm:"primaryKey"`
+	CreatedAt time.Time `json:"createdat" gorm:"autoCreateTime;index;not null"`
+
+	// ID of generic Connection application is linked to
+	// required: true
+	ConnectionID uuid.UUID `json:"connectionid" gorm:"not null;uniqueIndex:idx_connection_links_connection_application"`
+
+	// ID of application linked to connection
+	// required: true
+	ApplicationID string `json:"applicationid" gorm:"not null;index;uniqueIndex:idx_connection_links_connection_application"`
+
+	// Identity of caller who linked application
+	// required: false
+	CreatedBy string `json:"createdby"`
+
+	// Environment application runs in. One of dev, stage, prod
+	// required: false
+	Environment string `json:"environment" gorm:"index"`
+
+	// Purpose free-form reason for link
+	// required: false
+	Purpose string `json:"purpose"`
+
+	// AllowedRoles names of roles application may obtain credentials for. Every role of connection is allowed if empty
+	// required: false
+	AllowedRoles []string `json:"allowed_roles" gorm:"serializer:json"`
+
+	// ExpiresAt date and time after which link is removed. null if link does not expire
+	// required: false
+	ExpiresAt *time.Time `json:"expiresat" gorm:"index"`
+
+	// Metadata free-form attributes of link
+	// required: false
+	Metadata map[string]string `json:"metadata" gorm:"serializer:json"`
+}
+
+func NewConnectionLink(connectionID uuid.UUID, applicationID string, createdBy string) *ConnectionLink {
+	var l ConnectionLink
+
+	l.ID = uuid.New()
+	l.ConnectionID = connectionID
+	l.ApplicationID = applicationID
+	l.CreatedBy = createdBy
+	l.Metadata = map[string]string{}
+
+	return &l
+}
+
+// IsExpired tells whether link expired at or before now.
+func (l *ConnectionLink) IsExpired(now time.Time) bool {
+	return l.ExpiresAt != nil && !l.ExpiresAt.After(now)
+}
+
+// AllowsRole tells whether application may obtain credentials for role through link.
+func (l *ConnectionLink) AllowsRole(role string) bool {
+	return len(l.AllowedRoles) == 0 || slices.Contains(l.AllowedRoles, role)
+}
@@ -0,0 +1,84 @@
+package data
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ConnectionTestResult represents outcome of a single connectivity test of a connection.
+//
+// swagger:model
+type ConnectionTestResult struct {
+	ID        uuid.UUID `json:"id" gorm:"primaryKey"`
+	CreatedAt time.Time `json:"createdat" gorm:"autoCreateTime;index;not null"`
+
+	// ID of generic Connection resource which was tested
+	// required: true
+	ConnectionID uuid.UUID `json:"connectionid" gorm:"not null;index"`
+
+	// Name of role used to test connection
+	// required: false
+	RoleName string `json:"role_name"`
+
+	// ID of request which tested connection
+	// required: true
+	RequestID string `json:"request_id" gorm:"index;not null"`
+
+	// Scheduled tells whether test was run by scheduler rather than requested by caller
+	// required: true
+	Scheduled bool `json:"scheduled" gorm:"index;not null;default:false"`
+
+	// Test result. 0 = Failed. 1 = Successful
+	// required: true
+	TestSuccessful int `json:"testsuccessful"`
+
+	// Descriptive error for failed test
+	// required: false
+	TestError string `json:"testerror"`
+}
+
+// ConnectionTestResultsResponse represents ConnectionTestResult resources which are returned in response of GET on connection tests endpoint.
+//
+// swagger:model
+type ConnectionTestResultsResponse struct {
+	// Number of skipped resources
+	// required: true
+	Skip int `json:"skip"`
+
+	// Limit applied on resources returned
+	// required: true
+	Limit int `json:"limit"`
+
+	// Total number of resources returned
+	// required: true
+	Total int `json:"total"`
+
+	// ConnectionTestResult resource objects
+	// required: true
+	TestResults []ConnectionTestResult `json:"testresults"`
+}
+
+func NewConnectionTestResult(requestID string, connectionID uuid.UUID, roleName string, scheduled bool) *ConnectionTestResult {
+	var t ConnectionTestResult
+
+	t.ID = uuid.New()
+	t.RequestID = requestID
+	t.ConnectionID = connectionID
+	t.RoleName = roleName
+	t.Scheduled = scheduled
+
+	return &t
+}
+
+// SetPassed marks test as successful.
+func (t *ConnectionTestResult) SetPassed() {
+	t.TestSuccessful = 1
+	t.TestError = ""
+}
+
+// SetFailed marks test as failed with descriptive error e.
+func (t *ConnectionTestResult) SetFailed(e string) {
+	t.TestSuccessful = 0
+	t.TestError = e
+}
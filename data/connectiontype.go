@@ -12,6 +12,11 @@ type ConnectionTypeEnum int
 const (
 	NoConnectionType ConnectionTypeEnum = iota
 	AWSConnectionType
+	AzureConnectionType
+	GCPConnectionType
+	DatabaseConnectionType
+	SSHConnectionType
+	PKIConnectionType
 )
 
 func (o ConnectionTypeEnum) String() string {
@@ -19,14 +24,24 @@ func (o ConnectionTypeEnum) String() string {
 }
 
 var operation_toString = map[ConnectionTypeEnum]string{
-	NoConnectionType:  strings.ToLower("NoConnectionType"),
-	AWSConnectionType: strings.ToLower("AWSConnectionType"),
+	NoConnectionType:       strings.ToLower("NoConnectionType"),
+	AWSConnectionType:      strings.ToLower("AWSConnectionType"),
+	AzureConnectionType:    strings.ToLower("AzureConnectionType"),
+	GCPConnectionType:      strings.ToLower("GCPConnectionType"),
+	DatabaseConnectionType: strings.ToLower("DatabaseConnectionType"),
+	SSHConnectionType:      strings.ToLower("SSHConnectionType"),
+	PKIConnectionType:      strings.ToLower("PKIConnectionType"),
 }
 
 var operation_toID = map[string]ConnectionTypeEnum{
-	strings.ToLower(""):                  NoConnectionType,
-	strings.ToLower("NoConnectionType"):  NoConnectionType,
-	strings.ToLower("AWSConnectionType"): AWSConnectionType,
+	strings.ToLower(""):                       NoConnectionType,
+	strings.ToLower("NoConnectionType"):       NoConnectionType,
+	strings.ToLower("AWSConnectionType"):      AWSConnectionType,
+	strings.ToLower("AzureConnectionType"):    AzureConnectionType,
+	strings.ToLower("GCPConnectionType"):      GCPConnectionType,
+	strings.ToLower("DatabaseConnectionType"): DatabaseConnectionType,
+	strings.ToLower("SSHConnectionType"):      SSHConnectionType,
+	strings.ToLower("PKIConnectionType"):      PKIConnectionType,
 }
 
 // MarshalJSON marshals the enum as a quoted json string
@@ -0,0 +1,75 @@
+package data
+
+import (
+	"time"
+)
+
+// MountDrift represents an inconsistency between aws_connections datastore and secrets engine mounts of Vault.
+//
+// swagger:model
+type MountDrift struct {
+	// ID of generic Connection resource. Empty for secrets engine mounts without connection.
+	// required: false
+	ConnectionID string `json:"connectionid,omitempty"`
+
+	// Type of secrets engine i.e. aws, azure, gcp, database, ssh, pki
+	// required: true
+	Engine string `json:"engine"`
+
+	// Path of secrets engine mount in Vault
+	// required: true
+	VaultPath string `json:"vaultpath"`
+
+	// Vault namespace of secrets engine mount. Empty for root namespace
+	// required: false
+	VaultNamespace string `json:"vault_namespace,omitempty"`
+
+	// Date and time when drift was first observed
+	// required: true
+	DetectedAt time.Time `json:"detectedat"`
+
+	// Repaired tells whether drift has been repaired
+	// required: true
+	Repaired bool `json:"repaired"`
+
+	// Descriptive error for latest failed repair attempt
+	// required: false
+	RepairError string `json:"repairerror,omitempty"`
+}
+
+// DriftReport represents outcome of latest reconciliation between datastore and Vault.
+//
+// swagger:model
+type DriftReport struct {
+	// Date and time of latest reconciliation. Empty if reconciliation has not run yet.
+	// required: false
+	CheckedAt *time.Time `json:"checkedat"`
+
+	// Repair tells whether detected drift is repaired automatically
+	// required: true
+	Repair bool `json:"repair"`
+
+	// Descriptive error if latest reconciliation could not complete
+	// required: false
+	Error string `json:"error,omitempty"`
+
+	// Connections whose secrets engine mount is missing in Vault
+	// required: true
+	MissingMounts []MountDrift `json:"missingmounts"`
+
+	// Secrets engine mounts in Vault without a connection
+	// required: true
+	OrphanMounts []MountDrift `json:"orphanmounts"`
+}
+
+// NewDriftReport returns empty report of reconciliation started at checkedAt.
+func NewDriftReport(checkedAt time.Time, repair bool) *DriftReport {
+	var d DriftReport
+
+	d.CheckedAt = &checkedAt
+	d.Repair = repair
+	d.MissingMounts = []MountDrift{}
+	d.OrphanMounts = []MountDrift{}
+
+	return &d
+}
@@ -0,0 +1,370 @@
+package data
+
+import (
+	"DemoServer_ConnectionManager/configuration"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// GCP role types and secret types understood by Vault's gcp secrets engine
+const (
+	GCPRoleTypeRoleset             = "roleset"
+	GCPRoleTypeStaticAccount       = "static_account"
+	GCPSecretTypeAccessToken       = "access_token"
+	GCPSecretTypeServiceAccountKey = "service_account_key"
+)
+
+// GCPBinding represents IAM roles granted on a GCP resource to service account of a role.
+// swagger:model
+type GCPBinding struct {
+	// Resource full resource name i.e. //cloudresourcemanager.googleapis.com/projects/my-project
+	// required: true
+	Resource string `json:"resource" validate:"required"`
+
+	// Roles IAM roles granted on resource i.e. roles/viewer
+	// required: true
+	Roles []string `json:"roles" validate:"required,min=1"`
+}
+
+// GCPConnectionPostWrapper represents GCPConnection attributes for POST request body schema.
+// swagger:model
+type GCPConnectionPostWrapper struct {
+	Connection ConnectionPostWrapper `json:"connection" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+
+	// Credentials JSON key of service account Vault uses to manage GCP
+	// required: true
+	Credentials string `json:"credentials" validate:"required" gorm:"-"`
+
+	// DefaultLeaseTTL default lease ttl of secrets engine mount i.e. 1h
+	// required: false
+	DefaultLeaseTTL string `json:"default_lease_ttl" gorm:"-"`
+
+	// MaxLeaseTTL max lease ttl of secrets engine mount i.e. 24h
+	// required: false
+	MaxLeaseTTL string `json:"max_lease_ttl" gorm:"-"`
+
+	// RoleName name of Vault roleset or static account credentials are generated through
+	// required: true
+	RoleName string `json:"role_name" validate:"required" gorm:"-"`
+
+	// RoleType either roleset, for which Vault creates a service account, or static_account, which uses an existing service account
+	// required: true
+	RoleType string `json:"role_type" validate:"required,oneof=roleset static_account" gorm:"-"`
+
+	// SecretType either access_token for OAuth2 tokens or service_account_key for service account keys
+	// required: true
+	SecretType string `json:"secret_type" validate:"required,oneof=access_token service_account_key" gorm:"-"`
+
+	// Project GCP project service account of roleset is created in. Ignored for static_account
+	// required: only if role_type is roleset
+	Project string `json:"project" gorm:"-"`
+
+	// ServiceAccountEmail email of existing service account of static account. Ignored for roleset
+	// required: only if role_type is static_account
+	ServiceAccountEmail string `json:"service_account_email" validate:"omitempty,email" gorm:"-"`
+
+	// Bindings IAM roles granted to service account on GCP resources
+	// required: only if role_type is roleset
+	Bindings []GCPBinding `json:"bindings" validate:"omitempty,dive" gorm:"-"`
+
+	// TokenScopes OAuth scopes of generated access tokens i.e. https://www.googleapis.com/auth/cloud-platform
+	// required: only if secret_type is access_token
+	TokenScopes []string `json:"token_scopes" gorm:"-"`
+
+	// VaultPath path of secrets engine mount for GCP. Has to be under path prefix configured for service
+	// required: false. generated from id of connection if not provided
+	VaultPath string `json:"vaultpath" gorm:"-"`
+
+	// VaultNamespace Vault namespace of secrets engine mount for GCP
+	// required: false. namespace configured for service is used if not provided
+	VaultNamespace string `json:"vault_namespace" gorm:"-"`
+}
+
+// GCPConnectionPatchWrapper represents GCPConnection attributes for PATCH request body schema. Role type, secret
+// type, project and service account of role can not be changed as Vault does not allow updating them.
+// swagger:model
+type GCPConnectionPatchWrapper struct {
+	Connection *ConnectionPatchWrapper `json:"connection,omitempty" validate:"omitempty" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+
+	// Credentials JSON key of service account Vault uses to manage GCP
+	// required: false
+	Credentials *string `json:"credentials,omitempty" validate:"omitempty" gorm:"-"`
+
+	// DefaultLeaseTTL default lease ttl of secrets engine mount
+	// required: false
+	DefaultLeaseTTL *string `json:"default_lease_ttl,omitempty" validate:"omitempty" gorm:"-"`
+
+	// MaxLeaseTTL max lease ttl of secrets engine mount
+	// required: false
+	MaxLeaseTTL *string `json:"max_lease_ttl,omitempty" validate:"omitempty" gorm:"-"`
+
+	// Bindings IAM roles granted to service account on GCP resources
+	// required: false
+	Bindings []GCPBinding `json:"bindings,omitempty" validate:"omitempty,dive" gorm:"-"`
+
+	// TokenScopes OAuth scopes of generated access tokens
+	// required: false
+	TokenScopes []string `json:"token_scopes,omitempty" validate:"omitempty" gorm:"-"`
+}
+
+// GCPConnection represents GCPConnection resource serialized by Microservice endpoints
+// swagger:model
+type GCPConnection struct {
+	ID           uuid.UUID  `json:"id" gorm:"primaryKey"`
+	CreatedAt    time.Time  `json:"createdat" gorm:"autoCreateTime;index;not null"`
+	UpdatedAt    time.Time  `json:"updatedat" gorm:"autoUpdateTime;index"`
+	ConnectionID uuid.UUID  `json:"connectionid" gorm:"not null;index;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Connection   Connection `json:"connection" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+
+	// VaultPath for GCP
+	// required: true
+	VaultPath string `json:"vaultpath" validate:"required" gorm:"not null;uniqueIndex:idx_gcp_connections_vault_mount"`
+
+	// VaultNamespace Vault namespace of secrets engine mount for GCP
+	// required: false
+	VaultNamespace string `json:"vault_namespace" gorm:"not null;default:'';uniqueIndex:idx_gcp_connections_vault_mount"`
+
+	// Credentials JSON key of service account Vault uses to manage GCP. Never returned by Vault
+	// required: true
+	Credentials string `json:"credentials" gorm:"-" redact:"true"`
+
+	// DefaultLeaseTTL default lease ttl of secrets engine mount
+	// required: false
+	DefaultLeaseTTL string `json:"default_lease_ttl" gorm:"-"`
+
+	// MaxLeaseTTL max lease ttl of secrets engine mount
+	// required: false
+	MaxLeaseTTL string `json:"max_lease_ttl" gorm:"-"`
+
+	// RoleName name of Vault roleset or static account credentials are generated through
+	// required: true
+	RoleName string `json:"role_name" validate:"required"`
+
+	// RoleType either roleset or static_account
+	// required: true
+	RoleType string `json:"role_type" validate:"required"`
+
+	// SecretType either access_token or service_account_key
+	// required: true
+	SecretType string `json:"secret_type" validate:"required"`
+
+	// Project GCP project of service account
+	// required: false
+	Project string `json:"project" gorm:"-"`
+
+	// ServiceAccountEmail email of service account credentials are generated for
+	// required: false
+	ServiceAccountEmail string `json:"service_account_email" gorm:"-"`
+
+	// Bindings IAM roles granted to service account on GCP resources
+	// required: false
+	Bindings []GCPBinding `json:"bindings" gorm:"-"`
+
+	// TokenScopes OAuth scopes of generated access tokens
+	// required: false
+	TokenScopes []string `json:"token_scopes" gorm:"-"`
+}
+
+// GCPConnectionResponseWrapper represents limited information GCPConnection resource returned by Post, Get and List endpoints
+// swagger:model
+type GCPConnectionResponseWrapper struct {
+	ID           uuid.UUID  `json:"id" gorm:"primaryKey"`
+	CreatedAt    time.Time  `json:"createdat" gorm:"autoCreateTime;index;not null"`
+	UpdatedAt    time.Time  `json:"updatedat" gorm:"autoUpdateTime;index"`
+	ConnectionID uuid.UUID  `json:"connectionid" gorm:"not null;index"`
+	Connection   Connection `json:"connection" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+
+	// VaultPath path of secrets engine mount for GCP
+	// required: true
+	VaultPath string `json:"vaultpath" gorm:"-"`
+
+	// VaultNamespace Vault namespace of secrets engine mount for GCP
+	// required: false
+	VaultNamespace string `json:"vault_namespace" gorm:"-"`
+
+	// DefaultLeaseTTL default lease ttl of secrets engine mount
+	// required: false
+	DefaultLeaseTTL string `json:"default_lease_ttl" gorm:"-"`
+
+	// MaxLeaseTTL max lease ttl of secrets engine mount
+	// required: false
+	MaxLeaseTTL string `json:"max_lease_ttl" gorm:"-"`
+
+	// RoleName name of Vault roleset or static account credentials are generated through
+	// required: true
+	RoleName string `json:"role_name" gorm:"-"`
+
+	// RoleType either roleset or static_account
+	// required: true
+	RoleType string `json:"role_type" gorm:"-"`
+
+	// SecretType either access_token or service_account_key
+	// required: true
+	SecretType string `json:"secret_type" gorm:"-"`
+
+	// Project GCP project of service account
+	// required: false
+	Project string `json:"project" gorm:"-"`
+
+	// ServiceAccountEmail email of service account credentials are generated for
+	// required: false
+	ServiceAccountEmail string `json:"service_account_email" gorm:"-"`
+
+	// Bindings IAM roles granted to service account on GCP resources
+	// required: false
+	Bindings []GCPBinding `json:"bindings" gorm:"-"`
+
+	// TokenScopes OAuth scopes of generated access tokens
+	// required: false
+	TokenScopes []string `json:"token_scopes" gorm:"-"`
+}
+
+// DeleteGCPConnectionResponse represents Response schema for DELETE - DeleteGCPConnection
+// swagger:model
+type DeleteGCPConnectionResponse struct {
+	// Descriptive human readable HTTP status of delete operation.
+	// in: status
+	Status string `json:"status"`
+
+	// HTTP status code for delete operation.
+	// in: statusCode
+	StatusCode int `json:"statusCode"`
+}
+
+// TestGCPConnectionResponse Response schema for GET - TestGCPConnection
+// swagger:model
+type TestGCPConnectionResponse struct {
+	// connectionid for GCPConnection which was tested.
+	// in: id
+	ID string `json:"id"`
+
+	// role_name of role which was tested.
+	// in: role_name
+	RoleName string `json:"role_name"`
+
+	// test status descriptive human readable message.
+	// in: test_status
+	TestStatus string `json:"testStatus"`
+
+	// test_status_code. 1 = connectivity test successful. 0 = connectivity test failed.
+	// in: test_status_code
+	TestStatusCode int `json:"testStatusCode"`
+}
+
+// CredsGCPConnectionResponse Response schema for GET - /gcp/creds. Access tokens are not leased by Vault, so
+// lease attributes are only set for service account keys.
+// swagger:model
+type CredsGCPConnectionResponse struct {
+	// connectionid for GCPConnection which was used to generate credentials
+	// out: id
+	ConnectionID string `json:"connectionid"`
+
+	// LeaseID for generated service account key
+	// out: lease_id
+	LeaseID string `json:"lease_id,omitempty"`
+
+	// LeaseDuration for generated service account key
+	// out: lease_duration
+	LeaseDuration int `json:"lease_duration,omitempty"`
+
+	// Renewable tells whether lease for generated service account key can be renewed
+	// out: renewable
+	Renewable bool `json:"renewable,omitempty"`
+
+	// RoleName of roleset or static account which was used to generate credentials
+	// out: role_name
+	RoleName string `json:"role_name"`
+
+	// SecretType of generated credentials. Either access_token or service_account_key
+	// out: secret_type
+	SecretType string `json:"secret_type"`
+
+	Data struct {
+		// Token OAuth2 access token
+		// out: token
+		Token string `json:"token,omitempty"`
+
+		// TokenTTL lifetime of access token in seconds
+		// out: token_ttl
+		TokenTTL int `json:"token_ttl,omitempty"`
+
+		// ExpiresAtSeconds expiry of access token as unix time
+		// out: expires_at_seconds
+		ExpiresAtSeconds int64 `json:"expires_at_seconds,omitempty"`
+
+		// PrivateKeyData base64 encoded service account key
+		// out: private_key_data
+		PrivateKeyData string `json:"private_key_data,omitempty"`
+
+		// KeyAlgorithm algorithm of service account key
+		// out: key_algorithm
+		KeyAlgorithm string `json:"key_algorithm,omitempty"`
+
+		// KeyType type of service account key
+		// out: key_type
+		KeyType string `json:"key_type,omitempty"`
+	} `json:"data"`
+}
+
+// GCPConnectionsResponse represents GCP Connection attributes which are returned in response of GET on connections/gcp endpoint.
+// swagger:model
+type GCPConnectionsResponse struct {
+	// Number of skipped resources
+	// required: true
+	Skip int `json:"skip"`
+
+	// Limit applied on resources returned
+	// required: true
+	Limit int `json:"limit"`
+
+	// Total number of resources returned
+	// required: true
+	Total int `json:"total"`
+
+	// Connection resource objects
+	// required: true
+	GCPConnections []GCPConnectionResponseWrapper `json:"gcpconnections"`
+}
+
+func NewGCPConnection(cfg *configuration.Config) *GCPConnection {
+	var c GCPConnection
+
+	c.ID = uuid.New()
+	c.Connection.ID = uuid.New()
+	c.ConnectionID = c.Connection.ID
+	c.Connection.ConnectionType = GCPConnectionType
+	c.SetVaultMount("", "", cfg)
+
+	return &c
+}
+
+// SetVaultMount sets path and namespace of secrets engine mount of connection. Path is generated from id of
+// connection and namespace configured for service is used for values not provided.
+func (c *GCPConnection) SetVaultMount(path string, namespace string, cfg *configuration.Config) {
+	c.VaultPath, c.VaultNamespace = vaultMount(path, namespace, "gcp", c.ID, cfg)
+}
+
+// HasRole tells whether connection carries attributes Vault requires for its role type and secret type.
+func (c *GCPConnection) HasRole() bool {
+	switch c.RoleType {
+	case GCPRoleTypeRoleset:
+		if c.Project == "" || len(c.Bindings) == 0 {
+			return false
+		}
+	case GCPRoleTypeStaticAccount:
+		if c.ServiceAccountEmail == "" {
+			return false
+		}
+	default:
+		return false
+	}
+
+	switch c.SecretType {
+	case GCPSecretTypeAccessToken:
+		return len(c.TokenScopes) > 0
+	case GCPSecretTypeServiceAccountKey:
+		return true
+	}
+	return false
+}
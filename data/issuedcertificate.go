@@ -0,0 +1,99 @@
+package data
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// IssuedCertificate represents certificate issued through a PKI connection. Certificates are not leased by Vault,
+// so inventory is the only record of which certificates were issued and when they expire.
+//
+// swagger:model
+type IssuedCertificate struct {
+	ID        uuid.UUID `json:"id" gorm:"primaryKey"`
+	CreatedAt time.Time `json:"createdat" gorm:"autoCreateTime;index;not null"`
+	UpdatedAt time.Time `json:"updatedat" gorm:"autoUpdateTime;index"`
+
+	// ID of generic Connection resource used to issue certificate
+	// required: true
+	ConnectionID uuid.UUID `json:"connectionid" gorm:"not null;index"`
+
+	// ID of application for which certificate was issued
+	// required: false
+	ApplicationID string `json:"applicationid" gorm:"index"`
+
+	// Name of role used to issue certificate
+	// required: false
+	RoleName string `json:"role_name" gorm:"index"`
+
+	// Identity of caller who requested certificate
+	// required: false
+	Requester string `json:"requester" gorm:"index"`
+
+	// ID of request which issued certificate
+	// required: true
+	RequestID string `json:"request_id" gorm:"index;not null"`
+
+	// SerialNumber of certificate in colon separated hex format Vault uses i.e. 1a:2b:3c
+	// required: true
+	SerialNumber string `json:"serial_number" gorm:"uniqueIndex;not null"`
+
+	// CommonName of certificate
+	// required: true
+	CommonName string `json:"common_name" gorm:"index;not null"`
+
+	// AltNames DNS subject alternative names of certificate
+	// required: false
+	AltNames []string `json:"alt_names" gorm:"serializer:json"`
+
+	// Date and time when certificate expires
+	// required: true
+	ExpiresAt time.Time `json:"expiresat" gorm:"index;not null"`
+
+	// Revoked tells whether certificate has been revoked
+	// required: true
+	Revoked bool `json:"revoked" gorm:"index;not null;default:false"`
+
+	// Date and time when certificate was revoked
+	// required: false
+	RevokedAt *time.Time `json:"revokedat"`
+}
+
+// IssuedCertificatesResponse represents IssuedCertificate resources which are returned in response of GET on certificates endpoint.
+//
+// swagger:model
+type IssuedCertificatesResponse struct {
+	// Number of skipped resources
+	// required: true
+	Skip int `json:"skip"`
+
+	// Limit applied on resources returned
+	// required: true
+	Limit int `json:"limit"`
+
+	// Total number of resources returned
+	// required: true
+	Total int `json:"total"`
+
+	// IssuedCertificate resource objects
+	// required: true
+	Certificates []IssuedCertificate `json:"certificates"`
+}
+
+func NewIssuedCertificate(connectionID uuid.UUID, requestID string) *IssuedCertificate {
+	var c IssuedCertificate
+
+	c.ID = uuid.New()
+	c.ConnectionID = connectionID
+	c.RequestID = requestID
+
+	return &c
+}
+
+// SetRevoked marks certificate as revoked.
+func (c *IssuedCertificate) SetRevoked() {
+	now := time.Now().UTC()
+	c.Revoked = true
+	c.RevokedAt = &now
+}
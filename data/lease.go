@@ -0,0 +1,125 @@
+package data
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Lease represents Vault lease issued for dynamic credentials generated through a connection.
+//
+// swagger:model
+type Lease struct {
+	ID        uuid.UUID `json:"id" gorm:"primaryKey"`
+	CreatedAt time.Time `json:"createdat" gorm:"autoCreateTime;index;not null"`
+	UpdatedAt time.Time `json:"updatedat" gorm:"autoUpdateTime;index"`
+
+	// ID of generic Connection resource used to generate credentials
+	// required: true
+	ConnectionID uuid.UUID `json:"connectionid" gorm:"not null;index"`
+
+	// ID of application for which credentials were generated
+	// required: false
+	ApplicationID string `json:"applicationid" gorm:"index"`
+
+	// Name of role used to generate credentials
+	// required: false
+	RoleName string `json:"role_name" gorm:"index"`
+
+	// Identity of caller who requested credentials
+	// required: false
+	Requester string `json:"requester" gorm:"index"`
+
+	// ID of request which generated credentials
+	// required: true
+	RequestID string `json:"request_id" gorm:"index;not null"`
+
+	// LeaseID returned by Vault for generated credentials
+	// required: true
+	LeaseID string `json:"lease_id" gorm:"uniqueIndex;not null"`
+
+	// LeaseDuration in seconds as of issuance or latest renewal
+	// required: true
+	LeaseDuration int `json:"lease_duration"`
+
+	// Renewable tells whether Vault allows renewal of lease
+	// required: true
+	Renewable bool `json:"renewable"`
+
+	// Date and time when lease expires unless renewed
+	// required: true
+	ExpiresAt time.Time `json:"expiresat" gorm:"index;not null"`
+
+	// Revoked tells whether lease has been revoked
+	// required: true
+	Revoked bool `json:"revoked" gorm:"index;not null;default:false"`
+
+	// Date and time when lease was revoked
+	// required: false
+	RevokedAt *time.Time `json:"revokedat"`
+}
+
+// LeasesResponse represents Lease resources which are returned in response of GET on leases endpoint.
+//
+// swagger:model
+type LeasesResponse struct {
+	// Number of skipped resources
+	// required: true
+	Skip int `json:"skip"`
+
+	// Limit applied on resources returned
+	// required: true
+	Limit int `json:"limit"`
+
+	// Total number of resources returned
+	// required: true
+	Total int `json:"total"`
+
+	// Lease resource objects
+	// required: true
+	Leases []Lease `json:"leases"`
+}
+
+// RevokeLeasesResponse represents Response schema for POST - RevokeLeases
+//
+// swagger:model
+type RevokeLeasesResponse struct {
+	// connectionid for Connection whose leases were revoked.
+	// in: connectionid
+	ConnectionID string `json:"connectionid"`
+
+	// Number of tracked leases marked as revoked.
+	// in: revoked
+	Revoked int `json:"revoked"`
+}
+
+// VaultLeaseRenewal represents Vault response for lease renewal.
+type VaultLeaseRenewal struct {
+	LeaseID       string `json:"lease_id"`
+	LeaseDuration int    `json:"lease_duration"`
+	Renewable     bool   `json:"renewable"`
+}
+
+func NewLease(connectionID uuid.UUID, requestID string) *Lease {
+	var l Lease
+
+	l.ID = uuid.New()
+	l.ConnectionID = connectionID
+	l.RequestID = requestID
+
+	return &l
+}
+
+// SetRenewed records lease duration returned by Vault after renewal.
+func (l *Lease) SetRenewed(leaseDuration int, renewable bool) {
+	l.LeaseDuration = leaseDuration
+	l.Renewable = renewable
+	l.ExpiresAt = time.Now().UTC().Add(time.Duration(leaseDuration) * time.Second)
+}
+
+// SetRevoked marks lease as revoked.
+func (l *Lease) SetRevoked() {
+	now := time.Now().UTC()
+	l.Revoked = true
+	l.RevokedAt = &now
+}
@@ -0,0 +1,353 @@
+package data
+
+import (
+	"DemoServer_ConnectionManager/configuration"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Types of CA PKIConnection sets up
+const (
+	PKICATypeRoot         = "root"
+	PKICATypeIntermediate = "intermediate"
+)
+
+// PKIConnectionPostWrapper represents PKIConnection attributes for POST request body schema.
+// swagger:model
+type PKIConnectionPostWrapper struct {
+	Connection ConnectionPostWrapper `json:"connection" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+
+	// CAType type of CA set up in secrets engine. root CA is self signed, intermediate CA is signed by CA of issuer_connectionid
+	// required: true
+	CAType string `json:"ca_type" validate:"required,oneof=root intermediate" gorm:"-"`
+
+	// CommonName common name of CA certificate i.e. Example Services Intermediate CA
+	// required: true
+	CommonName string `json:"common_name" validate:"required" gorm:"-"`
+
+	// IssuerConnectionID id of PKIConnection whose CA signs intermediate CA
+	// required: only if ca_type is intermediate
+	IssuerConnectionID string `json:"issuer_connectionid" validate:"omitempty,uuid" gorm:"-"`
+
+	// KeyType type of key of CA and issued certificates. Vault default rsa is used if not provided
+	// required: false
+	KeyType string `json:"key_type" validate:"omitempty,oneof=rsa ec ed25519" gorm:"-"`
+
+	// DefaultLeaseTTL default lease ttl of secrets engine mount, used as validity of certificates if not requested i.e. 720h
+	// required: false
+	DefaultLeaseTTL string `json:"default_lease_ttl" gorm:"-"`
+
+	// MaxLeaseTTL max lease ttl of secrets engine mount. Used as validity of CA certificate and caps validity of issued certificates i.e. 87600h
+	// required: false
+	MaxLeaseTTL string `json:"max_lease_ttl" gorm:"-"`
+
+	// RoleName name of Vault role certificates are issued through
+	// required: true
+	RoleName string `json:"role_name" validate:"required" gorm:"-"`
+
+	// AllowedDomains domains certificates can be issued for
+	// required: true
+	AllowedDomains []string `json:"allowed_domains" validate:"required,min=1,dive,required" gorm:"-"`
+
+	// AllowSubdomains allows certificates for subdomains of allowed_domains
+	// required: false
+	AllowSubdomains bool `json:"allow_subdomains" gorm:"-"`
+
+	// AllowBareDomains allows certificates for allowed_domains themselves
+	// required: false
+	AllowBareDomains bool `json:"allow_bare_domains" gorm:"-"`
+
+	// VaultPath path of secrets engine mount for CA. Has to be under path prefix configured for service
+	// required: false. generated from id of connection if not provided
+	VaultPath string `json:"vaultpath" gorm:"-"`
+
+	// VaultNamespace Vault namespace of secrets engine mount for CA
+	// required: false. namespace configured for service is used if not provided
+	VaultNamespace string `json:"vault_namespace" gorm:"-"`
+}
+
+// PKIConnectionPatchWrapper represents PKIConnection attributes for PATCH request body schema. CA can not be changed,
+// as services trusting it would stop accepting certificates.
+// swagger:model
+type PKIConnectionPatchWrapper struct {
+	Connection *ConnectionPatchWrapper `json:"connection,omitempty" validate:"omitempty" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+
+	// DefaultLeaseTTL default lease ttl of secrets engine mount
+	// required: false
+	DefaultLeaseTTL *string `json:"default_lease_ttl,omitempty" validate:"omitempty" gorm:"-"`
+
+	// MaxLeaseTTL max lease ttl of secrets engine mount
+	// required: false
+	MaxLeaseTTL *string `json:"max_lease_ttl,omitempty" validate:"omitempty" gorm:"-"`
+
+	// AllowedDomains domains certificates can be issued for
+	// required: false
+	AllowedDomains []string `json:"allowed_domains,omitempty" validate:"omitempty,min=1,dive,required" gorm:"-"`
+
+	// AllowSubdomains allows certificates for subdomains of allowed_domains
+	// required: false
+	AllowSubdomains *bool `json:"allow_subdomains,omitempty" validate:"omitempty" gorm:"-"`
+
+	// AllowBareDomains allows certificates for allowed_domains themselves
+	// required: false
+	AllowBareDomains *bool `json:"allow_bare_domains,omitempty" validate:"omitempty" gorm:"-"`
+}
+
+// PKIConnection represents PKIConnection resource serialized by Microservice endpoints
+// swagger:model
+type PKIConnection struct {
+	ID           uuid.UUID  `json:"id" gorm:"primaryKey"`
+	CreatedAt    time.Time  `json:"createdat" gorm:"autoCreateTime;index;not null"`
+	UpdatedAt    time.Time  `json:"updatedat" gorm:"autoUpdateTime;index"`
+	ConnectionID uuid.UUID  `json:"connectionid" gorm:"not null;index;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Connection   Connection `json:"connection" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+
+	// VaultPath for CA
+	// required: true
+	VaultPath string `json:"vaultpath" validate:"required" gorm:"not null;uniqueIndex:idx_pki_connections_vault_mount"`
+
+	// VaultNamespace Vault namespace of secrets engine mount for CA
+	// required: false
+	VaultNamespace string `json:"vault_namespace" gorm:"not null;default:'';uniqueIndex:idx_pki_connections_vault_mount"`
+
+	// CAType type of CA set up in secrets engine
+	// required: true
+	CAType string `json:"ca_type" validate:"required"`
+
+	// IssuerConnectionID id of PKIConnection whose CA signed intermediate CA
+	// required: false
+	IssuerConnectionID string `json:"issuer_connectionid" gorm:"not null;default:'';index"`
+
+	// CommonName common name of CA certificate
+	// required: true
+	CommonName string `json:"common_name" gorm:"-"`
+
+	// KeyType type of key of CA and issued certificates
+	// required: false
+	KeyType string `json:"key_type" gorm:"-"`
+
+	// Certificate CA certificate in PEM format
+	// required: false
+	Certificate string `json:"certificate" gorm:"-"`
+
+	// DefaultLeaseTTL default lease ttl of secrets engine mount
+	// required: false
+	DefaultLeaseTTL string `json:"default_lease_ttl" gorm:"-"`
+
+	// MaxLeaseTTL max lease ttl of secrets engine mount
+	// required: false
+	MaxLeaseTTL string `json:"max_lease_ttl" gorm:"-"`
+
+	// RoleName name of Vault role certificates are issued through
+	// required: true
+	RoleName string `json:"role_name" validate:"required"`
+
+	// AllowedDomains domains certificates can be issued for
+	// required: true
+	AllowedDomains []string `json:"allowed_domains" gorm:"-"`
+
+	// AllowSubdomains allows certificates for subdomains of allowed_domains
+	// required: false
+	AllowSubdomains bool `json:"allow_subdomains" gorm:"-"`
+
+	// AllowBareDomains allows certificates for allowed_domains themselves
+	// required: false
+	AllowBareDomains bool `json:"allow_bare_domains" gorm:"-"`
+}
+
+// PKIConnectionResponseWrapper represents limited information PKIConnection resource returned by Post, Get and List endpoints
+// swagger:model
+type PKIConnectionResponseWrapper struct {
+	ID           uuid.UUID  `json:"id" gorm:"primaryKey"`
+	CreatedAt    time.Time  `json:"createdat" gorm:"autoCreateTime;index;not null"`
+	UpdatedAt    time.Time  `json:"updatedat" gorm:"autoUpdateTime;index"`
+	ConnectionID uuid.UUID  `json:"connectionid" gorm:"not null;index"`
+	Connection   Connection `json:"connection" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+
+	// VaultPath path of secrets engine mount for CA
+	// required: true
+	VaultPath string `json:"vaultpath" gorm:"-"`
+
+	// VaultNamespace Vault namespace of secrets engine mount for CA
+	// required: false
+	VaultNamespace string `json:"vault_namespace" gorm:"-"`
+
+	// CAType type of CA set up in secrets engine
+	// required: true
+	CAType string `json:"ca_type" gorm:"-"`
+
+	// IssuerConnectionID id of PKIConnection whose CA signed intermediate CA
+	// required: false
+	IssuerConnectionID string `json:"issuer_connectionid" gorm:"-"`
+
+	// CommonName common name of CA certificate
+	// required: true
+	CommonName string `json:"common_name" gorm:"-"`
+
+	// KeyType type of key of CA and issued certificates
+	// required: true
+	KeyType string `json:"key_type" gorm:"-"`
+
+	// Certificate CA certificate in PEM format
+	// required: true
+	Certificate string `json:"certificate" gorm:"-"`
+
+	// DefaultLeaseTTL default lease ttl of secrets engine mount
+	// required: false
+	DefaultLeaseTTL string `json:"default_lease_ttl" gorm:"-"`
+
+	// MaxLeaseTTL max lease ttl of secrets engine mount
+	// required: false
+	MaxLeaseTTL string `json:"max_lease_ttl" gorm:"-"`
+
+	// RoleName name of Vault role certificates are issued through
+	// required: true
+	RoleName string `json:"role_name" gorm:"-"`
+
+	// AllowedDomains domains certificates can be issued for
+	// required: true
+	AllowedDomains []string `json:"allowed_domains" gorm:"-"`
+
+	// AllowSubdomains allows certificates for subdomains of allowed_domains
+	// required: false
+	AllowSubdomains bool `json:"allow_subdomains" gorm:"-"`
+
+	// AllowBareDomains allows certificates for allowed_domains themselves
+	// required: false
+	AllowBareDomains bool `json:"allow_bare_domains" gorm:"-"`
+}
+
+// DeletePKIConnectionResponse represents Response schema for DELETE - DeletePKIConnection
+// swagger:model
+type DeletePKIConnectionResponse struct {
+	// Descriptive human readable HTTP status of delete operation.
+	// in: status
+	Status string `json:"status"`
+
+	// HTTP status code for delete operation.
+	// in: statusCode
+	StatusCode int `json:"statusCode"`
+}
+
+// TestPKIConnectionResponse Response schema for GET - TestPKIConnection
+// swagger:model
+type TestPKIConnectionResponse struct {
+	// connectionid for PKIConnection which was tested.
+	// in: id
+	ID string `json:"id"`
+
+	// role_name of role which was tested.
+	// in: role_name
+	RoleName string `json:"role_name"`
+
+	// test status descriptive human readable message.
+	// in: test_status
+	TestStatus string `json:"testStatus"`
+
+	// test_status_code. 1 = connectivity test successful. 0 = connectivity test failed.
+	// in: test_status_code
+	TestStatusCode int `json:"testStatusCode"`
+}
+
+// IssuePKIConnectionRequest represents request body schema for POST - /pki/issue
+// swagger:model
+type IssuePKIConnectionRequest struct {
+	// CommonName common name of certificate. Has to be allowed by role of connection
+	// required: true
+	CommonName string `json:"common_name" validate:"required"`
+
+	// AltNames DNS subject alternative names of certificate. Have to be allowed by role of connection
+	// required: false
+	AltNames []string `json:"alt_names" validate:"omitempty,dive,required"`
+
+	// IPSANs IP subject alternative names of certificate
+	// required: false
+	IPSANs []string `json:"ip_sans" validate:"omitempty,dive,ip"`
+
+	// TTL requested validity of certificate i.e. 72h. Default lease ttl of secrets engine mount is used if not provided
+	// required: false
+	TTL string `json:"ttl"`
+}
+
+// IssuePKIConnectionResponse Response schema for POST - /pki/issue
+// swagger:model
+type IssuePKIConnectionResponse struct {
+	// connectionid for PKIConnection which was used to issue certificate
+	// out: id
+	ConnectionID string `json:"connectionid"`
+
+	// CertificateID id of certificate in inventory of issued certificates
+	// out: certificateid
+	CertificateID string `json:"certificateid"`
+
+	// RoleName of role which was used to issue certificate
+	// out: role_name
+	RoleName string `json:"role_name"`
+
+	// SerialNumber of issued certificate
+	// out: serial_number
+	SerialNumber string `json:"serial_number"`
+
+	// Certificate issued certificate in PEM format
+	// out: certificate
+	Certificate string `json:"certificate"`
+
+	// IssuingCA certificate of CA which issued certificate in PEM format
+	// out: issuing_ca
+	IssuingCA string `json:"issuing_ca"`
+
+	// CAChain certificates of CA chain in PEM format, starting with issuing CA
+	// out: ca_chain
+	CAChain []string `json:"ca_chain"`
+
+	// PrivateKey private key of certificate in PEM format. Not stored by service or Vault
+	// out: private_key
+	PrivateKey string `json:"private_key"`
+
+	// PrivateKeyType type of private key
+	// out: private_key_type
+	PrivateKeyType string `json:"private_key_type"`
+
+	// ExpiresAt end of validity of certificate
+	// out: expiresat
+	ExpiresAt time.Time `json:"expiresat"`
+}
+
+// PKIConnectionsResponse represents PKI Connection attributes which are returned in response of GET on connections/pki endpoint.
+// swagger:model
+type PKIConnectionsResponse struct {
+	// Number of skipped resources
+	// required: true
+	Skip int `json:"skip"`
+
+	// Limit applied on resources returned
+	// required: true
+	Limit int `json:"limit"`
+
+	// Total number of resources returned
+	// required: true
+	Total int `json:"total"`
+
+	// Connection resource objects
+	// required: true
+	PKIConnections []PKIConnectionResponseWrapper `json:"pkiconnections"`
+}
+
+func NewPKIConnection(cfg *configuration.Config) *PKIConnection {
+	var c PKIConnection
+
+	c.ID = uuid.New()
+	c.Connection.ID = uuid.New()
+	c.ConnectionID = c.Connection.ID
+	c.Connection.ConnectionType = PKIConnectionType
+	c.SetVaultMount("", "", cfg)
+
+	return &c
+}
+
+// SetVaultMount sets path and namespace of secrets engine mount of connection. Path is generated from id of
+// connection and namespace configured for service is used for values not provided.
+func (c *PKIConnection) SetVaultMount(path string, namespace string, cfg *configuration.Config) {
+	c.VaultPath, c.VaultNamespace = vaultMount(path, namespace, "pki", c.ID, cfg)
+}
@@ -0,0 +1,171 @@
+package data
+
+import (
+	"regexp"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// pkiRoleNamePattern restricts role names to characters which are safe to use in Vault paths.
+var pkiRoleNamePattern = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,64}$`)
+
+// PKIRolePostWrapper represents PKIRole attributes for POST request body schema.
+// swagger:model
+type PKIRolePostWrapper struct {
+	// RoleName name of Vault role certificates are issued through
+	// required: true
+	RoleName string `json:"role_name" validate:"required"`
+
+	// AllowedDomains domains certificates can be issued for
+	// required: true
+	AllowedDomains []string `json:"allowed_domains" validate:"required,min=1,dive,required"`
+
+	// AllowSubdomains allows certificates for subdomains of allowed_domains
+	// required: false
+	AllowSubdomains bool `json:"allow_subdomains"`
+
+	// AllowBareDomains allows certificates for allowed_domains themselves
+	// required: false
+	AllowBareDomains bool `json:"allow_bare_domains"`
+
+	// KeyType type of key of issued certificates. Key type of connection is used if not provided
+	// required: false
+	KeyType string `json:"key_type" validate:"omitempty,oneof=rsa ec ed25519"`
+}
+
+// PKIRolePatchWrapper represents PKIRole attributes for PATCH request body schema.
+// swagger:model
+type PKIRolePatchWrapper struct {
+	// AllowedDomains domains certificates can be issued for
+	// required: false
+	AllowedDomains []string `json:"allowed_domains,omitempty" validate:"omitempty,min=1,dive,required"`
+
+	// AllowSubdomains allows certificates for subdomains of allowed_domains
+	// required: false
+	AllowSubdomains *bool `json:"allow_subdomains,omitempty" validate:"omitempty"`
+
+	// AllowBareDomains allows certificates for allowed_domains themselves
+	// required: false
+	AllowBareDomains *bool `json:"allow_bare_domains,omitempty" validate:"omitempty"`
+
+	// KeyType type of key of issued certificates
+	// required: false
+	KeyType *string `json:"key_type,omitempty" validate:"omitempty,oneof=rsa ec ed25519"`
+}
+
+// PKIRole represents named Vault role of PKIConnection. Each role issues certificates for its own allowed
+// domains through CA of connection. Role attributes are kept in Vault, datastore only tracks roles of each
+// PKIConnection.
+// swagger:model
+type PKIRole struct {
+	ID        uuid.UUID `json:"id" gorm:"primaryKey"`
+	CreatedAt time.Time `json:"createdat" gorm:"autoCreateTime;index;not null"`
+	UpdatedAt time.Time `json:"updatedat" gorm:"autoUpdateTime;index"`
+
+	// PKIConnectionID id of PKIConnection this role belongs to
+	// required: true
+	PKIConnectionID uuid.UUID `json:"pkiconnectionid" gorm:"not null;uniqueIndex:idx_pki_roles_connection_role"`
+
+	// RoleName name of Vault role certificates are issued through
+	// required: true
+	RoleName string `json:"role_name" validate:"required" gorm:"not null;uniqueIndex:idx_pki_roles_connection_role"`
+
+	// AllowedDomains domains certificates can be issued for
+	// required: true
+	AllowedDomains []string `json:"allowed_domains" gorm:"-"`
+
+	// AllowSubdomains allows certificates for subdomains of allowed_domains
+	// required: false
+	AllowSubdomains bool `json:"allow_subdomains" gorm:"-"`
+
+	// AllowBareDomains allows certificates for allowed_domains themselves
+	// required: false
+	AllowBareDomains bool `json:"allow_bare_domains" gorm:"-"`
+
+	// KeyType type of key of issued certificates
+	// required: false
+	KeyType string `json:"key_type" gorm:"-"`
+}
+
+// PKIRolesResponse represents PKIRole resources which are returned in response of GET on roles endpoint.
+// swagger:model
+type PKIRolesResponse struct {
+	// Number of skipped resources
+	// required: true
+	Skip int `json:"skip"`
+
+	// Limit applied on resources returned
+	// required: true
+	Limit int `json:"limit"`
+
+	// Total number of resources returned
+	// required: true
+	Total int `json:"total"`
+
+	// PKIRole resource objects
+	// required: true
+	Roles []PKIRole `json:"roles"`
+}
+
+// DeletePKIRoleResponse represents Response schema for DELETE - DeletePKIConnectionRole
+// swagger:model
+type DeletePKIRoleResponse struct {
+	// Descriptive human readable HTTP status of delete operation.
+	// in: status
+	Status string `json:"status"`
+
+	// HTTP status code for delete operation.
+	// in: statusCode
+	StatusCode int `json:"statusCode"`
+}
+
+func NewPKIRole(pkiConnectionID uuid.UUID) *PKIRole {
+	var r PKIRole
+
+	r.ID = uuid.New()
+	r.PKIConnectionID = pkiConnectionID
+
+	return &r
+}
+
+// DefaultRole returns role defined by PKIConnection's own role attributes.
+func (c *PKIConnection) DefaultRole() *PKIRole {
+	r := NewPKIRole(c.ID)
+
+	r.RoleName = c.RoleName
+	r.AllowedDomains = c.AllowedDomains
+	r.AllowSubdomains = c.AllowSubdomains
+	r.AllowBareDomains = c.AllowBareDomains
+	r.KeyType = c.KeyType
+
+	return r
+}
+
+// Name returns name of Vault role PKIRole configures.
+func (r *PKIRole) Name() string {
+	return r.RoleName
+}
+
+// IsValidPKIRoleName tells whether name can be used as name of PKIRole.
+func IsValidPKIRoleName(name string) bool {
+	return pkiRoleNamePattern.MatchString(name)
+}
+
+// AllowsIssuance tells whether role allows issuing certificates for any name. Allowed domains only match names
+// when either domains themselves or their subdomains are allowed.
+func (r *PKIRole) AllowsIssuance() bool {
+	return len(r.AllowedDomains) > 0 && (r.AllowSubdomains || r.AllowBareDomains)
+}
+
+// TestCommonName returns common name a certificate can be issued for through role when testing it.
+func (r *PKIRole) TestCommonName() string {
+	switch {
+	case len(r.AllowedDomains) == 0:
+		return "connectionmgmt-test"
+	case r.AllowSubdomains:
+		return "connectionmgmt-test." + r.AllowedDomains[0]
+	default:
+		return r.AllowedDomains[0]
+	}
+}
@@ -0,0 +1,104 @@
+package data
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Roles which can be bound to subjects. viewer reads connections, operator additionally tests and links them,
+// admin additionally creates, updates and deletes them. credential-consumer only issues credentials.
+const (
+	RoleViewer             = "viewer"
+	RoleOperator           = "operator"
+	RoleAdmin              = "admin"
+	RoleCredentialConsumer = "credential-consumer"
+)
+
+// RoleBindingPostWrapper represents RoleBinding attributes which are allowed in POST request.
+//
+// swagger:model
+type RoleBindingPostWrapper struct {
+	// Subject role is granted to. Matched against sub claim of bearer token
+	// required: true
+	Subject string `json:"subject" validate:"required"`
+
+	// Role granted to subject. One of viewer, operator, admin, credential-consumer
+	// required: true
+	Role string `json:"role" validate:"required,oneof=viewer operator admin credential-consumer"`
+
+	// ID of generic Connection role is scoped to. Role is granted on all connections if omitted
+	// required: false
+	ConnectionID string `json:"connectionid,omitempty" validate:"omitempty,uuid"`
+}
+
+// RoleBinding grants role to subject either globally or on single connection.
+//
+// swagger:model
+type RoleBinding struct {
+	ID        uuid.UUID `json:"id" gorm:"primaryKey"`
+	CreatedAt time.Time `json:"createdat" gorm:"autoCreateTime;index;not null"`
+
+	// Subject role is granted to
+	// required: true
+	Subject string `json:"subject" gorm:"not null;index"`
+
+	// Role granted to subject
+	// required: true
+	Role string `json:"role" gorm:"not null;index"`
+
+	// ID of generic Connection role is scoped to. null if role is granted on all connections
+	// required: false
+	ConnectionID *uuid.UUID `json:"connectionid" gorm:"index"`
+
+	// Identity of caller who created binding
+	// required: false
+	CreatedBy string `json:"createdby"`
+}
+
+// RoleBindingsResponse represents RoleBinding resources which are returned in response of GET on rolebindings endpoint.
+//
+// swagger:model
+type RoleBindingsResponse struct {
+	// Number of skipped resources
+	// required: true
+	Skip int `json:"skip"`
+
+	// Limit applied on resources returned
+	// required: true
+	Limit int `json:"limit"`
+
+	// Total number of resources returned
+	// required: true
+	Total int `json:"total"`
+
+	// RoleBinding resource objects
+	// required: true
+	RoleBindings []RoleBinding `json:"rolebindings"`
+}
+
+func NewRoleBinding(subject string, role string, connectionID *uuid.UUID, createdBy string) *RoleBinding {
+	var b RoleBinding
+
+	b.ID = uuid.New()
+	b.Subject = subject
+	b.Role = role
+	b.ConnectionID = connectionID
+	b.CreatedBy = createdBy
+
+	return &b
+}
+
+// IsGlobal tells whether binding grants role on all connections.
+func (b *RoleBinding) IsGlobal() bool {
+	return b.ConnectionID == nil
+}
+
+// AuditConnectionID returns ID of connection binding is scoped to, or nil uuid for global bindings, for use with
+// audit records.
+func (b *RoleBinding) AuditConnectionID() uuid.UUID {
+	if b.ConnectionID == nil {
+		return uuid.Nil
+	}
+	return *b.ConnectionID
+}
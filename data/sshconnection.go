@@ -0,0 +1,371 @@
+package data
+
+import (
+	"DemoServer_ConnectionManager/configuration"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Types of certificates SSHConnection signs
+const (
+	SSHCertTypeUser = "user"
+	SSHCertTypeHost = "host"
+)
+
+// SSHConnectionPostWrapper represents SSHConnection attributes for POST request body schema.
+// swagger:model
+type SSHConnectionPostWrapper struct {
+	Connection ConnectionPostWrapper `json:"connection" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+
+	// PrivateKey private key of CA certificates are signed with in OpenSSH or PEM format. Vault generates key pair of CA if not provided
+	// required: only if public_key is provided
+	PrivateKey string `json:"private_key" validate:"required_with=PublicKey" gorm:"-"`
+
+	// PublicKey public key of CA in OpenSSH authorized_keys format
+	// required: only if private_key is provided
+	PublicKey string `json:"public_key" validate:"required_with=PrivateKey" gorm:"-"`
+
+	// DefaultLeaseTTL default lease ttl of secrets engine mount, used as validity of certificates if role does not set one i.e. 1h
+	// required: false
+	DefaultLeaseTTL string `json:"default_lease_ttl" gorm:"-"`
+
+	// MaxLeaseTTL max lease ttl of secrets engine mount, caps validity of certificates i.e. 24h
+	// required: false
+	MaxLeaseTTL string `json:"max_lease_ttl" gorm:"-"`
+
+	// RoleName name of Vault role certificates are signed through
+	// required: true
+	RoleName string `json:"role_name" validate:"required" gorm:"-"`
+
+	// CertType type of certificates signed through role. user certificates authenticate users to hosts, host certificates hosts to users
+	// required: true
+	CertType string `json:"cert_type" validate:"required,oneof=user host" gorm:"-"`
+
+	// AllowedUsers principals user certificates can be signed for. * allows any
+	// required: only if cert_type is user
+	AllowedUsers []string `json:"allowed_users" gorm:"-"`
+
+	// DefaultUser principal user certificates are signed for when none is requested
+	// required: false
+	DefaultUser string `json:"default_user" gorm:"-"`
+
+	// AllowedDomains domains host certificates can be signed for
+	// required: only if cert_type is host
+	AllowedDomains []string `json:"allowed_domains" gorm:"-"`
+
+	// AllowSubdomains allows host certificates for subdomains of allowed_domains
+	// required: false
+	AllowSubdomains bool `json:"allow_subdomains" gorm:"-"`
+
+	// DefaultExtensions extensions of signed certificates i.e. permit-pty, permit-port-forwarding
+	// required: false
+	DefaultExtensions []string `json:"default_extensions" gorm:"-"`
+
+	// VaultPath path of secrets engine mount for SSH CA. Has to be under path prefix configured for service
+	// required: false. generated from id of connection if not provided
+	VaultPath string `json:"vaultpath" gorm:"-"`
+
+	// VaultNamespace Vault namespace of secrets engine mount for SSH CA
+	// required: false. namespace configured for service is used if not provided
+	VaultNamespace string `json:"vault_namespace" gorm:"-"`
+}
+
+// SSHConnectionPatchWrapper represents SSHConnection attributes for PATCH request body schema. Key pair of CA can not
+// be changed, as hosts and users trusting it would stop accepting certificates.
+// swagger:model
+type SSHConnectionPatchWrapper struct {
+	Connection *ConnectionPatchWrapper `json:"connection,omitempty" validate:"omitempty" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+
+	// DefaultLeaseTTL default lease ttl of secrets engine mount
+	// required: false
+	DefaultLeaseTTL *string `json:"default_lease_ttl,omitempty" validate:"omitempty" gorm:"-"`
+
+	// MaxLeaseTTL max lease ttl of secrets engine mount
+	// required: false
+	MaxLeaseTTL *string `json:"max_lease_ttl,omitempty" validate:"omitempty" gorm:"-"`
+
+	// AllowedUsers principals user certificates can be signed for
+	// required: false
+	AllowedUsers []string `json:"allowed_users,omitempty" validate:"omitempty" gorm:"-"`
+
+	// DefaultUser principal user certificates are signed for when none is requested
+	// required: false
+	DefaultUser *string `json:"default_user,omitempty" validate:"omitempty" gorm:"-"`
+
+	// AllowedDomains domains host certificates can be signed for
+	// required: false
+	AllowedDomains []string `json:"allowed_domains,omitempty" validate:"omitempty" gorm:"-"`
+
+	// AllowSubdomains allows host certificates for subdomains of allowed_domains
+	// required: false
+	AllowSubdomains *bool `json:"allow_subdomains,omitempty" validate:"omitempty" gorm:"-"`
+
+	// DefaultExtensions extensions of signed certificates
+	// required: false
+	DefaultExtensions []string `json:"default_extensions,omitempty" validate:"omitempty" gorm:"-"`
+}
+
+// SSHConnection represents SSHConnection resource serialized by Microservice endpoints
+// swagger:model
+type SSHConnection struct {
+	ID           uuid.UUID  `json:"id" gorm:"primaryKey"`
+	CreatedAt    time.Time  `json:"createdat" gorm:"autoCreateTime;index;not null"`
+	UpdatedAt    time.Time  `json:"updatedat" gorm:"autoUpdateTime;index"`
+	ConnectionID uuid.UUID  `json:"connectionid" gorm:"not null;index;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Connection   Connection `json:"connection" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+
+	// VaultPath for SSH CA
+	// required: true
+	VaultPath string `json:"vaultpath" validate:"required" gorm:"not null;uniqueIndex:idx_ssh_connections_vault_mount"`
+
+	// VaultNamespace Vault namespace of secrets engine mount for SSH CA
+	// required: false
+	VaultNamespace string `json:"vault_namespace" gorm:"not null;default:'';uniqueIndex:idx_ssh_connections_vault_mount"`
+
+	// PrivateKey private key of CA. Only set when provided on creation and masked in responses
+	// required: false
+	PrivateKey string `json:"private_key" gorm:"-" redact:"true"`
+
+	// PublicKey public key of CA in OpenSSH authorized_keys format
+	// required: false
+	PublicKey string `json:"public_key" gorm:"-"`
+
+	// DefaultLeaseTTL default lease ttl of secrets engine mount
+	// required: false
+	DefaultLeaseTTL string `json:"default_lease_ttl" gorm:"-"`
+
+	// MaxLeaseTTL max lease ttl of secrets engine mount
+	// required: false
+	MaxLeaseTTL string `json:"max_lease_ttl" gorm:"-"`
+
+	// RoleName name of Vault role certificates are signed through
+	// required: true
+	RoleName string `json:"role_name" validate:"required"`
+
+	// CertType type of certificates signed through role
+	// required: true
+	CertType string `json:"cert_type" validate:"required"`
+
+	// AllowedUsers principals user certificates can be signed for
+	// required: false
+	AllowedUsers []string `json:"allowed_users" gorm:"-"`
+
+	// DefaultUser principal user certificates are signed for when none is requested
+	// required: false
+	DefaultUser string `json:"default_user" gorm:"-"`
+
+	// AllowedDomains domains host certificates can be signed for
+	// required: false
+	AllowedDomains []string `json:"allowed_domains" gorm:"-"`
+
+	// AllowSubdomains allows host certificates for subdomains of allowed_domains
+	// required: false
+	AllowSubdomains bool `json:"allow_subdomains" gorm:"-"`
+
+	// DefaultExtensions extensions of signed certificates
+	// required: false
+	DefaultExtensions []string `json:"default_extensions" gorm:"-"`
+}
+
+// SSHConnectionResponseWrapper represents limited information SSHConnection resource returned by Post, Get and List endpoints
+// swagger:model
+type SSHConnectionResponseWrapper struct {
+	ID           uuid.UUID  `json:"id" gorm:"primaryKey"`
+	CreatedAt    time.Time  `json:"createdat" gorm:"autoCreateTime;index;not null"`
+	UpdatedAt    time.Time  `json:"updatedat" gorm:"autoUpdateTime;index"`
+	ConnectionID uuid.UUID  `json:"connectionid" gorm:"not null;index"`
+	Connection   Connection `json:"connection" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+
+	// VaultPath path of secrets engine mount for SSH CA
+	// required: true
+	VaultPath string `json:"vaultpath" gorm:"-"`
+
+	// VaultNamespace Vault namespace of secrets engine mount for SSH CA
+	// required: false
+	VaultNamespace string `json:"vault_namespace" gorm:"-"`
+
+	// PublicKey public key of CA in OpenSSH authorized_keys format
+	// required: true
+	PublicKey string `json:"public_key" gorm:"-"`
+
+	// DefaultLeaseTTL default lease ttl of secrets engine mount
+	// required: false
+	DefaultLeaseTTL string `json:"default_lease_ttl" gorm:"-"`
+
+	// MaxLeaseTTL max lease ttl of secrets engine mount
+	// required: false
+	MaxLeaseTTL string `json:"max_lease_ttl" gorm:"-"`
+
+	// RoleName name of Vault role certificates are signed through
+	// required: true
+	RoleName string `json:"role_name" gorm:"-"`
+
+	// CertType type of certificates signed through role
+	// required: true
+	CertType string `json:"cert_type" gorm:"-"`
+
+	// AllowedUsers principals user certificates can be signed for
+	// required: false
+	AllowedUsers []string `json:"allowed_users" gorm:"-"`
+
+	// DefaultUser principal user certificates are signed for when none is requested
+	// required: false
+	DefaultUser string `json:"default_user" gorm:"-"`
+
+	// AllowedDomains domains host certificates can be signed for
+	// required: false
+	AllowedDomains []string `json:"allowed_domains" gorm:"-"`
+
+	// AllowSubdomains allows host certificates for subdomains of allowed_domains
+	// required: false
+	AllowSubdomains bool `json:"allow_subdomains" gorm:"-"`
+
+	// DefaultExtensions extensions of signed certificates
+	// required: false
+	DefaultExtensions []string `json:"default_extensions" gorm:"-"`
+}
+
+// DeleteSSHConnectionResponse represents Response schema for DELETE - DeleteSSHConnection
+// swagger:model
+type DeleteSSHConnectionResponse struct {
+	// Descriptive human readable HTTP status of delete operation.
+	// in: status
+	Status string `json:"status"`
+
+	// HTTP status code for delete operation.
+	// in: statusCode
+	StatusCode int `json:"statusCode"`
+}
+
+// TestSSHConnectionResponse Response schema for GET - TestSSHConnection
+// swagger:model
+type TestSSHConnectionResponse struct {
+	// connectionid for SSHConnection which was tested.
+	// in: id
+	ID string `json:"id"`
+
+	// role_name of role which was tested.
+	// in: role_name
+	RoleName string `json:"role_name"`
+
+	// test status descriptive human readable message.
+	// in: test_status
+	TestStatus string `json:"testStatus"`
+
+	// test_status_code. 1 = connectivity test successful. 0 = connectivity test failed.
+	// in: test_status_code
+	TestStatusCode int `json:"testStatusCode"`
+}
+
+// SignSSHConnectionRequest represents request body schema for POST - /ssh/sign
+// swagger:model
+type SignSSHConnectionRequest struct {
+	// PublicKey public key to be signed in OpenSSH authorized_keys format
+	// required: true
+	PublicKey string `json:"public_key" validate:"required"`
+
+	// ValidPrincipals users or hosts certificate is valid for. Have to be allowed by role of connection
+	// required: true
+	ValidPrincipals []string `json:"valid_principals" validate:"required,min=1,dive,required"`
+
+	// TTL requested validity of certificate i.e. 30m. Default lease ttl of secrets engine mount is used if not provided
+	// required: false
+	TTL string `json:"ttl"`
+}
+
+// SignSSHConnectionResponse Response schema for POST - /ssh/sign
+// swagger:model
+type SignSSHConnectionResponse struct {
+	// connectionid for SSHConnection which was used to sign certificate
+	// out: id
+	ConnectionID string `json:"connectionid"`
+
+	// RoleName of role which was used to sign certificate
+	// out: role_name
+	RoleName string `json:"role_name"`
+
+	// SerialNumber of signed certificate
+	// out: serial_number
+	SerialNumber string `json:"serial_number"`
+
+	// SignedKey signed certificate in OpenSSH format
+	// out: signed_key
+	SignedKey string `json:"signed_key"`
+
+	// ValidPrincipals users or hosts certificate is valid for
+	// out: valid_principals
+	ValidPrincipals []string `json:"valid_principals"`
+
+	// ValidAfter start of validity window of certificate
+	// out: valid_after
+	ValidAfter time.Time `json:"valid_after"`
+
+	// ValidBefore end of validity window of certificate
+	// out: valid_before
+	ValidBefore time.Time `json:"valid_before"`
+}
+
+// SSHConnectionsResponse represents SSH Connection attributes which are returned in response of GET on connections/ssh endpoint.
+// swagger:model
+type SSHConnectionsResponse struct {
+	// Number of skipped resources
+	// required: true
+	Skip int `json:"skip"`
+
+	// Limit applied on resources returned
+	// required: true
+	Limit int `json:"limit"`
+
+	// Total number of resources returned
+	// required: true
+	Total int `json:"total"`
+
+	// Connection resource objects
+	// required: true
+	SSHConnections []SSHConnectionResponseWrapper `json:"sshconnections"`
+}
+
+func NewSSHConnection(cfg *configuration.Config) *SSHConnection {
+	var c SSHConnection
+
+	c.ID = uuid.New()
+	c.Connection.ID = uuid.New()
+	c.ConnectionID = c.Connection.ID
+	c.Connection.ConnectionType = SSHConnectionType
+	c.SetVaultMount("", "", cfg)
+
+	return &c
+}
+
+// SetVaultMount sets path and namespace of secrets engine mount of connection. Path is generated from id of
+// connection and namespace configured for service is used for values not provided.
+func (c *SSHConnection) SetVaultMount(path string, namespace string, cfg *configuration.Config) {
+	c.VaultPath, c.VaultNamespace = vaultMount(path, namespace, "ssh", c.ID, cfg)
+}
+
+// HasPrincipals tells whether role of connection allows signing certificates for any principal. User certificates
+// need allowed users and host certificates allowed domains.
+func (c *SSHConnection) HasPrincipals() bool {
+	if c.CertType == SSHCertTypeHost {
+		return len(c.AllowedDomains) > 0
+	}
+	return len(c.AllowedUsers) > 0
+}
+
+// TestPrincipal returns principal a certificate can be signed for through role of connection when testing it.
+func (c *SSHConnection) TestPrincipal() string {
+	switch {
+	case c.CertType == SSHCertTypeHost && len(c.AllowedDomains) > 0:
+		if c.AllowSubdomains {
+			return "test." + c.AllowedDomains[0]
+		}
+		return c.AllowedDomains[0]
+	case c.DefaultUser != "":
+		return c.DefaultUser
+	case len(c.AllowedUsers) > 0 && c.AllowedUsers[0] != "*":
+		return c.AllowedUsers[0]
+	default:
+		return "connectionmgmt-test"
+	}
+}
@@ -0,0 +1,31 @@
+package data
+
+import (
+	"time"
+)
+
+// WrapInfo represents Vault response-wrapping token which is returned instead of secret material. Token is
+// single-use and is exchanged for wrapped secret through sys/wrapping/unwrap endpoint of Vault.
+//
+// swagger:model
+type WrapInfo struct {
+	// Single-use token to be presented to Vault to unwrap secret
+	// required: true
+	Token string `json:"token"`
+
+	// Accessor of wrapping token
+	// required: true
+	Accessor string `json:"accessor"`
+
+	// TTL in seconds after which wrapping token expires
+	// required: true
+	TTL int `json:"ttl"`
+
+	// Date and time when wrapping token was created
+	// required: true
+	CreationTime time.Time `json:"creation_time"`
+
+	// Vault namespace wrapping token was created in. Empty for root namespace
+	// required: false
+	Namespace string `json:"namespace,omitempty"`
+}
@@ -11,9 +11,11 @@ import (
 	"DemoServer_ConnectionManager/data"
 	"DemoServer_ConnectionManager/utilities"
 
+	"github.com/google/uuid"
 	"go.opentelemetry.io/otel"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	_ "github.com/lib/pq"
 )
@@ -107,7 +109,43 @@ func NewPostgresDataSource(c *configuration.Config, l *slog.Logger) (*PostgresDa
 }
 
 func (d *PostgresDataSource) AutoMigrate() error {
-	return d.rwdb.AutoMigrate(&data.AWSConnection{}, &data.AuditRecord{})
+	if err := d.rwdb.AutoMigrate(&data.AWSConnection{}, &data.AWSRole{}, &data.AuditRecord{}, &data.Lease{}, &data.ConnectionTestResult{}, &data.RoleBinding{}, &data.ConnectionLink{}, &data.AzureConnection{}, &data.GCPConnection{}, &data.DatabaseConnection{}, &data.SSHConnection{}, &data.PKIConnection{}, &data.PKIRole{}, &data.IssuedCertificate{}); err != nil {
+		return err
+	}
+
+	return d.migrateApplicationsColumn()
+}
+
+// migrateApplicationsColumn moves links kept in applications JSON column of connections table to connection_links
+// table and drops column afterwards. It does nothing once column is gone.
+func (d *PostgresDataSource) migrateApplicationsColumn() error {
+	if !d.rwdb.Migrator().HasColumn(&data.Connection{}, "applications") {
+		return nil
+	}
+
+	return d.rwdb.Transaction(func(tx *gorm.DB) error {
+		var rows []struct {
+			ID           uuid.UUID
+			Applications data.JSONStringArray
+		}
+
+		if err := tx.Table("connections").Select("id", "applications").Find(&rows).Error; err != nil {
+			return err
+		}
+
+		for _, row := range rows {
+			for _, applicationID := range row.Applications {
+				link := data.NewConnectionLink(row.ID, applicationID, "migration")
+				if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(link).Error; err != nil {
+					return err
+				}
+			}
+		}
+
+		d.l.Info("Migrated applications column to connection_links table", "connections", len(rows))
+
+		return tx.Migrator().DropColumn(&data.Connection{}, "applications")
+	})
 }
 
 func (d *PostgresDataSource) RODB() *gorm.DB {
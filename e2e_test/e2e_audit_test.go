@@ -0,0 +1,33 @@
+package e2e_test
+
+import (
+	"net/http"
+)
+
+const (
+	auditPath = "/v1/connectionmgmt/audit"
+)
+
+func (s *EndToEndSuite) TestNegative_Functional_AuditGet_InvalidAction() {
+	ip, port := GetIPAndPort()
+
+	url := prefixHTTP + ip + ":" + port + auditPath + "?action=abc"
+
+	s.funcLease_ErrorResponse(http.MethodGet, url, http.StatusBadRequest, "ConnectionManager_Err_000054")
+}
+
+func (s *EndToEndSuite) TestNegative_Functional_AuditGet_InvalidStatus() {
+	ip, port := GetIPAndPort()
+
+	url := prefixHTTP + ip + ":" + port + auditPath + "?status=abc"
+
+	s.funcLease_ErrorResponse(http.MethodGet, url, http.StatusBadRequest, "ConnectionManager_Err_000055")
+}
+
+func (s *EndToEndSuite) TestNegative_Functional_AuditGet_InvalidTimeRange() {
+	ip, port := GetIPAndPort()
+
+	url := prefixHTTP + ip + ":" + port + auditPath + "?from=yesterday"
+
+	s.funcLease_ErrorResponse(http.MethodGet, url, http.StatusBadRequest, "ConnectionManager_Err_000056")
+}
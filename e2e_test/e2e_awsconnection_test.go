@@ -13,6 +13,8 @@ import (
 	"os"
 	"strconv"
 	"strings"
+
+	"github.com/google/uuid"
 )
 
 const (
@@ -22,10 +24,14 @@ const (
 	addAWSConnectionPath            = "/v1/connectionmgmt/connection/aws"
 	getConnectionsPath              = "/v1/connectionmgmt/connections"
 	getAWSConnectionsPath           = "/v1/connectionmgmt/connections/aws"
-	testAWSConnectionsPath          = "/v1/connectionmgmt/connection/aws/test"
-	credsAWSConnectionsPath         = "/v1/connectionmgmt/connection/aws/creds"
+	testAWSConnectionsPath          = "/v1/connectionmgmt/connection/aws"
+	testAWSConnectionsSuffix        = "/test"
+	credsAWSConnectionsPath         = "/v1/connectionmgmt/connection/aws"
+	credsAWSConnectionsSuffix       = "/creds"
 	deleteAWSConnectionPath         = "/v1/connectionmgmt/connection/aws"
 	updateAWSConnectionsPath        = "/v1/connectionmgmt/connection/aws"
+	rotateRootAWSConnectionPath     = "/v1/connectionmgmt/connection/aws"
+	rotateRootAWSConnectionSuffix   = "/rotate-root"
 )
 
 func (s *EndToEndSuite) funcAddAWSConnection_Load(threadID int, rounds int) {
@@ -165,7 +171,7 @@ func (s *EndToEndSuite) funcUpdateAWSConnection_Load() {
 			s.Equal(rc.Connection.ConnectionType, data.AWSConnectionType, "Unexpected connectiontype")
 			s.Equal(rc.Connection.TestSuccessful, 0, "Unexpected TestSuccessful state. Expected: %d, Actual: %d", 0, rc.Connection.TestSuccessful)
 			s.Equal(rc.Connection.TestError, "", "Unexpected TestError state. Expected: %s, Actual: %s", "EmptyString", rc.Connection.TestError)
-			s.Equal(*obj.AccessKey, rc.AccessKey, "Unexpected URL. Expected %s, Actual: %s", *obj.AccessKey, rc.AccessKey)
+			s.Equal(helper.MaskValue(*obj.AccessKey), rc.AccessKey, "Unexpected AccessKey. Expected %s, Actual: %s", helper.MaskValue(*obj.AccessKey), rc.AccessKey)
 			s.Equal(strDefaultRegion, rc.DefaultRegion, "Unexpected DefaultRegion. Expected %s, Actual: %s", strDefaultRegion, rc.DefaultRegion)
 			s.Equal(*obj.DefaultLeaseTTL, rc.DefaultLeaseTTL, "Unexpected DefaultLeaseTTL. Expected %s, Actual: %s", *obj.DefaultLeaseTTL, rc.DefaultLeaseTTL)
 			s.Equal(*obj.MaxLeaseTTL, rc.MaxLeaseTTL, "Unexpected MaxLeaseTTL. Expected %s, Actual: %s", *obj.MaxLeaseTTL, rc.MaxLeaseTTL)
@@ -234,7 +240,8 @@ func (s *EndToEndSuite) funcDeleteAWSConnections_All(limit ...int) {
 		}
 
 		for i, jc := range rc.AWSConnections {
-			req, err := http.NewRequest("DELETE", prefixHTTP+ip+":"+port+deleteAWSConnectionPath+"/"+strings.ToLower(jc.ID.String()), nil)
+			// Connections linked to applications are only deleted when forced
+			req, err := http.NewRequest("DELETE", prefixHTTP+ip+":"+port+deleteAWSConnectionPath+"/"+strings.ToLower(jc.ID.String())+"?force=true", nil)
 			if err != nil {
 				s.True(false, "Delete request creation failed")
 			}
@@ -263,7 +270,7 @@ func (s *EndToEndSuite) funcDeleteAWSConnections_All(limit ...int) {
 
 			_ = r.Body.Close()
 
-			diff := JSONCompare(`{"status": "No Content", "statusCode": 204}`, string(b))
+			diff := JSONCompare(`{"status": "OK", "statusCode": 200}`, string(b))
 			s.Equal("", diff, "JSON Response comparison failed. Expected no differences. Found: %s", diff)
 
 			if i%1000 == 0 {
@@ -366,7 +373,7 @@ func (s *EndToEndSuite) funcTestAWSConnection(connectionid string) {
 
 	ip, port := GetIPAndPort()
 
-	r, err := c.Get(prefixHTTP + ip + ":" + port + testAWSConnectionsPath + "/" + connectionid)
+	r, err := c.Get(prefixHTTP + ip + ":" + port + testAWSConnectionsPath + "/" + connectionid + testAWSConnectionsSuffix)
 
 	if err != nil {
 		fmt.Printf("Get request received error: %s\n", err.Error())
@@ -398,12 +405,26 @@ func (s *EndToEndSuite) funcTestAWSConnection(connectionid string) {
 	s.Equal(rc.TestStatusCode, 1, "TestStatusCode comparison failed. Expected: %d, Received: %d", 1, rc.TestStatusCode)
 }
 
-func (s *EndToEndSuite) funcCredsAWSConnection_Negative(connectionid string) {
+// funcCredsAWSConnection_Negative requests credentials of connection on behalf of application and verifies that
+// request is refused with expectedStatus and expectedErrorCode.
+func (s *EndToEndSuite) funcCredsAWSConnection_Negative(connectionid string, applicationid string, expectedStatus int, expectedErrorCode string, ttl ...string) {
 	c := http.Client{}
 
 	ip, port := GetIPAndPort()
 
-	r, err := c.Get(prefixHTTP + ip + ":" + port + credsAWSConnectionsPath + "/" + connectionid)
+	url := prefixHTTP + ip + ":" + port + credsAWSConnectionsPath + "/" + connectionid + credsAWSConnectionsSuffix + "?applicationid=" + applicationid
+
+	if len(ttl) > 0 {
+		url += "&ttl=" + ttl[0]
+	}
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		s.True(false, "Request creation failed")
+	}
+	req.Header.Set("X-Requester", applicationid)
+
+	r, err := c.Do(req)
 
 	if err != nil {
 		fmt.Printf("Get request received error: %s\n", err.Error())
@@ -417,7 +438,7 @@ func (s *EndToEndSuite) funcCredsAWSConnection_Negative(connectionid string) {
 
 	defer func() { _ = r.Body.Close() }()
 
-	s.Equal(http.StatusOK, r.StatusCode, "HTTP Status Code comparison failed. Expected %d, Received: %", http.StatusOK, r.StatusCode)
+	s.Equal(expectedStatus, r.StatusCode, "HTTP Status Code comparison failed. Expected %d, Received: %d", expectedStatus, r.StatusCode)
 	requestid := r.Header.Get("X-Request-Id")
 	s.NotEqual(requestid, "", "X-Request-ID Header not returned by endpoint. X-Request-ID received: %s", requestid)
 
@@ -431,20 +452,33 @@ func (s *EndToEndSuite) funcCredsAWSConnection_Negative(connectionid string) {
 	}
 
 	s.NotEmpty(rc.Timestamp, "Timestamp empty")
-	s.Equal(rc.Status, 500, "Status. Expected: %d, Received: %d", 500, rc.Status)
-	s.Equal(rc.ErrorCode, "ConnectionManager_Err_000032", "Unexpected error code. Expected: %s, Received: %s", "ConnectionManager_Err_000032", rc.ErrorCode)
+	s.Equal(rc.Status, expectedStatus, "Status. Expected: %d, Received: %d", expectedStatus, rc.Status)
+	s.Equal(rc.ErrorCode, expectedErrorCode, "Unexpected error code. Expected: %s, Received: %s", expectedErrorCode, rc.ErrorCode)
 	s.NotEmpty(rc.ErrorDescription, "ErrorDescription empty")
 	s.NotEmpty(rc.Endpoint, "Endpoint empty")
 	s.NotEmpty(rc.Method, "Method empty")
 	s.NotEmpty(rc.RequestID, "RequestID empty")
 }
 
-func (s *EndToEndSuite) funcCredsAWSConnection(connectionid string) {
+// funcCredsAWSConnection requests credentials of connection on behalf of linked application and returns them.
+func (s *EndToEndSuite) funcCredsAWSConnection(connectionid string, applicationid string, ttl ...string) data.CredsAWSConnectionResponse {
 	c := http.Client{}
 
 	ip, port := GetIPAndPort()
 
-	r, err := c.Get(prefixHTTP + ip + ":" + port + credsAWSConnectionsPath + "/" + connectionid)
+	url := prefixHTTP + ip + ":" + port + credsAWSConnectionsPath + "/" + connectionid + credsAWSConnectionsSuffix + "?applicationid=" + applicationid
+
+	if len(ttl) > 0 {
+		url += "&ttl=" + ttl[0]
+	}
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		s.True(false, "Request creation failed")
+	}
+	req.Header.Set("X-Requester", applicationid)
+
+	r, err := c.Do(req)
 
 	if err != nil {
 		fmt.Printf("Get request received error: %s\n", err.Error())
@@ -474,9 +508,75 @@ func (s *EndToEndSuite) funcCredsAWSConnection(connectionid string) {
 	s.NotEmpty(rc.ConnectionID, "ID empty")
 	s.Equal(rc.ConnectionID, connectionid, "Unexpected ConnectionID in response. Expected: %s, Received: %s", connectionid, rc.ConnectionID)
 	s.NotEmpty(rc.LeaseID, "LeaseID empty")
+	s.NotZero(rc.LeaseDuration, "LeaseDuration empty")
 	s.NotEmpty(rc.Data.AccessKey, "AccessKey empty")
 	s.NotEmpty(rc.Data.SecretKey, "AccessKey empty")
-	s.NotEmpty(rc.Data.SessionToken, "SessionToken is not empty. It was supposed to be empty")
+
+	return rc
+}
+
+// funcGetAWSConnection returns AWSConnection with id.
+func (s *EndToEndSuite) funcGetAWSConnection(id string) data.AWSConnectionResponseWrapper {
+	ip, port := GetIPAndPort()
+
+	b := s.funcAWSRole_Request(http.MethodGet, prefixHTTP+ip+":"+port+addAWSConnectionPath+"/"+id, nil)
+
+	var rc data.AWSConnectionResponseWrapper
+
+	err := json.Unmarshal(b, &rc)
+	if err != nil {
+		s.True(false, "Error unmarshalling response into JSON:", err)
+	}
+
+	s.Equal(id, rc.ID.String(), "Unexpected ID. Expected: %s, Received: %s", id, rc.ID.String())
+
+	return rc
+}
+
+func (s *EndToEndSuite) funcCredsAWSConnection_InvalidTTL(connectionid string, ttl string) {
+	s.funcCredsAWSConnection_InvalidParam(connectionid, "applicationid="+uuid.New().String()+"&ttl="+ttl, "ConnectionManager_Err_000039")
+}
+
+func (s *EndToEndSuite) funcCredsAWSConnection_InvalidWrapTTL(connectionid string, wrapTTL string) {
+	s.funcCredsAWSConnection_InvalidParam(connectionid, "applicationid="+uuid.New().String()+"&wrap_ttl="+wrapTTL, "ConnectionManager_Err_000061")
+}
+
+// funcCredsAWSConnection_InvalidParam requests credentials of connection with query and verifies that request is
+// rejected with errorCode.
+func (s *EndToEndSuite) funcCredsAWSConnection_InvalidParam(connectionid string, query string, errorCode string) {
+	c := http.Client{}
+
+	ip, port := GetIPAndPort()
+
+	r, err := c.Get(prefixHTTP + ip + ":" + port + credsAWSConnectionsPath + "/" + connectionid + credsAWSConnectionsSuffix + "?" + query)
+
+	if err != nil {
+		fmt.Printf("Get request received error: %s\n", err.Error())
+		s.True(false)
+	} else {
+		if r == nil {
+			fmt.Printf("No error but resonse object is nil.\n")
+			s.True(false)
+		}
+	}
+
+	defer func() { _ = r.Body.Close() }()
+
+	s.Equal(http.StatusBadRequest, r.StatusCode, "HTTP Status Code comparison failed. Expected %d, Received: %d", http.StatusBadRequest, r.StatusCode)
+	requestid := r.Header.Get("X-Request-Id")
+	s.NotEqual(requestid, "", "X-Request-ID Header not returned by endpoint. X-Request-ID received: %s", requestid)
+
+	b, _ := io.ReadAll(r.Body)
+
+	var rc helper.ErrorResponse
+
+	err = json.Unmarshal(b, &rc)
+	if err != nil {
+		s.True(false, "Error unmarshalling response into JSON:", err)
+	}
+
+	s.Equal(rc.Status, http.StatusBadRequest, "Status. Expected: %d, Received: %d", http.StatusBadRequest, rc.Status)
+	s.Equal(rc.ErrorCode, errorCode, "Unexpected error code. Expected: %s, Received: %s", errorCode, rc.ErrorCode)
 }
 
 func (s *EndToEndSuite) funcGetAWSConnection_Nth(skip int) *data.AWSConnectionResponseWrapper {
@@ -641,7 +741,7 @@ func (s *EndToEndSuite) funcAddAWSConnection(dummy data.AWSConnectionPostWrapper
 	s.Equal(rc.Connection.TestedOn, "", "Unexpected testedon")
 	s.Equal(rc.Connection.LastSuccessfulTest, "", "Unexpected lastsuccessfultest")
 
-	s.Equal(rc.AccessKey, jc.AccessKey, "Unexpected URL")
+	s.Equal(rc.AccessKey, helper.MaskValue(jc.AccessKey), "Unexpected AccessKey")
 	s.Equal(rc.DefaultRegion, jc.DefaultRegion, "Unexpected DefaultRegion")
 	s.Equal(rc.DefaultLeaseTTL, jc.DefaultLeaseTTL, "Unexpected DefaultLeaseTTL")
 	s.Equal(rc.MaxLeaseTTL, jc.MaxLeaseTTL, "Unexpected MaxLeaseTTL")
@@ -652,6 +752,44 @@ func (s *EndToEndSuite) funcAddAWSConnection(dummy data.AWSConnectionPostWrapper
 	return rc.ID.String()
 }
 
+func (s *EndToEndSuite) funcAddAWSConnection_Negative(jc data.AWSConnectionPostWrapper, ip string, port string, expectedErrorCode string) {
+	c := http.Client{}
+
+	jsonData, err := json.Marshal(jc)
+	if err != nil {
+		s.True(false, "Error marshalling JSON:", err)
+	}
+
+	r, err := c.Post(prefixHTTP+ip+":"+port+addAWSConnectionPath, "application/json", bytes.NewBuffer(jsonData))
+
+	if err != nil {
+		fmt.Printf("Post request received error: %s\n", err.Error())
+		s.True(false)
+	} else {
+		if r == nil {
+			fmt.Printf("No error but resonse object is nil.\n")
+			s.True(false)
+		}
+	}
+
+	defer func() { _ = r.Body.Close() }()
+
+	requestid := r.Header.Get("X-Request-Id")
+	s.NotEqual(requestid, "", "X-Request-ID Header not returned by endpoint. X-Request-ID received: %s", requestid)
+
+	b, _ := io.ReadAll(r.Body)
+
+	var rc helper.ErrorResponse
+
+	err = json.Unmarshal(b, &rc)
+	if err != nil {
+		s.True(false, "Error unmarshalling response into JSON:", err)
+	}
+
+	s.Equal(rc.Status, http.StatusBadRequest, "Status. Expected: %d, Received: %d", http.StatusBadRequest, rc.Status)
+	s.Equal(rc.ErrorCode, expectedErrorCode, "Unexpected error code. Expected: %s, Received: %s", expectedErrorCode, rc.ErrorCode)
+}
+
 func (s *EndToEndSuite) func_VerifyAWSConnection_Nth(i int, suffix string) {
 	suffix = suffix + strconv.Itoa(i)
 	c := s.funcGetAWSConnection_Nth(i)
@@ -710,60 +848,137 @@ func (s *EndToEndSuite) TestPositive_Functional_AWSConnectionsGet_Skip() {
 	s.funcDeleteAWSConnections_All()
 }
 
-/*
-	func (s *EndToEndSuite) TestPositive_Functional_AWSConnectionTest() {
-		threadID := 1
-		strThreadID := strUnderscore + strconv.Itoa(threadID) + strUnderscore
+func (s *EndToEndSuite) TestPositive_Functional_AWSConnectionTest() {
+	threadID := 1
+	strThreadID := strUnderscore + strconv.Itoa(threadID) + strUnderscore
 
-		s.funcDeleteAWSConnections_All()
+	s.funcDeleteAWSConnections_All()
 
-		dummy := s.funcLoadDummyAWSConnection("../testdata/aws_connection.json")
-		ip, port := GetIPAndPort()
-		suffix := strThreadID + strconv.Itoa(1)
+	dummy := s.funcLoadDummyAWSConnection("../testdata/aws_connection.json")
+	ip, port := GetIPAndPort()
+	suffix := strThreadID + strconv.Itoa(1)
 
-		connectionid := s.funcAddAWSConnection(dummy, suffix, ip, port)
+	connectionid := s.funcAddAWSConnection(dummy, suffix, ip, port)
 
-		s.funcTestAWSConnection(connectionid)
+	s.funcTestAWSConnection(connectionid)
 
-		s.funcDeleteAWSConnections_All()
-	}
+	s.funcDeleteAWSConnections_All()
+}
 
-	func (s *EndToEndSuite) TestPositive_Functional_AWSConnectionCreds() {
-		threadID := 1
-		strThreadID := strUnderscore + strconv.Itoa(threadID) + strUnderscore
+// TestPositive_Functional_AWSConnectionCreds issues credentials to linked application and verifies that lease of
+// credentials is tracked for application while applications not linked to connection are refused.
+func (s *EndToEndSuite) TestPositive_Functional_AWSConnectionCreds() {
+	threadID := 1
+	strThreadID := strUnderscore + strconv.Itoa(threadID) + strUnderscore
 
-		s.funcDeleteAWSConnections_All()
+	s.funcDeleteAWSConnections_All()
 
-		dummy := s.funcLoadDummyAWSConnection("../testdata/aws_connection.json")
-		ip, port := GetIPAndPort()
-		suffix := strThreadID + strconv.Itoa(1)
+	dummy := s.funcLoadDummyAWSConnection("../testdata/aws_connection.json")
+	ip, port := GetIPAndPort()
+	suffix := strThreadID + strconv.Itoa(1)
 
-		connectionid := s.funcAddAWSConnection(dummy, suffix, ip, port)
+	connectionid := s.funcAddAWSConnection(dummy, suffix, ip, port)
+	genericid := s.funcGetAWSConnection(connectionid).ConnectionID.String()
 
-		s.funcTestAWSConnection(connectionid)
+	applicationid := uuid.New().String()
+	s.funcLinkConnection(genericid, applicationid)
 
-		s.funcCredsAWSConnection(connectionid)
+	s.funcTestAWSConnection(connectionid)
 
-		s.funcDeleteAWSConnections_All()
-	}
+	creds := s.funcCredsAWSConnection(connectionid, applicationid)
+
+	leases := s.funcGetConnectionLeases(genericid)
+	s.Equal(1, leases.Total, "Unexpected number of leases. Expected: %d, Received: %d", 1, leases.Total)
+	s.Require().Len(leases.Leases, 1, "Unexpected number of leases")
+	s.Equal(creds.LeaseID, leases.Leases[0].LeaseID, "Unexpected LeaseID. Expected: %s, Received: %s", creds.LeaseID, leases.Leases[0].LeaseID)
+	s.Equal(applicationid, leases.Leases[0].ApplicationID, "Unexpected ApplicationID. Expected: %s, Received: %s", applicationid, leases.Leases[0].ApplicationID)
+
+	s.funcCredsAWSConnection_Negative(connectionid, uuid.New().String(), http.StatusForbidden, "ConnectionManager_Err_000072")
+
+	s.funcDeleteAWSConnections_All()
+}
 
-	func (s *EndToEndSuite) TestNegative_Functional_AWSConnectionCreds() {
-		threadID := 1
-		strThreadID := strUnderscore + strconv.Itoa(threadID) + strUnderscore
+// TestNegative_Functional_AWSConnectionCreds verifies that credentials are refused to linked application until
+// connection is tested successfully.
+func (s *EndToEndSuite) TestNegative_Functional_AWSConnectionCreds() {
+	threadID := 1
+	strThreadID := strUnderscore + strconv.Itoa(threadID) + strUnderscore
 
-		s.funcDeleteAWSConnections_All()
+	s.funcDeleteAWSConnections_All()
 
-		dummy := s.funcLoadDummyAWSConnection("../testdata/aws_connection.json")
-		ip, port := GetIPAndPort()
-		suffix := strThreadID + strconv.Itoa(1)
+	dummy := s.funcLoadDummyAWSConnection("../testdata/aws_connection.json")
+	ip, port := GetIPAndPort()
+	suffix := strThreadID + strconv.Itoa(1)
 
-		connectionid := s.funcAddAWSConnection(dummy, suffix, ip, port)
+	connectionid := s.funcAddAWSConnection(dummy, suffix, ip, port)
 
-		s.funcCredsAWSConnection_Negative(connectionid)
+	applicationid := uuid.New().String()
+	s.funcLinkConnection(s.funcGetAWSConnection(connectionid).ConnectionID.String(), applicationid)
+
+	s.funcCredsAWSConnection_Negative(connectionid, applicationid, http.StatusConflict, "ConnectionManager_Err_000032")
+
+	s.funcDeleteAWSConnections_All()
+}
+
+func (s *EndToEndSuite) TestNegative_Functional_AWSConnectionAdd_AssumedRoleWithoutRoleARNs() {
+	ip, port := GetIPAndPort()
+
+	jc := s.funcLoadDummyAWSConnection("../testdata/aws_connection_assumed_role.json")
+	jc.RoleARNs = nil
+
+	s.funcAddAWSConnection_Negative(jc, ip, port, "ConnectionManager_Err_000046")
+}
+
+func (s *EndToEndSuite) TestNegative_Functional_AWSConnectionAdd_FederationTokenInvalidPolicyDocument() {
+	ip, port := GetIPAndPort()
+
+	jc := s.funcLoadDummyAWSConnection("../testdata/aws_connection_federation_token.json")
+	jc.PolicyDocument = "not a json document"
+
+	s.funcAddAWSConnection_Negative(jc, ip, port, "ConnectionManager_Err_000047")
+}
+
+func (s *EndToEndSuite) TestNegative_Functional_AWSConnectionAdd_SessionTagsNotSupported() {
+	ip, port := GetIPAndPort()
+
+	jc := s.funcLoadDummyAWSConnection("../testdata/aws_connection.json")
+	jc.SessionTags = map[string]string{"team": "demoserver"}
+
+	s.funcAddAWSConnection_Negative(jc, ip, port, "ConnectionManager_Err_000048")
+}
+
+// TestNegative_Functional_AWSConnectionCreds_IAMUserTTL verifies that ttl is refused for iam_user credentials,
+// whose lease is governed by tune settings of secrets engine mount.
+func (s *EndToEndSuite) TestNegative_Functional_AWSConnectionCreds_IAMUserTTL() {
+	threadID := 1
+	strThreadID := strUnderscore + strconv.Itoa(threadID) + strUnderscore
+
+	s.funcDeleteAWSConnections_All()
+
+	dummy := s.funcLoadDummyAWSConnection("../testdata/aws_connection.json")
+	ip, port := GetIPAndPort()
+	suffix := strThreadID + strconv.Itoa(1)
+
+	connectionid := s.funcAddAWSConnection(dummy, suffix, ip, port)
+
+	applicationid := uuid.New().String()
+	s.funcLinkConnection(s.funcGetAWSConnection(connectionid).ConnectionID.String(), applicationid)
+
+	s.funcTestAWSConnection(connectionid)
+
+	s.funcCredsAWSConnection_Negative(connectionid, applicationid, http.StatusBadRequest, "ConnectionManager_Err_000039", "15m")
+
+	s.funcDeleteAWSConnections_All()
+}
+
+func (s *EndToEndSuite) TestNegative_Functional_AWSConnectionCreds_InvalidTTL() {
+	connectionid := uuid.New().String()
+
+	s.funcCredsAWSConnection_InvalidTTL(connectionid, "abc")
+	s.funcCredsAWSConnection_InvalidTTL(connectionid, "-5")
+	s.funcCredsAWSConnection_InvalidTTL(connectionid, "0s")
+}
 
-		s.funcDeleteAWSConnections_All()
-	}
-*/
 func (s *EndToEndSuite) TestPositive_Functional_AWSConnectionsGet_Limit() {
 	limit := 5
 	total := limit * 2
@@ -1041,3 +1256,96 @@ func (s *EndToEndSuite) TestPositive_Functional_ConnectionsGet_SkipAndLimit() {
 
 	s.funcDeleteAWSConnections_All(3)
 }
+
+func (s *EndToEndSuite) TestNegative_Functional_AWSConnectionAdd_VaultPathOutsidePrefix() {
+	ip, port := GetIPAndPort()
+
+	jc := s.funcLoadDummyAWSConnection("../testdata/aws_connection.json")
+	jc.VaultPath = "aws"
+
+	s.funcAddAWSConnection_Negative(jc, ip, port, "ConnectionManager_Err_000058")
+}
+
+func (s *EndToEndSuite) TestNegative_Functional_AWSConnectionAdd_InvalidVaultNamespace() {
+	ip, port := GetIPAndPort()
+
+	jc := s.funcLoadDummyAWSConnection("../testdata/aws_connection.json")
+	jc.VaultNamespace = "bu1/../root"
+
+	s.funcAddAWSConnection_Negative(jc, ip, port, "ConnectionManager_Err_000059")
+}
+
+func (s *EndToEndSuite) TestNegative_Functional_AWSConnectionCreds_InvalidWrapTTL() {
+	connectionid := uuid.New().String()
+
+	s.funcCredsAWSConnection_InvalidWrapTTL(connectionid, "abc")
+	s.funcCredsAWSConnection_InvalidWrapTTL(connectionid, "0")
+}
+
+func (s *EndToEndSuite) TestNegative_Functional_AWSConnectionDelete_InvalidForce() {
+	ip, port := GetIPAndPort()
+
+	url := prefixHTTP + ip + ":" + port + deleteAWSConnectionPath + "/" + uuid.New().String() + "?force=abc"
+
+	s.funcLease_ErrorResponse(http.MethodDelete, url, http.StatusBadRequest, "ConnectionManager_Err_000077")
+}
+
+func (s *EndToEndSuite) TestNegative_Functional_AWSConnectionCreds_MissingApplicationID() {
+	connectionid := uuid.New().String()
+
+	s.funcCredsAWSConnection_InvalidParam(connectionid, "ttl=15m", "ConnectionManager_Err_000070")
+}
+
+func (s *EndToEndSuite) TestNegative_Functional_AWSConnectionCreds_InvalidApplicationID() {
+	connectionid := uuid.New().String()
+
+	s.funcCredsAWSConnection_InvalidParam(connectionid, "applicationid=abc", "ConnectionManager_Err_000033")
+}
+
+func (s *EndToEndSuite) TestNegative_Functional_AWSConnectionRotateRoot_ConnectionNotFound() {
+	ip, port := GetIPAndPort()
+
+	url := prefixHTTP + ip + ":" + port + rotateRootAWSConnectionPath + "/" + uuid.New().String() + rotateRootAWSConnectionSuffix
+
+	s.funcLease_ErrorResponse(http.MethodPost, url, http.StatusNotFound, "ConnectionManager_Err_000002")
+}
+
+func (s *EndToEndSuite) TestNegative_Functional_AWSConnectionGet_RevealWithoutScope() {
+	ip, port := GetIPAndPort()
+
+	url := prefixHTTP + ip + ":" + port + getAWSConnectionsPath + "?reveal=true"
+
+	s.funcLease_ErrorResponse(http.MethodGet, url, http.StatusForbidden, "ConnectionManager_Err_000065")
+}
+
+func (s *EndToEndSuite) TestNegative_Functional_AWSConnectionGet_RevealScopeHeader() {
+	ip, port := GetIPAndPort()
+
+	c := http.Client{}
+
+	req, err := http.NewRequest(http.MethodGet, prefixHTTP+ip+":"+port+getAWSConnectionsPath+"?reveal=true", nil)
+	if err != nil {
+		s.True(false, "Request creation failed")
+	}
+	req.Header.Set("X-Requester-Scopes", "connectionmgmt:reveal")
+
+	r, err := c.Do(req)
+	if err != nil {
+		s.True(false, "Get request received error:", err)
+	}
+
+	defer func() { _ = r.Body.Close() }()
+
+	b, _ := io.ReadAll(r.Body)
+
+	// Scope is honoured only from verified bearer token, never from header
+	s.funcAWSRole_Error(b, http.StatusForbidden, "ConnectionManager_Err_000065")
+}
+
+func (s *EndToEndSuite) TestNegative_Functional_AWSConnectionGet_InvalidReveal() {
+	ip, port := GetIPAndPort()
+
+	url := prefixHTTP + ip + ":" + port + getAWSConnectionsPath + "?reveal=abc"
+
+	s.funcLease_ErrorResponse(http.MethodGet, url, http.StatusBadRequest, "ConnectionManager_Err_000064")
+}
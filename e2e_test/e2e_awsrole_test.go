@@ -0,0 +1,289 @@
+package e2e_test
+
+import (
+	"DemoServer_ConnectionManager/data"
+	"DemoServer_ConnectionManager/helper"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+const (
+	rolesAWSConnectionPath   = "/v1/connectionmgmt/connection/aws"
+	rolesAWSConnectionSuffix = "/roles"
+)
+
+// funcAWSRole_Request sends request with optional JSON payload and returns body of response.
+func (s *EndToEndSuite) funcAWSRole_Request(method string, url string, payload interface{}) []byte {
+	c := http.Client{}
+
+	var body io.Reader
+	if payload != nil {
+		jsonData, err := json.Marshal(payload)
+		if err != nil {
+			s.True(false, "Error marshalling payload into JSON:", err)
+		}
+		body = bytes.NewBuffer(jsonData)
+	}
+
+	req, err := http.NewRequest(method, url, body)
+	if err != nil {
+		s.True(false, "Request creation failed")
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	r, err := c.Do(req)
+
+	if err != nil {
+		fmt.Printf("%s request received error: %s\n", method, err.Error())
+		s.True(false)
+	} else {
+		if r == nil {
+			fmt.Printf("No error but resonse object is nil.\n")
+			s.True(false)
+		}
+	}
+
+	defer func() { _ = r.Body.Close() }()
+
+	requestid := r.Header.Get("X-Request-Id")
+	s.NotEqual(requestid, "", "X-Request-ID Header not returned by endpoint. X-Request-ID received: %s", requestid)
+
+	b, _ := io.ReadAll(r.Body)
+
+	return b
+}
+
+func (s *EndToEndSuite) funcAWSRole_Error(b []byte, expectedStatus int, expectedErrorCode string) {
+	var rc helper.ErrorResponse
+
+	err := json.Unmarshal(b, &rc)
+	if err != nil {
+		s.True(false, "Error unmarshalling response into JSON:", err)
+	}
+
+	s.Equal(expectedStatus, rc.Status, "Status. Expected: %d, Received: %d", expectedStatus, rc.Status)
+	s.Equal(expectedErrorCode, rc.ErrorCode, "Unexpected error code. Expected: %s, Received: %s", expectedErrorCode, rc.ErrorCode)
+}
+
+// funcAWSRole_AddConnection adds AWSConnection and returns its id along with id of its generic Connection.
+func (s *EndToEndSuite) funcAWSRole_AddConnection() (string, string) {
+	ip, port := GetIPAndPort()
+
+	id := s.funcAddAWSConnection(s.funcLoadDummyAWSConnection(), "_Roles", ip, port)
+
+	b := s.funcAWSRole_Request(http.MethodGet, prefixHTTP+ip+":"+port+rolesAWSConnectionPath+"/"+id, nil)
+
+	var rc data.AWSConnectionResponseWrapper
+
+	err := json.Unmarshal(b, &rc)
+	if err != nil {
+		s.True(false, "Error unmarshalling response into JSON:", err)
+	}
+
+	s.Equal(id, rc.ID.String(), "Unexpected ID. Expected: %s, Received: %s", id, rc.ID.String())
+
+	return id, rc.ConnectionID.String()
+}
+
+func (s *EndToEndSuite) funcAWSRole_DeleteConnection(id string) {
+	ip, port := GetIPAndPort()
+
+	b := s.funcAWSRole_Request(http.MethodDelete, prefixHTTP+ip+":"+port+deleteAWSConnectionPath+"/"+id+"?force=true", nil)
+
+	var rc data.DeleteAWSConnectionResponse
+
+	err := json.Unmarshal(b, &rc)
+	if err != nil {
+		s.True(false, "Error unmarshalling response into JSON:", err)
+	}
+
+	s.Equal(http.StatusOK, rc.StatusCode, "Unexpected StatusCode. Expected: %d, Received: %d", http.StatusOK, rc.StatusCode)
+}
+
+func (s *EndToEndSuite) funcAWSRole_Add(id string, roleName string) data.AWSRole {
+	ip, port := GetIPAndPort()
+
+	post := data.AWSRolePostWrapper{
+		RoleName:       roleName,
+		CredentialType: "iam_user",
+		PolicyARNs:     []string{"arn:aws:iam::aws:policy/ReadOnlyAccess"},
+	}
+
+	b := s.funcAWSRole_Request(http.MethodPost, prefixHTTP+ip+":"+port+rolesAWSConnectionPath+"/"+id+rolesAWSConnectionSuffix, post)
+
+	var rc data.AWSRole
+
+	err := json.Unmarshal(b, &rc)
+	if err != nil {
+		s.True(false, "Error unmarshalling response into JSON:", err)
+	}
+
+	s.NotEqual(uuid.Nil, rc.ID, "ID empty")
+	s.Equal(id, rc.AWSConnectionID.String(), "Unexpected AWSConnectionID. Expected: %s, Received: %s", id, rc.AWSConnectionID.String())
+	s.Equal(post.RoleName, rc.RoleName, "Unexpected RoleName")
+	s.Equal(post.CredentialType, rc.CredentialType, "Unexpected CredentialType")
+	s.Equal(post.PolicyARNs, rc.PolicyARNs, "Unexpected PolicyARNs")
+
+	return rc
+}
+
+func (s *EndToEndSuite) funcAWSRole_Link(connectionID string, applicationID string, allowedRoles ...string) {
+	ip, port := GetIPAndPort()
+
+	post := data.ConnectionLinkPostWrapper{Purpose: "roles", AllowedRoles: allowedRoles}
+
+	b := s.funcAWSRole_Request(http.MethodPost, prefixHTTP+ip+":"+port+linkConnectionPath+"/"+connectionID+linkConnectionSuffix+"/"+applicationID, post)
+
+	var rc data.ConnectionLink
+
+	err := json.Unmarshal(b, &rc)
+	if err != nil {
+		s.True(false, "Error unmarshalling response into JSON:", err)
+	}
+
+	s.Equal(connectionID, rc.ConnectionID.String(), "Unexpected ConnectionID. Expected: %s, Received: %s", connectionID, rc.ConnectionID.String())
+	s.Equal(applicationID, rc.ApplicationID, "Unexpected ApplicationID. Expected: %s, Received: %s", applicationID, rc.ApplicationID)
+}
+
+func (s *EndToEndSuite) TestPositive_Functional_Roles_Lifecycle() {
+	ip, port := GetIPAndPort()
+
+	id, _ := s.funcAWSRole_AddConnection()
+	defer s.funcAWSRole_DeleteConnection(id)
+
+	rolesURL := prefixHTTP + ip + ":" + port + rolesAWSConnectionPath + "/" + id + rolesAWSConnectionSuffix
+
+	added := s.funcAWSRole_Add(id, "readonly")
+
+	var role data.AWSRole
+
+	err := json.Unmarshal(s.funcAWSRole_Request(http.MethodGet, rolesURL+"/readonly", nil), &role)
+	if err != nil {
+		s.True(false, "Error unmarshalling response into JSON:", err)
+	}
+
+	s.Equal(added.ID, role.ID, "Unexpected ID. Expected: %s, Received: %s", added.ID, role.ID)
+	s.Equal("readonly", role.RoleName, "Unexpected RoleName")
+	s.Equal("iam_user", role.CredentialType, "Unexpected CredentialType")
+
+	var roles data.AWSRolesResponse
+
+	err = json.Unmarshal(s.funcAWSRole_Request(http.MethodGet, rolesURL, nil), &roles)
+	if err != nil {
+		s.True(false, "Error unmarshalling response into JSON:", err)
+	}
+
+	names := make([]string, 0, len(roles.Roles))
+	for _, r := range roles.Roles {
+		names = append(names, r.RoleName)
+	}
+	s.Contains(names, "readonly", "Added role not listed. Received: %v", names)
+
+	patch := data.AWSRolePatchWrapper{PolicyARNs: []string{"arn:aws:iam::aws:policy/AmazonS3ReadOnlyAccess"}}
+
+	var patched data.AWSRole
+
+	err = json.Unmarshal(s.funcAWSRole_Request(http.MethodPatch, rolesURL+"/readonly", patch), &patched)
+	if err != nil {
+		s.True(false, "Error unmarshalling response into JSON:", err)
+	}
+
+	s.Equal("readonly", patched.RoleName, "Unexpected RoleName")
+	s.Equal(patch.PolicyARNs, patched.PolicyARNs, "Unexpected PolicyARNs")
+
+	var deleted data.DeleteAWSRoleResponse
+
+	err = json.Unmarshal(s.funcAWSRole_Request(http.MethodDelete, rolesURL+"/readonly", nil), &deleted)
+	if err != nil {
+		s.True(false, "Error unmarshalling response into JSON:", err)
+	}
+
+	s.Equal(http.StatusOK, deleted.StatusCode, "Unexpected StatusCode. Expected: %d, Received: %d", http.StatusOK, deleted.StatusCode)
+
+	s.funcAWSRole_Error(s.funcAWSRole_Request(http.MethodGet, rolesURL+"/readonly", nil), http.StatusNotFound, "ConnectionManager_Err_000002")
+}
+
+func (s *EndToEndSuite) TestPositive_Functional_Roles_TestWithRoleName() {
+	ip, port := GetIPAndPort()
+
+	id, _ := s.funcAWSRole_AddConnection()
+	defer s.funcAWSRole_DeleteConnection(id)
+
+	s.funcAWSRole_Add(id, "readonly")
+
+	testURL := prefixHTTP + ip + ":" + port + rolesAWSConnectionPath + "/" + id + "/test"
+
+	var rc data.TestAWSConnectionResponse
+
+	err := json.Unmarshal(s.funcAWSRole_Request(http.MethodGet, testURL+"?role_name=readonly", nil), &rc)
+	if err != nil {
+		s.True(false, "Error unmarshalling response into JSON:", err)
+	}
+
+	s.Equal(id, rc.ID, "Unexpected ID. Expected: %s, Received: %s", id, rc.ID)
+	s.Equal("readonly", rc.RoleName, "Requested role was not tested. Expected: %s, Received: %s", "readonly", rc.RoleName)
+
+	s.funcAWSRole_Error(s.funcAWSRole_Request(http.MethodGet, testURL+"?role_name=unknown", nil), http.StatusNotFound, "ConnectionManager_Err_000002")
+}
+
+func (s *EndToEndSuite) TestPositive_Functional_Roles_CredsWithRoleName() {
+	ip, port := GetIPAndPort()
+
+	id, connectionID := s.funcAWSRole_AddConnection()
+	defer s.funcAWSRole_DeleteConnection(id)
+
+	s.funcAWSRole_Add(id, "readonly")
+	s.funcAWSRole_Add(id, "admin")
+
+	applicationID := uuid.New().String()
+	s.funcAWSRole_Link(connectionID, applicationID, "readonly")
+
+	credsURL := prefixHTTP + ip + ":" + port + credsAWSConnectionsPath + "/" + id + credsAWSConnectionsSuffix + "?applicationid=" + applicationID + "&role_name="
+
+	creds := func(roleName string) []byte {
+		c := http.Client{}
+
+		req, err := http.NewRequest(http.MethodGet, credsURL+roleName, nil)
+		if err != nil {
+			s.True(false, "Request creation failed")
+		}
+		req.Header.Set("X-Requester", applicationID)
+
+		r, err := c.Do(req)
+		if err != nil {
+			s.True(false, "Get request received error:", err)
+		}
+
+		defer func() { _ = r.Body.Close() }()
+
+		b, _ := io.ReadAll(r.Body)
+
+		return b
+	}
+
+	// Role allowed by link is resolved, credentials are refused only because connection was not tested yet.
+	s.funcAWSRole_Error(creds("readonly"), http.StatusConflict, "ConnectionManager_Err_000032")
+	s.funcAWSRole_Error(creds("admin"), http.StatusForbidden, "ConnectionManager_Err_000074")
+	s.funcAWSRole_Error(creds("unknown"), http.StatusNotFound, "ConnectionManager_Err_000002")
+}
+
+func (s *EndToEndSuite) TestNegative_Functional_RolesGet_ConnectionNotFound() {
+	ip, port := GetIPAndPort()
+
+	url := prefixHTTP + ip + ":" + port + rolesAWSConnectionPath + "/" + uuid.New().String() + rolesAWSConnectionSuffix
+
+	s.funcLease_ErrorResponse(http.MethodGet, url, http.StatusNotFound, "ConnectionManager_Err_000002")
+}
+
+func (s *EndToEndSuite) TestNegative_Functional_RoleDelete_ConnectionNotFound() {
+	ip, port := GetIPAndPort()
+
+	url := prefixHTTP + ip + ":" + port + rolesAWSConnectionPath + "/" + uuid.New().String() + rolesAWSConnectionSuffix + "/readonly"
+
+	s.funcLease_ErrorResponse(http.MethodDelete, url, http.StatusNotFound, "ConnectionManager_Err_000002")
+}
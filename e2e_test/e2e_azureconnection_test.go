@@ -0,0 +1,174 @@
+package e2e_test
+
+import (
+	"DemoServer_ConnectionManager/data"
+	"DemoServer_ConnectionManager/helper"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+
+	"github.com/google/uuid"
+)
+
+const (
+	addAzureConnectionPath    = "/v1/connectionmgmt/connection/azure"
+	getAzureConnectionsPath   = "/v1/connectionmgmt/connections/azure"
+	credsAzureConnectionsPath = "/v1/connectionmgmt/connection/azure"
+	deleteAzureConnectionPath = "/v1/connectionmgmt/connection/azure"
+)
+
+func (s *EndToEndSuite) funcLoadDummyAzureConnection() data.AzureConnectionPostWrapper {
+
+	filePathValue := "../testdata/azure_connection.json"
+
+	var obj data.AzureConnectionPostWrapper
+
+	fileContent, err := os.ReadFile(filePathValue)
+	if err != nil {
+		s.True(false, "Couldnt load json file: "+filePathValue)
+	}
+
+	err = json.Unmarshal(fileContent, &obj)
+	if err != nil {
+		s.True(false, "Error unmarshalling filecontent into JSON:", err)
+	}
+
+	return obj
+}
+
+func (s *EndToEndSuite) funcAddAzureConnection_Negative(zc data.AzureConnectionPostWrapper, expectedErrorCode string) {
+	c := http.Client{}
+
+	ip, port := GetIPAndPort()
+
+	jsonData, err := json.Marshal(zc)
+	if err != nil {
+		s.True(false, "Error marshalling JSON:", err)
+	}
+
+	r, err := c.Post(prefixHTTP+ip+":"+port+addAzureConnectionPath, "application/json", bytes.NewBuffer(jsonData))
+
+	if err != nil {
+		fmt.Printf("Post request received error: %s\n", err.Error())
+		s.True(false)
+	} else {
+		if r == nil {
+			fmt.Printf("No error but resonse object is nil.\n")
+			s.True(false)
+		}
+	}
+
+	defer func() { _ = r.Body.Close() }()
+
+	requestid := r.Header.Get("X-Request-Id")
+	s.NotEqual(requestid, "", "X-Request-ID Header not returned by endpoint. X-Request-ID received: %s", requestid)
+
+	b, _ := io.ReadAll(r.Body)
+
+	var rc helper.ErrorResponse
+
+	err = json.Unmarshal(b, &rc)
+	if err != nil {
+		s.True(false, "Error unmarshalling response into JSON:", err)
+	}
+
+	s.Equal(http.StatusBadRequest, rc.Status, "Status. Expected: %d, Received: %d", http.StatusBadRequest, rc.Status)
+	s.Equal(expectedErrorCode, rc.ErrorCode, "Unexpected error code. Expected: %s, Received: %s", expectedErrorCode, rc.ErrorCode)
+}
+
+func (s *EndToEndSuite) TestPositive_Functional_AzureConnectionsGet() {
+	c := http.Client{}
+
+	ip, port := GetIPAndPort()
+
+	r, err := c.Get(prefixHTTP + ip + ":" + port + getAzureConnectionsPath)
+
+	if err != nil {
+		fmt.Printf("Get request received error: %s\n", err.Error())
+		s.True(false)
+	} else {
+		if r == nil {
+			fmt.Printf("No error but resonse object is nil.\n")
+			s.True(false)
+		}
+	}
+
+	defer func() { _ = r.Body.Close() }()
+
+	s.Equal(http.StatusOK, r.StatusCode, "HTTP Status Code comparison failed. Expected %d, Received: %d", http.StatusOK, r.StatusCode)
+
+	b, _ := io.ReadAll(r.Body)
+
+	var rc data.AzureConnectionsResponse
+
+	err = json.Unmarshal(b, &rc)
+	if err != nil {
+		s.True(false, "Error unmarshalling response into JSON:", err)
+	}
+
+	s.NotNil(rc.AzureConnections, "AzureConnections expected to be a list")
+	s.Equal(len(rc.AzureConnections), rc.Total, "Total comparison failed. Expected: %d, Received: %d", len(rc.AzureConnections), rc.Total)
+}
+
+func (s *EndToEndSuite) TestNegative_Functional_AzureConnectionsGet_InvalidLimit() {
+	ip, port := GetIPAndPort()
+
+	url := prefixHTTP + ip + ":" + port + getAzureConnectionsPath + "?limit=0"
+
+	s.funcLease_ErrorResponse(http.MethodGet, url, http.StatusBadRequest, "ConnectionManager_Err_000003")
+}
+
+func (s *EndToEndSuite) TestNegative_Functional_AzureConnectionAdd_InvalidTenantID() {
+	zc := s.funcLoadDummyAzureConnection()
+	zc.TenantID = "not-a-tenant"
+
+	s.funcAddAzureConnection_Negative(zc, "ConnectionManager_Err_000010")
+}
+
+func (s *EndToEndSuite) TestNegative_Functional_AzureConnectionAdd_InvalidEnvironment() {
+	zc := s.funcLoadDummyAzureConnection()
+	zc.Environment = "AzureMoonCloud"
+
+	s.funcAddAzureConnection_Negative(zc, "ConnectionManager_Err_000010")
+}
+
+func (s *EndToEndSuite) TestNegative_Functional_AzureConnectionAdd_RolesMissing() {
+	zc := s.funcLoadDummyAzureConnection()
+	zc.AzureRoles = nil
+
+	s.funcAddAzureConnection_Negative(zc, "ConnectionManager_Err_000079")
+}
+
+func (s *EndToEndSuite) TestNegative_Functional_AzureConnectionAdd_RolesAndApplication() {
+	zc := s.funcLoadDummyAzureConnection()
+	zc.ApplicationObjectID = uuid.New().String()
+
+	s.funcAddAzureConnection_Negative(zc, "ConnectionManager_Err_000079")
+}
+
+func (s *EndToEndSuite) TestNegative_Functional_AzureConnectionGet_NotFound() {
+	ip, port := GetIPAndPort()
+
+	url := prefixHTTP + ip + ":" + port + addAzureConnectionPath + "/" + uuid.New().String()
+
+	s.funcLease_ErrorResponse(http.MethodGet, url, http.StatusNotFound, "ConnectionManager_Err_000002")
+}
+
+func (s *EndToEndSuite) TestNegative_Functional_AzureConnectionDelete_InvalidForce() {
+	ip, port := GetIPAndPort()
+
+	url := prefixHTTP + ip + ":" + port + deleteAzureConnectionPath + "/" + uuid.New().String() + "?force=maybe"
+
+	s.funcLease_ErrorResponse(http.MethodDelete, url, http.StatusBadRequest, "ConnectionManager_Err_000077")
+}
+
+func (s *EndToEndSuite) TestNegative_Functional_AzureConnectionCreds_ApplicationIDMissing() {
+	ip, port := GetIPAndPort()
+
+	url := prefixHTTP + ip + ":" + port + credsAzureConnectionsPath + "/" + uuid.New().String() + credsAWSConnectionsSuffix
+
+	s.funcLease_ErrorResponse(http.MethodGet, url, http.StatusBadRequest, "ConnectionManager_Err_000070")
+}
@@ -0,0 +1,139 @@
+package e2e_test
+
+import (
+	"DemoServer_ConnectionManager/data"
+	"DemoServer_ConnectionManager/helper"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	applicationsPath           = "/v1/connectionmgmt/applications"
+	applicationConnectionsPath = "/connections"
+	linkConnectionPath         = "/v1/connectionmgmt/connection"
+	linkConnectionSuffix       = "/link"
+)
+
+// funcLinkConnection links application to connection with generic Connection ID connectionid.
+func (s *EndToEndSuite) funcLinkConnection(connectionid string, applicationid string) {
+	ip, port := GetIPAndPort()
+
+	url := prefixHTTP + ip + ":" + port + linkConnectionPath + "/" + connectionid + linkConnectionSuffix + "/" + applicationid
+
+	b := s.funcAWSRole_Request(http.MethodPost, url, data.ConnectionLinkPostWrapper{Purpose: "e2e test"})
+
+	var rc data.ConnectionLink
+
+	err := json.Unmarshal(b, &rc)
+	if err != nil {
+		s.True(false, "Error unmarshalling response into JSON:", err)
+	}
+
+	s.Equal(connectionid, rc.ConnectionID.String(), "Unexpected ConnectionID. Expected: %s, Received: %s", connectionid, rc.ConnectionID.String())
+	s.Equal(applicationid, rc.ApplicationID, "Unexpected ApplicationID. Expected: %s, Received: %s", applicationid, rc.ApplicationID)
+}
+
+func (s *EndToEndSuite) funcLinkConnection_Negative(payload interface{}, expectedErrorCode string) {
+	c := http.Client{}
+
+	ip, port := GetIPAndPort()
+
+	jsonData, err := json.Marshal(payload)
+	if err != nil {
+		s.True(false, "Error marshalling payload into JSON:", err)
+	}
+
+	url := prefixHTTP + ip + ":" + port + linkConnectionPath + "/" + uuid.New().String() + linkConnectionSuffix + "/" + uuid.New().String()
+
+	r, err := c.Post(url, "application/json", bytes.NewBuffer(jsonData))
+
+	if err != nil {
+		fmt.Printf("Post request received error: %s\n", err.Error())
+		s.True(false)
+	} else {
+		if r == nil {
+			fmt.Printf("No error but resonse object is nil.\n")
+			s.True(false)
+		}
+	}
+
+	defer func() { _ = r.Body.Close() }()
+
+	b, _ := io.ReadAll(r.Body)
+
+	var rc helper.ErrorResponse
+
+	err = json.Unmarshal(b, &rc)
+	if err != nil {
+		s.True(false, "Error unmarshalling response into JSON:", err)
+	}
+
+	s.Equal(http.StatusBadRequest, rc.Status, "Status. Expected: %d, Received: %d", http.StatusBadRequest, rc.Status)
+	s.Equal(expectedErrorCode, rc.ErrorCode, "Unexpected error code. Expected: %s, Received: %s", expectedErrorCode, rc.ErrorCode)
+}
+
+func (s *EndToEndSuite) TestPositive_Functional_ApplicationConnectionsGet_NotLinked() {
+	c := http.Client{}
+
+	ip, port := GetIPAndPort()
+
+	r, err := c.Get(prefixHTTP + ip + ":" + port + applicationsPath + "/" + uuid.New().String() + applicationConnectionsPath)
+
+	if err != nil {
+		fmt.Printf("Get request received error: %s\n", err.Error())
+		s.True(false)
+	} else {
+		if r == nil {
+			fmt.Printf("No error but resonse object is nil.\n")
+			s.True(false)
+		}
+	}
+
+	defer func() { _ = r.Body.Close() }()
+
+	s.Equal(http.StatusOK, r.StatusCode, "HTTP Status Code comparison failed. Expected %d, Received: %d", http.StatusOK, r.StatusCode)
+
+	b, _ := io.ReadAll(r.Body)
+
+	var rc data.ConnectionsResponse
+
+	err = json.Unmarshal(b, &rc)
+	if err != nil {
+		s.True(false, "Error unmarshalling response into JSON:", err)
+	}
+
+	s.Equal(0, rc.Total, "Total comparison failed. Expected: %d, Received: %d", 0, rc.Total)
+	s.NotNil(rc.Connections, "Connections expected to be empty list")
+}
+
+func (s *EndToEndSuite) TestNegative_Functional_ApplicationConnectionsGet_InvalidLimit() {
+	ip, port := GetIPAndPort()
+
+	url := prefixHTTP + ip + ":" + port + applicationsPath + "/" + uuid.New().String() + applicationConnectionsPath + "?limit=0"
+
+	s.funcLease_ErrorResponse(http.MethodGet, url, http.StatusBadRequest, "ConnectionManager_Err_000003")
+}
+
+func (s *EndToEndSuite) TestNegative_Functional_ApplicationConnectionsGet_InvalidEnvironment() {
+	ip, port := GetIPAndPort()
+
+	url := prefixHTTP + ip + ":" + port + applicationsPath + "/" + uuid.New().String() + applicationConnectionsPath + "?environment=qa"
+
+	s.funcLease_ErrorResponse(http.MethodGet, url, http.StatusBadRequest, "ConnectionManager_Err_000075")
+}
+
+func (s *EndToEndSuite) TestNegative_Functional_LinkConnection_ExpiryInPast() {
+	expiresAt := time.Now().Add(-time.Hour)
+
+	s.funcLinkConnection_Negative(data.ConnectionLinkPostWrapper{ExpiresAt: &expiresAt}, "ConnectionManager_Err_000073")
+}
+
+func (s *EndToEndSuite) TestNegative_Functional_LinkConnection_InvalidEnvironment() {
+	s.funcLinkConnection_Negative(data.ConnectionLinkPostWrapper{Environment: "qa"}, "ConnectionManager_Err_000010")
+}
@@ -0,0 +1,171 @@
+package e2e_test
+
+import (
+	"DemoServer_ConnectionManager/data"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	testsConnectionPath   = "/v1/connectionmgmt/connection"
+	testsConnectionSuffix = "/tests"
+)
+
+func (s *EndToEndSuite) funcGetConnectionTests(connectionid string) *data.ConnectionTestResultsResponse {
+	c := http.Client{}
+
+	ip, port := GetIPAndPort()
+
+	r, err := c.Get(prefixHTTP + ip + ":" + port + testsConnectionPath + "/" + connectionid + testsConnectionSuffix)
+
+	if err != nil {
+		fmt.Printf("Get request received error: %s\n", err.Error())
+		s.True(false)
+	} else {
+		if r == nil {
+			fmt.Printf("No error but resonse object is nil.\n")
+			s.True(false)
+		}
+	}
+
+	defer func() { _ = r.Body.Close() }()
+
+	s.Equal(http.StatusOK, r.StatusCode, "HTTP Status Code comparison failed. Expected %d, Received: %d", http.StatusOK, r.StatusCode)
+
+	b, _ := io.ReadAll(r.Body)
+
+	var rc data.ConnectionTestResultsResponse
+
+	err = json.Unmarshal(b, &rc)
+	if err != nil {
+		s.True(false, "Error unmarshalling response into JSON:", err)
+	}
+
+	return &rc
+}
+
+func (s *EndToEndSuite) funcGetPKIConnection(id string) *data.PKIConnectionResponseWrapper {
+	c := http.Client{}
+
+	ip, port := GetIPAndPort()
+
+	r, err := c.Get(prefixHTTP + ip + ":" + port + addPKIConnectionPath + "/" + id)
+
+	if err != nil {
+		fmt.Printf("Get request received error: %s\n", err.Error())
+		s.True(false)
+	} else {
+		if r == nil {
+			fmt.Printf("No error but resonse object is nil.\n")
+			s.True(false)
+		}
+	}
+
+	defer func() { _ = r.Body.Close() }()
+
+	s.Equal(http.StatusOK, r.StatusCode, "HTTP Status Code comparison failed. Expected %d, Received: %d", http.StatusOK, r.StatusCode)
+
+	b, _ := io.ReadAll(r.Body)
+
+	var rc data.PKIConnectionResponseWrapper
+
+	err = json.Unmarshal(b, &rc)
+	if err != nil {
+		s.True(false, "Error unmarshalling response into JSON:", err)
+	}
+
+	return &rc
+}
+
+// TestPositive_Functional_ConnectionTestsGet_Manual tests connection on request and checks that outcome is kept in test
+// history and reflected in test status of connection.
+func (s *EndToEndSuite) TestPositive_Functional_ConnectionTestsGet_Manual() {
+	dc := s.funcLoadDummyPKIConnection()
+	dc.Connection.Name = dc.Connection.Name + uuid.New().String()
+
+	id, _ := s.funcAddPKIConnection(dc)
+	defer s.funcDeletePKIConnection(id)
+
+	connectionid := s.funcGetPKIConnection(id).ConnectionID.String()
+
+	tests := s.funcGetConnectionTests(connectionid)
+	s.Equal(0, tests.Total, "Untested connection has test history. Received: %d", tests.Total)
+
+	s.funcTestPKIConnection(id)
+
+	tests = s.funcGetConnectionTests(connectionid)
+	s.Equal(1, tests.Total, "Unexpected number of test results. Expected: %d, Received: %d", 1, tests.Total)
+	s.Equal(connectionid, tests.TestResults[0].ConnectionID.String(), "Unexpected ConnectionID")
+	s.False(tests.TestResults[0].Scheduled, "Test on request recorded as scheduled")
+	s.Equal(1, tests.TestResults[0].TestSuccessful, "Unexpected testsuccessful")
+	s.NotEmpty(tests.TestResults[0].RequestID, "RequestID empty")
+
+	connection := s.funcGetPKIConnection(id).Connection
+	s.Equal(1, connection.TestSuccessful, "Test status of connection not updated")
+	s.NotEmpty(connection.TestedOn, "TestedOn empty")
+	s.NotEmpty(connection.LastSuccessfulTest, "LastSuccessfulTest empty")
+}
+
+// TestPositive_Functional_ConnectionTestsGet_Scheduled waits for scheduled run to test connection and checks that outcome
+// is kept in test history and reflected in test status of connection. Service has to run with connection test
+// interval of at most a minute, set through DEMOSERVER_CONNECTIONMANAGER_CONNECTIONTEST_INTERVAL for both service
+// and test.
+func (s *EndToEndSuite) TestPositive_Functional_ConnectionTestsGet_Scheduled() {
+	interval, err := strconv.Atoi(os.Getenv("DEMOSERVER_CONNECTIONMANAGER_CONNECTIONTEST_INTERVAL"))
+	if err != nil || interval <= 0 || interval > 60 {
+		s.T().Skip("DEMOSERVER_CONNECTIONMANAGER_CONNECTIONTEST_INTERVAL not set to at most 60 seconds")
+	}
+
+	dc := s.funcLoadDummyPKIConnection()
+	dc.Connection.Name = dc.Connection.Name + uuid.New().String()
+
+	id, _ := s.funcAddPKIConnection(dc)
+	defer s.funcDeletePKIConnection(id)
+
+	connectionid := s.funcGetPKIConnection(id).ConnectionID.String()
+
+	var scheduled *data.ConnectionTestResult
+
+	deadline := time.Now().Add(time.Duration(2*interval+10) * time.Second)
+	for scheduled == nil && time.Now().Before(deadline) {
+		time.Sleep(time.Second)
+
+		tests := s.funcGetConnectionTests(connectionid)
+		for i := range tests.TestResults {
+			if tests.TestResults[i].Scheduled {
+				scheduled = &tests.TestResults[i]
+				break
+			}
+		}
+	}
+
+	s.NotNil(scheduled, "Connection not tested by scheduled run within %d seconds", 2*interval+10)
+	s.Equal(1, scheduled.TestSuccessful, "Unexpected testsuccessful. TestError: %s", scheduled.TestError)
+
+	connection := s.funcGetPKIConnection(id).Connection
+	s.Equal(1, connection.TestSuccessful, "Test status of connection not updated by scheduled run")
+	s.NotEmpty(connection.TestedOn, "TestedOn empty")
+}
+
+func (s *EndToEndSuite) TestNegative_Functional_ConnectionTestsGet_ConnectionNotFound() {
+	ip, port := GetIPAndPort()
+
+	url := prefixHTTP + ip + ":" + port + testsConnectionPath + "/" + uuid.New().String() + testsConnectionSuffix
+
+	s.funcLease_ErrorResponse(http.MethodGet, url, http.StatusNotFound, "ConnectionManager_Err_000002")
+}
+
+func (s *EndToEndSuite) TestNegative_Functional_ConnectionTestsGet_InvalidLimit() {
+	ip, port := GetIPAndPort()
+
+	url := prefixHTTP + ip + ":" + port + testsConnectionPath + "/" + uuid.New().String() + testsConnectionSuffix + "?limit=abc"
+
+	s.funcLease_ErrorResponse(http.MethodGet, url, http.StatusBadRequest, "ConnectionManager_Err_000003")
+}
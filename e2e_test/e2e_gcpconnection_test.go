@@ -0,0 +1,181 @@
+package e2e_test
+
+import (
+	"DemoServer_ConnectionManager/data"
+	"DemoServer_ConnectionManager/helper"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+
+	"github.com/google/uuid"
+)
+
+const (
+	addGCPConnectionPath    = "/v1/connectionmgmt/connection/gcp"
+	getGCPConnectionsPath   = "/v1/connectionmgmt/connections/gcp"
+	credsGCPConnectionsPath = "/v1/connectionmgmt/connection/gcp"
+	deleteGCPConnectionPath = "/v1/connectionmgmt/connection/gcp"
+)
+
+func (s *EndToEndSuite) funcLoadDummyGCPConnection() data.GCPConnectionPostWrapper {
+
+	filePathValue := "../testdata/gcp_connection.json"
+
+	var obj data.GCPConnectionPostWrapper
+
+	fileContent, err := os.ReadFile(filePathValue)
+	if err != nil {
+		s.True(false, "Couldnt load json file: "+filePathValue)
+	}
+
+	err = json.Unmarshal(fileContent, &obj)
+	if err != nil {
+		s.True(false, "Error unmarshalling filecontent into JSON:", err)
+	}
+
+	return obj
+}
+
+func (s *EndToEndSuite) funcAddGCPConnection_Negative(gc data.GCPConnectionPostWrapper, expectedErrorCode string) {
+	c := http.Client{}
+
+	ip, port := GetIPAndPort()
+
+	jsonData, err := json.Marshal(gc)
+	if err != nil {
+		s.True(false, "Error marshalling JSON:", err)
+	}
+
+	r, err := c.Post(prefixHTTP+ip+":"+port+addGCPConnectionPath, "application/json", bytes.NewBuffer(jsonData))
+
+	if err != nil {
+		fmt.Printf("Post request received error: %s\n", err.Error())
+		s.True(false)
+	} else {
+		if r == nil {
+			fmt.Printf("No error but resonse object is nil.\n")
+			s.True(false)
+		}
+	}
+
+	defer func() { _ = r.Body.Close() }()
+
+	requestid := r.Header.Get("X-Request-Id")
+	s.NotEqual(requestid, "", "X-Request-ID Header not returned by endpoint. X-Request-ID received: %s", requestid)
+
+	b, _ := io.ReadAll(r.Body)
+
+	var rc helper.ErrorResponse
+
+	err = json.Unmarshal(b, &rc)
+	if err != nil {
+		s.True(false, "Error unmarshalling response into JSON:", err)
+	}
+
+	s.Equal(http.StatusBadRequest, rc.Status, "Status. Expected: %d, Received: %d", http.StatusBadRequest, rc.Status)
+	s.Equal(expectedErrorCode, rc.ErrorCode, "Unexpected error code. Expected: %s, Received: %s", expectedErrorCode, rc.ErrorCode)
+}
+
+func (s *EndToEndSuite) TestPositive_Functional_GCPConnectionsGet() {
+	c := http.Client{}
+
+	ip, port := GetIPAndPort()
+
+	r, err := c.Get(prefixHTTP + ip + ":" + port + getGCPConnectionsPath)
+
+	if err != nil {
+		fmt.Printf("Get request received error: %s\n", err.Error())
+		s.True(false)
+	} else {
+		if r == nil {
+			fmt.Printf("No error but resonse object is nil.\n")
+			s.True(false)
+		}
+	}
+
+	defer func() { _ = r.Body.Close() }()
+
+	s.Equal(http.StatusOK, r.StatusCode, "HTTP Status Code comparison failed. Expected %d, Received: %d", http.StatusOK, r.StatusCode)
+
+	b, _ := io.ReadAll(r.Body)
+
+	var rc data.GCPConnectionsResponse
+
+	err = json.Unmarshal(b, &rc)
+	if err != nil {
+		s.True(false, "Error unmarshalling response into JSON:", err)
+	}
+
+	s.NotNil(rc.GCPConnections, "GCPConnections expected to be a list")
+	s.Equal(len(rc.GCPConnections), rc.Total, "Total comparison failed. Expected: %d, Received: %d", len(rc.GCPConnections), rc.Total)
+}
+
+func (s *EndToEndSuite) TestNegative_Functional_GCPConnectionsGet_InvalidLimit() {
+	ip, port := GetIPAndPort()
+
+	url := prefixHTTP + ip + ":" + port + getGCPConnectionsPath + "?limit=0"
+
+	s.funcLease_ErrorResponse(http.MethodGet, url, http.StatusBadRequest, "ConnectionManager_Err_000003")
+}
+
+func (s *EndToEndSuite) TestNegative_Functional_GCPConnectionAdd_InvalidRoleType() {
+	gc := s.funcLoadDummyGCPConnection()
+	gc.RoleType = "service_account"
+
+	s.funcAddGCPConnection_Negative(gc, "ConnectionManager_Err_000010")
+}
+
+func (s *EndToEndSuite) TestNegative_Functional_GCPConnectionAdd_CredentialsNotServiceAccountKey() {
+	gc := s.funcLoadDummyGCPConnection()
+	gc.Credentials = `{"type": "authorized_user"}`
+
+	s.funcAddGCPConnection_Negative(gc, "ConnectionManager_Err_000080")
+}
+
+func (s *EndToEndSuite) TestNegative_Functional_GCPConnectionAdd_RolesetWithoutBindings() {
+	gc := s.funcLoadDummyGCPConnection()
+	gc.Bindings = nil
+
+	s.funcAddGCPConnection_Negative(gc, "ConnectionManager_Err_000081")
+}
+
+func (s *EndToEndSuite) TestNegative_Functional_GCPConnectionAdd_AccessTokenWithoutScopes() {
+	gc := s.funcLoadDummyGCPConnection()
+	gc.TokenScopes = nil
+
+	s.funcAddGCPConnection_Negative(gc, "ConnectionManager_Err_000081")
+}
+
+func (s *EndToEndSuite) TestNegative_Functional_GCPConnectionAdd_StaticAccountWithoutEmail() {
+	gc := s.funcLoadDummyGCPConnection()
+	gc.RoleType = data.GCPRoleTypeStaticAccount
+
+	s.funcAddGCPConnection_Negative(gc, "ConnectionManager_Err_000081")
+}
+
+func (s *EndToEndSuite) TestNegative_Functional_GCPConnectionGet_NotFound() {
+	ip, port := GetIPAndPort()
+
+	url := prefixHTTP + ip + ":" + port + addGCPConnectionPath + "/" + uuid.New().String()
+
+	s.funcLease_ErrorResponse(http.MethodGet, url, http.StatusNotFound, "ConnectionManager_Err_000002")
+}
+
+func (s *EndToEndSuite) TestNegative_Functional_GCPConnectionDelete_InvalidForce() {
+	ip, port := GetIPAndPort()
+
+	url := prefixHTTP + ip + ":" + port + deleteGCPConnectionPath + "/" + uuid.New().String() + "?force=maybe"
+
+	s.funcLease_ErrorResponse(http.MethodDelete, url, http.StatusBadRequest, "ConnectionManager_Err_000077")
+}
+
+func (s *EndToEndSuite) TestNegative_Functional_GCPConnectionCreds_ApplicationIDMissing() {
+	ip, port := GetIPAndPort()
+
+	url := prefixHTTP + ip + ":" + port + credsGCPConnectionsPath + "/" + uuid.New().String() + credsAWSConnectionsSuffix
+
+	s.funcLease_ErrorResponse(http.MethodGet, url, http.StatusBadRequest, "ConnectionManager_Err_000070")
+}
@@ -0,0 +1,132 @@
+package e2e_test
+
+import (
+	"DemoServer_ConnectionManager/data"
+	"DemoServer_ConnectionManager/helper"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+const (
+	leasesConnectionPath   = "/v1/connectionmgmt/connection"
+	leasesConnectionSuffix = "/leases"
+)
+
+func (s *EndToEndSuite) funcLease_ErrorResponse(method string, url string, expectedStatus int, expectedErrorCode string) {
+	c := http.Client{}
+
+	req, err := http.NewRequest(method, url, nil)
+	if err != nil {
+		s.True(false, "Request creation failed")
+	}
+
+	r, err := c.Do(req)
+
+	if err != nil {
+		fmt.Printf("%s request received error: %s\n", method, err.Error())
+		s.True(false)
+	} else {
+		if r == nil {
+			fmt.Printf("No error but resonse object is nil.\n")
+			s.True(false)
+		}
+	}
+
+	defer func() { _ = r.Body.Close() }()
+
+	requestid := r.Header.Get("X-Request-Id")
+	s.NotEqual(requestid, "", "X-Request-ID Header not returned by endpoint. X-Request-ID received: %s", requestid)
+
+	b, _ := io.ReadAll(r.Body)
+
+	var rc helper.ErrorResponse
+
+	err = json.Unmarshal(b, &rc)
+	if err != nil {
+		s.True(false, "Error unmarshalling response into JSON:", err)
+	}
+
+	s.Equal(expectedStatus, rc.Status, "Status. Expected: %d, Received: %d", expectedStatus, rc.Status)
+	s.Equal(expectedErrorCode, rc.ErrorCode, "Unexpected error code. Expected: %s, Received: %s", expectedErrorCode, rc.ErrorCode)
+	s.NotEmpty(rc.RequestID, "RequestID empty")
+}
+
+// funcGetConnectionLeases returns leases tracked for connection with generic Connection ID connectionid.
+func (s *EndToEndSuite) funcGetConnectionLeases(connectionid string) data.LeasesResponse {
+	ip, port := GetIPAndPort()
+
+	b := s.funcAWSRole_Request(http.MethodGet, prefixHTTP+ip+":"+port+leasesConnectionPath+"/"+connectionid+leasesConnectionSuffix, nil)
+
+	var rc data.LeasesResponse
+
+	err := json.Unmarshal(b, &rc)
+	if err != nil {
+		s.True(false, "Error unmarshalling response into JSON:", err)
+	}
+
+	return rc
+}
+
+// TestPositive_Functional_LeasesGet_PKIConnection lists leases of connection other than AWS through its generic
+// Connection ID.
+func (s *EndToEndSuite) TestPositive_Functional_LeasesGet_PKIConnection() {
+	ip, port := GetIPAndPort()
+
+	dc := s.funcLoadDummyPKIConnection()
+	dc.Connection.Name = dc.Connection.Name + uuid.New().String()
+
+	id, _ := s.funcAddPKIConnection(dc)
+	defer s.funcDeletePKIConnection(id)
+
+	connectionid := s.funcGetPKIConnection(id).ConnectionID.String()
+
+	b := s.funcAWSRole_Request(http.MethodGet, prefixHTTP+ip+":"+port+leasesConnectionPath+"/"+connectionid+leasesConnectionSuffix, nil)
+
+	var rc data.LeasesResponse
+
+	err := json.Unmarshal(b, &rc)
+	if err != nil {
+		s.True(false, "Error unmarshalling response into JSON:", err)
+	}
+
+	s.Equal(0, rc.Total, "Unexpected number of leases. Expected: %d, Received: %d", 0, rc.Total)
+	s.NotNil(rc.Leases, "Leases nil")
+
+	var revoked data.RevokeLeasesResponse
+
+	err = json.Unmarshal(s.funcAWSRole_Request(http.MethodPost, prefixHTTP+ip+":"+port+leasesConnectionPath+"/"+connectionid+leasesConnectionSuffix+"/revoke", nil), &revoked)
+	if err != nil {
+		s.True(false, "Error unmarshalling response into JSON:", err)
+	}
+
+	s.Equal(connectionid, revoked.ConnectionID, "Unexpected ConnectionID. Expected: %s, Received: %s", connectionid, revoked.ConnectionID)
+	s.Equal(0, revoked.Revoked, "Unexpected number of revoked leases")
+}
+
+func (s *EndToEndSuite) TestNegative_Functional_LeasesGet_ConnectionNotFound() {
+	ip, port := GetIPAndPort()
+
+	url := prefixHTTP + ip + ":" + port + leasesConnectionPath + "/" + uuid.New().String() + leasesConnectionSuffix
+
+	s.funcLease_ErrorResponse(http.MethodGet, url, http.StatusNotFound, "ConnectionManager_Err_000002")
+}
+
+func (s *EndToEndSuite) TestNegative_Functional_LeaseRenew_ConnectionNotFound() {
+	ip, port := GetIPAndPort()
+
+	url := prefixHTTP + ip + ":" + port + leasesConnectionPath + "/" + uuid.New().String() + leasesConnectionSuffix + "/" + uuid.New().String() + "/renew"
+
+	s.funcLease_ErrorResponse(http.MethodPost, url, http.StatusNotFound, "ConnectionManager_Err_000002")
+}
+
+func (s *EndToEndSuite) TestNegative_Functional_LeaseRenew_InvalidIncrement() {
+	ip, port := GetIPAndPort()
+
+	url := prefixHTTP + ip + ":" + port + leasesConnectionPath + "/" + uuid.New().String() + leasesConnectionSuffix + "/" + uuid.New().String() + "/renew?increment=abc"
+
+	s.funcLease_ErrorResponse(http.MethodPost, url, http.StatusBadRequest, "ConnectionManager_Err_000045")
+}
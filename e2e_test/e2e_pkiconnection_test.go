@@ -0,0 +1,443 @@
+package e2e_test
+
+import (
+	"DemoServer_ConnectionManager/data"
+	"DemoServer_ConnectionManager/helper"
+	"bytes"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+
+	"github.com/google/uuid"
+)
+
+const (
+	addPKIConnectionPath    = "/v1/connectionmgmt/connection/pki"
+	getPKIConnectionsPath   = "/v1/connectionmgmt/connections/pki"
+	testPKIConnectionsPath  = "/v1/connectionmgmt/connection/pki"
+	issuePKIConnectionsPath = "/v1/connectionmgmt/connection/pki"
+	deletePKIConnectionPath = "/v1/connectionmgmt/connection/pki"
+)
+
+func (s *EndToEndSuite) funcLoadDummyPKIConnection() data.PKIConnectionPostWrapper {
+
+	filePathValue := "../testdata/pki_connection.json"
+
+	var obj data.PKIConnectionPostWrapper
+
+	fileContent, err := os.ReadFile(filePathValue)
+	if err != nil {
+		s.True(false, "Couldnt load json file: "+filePathValue)
+	}
+
+	err = json.Unmarshal(fileContent, &obj)
+	if err != nil {
+		s.True(false, "Error unmarshalling filecontent into JSON:", err)
+	}
+
+	return obj
+}
+
+func (s *EndToEndSuite) funcAddPKIConnection(dc data.PKIConnectionPostWrapper) (string, string) {
+	c := http.Client{}
+
+	ip, port := GetIPAndPort()
+
+	jsonData, err := json.Marshal(dc)
+	if err != nil {
+		s.True(false, "Error marshalling JSON:", err)
+	}
+
+	r, err := c.Post(prefixHTTP+ip+":"+port+addPKIConnectionPath, "application/json", bytes.NewBuffer(jsonData))
+
+	if err != nil {
+		fmt.Printf("Post request received error: %s\n", err.Error())
+		s.True(false)
+	} else {
+		if r == nil {
+			fmt.Printf("No error but resonse object is nil.\n")
+			s.True(false)
+		}
+	}
+
+	defer func() { _ = r.Body.Close() }()
+
+	s.Equal(http.StatusOK, r.StatusCode, "HTTP Status Code comparison failed. Expected %d, Received: %d", http.StatusOK, r.StatusCode)
+	requestid := r.Header.Get("X-Request-Id")
+	s.NotEqual(requestid, "", "X-Request-ID Header not returned by endpoint. X-Request-ID received: %s", requestid)
+
+	b, _ := io.ReadAll(r.Body)
+
+	var rc data.PKIConnectionResponseWrapper
+
+	err = json.Unmarshal(b, &rc)
+	if err != nil {
+		s.True(false, "Error unmarshalling response into JSON:", err)
+	}
+
+	s.NotEmpty(rc.ID.String(), "ID empty")
+	s.Equal(rc.ConnectionID.String(), rc.Connection.ID.String(), "ConnectionID should be same as Connection.ID")
+	s.Equal(rc.Connection.Name, dc.Connection.Name, "Unexpected Name")
+	s.Equal(rc.Connection.ConnectionType, data.PKIConnectionType, "Unexpected connectiontype")
+	s.Equal(rc.Connection.TestSuccessful, 0, "Unexpected testsuccessful")
+	s.Equal(rc.CAType, dc.CAType, "Unexpected CAType")
+	s.Equal(rc.RoleName, dc.RoleName, "Unexpected RoleName")
+	s.Equal(rc.AllowedDomains, dc.AllowedDomains, "Unexpected AllowedDomains")
+	s.NotEmpty(rc.Certificate, "Certificate of CA empty")
+
+	return rc.ID.String(), rc.Certificate
+}
+
+func (s *EndToEndSuite) funcGetPKIConnectionCA(connectionid string) *x509.Certificate {
+	c := http.Client{}
+
+	ip, port := GetIPAndPort()
+
+	r, err := c.Get(prefixHTTP + ip + ":" + port + addPKIConnectionPath + "/" + connectionid + "/ca")
+
+	if err != nil {
+		fmt.Printf("Get request received error: %s\n", err.Error())
+		s.True(false)
+	} else {
+		if r == nil {
+			fmt.Printf("No error but resonse object is nil.\n")
+			s.True(false)
+		}
+	}
+
+	defer func() { _ = r.Body.Close() }()
+
+	s.Equal(http.StatusOK, r.StatusCode, "HTTP Status Code comparison failed. Expected %d, Received: %d", http.StatusOK, r.StatusCode)
+	s.Equal("application/pkix-cert", r.Header.Get("Content-Type"), "Unexpected Content-Type")
+
+	b, _ := io.ReadAll(r.Body)
+
+	certificate, err := x509.ParseCertificate(b)
+	if err != nil {
+		s.True(false, "Error parsing certificate of CA:", err)
+	}
+
+	return certificate
+}
+
+func (s *EndToEndSuite) funcGetPKIConnectionCRL(connectionid string) *x509.RevocationList {
+	c := http.Client{}
+
+	ip, port := GetIPAndPort()
+
+	r, err := c.Get(prefixHTTP + ip + ":" + port + addPKIConnectionPath + "/" + connectionid + "/crl")
+
+	if err != nil {
+		fmt.Printf("Get request received error: %s\n", err.Error())
+		s.True(false)
+	} else {
+		if r == nil {
+			fmt.Printf("No error but resonse object is nil.\n")
+			s.True(false)
+		}
+	}
+
+	defer func() { _ = r.Body.Close() }()
+
+	s.Equal(http.StatusOK, r.StatusCode, "HTTP Status Code comparison failed. Expected %d, Received: %d", http.StatusOK, r.StatusCode)
+	s.Equal("application/pkix-crl", r.Header.Get("Content-Type"), "Unexpected Content-Type")
+
+	b, _ := io.ReadAll(r.Body)
+
+	crl, err := x509.ParseRevocationList(b)
+	if err != nil {
+		s.True(false, "Error parsing CRL:", err)
+	}
+
+	return crl
+}
+
+func (s *EndToEndSuite) funcTestPKIConnection(connectionid string) {
+	c := http.Client{}
+
+	ip, port := GetIPAndPort()
+
+	r, err := c.Get(prefixHTTP + ip + ":" + port + testPKIConnectionsPath + "/" + connectionid + "/test")
+
+	if err != nil {
+		fmt.Printf("Get request received error: %s\n", err.Error())
+		s.True(false)
+	} else {
+		if r == nil {
+			fmt.Printf("No error but resonse object is nil.\n")
+			s.True(false)
+		}
+	}
+
+	defer func() { _ = r.Body.Close() }()
+
+	s.Equal(http.StatusOK, r.StatusCode, "HTTP Status Code comparison failed. Expected %d, Received: %d", http.StatusOK, r.StatusCode)
+
+	b, _ := io.ReadAll(r.Body)
+
+	var rc data.TestPKIConnectionResponse
+
+	err = json.Unmarshal(b, &rc)
+	if err != nil {
+		s.True(false, "Error unmarshalling response into JSON:", err)
+	}
+
+	s.Equal(connectionid, rc.ID, "Unexpected ID")
+	s.Equal("", rc.TestStatus, "Test Status comparison failed. Expected Empty String, Received: %s", rc.TestStatus)
+	s.Equal(1, rc.TestStatusCode, "TestStatusCode comparison failed. Expected: %d, Received: %d", 1, rc.TestStatusCode)
+}
+
+func (s *EndToEndSuite) funcDeletePKIConnection(connectionid string) {
+	c := http.Client{}
+
+	ip, port := GetIPAndPort()
+
+	req, err := http.NewRequest(http.MethodDelete, prefixHTTP+ip+":"+port+deletePKIConnectionPath+"/"+connectionid, nil)
+	if err != nil {
+		s.True(false, "Error creating request:", err)
+	}
+
+	r, err := c.Do(req)
+
+	if err != nil {
+		fmt.Printf("Delete request received error: %s\n", err.Error())
+		s.True(false)
+	} else {
+		if r == nil {
+			fmt.Printf("No error but resonse object is nil.\n")
+			s.True(false)
+		}
+	}
+
+	defer func() { _ = r.Body.Close() }()
+
+	s.Equal(http.StatusOK, r.StatusCode, "HTTP Status Code comparison failed. Expected %d, Received: %d", http.StatusOK, r.StatusCode)
+}
+
+func (s *EndToEndSuite) funcAddPKIConnection_Negative(dc data.PKIConnectionPostWrapper, expectedErrorCode string) {
+	c := http.Client{}
+
+	ip, port := GetIPAndPort()
+
+	jsonData, err := json.Marshal(dc)
+	if err != nil {
+		s.True(false, "Error marshalling JSON:", err)
+	}
+
+	r, err := c.Post(prefixHTTP+ip+":"+port+addPKIConnectionPath, "application/json", bytes.NewBuffer(jsonData))
+
+	if err != nil {
+		fmt.Printf("Post request received error: %s\n", err.Error())
+		s.True(false)
+	} else {
+		if r == nil {
+			fmt.Printf("No error but resonse object is nil.\n")
+			s.True(false)
+		}
+	}
+
+	defer func() { _ = r.Body.Close() }()
+
+	requestid := r.Header.Get("X-Request-Id")
+	s.NotEqual(requestid, "", "X-Request-ID Header not returned by endpoint. X-Request-ID received: %s", requestid)
+
+	b, _ := io.ReadAll(r.Body)
+
+	var rc helper.ErrorResponse
+
+	err = json.Unmarshal(b, &rc)
+	if err != nil {
+		s.True(false, "Error unmarshalling response into JSON:", err)
+	}
+
+	s.Equal(http.StatusBadRequest, rc.Status, "Status. Expected: %d, Received: %d", http.StatusBadRequest, rc.Status)
+	s.Equal(expectedErrorCode, rc.ErrorCode, "Unexpected error code. Expected: %s, Received: %s", expectedErrorCode, rc.ErrorCode)
+}
+
+// TestPositive_Functional_PKIConnectionLifecycle adds root and intermediate connections, tests intermediate, retrieves
+// CA certificate and CRL the way TLS clients do and deletes both connections, intermediate first.
+func (s *EndToEndSuite) TestPositive_Functional_PKIConnectionLifecycle() {
+	root := s.funcLoadDummyPKIConnection()
+	root.Connection.Name = root.Connection.Name + uuid.New().String()
+
+	rootid, rootCertificate := s.funcAddPKIConnection(root)
+
+	intermediate := s.funcLoadDummyPKIConnection()
+	intermediate.Connection.Name = "Demo PKI Intermediate CA " + uuid.New().String()
+	intermediate.CAType = data.PKICATypeIntermediate
+	intermediate.CommonName = "Demo Services Intermediate CA"
+	intermediate.IssuerConnectionID = rootid
+	intermediate.MaxLeaseTTL = "4380h"
+
+	connectionid, certificate := s.funcAddPKIConnection(intermediate)
+
+	s.funcTestPKIConnection(connectionid)
+
+	block, _ := pem.Decode([]byte(certificate))
+	s.NotNil(block, "Certificate of CA is not PEM encoded")
+
+	ca := s.funcGetPKIConnectionCA(connectionid)
+	s.Equal(block.Bytes, ca.Raw, "Unexpected certificate of CA")
+
+	rootBlock, _ := pem.Decode([]byte(rootCertificate))
+	s.NotNil(rootBlock, "Certificate of root CA is not PEM encoded")
+
+	rootCA, err := x509.ParseCertificate(rootBlock.Bytes)
+	if err != nil {
+		s.True(false, "Error parsing certificate of root CA:", err)
+	}
+	s.NoError(ca.CheckSignatureFrom(rootCA), "Intermediate CA not signed by root CA")
+
+	crl := s.funcGetPKIConnectionCRL(connectionid)
+	s.NoError(crl.CheckSignatureFrom(ca), "CRL not signed by CA")
+
+	// Root can not be deleted while it is issuer of intermediate, not even with force
+	ip, port := GetIPAndPort()
+	b := s.funcAWSRole_Request(http.MethodDelete, prefixHTTP+ip+":"+port+deletePKIConnectionPath+"/"+rootid+"?force=true", nil)
+	s.funcAWSRole_Error(b, http.StatusConflict, "ConnectionManager_Err_000090")
+
+	s.funcDeletePKIConnection(connectionid)
+	s.funcDeletePKIConnection(rootid)
+
+	url := prefixHTTP + ip + ":" + port + addPKIConnectionPath + "/" + connectionid
+
+	s.funcLease_ErrorResponse(http.MethodGet, url, http.StatusNotFound, "ConnectionManager_Err_000002")
+}
+
+func (s *EndToEndSuite) TestPositive_Functional_PKIConnectionsGet() {
+	c := http.Client{}
+
+	ip, port := GetIPAndPort()
+
+	r, err := c.Get(prefixHTTP + ip + ":" + port + getPKIConnectionsPath)
+
+	if err != nil {
+		fmt.Printf("Get request received error: %s\n", err.Error())
+		s.True(false)
+	} else {
+		if r == nil {
+			fmt.Printf("No error but resonse object is nil.\n")
+			s.True(false)
+		}
+	}
+
+	defer func() { _ = r.Body.Close() }()
+
+	s.Equal(http.StatusOK, r.StatusCode, "HTTP Status Code comparison failed. Expected %d, Received: %d", http.StatusOK, r.StatusCode)
+
+	b, _ := io.ReadAll(r.Body)
+
+	var rc data.PKIConnectionsResponse
+
+	err = json.Unmarshal(b, &rc)
+	if err != nil {
+		s.True(false, "Error unmarshalling response into JSON:", err)
+	}
+
+	s.NotNil(rc.PKIConnections, "PKIConnections expected to be a list")
+	s.Equal(len(rc.PKIConnections), rc.Total, "Total comparison failed. Expected: %d, Received: %d", len(rc.PKIConnections), rc.Total)
+}
+
+func (s *EndToEndSuite) TestNegative_Functional_PKIConnectionAdd_InvalidCAType() {
+	dc := s.funcLoadDummyPKIConnection()
+	dc.CAType = "subordinate"
+
+	s.funcAddPKIConnection_Negative(dc, "ConnectionManager_Err_000010")
+}
+
+func (s *EndToEndSuite) TestNegative_Functional_PKIConnectionAdd_NoAllowedDomains() {
+	dc := s.funcLoadDummyPKIConnection()
+	dc.AllowedDomains = nil
+
+	s.funcAddPKIConnection_Negative(dc, "ConnectionManager_Err_000010")
+}
+
+func (s *EndToEndSuite) TestNegative_Functional_PKIConnectionAdd_DomainsMatchNoName() {
+	dc := s.funcLoadDummyPKIConnection()
+	dc.AllowSubdomains = false
+	dc.AllowBareDomains = false
+
+	s.funcAddPKIConnection_Negative(dc, "ConnectionManager_Err_000089")
+}
+
+func (s *EndToEndSuite) TestNegative_Functional_PKIConnectionAdd_IntermediateWithoutIssuer() {
+	dc := s.funcLoadDummyPKIConnection()
+	dc.CAType = data.PKICATypeIntermediate
+
+	s.funcAddPKIConnection_Negative(dc, "ConnectionManager_Err_000085")
+}
+
+func (s *EndToEndSuite) TestNegative_Functional_PKIConnectionAdd_IntermediateWithUnknownIssuer() {
+	dc := s.funcLoadDummyPKIConnection()
+	dc.CAType = data.PKICATypeIntermediate
+	dc.IssuerConnectionID = uuid.New().String()
+
+	s.funcAddPKIConnection_Negative(dc, "ConnectionManager_Err_000085")
+}
+
+func (s *EndToEndSuite) TestNegative_Functional_PKIConnectionAdd_RootWithIssuer() {
+	dc := s.funcLoadDummyPKIConnection()
+	dc.IssuerConnectionID = uuid.New().String()
+
+	s.funcAddPKIConnection_Negative(dc, "ConnectionManager_Err_000085")
+}
+
+func (s *EndToEndSuite) TestNegative_Functional_PKIConnectionCA_NotFound() {
+	ip, port := GetIPAndPort()
+
+	url := prefixHTTP + ip + ":" + port + addPKIConnectionPath + "/" + uuid.New().String() + "/ca"
+
+	s.funcLease_ErrorResponse(http.MethodGet, url, http.StatusNotFound, "ConnectionManager_Err_000002")
+}
+
+func (s *EndToEndSuite) TestNegative_Functional_PKIConnectionCRL_InvalidFormat() {
+	ip, port := GetIPAndPort()
+
+	url := prefixHTTP + ip + ":" + port + addPKIConnectionPath + "/" + uuid.New().String() + "/crl?format=xml"
+
+	s.funcLease_ErrorResponse(http.MethodGet, url, http.StatusBadRequest, "ConnectionManager_Err_000087")
+}
+
+func (s *EndToEndSuite) TestNegative_Functional_PKIConnectionOCSP_InvalidRequest() {
+	ip, port := GetIPAndPort()
+
+	url := prefixHTTP + ip + ":" + port + addPKIConnectionPath + "/" + uuid.New().String() + "/ocsp"
+
+	s.funcLease_ErrorResponse(http.MethodPost, url, http.StatusBadRequest, "ConnectionManager_Err_000088")
+}
+
+func (s *EndToEndSuite) TestNegative_Functional_PKIConnectionCertificates_InvalidExpiresWithin() {
+	ip, port := GetIPAndPort()
+
+	url := prefixHTTP + ip + ":" + port + addPKIConnectionPath + "/" + uuid.New().String() + "/certificates?expires_within=soon"
+
+	s.funcLease_ErrorResponse(http.MethodGet, url, http.StatusBadRequest, "ConnectionManager_Err_000086")
+}
+
+func (s *EndToEndSuite) TestNegative_Functional_PKIConnectionGet_NotFound() {
+	ip, port := GetIPAndPort()
+
+	url := prefixHTTP + ip + ":" + port + addPKIConnectionPath + "/" + uuid.New().String()
+
+	s.funcLease_ErrorResponse(http.MethodGet, url, http.StatusNotFound, "ConnectionManager_Err_000002")
+}
+
+func (s *EndToEndSuite) TestNegative_Functional_PKIConnectionDelete_InvalidForce() {
+	ip, port := GetIPAndPort()
+
+	url := prefixHTTP + ip + ":" + port + deletePKIConnectionPath + "/" + uuid.New().String() + "?force=maybe"
+
+	s.funcLease_ErrorResponse(http.MethodDelete, url, http.StatusBadRequest, "ConnectionManager_Err_000077")
+}
+
+func (s *EndToEndSuite) TestNegative_Functional_PKIConnectionIssue_ApplicationIDMissing() {
+	ip, port := GetIPAndPort()
+
+	url := prefixHTTP + ip + ":" + port + issuePKIConnectionsPath + "/" + uuid.New().String() + "/issue"
+
+	s.funcLease_ErrorResponse(http.MethodPost, url, http.StatusBadRequest, "ConnectionManager_Err_000070")
+}
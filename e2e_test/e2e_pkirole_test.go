@@ -0,0 +1,236 @@
+package e2e_test
+
+import (
+	"DemoServer_ConnectionManager/data"
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+const (
+	rolesPKIConnectionPath   = "/v1/connectionmgmt/connection/pki"
+	rolesPKIConnectionSuffix = "/roles"
+)
+
+// funcPKIRole_AddConnection adds root PKIConnection and returns its id along with id of its generic Connection.
+func (s *EndToEndSuite) funcPKIRole_AddConnection() (string, string) {
+	ip, port := GetIPAndPort()
+
+	dc := s.funcLoadDummyPKIConnection()
+	dc.Connection.Name = dc.Connection.Name + " Roles " + uuid.New().String()
+
+	id, _ := s.funcAddPKIConnection(dc)
+
+	b := s.funcAWSRole_Request(http.MethodGet, prefixHTTP+ip+":"+port+rolesPKIConnectionPath+"/"+id, nil)
+
+	var rc data.PKIConnectionResponseWrapper
+
+	err := json.Unmarshal(b, &rc)
+	if err != nil {
+		s.True(false, "Error unmarshalling response into JSON:", err)
+	}
+
+	s.Equal(id, rc.ID.String(), "Unexpected ID. Expected: %s, Received: %s", id, rc.ID.String())
+
+	return id, rc.ConnectionID.String()
+}
+
+func (s *EndToEndSuite) funcPKIRole_DeleteConnection(id string) {
+	ip, port := GetIPAndPort()
+
+	b := s.funcAWSRole_Request(http.MethodDelete, prefixHTTP+ip+":"+port+deletePKIConnectionPath+"/"+id+"?force=true", nil)
+
+	var rc data.DeletePKIConnectionResponse
+
+	err := json.Unmarshal(b, &rc)
+	if err != nil {
+		s.True(false, "Error unmarshalling response into JSON:", err)
+	}
+
+	s.Equal(http.StatusOK, rc.StatusCode, "Unexpected StatusCode. Expected: %d, Received: %d", http.StatusOK, rc.StatusCode)
+}
+
+func (s *EndToEndSuite) funcPKIRole_Add(id string, roleName string, domain string) data.PKIRole {
+	ip, port := GetIPAndPort()
+
+	post := data.PKIRolePostWrapper{
+		RoleName:         roleName,
+		AllowedDomains:   []string{domain},
+		AllowBareDomains: true,
+	}
+
+	b := s.funcAWSRole_Request(http.MethodPost, prefixHTTP+ip+":"+port+rolesPKIConnectionPath+"/"+id+rolesPKIConnectionSuffix, post)
+
+	var rc data.PKIRole
+
+	err := json.Unmarshal(b, &rc)
+	if err != nil {
+		s.True(false, "Error unmarshalling response into JSON:", err)
+	}
+
+	s.NotEqual(uuid.Nil, rc.ID, "ID empty")
+	s.Equal(id, rc.PKIConnectionID.String(), "Unexpected PKIConnectionID. Expected: %s, Received: %s", id, rc.PKIConnectionID.String())
+	s.Equal(post.RoleName, rc.RoleName, "Unexpected RoleName")
+	s.Equal(post.AllowedDomains, rc.AllowedDomains, "Unexpected AllowedDomains")
+
+	return rc
+}
+
+// funcPKIRole_Issue requests certificate for common name through role on behalf of application and returns body
+// of response.
+func (s *EndToEndSuite) funcPKIRole_Issue(id string, applicationID string, roleName string, commonName string) []byte {
+	c := http.Client{}
+
+	ip, port := GetIPAndPort()
+
+	jsonData, err := json.Marshal(data.IssuePKIConnectionRequest{CommonName: commonName, TTL: "1h"})
+	if err != nil {
+		s.True(false, "Error marshalling JSON:", err)
+	}
+
+	url := prefixHTTP + ip + ":" + port + issuePKIConnectionsPath + "/" + id + "/issue?applicationid=" + applicationID + "&role_name=" + roleName
+
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		s.True(false, "Request creation failed")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Requester", applicationID)
+
+	r, err := c.Do(req)
+	if err != nil {
+		s.True(false, "Post request received error:", err)
+	}
+
+	defer func() { _ = r.Body.Close() }()
+
+	b, _ := io.ReadAll(r.Body)
+
+	return b
+}
+
+func (s *EndToEndSuite) TestPositive_Functional_PKIRoles_Lifecycle() {
+	ip, port := GetIPAndPort()
+
+	id, _ := s.funcPKIRole_AddConnection()
+	defer s.funcPKIRole_DeleteConnection(id)
+
+	rolesURL := prefixHTTP + ip + ":" + port + rolesPKIConnectionPath + "/" + id + rolesPKIConnectionSuffix
+
+	added := s.funcPKIRole_Add(id, "jobs", "jobs.demo.internal")
+
+	var role data.PKIRole
+
+	err := json.Unmarshal(s.funcAWSRole_Request(http.MethodGet, rolesURL+"/jobs", nil), &role)
+	if err != nil {
+		s.True(false, "Error unmarshalling response into JSON:", err)
+	}
+
+	s.Equal(added.ID, role.ID, "Unexpected ID. Expected: %s, Received: %s", added.ID, role.ID)
+	s.Equal([]string{"jobs.demo.internal"}, role.AllowedDomains, "Unexpected AllowedDomains")
+	s.True(role.AllowBareDomains, "Unexpected AllowBareDomains")
+	s.Equal("ec", role.KeyType, "Key type of connection not used. Received: %s", role.KeyType)
+
+	var roles data.PKIRolesResponse
+
+	err = json.Unmarshal(s.funcAWSRole_Request(http.MethodGet, rolesURL, nil), &roles)
+	if err != nil {
+		s.True(false, "Error unmarshalling response into JSON:", err)
+	}
+
+	names := make([]string, 0, len(roles.Roles))
+	for _, r := range roles.Roles {
+		names = append(names, r.RoleName)
+	}
+	s.Equal([]string{"DemoServices", "jobs"}, names, "Unexpected roles listed")
+
+	allowSubdomains := true
+	patch := data.PKIRolePatchWrapper{AllowSubdomains: &allowSubdomains}
+
+	var patched data.PKIRole
+
+	err = json.Unmarshal(s.funcAWSRole_Request(http.MethodPatch, rolesURL+"/jobs", patch), &patched)
+	if err != nil {
+		s.True(false, "Error unmarshalling response into JSON:", err)
+	}
+
+	s.Equal("jobs", patched.RoleName, "Unexpected RoleName")
+	s.True(patched.AllowSubdomains, "Unexpected AllowSubdomains")
+	s.Equal([]string{"jobs.demo.internal"}, patched.AllowedDomains, "Unexpected AllowedDomains")
+
+	// Default role is managed through connection
+	post := data.PKIRolePostWrapper{RoleName: "DemoServices", AllowedDomains: []string{"jobs.demo.internal"}, AllowBareDomains: true}
+	s.funcAWSRole_Error(s.funcAWSRole_Request(http.MethodPost, rolesURL, post), http.StatusConflict, "ConnectionManager_Err_000050")
+	s.funcAWSRole_Error(s.funcAWSRole_Request(http.MethodPatch, rolesURL+"/DemoServices", patch), http.StatusBadRequest, "ConnectionManager_Err_000053")
+	s.funcAWSRole_Error(s.funcAWSRole_Request(http.MethodDelete, rolesURL+"/DemoServices", nil), http.StatusBadRequest, "ConnectionManager_Err_000051")
+
+	var deleted data.DeletePKIRoleResponse
+
+	err = json.Unmarshal(s.funcAWSRole_Request(http.MethodDelete, rolesURL+"/jobs", nil), &deleted)
+	if err != nil {
+		s.True(false, "Error unmarshalling response into JSON:", err)
+	}
+
+	s.Equal(http.StatusOK, deleted.StatusCode, "Unexpected StatusCode. Expected: %d, Received: %d", http.StatusOK, deleted.StatusCode)
+
+	s.funcAWSRole_Error(s.funcAWSRole_Request(http.MethodGet, rolesURL+"/jobs", nil), http.StatusNotFound, "ConnectionManager_Err_000002")
+}
+
+func (s *EndToEndSuite) TestPositive_Functional_PKIRoles_TestWithRoleName() {
+	ip, port := GetIPAndPort()
+
+	id, _ := s.funcPKIRole_AddConnection()
+	defer s.funcPKIRole_DeleteConnection(id)
+
+	s.funcPKIRole_Add(id, "jobs", "jobs.demo.internal")
+
+	testURL := prefixHTTP + ip + ":" + port + testPKIConnectionsPath + "/" + id + "/test"
+
+	var rc data.TestPKIConnectionResponse
+
+	err := json.Unmarshal(s.funcAWSRole_Request(http.MethodGet, testURL+"?role_name=jobs", nil), &rc)
+	if err != nil {
+		s.True(false, "Error unmarshalling response into JSON:", err)
+	}
+
+	s.Equal(id, rc.ID, "Unexpected ID. Expected: %s, Received: %s", id, rc.ID)
+	s.Equal("jobs", rc.RoleName, "Requested role was not tested. Expected: %s, Received: %s", "jobs", rc.RoleName)
+	s.Equal(1, rc.TestStatusCode, "TestStatusCode comparison failed. Received: %s", rc.TestStatus)
+
+	s.funcAWSRole_Error(s.funcAWSRole_Request(http.MethodGet, testURL+"?role_name=unknown", nil), http.StatusNotFound, "ConnectionManager_Err_000002")
+}
+
+func (s *EndToEndSuite) TestPositive_Functional_PKIRoles_IssueWithRoleName() {
+	id, connectionID := s.funcPKIRole_AddConnection()
+	defer s.funcPKIRole_DeleteConnection(id)
+
+	s.funcPKIRole_Add(id, "jobs", "jobs.demo.internal")
+	s.funcTestPKIConnection(id)
+
+	applicationID := uuid.New().String()
+	s.funcAWSRole_Link(connectionID, applicationID, "jobs")
+
+	var rc data.IssuePKIConnectionResponse
+
+	err := json.Unmarshal(s.funcPKIRole_Issue(id, applicationID, "jobs", "jobs.demo.internal"), &rc)
+	if err != nil {
+		s.True(false, "Error unmarshalling response into JSON:", err)
+	}
+
+	s.Equal("jobs", rc.RoleName, "Certificate not issued through requested role. Received: %s", rc.RoleName)
+	s.NotEmpty(rc.Certificate, "Certificate empty")
+
+	s.funcAWSRole_Error(s.funcPKIRole_Issue(id, applicationID, "", "api.services.demo.internal"), http.StatusForbidden, "ConnectionManager_Err_000074")
+	s.funcAWSRole_Error(s.funcPKIRole_Issue(id, applicationID, "unknown", "jobs.demo.internal"), http.StatusNotFound, "ConnectionManager_Err_000002")
+}
+
+func (s *EndToEndSuite) TestNegative_Functional_PKIRolesGet_ConnectionNotFound() {
+	ip, port := GetIPAndPort()
+
+	url := prefixHTTP + ip + ":" + port + rolesPKIConnectionPath + "/" + uuid.New().String() + rolesPKIConnectionSuffix
+
+	s.funcLease_ErrorResponse(http.MethodGet, url, http.StatusNotFound, "ConnectionManager_Err_000002")
+}
@@ -0,0 +1,44 @@
+package e2e_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"DemoServer_ConnectionManager/data"
+)
+
+const (
+	getDriftPath = "/v1/connectionmgmt/status/drift"
+)
+
+func (s *EndToEndSuite) TestPositive_GetDrift_HappyPath() {
+	c := http.Client{}
+
+	ip, port := GetIPAndPort()
+
+	r, err := c.Get(prefixHTTP + ip + ":" + port + getDriftPath)
+
+	if err != nil {
+		fmt.Printf("Get request received error: %s\n", err.Error())
+		s.True(false)
+	} else {
+		if r == nil {
+			fmt.Printf("No error but resonse object is nil.\n")
+			s.True(false)
+		}
+	}
+
+	s.Equal(http.StatusOK, r.StatusCode, "HTTP Status Code comparison failed. Expected %d, Received: %", http.StatusOK, r.StatusCode)
+
+	b, _ := io.ReadAll(r.Body)
+	_ = r.Body.Close()
+
+	var report data.DriftReport
+	err = json.Unmarshal(b, &report)
+	s.NoError(err, "JSON decoding of DriftReport failed")
+
+	s.NotNil(report.MissingMounts, "missingmounts expected to be present in DriftReport")
+	s.NotNil(report.OrphanMounts, "orphanmounts expected to be present in DriftReport")
+}
@@ -0,0 +1,175 @@
+package e2e_test
+
+import (
+	"DemoServer_ConnectionManager/data"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+
+	"github.com/google/uuid"
+)
+
+const (
+	roleBindingsPath = "/v1/connectionmgmt/rolebindings"
+	roleBindingPath  = "/v1/connectionmgmt/rolebinding"
+)
+
+// funcRoleBinding_Tokens returns bearer tokens of admin and of subject without bindings along with sub claim of the
+// latter. Enforcement tests need service running with authentication and authorization enabled, subject of admin
+// token listed in authorization admins, and tokens set through DEMOSERVER_CONNECTIONMANAGER_E2E_ADMIN_TOKEN,
+// DEMOSERVER_CONNECTIONMANAGER_E2E_SUBJECT_TOKEN and DEMOSERVER_CONNECTIONMANAGER_E2E_SUBJECT.
+func (s *EndToEndSuite) funcRoleBinding_Tokens() (string, string, string) {
+	adminToken := os.Getenv("DEMOSERVER_CONNECTIONMANAGER_E2E_ADMIN_TOKEN")
+	subjectToken := os.Getenv("DEMOSERVER_CONNECTIONMANAGER_E2E_SUBJECT_TOKEN")
+	subject := os.Getenv("DEMOSERVER_CONNECTIONMANAGER_E2E_SUBJECT")
+
+	if adminToken == "" || subjectToken == "" || subject == "" {
+		s.T().Skip("DEMOSERVER_CONNECTIONMANAGER_E2E_ADMIN_TOKEN, DEMOSERVER_CONNECTIONMANAGER_E2E_SUBJECT_TOKEN or DEMOSERVER_CONNECTIONMANAGER_E2E_SUBJECT not set")
+	}
+
+	return adminToken, subjectToken, subject
+}
+
+// funcRoleBinding_Request sends request with optional JSON payload on behalf of bearer of token and returns status
+// of response along with its body.
+func (s *EndToEndSuite) funcRoleBinding_Request(method string, path string, token string, payload interface{}) (int, []byte) {
+	c := http.Client{}
+
+	ip, port := GetIPAndPort()
+
+	var body io.Reader
+	if payload != nil {
+		jsonData, err := json.Marshal(payload)
+		if err != nil {
+			s.True(false, "Error marshalling payload into JSON:", err)
+		}
+		body = bytes.NewBuffer(jsonData)
+	}
+
+	req, err := http.NewRequest(method, prefixHTTP+ip+":"+port+path, body)
+	if err != nil {
+		s.True(false, "Request creation failed")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	r, err := c.Do(req)
+
+	if err != nil {
+		fmt.Printf("%s request received error: %s\n", method, err.Error())
+		s.True(false)
+	} else {
+		if r == nil {
+			fmt.Printf("No error but resonse object is nil.\n")
+			s.True(false)
+		}
+	}
+
+	defer func() { _ = r.Body.Close() }()
+
+	b, _ := io.ReadAll(r.Body)
+
+	return r.StatusCode, b
+}
+
+// funcRoleBinding_AddPKIConnection adds PKIConnection as admin and returns it.
+func (s *EndToEndSuite) funcRoleBinding_AddPKIConnection(adminToken string) data.PKIConnectionResponseWrapper {
+	dc := s.funcLoadDummyPKIConnection()
+	dc.Connection.Name = dc.Connection.Name + uuid.New().String()
+
+	status, b := s.funcRoleBinding_Request(http.MethodPost, addPKIConnectionPath, adminToken, dc)
+	s.Equal(http.StatusOK, status, "HTTP Status Code comparison failed. Expected %d, Received: %d. Body: %s", http.StatusOK, status, string(b))
+
+	var rc data.PKIConnectionResponseWrapper
+
+	err := json.Unmarshal(b, &rc)
+	if err != nil {
+		s.True(false, "Error unmarshalling response into JSON:", err)
+	}
+
+	return rc
+}
+
+func (s *EndToEndSuite) funcRoleBinding_DeletePKIConnection(adminToken string, id string) {
+	status, b := s.funcRoleBinding_Request(http.MethodDelete, deletePKIConnectionPath+"/"+id, adminToken, nil)
+	s.Equal(http.StatusOK, status, "HTTP Status Code comparison failed. Expected %d, Received: %d. Body: %s", http.StatusOK, status, string(b))
+}
+
+func (s *EndToEndSuite) TestPositive_Functional_RoleBinding_Enforcement() {
+	adminToken, subjectToken, subject := s.funcRoleBinding_Tokens()
+
+	bound := s.funcRoleBinding_AddPKIConnection(adminToken)
+	defer s.funcRoleBinding_DeletePKIConnection(adminToken, bound.ID.String())
+
+	other := s.funcRoleBinding_AddPKIConnection(adminToken)
+	defer s.funcRoleBinding_DeletePKIConnection(adminToken, other.ID.String())
+
+	// Subject without bindings is denied
+	_, b := s.funcRoleBinding_Request(http.MethodGet, addPKIConnectionPath+"/"+bound.ID.String(), subjectToken, nil)
+	s.funcAWSRole_Error(b, http.StatusForbidden, "ConnectionManager_Err_000068")
+
+	post := data.RoleBindingPostWrapper{Subject: subject, Role: data.RoleViewer, ConnectionID: bound.ConnectionID.String()}
+
+	status, b := s.funcRoleBinding_Request(http.MethodPost, roleBindingPath, adminToken, post)
+	s.Equal(http.StatusOK, status, "HTTP Status Code comparison failed. Expected %d, Received: %d. Body: %s", http.StatusOK, status, string(b))
+
+	var binding data.RoleBinding
+
+	err := json.Unmarshal(b, &binding)
+	if err != nil {
+		s.True(false, "Error unmarshalling response into JSON:", err)
+	}
+
+	defer s.funcRoleBinding_Request(http.MethodDelete, roleBindingPath+"/"+binding.ID.String(), adminToken, nil)
+
+	s.Equal(subject, binding.Subject, "Unexpected Subject")
+	s.Equal(data.RoleViewer, binding.Role, "Unexpected Role")
+
+	// Role bound on connection allows its permissions on that connection
+	status, b = s.funcRoleBinding_Request(http.MethodGet, addPKIConnectionPath+"/"+bound.ID.String(), subjectToken, nil)
+	s.Equal(http.StatusOK, status, "HTTP Status Code comparison failed. Expected %d, Received: %d. Body: %s", http.StatusOK, status, string(b))
+
+	var rc data.PKIConnectionResponseWrapper
+
+	err = json.Unmarshal(b, &rc)
+	if err != nil {
+		s.True(false, "Error unmarshalling response into JSON:", err)
+	}
+
+	s.Equal(bound.ID, rc.ID, "Unexpected ID. Expected: %s, Received: %s", bound.ID, rc.ID)
+
+	// Permissions not granted by role are denied on same connection
+	_, b = s.funcRoleBinding_Request(http.MethodDelete, deletePKIConnectionPath+"/"+bound.ID.String(), subjectToken, nil)
+	s.funcAWSRole_Error(b, http.StatusForbidden, "ConnectionManager_Err_000068")
+
+	_, b = s.funcRoleBinding_Request(http.MethodGet, addPKIConnectionPath+"/"+bound.ID.String()+"/test", subjectToken, nil)
+	s.funcAWSRole_Error(b, http.StatusForbidden, "ConnectionManager_Err_000068")
+
+	// Role bound on one connection grants nothing on another
+	_, b = s.funcRoleBinding_Request(http.MethodGet, addPKIConnectionPath+"/"+other.ID.String(), subjectToken, nil)
+	s.funcAWSRole_Error(b, http.StatusForbidden, "ConnectionManager_Err_000068")
+
+	// Bindings are managed by admins only
+	post.Role = data.RoleAdmin
+	_, b = s.funcRoleBinding_Request(http.MethodPost, roleBindingPath, subjectToken, post)
+	s.funcAWSRole_Error(b, http.StatusForbidden, "ConnectionManager_Err_000068")
+}
+
+func (s *EndToEndSuite) TestNegative_Functional_RoleBindingsGet_InvalidConnectionID() {
+	ip, port := GetIPAndPort()
+
+	url := prefixHTTP + ip + ":" + port + roleBindingsPath + "?connectionid=abc"
+
+	s.funcLease_ErrorResponse(http.MethodGet, url, http.StatusBadRequest, "ConnectionManager_Err_000001")
+}
+
+func (s *EndToEndSuite) TestNegative_Functional_RoleBindingDelete_NotFound() {
+	ip, port := GetIPAndPort()
+
+	url := prefixHTTP + ip + ":" + port + roleBindingPath + "/" + uuid.New().String()
+
+	s.funcLease_ErrorResponse(http.MethodDelete, url, http.StatusNotFound, "ConnectionManager_Err_000002")
+}
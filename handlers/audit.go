@@ -0,0 +1,376 @@
+package handlers
+
+import (
+	"DemoServer_ConnectionManager/auth"
+	"DemoServer_ConnectionManager/configuration"
+	"DemoServer_ConnectionManager/data"
+	"DemoServer_ConnectionManager/datalayer"
+	"DemoServer_ConnectionManager/helper"
+	"DemoServer_ConnectionManager/utilities"
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type AuditHandler struct {
+	l          *slog.Logger
+	cfg        *configuration.Config
+	pd         *datalayer.PostgresDataSource
+	pe         *auth.PolicyEngine
+	list_limit int
+}
+
+func NewAuditHandler(cfg *configuration.Config, l *slog.Logger, pd *datalayer.PostgresDataSource, pe *auth.PolicyEngine) (*AuditHandler, error) {
+	var c AuditHandler
+
+	c.cfg = cfg
+	c.l = l
+	c.pd = pd
+	c.pe = pe
+	c.list_limit = cfg.Server.ListLimit
+
+	return &c, nil
+}
+
+// requester returns identity of caller recorded with audit records and leases. Subject of bearer token is used
+// when caller was authenticated, X-Requester header otherwise.
+func requester(r *http.Request) string {
+	if p, ok := helper.PrincipalFromContext(r.Context()); ok {
+		return p.Subject
+	}
+	return r.Header.Get("X-Requester")
+}
+
+// application returns identity of calling application which credentials are issued to. Claim of bearer token
+// named by claim is used when caller was authenticated, falling back to subject if claim is missing. X-Requester
+// header is used otherwise.
+func application(r *http.Request, claim string) string {
+	if p, ok := helper.PrincipalFromContext(r.Context()); ok {
+		if v := p.Claim(claim); claim != "" && v != "" {
+			return v
+		}
+		return p.Subject
+	}
+	return r.Header.Get("X-Requester")
+}
+
+// revealScope is scope which permits reveal of fields masked in responses.
+const revealScope = "connectionmgmt:reveal"
+
+// hasScope tells whether caller was granted scope. Only scopes of verified bearer token are honoured, so scope is
+// never granted when authentication is disabled.
+func hasScope(r *http.Request, scope string) bool {
+	if p, ok := helper.PrincipalFromContext(r.Context()); ok {
+		return p.HasScope(scope)
+	}
+	return false
+}
+
+// revealRequested tells whether caller asked for masked fields to be revealed through reveal parameter. Error
+// is returned to caller if value of reveal is invalid or caller lacks revealScope.
+func revealRequested(r *http.Request, cl *slog.Logger, requestid string, w *http.ResponseWriter, span trace.Span) (bool, error) {
+	value := r.URL.Query().Get("reveal")
+	if value == "" {
+		return false, nil
+	}
+
+	reveal, err := strconv.ParseBool(value)
+	if err != nil {
+		helper.ReturnError(cl, http.StatusBadRequest, helper.ErrorInvalidValueForReveal, err, requestid, r, w, span)
+		return false, err
+	}
+
+	if reveal && !hasScope(r, revealScope) {
+		err = fmt.Errorf("requester %s lacks scope %s", requester(r), revealScope)
+		helper.ReturnError(cl, http.StatusForbidden, helper.ErrorRevealNotPermitted, err, requestid, r, w, span)
+		return false, err
+	}
+
+	return reveal, nil
+}
+
+// saveWithAudit saves obj and audit record of action which changed it in single transaction.
+func saveWithAudit[T any](pd *datalayer.PostgresDataSource, obj *T, a *data.AuditRecord, ctx context.Context, tracerName string) error {
+
+	tr := otel.Tracer(tracerName)
+	ctx, span := tr.Start(ctx, utilities.GetFunctionName())
+	defer span.End()
+
+	// Begin a transaction
+	tx := pd.RWDB().Begin()
+
+	// Check if the transaction started successfully
+	if tx.Error != nil {
+		return tx.Error
+	}
+
+	if err := utilities.UpdateObjectWithoutTx(tx, obj, ctx, tracerName); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := utilities.CreateObjectWithoutTx(tx, a, ctx, tracerName); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// Commit the transaction
+	if err := tx.Commit().Error; err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// recordAuditFailure persists audit record of failed action. Record is written outside of transaction
+// of action as that transaction has been rolled back.
+func recordAuditFailure(pd *datalayer.PostgresDataSource, a *data.AuditRecord, cause error, cl *slog.Logger, ctx context.Context, tracerName string) {
+
+	tr := otel.Tracer(tracerName)
+	ctx, span := tr.Start(ctx, utilities.GetFunctionName())
+	defer span.End()
+
+	a.SetFailed(cause.Error())
+
+	if err := utilities.CreateObject(pd.RWDB(), a, ctx, tracerName); err != nil {
+		helper.LogError(cl, helper.ErrorDatastoreSaveFailed, err, span)
+	}
+}
+
+// auditFilter represents filters supported by GET on audit endpoint.
+type auditFilter struct {
+	connectionID string
+	action       *data.ActionTypeEnum
+	status       *data.ActionStatusTypeEnum
+	from         *time.Time
+	to           *time.Time
+}
+
+func (h *AuditHandler) fetchAuditRecords(scope connectionScope, f auditFilter, limit, skip int) ([]data.AuditRecord, error) {
+	var records []data.AuditRecord
+
+	q := scope.apply(h.pd.RODB().Model(&data.AuditRecord{}), "connection_id")
+
+	if f.connectionID != "" {
+		q = q.Where("connection_id = ?", f.connectionID)
+	}
+
+	if f.action != nil {
+		q = q.Where("action = ?", *f.action)
+	}
+
+	if f.status != nil {
+		q = q.Where("status = ?", *f.status)
+	}
+
+	if f.from != nil {
+		q = q.Where("created_at >= ?", *f.from)
+	}
+
+	if f.to != nil {
+		q = q.Where("created_at <= ?", *f.to)
+	}
+
+	result := q.
+		Limit(limit).
+		Offset(skip).
+		Order("created_at desc").
+		Find(&records)
+
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return records, nil
+}
+
+// parseAuditFilter parses filters of GET on audit endpoint. Filters are validated by MiddlewareValidateAuditGet.
+func parseAuditFilter(r *http.Request) auditFilter {
+	var f auditFilter
+
+	vars := r.URL.Query()
+
+	f.connectionID = vars.Get("connectionid")
+
+	if action, err := data.ParseActionType(vars.Get("action")); err == nil && vars.Get("action") != "" {
+		f.action = &action
+	}
+
+	if status, err := data.ParseActionStatusType(vars.Get("status")); err == nil && vars.Get("status") != "" {
+		f.status = &status
+	}
+
+	if from, err := time.Parse(time.RFC3339, vars.Get("from")); err == nil {
+		f.from = &from
+	}
+
+	if to, err := time.Parse(time.RFC3339, vars.Get("to")); err == nil {
+		f.to = &to
+	}
+
+	return f
+}
+
+func (h *AuditHandler) GetAuditRecords(w http.ResponseWriter, r *http.Request) {
+
+	// swagger:operation GET /audit Audit GetAuditRecords
+	// List Audit Records
+	//
+	// Endpoint: GET - /v1/connectionmgmt/audit
+	//
+	// Description: Returns audit trail of actions performed on connections, most recent first.
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: connectionid
+	//   in: query
+	//   description: id of generic Connection resource. expected to be in uuid format i.e. XXXXXXXX-XXXX-XXXX-XXXX-XXXXXXXXXXXX
+	//   required: false
+	//   type: string
+	// - name: action
+	//   in: query
+	//   description: action to filter on i.e. create, update, delete, test, link, unlink, issuecredentials, renewlease, revokelease, createrole, updaterole, deleterole, repairmount, rotateroot, bindrole, unbindrole
+	//   required: false
+	//   type: string
+	// - name: status
+	//   in: query
+	//   description: status to filter on i.e. successful, failed
+	//   required: false
+	//   type: string
+	// - name: from
+	//   in: query
+	//   description: only records created at or after this time are returned. RFC3339 format i.e. 2024-01-02T15:04:05Z
+	//   required: false
+	//   type: string
+	// - name: to
+	//   in: query
+	//   description: only records created at or before this time are returned. RFC3339 format i.e. 2024-01-02T15:04:05Z
+	//   required: false
+	//   type: string
+	// - name: limit
+	//   in: query
+	//   description: maximum number of results to return.
+	//   required: false
+	//   type: integer
+	//   format: int32
+	// - name: skip
+	//   in: query
+	//   description: number of results to be skipped from beginning of list
+	//   required: false
+	//   type: integer
+	//   format: int32
+	// responses:
+	//   '200':
+	//     description: List of AuditRecord resources
+	//     schema:
+	//         "$ref": "#/definitions/AuditRecordsResponse"
+	//   '400':
+	//     description: Issues with parameters or their value
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '500':
+	//     description: Internal server error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   default:
+	//     description: unexpected error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	ctx, span, requestid, cl := utilities.SetupTraceAndLogger(r, w, h.l, utilities.GetFunctionName(), h.cfg.Server.PrefixMain)
+	defer span.End()
+
+	scope, err := authorizedConnections(h.pe, auth.PermissionView, ctx, cl, requestid, r, &w, span)
+	if err != nil {
+		return
+	}
+
+	vars := r.URL.Query()
+	limit := utilities.ParseQueryParam(vars, "limit", h.list_limit, h.cfg.DataLayer.MaxResults)
+	skip := utilities.ParseQueryParam(vars, "skip", 0, math.MaxInt32)
+
+	records, err := h.fetchAuditRecords(scope, parseAuditFilter(r), limit, skip)
+	if err != nil {
+		helper.ReturnError(cl, http.StatusInternalServerError, helper.ErrorDatastoreRetrievalFailed, err, requestid, r, &w, span)
+		return
+	}
+
+	response := data.AuditRecordsResponse{
+		Total:        len(records),
+		Skip:         skip,
+		Limit:        limit,
+		AuditRecords: records,
+	}
+
+	if response.AuditRecords == nil {
+		response.AuditRecords = []data.AuditRecord{}
+	}
+
+	utilities.WriteResponse(w, cl, response, span)
+}
+
+func (h AuditHandler) MiddlewareValidateAuditGet(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+
+		_, span, requestid, cl := utilities.SetupTraceAndLogger(r, rw, h.l, utilities.GetFunctionName(), h.cfg.Server.PrefixMain)
+		defer span.End()
+
+		vars := r.URL.Query()
+
+		// Validate limit parameter
+		if err := utilities.ValidateQueryParam(vars.Get("limit"), 1, true, cl, r, rw, span, requestid, helper.ErrorInvalidValueForLimit); err != nil {
+			return
+		}
+
+		// Validate skip parameter
+		if err := utilities.ValidateQueryParam(vars.Get("skip"), 0, false, cl, r, rw, span, requestid, helper.ErrorInvalidValueForSkip); err != nil {
+			return
+		}
+
+		// Validate connectionid parameter
+		if connectionid := vars.Get("connectionid"); connectionid != "" {
+			if _, err := uuid.Parse(connectionid); err != nil {
+				helper.ReturnError(cl, http.StatusBadRequest, helper.ErrorConnectionIDInvalid, err, requestid, r, &rw, span)
+				return
+			}
+		}
+
+		// Validate action parameter
+		if action := vars.Get("action"); action != "" {
+			if _, err := data.ParseActionType(action); err != nil {
+				helper.ReturnError(cl, http.StatusBadRequest, helper.ErrorInvalidValueForAction, err, requestid, r, &rw, span)
+				return
+			}
+		}
+
+		// Validate status parameter
+		if status := vars.Get("status"); status != "" {
+			if _, err := data.ParseActionStatusType(status); err != nil {
+				helper.ReturnError(cl, http.StatusBadRequest, helper.ErrorInvalidValueForStatus, err, requestid, r, &rw, span)
+				return
+			}
+		}
+
+		// Validate from and to parameters
+		for _, param := range []string{"from", "to"} {
+			if value := vars.Get(param); value != "" {
+				if _, err := time.Parse(time.RFC3339, value); err != nil {
+					helper.ReturnError(cl, http.StatusBadRequest, helper.ErrorInvalidValueForTimeRange, err, requestid, r, &rw, span)
+					return
+				}
+			}
+		}
+
+		// Call the next handler, which can be another middleware in the chain, or the final handler.
+		next.ServeHTTP(rw, r)
+	})
+}
@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"DemoServer_ConnectionManager/auth"
+	"DemoServer_ConnectionManager/data"
+	"DemoServer_ConnectionManager/datalayer"
+	"DemoServer_ConnectionManager/helper"
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+// authorize returns error to caller unless caller holds permission on connection. Only global role bindings are
+// considered if connectionID is uuid.Nil.
+func authorize(pe *auth.PolicyEngine, p auth.Permission, connectionID uuid.UUID, ctx context.Context, cl *slog.Logger, requestid string, r *http.Request, w *http.ResponseWriter, span trace.Span) error {
+	allowed, err := pe.Authorize(ctx, requester(r), p, connectionID)
+	if err != nil {
+		helper.ReturnError(cl, http.StatusInternalServerError, helper.ErrorDatastoreRetrievalFailed, err, requestid, r, w, span)
+		return err
+	}
+
+	if !allowed {
+		err = fmt.Errorf("%w: requester %s lacks permission %s", helper.ErrPermissionDenied, requester(r), p)
+		if connectionID != uuid.Nil {
+			err = fmt.Errorf("%w on connection %s", err, connectionID)
+		}
+		helper.ReturnError(cl, http.StatusForbidden, helper.ErrorPermissionDenied, err, requestid, r, w, span)
+		return err
+	}
+
+	return nil
+}
+
+// authorizeApplication returns link of application to connection, or error to caller unless applicationID identifies
+// calling application and application is linked to connection. Credentials are only issued to applications linked
+// to connection.
+func authorizeApplication(pd *datalayer.PostgresDataSource, connectionID uuid.UUID, applicationID string, claim string, cl *slog.Logger, requestid string, r *http.Request, w *http.ResponseWriter, span trace.Span) (*data.ConnectionLink, error) {
+	if caller := application(r, claim); caller != applicationID {
+		err := fmt.Errorf("%w: applicationid %s, caller %s", helper.ErrApplicationIdentityMismatch, applicationID, caller)
+		helper.ReturnError(cl, http.StatusForbidden, helper.ErrorApplicationIdentityMismatch, err, requestid, r, w, span)
+		return nil, err
+	}
+
+	link, err := getConnectionLink(pd, connectionID, applicationID)
+	if err != nil {
+		helper.ReturnError(cl, http.StatusInternalServerError, helper.ErrorDatastoreRetrievalFailed, err, requestid, r, w, span)
+		return nil, err
+	}
+
+	if err := requireConnectionLink(link, connectionID, applicationID, cl, requestid, r, w, span); err != nil {
+		return nil, err
+	}
+
+	return link, nil
+}
+
+// requireConnectionLink returns error to caller if application is not linked to connection, i.e. link is nil.
+func requireConnectionLink(link *data.ConnectionLink, connectionID uuid.UUID, applicationID string, cl *slog.Logger, requestid string, r *http.Request, w *http.ResponseWriter, span trace.Span) error {
+	if link == nil {
+		err := fmt.Errorf("%w: application %s, connection %s", helper.ErrApplicationNotLinked, applicationID, connectionID)
+		helper.ReturnError(cl, http.StatusForbidden, helper.ErrorApplicationNotLinked, err, requestid, r, w, span)
+		return err
+	}
+
+	return nil
+}
+
+// validateApplicationIDParam returns error to caller unless applicationid parameter of credentials request is
+// present and in uuid format.
+func validateApplicationIDParam(r *http.Request, rw http.ResponseWriter, cl *slog.Logger, requestid string, span trace.Span) error {
+	applicationid := r.URL.Query().Get("applicationid")
+	if applicationid == "" {
+		helper.ReturnError(cl, http.StatusBadRequest, helper.ErrorApplicationIDRequired, helper.ErrApplicationIDRequired, requestid, r, &rw, span)
+		return helper.ErrApplicationIDRequired
+	}
+
+	if _, err := uuid.Parse(applicationid); err != nil {
+		helper.ReturnError(cl, http.StatusBadRequest, helper.ErrorApplicationIDInvalid, err, requestid, r, &rw, span)
+		return err
+	}
+
+	return nil
+}
+
+// connectionScope holds connections caller holds permission on.
+type connectionScope struct {
+	global        bool
+	connectionIDs []uuid.UUID
+}
+
+// apply restricts q to rows whose column holds ID of connection in scope.
+func (s connectionScope) apply(q *gorm.DB, column string) *gorm.DB {
+	if s.global {
+		return q
+	}
+
+	if len(s.connectionIDs) == 0 {
+		return q.Where("1 = 0")
+	}
+
+	return q.Where(column+" IN ?", s.connectionIDs)
+}
+
+// authorizedConnections returns connections caller holds permission on so that lists can be filtered to
+// connections visible to caller.
+func authorizedConnections(pe *auth.PolicyEngine, p auth.Permission, ctx context.Context, cl *slog.Logger, requestid string, r *http.Request, w *http.ResponseWriter, span trace.Span) (connectionScope, error) {
+	global, connectionIDs, err := pe.Scope(ctx, requester(r), p)
+	if err != nil {
+		helper.ReturnError(cl, http.StatusInternalServerError, helper.ErrorDatastoreRetrievalFailed, err, requestid, r, w, span)
+		return connectionScope{}, err
+	}
+
+	return connectionScope{global: global, connectionIDs: connectionIDs}, nil
+}
@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"DemoServer_ConnectionManager/data"
+	"DemoServer_ConnectionManager/helper"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// gate runs check against request sent on behalf of requester and returns error response written by it, if any.
+// Checks exercised this way never reach Postgres.
+func gate(t *testing.T, url string, requester string, check func(cl *slog.Logger, r *http.Request, w *http.ResponseWriter, span trace.Span) error) (helper.ErrorResponse, error) {
+	req := httptest.NewRequest(http.MethodGet, url, nil)
+	if requester != "" {
+		req.Header.Set("X-Requester", requester)
+	}
+
+	rec := httptest.NewRecorder()
+	var w http.ResponseWriter = rec
+
+	cl := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	err := check(cl, req, &w, trace.SpanFromContext(context.Background()))
+
+	var response helper.ErrorResponse
+	if err != nil {
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response), rec.Body.String())
+		require.Equal(t, response.Status, rec.Code)
+	}
+
+	return response, err
+}
+
+func TestAuthorizeApplication_IdentityMismatch(t *testing.T) {
+	applicationID := uuid.New().String()
+
+	// Caller is refused before link is looked up
+	response, err := gate(t, "/v1/connectionmgmt/connection/aws/1/creds?applicationid="+applicationID, uuid.New().String(), func(cl *slog.Logger, r *http.Request, w *http.ResponseWriter, span trace.Span) error {
+		_, err := authorizeApplication(nil, uuid.New(), applicationID, "", cl, "request-1", r, w, span)
+		return err
+	})
+	require.ErrorIs(t, err, helper.ErrApplicationIdentityMismatch)
+	require.Equal(t, http.StatusForbidden, response.Status)
+	require.Equal(t, "ConnectionManager_Err_000071", response.ErrorCode)
+}
+
+func TestRequireConnectionLink_NotLinked(t *testing.T) {
+	applicationID := uuid.New().String()
+
+	response, err := gate(t, "/v1/connectionmgmt/connection/aws/1/creds", applicationID, func(cl *slog.Logger, r *http.Request, w *http.ResponseWriter, span trace.Span) error {
+		return requireConnectionLink(nil, uuid.New(), applicationID, cl, "request-1", r, w, span)
+	})
+	require.ErrorIs(t, err, helper.ErrApplicationNotLinked)
+	require.Equal(t, http.StatusForbidden, response.Status)
+	require.Equal(t, "ConnectionManager_Err_000072", response.ErrorCode)
+}
+
+func TestCheckCredsGrant(t *testing.T) {
+	applicationID := uuid.New().String()
+
+	tested := data.Connection{ID: uuid.New(), TestSuccessful: 1}
+	untested := data.Connection{ID: uuid.New()}
+
+	link := data.NewConnectionLink(tested.ID, applicationID, "alice")
+	restricted := data.NewConnectionLink(tested.ID, applicationID, "alice")
+	restricted.AllowedRoles = []string{"reader"}
+
+	tests := []struct {
+		name       string
+		connection *data.Connection
+		link       *data.ConnectionLink
+		role       string
+		status     int
+		errorCode  string
+	}{
+		{"granted", &tested, link, "default", 0, ""},
+		{"granted for allowed role", &tested, restricted, "reader", 0, ""},
+		{"role not allowed", &tested, restricted, "default", http.StatusForbidden, "ConnectionManager_Err_000074"},
+		{"not tested", &untested, link, "default", http.StatusConflict, "ConnectionManager_Err_000032"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response, err := gate(t, "/v1/connectionmgmt/connection/aws/1/creds", applicationID, func(cl *slog.Logger, r *http.Request, w *http.ResponseWriter, span trace.Span) error {
+				return checkCredsGrant(tt.connection, tt.link, tt.role, cl, "request-1", r, w, span)
+			})
+
+			if tt.status == 0 {
+				require.NoError(t, err)
+				return
+			}
+
+			require.Error(t, err)
+			require.Equal(t, tt.status, response.Status)
+			require.Equal(t, tt.errorCode, response.ErrorCode)
+		})
+	}
+}
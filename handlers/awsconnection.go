@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"DemoServer_ConnectionManager/auth"
 	"DemoServer_ConnectionManager/configuration"
 	"DemoServer_ConnectionManager/data"
 	"DemoServer_ConnectionManager/datalayer"
@@ -11,35 +12,60 @@ import (
 	"encoding/json"
 	"fmt"
 	"log/slog"
-	"math"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
-	"github.com/gorilla/mux"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
 )
 
 type KeyAWSConnectionRecord struct{}
 type KeyAWSConnectionPatchParamsRecord struct{}
 
 type AWSConnectionHandler struct {
-	l          *slog.Logger
-	cfg        *configuration.Config
-	pd         *datalayer.PostgresDataSource
-	vh         *secretsmanager.VaultHandler
-	list_limit int
+	connectionHandler[data.AWSConnection, *data.AWSConnection]
+	roleHandler[data.AWSConnection, *data.AWSConnection, data.AWSRole, *data.AWSRole]
 }
 
-func NewAWSConnectionHandler(cfg *configuration.Config, l *slog.Logger, pd *datalayer.PostgresDataSource, vh *secretsmanager.VaultHandler) (*AWSConnectionHandler, error) {
+func NewAWSConnectionHandler(cfg *configuration.Config, l *slog.Logger, pd *datalayer.PostgresDataSource, sb secretsmanager.SecretsBackend, pe *auth.PolicyEngine) (*AWSConnectionHandler, error) {
 	var c AWSConnectionHandler
 
-	c.cfg = cfg
-	c.l = l
-	c.pd = pd
-	c.list_limit = cfg.Server.ListLimit
-	c.vh = vh
+	c.connectionHandler = newConnectionHandler[data.AWSConnection, *data.AWSConnection](cfg, l, pd, sb, pe, connectionEngine[data.AWSConnection]{
+		table:   "aws_connections",
+		load:    sb.GetAWSSecretsEngine,
+		mount:   sb.AddAWSSecretsEngine,
+		update:  c.remountAWSConnection,
+		unmount: sb.RemoveAWSSecretsEngine,
+		test: func(c *data.AWSConnection, roleName string, ctx context.Context) error {
+			return sb.TestAWSSecretsEngine(c.VaultPath, c.VaultNamespace, roleName, ctx)
+		},
+		mountFailed: helper.ErrorVaultAWSEngineFailed,
+		validate:    c.validateAWSConnection,
+		resolveRole: c.resolveRole,
+		records: func(c *data.AWSConnection) []interface{} {
+			return []interface{}{c.DefaultRole()}
+		},
+		// Remount configures default role again, including one lost with mount re-created by reconciler
+		updateRecords: func(tx *gorm.DB, c *data.AWSConnection) error {
+			return tx.Model(&data.AWSRole{}).Where("aws_connection_id = ? AND vault_role_missing = ?", c.ID, true).Update("vault_role_missing", false).Error
+		},
+		deleteRecords: c.deleteRoles,
+	})
+
+	c.roleHandler = newRoleHandler[data.AWSConnection, *data.AWSConnection, data.AWSRole, *data.AWSRole](&c.connectionHandler, roleEngine[data.AWSConnection, data.AWSRole]{
+		column:    "aws_connection_id",
+		owner:     func(c *data.AWSConnection) uuid.UUID { return c.ID },
+		newRole:   data.NewAWSRole,
+		load:      c.loadAWSRole,
+		add:       sb.AddAWSSecretsEngineRole,
+		update:    sb.UpdateAWSSecretsEngineRole,
+		remove:    sb.RemoveAWSSecretsEngineRole,
+		validName: data.IsValidAWSRoleName,
+		validate:  validateAWSRole,
+	})
 
 	return &c, nil
 }
@@ -71,6 +97,11 @@ func (h *AWSConnectionHandler) GetAWSConnections(w http.ResponseWriter, r *http.
 	//   required: false
 	//   type: integer
 	//   format: int32
+	// - name: reveal
+	//   in: query
+	//   description: true to return accesskey unmasked. Requires connectionmgmt:reveal scope in bearer token, so reveal is refused when authentication is disabled. accesskey is masked i.e. AKIA****WXYZ otherwise.
+	//   required: false
+	//   type: boolean
 	// responses:
 	//   '200':
 	//     description: List of AWSConnection resources
@@ -82,6 +113,10 @@ func (h *AWSConnectionHandler) GetAWSConnections(w http.ResponseWriter, r *http.
 	//     description: Issues with parameters or their value
 	//     schema:
 	//       "$ref": "#/definitions/ErrorResponse"
+	//   '403':
+	//     description: Reveal requested without connectionmgmt:reveal scope
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
 	//   '500':
 	//     description: Internal server error
 	//     schema:
@@ -94,90 +129,29 @@ func (h *AWSConnectionHandler) GetAWSConnections(w http.ResponseWriter, r *http.
 	ctx, span, requestid, cl := utilities.SetupTraceAndLogger(r, w, h.l, utilities.GetFunctionName(), h.cfg.Server.PrefixMain)
 	defer span.End()
 
-	vars := r.URL.Query()
-	limit := utilities.ParseQueryParam(vars, "limit", h.list_limit, h.cfg.DataLayer.MaxResults)
-	skip := utilities.ParseQueryParam(vars, "skip", 0, math.MaxInt32)
-
-	connections, err := h.fetchAWSConnections(limit, skip)
+	reveal, err := revealRequested(r, cl, requestid, &w, span)
 	if err != nil {
-		helper.ReturnError(cl, http.StatusInternalServerError, helper.ErrorDatastoreRetrievalFailed, err, requestid, r, &w, span)
 		return
 	}
 
-	response, err := h.buildAWSConnectionsResponse(ctx, connections, limit, skip)
+	connections, limit, skip, err := h.listConnections(ctx, cl, requestid, r, &w, span)
 	if err != nil {
-		helper.ReturnError(cl, http.StatusInternalServerError, helper.ErrorVaultLoadFailed, err, requestid, r, &w, span)
 		return
 	}
 
-	utilities.WriteResponse(w, cl, response, span)
-}
-
-func (h *AWSConnectionHandler) fetchAWSConnections(limit, skip int) ([]data.AWSConnection, error) {
-	var connections []data.AWSConnection
-
-	result := h.pd.RODB().
-		Preload("Connection").
-		Limit(limit).
-		Offset(skip).
-		Order("connections.name").
-		Joins("LEFT JOIN connections ON connections.id = aws_connections.connection_id").
-		Find(&connections)
-
-	if result.Error != nil {
-		return nil, result.Error
+	wrapped, err := connectionResponses[data.AWSConnectionResponseWrapper](connections, cl, requestid, r, &w, span)
+	if err != nil {
+		return
 	}
-	return connections, nil
-}
 
-func (h *AWSConnectionHandler) buildAWSConnectionsResponse(ctx context.Context, connections []data.AWSConnection, limit, skip int) (data.AWSConnectionsResponse, error) {
 	response := data.AWSConnectionsResponse{
-		Total: len(connections),
-		Skip:  skip,
-		Limit: limit,
-	}
-
-	if len(connections) == 0 {
-		response.AWSConnections = []data.AWSConnectionResponseWrapper{}
-		return response, nil
+		Total:          len(connections),
+		Skip:           skip,
+		Limit:          limit,
+		AWSConnections: wrapped,
 	}
 
-	for _, conn := range connections {
-		if err := h.vh.GetAWSSecretsEngine(&conn, ctx); err != nil {
-			return response, err
-		}
-
-		var wrappedConn data.AWSConnectionResponseWrapper
-		if err := utilities.CopyMatchingFields(conn, &wrappedConn); err != nil {
-			return response, err
-		}
-		response.AWSConnections = append(response.AWSConnections, wrappedConn)
-	}
-
-	return response, nil
-}
-
-func (h AWSConnectionHandler) MiddlewareValidateAWSConnectionsGet(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
-
-		_, span, requestid, cl := utilities.SetupTraceAndLogger(r, rw, h.l, utilities.GetFunctionName(), h.cfg.Server.PrefixMain)
-		defer span.End()
-
-		vars := r.URL.Query()
-
-		// Validate limit parameter
-		if err := utilities.ValidateQueryParam(vars.Get("limit"), 1, true, cl, r, rw, span, requestid, helper.ErrorInvalidValueForLimit); err != nil {
-			return
-		}
-
-		// Validate skip parameter
-		if err := utilities.ValidateQueryParam(vars.Get("skip"), 0, false, cl, r, rw, span, requestid, helper.ErrorInvalidValueForSkip); err != nil {
-			return
-		}
-
-		// Call the next handler, which can be another middleware in the chain, or the final handler.
-		next.ServeHTTP(rw, r)
-	})
+	utilities.WriteResponseWithReveal(w, cl, response, reveal, span)
 }
 
 // GetAWSConnection returns AWSConnection resource based on connectionid parameter
@@ -199,11 +173,24 @@ func (h *AWSConnectionHandler) GetAWSConnection(w http.ResponseWriter, r *http.R
 	//   description: id for AWSConnection resource to be retrieved. expected to be in uuid format i.e. XXXXXXXX-XXXX-XXXX-XXXX-XXXXXXXXXXXX
 	//   required: true
 	//   type: string
+	// - name: reveal
+	//   in: query
+	//   description: true to return accesskey unmasked. Requires connectionmgmt:reveal scope in bearer token, so reveal is refused when authentication is disabled. accesskey is masked i.e. AKIA****WXYZ otherwise.
+	//   required: false
+	//   type: boolean
 	// responses:
 	//   '200':
 	//     description: AWSConnection resource
 	//     schema:
 	//         "$ref": "#/definitions/AWSConnection"
+	//   '400':
+	//     description: Issues with parameters or their value
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '403':
+	//     description: Reveal requested without connectionmgmt:reveal scope or caller lacks viewer role on connection
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
 	//   '404':
 	//     description: Resource not found. Resources are filtered based on connectiontype = AWSConnectionType. If connectionid of Non-AWSConnection is provided ResourceNotFound error is returned.
 	//     schema:
@@ -220,32 +207,37 @@ func (h *AWSConnectionHandler) GetAWSConnection(w http.ResponseWriter, r *http.R
 	ctx, span, requestID, cl := utilities.SetupTraceAndLogger(r, w, h.l, utilities.GetFunctionName(), h.cfg.Server.PrefixMain)
 	defer span.End()
 
-	connectionID := mux.Vars(r)["connectionid"]
-	connection, err := h.fetchAWSConnection(connectionID)
+	reveal, err := revealRequested(r, cl, requestID, &w, span)
 	if err != nil {
-		helper.ReturnError(cl, http.StatusInternalServerError, helper.ErrorDatastoreRetrievalFailed, err, requestID, r, &w, span)
 		return
 	}
 
-	if err := h.vh.GetAWSSecretsEngine(connection, ctx); err != nil {
-		helper.ReturnError(cl, http.StatusInternalServerError, helper.ErrorVaultLoadFailed, err, requestID, r, &w, span)
+	connection, applications, err := h.viewConnection(ctx, cl, requestID, r, &w, span)
+	if err != nil {
 		return
 	}
 
-	var response data.AWSConnectionResponseWrapper
-	_ = utilities.CopyMatchingFields(connection, &response)
+	response, err := connectionResponse[data.AWSConnectionResponseWrapper](connection, cl, requestID, r, &w, span)
+	if err != nil {
+		return
+	}
+	response.Connection.Applications = applications
 
-	utilities.WriteResponse(w, cl, response, span)
+	utilities.WriteResponseWithReveal(w, cl, response, reveal, span)
 }
 
 func (h *AWSConnectionHandler) GenerateCredsAWSConnection(w http.ResponseWriter, r *http.Request) {
 
-	// swagger:operation GET /creds AWSConnection Generate Ephemeral Credentials
+	// swagger:operation GET /creds AWSConnection GenerateCredsAWSConnection
 	// Generate AWS Creds
 	//
-	// Endpoint: GET - /v1/connectionmgmt/connection/aws/creds/{connectionid}
+	// Endpoint: GET - /v1/connectionmgmt/connection/aws/{connectionid}/creds
 	//
-	// Description: Generate dynamic credentials using specified AWSConnection.
+	// Description: Generate dynamic credentials using specified AWSConnection. Connection has to be
+	// tested successfully before it can be used for generating credentials. If wrap_ttl is specified
+	// credentials are not returned in response. Response carries single-use Vault wrapping token instead,
+	// which has to be unwrapped through Vault to obtain credentials. Credentials are only issued to application
+	// identified by applicationid which has to match identity of caller and be linked to connection.
 	//
 	// ---
 	// produces:
@@ -256,15 +248,57 @@ func (h *AWSConnectionHandler) GenerateCredsAWSConnection(w http.ResponseWriter,
 	//   description: id for AWSConnection resource to be used for dynamic credentials generation. expected to be in uuid format i.e. XXXXXXXX-XXXX-XXXX-XXXX-XXXXXXXXXXXX
 	//   required: true
 	//   type: string
+	// - name: ttl
+	//   in: query
+	//   description: requested lease duration for credentials either in seconds or duration format i.e. 900s, 15m, 1h. Not supported for iam_user credential type.
+	//   required: false
+	//   type: string
+	// - name: role_name
+	//   in: query
+	//   description: name of role to be used for credentials generation. default role of connection is used if not specified. has to be among allowed_roles of link of application if link restricts roles.
+	//   required: false
+	//   type: string
+	// - name: role_arn
+	//   in: query
+	//   description: arn of role to be assumed. Only used for assumed_role credential type and required if role has more than one role arn.
+	//   required: false
+	//   type: string
+	// - name: applicationid
+	//   in: query
+	//   description: id of application for which credentials are generated. has to identify caller and be linked to connection. recorded with lease of credentials.
+	//   required: true
+	//   type: string
+	// - name: wrap_ttl
+	//   in: query
+	//   description: ttl of Vault wrapping token in seconds or duration format i.e. 60s, 5m. credentials are response-wrapped if specified.
+	//   required: false
+	//   type: string
+	// - name: X-Requester
+	//   in: header
+	//   description: identity of caller requesting credentials. recorded with lease of credentials. ignored in favour of subject of bearer token when authentication is enabled.
+	//   required: false
+	//   type: string
 	// responses:
 	//   '200':
-	//     description: Credentials generated successfully.
+	//     description: Credentials generated successfully. WrappedCredsAWSConnectionResponse is returned if wrap_ttl is specified.
 	//     schema:
 	//         "$ref": "#/definitions/CredsAWSConnectionResponse"
+	//   '400':
+	//     description: Issues with parameters or their value
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
 	//   '404':
 	//     description: Resource not found. Resources are filtered based on connectiontype = AWSConnectionType. If connectionid of Non-AWSConnection is provided ResourceNotFound error is returned.
 	//     schema:
 	//       "$ref": "#/definitions/ErrorResponse"
+	//   '403':
+	//     description: Caller lacks credential-consumer role on connection, applicationid does not identify caller, application is not linked to connection or link does not allow role
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '409':
+	//     description: Connection has not been tested successfully yet
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
 	//   '500':
 	//     description: Internal server error
 	//     schema:
@@ -277,34 +311,84 @@ func (h *AWSConnectionHandler) GenerateCredsAWSConnection(w http.ResponseWriter,
 	ctx, span, requestID, cl := utilities.SetupTraceAndLogger(r, w, h.l, utilities.GetFunctionName(), h.cfg.Server.PrefixMain)
 	defer span.End()
 
-	connectionID := mux.Vars(r)["connectionid"]
-	connection, err := h.fetchAWSConnection(connectionID)
+	ttl := r.URL.Query().Get("ttl")
+	roleARN := r.URL.Query().Get("role_arn")
+	wrapTTL := r.URL.Query().Get("wrap_ttl")
+
+	grant, err := h.grantCreds(ctx, cl, requestID, r, &w, span)
 	if err != nil {
-		helper.ReturnError(cl, http.StatusInternalServerError, helper.ErrorDatastoreRetrievalFailed, err, requestID, r, &w, span)
 		return
 	}
 
-	if err := h.vh.GetAWSSecretsEngine(connection, ctx); err != nil {
-		helper.ReturnError(cl, http.StatusInternalServerError, helper.ErrorVaultLoadFailed, err, requestID, r, &w, span)
+	connection := grant.connection
+
+	response, err := h.sb.GenerateCredsAWSSecretsEngine(connection.VaultPath, connection.VaultNamespace, grant.roleName, roleARN, ttl, ctx)
+	if err != nil {
+		h.failCreds(grant, err, ctx, cl, requestID, r, &w, span)
 		return
 	}
 
-	var response data.AWSConnectionResponseWrapper
-	_ = utilities.CopyMatchingFields(connection, &response)
+	response.ConnectionID = connection.ID.String()
 
-	utilities.WriteResponse(w, cl, response, span)
-}
+	lease := newCredsLease(connection.ConnectionID, grant.applicationID, requestID, requester(r), issuedLease{
+		LeaseID:       response.LeaseID,
+		LeaseDuration: response.LeaseDuration,
+		Renewable:     response.Renewable,
+		RoleName:      response.RoleName,
+	})
+
+	issued := fmt.Sprintf("credentials issued for role %s", response.RoleName)
 
-func (h *AWSConnectionHandler) fetchAWSConnection(connectionID string) (*data.AWSConnection, error) {
-	var connection data.AWSConnection
-	result := h.pd.RODB().Preload("Connection").First(&connection, "id = ?", connectionID)
-	if result.Error != nil {
-		return nil, result.Error
+	var wrapped *data.WrappedCredsAWSConnectionResponse
+
+	if wrapTTL != "" {
+		wrapped, err = h.wrapCredsAWSConnection(response, wrapTTL, connection.VaultNamespace, ctx)
+		if err != nil {
+			recordAuditFailure(h.pd, grant.audit, err, cl, ctx, h.cfg.Server.PrefixMain)
+			helper.ReturnError(cl, http.StatusInternalServerError, helper.ErrorVaultResponseWrapFailed, err, requestID, r, &w, span)
+			return
+		}
+
+		issued = fmt.Sprintf("response-wrapped credentials issued for role %s, wrapping token accessor %s,", response.RoleName, wrapped.WrapInfo.Accessor)
 	}
-	if result.RowsAffected == 0 {
-		return nil, helper.ErrorDictionary[helper.ErrorResourceNotFound].Error()
+
+	if err := h.recordCreds(grant, lease, issued, ctx, cl, requestID, r, &w, span); err != nil {
+		return
 	}
-	return &connection, nil
+
+	if wrapped != nil {
+		utilities.WriteResponse(w, cl, wrapped, span)
+		return
+	}
+
+	utilities.WriteResponse(w, cl, response, span)
+}
+
+// wrapCredsAWSConnection wraps generated credentials in a Vault wrapping token valid for wrapTTL. Credentials
+// are revoked if they can not be wrapped, as they would never reach caller.
+func (h *AWSConnectionHandler) wrapCredsAWSConnection(creds *data.CredsAWSConnectionResponse, wrapTTL string, namespace string, ctx context.Context) (*data.WrappedCredsAWSConnectionResponse, error) {
+
+	tr := otel.Tracer(h.cfg.Server.PrefixMain)
+	ctx, span := tr.Start(ctx, utilities.GetFunctionName())
+	defer span.End()
+
+	wrapInfo, err := h.sb.WrapResponse(creds, wrapTTL, ctx)
+	if err != nil {
+		if revokeErr := h.sb.RevokeLease(creds.LeaseID, namespace, ctx); revokeErr != nil {
+			return nil, fmt.Errorf("%w. revocation of unwrapped credentials failed: %s", err, revokeErr.Error())
+		}
+		return nil, err
+	}
+
+	return &data.WrappedCredsAWSConnectionResponse{
+		ConnectionID:  creds.ConnectionID,
+		LeaseID:       creds.LeaseID,
+		LeaseDuration: creds.LeaseDuration,
+		Renewable:     creds.Renewable,
+		RoleName:      creds.RoleName,
+		Latency:       creds.Latency,
+		WrapInfo:      *wrapInfo,
+	}, nil
 }
 
 func (h *AWSConnectionHandler) TestAWSConnection(w http.ResponseWriter, r *http.Request) {
@@ -325,6 +409,11 @@ func (h *AWSConnectionHandler) TestAWSConnection(w http.ResponseWriter, r *http.
 	//   description: id for AWSConnection resource to be retrieved. expected to be in uuid format i.e. XXXXXXXX-XXXX-XXXX-XXXX-XXXXXXXXXXXX
 	//   required: true
 	//   type: string
+	// - name: role_name
+	//   in: query
+	//   description: name of role to be tested. default role of connection is tested if not specified. Test status of connection is only updated for default role. Outcome of every test is kept in test history of connection.
+	//   required: false
+	//   type: string
 	// responses:
 	//   '200':
 	//     description: Connectivity test status
@@ -334,6 +423,10 @@ func (h *AWSConnectionHandler) TestAWSConnection(w http.ResponseWriter, r *http.
 	//     description: Resource not found. Resources are filtered based on connectiontype = AWSConnectionType. If connectionid of Non-AWSConnection is provided ResourceNotFound error is returned.
 	//     schema:
 	//       "$ref": "#/definitions/ErrorResponse"
+	//   '403':
+	//     description: Caller lacks operator role on connection
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
 	//   '500':
 	//     description: Internal server error
 	//     schema:
@@ -346,28 +439,16 @@ func (h *AWSConnectionHandler) TestAWSConnection(w http.ResponseWriter, r *http.
 	ctx, span, requestID, cl := utilities.SetupTraceAndLogger(r, w, h.l, utilities.GetFunctionName(), h.cfg.Server.PrefixMain)
 	defer span.End()
 
-	connectionID := mux.Vars(r)["connectionid"]
-	connection, err := h.fetchAWSConnection(connectionID)
+	connection, roleName, result, err := h.runTest(ctx, cl, requestID, r, &w, span)
 	if err != nil {
-		helper.ReturnError(cl, http.StatusInternalServerError, helper.ErrorDatastoreRetrievalFailed, err, requestID, r, &w, span)
 		return
 	}
 
 	var response data.TestAWSConnectionResponse
-	if err := h.vh.TestAWSSecretsEngine(connection.VaultPath, ctx); err != nil {
-		helper.LogDebug(cl, helper.DebugAWSConnectionTestFailed, err, span)
-		connection.Connection.SetTestFailed(err.Error())
-	} else {
-		connection.Connection.SetTestPassed()
-	}
-
-	if err := utilities.UpdateObject(h.pd.RWDB(), &connection.Connection, ctx, h.cfg.Server.PrefixMain); err != nil {
-		helper.LogError(cl, helper.ErrorDatastoreSaveFailed, err, span)
-	}
-
 	response.ID = connection.ID.String()
-	response.TestStatus = connection.Connection.TestError
-	response.TestStatusCode = connection.Connection.TestSuccessful
+	response.RoleName = roleName
+	response.TestStatus = result.TestError
+	response.TestStatusCode = result.TestSuccessful
 
 	utilities.WriteResponse(w, cl, response, span)
 }
@@ -407,6 +488,10 @@ func (h *AWSConnectionHandler) UpdateAWSConnection(w http.ResponseWriter, r *htt
 	//     description: Bad request or parameters
 	//     schema:
 	//       "$ref": "#/definitions/ErrorResponse"
+	//   '403':
+	//     description: Caller lacks admin role on connection
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
 	//   default:
 	//     description: unexpected error
 	//     schema:
@@ -415,106 +500,102 @@ func (h *AWSConnectionHandler) UpdateAWSConnection(w http.ResponseWriter, r *htt
 	ctx, span, requestid, cl := utilities.SetupTraceAndLogger(r, w, h.l, utilities.GetFunctionName(), h.cfg.Server.PrefixMain)
 	defer span.End()
 
-	connectionID := mux.Vars(r)["connectionid"]
 	p := r.Context().Value(KeyAWSConnectionPatchParamsRecord{}).(data.AWSConnectionPatchWrapper)
 
-	connection, err := h.getAWSConnection(connectionID, cl, requestid, r, &w, span)
+	// Attributes not part of request are kept as configured in Vault
+	connection, err := h.updateConnection(func(c *data.AWSConnection) error {
+		if p.SecretAccessKey != nil {
+			c.ResetRootRotationStatus()
+		}
+		return applyConnectionPatch(c, &c.Connection, p, p.Connection)
+	}, ctx, cl, requestid, r, &w, span)
 	if err != nil {
 		return
 	}
 
-	if err := utilities.CopyMatchingFields(p, &connection); err != nil {
-		helper.ReturnError(cl, http.StatusInternalServerError, helper.ErrorJSONDecodingFailed, err, requestid, r, &w, span)
-		return
-	}
-
-	if err := utilities.CopyMatchingFields(p.Connection, &connection.Connection); err != nil {
-		helper.ReturnError(cl, http.StatusInternalServerError, helper.ErrorJSONDecodingFailed, err, requestid, r, &w, span)
+	response, err := connectionResponse[data.AWSConnectionResponseWrapper](connection, cl, requestid, r, &w, span)
+	if err != nil {
 		return
 	}
 
-	if err := h.validateAWSConnection(&connection, cl, requestid, r, w, span); err != nil {
-		return
-	}
+	utilities.WriteResponse(w, cl, response, span)
+}
 
-	if err := h.vh.GetAWSSecretsEngine(&connection, ctx); err != nil {
-		helper.ReturnError(cl, http.StatusInternalServerError, helper.ErrorVaultLoadFailed, err, requestid, r, &w, span)
-		return
+func (h *AWSConnectionHandler) validateAWSConnection(c *data.AWSConnection, cl *slog.Logger, requestid string, r *http.Request, w http.ResponseWriter, span trace.Span) error {
+	if err := validateAWSRole(c.DefaultRole(), cl, requestid, r, w, span); err != nil {
+		return err
 	}
 
-	if p.Connection != nil {
-		if err := utilities.CopyMatchingFields(p.Connection, &connection.Connection); err != nil {
-			helper.ReturnError(cl, http.StatusInternalServerError, helper.ErrorJSONDecodingFailed, err, requestid, r, &w, span)
-			return
-		}
+	switch strings.ToLower(c.CredentialType) {
+	case "session_token", "assumed_role", "federation_token":
+		return h.validateAWSConnectionSTSLeaseTTL(c, cl, requestid, r, w, span)
 	}
+	return nil
+}
 
-	if err := utilities.CopyMatchingFields(p, &connection); err != nil {
-		helper.ReturnError(cl, http.StatusInternalServerError, helper.ErrorJSONDecodingFailed, err, requestid, r, &w, span)
-		return
+func validateAWSRole(c *data.AWSRole, cl *slog.Logger, requestid string, r *http.Request, w http.ResponseWriter, span trace.Span) error {
+	if !data.IsValidAWSRoleName(c.RoleName) {
+		helper.ReturnError(cl, http.StatusBadRequest, helper.ErrorInvalidRoleName, helper.ErrorDictionary[helper.ErrorInvalidRoleName].Error(), requestid, r, &w, span)
+		return fmt.Errorf("invalid role name")
 	}
 
-	connection.Connection.ResetTestStatus()
-
-	if err := h.updateAWSConnection(&connection, ctx); err != nil {
-		helper.ReturnError(cl, http.StatusInternalServerError, helper.ErrorDatastoreSaveFailed, err, requestid, r, &w, span)
-		return
+	if c.PolicyDocument != "" && !json.Valid([]byte(c.PolicyDocument)) {
+		helper.ReturnError(cl, http.StatusBadRequest, helper.ErrorInvalidPolicyDocument, helper.ErrorDictionary[helper.ErrorInvalidPolicyDocument].Error(), requestid, r, &w, span)
+		return fmt.Errorf("invalid policy document")
 	}
 
-	response, err := h.prepareAWSConnectionResponse(connection, cl, requestid, r, &w, span)
-	if err != nil {
-		return
-	}
+	credentialType := strings.ToLower(c.CredentialType)
 
-	utilities.WriteResponse(w, cl, response, span)
-}
-
-func (h *AWSConnectionHandler) getAWSConnection(connectionID string, cl *slog.Logger, requestID string, r *http.Request, w *http.ResponseWriter, span trace.Span) (data.AWSConnection, error) {
-	var connection data.AWSConnection
-	result := h.pd.RODB().Preload("Connection").First(&connection, "id = ?", connectionID)
-	if result.Error != nil {
-		helper.ReturnError(cl, http.StatusInternalServerError, helper.ErrorDatastoreRetrievalFailed, result.Error, requestID, r, w, span)
-		return data.AWSConnection{}, result.Error
-	}
-	if result.RowsAffected == 0 {
-		helper.ReturnError(cl, http.StatusNotFound, helper.ErrorResourceNotFound, helper.ErrorDictionary[helper.ErrorResourceNotFound].Error(), requestID, r, w, span)
-		return data.AWSConnection{}, fmt.Errorf("resource not found")
+	// role_arns, session_tags and external_id are only understood by assumed_role
+	if credentialType != "" && credentialType != "assumed_role" {
+		if len(c.RoleARNs) > 0 || len(c.SessionTags) > 0 || c.ExternalID != "" {
+			helper.ReturnError(cl, http.StatusBadRequest, helper.ErrorAWSConnectionAttributeNotSupported, helper.ErrorDictionary[helper.ErrorAWSConnectionAttributeNotSupported].Error(), requestid, r, &w, span)
+			return fmt.Errorf("attribute not supported for credential type")
+		}
 	}
-	return connection, nil
-}
 
-func (h *AWSConnectionHandler) validateAWSConnection(c *data.AWSConnection, cl *slog.Logger, requestid string, r *http.Request, w http.ResponseWriter, span trace.Span) error {
-	switch strings.ToLower(c.CredentialType) {
+	switch credentialType {
 	case "iam_user":
-		if len(c.PolicyARNs) == 0 {
+		if len(c.PolicyARNs) == 0 && c.PolicyDocument == "" {
 			helper.ReturnError(cl, http.StatusBadRequest, helper.ErrorInvalidPolicyARNs, helper.ErrorDictionary[helper.ErrorInvalidPolicyARNs].Error(), requestid, r, &w, span)
 			return fmt.Errorf("invalid policy ARNs")
 		}
-	case "session_token":
-		if c.DefaultLeaseTTL != "" {
-			helper.ReturnError(cl, http.StatusBadRequest, helper.ErrorAWSConnectionInvalidValueForDefaultLeaseTTL, helper.ErrorDictionary[helper.ErrorInvalidPolicyARNs].Error(), requestid, r, &w, span)
-			return fmt.Errorf("invalid default lease ttl")
+	case "assumed_role":
+		if len(c.RoleARNs) == 0 {
+			helper.ReturnError(cl, http.StatusBadRequest, helper.ErrorInvalidRoleARNs, helper.ErrorDictionary[helper.ErrorInvalidRoleARNs].Error(), requestid, r, &w, span)
+			return fmt.Errorf("invalid role ARNs")
 		}
-
-		if c.MaxLeaseTTL != "" {
-			helper.ReturnError(cl, http.StatusBadRequest, helper.ErrorAWSConnectionInvalidValueForMaxLeaseTTL, helper.ErrorDictionary[helper.ErrorInvalidPolicyARNs].Error(), requestid, r, &w, span)
-			return fmt.Errorf("invalid max lease ttl")
+	case "federation_token":
+		if len(c.PolicyARNs) == 0 && c.PolicyDocument == "" {
+			helper.ReturnError(cl, http.StatusBadRequest, helper.ErrorInvalidPolicyDocument, helper.ErrorDictionary[helper.ErrorInvalidPolicyDocument].Error(), requestid, r, &w, span)
+			return fmt.Errorf("invalid policy document")
 		}
 	}
 	return nil
 }
 
-func (h *AWSConnectionHandler) prepareAWSConnectionResponse(connection data.AWSConnection, cl *slog.Logger, requestID string, r *http.Request, w *http.ResponseWriter, span trace.Span) (data.AWSConnectionResponseWrapper, error) {
-	var response data.AWSConnectionResponseWrapper
-	if err := utilities.CopyMatchingFields(connection.Connection, &response.Connection); err != nil {
-		helper.ReturnError(cl, http.StatusInternalServerError, helper.ErrorJSONDecodingFailed, err, requestID, r, w, span)
-		return response, err
+// validateAWSConnectionSTSLeaseTTL rejects mount lease ttls for STS based credential types. Lifetime of
+// STS credentials is requested with each credentials request instead. Vault reports unset lease ttls as 0s.
+func (h *AWSConnectionHandler) validateAWSConnectionSTSLeaseTTL(c *data.AWSConnection, cl *slog.Logger, requestid string, r *http.Request, w http.ResponseWriter, span trace.Span) error {
+	if leaseTTLSet(c.DefaultLeaseTTL) {
+		helper.ReturnError(cl, http.StatusBadRequest, helper.ErrorAWSConnectionInvalidValueForDefaultLeaseTTL, helper.ErrorDictionary[helper.ErrorAWSConnectionInvalidValueForDefaultLeaseTTL].Error(), requestid, r, &w, span)
+		return fmt.Errorf("invalid default lease ttl")
 	}
-	if err := utilities.CopyMatchingFields(connection, &response); err != nil {
-		helper.ReturnError(cl, http.StatusInternalServerError, helper.ErrorJSONDecodingFailed, err, requestID, r, w, span)
-		return response, err
+
+	if leaseTTLSet(c.MaxLeaseTTL) {
+		helper.ReturnError(cl, http.StatusBadRequest, helper.ErrorAWSConnectionInvalidValueForMaxLeaseTTL, helper.ErrorDictionary[helper.ErrorAWSConnectionInvalidValueForMaxLeaseTTL].Error(), requestid, r, &w, span)
+		return fmt.Errorf("invalid max lease ttl")
 	}
-	return response, nil
+
+	return nil
+}
+
+// leaseTTLSet tells whether ttl sets lease ttl of secrets engine mount i.e. is neither empty nor zero.
+func leaseTTLSet(ttl string) bool {
+	if d, err := time.ParseDuration(ttl); err == nil {
+		return d != 0
+	}
+	return ttl != "" && ttl != "0"
 }
 
 // DeleteAWSConnection deletes a AWSConnection from datastore
@@ -525,7 +606,9 @@ func (h *AWSConnectionHandler) DeleteAWSConnection(w http.ResponseWriter, r *htt
 	//
 	// Endpoint: DELETE - /v1/connectionmgmt/connection/aws/{connectionid}
 	//
-	// Description: Returns AWSConnection resource based on connectionid.
+	// Description: Deletes AWSConnection resource based on connectionid and disables its secrets engine mount.
+	// Deletion is refused while applications are linked to connection unless force is set, in which case leases
+	// of connection are revoked and applications are unlinked before connection is deleted.
 	//
 	// ---
 	// produces:
@@ -533,18 +616,35 @@ func (h *AWSConnectionHandler) DeleteAWSConnection(w http.ResponseWriter, r *htt
 	// parameters:
 	// - name: connectionid
 	//   in: query
-	//   description: id for AWSConnection resource to be retrieved. expected to be in uuid format i.e. XXXXXXXX-XXXX-XXXX-XXXX-XXXXXXXXXXXX
+	//   description: id for AWSConnection resource to be deleted. expected to be in uuid format i.e. XXXXXXXX-XXXX-XXXX-XXXX-XXXXXXXXXXXX
 	//   required: true
 	//   type: string
+	// - name: force
+	//   in: query
+	//   description: true to revoke leases and unlink applications before deletion. deletion of linked connection is refused otherwise.
+	//   required: false
+	//   type: boolean
 	// responses:
 	//   '200':
 	//     description: Resource successfully deleted.
 	//     schema:
 	//         "$ref": "#/definitions/DeleteAWSConnectionResponse"
+	//   '400':
+	//     description: Issues with parameters or their value
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '409':
+	//     description: Applications are linked to connection. Linked applications are listed in errorAdditionalInfo
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
 	//   '404':
 	//     description: Resource not found.
 	//     schema:
 	//       "$ref": "#/definitions/ErrorResponse"
+	//   '403':
+	//     description: Caller lacks admin role on connection
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
 	//   '500':
 	//     description: Internal server error
 	//     schema:
@@ -557,120 +657,37 @@ func (h *AWSConnectionHandler) DeleteAWSConnection(w http.ResponseWriter, r *htt
 	ctx, span, requestid, cl := utilities.SetupTraceAndLogger(r, w, h.l, utilities.GetFunctionName(), h.cfg.Server.PrefixMain)
 	defer span.End()
 
-	vars := mux.Vars(r)
-	connectionid := vars["connectionid"]
-
-	if _, err := uuid.Parse(connectionid); err != nil {
-		helper.ReturnError(cl, http.StatusBadRequest, helper.ErrorConnectionIDInvalid, err, requestid, r, &w, span)
-		return
-	}
-
-	connection, err := h.getAWSConnection(connectionid, cl, requestid, r, &w, span)
-	if err != nil {
-		return
-	}
-
-	if err = h.deleteAWSConnection(&connection, ctx); err != nil {
-		helper.ReturnError(cl, http.StatusBadRequest, helper.ErrorDatastoreDeleteFailed, err, requestid, r, &w, span)
+	if err := h.deleteConnection(ctx, cl, requestid, r, &w, span); err != nil {
 		return
 	}
 
 	var response data.DeleteAWSConnectionResponse
-	response.StatusCode = http.StatusNoContent
+	response.StatusCode = http.StatusOK
 	response.Status = http.StatusText(response.StatusCode)
 
 	utilities.WriteResponse(w, cl, response, span)
 }
 
-func (h *AWSConnectionHandler) deleteAWSConnection(c *data.AWSConnection, ctx context.Context) error {
-
-	tr := otel.Tracer(h.cfg.Server.PrefixMain)
-	ctx, span := tr.Start(ctx, utilities.GetFunctionName())
-	defer span.End()
-
-	// Begin a transaction
-	tx := h.pd.RWDB().Begin()
-
-	// Check if the transaction started successfully
-	if tx.Error != nil {
-		return tx.Error
-	}
-
-	// Delete from aws_connections
-	//if err := tx.Exec("DELETE FROM aws_connections WHERE id = ?", c.ID.String()).Error; err != nil || tx.RowsAffected != 1 {
-	if err := utilities.DeleteObjectWithoutTx(tx, c, ctx, h.cfg.Server.PrefixMain); err != nil {
-		tx.Rollback()
-		return err
-	}
-
-	// Delete from connections
-	//if err := tx.Exec("DELETE FROM connections WHERE id = ?", c.ConnectionID.String()).Error; err != nil || tx.RowsAffected != 1 {
-	if err := utilities.DeleteObjectWithoutTx(tx, &c.Connection, ctx, h.cfg.Server.PrefixMain); err != nil {
-		tx.Rollback()
-		return err
-	}
-
-	if err := h.vh.RemoveAWSSecretsEngine(c, ctx); err != nil {
-		tx.Rollback()
-		return err
-	}
-
-	// Commit the transaction
-	if err := tx.Commit().Error; err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
-	}
-
-	return nil
-}
-
-func (h *AWSConnectionHandler) updateAWSConnection(c *data.AWSConnection, ctx context.Context) error {
-
-	tr := otel.Tracer(h.cfg.Server.PrefixMain)
-	ctx, span := tr.Start(ctx, utilities.GetFunctionName())
-	defer span.End()
-
-	// Begin a transaction
-	tx := h.pd.RWDB().Begin()
-
-	// Check if the transaction started successfully
-	if tx.Error != nil {
-		return tx.Error
-	}
-
-	if err := h.vh.RemoveAWSSecretsEngine(c, ctx); err != nil {
-		tx.Rollback()
+// remountAWSConnection applies configuration of c to its secrets engine. AWS secrets engine can not be
+// reconfigured in place, so engine is mounted again and additional roles of connection are restored.
+func (h *AWSConnectionHandler) remountAWSConnection(c *data.AWSConnection, ctx context.Context) error {
+	roles, err := h.fetchAdditionalRoles(c, ctx)
+	if err != nil {
 		return err
 	}
 
-	if err := h.vh.AddAWSSecretsEngine(c, ctx); err != nil {
-		tx.Rollback()
+	if err := h.sb.RemoveAWSSecretsEngine(c, ctx); err != nil {
 		return err
 	}
 
-	/*err := h.vh.UpdateAWSSecretsEngine(c, ctx)
-	if err != nil {
-		tx.Rollback()
+	if err := h.sb.AddAWSSecretsEngine(c, ctx); err != nil {
 		return err
 	}
-	*/
-
-	result := tx.Save(&c.Connection)
-
-	if result.Error != nil {
-		tx.Rollback()
-		return result.Error
-	}
-
-	result = tx.Save(c)
 
-	if result.Error != nil {
-		tx.Rollback()
-		return result.Error
-	}
-
-	// Commit the transaction
-	if err := tx.Commit().Error; err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
+	for i := range roles {
+		if err := h.sb.AddAWSSecretsEngineRole(c.VaultPath, c.VaultNamespace, &roles[i], ctx); err != nil {
+			return err
+		}
 	}
 
 	return nil
@@ -683,7 +700,9 @@ func (h *AWSConnectionHandler) AddAWSConnection(w http.ResponseWriter, r *http.R
 	//
 	// Endpoint: POST - /v1/connectionmgmt/connection/aws
 	//
-	// Description: Create new AWSConnection resource.
+	// Description: Create new AWSConnection resource. Secrets engine of connection is mounted at vaultpath in
+	// vault_namespace if provided. Otherwise path is generated from id of connection and namespace configured for
+	// service is used.
 	//
 	// ---
 	// consumes:
@@ -702,6 +721,18 @@ func (h *AWSConnectionHandler) AddAWSConnection(w http.ResponseWriter, r *http.R
 	//     description: AWSConnection resource just created.
 	//     schema:
 	//         "$ref": "#/definitions/AWSConnection"
+	//   '400':
+	//     description: Bad request or parameters
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '409':
+	//     description: vaultpath is already used by another connection or overlaps with an existing secrets engine mount in vault_namespace
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '403':
+	//     description: Caller lacks admin role on all connections
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
 	//   '500':
 	//     description: Internal server error
 	//     schema:
@@ -718,127 +749,52 @@ func (h *AWSConnectionHandler) AddAWSConnection(w http.ResponseWriter, r *http.R
 
 	c := data.NewAWSConnection(h.cfg)
 
-	if err := utilities.CopyMatchingFields(p, c); err != nil {
-		helper.ReturnError(cl, http.StatusInternalServerError, helper.ErrorJSONDecodingFailed, err, requestid, r, &w, span)
-		return
-	}
-
-	if err := utilities.CopyMatchingFields(p.Connection, &c.Connection); err != nil {
-		helper.ReturnError(cl, http.StatusInternalServerError, helper.ErrorJSONDecodingFailed, err, requestid, r, &w, span)
-		return
-	}
-
-	if err := h.validateAWSConnection(c, cl, requestid, r, w, span); err != nil {
-		return
-	}
-
-	// Begin a transaction
-	tx := h.pd.RWDB().Begin()
-
-	// Check if the transaction started successfully
-	if tx.Error != nil {
-		helper.ReturnError(cl, http.StatusInternalServerError, helper.ErrorDatastoreSaveFailed, tx.Error, requestid, r, &w, span)
-		return
-	}
-
-	if err := utilities.CreateObjectWithoutTx(tx, &c.Connection, ctx, h.cfg.Server.PrefixMain); err != nil {
-		tx.Rollback()
-		helper.ReturnError(cl, http.StatusInternalServerError, helper.ErrorDatastoreSaveFailed, err, requestid, r, &w, span)
-		return
-	}
-
-	if err := utilities.CreateObjectWithoutTx(tx, c, ctx, h.cfg.Server.PrefixMain); err != nil {
-		tx.Rollback()
-		helper.ReturnError(cl, http.StatusInternalServerError, helper.ErrorDatastoreSaveFailed, err, requestid, r, &w, span)
-		return
-	}
-
-	if err := h.vh.AddAWSSecretsEngine(c, ctx); err != nil {
-		tx.Rollback()
-		helper.ReturnError(cl, http.StatusInternalServerError, helper.ErrorVaultAWSEngineFailed, err, requestid, r, &w, span)
-		return
-	}
-
-	if err := tx.Commit().Error; err != nil {
-		helper.ReturnError(cl, http.StatusInternalServerError, helper.ErrorDatastoreSaveFailed, err, requestid, r, &w, span)
+	if err := h.addConnection(c, p, p.Connection, p.VaultPath, p.VaultNamespace, ctx, cl, requestid, r, &w, span); err != nil {
 		return
 	}
 
-	var c_wrapper data.AWSConnectionResponseWrapper
-
-	if err := utilities.CopyMatchingFields(c, &c_wrapper); err != nil {
-		helper.ReturnError(cl, http.StatusInternalServerError, helper.ErrorJSONDecodingFailed, err, requestid, r, &w, span)
+	response, err := connectionResponse[data.AWSConnectionResponseWrapper](c, cl, requestid, r, &w, span)
+	if err != nil {
 		return
 	}
 
-	utilities.WriteResponse(w, cl, c_wrapper, span)
+	utilities.WriteResponse(w, cl, response, span)
 }
 
-func (h AWSConnectionHandler) MiddlewareValidateAWSConnection(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+func (h *AWSConnectionHandler) MiddlewareValidateAWSConnectionCreds(next http.Handler) http.Handler {
+	return h.MiddlewareValidateConnectionCreds(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
 
-		_, span, _, cl := utilities.SetupTraceAndLogger(r, rw, h.l, utilities.GetFunctionName(), h.cfg.Server.PrefixMain)
+		_, span, requestid, cl := utilities.SetupTraceAndLogger(r, rw, h.l, utilities.GetFunctionName(), h.cfg.Server.PrefixMain)
 		defer span.End()
 
-		if _, found := utilities.ValidateQueryStringParam("connectionid", r, cl, rw, span); !found {
+		// Validate ttl parameter
+		if err := utilities.ValidateDurationParam(r.URL.Query().Get("ttl"), cl, r, rw, span, requestid, helper.ErrorInvalidValueForTTL); err != nil {
 			return
 		}
 
-		// Call the next handler, which can be another middleware in the chain, or the final handler.
-		next.ServeHTTP(rw, r)
-	})
-}
-
-func (h AWSConnectionHandler) MiddlewareValidateAWSConnectionPost(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
-
-		ctx, span, _, cl := utilities.SetupTraceAndLogger(r, rw, h.l, utilities.GetFunctionName(), h.cfg.Server.PrefixMain)
-		defer span.End()
-
-		payload, valid := utilities.DecodeAndValidate[data.AWSConnectionPostWrapper](r, cl, rw, span)
-		if !valid {
+		// Validate wrap_ttl parameter
+		if err := utilities.ValidateDurationParam(r.URL.Query().Get("wrap_ttl"), cl, r, rw, span, requestid, helper.ErrorInvalidValueForWrapTTL); err != nil {
 			return
 		}
 
-		// Add application to context
-		ctx = context.WithValue(ctx, KeyAWSConnectionRecord{}, payload)
-		next.ServeHTTP(rw, r.WithContext(ctx))
-	})
+		// Call the next handler, which can be another middleware in the chain, or the final handler.
+		next.ServeHTTP(rw, r)
+	}))
 }
 
-func (h AWSConnectionHandler) MiddlewareValidateAWSConnectionUpdate(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
-
-		ctx, span, requestid, cl := utilities.SetupTraceAndLogger(r, rw, h.l, utilities.GetFunctionName(), h.cfg.Server.PrefixMain)
-		defer span.End()
-
-		if _, found := utilities.ValidateQueryStringParam("connectionid", r, cl, rw, span); !found {
-			return
-		}
-
-		// Decode JSON into a map
-		var payload map[string]interface{}
-		err := json.NewDecoder(r.Body).Decode(&payload)
-		if err != nil {
-			helper.ReturnError(cl, http.StatusBadRequest, helper.ErrorInvalidJSONSchemaForParameter, err, requestid, r, &rw, span)
-			return
-		}
-
-		var p data.AWSConnectionPatchWrapper
+func (h *AWSConnectionHandler) MiddlewareValidateAWSConnectionPost(next http.Handler) http.Handler {
+	return validateConnectionPost[data.AWSConnectionPostWrapper](h.l, h.cfg, KeyAWSConnectionRecord{}, next)
+}
 
-		// Validate and wrap the payload
-		err = utilities.ValidateAndWrapPayload(payload, &p)
-		if err != nil {
-			helper.ReturnError(cl, http.StatusBadRequest, helper.ErrorInvalidJSONSchemaForParameter, err, requestid, r, &rw, span)
-			return
-		}
+func (h *AWSConnectionHandler) MiddlewareValidateAWSConnectionUpdate(next http.Handler) http.Handler {
+	return validateConnectionPatch[data.AWSConnectionPatchWrapper](h.l, h.cfg, KeyAWSConnectionPatchParamsRecord{}, next)
+}
 
-		// add the connection to the context
-		//ctx := context.WithValue(r.Context(), KeyAWSConnectionPatchParamsRecord{}, p)
-		ctx = context.WithValue(ctx, KeyAWSConnectionPatchParamsRecord{}, p)
-		r = r.WithContext(ctx)
+// loadAWSRole loads attributes of r from Vault. Role missing from Vault is returned without attributes.
+func (h *AWSConnectionHandler) loadAWSRole(path string, namespace string, r *data.AWSRole, ctx context.Context) error {
+	if r.VaultRoleMissing {
+		return nil
+	}
 
-		// Call the next handler, which can be another middleware in the chain, or the final handler.
-		next.ServeHTTP(rw, r)
-	})
+	return h.sb.GetAWSSecretsEngineRole(path, namespace, r, ctx)
 }
@@ -0,0 +1,345 @@
+package handlers
+
+import (
+	"DemoServer_ConnectionManager/data"
+	"DemoServer_ConnectionManager/utilities"
+	"net/http"
+)
+
+type KeyAWSRoleRecord struct{}
+type KeyAWSRolePatchParamsRecord struct{}
+
+func (h *AWSConnectionHandler) GetAWSConnectionRoles(w http.ResponseWriter, r *http.Request) {
+
+	// swagger:operation GET /connection/aws/roles AWSRole GetAWSConnectionRoles
+	// List roles of AWS Connection
+	//
+	// Endpoint: GET - /v1/connectionmgmt/connection/aws/{connectionid}/roles
+	//
+	// Description: Returns list of roles which can be used to generate credentials through specified AWSConnection.
+	// Default role of AWSConnection is included in the list.
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: connectionid
+	//   in: query
+	//   description: id for AWSConnection resource. expected to be in uuid format i.e. XXXXXXXX-XXXX-XXXX-XXXX-XXXXXXXXXXXX
+	//   required: true
+	//   type: string
+	// - name: limit
+	//   in: query
+	//   description: maximum number of results to return.
+	//   required: false
+	//   type: integer
+	//   format: int32
+	// - name: skip
+	//   in: query
+	//   description: number of results to be skipped from beginning of list
+	//   required: false
+	//   type: integer
+	//   format: int32
+	// responses:
+	//   '200':
+	//     description: List of AWSRole resources
+	//     schema:
+	//         "$ref": "#/definitions/AWSRolesResponse"
+	//   '404':
+	//     description: Resource not found.
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '403':
+	//     description: Caller lacks viewer role on connection
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '500':
+	//     description: Internal server error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   default:
+	//     description: unexpected error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	ctx, span, requestid, cl := utilities.SetupTraceAndLogger(r, w, h.l, utilities.GetFunctionName(), h.cfg.Server.PrefixMain)
+	defer span.End()
+
+	roles, limit, skip, err := h.listRoles(ctx, cl, requestid, r, &w, span)
+	if err != nil {
+		return
+	}
+
+	response := data.AWSRolesResponse{
+		Total: len(roles),
+		Skip:  skip,
+		Limit: limit,
+		Roles: roles,
+	}
+
+	utilities.WriteResponse(w, cl, response, span)
+}
+
+func (h *AWSConnectionHandler) GetAWSConnectionRole(w http.ResponseWriter, r *http.Request) {
+
+	// swagger:operation GET /connection/aws/roles/{rolename} AWSRole GetAWSConnectionRole
+	// Retrieve role of AWS Connection
+	//
+	// Endpoint: GET - /v1/connectionmgmt/connection/aws/{connectionid}/roles/{rolename}
+	//
+	// Description: Returns AWSRole resource based on connectionid and rolename.
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: connectionid
+	//   in: query
+	//   description: id for AWSConnection resource. expected to be in uuid format i.e. XXXXXXXX-XXXX-XXXX-XXXX-XXXXXXXXXXXX
+	//   required: true
+	//   type: string
+	// - name: rolename
+	//   in: query
+	//   description: name of role to be retrieved.
+	//   required: true
+	//   type: string
+	// responses:
+	//   '200':
+	//     description: AWSRole resource
+	//     schema:
+	//         "$ref": "#/definitions/AWSRole"
+	//   '404':
+	//     description: Resource not found.
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '403':
+	//     description: Caller lacks viewer role on connection
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '500':
+	//     description: Internal server error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   default:
+	//     description: unexpected error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	ctx, span, requestid, cl := utilities.SetupTraceAndLogger(r, w, h.l, utilities.GetFunctionName(), h.cfg.Server.PrefixMain)
+	defer span.End()
+
+	role, err := h.viewRole(ctx, cl, requestid, r, &w, span)
+	if err != nil {
+		return
+	}
+
+	utilities.WriteResponse(w, cl, role, span)
+}
+
+func (h *AWSConnectionHandler) AddAWSConnectionRole(w http.ResponseWriter, r *http.Request) {
+
+	// swagger:operation POST /connection/aws/roles AWSRole AddAWSConnectionRole
+	// New role of AWS Connection
+	//
+	// Endpoint: POST - /v1/connectionmgmt/connection/aws/{connectionid}/roles
+	//
+	// Description: Create new AWSRole resource in AWSConnection's secrets engine.
+	//
+	// ---
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: connectionid
+	//   in: query
+	//   description: id for AWSConnection resource. expected to be in uuid format i.e. XXXXXXXX-XXXX-XXXX-XXXX-XXXXXXXXXXXX
+	//   required: true
+	//   type: string
+	// - in: body
+	//   name: Body
+	//   description: JSON string defining AWSRole resource
+	//   required: true
+	//   schema:
+	//     "$ref": "#/definitions/AWSRolePostWrapper"
+	// responses:
+	//   '200':
+	//     description: AWSRole resource just created.
+	//     schema:
+	//         "$ref": "#/definitions/AWSRole"
+	//   '400':
+	//     description: Bad request or parameters
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '404':
+	//     description: Resource not found.
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '409':
+	//     description: Role with same name already exists for connection
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '403':
+	//     description: Caller lacks admin role on connection
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '500':
+	//     description: Internal server error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   default:
+	//     description: unexpected error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	ctx, span, requestid, cl := utilities.SetupTraceAndLogger(r, w, h.l, utilities.GetFunctionName(), h.cfg.Server.PrefixMain)
+	defer span.End()
+
+	p := r.Context().Value(KeyAWSRoleRecord{}).(*data.AWSRolePostWrapper)
+
+	role, err := h.addRole(p, ctx, cl, requestid, r, &w, span)
+	if err != nil {
+		return
+	}
+
+	utilities.WriteResponse(w, cl, role, span)
+}
+
+func (h *AWSConnectionHandler) UpdateAWSConnectionRole(w http.ResponseWriter, r *http.Request) {
+
+	// swagger:operation PATCH /connection/aws/roles/{rolename} AWSRole UpdateAWSConnectionRole
+	// Update role of AWS Connection
+	//
+	// Endpoint: PATCH - /v1/connectionmgmt/connection/aws/{connectionid}/roles/{rolename}
+	//
+	// Description: Update attributes of AWSRole resource. Default role of AWSConnection is updated through
+	// PATCH on AWSConnection instead.
+	//
+	// ---
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: connectionid
+	//   in: query
+	//   description: id for AWSConnection resource. expected to be in uuid format i.e. XXXXXXXX-XXXX-XXXX-XXXX-XXXXXXXXXXXX
+	//   required: true
+	//   type: string
+	// - name: rolename
+	//   in: query
+	//   description: name of role to be updated.
+	//   required: true
+	//   type: string
+	// - in: body
+	//   name: Body
+	//   description: JSON string defining AWSRole attributes to be updated. Change of role_name is not allowed.
+	//   required: true
+	//   schema:
+	//     "$ref": "#/definitions/AWSRolePatchWrapper"
+	// responses:
+	//   '200':
+	//     description: AWSRole resource after updates.
+	//     schema:
+	//         "$ref": "#/definitions/AWSRole"
+	//   '400':
+	//     description: Bad request or parameters
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '403':
+	//     description: Caller lacks admin role on connection
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '404':
+	//     description: Resource not found.
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   default:
+	//     description: unexpected error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	ctx, span, requestid, cl := utilities.SetupTraceAndLogger(r, w, h.l, utilities.GetFunctionName(), h.cfg.Server.PrefixMain)
+	defer span.End()
+
+	p := r.Context().Value(KeyAWSRolePatchParamsRecord{}).(data.AWSRolePatchWrapper)
+
+	role, err := h.updateRole(p, ctx, cl, requestid, r, &w, span)
+	if err != nil {
+		return
+	}
+
+	utilities.WriteResponse(w, cl, role, span)
+}
+
+// DeleteAWSConnectionRole deletes a role of AWSConnection from datastore and Vault
+func (h *AWSConnectionHandler) DeleteAWSConnectionRole(w http.ResponseWriter, r *http.Request) {
+
+	// swagger:operation DELETE /connection/aws/roles/{rolename} AWSRole DeleteAWSConnectionRole
+	// Delete role of AWS Connection
+	//
+	// Endpoint: DELETE - /v1/connectionmgmt/connection/aws/{connectionid}/roles/{rolename}
+	//
+	// Description: Delete AWSRole resource. Default role of AWSConnection can not be deleted.
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: connectionid
+	//   in: query
+	//   description: id for AWSConnection resource. expected to be in uuid format i.e. XXXXXXXX-XXXX-XXXX-XXXX-XXXXXXXXXXXX
+	//   required: true
+	//   type: string
+	// - name: rolename
+	//   in: query
+	//   description: name of role to be deleted.
+	//   required: true
+	//   type: string
+	// responses:
+	//   '200':
+	//     description: Resource successfully deleted.
+	//     schema:
+	//         "$ref": "#/definitions/DeleteAWSRoleResponse"
+	//   '400':
+	//     description: Default role of connection can not be deleted
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '404':
+	//     description: Resource not found.
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '403':
+	//     description: Caller lacks admin role on connection
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '500':
+	//     description: Internal server error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   default:
+	//     description: unexpected error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	ctx, span, requestid, cl := utilities.SetupTraceAndLogger(r, w, h.l, utilities.GetFunctionName(), h.cfg.Server.PrefixMain)
+	defer span.End()
+
+	if err := h.deleteRole(ctx, cl, requestid, r, &w, span); err != nil {
+		return
+	}
+
+	var response data.DeleteAWSRoleResponse
+	response.StatusCode = http.StatusOK
+	response.Status = http.StatusText(response.StatusCode)
+
+	utilities.WriteResponse(w, cl, response, span)
+}
+
+func (h *AWSConnectionHandler) MiddlewareValidateAWSRolePost(next http.Handler) http.Handler {
+	return validateRolePost[data.AWSRolePostWrapper](h.l, h.cfg, KeyAWSRoleRecord{}, next)
+}
+
+func (h *AWSConnectionHandler) MiddlewareValidateAWSRoleUpdate(next http.Handler) http.Handler {
+	return validateRolePatch[data.AWSRolePatchWrapper](h.l, h.cfg, KeyAWSRolePatchParamsRecord{}, next)
+}
@@ -0,0 +1,551 @@
+package handlers
+
+import (
+	"DemoServer_ConnectionManager/auth"
+	"DemoServer_ConnectionManager/configuration"
+	"DemoServer_ConnectionManager/data"
+	"DemoServer_ConnectionManager/datalayer"
+	"DemoServer_ConnectionManager/helper"
+	"DemoServer_ConnectionManager/secretsmanager"
+	"DemoServer_ConnectionManager/utilities"
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+type KeyAzureConnectionRecord struct{}
+type KeyAzureConnectionPatchParamsRecord struct{}
+
+type AzureConnectionHandler struct {
+	connectionHandler[data.AzureConnection, *data.AzureConnection]
+}
+
+func NewAzureConnectionHandler(cfg *configuration.Config, l *slog.Logger, pd *datalayer.PostgresDataSource, sb secretsmanager.SecretsBackend, pe *auth.PolicyEngine) (*AzureConnectionHandler, error) {
+	var c AzureConnectionHandler
+
+	c.connectionHandler = newConnectionHandler[data.AzureConnection, *data.AzureConnection](cfg, l, pd, sb, pe, connectionEngine[data.AzureConnection]{
+		table:   "azure_connections",
+		load:    sb.GetAzureSecretsEngine,
+		mount:   sb.AddAzureSecretsEngine,
+		update:  sb.UpdateAzureSecretsEngine,
+		unmount: sb.RemoveAzureSecretsEngine,
+		test: func(c *data.AzureConnection, roleName string, ctx context.Context) error {
+			return sb.TestAzureSecretsEngine(c.VaultPath, c.VaultNamespace, roleName, ctx)
+		},
+		mountFailed: helper.ErrorVaultSecretsEngineFailed,
+		validate:    validateAzureConnection,
+	})
+
+	return &c, nil
+}
+
+func (h *AzureConnectionHandler) GetAzureConnections(w http.ResponseWriter, r *http.Request) {
+
+	// swagger:operation GET /connections/azure AzureConnection GetAzureConnections
+	// List Azure Connections
+	//
+	// Endpoint: GET - /v1/connectionmgmt/connections/azure
+	//
+	// Description: Returns list of AzureConnection resources. Each AzureConnection resource
+	// contains underlying generic Connection resource as well as AzureConnection
+	// specific attributes. Client secret of connection is never returned.
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: limit
+	//   in: query
+	//   description: maximum number of results to return.
+	//   required: false
+	//   type: integer
+	//   format: int32
+	// - name: skip
+	//   in: query
+	//   description: number of results to be skipped from beginning of list
+	//   required: false
+	//   type: integer
+	//   format: int32
+	// responses:
+	//   '200':
+	//     description: List of AzureConnection resources
+	//     schema:
+	//       type: array
+	//       items:
+	//         "$ref": "#/definitions/AzureConnection"
+	//   '400':
+	//     description: Issues with parameters or their value
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '500':
+	//     description: Internal server error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   default:
+	//     description: unexpected error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	ctx, span, requestid, cl := utilities.SetupTraceAndLogger(r, w, h.l, utilities.GetFunctionName(), h.cfg.Server.PrefixMain)
+	defer span.End()
+
+	connections, limit, skip, err := h.listConnections(ctx, cl, requestid, r, &w, span)
+	if err != nil {
+		return
+	}
+
+	wrapped, err := connectionResponses[data.AzureConnectionResponseWrapper](connections, cl, requestid, r, &w, span)
+	if err != nil {
+		return
+	}
+
+	response := data.AzureConnectionsResponse{
+		Total:            len(connections),
+		Skip:             skip,
+		Limit:            limit,
+		AzureConnections: wrapped,
+	}
+
+	utilities.WriteResponse(w, cl, response, span)
+}
+
+// GetAzureConnection returns AzureConnection resource based on connectionid parameter
+func (h *AzureConnectionHandler) GetAzureConnection(w http.ResponseWriter, r *http.Request) {
+
+	// swagger:operation GET /connection/azure AzureConnection GetAzureConnection
+	// Retrieve Azure Connection
+	//
+	// Endpoint: GET - /v1/connectionmgmt/connection/azure/{connectionid}
+	//
+	// Description: Returns AzureConnection resource based on connectionid.
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: connectionid
+	//   in: query
+	//   description: id for AzureConnection resource to be retrieved. expected to be in uuid format i.e. XXXXXXXX-XXXX-XXXX-XXXX-XXXXXXXXXXXX
+	//   required: true
+	//   type: string
+	// responses:
+	//   '200':
+	//     description: AzureConnection resource
+	//     schema:
+	//         "$ref": "#/definitions/AzureConnection"
+	//   '400':
+	//     description: Issues with parameters or their value
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '403':
+	//     description: Caller lacks viewer role on connection
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '404':
+	//     description: Resource not found. Resources are filtered based on connectiontype = AzureConnectionType. If connectionid of Non-AzureConnection is provided ResourceNotFound error is returned.
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '500':
+	//     description: Internal server error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   default:
+	//     description: unexpected error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	ctx, span, requestID, cl := utilities.SetupTraceAndLogger(r, w, h.l, utilities.GetFunctionName(), h.cfg.Server.PrefixMain)
+	defer span.End()
+
+	connection, applications, err := h.viewConnection(ctx, cl, requestID, r, &w, span)
+	if err != nil {
+		return
+	}
+
+	response, err := connectionResponse[data.AzureConnectionResponseWrapper](connection, cl, requestID, r, &w, span)
+	if err != nil {
+		return
+	}
+	response.Connection.Applications = applications
+
+	utilities.WriteResponse(w, cl, response, span)
+}
+
+func (h *AzureConnectionHandler) GenerateCredsAzureConnection(w http.ResponseWriter, r *http.Request) {
+
+	// swagger:operation GET /connection/azure/creds AzureConnection GenerateCredsAzureConnection
+	// Generate Azure Creds
+	//
+	// Endpoint: GET - /v1/connectionmgmt/connection/azure/{connectionid}/creds
+	//
+	// Description: Generate dynamic service principal credentials using specified AzureConnection. Connection has
+	// to be tested successfully before it can be used for generating credentials. Credentials are only issued to
+	// application identified by applicationid which has to match identity of caller and be linked to connection.
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: connectionid
+	//   in: query
+	//   description: id for AzureConnection resource to be used for dynamic credentials generation. expected to be in uuid format i.e. XXXXXXXX-XXXX-XXXX-XXXX-XXXXXXXXXXXX
+	//   required: true
+	//   type: string
+	// - name: applicationid
+	//   in: query
+	//   description: id of application for which credentials are generated. has to identify caller and be linked to connection. recorded with lease of credentials.
+	//   required: true
+	//   type: string
+	// - name: X-Requester
+	//   in: header
+	//   description: identity of caller requesting credentials. recorded with lease of credentials. ignored in favour of subject of bearer token when authentication is enabled.
+	//   required: false
+	//   type: string
+	// responses:
+	//   '200':
+	//     description: Credentials generated successfully
+	//     schema:
+	//         "$ref": "#/definitions/CredsAzureConnectionResponse"
+	//   '400':
+	//     description: Issues with parameters or their value
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '404':
+	//     description: Resource not found. Resources are filtered based on connectiontype = AzureConnectionType. If connectionid of Non-AzureConnection is provided ResourceNotFound error is returned.
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '403':
+	//     description: Caller lacks credential-consumer role on connection, applicationid does not identify caller, application is not linked to connection or link does not allow role
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '409':
+	//     description: Connection has not been tested successfully yet
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '500':
+	//     description: Internal server error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   default:
+	//     description: unexpected error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	ctx, span, requestID, cl := utilities.SetupTraceAndLogger(r, w, h.l, utilities.GetFunctionName(), h.cfg.Server.PrefixMain)
+	defer span.End()
+
+	grant, err := h.grantCreds(ctx, cl, requestID, r, &w, span)
+	if err != nil {
+		return
+	}
+
+	connection := grant.connection
+
+	response, err := h.sb.GenerateCredsAzureSecretsEngine(connection.VaultPath, connection.VaultNamespace, grant.roleName, ctx)
+	if err != nil {
+		h.failCreds(grant, err, ctx, cl, requestID, r, &w, span)
+		return
+	}
+
+	response.ConnectionID = connection.ID.String()
+
+	lease := newCredsLease(connection.ConnectionID, grant.applicationID, requestID, requester(r), issuedLease{
+		LeaseID:       response.LeaseID,
+		LeaseDuration: response.LeaseDuration,
+		Renewable:     response.Renewable,
+		RoleName:      response.RoleName,
+	})
+
+	if err := h.recordCreds(grant, lease, fmt.Sprintf("credentials issued for role %s", response.RoleName), ctx, cl, requestID, r, &w, span); err != nil {
+		return
+	}
+
+	utilities.WriteResponse(w, cl, response, span)
+}
+
+func (h *AzureConnectionHandler) TestAzureConnection(w http.ResponseWriter, r *http.Request) {
+
+	// swagger:operation GET /connection/azure/test AzureConnection TestAzureConnection
+	// Test Azure Connection
+	//
+	// Endpoint: GET - /v1/connectionmgmt/connection/azure/{connectionid}/test
+	//
+	// Description: Test connectivity of specified AzureConnection resource. Credentials are generated through role
+	// of connection and revoked right away.
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: connectionid
+	//   in: query
+	//   description: id for AzureConnection resource to be tested. expected to be in uuid format i.e. XXXXXXXX-XXXX-XXXX-XXXX-XXXXXXXXXXXX
+	//   required: true
+	//   type: string
+	// responses:
+	//   '200':
+	//     description: Connectivity test status
+	//     schema:
+	//         "$ref": "#/definitions/TestAzureConnectionResponse"
+	//   '404':
+	//     description: Resource not found. Resources are filtered based on connectiontype = AzureConnectionType. If connectionid of Non-AzureConnection is provided ResourceNotFound error is returned.
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '403':
+	//     description: Caller lacks operator role on connection
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '500':
+	//     description: Internal server error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   default:
+	//     description: unexpected error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	ctx, span, requestID, cl := utilities.SetupTraceAndLogger(r, w, h.l, utilities.GetFunctionName(), h.cfg.Server.PrefixMain)
+	defer span.End()
+
+	connection, roleName, result, err := h.runTest(ctx, cl, requestID, r, &w, span)
+	if err != nil {
+		return
+	}
+
+	var response data.TestAzureConnectionResponse
+	response.ID = connection.ID.String()
+	response.RoleName = roleName
+	response.TestStatus = result.TestError
+	response.TestStatusCode = result.TestSuccessful
+
+	utilities.WriteResponse(w, cl, response, span)
+}
+
+func (h *AzureConnectionHandler) UpdateAzureConnection(w http.ResponseWriter, r *http.Request) {
+
+	// swagger:operation PATCH /connection/azure AzureConnection UpdateAzureConnection
+	// Update Azure Connection
+	//
+	// Endpoint: PATCH - /v1/connectionmgmt/connection/azure/{connectionid}
+	//
+	// Description: Update attributes of AzureConnection resource. Secrets engine is reconfigured in place, so
+	// credentials issued before update remain valid until their leases expire. Update operation resets Tested
+	// status of AzureConnection.
+	//
+	// ---
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: connectionid
+	//   in: query
+	//   description: id for AzureConnection resource to be updated. expected to be in uuid format i.e. XXXXXXXX-XXXX-XXXX-XXXX-XXXXXXXXXXXX
+	//   required: true
+	//   type: string
+	// - in: body
+	//   name: Body
+	//   description: JSON string defining AzureConnection resource. Change of connectiontype and ID attributes is not allowed.
+	//   required: true
+	//   schema:
+	//     "$ref": "#/definitions/AzureConnectionPatchWrapper"
+	// responses:
+	//   '200':
+	//     description: AzureConnection resource after updates.
+	//     schema:
+	//         "$ref": "#/definitions/AzureConnection"
+	//   '400':
+	//     description: Bad request or parameters
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '403':
+	//     description: Caller lacks admin role on connection
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   default:
+	//     description: unexpected error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	ctx, span, requestid, cl := utilities.SetupTraceAndLogger(r, w, h.l, utilities.GetFunctionName(), h.cfg.Server.PrefixMain)
+	defer span.End()
+
+	p := r.Context().Value(KeyAzureConnectionPatchParamsRecord{}).(data.AzureConnectionPatchWrapper)
+
+	// Attributes not part of request are kept as configured in Vault
+	connection, err := h.updateConnection(func(c *data.AzureConnection) error {
+		return applyConnectionPatch(c, &c.Connection, p, p.Connection)
+	}, ctx, cl, requestid, r, &w, span)
+	if err != nil {
+		return
+	}
+
+	response, err := connectionResponse[data.AzureConnectionResponseWrapper](connection, cl, requestid, r, &w, span)
+	if err != nil {
+		return
+	}
+
+	utilities.WriteResponse(w, cl, response, span)
+}
+
+// validateAzureConnection makes sure role of connection either assigns Azure roles or uses an existing
+// application. Vault refuses roles with neither and ignores azure_roles when both are given.
+func validateAzureConnection(c *data.AzureConnection, cl *slog.Logger, requestid string, r *http.Request, w http.ResponseWriter, span trace.Span) error {
+	if !c.HasRole() || (len(c.AzureRoles) > 0 && c.ApplicationObjectID != "") {
+		helper.ReturnError(cl, http.StatusBadRequest, helper.ErrorInvalidAzureRoles, helper.ErrorDictionary[helper.ErrorInvalidAzureRoles].Error(), requestid, r, &w, span)
+		return fmt.Errorf("invalid azure roles")
+	}
+	return nil
+}
+
+// DeleteAzureConnection deletes a AzureConnection from datastore
+func (h *AzureConnectionHandler) DeleteAzureConnection(w http.ResponseWriter, r *http.Request) {
+
+	// swagger:operation DELETE /connection/azure AzureConnection DeleteAzureConnection
+	// Delete Azure Connection
+	//
+	// Endpoint: DELETE - /v1/connectionmgmt/connection/azure/{connectionid}
+	//
+	// Description: Deletes AzureConnection resource based on connectionid and disables its secrets engine mount,
+	// which revokes credentials issued through connection. Deletion is refused while applications are linked to
+	// connection unless force is set, in which case leases of connection are revoked and applications are
+	// unlinked before connection is deleted.
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: connectionid
+	//   in: query
+	//   description: id for AzureConnection resource to be deleted. expected to be in uuid format i.e. XXXXXXXX-XXXX-XXXX-XXXX-XXXXXXXXXXXX
+	//   required: true
+	//   type: string
+	// - name: force
+	//   in: query
+	//   description: true to revoke leases and unlink applications before deletion. deletion of linked connection is refused otherwise.
+	//   required: false
+	//   type: boolean
+	// responses:
+	//   '200':
+	//     description: Resource successfully deleted.
+	//     schema:
+	//         "$ref": "#/definitions/DeleteAzureConnectionResponse"
+	//   '400':
+	//     description: Issues with parameters or their value
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '409':
+	//     description: Applications are linked to connection. Linked applications are listed in errorAdditionalInfo
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '404':
+	//     description: Resource not found.
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '403':
+	//     description: Caller lacks admin role on connection
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '500':
+	//     description: Internal server error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   default:
+	//     description: unexpected error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	ctx, span, requestid, cl := utilities.SetupTraceAndLogger(r, w, h.l, utilities.GetFunctionName(), h.cfg.Server.PrefixMain)
+	defer span.End()
+
+	if err := h.deleteConnection(ctx, cl, requestid, r, &w, span); err != nil {
+		return
+	}
+
+	var response data.DeleteAzureConnectionResponse
+	response.StatusCode = http.StatusOK
+	response.Status = http.StatusText(response.StatusCode)
+
+	utilities.WriteResponse(w, cl, response, span)
+}
+
+func (h *AzureConnectionHandler) AddAzureConnection(w http.ResponseWriter, r *http.Request) {
+
+	// swagger:operation POST /connection/azure AzureConnection AddAzureConnection
+	// New Azure Connection
+	//
+	// Endpoint: POST - /v1/connectionmgmt/connection/azure
+	//
+	// Description: Create new AzureConnection resource. Azure secrets engine of connection is mounted at vaultpath
+	// in vault_namespace if provided and configured with service principal Vault manages Azure subscription with.
+	// Otherwise path is generated from id of connection and namespace configured for service is used. Role of
+	// connection either assigns azure_roles to generated service principals or generates credentials of existing
+	// application identified by application_object_id.
+	//
+	// ---
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - in: body
+	//   name: Body
+	//   description: JSON string defining AzureConnection resource
+	//   required: true
+	//   schema:
+	//     "$ref": "#/definitions/AzureConnectionPostWrapper"
+	// responses:
+	//   '200':
+	//     description: AzureConnection resource just created.
+	//     schema:
+	//         "$ref": "#/definitions/AzureConnection"
+	//   '400':
+	//     description: Bad request or parameters
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '409':
+	//     description: vaultpath is already used by another connection or overlaps with an existing secrets engine mount in vault_namespace
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '403':
+	//     description: Caller lacks admin role on all connections
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '500':
+	//     description: Internal server error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   default:
+	//     description: unexpected error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	ctx, span, requestid, cl := utilities.SetupTraceAndLogger(r, w, h.l, utilities.GetFunctionName(), h.cfg.Server.PrefixMain)
+	defer span.End()
+
+	p := r.Context().Value(KeyAzureConnectionRecord{}).(*data.AzureConnectionPostWrapper)
+
+	c := data.NewAzureConnection(h.cfg)
+
+	if err := h.addConnection(c, p, p.Connection, p.VaultPath, p.VaultNamespace, ctx, cl, requestid, r, &w, span); err != nil {
+		return
+	}
+
+	response, err := connectionResponse[data.AzureConnectionResponseWrapper](c, cl, requestid, r, &w, span)
+	if err != nil {
+		return
+	}
+
+	utilities.WriteResponse(w, cl, response, span)
+}
+
+func (h *AzureConnectionHandler) MiddlewareValidateAzureConnectionPost(next http.Handler) http.Handler {
+	return validateConnectionPost[data.AzureConnectionPostWrapper](h.l, h.cfg, KeyAzureConnectionRecord{}, next)
+}
+
+func (h *AzureConnectionHandler) MiddlewareValidateAzureConnectionUpdate(next http.Handler) http.Handler {
+	return validateConnectionPatch[data.AzureConnectionPatchWrapper](h.l, h.cfg, KeyAzureConnectionPatchParamsRecord{}, next)
+}
@@ -1,18 +1,29 @@
 package handlers
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"math"
 	"net/http"
+	"strconv"
+	"time"
 
+	"DemoServer_ConnectionManager/auth"
 	"DemoServer_ConnectionManager/configuration"
 	"DemoServer_ConnectionManager/data"
 	"DemoServer_ConnectionManager/datalayer"
 	"DemoServer_ConnectionManager/helper"
+	"DemoServer_ConnectionManager/secretsmanager"
 	"DemoServer_ConnectionManager/utilities"
 
+	"github.com/google/uuid"
 	"github.com/gorilla/mux"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
 )
 
 type KeyConnectionRecord struct{}
@@ -21,24 +32,26 @@ type ConnectionHandler struct {
 	l          *slog.Logger
 	cfg        *configuration.Config
 	pd         *datalayer.PostgresDataSource
+	pe         *auth.PolicyEngine
 	list_limit int
 }
 
-func NewConnectionsHandler(cfg *configuration.Config, l *slog.Logger, pd *datalayer.PostgresDataSource) (*ConnectionHandler, error) {
+func NewConnectionsHandler(cfg *configuration.Config, l *slog.Logger, pd *datalayer.PostgresDataSource, pe *auth.PolicyEngine) (*ConnectionHandler, error) {
 	var c ConnectionHandler
 
 	c.cfg = cfg
 	c.l = l
 	c.pd = pd
+	c.pe = pe
 	c.list_limit = cfg.Server.ListLimit
 
 	return &c, nil
 }
 
-func (h *ConnectionHandler) fetchConnections(limit, skip int) ([]data.Connection, error) {
+func (h *ConnectionHandler) fetchConnections(scope connectionScope, limit, skip int) ([]data.Connection, error) {
 	var connections []data.Connection
 
-	result := h.pd.RODB().
+	result := scope.apply(h.pd.RODB(), "id").
 		Limit(limit).
 		Offset(skip).
 		Order("name").
@@ -113,14 +126,19 @@ func (h *ConnectionHandler) GetConnections(w http.ResponseWriter, r *http.Reques
 	//     schema:
 	//       "$ref": "#/definitions/ErrorResponse"
 
-	_, span, requestid, cl := utilities.SetupTraceAndLogger(r, w, h.l, utilities.GetFunctionName(), h.cfg.Server.PrefixMain)
+	ctx, span, requestid, cl := utilities.SetupTraceAndLogger(r, w, h.l, utilities.GetFunctionName(), h.cfg.Server.PrefixMain)
 	defer span.End()
 
+	scope, err := authorizedConnections(h.pe, auth.PermissionView, ctx, cl, requestid, r, &w, span)
+	if err != nil {
+		return
+	}
+
 	vars := r.URL.Query()
 	limit := utilities.ParseQueryParam(vars, "limit", h.list_limit, h.cfg.DataLayer.MaxResults)
 	skip := utilities.ParseQueryParam(vars, "skip", 0, math.MaxInt32)
 
-	connections, err := h.fetchConnections(limit, skip)
+	connections, err := h.fetchConnections(scope, limit, skip)
 	if err != nil {
 		helper.ReturnError(cl, http.StatusInternalServerError, helper.ErrorDatastoreRetrievalFailed, err, requestid, r, &w, span)
 		return
@@ -147,11 +165,22 @@ func (h *ConnectionHandler) buildConnectionsResponse(connections []data.Connecti
 		return response, nil
 	}
 
+	connectionIDs := make([]uuid.UUID, 0, len(connections))
+	for _, conn := range connections {
+		connectionIDs = append(connectionIDs, conn.ID)
+	}
+
+	applications, err := linkedApplications(h.pd, connectionIDs)
+	if err != nil {
+		return response, err
+	}
+
 	for _, conn := range connections {
 		var wrappedConn data.Connection
 		if err := utilities.CopyMatchingFields(conn, &wrappedConn); err != nil {
 			return response, err
 		}
+		wrappedConn.Applications = applications[conn.ID]
 		response.Connections = append(response.Connections, wrappedConn)
 	}
 
@@ -184,7 +213,7 @@ func (h ConnectionHandler) MiddlewareValidateConnectionsGet(next http.Handler) h
 func (h ConnectionHandler) MiddlewareValidateConnectionLink(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
 
-		_, span, _, cl := utilities.SetupTraceAndLogger(r, rw, h.l, utilities.GetFunctionName(), h.cfg.Server.PrefixMain)
+		ctx, span, requestid, cl := utilities.SetupTraceAndLogger(r, rw, h.l, utilities.GetFunctionName(), h.cfg.Server.PrefixMain)
 		defer span.End()
 
 		if _, found := utilities.ValidateQueryStringParam("connectionid", r, cl, rw, span); !found {
@@ -195,8 +224,23 @@ func (h ConnectionHandler) MiddlewareValidateConnectionLink(next http.Handler) h
 			return
 		}
 
-		// Call the next handler, which can be another middleware in the chain, or the final handler.
-		next.ServeHTTP(rw, r)
+		// Body carrying attributes of link is optional
+		payload := &data.ConnectionLinkPostWrapper{}
+		if r.ContentLength != 0 {
+			var valid bool
+			if payload, valid = utilities.DecodeAndValidate[data.ConnectionLinkPostWrapper](r, cl, rw, span); !valid {
+				return
+			}
+		}
+
+		if payload.ExpiresAt != nil && !payload.ExpiresAt.After(time.Now()) {
+			helper.ReturnError(cl, http.StatusBadRequest, helper.ErrorInvalidValueForExpiresAt, fmt.Errorf("expiresat %s is not in future", payload.ExpiresAt), requestid, r, &rw, span)
+			return
+		}
+
+		// Add link attributes to context
+		ctx = context.WithValue(ctx, KeyConnectionLinkRecord{}, payload)
+		next.ServeHTTP(rw, r.WithContext(ctx))
 	})
 }
 
@@ -221,12 +265,13 @@ func (h ConnectionHandler) MiddlewareValidateConnectionUnlink(next http.Handler)
 
 func (h *ConnectionHandler) LinkConnection(w http.ResponseWriter, r *http.Request) {
 
-	// swagger:operation POST /connection/link LinkConnection
+	// swagger:operation POST /connection/link Connection LinkConnection
 	// Link application to connection
 	//
-	// Endpoint: POST - /v1/connectionmgmt/connection/link
+	// Endpoint: POST - /v1/connectionmgmt/connection/{connectionid}/link/{applicationid}
 	//
-	// Description: Link application to connection.
+	// Description: Link application to connection. Link can be tagged with environment and purpose, restricted to
+	// roles application may obtain credentials for and given expiry after which it is removed.
 	//
 	// ---
 	// consumes:
@@ -236,13 +281,23 @@ func (h *ConnectionHandler) LinkConnection(w http.ResponseWriter, r *http.Reques
 	// parameters:
 	// - in: body
 	//   name: Body
-	//   description: JSON string defining AWSConnection resource
-	//   required: true
+	//   description: JSON string defining attributes of link
+	//   required: false
 	//   schema:
-	//     "$ref": "#/definitions/AWSConnectionPostWrapper"
+	//     "$ref": "#/definitions/ConnectionLinkPostWrapper"
 	// responses:
 	//   '200':
 	//     description: Connection linked successfully.
+	//     schema:
+	//         "$ref": "#/definitions/ConnectionLink"
+	//   '400':
+	//     description: Issues with parameters or their value
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '403':
+	//     description: Caller lacks operator role on connection
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
 	//   '500':
 	//     description: Internal server error
 	//     schema:
@@ -265,53 +320,80 @@ func (h *ConnectionHandler) LinkConnection(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	for _, item := range connection.Applications {
-		if item == applicationid {
-			// The applicationid already exists.
-			helper.ReturnError(
-				cl,
-				http.StatusBadRequest,
-				helper.ErrorApplicationAlreadyLinked,
-				helper.ErrorDictionary[helper.ErrorApplicationAlreadyLinked].Error(),
-				requestid,
-				r,
-				&w,
-				span)
-			return
-		}
+	if err := authorize(h.pe, auth.PermissionLink, connection.ID, ctx, cl, requestid, r, &w, span); err != nil {
+		return
 	}
-	// The string does not exist, append it.
-	connection.Applications = append(connection.Applications, applicationid)
 
-	if err := utilities.UpdateObject(h.pd.RWDB(), connection, ctx, h.cfg.Server.PrefixMain); err != nil {
+	link := data.NewConnectionLink(connection.ID, applicationid, requester(r))
+	if err := utilities.CopyMatchingFields(r.Context().Value(KeyConnectionLinkRecord{}).(*data.ConnectionLinkPostWrapper), link); err != nil {
+		helper.ReturnError(cl, http.StatusInternalServerError, helper.ErrorInvalidJSONSchemaForParameter, err, requestid, r, &w, span)
+		return
+	}
+
+	details := fmt.Sprintf("application %s linked", applicationid)
+	if link.ExpiresAt != nil {
+		details += " until " + link.ExpiresAt.UTC().Format(time.RFC3339)
+	}
+
+	audit := data.NewAuditRecord(requestid, connection.ID, data.LinkConnection, requester(r))
+	audit.SetSuccessful(details)
+
+	created, err := h.saveConnectionLink(link, false, audit, ctx)
+	if err != nil {
 		helper.ReturnError(cl, http.StatusInternalServerError, helper.ErrorDatastoreRetrievalFailed, err, requestid, r, &w, span)
+		return
+	}
+
+	if !created {
+		helper.ReturnError(
+			cl,
+			http.StatusBadRequest,
+			helper.ErrorApplicationAlreadyLinked,
+			helper.ErrorDictionary[helper.ErrorApplicationAlreadyLinked].Error(),
+			requestid,
+			r,
+			&w,
+			span)
+		return
 	}
+
+	utilities.WriteResponse(w, cl, link, span)
 }
 
 func (h *ConnectionHandler) UnlinkConnection(w http.ResponseWriter, r *http.Request) {
 
-	// swagger:operation POST /connection/link LinkConnection
-	// Link application to connection
+	// swagger:operation POST /connection/unlink Connection UnlinkConnection
+	// Unlink application from connection
 	//
-	// Endpoint: POST - /v1/connectionmgmt/connection/link
+	// Endpoint: POST - /v1/connectionmgmt/connection/{connectionid}/unlink/{applicationid}
 	//
-	// Description: Link application to connection.
+	// Description: Unlink application from connection. Application can no longer obtain credentials through connection.
 	//
 	// ---
-	// consumes:
-	// - application/json
 	// produces:
 	// - application/json
 	// parameters:
-	// - in: body
-	//   name: Body
-	//   description: JSON string defining AWSConnection resource
+	// - name: connectionid
+	//   in: query
+	//   description: id for generic Connection resource. expected to be in uuid format i.e. XXXXXXXX-XXXX-XXXX-XXXX-XXXXXXXXXXXX
 	//   required: true
-	//   schema:
-	//     "$ref": "#/definitions/AWSConnectionPostWrapper"
+	//   type: string
+	// - name: applicationid
+	//   in: query
+	//   description: id of application to be unlinked. expected to be in uuid format i.e. XXXXXXXX-XXXX-XXXX-XXXX-XXXXXXXXXXXX
+	//   required: true
+	//   type: string
 	// responses:
 	//   '200':
-	//     description: Connection linked successfully.
+	//     description: Application unlinked successfully.
+	//   '403':
+	//     description: Caller lacks operator role on connection
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '404':
+	//     description: Connection not found or application is not linked to it
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
 	//   '500':
 	//     description: Internal server error
 	//     schema:
@@ -334,18 +416,22 @@ func (h *ConnectionHandler) UnlinkConnection(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	found := false
-	apps := []string{}
+	if err := authorize(h.pe, auth.PermissionLink, connection.ID, ctx, cl, requestid, r, &w, span); err != nil {
+		return
+	}
 
-	for _, item := range connection.Applications {
-		if item == applicationid {
-			found = true
-			continue // Skip the string to be removed
-		}
-		apps = append(apps, item)
+	link := data.NewConnectionLink(connection.ID, applicationid, requester(r))
+
+	audit := data.NewAuditRecord(requestid, connection.ID, data.UnlinkConnection, requester(r))
+	audit.SetSuccessful(fmt.Sprintf("application %s unlinked", applicationid))
+
+	deleted, err := h.saveConnectionLink(link, true, audit, ctx)
+	if err != nil {
+		helper.ReturnError(cl, http.StatusInternalServerError, helper.ErrorDatastoreRetrievalFailed, err, requestid, r, &w, span)
+		return
 	}
 
-	if !found {
+	if !deleted {
 		helper.ReturnError(
 			cl,
 			http.StatusNotFound,
@@ -355,12 +441,985 @@ func (h *ConnectionHandler) UnlinkConnection(w http.ResponseWriter, r *http.Requ
 			r,
 			&w,
 			span)
+	}
+}
+
+func (h *ConnectionHandler) fetchConnectionTestResults(connectionid string, limit, skip int) ([]data.ConnectionTestResult, error) {
+	var results []data.ConnectionTestResult
+
+	result := h.pd.RODB().
+		Where("connection_id = ?", connectionid).
+		Limit(limit).
+		Offset(skip).
+		Order("created_at desc").
+		Find(&results)
+
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return results, nil
+}
+
+func (h *ConnectionHandler) GetConnectionTests(w http.ResponseWriter, r *http.Request) {
+
+	// swagger:operation GET /connection/tests Connection GetConnectionTests
+	// List connectivity test history of Connection
+	//
+	// Endpoint: GET - /v1/connectionmgmt/connection/{connectionid}/tests
+	//
+	// Description: Returns results of connectivity tests of connection, most recent first. Results of tests
+	// requested by callers as well as tests run by scheduler are included.
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: connectionid
+	//   in: query
+	//   description: id of generic Connection resource. expected to be in uuid format i.e. XXXXXXXX-XXXX-XXXX-XXXX-XXXXXXXXXXXX
+	//   required: true
+	//   type: string
+	// - name: limit
+	//   in: query
+	//   description: maximum number of results to return.
+	//   required: false
+	//   type: integer
+	//   format: int32
+	// - name: skip
+	//   in: query
+	//   description: number of results to be skipped from beginning of list
+	//   required: false
+	//   type: integer
+	//   format: int32
+	// responses:
+	//   '200':
+	//     description: List of ConnectionTestResult resources
+	//     schema:
+	//         "$ref": "#/definitions/ConnectionTestResultsResponse"
+	//   '400':
+	//     description: Issues with parameters or their value
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '404':
+	//     description: Resource not found.
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '403':
+	//     description: Caller lacks viewer role on connection
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '500':
+	//     description: Internal server error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   default:
+	//     description: unexpected error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	ctx, span, requestid, cl := utilities.SetupTraceAndLogger(r, w, h.l, utilities.GetFunctionName(), h.cfg.Server.PrefixMain)
+	defer span.End()
+
+	connectionid := mux.Vars(r)["connectionid"]
+
+	connection, httpStatusCode, helpError, err := h.getConnection(connectionid)
+	if err != nil {
+		helper.ReturnError(cl, httpStatusCode, helpError, err, requestid, r, &w, span)
+		return
+	}
+
+	if err := authorize(h.pe, auth.PermissionView, connection.ID, ctx, cl, requestid, r, &w, span); err != nil {
 		return
 	}
 
-	connection.Applications = apps
+	vars := r.URL.Query()
+	limit := utilities.ParseQueryParam(vars, "limit", h.list_limit, h.cfg.DataLayer.MaxResults)
+	skip := utilities.ParseQueryParam(vars, "skip", 0, math.MaxInt32)
 
-	if err := utilities.UpdateObject(h.pd.RWDB(), &connection, ctx, h.cfg.Server.PrefixMain); err != nil {
+	results, err := h.fetchConnectionTestResults(connectionid, limit, skip)
+	if err != nil {
 		helper.ReturnError(cl, http.StatusInternalServerError, helper.ErrorDatastoreRetrievalFailed, err, requestid, r, &w, span)
+		return
+	}
+
+	response := data.ConnectionTestResultsResponse{
+		Total:       len(results),
+		Skip:        skip,
+		Limit:       limit,
+		TestResults: results,
+	}
+
+	if response.TestResults == nil {
+		response.TestResults = []data.ConnectionTestResult{}
+	}
+
+	utilities.WriteResponse(w, cl, response, span)
+}
+
+func (h ConnectionHandler) MiddlewareValidateConnectionTestsGet(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+
+		_, span, requestid, cl := utilities.SetupTraceAndLogger(r, rw, h.l, utilities.GetFunctionName(), h.cfg.Server.PrefixMain)
+		defer span.End()
+
+		if _, found := utilities.ValidateQueryStringParam("connectionid", r, cl, rw, span); !found {
+			return
+		}
+
+		vars := r.URL.Query()
+
+		// Validate limit parameter
+		if err := utilities.ValidateQueryParam(vars.Get("limit"), 1, true, cl, r, rw, span, requestid, helper.ErrorInvalidValueForLimit); err != nil {
+			return
+		}
+
+		// Validate skip parameter
+		if err := utilities.ValidateQueryParam(vars.Get("skip"), 0, false, cl, r, rw, span, requestid, helper.ErrorInvalidValueForSkip); err != nil {
+			return
+		}
+
+		// Call the next handler, which can be another middleware in the chain, or the final handler.
+		next.ServeHTTP(rw, r)
+	})
+}
+
+// connectionModel is pointer to model T of connection type whose secrets engine is mounted at its vault_path.
+type connectionModel[T any] interface {
+	*T
+	GenericConnection() *data.Connection
+	Mount() (string, string)
+	DefaultRoleName() string
+	SetVaultMount(path string, namespace string, cfg *configuration.Config)
+}
+
+// connectionEngine maps steps of flow shared by connection types to secrets engine backing connection type T.
+// Optional steps are left nil by types not needing them.
+type connectionEngine[T any] struct {
+	// table holds connections of type
+	table string
+
+	load    func(c *T, ctx context.Context) error
+	mount   func(c *T, ctx context.Context) error
+	update  func(c *T, ctx context.Context) error
+	unmount func(c *T, ctx context.Context) error
+
+	// test generates credentials through roleName and revokes them right away
+	test func(c *T, roleName string, ctx context.Context) error
+
+	// mountFailed is reported when secrets engine of new connection can not be mounted
+	mountFailed helper.ErrorTypeEnum
+
+	// validate returns error to caller if attributes of new or updated connection are not accepted
+	validate func(c *T, cl *slog.Logger, requestid string, r *http.Request, w http.ResponseWriter, span trace.Span) error
+
+	// resolveRole returns name of role requested by caller. Optional, types with single role always use
+	// their default role.
+	resolveRole func(c *T, roleName string, cl *slog.Logger, requestID string, r *http.Request, w *http.ResponseWriter, span trace.Span) (string, error)
+
+	// records returns records created along with connection i.e. its default role. Optional.
+	records func(c *T) []interface{}
+
+	// updateRecords updates records of connection kept besides connection itself along with update of
+	// connection i.e. its default role. Optional.
+	updateRecords func(tx *gorm.DB, c *T) error
+
+	// deleteRecords deletes records of connection kept besides connection itself i.e. its roles. Optional.
+	deleteRecords func(tx *gorm.DB, c *T) error
+
+	// refuseDelete returns error to caller if connection may not be deleted. Optional.
+	refuseDelete func(c *T, cl *slog.Logger, requestid string, r *http.Request, w *http.ResponseWriter, span trace.Span) error
+}
+
+// connectionHandler implements steps of request handling shared by handlers of every connection type on top of
+// secrets engine of type. Handlers of connection types embed it and keep mapping of requests and responses.
+type connectionHandler[T any, PT connectionModel[T]] struct {
+	l          *slog.Logger
+	cfg        *configuration.Config
+	pd         *datalayer.PostgresDataSource
+	sb         secretsmanager.SecretsBackend
+	pe         *auth.PolicyEngine
+	list_limit int
+	engine     connectionEngine[T]
+}
+
+func newConnectionHandler[T any, PT connectionModel[T]](cfg *configuration.Config, l *slog.Logger, pd *datalayer.PostgresDataSource, sb secretsmanager.SecretsBackend, pe *auth.PolicyEngine, engine connectionEngine[T]) connectionHandler[T, PT] {
+	return connectionHandler[T, PT]{
+		l:          l,
+		cfg:        cfg,
+		pd:         pd,
+		sb:         sb,
+		pe:         pe,
+		list_limit: cfg.Server.ListLimit,
+		engine:     engine,
+	}
+}
+
+// getConnection returns connection identified by connectionID. Resources of other connection types are not found.
+func (h *connectionHandler[T, PT]) getConnection(connectionID string, cl *slog.Logger, requestID string, r *http.Request, w *http.ResponseWriter, span trace.Span) (PT, error) {
+	var connection T
+	result := h.pd.RODB().Preload("Connection").First(&connection, "id = ?", connectionID)
+	if result.Error != nil {
+		helper.ReturnError(cl, http.StatusInternalServerError, helper.ErrorDatastoreRetrievalFailed, result.Error, requestID, r, w, span)
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		helper.ReturnError(cl, http.StatusNotFound, helper.ErrorResourceNotFound, helper.ErrorDictionary[helper.ErrorResourceNotFound].Error(), requestID, r, w, span)
+		return nil, fmt.Errorf("resource not found")
+	}
+	return &connection, nil
+}
+
+// listConnections returns page of connections caller may view along with attributes kept by their secrets
+// engines, followed by limit and skip applied.
+func (h *connectionHandler[T, PT]) listConnections(ctx context.Context, cl *slog.Logger, requestID string, r *http.Request, w *http.ResponseWriter, span trace.Span) ([]T, int, int, error) {
+	scope, err := authorizedConnections(h.pe, auth.PermissionView, ctx, cl, requestID, r, w, span)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	vars := r.URL.Query()
+	limit := utilities.ParseQueryParam(vars, "limit", h.list_limit, h.cfg.DataLayer.MaxResults)
+	skip := utilities.ParseQueryParam(vars, "skip", 0, math.MaxInt32)
+
+	var connections []T
+
+	result := scope.apply(h.pd.RODB(), h.engine.table+".connection_id").
+		Preload("Connection").
+		Limit(limit).
+		Offset(skip).
+		Order("connections.name").
+		Joins("LEFT JOIN connections ON connections.id = " + h.engine.table + ".connection_id").
+		Find(&connections)
+
+	if result.Error != nil {
+		helper.ReturnError(cl, http.StatusInternalServerError, helper.ErrorDatastoreRetrievalFailed, result.Error, requestID, r, w, span)
+		return nil, 0, 0, result.Error
+	}
+
+	for i := range connections {
+		if err := h.engine.load(&connections[i], ctx); err != nil {
+			helper.ReturnError(cl, http.StatusInternalServerError, helper.ErrorVaultLoadFailed, err, requestID, r, w, span)
+			return nil, 0, 0, err
+		}
+	}
+
+	return connections, limit, skip, nil
+}
+
+// viewConnection returns connection identified by connectionid of request along with attributes kept by its
+// secrets engine and applications linked to it.
+func (h *connectionHandler[T, PT]) viewConnection(ctx context.Context, cl *slog.Logger, requestID string, r *http.Request, w *http.ResponseWriter, span trace.Span) (PT, []string, error) {
+	connection, err := h.getConnection(mux.Vars(r)["connectionid"], cl, requestID, r, w, span)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	connectionID := connection.GenericConnection().ID
+
+	if err := authorize(h.pe, auth.PermissionView, connectionID, ctx, cl, requestID, r, w, span); err != nil {
+		return nil, nil, err
+	}
+
+	if err := h.engine.load(connection, ctx); err != nil {
+		helper.ReturnError(cl, http.StatusInternalServerError, helper.ErrorVaultLoadFailed, err, requestID, r, w, span)
+		return nil, nil, err
+	}
+
+	applications, err := linkedApplications(h.pd, []uuid.UUID{connectionID})
+	if err != nil {
+		helper.ReturnError(cl, http.StatusInternalServerError, helper.ErrorDatastoreRetrievalFailed, err, requestID, r, w, span)
+		return nil, nil, err
+	}
+
+	return connection, applications[connectionID], nil
+}
+
+// resolveRoleName returns name of role requested by caller, which is default role of connection unless
+// connection type supports several roles and caller names one.
+func (h *connectionHandler[T, PT]) resolveRoleName(c PT, roleName string, cl *slog.Logger, requestID string, r *http.Request, w *http.ResponseWriter, span trace.Span) (string, error) {
+	if h.engine.resolveRole == nil {
+		return c.DefaultRoleName(), nil
+	}
+	return h.engine.resolveRole(c, roleName, cl, requestID, r, w, span)
+}
+
+// credsGrant is request of application for credentials of role of connection which passed authorization.
+type credsGrant[PT any] struct {
+	connection    PT
+	applicationID string
+	roleName      string
+	audit         *data.AuditRecord
+}
+
+// grantCreds authorizes request for credentials issued through connection identified by connectionid of
+// request. Caller needs credential-consumer role on connection and has to identify application linked to
+// connection whose link allows requested role. Connection has to be tested successfully.
+func (h *connectionHandler[T, PT]) grantCreds(ctx context.Context, cl *slog.Logger, requestID string, r *http.Request, w *http.ResponseWriter, span trace.Span) (*credsGrant[PT], error) {
+	connection, err := h.getConnection(mux.Vars(r)["connectionid"], cl, requestID, r, w, span)
+	if err != nil {
+		return nil, err
+	}
+
+	generic := connection.GenericConnection()
+
+	if err := authorize(h.pe, auth.PermissionIssueCreds, generic.ID, ctx, cl, requestID, r, w, span); err != nil {
+		return nil, err
 	}
+
+	applicationID := r.URL.Query().Get("applicationid")
+
+	link, err := authorizeApplication(h.pd, generic.ID, applicationID, h.cfg.Auth.ApplicationClaim, cl, requestID, r, w, span)
+	if err != nil {
+		return nil, err
+	}
+
+	roleName, err := h.resolveRoleName(connection, r.URL.Query().Get("role_name"), cl, requestID, r, w, span)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkCredsGrant(generic, link, roleName, cl, requestID, r, w, span); err != nil {
+		return nil, err
+	}
+
+	return &credsGrant[PT]{
+		connection:    connection,
+		applicationID: applicationID,
+		roleName:      roleName,
+		audit:         data.NewAuditRecord(requestID, generic.ID, data.IssueCredentials, requester(r)),
+	}, nil
+}
+
+// checkCredsGrant returns error to caller unless application linked to connection through link may obtain
+// credentials for role roleName and connection was tested successfully.
+func checkCredsGrant(c *data.Connection, link *data.ConnectionLink, roleName string, cl *slog.Logger, requestID string, r *http.Request, w *http.ResponseWriter, span trace.Span) error {
+	if !link.AllowsRole(roleName) {
+		err := fmt.Errorf("%w: role %s, application %s", helper.ErrRoleNotAllowedForApplication, roleName, link.ApplicationID)
+		helper.ReturnError(cl, http.StatusForbidden, helper.ErrorRoleNotAllowedForApplication, err, requestID, r, w, span)
+		return err
+	}
+
+	if c.TestSuccessful != 1 {
+		helper.ReturnError(cl, http.StatusConflict, helper.ErrorConnectionNotTestedSuccessfully, helper.ErrorDictionary[helper.ErrorConnectionNotTestedSuccessfully].Error(), requestID, r, w, span)
+		return fmt.Errorf("connection not tested successfully")
+	}
+
+	return nil
+}
+
+// failCreds records failure of secrets engine to issue credentials for grant and reports it to caller.
+func (h *connectionHandler[T, PT]) failCreds(g *credsGrant[PT], err error, ctx context.Context, cl *slog.Logger, requestID string, r *http.Request, w *http.ResponseWriter, span trace.Span) {
+	helper.LogDebug(cl, helper.DebugCredsGenerationFailed, err, span)
+	recordAuditFailure(h.pd, g.audit, err, cl, ctx, h.cfg.Server.PrefixMain)
+
+	if errors.Is(err, helper.ErrVaultTTLNotSupportedForCredentialType) {
+		helper.ReturnError(cl, http.StatusBadRequest, helper.ErrorInvalidValueForTTL, err, requestID, r, w, span)
+		return
+	}
+
+	helper.ReturnError(cl, http.StatusInternalServerError, helper.ErrorVaultCredsGenerationFailed, err, requestID, r, w, span)
+}
+
+// issuedLease is Vault lease credentials were issued with. LeaseID is empty for credentials Vault does not
+// lease, such as OAuth2 access tokens of GCP.
+type issuedLease struct {
+	LeaseID       string
+	LeaseDuration int
+	Renewable     bool
+	RoleName      string
+}
+
+// newCredsLease returns lease tracking credentials issued to application under issued, or nil if credentials
+// were issued without lease. Vault can neither renew nor revoke such credentials, so there is nothing to track.
+func newCredsLease(connectionID uuid.UUID, applicationID string, requestID string, user string, issued issuedLease) *data.Lease {
+	if issued.LeaseID == "" {
+		return nil
+	}
+
+	lease := data.NewLease(connectionID, requestID)
+	lease.ApplicationID = applicationID
+	lease.Requester = user
+	lease.RoleName = issued.RoleName
+	lease.LeaseID = issued.LeaseID
+	lease.SetRenewed(issued.LeaseDuration, issued.Renewable)
+
+	return lease
+}
+
+// credsAuditDetails returns details of audit record of credentials described by issued. Credentials without
+// lease are called out as they expire on their own and can not be revoked.
+func credsAuditDetails(issued string, applicationID string, lease *data.Lease) string {
+	if lease == nil {
+		return fmt.Sprintf("%s for application %s without lease", issued, applicationID)
+	}
+	return fmt.Sprintf("%s for application %s with lease %s", issued, applicationID, lease.ID.String())
+}
+
+// recordCreds records issuance of credentials described by issued in audit trail along with lease tracking
+// them. If lease can not be persisted credentials are revoked. Credentials issued without lease are only
+// recorded in audit trail.
+func (h *connectionHandler[T, PT]) recordCreds(g *credsGrant[PT], lease *data.Lease, issued string, ctx context.Context, cl *slog.Logger, requestID string, r *http.Request, w *http.ResponseWriter, span trace.Span) error {
+	g.audit.SetSuccessful(credsAuditDetails(issued, g.applicationID, lease))
+
+	var err error
+
+	if lease == nil {
+		err = utilities.CreateObject(h.pd.RWDB(), g.audit, ctx, h.cfg.Server.PrefixMain)
+	} else {
+		_, namespace := g.connection.Mount()
+		err = recordLease(h.pd, h.sb, lease, namespace, g.audit, ctx, h.cfg.Server.PrefixMain)
+	}
+
+	if err != nil {
+		recordAuditFailure(h.pd, g.audit, err, cl, ctx, h.cfg.Server.PrefixMain)
+		helper.ReturnError(cl, http.StatusInternalServerError, helper.ErrorDatastoreSaveFailed, err, requestID, r, w, span)
+		return err
+	}
+
+	return nil
+}
+
+// testConnection tests connectivity of connection through roleName and records outcome in test history along
+// with audit record. Only default role reflects usability of connection, so test status of connection is only
+// updated when default role is tested.
+func (h *connectionHandler[T, PT]) testConnection(c PT, roleName string, requestID string, user string, scheduled bool, cl *slog.Logger, ctx context.Context, tracerName string) *data.ConnectionTestResult {
+
+	tr := otel.Tracer(tracerName)
+	ctx, span := tr.Start(ctx, utilities.GetFunctionName())
+	defer span.End()
+
+	generic := c.GenericConnection()
+
+	result := data.NewConnectionTestResult(requestID, generic.ID, roleName, scheduled)
+	audit := data.NewAuditRecord(requestID, generic.ID, data.TestConnection, user)
+
+	if err := h.engine.test(c, roleName, ctx); err != nil {
+		helper.LogDebug(cl, helper.DebugConnectionTestFailed, err, span)
+		result.SetFailed(err.Error())
+		audit.SetFailed(fmt.Sprintf("role %s: %s", roleName, err.Error()))
+	} else {
+		result.SetPassed()
+		audit.SetSuccessful(fmt.Sprintf("role %s tested", roleName))
+	}
+
+	var connection *data.Connection
+
+	if roleName == c.DefaultRoleName() {
+		if result.TestSuccessful == 1 {
+			generic.SetTestPassed()
+		} else {
+			generic.SetTestFailed(result.TestError)
+		}
+		connection = generic
+	}
+
+	if err := saveTestResult(h.pd, connection, result, audit, ctx, tracerName); err != nil {
+		helper.LogError(cl, helper.ErrorDatastoreSaveFailed, err, span)
+	}
+
+	return result
+}
+
+// runTest tests connection identified by connectionid of request through role_name requested by caller and
+// returns connection along with name of role tested and outcome of test.
+func (h *connectionHandler[T, PT]) runTest(ctx context.Context, cl *slog.Logger, requestID string, r *http.Request, w *http.ResponseWriter, span trace.Span) (PT, string, *data.ConnectionTestResult, error) {
+	connection, err := h.getConnection(mux.Vars(r)["connectionid"], cl, requestID, r, w, span)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	if err := authorize(h.pe, auth.PermissionTest, connection.GenericConnection().ID, ctx, cl, requestID, r, w, span); err != nil {
+		return nil, "", nil, err
+	}
+
+	roleName, err := h.resolveRoleName(connection, r.URL.Query().Get("role_name"), cl, requestID, r, w, span)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	result := h.testConnection(connection, roleName, requestID, requester(r), false, cl, ctx, h.cfg.Server.PrefixMain)
+
+	return connection, roleName, result, nil
+}
+
+// applyConnectionPatch copies attributes set in patch p to connection c and attributes set in patch
+// of generic Connection to generic Connection of c.
+func applyConnectionPatch(c interface{}, generic *data.Connection, p interface{}, patch *data.ConnectionPatchWrapper) error {
+	if patch != nil {
+		if err := utilities.CopyMatchingFields(patch, generic); err != nil {
+			return err
+		}
+	}
+
+	return utilities.CopyMatchingFields(p, c)
+}
+
+// updateConnection applies patch to connection identified by connectionid of request and reconfigures its
+// secrets engine. Attributes not part of patch are kept as configured in secrets engine. Update resets test
+// status of connection.
+func (h *connectionHandler[T, PT]) updateConnection(patch func(c PT) error, ctx context.Context, cl *slog.Logger, requestid string, r *http.Request, w *http.ResponseWriter, span trace.Span) (PT, error) {
+	connection, err := h.getConnection(mux.Vars(r)["connectionid"], cl, requestid, r, w, span)
+	if err != nil {
+		return nil, err
+	}
+
+	generic := connection.GenericConnection()
+
+	if err := authorize(h.pe, auth.PermissionUpdate, generic.ID, ctx, cl, requestid, r, w, span); err != nil {
+		return nil, err
+	}
+
+	if err := h.engine.load(connection, ctx); err != nil {
+		helper.ReturnError(cl, http.StatusInternalServerError, helper.ErrorVaultLoadFailed, err, requestid, r, w, span)
+		return nil, err
+	}
+
+	if err := patch(connection); err != nil {
+		helper.ReturnError(cl, http.StatusInternalServerError, helper.ErrorJSONDecodingFailed, err, requestid, r, w, span)
+		return nil, err
+	}
+
+	if err := h.engine.validate(connection, cl, requestid, r, *w, span); err != nil {
+		return nil, err
+	}
+
+	generic.ResetTestStatus()
+
+	audit := data.NewAuditRecord(requestid, generic.ID, data.UpdateConnection, requester(r))
+
+	if err := h.saveConnection(connection, audit, ctx); err != nil {
+		recordAuditFailure(h.pd, audit, err, cl, ctx, h.cfg.Server.PrefixMain)
+		helper.ReturnError(cl, http.StatusInternalServerError, helper.ErrorDatastoreSaveFailed, err, requestid, r, w, span)
+		return nil, err
+	}
+
+	return connection, nil
+}
+
+// saveConnection saves connection along with audit record of its update and reconfigures its secrets engine
+// in single transaction.
+func (h *connectionHandler[T, PT]) saveConnection(c PT, audit *data.AuditRecord, ctx context.Context) error {
+
+	tr := otel.Tracer(h.cfg.Server.PrefixMain)
+	ctx, span := tr.Start(ctx, utilities.GetFunctionName())
+	defer span.End()
+
+	generic := c.GenericConnection()
+
+	// Begin a transaction
+	tx := h.pd.RWDB().Begin()
+
+	// Check if the transaction started successfully
+	if tx.Error != nil {
+		return tx.Error
+	}
+
+	if err := tx.Save(generic).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Save(c).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if h.engine.updateRecords != nil {
+		if err := h.engine.updateRecords(tx, c); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	audit.SetSuccessful(fmt.Sprintf("connection %s updated", generic.Name))
+
+	if err := utilities.CreateObjectWithoutTx(tx, audit, ctx, h.cfg.Server.PrefixMain); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := h.engine.update(c, ctx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// Commit the transaction
+	if err := tx.Commit().Error; err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// deleteConnection deletes connection identified by connectionid of request and disables its secrets engine
+// mount. Deletion is refused while applications are linked to connection unless force is set, in which case
+// leases of connection are revoked and applications are unlinked first.
+func (h *connectionHandler[T, PT]) deleteConnection(ctx context.Context, cl *slog.Logger, requestid string, r *http.Request, w *http.ResponseWriter, span trace.Span) error {
+	connectionid := mux.Vars(r)["connectionid"]
+
+	if _, err := uuid.Parse(connectionid); err != nil {
+		helper.ReturnError(cl, http.StatusBadRequest, helper.ErrorConnectionIDInvalid, err, requestid, r, w, span)
+		return err
+	}
+
+	connection, err := h.getConnection(connectionid, cl, requestid, r, w, span)
+	if err != nil {
+		return err
+	}
+
+	generic := connection.GenericConnection()
+
+	if err := authorize(h.pe, auth.PermissionDelete, generic.ID, ctx, cl, requestid, r, w, span); err != nil {
+		return err
+	}
+
+	if h.engine.refuseDelete != nil {
+		if err := h.engine.refuseDelete(connection, cl, requestid, r, w, span); err != nil {
+			return err
+		}
+	}
+
+	links, err := fetchConnectionLinks(h.pd, generic.ID)
+	if err != nil {
+		helper.ReturnError(cl, http.StatusInternalServerError, helper.ErrorDatastoreRetrievalFailed, err, requestid, r, w, span)
+		return err
+	}
+
+	vaultPath, vaultNamespace := connection.Mount()
+
+	if err := releaseConnectionLinks(h.pd, h.sb, generic, links, vaultPath, vaultNamespace, ctx, cl, requestid, r, w, span, h.cfg.Server.PrefixMain); err != nil {
+		return err
+	}
+
+	audit := data.NewAuditRecord(requestid, generic.ID, data.DeleteConnection, requester(r))
+
+	if errCode, err := h.removeConnection(connection, links, audit, ctx); err != nil {
+		recordAuditFailure(h.pd, audit, err, cl, ctx, h.cfg.Server.PrefixMain)
+		helper.ReturnError(cl, http.StatusInternalServerError, errCode, err, requestid, r, w, span)
+		return err
+	}
+
+	return nil
+}
+
+// removeConnection deletes connection along with records of its type, leases, test history, role bindings and
+// links in single transaction and disables its secrets engine mount. Removal of each link is recorded in audit
+// trail before connection is deleted. Error code tells whether datastore or Vault failed.
+func (h *connectionHandler[T, PT]) removeConnection(c PT, links []data.ConnectionLink, audit *data.AuditRecord, ctx context.Context) (helper.ErrorTypeEnum, error) {
+
+	tr := otel.Tracer(h.cfg.Server.PrefixMain)
+	ctx, span := tr.Start(ctx, utilities.GetFunctionName())
+	defer span.End()
+
+	generic := c.GenericConnection()
+
+	// Begin a transaction
+	tx := h.pd.RWDB().Begin()
+
+	// Check if the transaction started successfully
+	if tx.Error != nil {
+		return helper.ErrorDatastoreDeleteFailed, tx.Error
+	}
+
+	if err := utilities.DeleteObjectWithoutTx(tx, c, ctx, h.cfg.Server.PrefixMain); err != nil {
+		tx.Rollback()
+		return helper.ErrorDatastoreDeleteFailed, err
+	}
+
+	// Disabling secrets engine mount removes roles and issued objects from Vault
+	if h.engine.deleteRecords != nil {
+		if err := h.engine.deleteRecords(tx, c); err != nil {
+			tx.Rollback()
+			return helper.ErrorDatastoreDeleteFailed, err
+		}
+	}
+
+	// Delete leases, test history, role bindings and links
+	if err := deleteConnectionRecords(tx, generic, links, audit, ctx, h.cfg.Server.PrefixMain); err != nil {
+		tx.Rollback()
+		return helper.ErrorDatastoreDeleteFailed, err
+	}
+
+	if err := utilities.DeleteObjectWithoutTx(tx, generic, ctx, h.cfg.Server.PrefixMain); err != nil {
+		tx.Rollback()
+		return helper.ErrorDatastoreDeleteFailed, err
+	}
+
+	audit.SetSuccessful(fmt.Sprintf("connection %s deleted", generic.Name))
+
+	if err := utilities.CreateObjectWithoutTx(tx, audit, ctx, h.cfg.Server.PrefixMain); err != nil {
+		tx.Rollback()
+		return helper.ErrorDatastoreDeleteFailed, err
+	}
+
+	if err := h.engine.unmount(c, ctx); err != nil {
+		tx.Rollback()
+		return helper.ErrorVaultDeleteFailed, err
+	}
+
+	// Commit the transaction
+	if err := tx.Commit().Error; err != nil {
+		return helper.ErrorDatastoreDeleteFailed, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return helper.ErrorNone, nil
+}
+
+// prepareConnection fills new connection c from post p and post of its generic Connection and validates it.
+// Secrets engine is mounted at vaultPath in vaultNamespace if provided, which must not be used already.
+// Connections can only be created by callers holding permission on all connections.
+func (h *connectionHandler[T, PT]) prepareConnection(c PT, p interface{}, post data.ConnectionPostWrapper, vaultPath string, vaultNamespace string, ctx context.Context, cl *slog.Logger, requestid string, r *http.Request, w *http.ResponseWriter, span trace.Span) error {
+	if err := authorize(h.pe, auth.PermissionCreate, uuid.Nil, ctx, cl, requestid, r, w, span); err != nil {
+		return err
+	}
+
+	if err := utilities.CopyMatchingFields(p, c); err != nil {
+		helper.ReturnError(cl, http.StatusInternalServerError, helper.ErrorJSONDecodingFailed, err, requestid, r, w, span)
+		return err
+	}
+
+	if err := utilities.CopyMatchingFields(post, c.GenericConnection()); err != nil {
+		helper.ReturnError(cl, http.StatusInternalServerError, helper.ErrorJSONDecodingFailed, err, requestid, r, w, span)
+		return err
+	}
+
+	c.SetVaultMount(vaultPath, vaultNamespace, h.cfg)
+
+	if err := h.engine.validate(c, cl, requestid, r, *w, span); err != nil {
+		return err
+	}
+
+	if vaultPath != "" || vaultNamespace != "" {
+		path, namespace := c.Mount()
+		if err := validateVaultMount(h.pd, h.sb, h.cfg, new(T), path, namespace, ctx, cl, requestid, r, *w, span); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// createConnection persists connection prepared by prepareConnection along with records of its type and
+// mounts its secrets engine through mount in single transaction.
+func (h *connectionHandler[T, PT]) createConnection(c PT, mount func(ctx context.Context) error, ctx context.Context, cl *slog.Logger, requestid string, r *http.Request, w *http.ResponseWriter, span trace.Span) error {
+	generic := c.GenericConnection()
+
+	audit := data.NewAuditRecord(requestid, generic.ID, data.CreateConnection, requester(r))
+
+	records := []interface{}{generic, c}
+	if h.engine.records != nil {
+		records = append(records, h.engine.records(c)...)
+	}
+
+	// Begin a transaction
+	tx := h.pd.RWDB().Begin()
+
+	// Check if the transaction started successfully
+	if tx.Error != nil {
+		helper.ReturnError(cl, http.StatusInternalServerError, helper.ErrorDatastoreSaveFailed, tx.Error, requestid, r, w, span)
+		return tx.Error
+	}
+
+	for _, record := range records {
+		if err := tx.Create(record).Error; err != nil {
+			tx.Rollback()
+			helper.ReturnError(cl, http.StatusInternalServerError, helper.ErrorDatastoreSaveFailed, err, requestid, r, w, span)
+			return err
+		}
+	}
+
+	audit.SetSuccessful(fmt.Sprintf("connection %s created", generic.Name))
+
+	if err := utilities.CreateObjectWithoutTx(tx, audit, ctx, h.cfg.Server.PrefixMain); err != nil {
+		tx.Rollback()
+		helper.ReturnError(cl, http.StatusInternalServerError, helper.ErrorDatastoreSaveFailed, err, requestid, r, w, span)
+		return err
+	}
+
+	if err := mount(ctx); err != nil {
+		tx.Rollback()
+		recordAuditFailure(h.pd, audit, err, cl, ctx, h.cfg.Server.PrefixMain)
+		helper.ReturnError(cl, http.StatusInternalServerError, h.engine.mountFailed, err, requestid, r, w, span)
+		return err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		recordAuditFailure(h.pd, audit, err, cl, ctx, h.cfg.Server.PrefixMain)
+		helper.ReturnError(cl, http.StatusInternalServerError, helper.ErrorDatastoreSaveFailed, err, requestid, r, w, span)
+		return err
+	}
+
+	return nil
+}
+
+// addConnection creates connection c from post p and mounts its secrets engine.
+func (h *connectionHandler[T, PT]) addConnection(c PT, p interface{}, post data.ConnectionPostWrapper, vaultPath string, vaultNamespace string, ctx context.Context, cl *slog.Logger, requestid string, r *http.Request, w *http.ResponseWriter, span trace.Span) error {
+	if err := h.prepareConnection(c, p, post, vaultPath, vaultNamespace, ctx, cl, requestid, r, w, span); err != nil {
+		return err
+	}
+
+	return h.createConnection(c, func(ctx context.Context) error {
+		return h.engine.mount(c, ctx)
+	}, ctx, cl, requestid, r, w, span)
+}
+
+// connectionResponse returns response wrapper W of connection c.
+func connectionResponse[W any](c interface{}, cl *slog.Logger, requestID string, r *http.Request, w *http.ResponseWriter, span trace.Span) (W, error) {
+	var response W
+	if err := utilities.CopyMatchingFields(c, &response); err != nil {
+		helper.ReturnError(cl, http.StatusInternalServerError, helper.ErrorJSONDecodingFailed, err, requestID, r, w, span)
+		return response, err
+	}
+	return response, nil
+}
+
+// connectionResponses returns response wrappers W of connections. Empty list is returned rather than nil.
+func connectionResponses[W any, T any](connections []T, cl *slog.Logger, requestID string, r *http.Request, w *http.ResponseWriter, span trace.Span) ([]W, error) {
+	responses := make([]W, 0, len(connections))
+
+	for i := range connections {
+		response, err := connectionResponse[W](&connections[i], cl, requestID, r, w, span)
+		if err != nil {
+			return nil, err
+		}
+		responses = append(responses, response)
+	}
+
+	return responses, nil
+}
+
+func (h *connectionHandler[T, PT]) MiddlewareValidateConnectionsGet(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+
+		_, span, requestid, cl := utilities.SetupTraceAndLogger(r, rw, h.l, utilities.GetFunctionName(), h.cfg.Server.PrefixMain)
+		defer span.End()
+
+		vars := r.URL.Query()
+
+		// Validate limit parameter
+		if err := utilities.ValidateQueryParam(vars.Get("limit"), 1, true, cl, r, rw, span, requestid, helper.ErrorInvalidValueForLimit); err != nil {
+			return
+		}
+
+		// Validate skip parameter
+		if err := utilities.ValidateQueryParam(vars.Get("skip"), 0, false, cl, r, rw, span, requestid, helper.ErrorInvalidValueForSkip); err != nil {
+			return
+		}
+
+		// Call the next handler, which can be another middleware in the chain, or the final handler.
+		next.ServeHTTP(rw, r)
+	})
+}
+
+func (h *connectionHandler[T, PT]) MiddlewareValidateConnection(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+
+		_, span, _, cl := utilities.SetupTraceAndLogger(r, rw, h.l, utilities.GetFunctionName(), h.cfg.Server.PrefixMain)
+		defer span.End()
+
+		if _, found := utilities.ValidateQueryStringParam("connectionid", r, cl, rw, span); !found {
+			return
+		}
+
+		// Call the next handler, which can be another middleware in the chain, or the final handler.
+		next.ServeHTTP(rw, r)
+	})
+}
+
+func (h *connectionHandler[T, PT]) MiddlewareValidateConnectionDelete(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+
+		_, span, requestid, cl := utilities.SetupTraceAndLogger(r, rw, h.l, utilities.GetFunctionName(), h.cfg.Server.PrefixMain)
+		defer span.End()
+
+		if _, found := utilities.ValidateQueryStringParam("connectionid", r, cl, rw, span); !found {
+			return
+		}
+
+		// Validate force parameter
+		if force := r.URL.Query().Get("force"); force != "" {
+			if _, err := strconv.ParseBool(force); err != nil {
+				helper.ReturnError(cl, http.StatusBadRequest, helper.ErrorInvalidValueForForce, err, requestid, r, &rw, span)
+				return
+			}
+		}
+
+		// Call the next handler, which can be another middleware in the chain, or the final handler.
+		next.ServeHTTP(rw, r)
+	})
+}
+
+func (h *connectionHandler[T, PT]) MiddlewareValidateConnectionCreds(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+
+		_, span, requestid, cl := utilities.SetupTraceAndLogger(r, rw, h.l, utilities.GetFunctionName(), h.cfg.Server.PrefixMain)
+		defer span.End()
+
+		if _, found := utilities.ValidateQueryStringParam("connectionid", r, cl, rw, span); !found {
+			return
+		}
+
+		// Validate applicationid parameter
+		if err := validateApplicationIDParam(r, rw, cl, requestid, span); err != nil {
+			return
+		}
+
+		// Call the next handler, which can be another middleware in the chain, or the final handler.
+		next.ServeHTTP(rw, r)
+	})
+}
+
+// validateConnectionPost decodes and validates post P of connection and adds it to context of request under key.
+func validateConnectionPost[P any](l *slog.Logger, cfg *configuration.Config, key interface{}, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+
+		ctx, span, _, cl := utilities.SetupTraceAndLogger(r, rw, l, utilities.GetFunctionName(), cfg.Server.PrefixMain)
+		defer span.End()
+
+		payload, valid := utilities.DecodeAndValidate[P](r, cl, rw, span)
+		if !valid {
+			return
+		}
+
+		// Add connection to context
+		ctx = context.WithValue(ctx, key, payload)
+		next.ServeHTTP(rw, r.WithContext(ctx))
+	})
+}
+
+// validateConnectionPatch decodes and validates patch P of connection and adds it to context of request under key.
+func validateConnectionPatch[P any](l *slog.Logger, cfg *configuration.Config, key interface{}, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+
+		ctx, span, requestid, cl := utilities.SetupTraceAndLogger(r, rw, l, utilities.GetFunctionName(), cfg.Server.PrefixMain)
+		defer span.End()
+
+		if _, found := utilities.ValidateQueryStringParam("connectionid", r, cl, rw, span); !found {
+			return
+		}
+
+		// Decode JSON into a map
+		var payload map[string]interface{}
+		err := json.NewDecoder(r.Body).Decode(&payload)
+		if err != nil {
+			helper.ReturnError(cl, http.StatusBadRequest, helper.ErrorInvalidJSONSchemaForParameter, err, requestid, r, &rw, span)
+			return
+		}
+
+		var p P
+
+		// Validate and wrap the payload
+		err = utilities.ValidateAndWrapPayload(payload, &p)
+		if err != nil {
+			helper.ReturnError(cl, http.StatusBadRequest, helper.ErrorInvalidJSONSchemaForParameter, err, requestid, r, &rw, span)
+			return
+		}
+
+		// add the connection to the context
+		ctx = context.WithValue(ctx, key, p)
+		r = r.WithContext(ctx)
+
+		// Call the next handler, which can be another middleware in the chain, or the final handler.
+		next.ServeHTTP(rw, r)
+	})
 }
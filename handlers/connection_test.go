@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewCredsLease_WithoutLease(t *testing.T) {
+	// GCP access tokens are not leased by Vault
+	lease := newCredsLease(uuid.New(), "app-1", "request-1", "alice", issuedLease{RoleName: "viewer"})
+	require.Nil(t, lease)
+
+	details := credsAuditDetails("access_token issued for role viewer", "app-1", lease)
+	require.Equal(t, "access_token issued for role viewer for application app-1 without lease", details)
+}
+
+func TestNewCredsLease_WithLease(t *testing.T) {
+	connectionID := uuid.New()
+
+	lease := newCredsLease(connectionID, "app-1", "request-1", "alice", issuedLease{
+		LeaseID:       "gcp/roleset/viewer/key/abc",
+		LeaseDuration: 3600,
+		Renewable:     true,
+		RoleName:      "viewer",
+	})
+	require.NotNil(t, lease)
+	require.Equal(t, connectionID, lease.ConnectionID)
+	require.Equal(t, "app-1", lease.ApplicationID)
+	require.Equal(t, "alice", lease.Requester)
+	require.Equal(t, "viewer", lease.RoleName)
+	require.Equal(t, "gcp/roleset/viewer/key/abc", lease.LeaseID)
+	require.True(t, lease.Renewable)
+
+	details := credsAuditDetails("service_account_key issued for role viewer", "app-1", lease)
+	require.Equal(t, "service_account_key issued for role viewer for application app-1 with lease "+lease.ID.String(), details)
+}
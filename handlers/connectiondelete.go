@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"DemoServer_ConnectionManager/data"
+	"DemoServer_ConnectionManager/datalayer"
+	"DemoServer_ConnectionManager/helper"
+	"DemoServer_ConnectionManager/secretsmanager"
+	"DemoServer_ConnectionManager/utilities"
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+// releaseConnectionLinks refuses deletion of connection while applications are linked to it unless force is
+// requested, in which case leases of connection are revoked before deletion. Error response is written to w
+// if deletion can not proceed.
+func releaseConnectionLinks(pd *datalayer.PostgresDataSource, sb secretsmanager.SecretsBackend, c *data.Connection, links []data.ConnectionLink, vaultPath string, namespace string, ctx context.Context, cl *slog.Logger, requestid string, r *http.Request, w *http.ResponseWriter, span trace.Span, tracerName string) error {
+	force, _ := strconv.ParseBool(r.URL.Query().Get("force"))
+
+	now := time.Now().UTC()
+
+	var linked []string
+	for _, link := range links {
+		if !link.IsExpired(now) {
+			linked = append(linked, link.ApplicationID)
+		}
+	}
+
+	if len(linked) > 0 && !force {
+		err := fmt.Errorf("%w: %s", helper.ErrConnectionLinked, strings.Join(linked, ", "))
+		helper.ReturnError(cl, http.StatusConflict, helper.ErrorConnectionLinked, err, requestid, r, w, span)
+		return err
+	}
+
+	if force {
+		audit := data.NewAuditRecord(requestid, c.ID, data.RevokeLease, requester(r))
+
+		if _, err := revokeConnectionLeases(pd, sb, c.ID, vaultPath, namespace, audit, ctx, tracerName); err != nil {
+			recordAuditFailure(pd, audit, err, cl, ctx, tracerName)
+			helper.ReturnError(cl, http.StatusInternalServerError, helper.ErrorVaultLeaseRevokeFailed, err, requestid, r, w, span)
+			return err
+		}
+	}
+
+	return nil
+}
+
+// deleteConnectionRecords deletes leases, test history, role bindings and links of connection in tx. Removal of
+// each link is recorded in audit trail. Records specific to connection type are left to caller.
+func deleteConnectionRecords(tx *gorm.DB, c *data.Connection, links []data.ConnectionLink, audit *data.AuditRecord, ctx context.Context, tracerName string) error {
+
+	// Delete tracked leases. Disabling secrets engine mount revokes them in Vault.
+	if err := tx.Where("connection_id = ?", c.ID).Delete(&data.Lease{}).Error; err != nil {
+		return err
+	}
+
+	// Delete test history
+	if err := tx.Where("connection_id = ?", c.ID).Delete(&data.ConnectionTestResult{}).Error; err != nil {
+		return err
+	}
+
+	// Delete role bindings scoped to connection
+	if err := tx.Where("connection_id = ?", c.ID).Delete(&data.RoleBinding{}).Error; err != nil {
+		return err
+	}
+
+	// Delete links of applications to connection
+	if err := tx.Where("connection_id = ?", c.ID).Delete(&data.ConnectionLink{}).Error; err != nil {
+		return err
+	}
+
+	for _, link := range links {
+		unlinkAudit := data.NewAuditRecord(audit.RequestID, c.ID, data.UnlinkConnection, audit.UserID)
+		unlinkAudit.SetSuccessful(fmt.Sprintf("application %s unlinked. connection %s deleted", link.ApplicationID, c.Name))
+
+		if err := utilities.CreateObjectWithoutTx(tx, unlinkAudit, ctx, tracerName); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
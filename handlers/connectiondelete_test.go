@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+	"testing"
+	"time"
+
+	"DemoServer_ConnectionManager/data"
+	"DemoServer_ConnectionManager/helper"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestReleaseConnectionLinks_RefusedWhileLinked(t *testing.T) {
+	c := data.Connection{ID: uuid.New()}
+
+	expired := time.Now().Add(-time.Hour)
+
+	active := data.NewConnectionLink(c.ID, uuid.New().String(), "alice")
+	lapsed := data.NewConnectionLink(c.ID, uuid.New().String(), "alice")
+	lapsed.ExpiresAt = &expired
+
+	links := []data.ConnectionLink{*active, *lapsed}
+
+	// Deletion is refused before leases are revoked, so secrets engine is kept
+	response, err := gate(t, "/v1/connectionmgmt/connection/aws/1", "", func(cl *slog.Logger, r *http.Request, w *http.ResponseWriter, span trace.Span) error {
+		return releaseConnectionLinks(nil, nil, &c, links, "aws", "", r.Context(), cl, "request-1", r, w, span, "test")
+	})
+	require.ErrorIs(t, err, helper.ErrConnectionLinked)
+	require.Equal(t, http.StatusConflict, response.Status)
+	require.Equal(t, "ConnectionManager_Err_000076", response.ErrorCode)
+	require.Contains(t, err.Error(), active.ApplicationID)
+	require.NotContains(t, err.Error(), lapsed.ApplicationID)
+}
+
+func TestReleaseConnectionLinks_ExpiredLinksIgnored(t *testing.T) {
+	c := data.Connection{ID: uuid.New()}
+
+	expired := time.Now().Add(-time.Hour)
+
+	lapsed := data.NewConnectionLink(c.ID, uuid.New().String(), "alice")
+	lapsed.ExpiresAt = &expired
+
+	_, err := gate(t, "/v1/connectionmgmt/connection/aws/1", "", func(cl *slog.Logger, r *http.Request, w *http.ResponseWriter, span trace.Span) error {
+		return releaseConnectionLinks(nil, nil, &c, []data.ConnectionLink{*lapsed}, "aws", "", r.Context(), cl, "request-1", r, w, span, "test")
+	})
+	require.NoError(t, err)
+}
@@ -0,0 +1,448 @@
+package handlers
+
+import (
+	"DemoServer_ConnectionManager/auth"
+	"DemoServer_ConnectionManager/configuration"
+	"DemoServer_ConnectionManager/data"
+	"DemoServer_ConnectionManager/datalayer"
+	"DemoServer_ConnectionManager/helper"
+	"DemoServer_ConnectionManager/secretsmanager"
+	"DemoServer_ConnectionManager/utilities"
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/otel"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type KeyConnectionLinkRecord struct{}
+
+// linkEnvironments lists environments links can be tagged with.
+var linkEnvironments = []string{"dev", "stage", "prod"}
+
+// activeLinks restricts q to links which have not expired yet. Expired links are ignored until sweeper removes them.
+func activeLinks(q *gorm.DB, now time.Time) *gorm.DB {
+	return q.Where("(connection_links.expires_at IS NULL OR connection_links.expires_at > ?)", now)
+}
+
+// getConnectionLink returns link of application to connection, or nil if application is not linked to connection.
+func getConnectionLink(pd *datalayer.PostgresDataSource, connectionID uuid.UUID, applicationID string) (*data.ConnectionLink, error) {
+	var links []data.ConnectionLink
+
+	result := activeLinks(pd.RODB(), time.Now().UTC()).
+		Where("connection_id = ? AND application_id = ?", connectionID, applicationID).
+		Limit(1).
+		Find(&links)
+
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	if len(links) == 0 {
+		return nil, nil
+	}
+
+	return &links[0], nil
+}
+
+// fetchConnectionLinks returns every link of connection including expired links which were not removed yet.
+func fetchConnectionLinks(pd *datalayer.PostgresDataSource, connectionID uuid.UUID) ([]data.ConnectionLink, error) {
+	var links []data.ConnectionLink
+
+	result := pd.RODB().
+		Where("connection_id = ?", connectionID).
+		Order("created_at").
+		Find(&links)
+
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return links, nil
+}
+
+// linkedApplications returns IDs of applications linked to each of connections.
+func linkedApplications(pd *datalayer.PostgresDataSource, connectionIDs []uuid.UUID) (map[uuid.UUID][]string, error) {
+	applications := map[uuid.UUID][]string{}
+
+	if len(connectionIDs) == 0 {
+		return applications, nil
+	}
+
+	var links []data.ConnectionLink
+
+	result := activeLinks(pd.RODB(), time.Now().UTC()).
+		Where("connection_id IN ?", connectionIDs).
+		Order("created_at").
+		Find(&links)
+
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	for _, link := range links {
+		applications[link.ConnectionID] = append(applications[link.ConnectionID], link.ApplicationID)
+	}
+
+	return applications, nil
+}
+
+// saveConnectionLink creates or deletes link along with audit record of change in single transaction. Unique
+// constraint on connection and application decides whether link is created so that concurrent calls do not
+// overwrite each other. false is returned if link already existed on create or did not exist on delete.
+func (h *ConnectionHandler) saveConnectionLink(link *data.ConnectionLink, remove bool, a *data.AuditRecord, ctx context.Context) (bool, error) {
+
+	tr := otel.Tracer(h.cfg.Server.PrefixMain)
+	ctx, span := tr.Start(ctx, utilities.GetFunctionName())
+	defer span.End()
+
+	// Begin a transaction
+	tx := h.pd.RWDB().Begin()
+
+	// Check if the transaction started successfully
+	if tx.Error != nil {
+		return false, tx.Error
+	}
+
+	var result *gorm.DB
+	if remove {
+		result = tx.Where("connection_id = ? AND application_id = ?", link.ConnectionID, link.ApplicationID).Delete(&data.ConnectionLink{})
+	} else {
+		result = tx.Clauses(clause.OnConflict{DoNothing: true}).Create(link)
+	}
+
+	if result.Error != nil {
+		tx.Rollback()
+		return false, result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		tx.Rollback()
+		return false, nil
+	}
+
+	if err := utilities.CreateObjectWithoutTx(tx, a, ctx, h.cfg.Server.PrefixMain); err != nil {
+		tx.Rollback()
+		return false, err
+	}
+
+	// Commit the transaction
+	if err := tx.Commit().Error; err != nil {
+		return false, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return true, nil
+}
+
+func (h *ConnectionHandler) fetchApplicationConnections(scope connectionScope, applicationID string, environment string, limit, skip int) ([]data.Connection, error) {
+	var connections []data.Connection
+
+	q := scope.apply(h.pd.RODB(), "connections.id").
+		Joins("JOIN connection_links ON connection_links.connection_id = connections.id").
+		Where("connection_links.application_id = ?", applicationID)
+
+	if environment != "" {
+		q = q.Where("connection_links.environment = ?", environment)
+	}
+
+	result := activeLinks(q, time.Now().UTC()).
+		Limit(limit).
+		Offset(skip).
+		Order("connections.name").
+		Find(&connections)
+
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return connections, nil
+}
+
+func (h *ConnectionHandler) GetApplicationConnections(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation GET /applications/connections Connection GetApplicationConnections
+	// List Connections linked to application
+	//
+	// Endpoint: GET - /v1/connectionmgmt/applications/{applicationid}/connections
+	//
+	// Description: Returns list of generic connections resources application is linked to. Only connections
+	// visible to caller are returned. Expired links are ignored.
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: applicationid
+	//   in: query
+	//   description: id of application. expected to be in uuid format i.e. XXXXXXXX-XXXX-XXXX-XXXX-XXXXXXXXXXXX
+	//   required: true
+	//   type: string
+	// - name: environment
+	//   in: query
+	//   description: environment of links to filter on. One of dev, stage, prod
+	//   required: false
+	//   type: string
+	// - name: limit
+	//   in: query
+	//   description: maximum number of results to return.
+	//   required: false
+	//   type: integer
+	//   format: int32
+	// - name: skip
+	//   in: query
+	//   description: number of results to be skipped from beginning of list
+	//   required: false
+	//   type: integer
+	//   format: int32
+	// responses:
+	//   '200':
+	//     description: List of Connection resources
+	//     schema:
+	//         "$ref": "#/definitions/ConnectionsResponse"
+	//   '400':
+	//     description: Issues with parameters or their value
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '500':
+	//     description: Internal server error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   default:
+	//     description: unexpected error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	ctx, span, requestid, cl := utilities.SetupTraceAndLogger(r, w, h.l, utilities.GetFunctionName(), h.cfg.Server.PrefixMain)
+	defer span.End()
+
+	scope, err := authorizedConnections(h.pe, auth.PermissionView, ctx, cl, requestid, r, &w, span)
+	if err != nil {
+		return
+	}
+
+	vars := r.URL.Query()
+	limit := utilities.ParseQueryParam(vars, "limit", h.list_limit, h.cfg.DataLayer.MaxResults)
+	skip := utilities.ParseQueryParam(vars, "skip", 0, math.MaxInt32)
+
+	connections, err := h.fetchApplicationConnections(scope, mux.Vars(r)["applicationid"], vars.Get("environment"), limit, skip)
+	if err != nil {
+		helper.ReturnError(cl, http.StatusInternalServerError, helper.ErrorDatastoreRetrievalFailed, err, requestid, r, &w, span)
+		return
+	}
+
+	response, err := h.buildConnectionsResponse(connections, limit, skip)
+	if err != nil {
+		helper.ReturnError(cl, http.StatusInternalServerError, helper.ErrorDatastoreRetrievalFailed, err, requestid, r, &w, span)
+		return
+	}
+
+	utilities.WriteResponse(w, cl, response, span)
+}
+
+func (h ConnectionHandler) MiddlewareValidateApplicationConnectionsGet(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+
+		_, span, requestid, cl := utilities.SetupTraceAndLogger(r, rw, h.l, utilities.GetFunctionName(), h.cfg.Server.PrefixMain)
+		defer span.End()
+
+		if _, found := utilities.ValidateQueryStringParam("applicationid", r, cl, rw, span); !found {
+			return
+		}
+
+		vars := r.URL.Query()
+
+		// Validate limit parameter
+		if err := utilities.ValidateQueryParam(vars.Get("limit"), 1, true, cl, r, rw, span, requestid, helper.ErrorInvalidValueForLimit); err != nil {
+			return
+		}
+
+		// Validate skip parameter
+		if err := utilities.ValidateQueryParam(vars.Get("skip"), 0, false, cl, r, rw, span, requestid, helper.ErrorInvalidValueForSkip); err != nil {
+			return
+		}
+
+		// Validate environment parameter
+		if environment := vars.Get("environment"); environment != "" && !slices.Contains(linkEnvironments, environment) {
+			helper.ReturnError(cl, http.StatusBadRequest, helper.ErrorInvalidValueForEnvironment, fmt.Errorf("invalid environment %s", environment), requestid, r, &rw, span)
+			return
+		}
+
+		// Call the next handler, which can be another middleware in the chain, or the final handler.
+		next.ServeHTTP(rw, r)
+	})
+}
+
+// expireConnectionLink deletes expired link and revokes leases tracked for application on connection along with
+// audit record of removal in single transaction. Leases are revoked through sb, so that application loses
+// credentials obtained through link as well. false is returned if link was removed or renewed in the meantime.
+func expireConnectionLink(pd *datalayer.PostgresDataSource, sb secretsmanager.SecretsBackend, link *data.ConnectionLink, a *data.AuditRecord, now time.Time, ctx context.Context, tracerName string) (bool, helper.ErrorTypeEnum, error) {
+
+	tr := otel.Tracer(tracerName)
+	ctx, span := tr.Start(ctx, utilities.GetFunctionName())
+	defer span.End()
+
+	// Begin a transaction
+	tx := pd.RWDB().Begin()
+
+	// Check if the transaction started successfully
+	if tx.Error != nil {
+		return false, helper.ErrorDatastoreSaveFailed, tx.Error
+	}
+
+	result := tx.Where("id = ? AND expires_at <= ?", link.ID, now).Delete(&data.ConnectionLink{})
+	if result.Error != nil {
+		tx.Rollback()
+		return false, helper.ErrorDatastoreSaveFailed, result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		tx.Rollback()
+		return false, helper.ErrorNone, nil
+	}
+
+	revoked, errCode, err := revokeApplicationLeases(pd, tx, sb, link, now, ctx)
+	if err != nil {
+		tx.Rollback()
+		return false, errCode, err
+	}
+
+	details := fmt.Sprintf("application %s unlinked. link expired at %s", link.ApplicationID, link.ExpiresAt.UTC().Format(time.RFC3339))
+	if len(revoked) > 0 {
+		details += fmt.Sprintf(". leases revoked: %s", strings.Join(revoked, ", "))
+	}
+	a.SetSuccessful(details)
+
+	if err := utilities.CreateObjectWithoutTx(tx, a, ctx, tracerName); err != nil {
+		tx.Rollback()
+		return false, helper.ErrorDatastoreSaveFailed, err
+	}
+
+	// Commit the transaction
+	if err := tx.Commit().Error; err != nil {
+		return false, helper.ErrorDatastoreSaveFailed, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return true, helper.ErrorNone, nil
+}
+
+// revokeApplicationLeases revokes unexpired leases tracked for application of link on its connection in Vault and
+// marks them revoked in tx. IDs of revoked leases are returned.
+func revokeApplicationLeases(pd *datalayer.PostgresDataSource, tx *gorm.DB, sb secretsmanager.SecretsBackend, link *data.ConnectionLink, now time.Time, ctx context.Context) ([]string, helper.ErrorTypeEnum, error) {
+	var leases []data.Lease
+
+	result := tx.Where("connection_id = ? AND application_id = ? AND revoked = ? AND expires_at > ?", link.ConnectionID, link.ApplicationID, false, now).Find(&leases)
+	if result.Error != nil {
+		return nil, helper.ErrorDatastoreRetrievalFailed, result.Error
+	}
+
+	if len(leases) == 0 {
+		return nil, helper.ErrorNone, nil
+	}
+
+	connection, _, errCode, err := getMountedConnection(pd, link.ConnectionID.String())
+	if err != nil {
+		return nil, errCode, err
+	}
+
+	revoked := make([]string, 0, len(leases))
+
+	for i := range leases {
+		lease := &leases[i]
+
+		if err := sb.RevokeLease(lease.LeaseID, connection.VaultNamespace, ctx); err != nil {
+			return nil, helper.ErrorVaultLeaseRevokeFailed, err
+		}
+
+		lease.SetRevoked()
+
+		if err := tx.Save(lease).Error; err != nil {
+			return nil, helper.ErrorDatastoreSaveFailed, err
+		}
+
+		revoked = append(revoked, lease.ID.String())
+	}
+
+	return revoked, helper.ErrorNone, nil
+}
+
+// LinkSweeper removes links of applications to connections once they expire so that temporary access can be
+// granted through links with expiry.
+type LinkSweeper struct {
+	l   *slog.Logger
+	cfg *configuration.Config
+	pd  *datalayer.PostgresDataSource
+	sb  secretsmanager.SecretsBackend
+}
+
+func NewLinkSweeper(cfg *configuration.Config, l *slog.Logger, pd *datalayer.PostgresDataSource, sb secretsmanager.SecretsBackend) (*LinkSweeper, error) {
+	var s LinkSweeper
+
+	s.cfg = cfg
+	s.l = l
+	s.pd = pd
+	s.sb = sb
+
+	return &s, nil
+}
+
+// Run removes expired links every LinkSweeper.Interval seconds until ctx is cancelled.
+func (s *LinkSweeper) Run(ctx context.Context) {
+	if s.cfg.LinkSweeper.Interval <= 0 {
+		s.l.Warn("Link sweeper not started. link_sweeper interval must be greater than 0")
+		return
+	}
+
+	ticker := time.NewTicker(time.Duration(s.cfg.LinkSweeper.Interval) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.sweep(ctx)
+		}
+	}
+}
+
+// sweep removes expired links, revokes leases of applications they linked and records audit record of each
+// removal. Links whose leases could not be revoked are retried on next sweep.
+func (s *LinkSweeper) sweep(ctx context.Context) {
+
+	tr := otel.Tracer(s.cfg.Server.PrefixWorker)
+	ctx, span := tr.Start(ctx, utilities.GetFunctionName())
+	defer span.End()
+
+	now := time.Now().UTC()
+
+	var links []data.ConnectionLink
+
+	result := s.pd.RODB().Where("expires_at <= ?", now).Find(&links)
+	if result.Error != nil {
+		helper.LogError(s.l, helper.ErrorDatastoreRetrievalFailed, result.Error, span)
+		return
+	}
+
+	for i := range links {
+		if ctx.Err() != nil {
+			return
+		}
+
+		link := &links[i]
+
+		requestid := uuid.New().String()
+		cl := s.l.With(slog.String("requestid", requestid))
+
+		audit := data.NewAuditRecord(requestid, link.ConnectionID, data.UnlinkConnection, schedulerUser)
+
+		if _, errCode, err := expireConnectionLink(s.pd, s.sb, link, audit, now, ctx, s.cfg.Server.PrefixWorker); err != nil {
+			helper.LogError(cl, errCode, err, span)
+		}
+	}
+}
@@ -0,0 +1,533 @@
+package handlers
+
+import (
+	"DemoServer_ConnectionManager/auth"
+	"DemoServer_ConnectionManager/configuration"
+	"DemoServer_ConnectionManager/data"
+	"DemoServer_ConnectionManager/helper"
+	"DemoServer_ConnectionManager/utilities"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+// roleModel is pointer to model R of role of connection type supporting several roles.
+type roleModel[R any] interface {
+	*R
+	Name() string
+}
+
+// roleEngine maps steps of role management shared by connection types supporting several roles to roles R of
+// secrets engine backing connection type T.
+type roleEngine[T any, R any] struct {
+	// column references connection of type T in table of roles
+	column string
+
+	// owner returns id of connection of type T roles of connection reference
+	owner func(c *T) uuid.UUID
+
+	newRole func(ownerID uuid.UUID) *R
+
+	load   func(path string, namespace string, role *R, ctx context.Context) error
+	add    func(path string, namespace string, role *R, ctx context.Context) error
+	update func(path string, namespace string, role *R, ctx context.Context) error
+	remove func(path string, namespace string, roleName string, ctx context.Context) error
+
+	// validName tells whether name can be used as name of role
+	validName func(name string) bool
+
+	// validate returns error to caller if attributes of new or updated role are not accepted
+	validate func(role *R, cl *slog.Logger, requestid string, r *http.Request, w http.ResponseWriter, span trace.Span) error
+
+	// defaults fills attributes of new role not provided by caller from connection. Optional.
+	defaults func(c *T, role *R, ctx context.Context) error
+}
+
+// roleHandler implements management of roles of connection types supporting several roles besides default
+// role of connection. Handlers of these connection types embed it along with connectionHandler and keep mapping
+// of requests and responses.
+type roleHandler[T any, PT connectionModel[T], R any, PR roleModel[R]] struct {
+	conn  *connectionHandler[T, PT]
+	roles roleEngine[T, R]
+}
+
+func newRoleHandler[T any, PT connectionModel[T], R any, PR roleModel[R]](conn *connectionHandler[T, PT], roles roleEngine[T, R]) roleHandler[T, PT, R, PR] {
+	return roleHandler[T, PT, R, PR]{
+		conn:  conn,
+		roles: roles,
+	}
+}
+
+// resolveRole returns name of role to be used for connection. Default role of connection is used when roleName
+// is not specified, otherwise role has to exist for connection.
+func (h *roleHandler[T, PT, R, PR]) resolveRole(c *T, roleName string, cl *slog.Logger, requestID string, r *http.Request, w *http.ResponseWriter, span trace.Span) (string, error) {
+	defaultRoleName := PT(c).DefaultRoleName()
+
+	if roleName == "" || roleName == defaultRoleName {
+		return defaultRoleName, nil
+	}
+
+	if !h.roles.validName(roleName) {
+		helper.ReturnError(cl, http.StatusBadRequest, helper.ErrorInvalidRoleName, helper.ErrorDictionary[helper.ErrorInvalidRoleName].Error(), requestID, r, w, span)
+		return "", fmt.Errorf("invalid role name")
+	}
+
+	if _, err := h.getRole(c, roleName, cl, requestID, r, w, span); err != nil {
+		return "", err
+	}
+
+	return roleName, nil
+}
+
+func (h *roleHandler[T, PT, R, PR]) getRole(c *T, roleName string, cl *slog.Logger, requestID string, r *http.Request, w *http.ResponseWriter, span trace.Span) (PR, error) {
+	var role R
+	result := h.conn.pd.RODB().Where(h.roles.column+" = ? AND role_name = ?", h.roles.owner(c), roleName).Limit(1).Find(&role)
+	if result.Error != nil {
+		helper.ReturnError(cl, http.StatusInternalServerError, helper.ErrorDatastoreRetrievalFailed, result.Error, requestID, r, w, span)
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		helper.ReturnError(cl, http.StatusNotFound, helper.ErrorResourceNotFound, helper.ErrorDictionary[helper.ErrorResourceNotFound].Error(), requestID, r, w, span)
+		return nil, fmt.Errorf("resource not found")
+	}
+	return &role, nil
+}
+
+func (h *roleHandler[T, PT, R, PR]) fetchRoles(c *T, limit, skip int) ([]R, error) {
+	var roles []R
+
+	result := h.conn.pd.RODB().
+		Where(h.roles.column+" = ?", h.roles.owner(c)).
+		Limit(limit).
+		Offset(skip).
+		Order("role_name").
+		Find(&roles)
+
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return roles, nil
+}
+
+// fetchAdditionalRoles returns roles of connection other than its default role along with their Vault attributes.
+func (h *roleHandler[T, PT, R, PR]) fetchAdditionalRoles(c *T, ctx context.Context) ([]R, error) {
+	var roles []R
+
+	result := h.conn.pd.RODB().
+		Where(h.roles.column+" = ? AND role_name <> ?", h.roles.owner(c), PT(c).DefaultRoleName()).
+		Find(&roles)
+
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	path, namespace := PT(c).Mount()
+
+	for i := range roles {
+		if err := h.roles.load(path, namespace, &roles[i], ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	return roles, nil
+}
+
+// deleteRoles deletes roles of connection along with connection in transaction tx. Disabling secrets engine
+// mount removes them from Vault.
+func (h *roleHandler[T, PT, R, PR]) deleteRoles(tx *gorm.DB, c *T) error {
+	return tx.Where(h.roles.column+" = ?", h.roles.owner(c)).Delete(new(R)).Error
+}
+
+// roleConnection returns connection identified by connectionid of request if caller holds permission on it.
+func (h *roleHandler[T, PT, R, PR]) roleConnection(permission auth.Permission, ctx context.Context, cl *slog.Logger, requestid string, r *http.Request, w *http.ResponseWriter, span trace.Span) (PT, error) {
+	connection, err := h.conn.getConnection(mux.Vars(r)["connectionid"], cl, requestid, r, w, span)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := authorize(h.conn.pe, permission, connection.GenericConnection().ID, ctx, cl, requestid, r, w, span); err != nil {
+		return nil, err
+	}
+
+	return connection, nil
+}
+
+// listRoles returns page of roles of connection requested by limit and skip along with their Vault attributes.
+// Default role of connection is included.
+func (h *roleHandler[T, PT, R, PR]) listRoles(ctx context.Context, cl *slog.Logger, requestid string, r *http.Request, w *http.ResponseWriter, span trace.Span) ([]R, int, int, error) {
+	vars := r.URL.Query()
+	limit := utilities.ParseQueryParam(vars, "limit", h.conn.list_limit, h.conn.cfg.DataLayer.MaxResults)
+	skip := utilities.ParseQueryParam(vars, "skip", 0, math.MaxInt32)
+
+	connection, err := h.roleConnection(auth.PermissionView, ctx, cl, requestid, r, w, span)
+	if err != nil {
+		return nil, limit, skip, err
+	}
+
+	roles, err := h.fetchRoles(connection, limit, skip)
+	if err != nil {
+		helper.ReturnError(cl, http.StatusInternalServerError, helper.ErrorDatastoreRetrievalFailed, err, requestid, r, w, span)
+		return nil, limit, skip, err
+	}
+
+	path, namespace := connection.Mount()
+
+	for i := range roles {
+		if err := h.roles.load(path, namespace, &roles[i], ctx); err != nil {
+			helper.ReturnError(cl, http.StatusInternalServerError, helper.ErrorVaultLoadFailed, err, requestid, r, w, span)
+			return nil, limit, skip, err
+		}
+	}
+
+	if roles == nil {
+		roles = []R{}
+	}
+
+	return roles, limit, skip, nil
+}
+
+// viewRole returns role identified by rolename of request along with its Vault attributes.
+func (h *roleHandler[T, PT, R, PR]) viewRole(ctx context.Context, cl *slog.Logger, requestid string, r *http.Request, w *http.ResponseWriter, span trace.Span) (PR, error) {
+	connection, err := h.roleConnection(auth.PermissionView, ctx, cl, requestid, r, w, span)
+	if err != nil {
+		return nil, err
+	}
+
+	role, err := h.getRole(connection, mux.Vars(r)["rolename"], cl, requestid, r, w, span)
+	if err != nil {
+		return nil, err
+	}
+
+	path, namespace := connection.Mount()
+
+	if err := h.roles.load(path, namespace, role, ctx); err != nil {
+		helper.ReturnError(cl, http.StatusInternalServerError, helper.ErrorVaultLoadFailed, err, requestid, r, w, span)
+		return nil, err
+	}
+
+	return role, nil
+}
+
+// addRole creates role of connection from post p and configures it in secrets engine of connection in single
+// transaction.
+func (h *roleHandler[T, PT, R, PR]) addRole(p interface{}, ctx context.Context, cl *slog.Logger, requestid string, r *http.Request, w *http.ResponseWriter, span trace.Span) (PR, error) {
+	connection, err := h.roleConnection(auth.PermissionUpdate, ctx, cl, requestid, r, w, span)
+	if err != nil {
+		return nil, err
+	}
+
+	var role PR = h.roles.newRole(h.roles.owner(connection))
+
+	if err := utilities.CopyMatchingFields(p, role); err != nil {
+		helper.ReturnError(cl, http.StatusInternalServerError, helper.ErrorJSONDecodingFailed, err, requestid, r, w, span)
+		return nil, err
+	}
+
+	if err := h.roles.validate(role, cl, requestid, r, *w, span); err != nil {
+		return nil, err
+	}
+
+	var count int64
+	if err := h.conn.pd.RODB().Model(new(R)).Where(h.roles.column+" = ? AND role_name = ?", h.roles.owner(connection), role.Name()).Count(&count).Error; err != nil {
+		helper.ReturnError(cl, http.StatusInternalServerError, helper.ErrorDatastoreRetrievalFailed, err, requestid, r, w, span)
+		return nil, err
+	}
+
+	if count > 0 || role.Name() == connection.DefaultRoleName() {
+		helper.ReturnError(cl, http.StatusConflict, helper.ErrorAWSRoleAlreadyExists, helper.ErrorDictionary[helper.ErrorAWSRoleAlreadyExists].Error(), requestid, r, w, span)
+		return nil, fmt.Errorf("role already exists")
+	}
+
+	if h.roles.defaults != nil {
+		if err := h.roles.defaults(connection, role, ctx); err != nil {
+			helper.ReturnError(cl, http.StatusInternalServerError, helper.ErrorVaultLoadFailed, err, requestid, r, w, span)
+			return nil, err
+		}
+	}
+
+	audit := data.NewAuditRecord(requestid, connection.GenericConnection().ID, data.CreateRole, requester(r))
+
+	// Begin a transaction
+	tx := h.conn.pd.RWDB().Begin()
+
+	// Check if the transaction started successfully
+	if tx.Error != nil {
+		helper.ReturnError(cl, http.StatusInternalServerError, helper.ErrorDatastoreSaveFailed, tx.Error, requestid, r, w, span)
+		return nil, tx.Error
+	}
+
+	if err := utilities.CreateObjectWithoutTx[R](tx, role, ctx, h.conn.cfg.Server.PrefixMain); err != nil {
+		tx.Rollback()
+		helper.ReturnError(cl, http.StatusInternalServerError, helper.ErrorDatastoreSaveFailed, err, requestid, r, w, span)
+		return nil, err
+	}
+
+	audit.SetSuccessful(fmt.Sprintf("role %s created", role.Name()))
+
+	if err := utilities.CreateObjectWithoutTx(tx, audit, ctx, h.conn.cfg.Server.PrefixMain); err != nil {
+		tx.Rollback()
+		helper.ReturnError(cl, http.StatusInternalServerError, helper.ErrorDatastoreSaveFailed, err, requestid, r, w, span)
+		return nil, err
+	}
+
+	path, namespace := connection.Mount()
+
+	if err := h.roles.add(path, namespace, role, ctx); err != nil {
+		tx.Rollback()
+		recordAuditFailure(h.conn.pd, audit, err, cl, ctx, h.conn.cfg.Server.PrefixMain)
+		helper.ReturnError(cl, http.StatusInternalServerError, helper.ErrorVaultRoleConfigurationFailed, err, requestid, r, w, span)
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		recordAuditFailure(h.conn.pd, audit, err, cl, ctx, h.conn.cfg.Server.PrefixMain)
+		helper.ReturnError(cl, http.StatusInternalServerError, helper.ErrorDatastoreSaveFailed, err, requestid, r, w, span)
+		return nil, err
+	}
+
+	return role, nil
+}
+
+// updateRole applies patch p to role identified by rolename of request. Attributes not part of patch are kept as
+// configured in Vault. Default role of connection is only updated through connection.
+func (h *roleHandler[T, PT, R, PR]) updateRole(p interface{}, ctx context.Context, cl *slog.Logger, requestid string, r *http.Request, w *http.ResponseWriter, span trace.Span) (PR, error) {
+	connection, err := h.roleConnection(auth.PermissionUpdate, ctx, cl, requestid, r, w, span)
+	if err != nil {
+		return nil, err
+	}
+
+	roleName := mux.Vars(r)["rolename"]
+
+	if roleName == connection.DefaultRoleName() {
+		helper.ReturnError(cl, http.StatusBadRequest, helper.ErrorAWSRoleUpdateNotAllowed, helper.ErrorDictionary[helper.ErrorAWSRoleUpdateNotAllowed].Error(), requestid, r, w, span)
+		return nil, fmt.Errorf("default role update not allowed")
+	}
+
+	role, err := h.getRole(connection, roleName, cl, requestid, r, w, span)
+	if err != nil {
+		return nil, err
+	}
+
+	path, namespace := connection.Mount()
+
+	if err := h.roles.load(path, namespace, role, ctx); err != nil {
+		helper.ReturnError(cl, http.StatusInternalServerError, helper.ErrorVaultLoadFailed, err, requestid, r, w, span)
+		return nil, err
+	}
+
+	if err := utilities.CopyMatchingFields(p, role); err != nil {
+		helper.ReturnError(cl, http.StatusInternalServerError, helper.ErrorJSONDecodingFailed, err, requestid, r, w, span)
+		return nil, err
+	}
+
+	if err := h.roles.validate(role, cl, requestid, r, *w, span); err != nil {
+		return nil, err
+	}
+
+	audit := data.NewAuditRecord(requestid, connection.GenericConnection().ID, data.UpdateRole, requester(r))
+
+	if err := h.roles.update(path, namespace, role, ctx); err != nil {
+		recordAuditFailure(h.conn.pd, audit, err, cl, ctx, h.conn.cfg.Server.PrefixMain)
+		helper.ReturnError(cl, http.StatusInternalServerError, helper.ErrorVaultRoleConfigurationFailed, err, requestid, r, w, span)
+		return nil, err
+	}
+
+	audit.SetSuccessful(fmt.Sprintf("role %s updated", role.Name()))
+
+	if err := saveWithAudit[R](h.conn.pd, role, audit, ctx, h.conn.cfg.Server.PrefixMain); err != nil {
+		helper.ReturnError(cl, http.StatusInternalServerError, helper.ErrorDatastoreSaveFailed, err, requestid, r, w, span)
+		return nil, err
+	}
+
+	return role, nil
+}
+
+// deleteRole deletes role identified by rolename of request from datastore and Vault. Default role of
+// connection can not be deleted.
+func (h *roleHandler[T, PT, R, PR]) deleteRole(ctx context.Context, cl *slog.Logger, requestid string, r *http.Request, w *http.ResponseWriter, span trace.Span) error {
+	connection, err := h.roleConnection(auth.PermissionUpdate, ctx, cl, requestid, r, w, span)
+	if err != nil {
+		return err
+	}
+
+	roleName := mux.Vars(r)["rolename"]
+
+	if roleName == connection.DefaultRoleName() {
+		helper.ReturnError(cl, http.StatusBadRequest, helper.ErrorAWSRoleDeleteNotAllowed, helper.ErrorDictionary[helper.ErrorAWSRoleDeleteNotAllowed].Error(), requestid, r, w, span)
+		return fmt.Errorf("default role delete not allowed")
+	}
+
+	role, err := h.getRole(connection, roleName, cl, requestid, r, w, span)
+	if err != nil {
+		return err
+	}
+
+	audit := data.NewAuditRecord(requestid, connection.GenericConnection().ID, data.DeleteRole, requester(r))
+
+	if err := h.removeRole(connection, role, audit, ctx); err != nil {
+		recordAuditFailure(h.conn.pd, audit, err, cl, ctx, h.conn.cfg.Server.PrefixMain)
+		helper.ReturnError(cl, http.StatusInternalServerError, helper.ErrorDatastoreDeleteFailed, err, requestid, r, w, span)
+		return err
+	}
+
+	return nil
+}
+
+func (h *roleHandler[T, PT, R, PR]) removeRole(c PT, role PR, audit *data.AuditRecord, ctx context.Context) error {
+
+	tr := otel.Tracer(h.conn.cfg.Server.PrefixMain)
+	ctx, span := tr.Start(ctx, utilities.GetFunctionName())
+	defer span.End()
+
+	// Begin a transaction
+	tx := h.conn.pd.RWDB().Begin()
+
+	// Check if the transaction started successfully
+	if tx.Error != nil {
+		return tx.Error
+	}
+
+	if err := utilities.DeleteObjectWithoutTx[R](tx, role, ctx, h.conn.cfg.Server.PrefixMain); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	audit.SetSuccessful(fmt.Sprintf("role %s deleted", role.Name()))
+
+	if err := utilities.CreateObjectWithoutTx(tx, audit, ctx, h.conn.cfg.Server.PrefixMain); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	path, namespace := c.Mount()
+
+	if err := h.roles.remove(path, namespace, role.Name(), ctx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// Commit the transaction
+	if err := tx.Commit().Error; err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+func (h *roleHandler[T, PT, R, PR]) MiddlewareValidateRolesGet(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+
+		_, span, requestid, cl := utilities.SetupTraceAndLogger(r, rw, h.conn.l, utilities.GetFunctionName(), h.conn.cfg.Server.PrefixMain)
+		defer span.End()
+
+		if _, found := utilities.ValidateQueryStringParam("connectionid", r, cl, rw, span); !found {
+			return
+		}
+
+		vars := r.URL.Query()
+
+		// Validate limit parameter
+		if err := utilities.ValidateQueryParam(vars.Get("limit"), 1, true, cl, r, rw, span, requestid, helper.ErrorInvalidValueForLimit); err != nil {
+			return
+		}
+
+		// Validate skip parameter
+		if err := utilities.ValidateQueryParam(vars.Get("skip"), 0, false, cl, r, rw, span, requestid, helper.ErrorInvalidValueForSkip); err != nil {
+			return
+		}
+
+		// Call the next handler, which can be another middleware in the chain, or the final handler.
+		next.ServeHTTP(rw, r)
+	})
+}
+
+func (h *roleHandler[T, PT, R, PR]) MiddlewareValidateRole(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+
+		_, span, _, cl := utilities.SetupTraceAndLogger(r, rw, h.conn.l, utilities.GetFunctionName(), h.conn.cfg.Server.PrefixMain)
+		defer span.End()
+
+		if _, found := utilities.ValidateQueryStringParam("connectionid", r, cl, rw, span); !found {
+			return
+		}
+
+		if _, found := utilities.ValidateQueryStringParam("rolename", r, cl, rw, span); !found {
+			return
+		}
+
+		// Call the next handler, which can be another middleware in the chain, or the final handler.
+		next.ServeHTTP(rw, r)
+	})
+}
+
+// validateRolePost decodes and validates post P of role and adds it to context of request under key.
+func validateRolePost[P any](l *slog.Logger, cfg *configuration.Config, key interface{}, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+
+		ctx, span, _, cl := utilities.SetupTraceAndLogger(r, rw, l, utilities.GetFunctionName(), cfg.Server.PrefixMain)
+		defer span.End()
+
+		if _, found := utilities.ValidateQueryStringParam("connectionid", r, cl, rw, span); !found {
+			return
+		}
+
+		payload, valid := utilities.DecodeAndValidate[P](r, cl, rw, span)
+		if !valid {
+			return
+		}
+
+		// Add role to context
+		ctx = context.WithValue(ctx, key, payload)
+		next.ServeHTTP(rw, r.WithContext(ctx))
+	})
+}
+
+// validateRolePatch decodes and validates patch P of role and adds it to context of request under key.
+func validateRolePatch[P any](l *slog.Logger, cfg *configuration.Config, key interface{}, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+
+		ctx, span, requestid, cl := utilities.SetupTraceAndLogger(r, rw, l, utilities.GetFunctionName(), cfg.Server.PrefixMain)
+		defer span.End()
+
+		if _, found := utilities.ValidateQueryStringParam("connectionid", r, cl, rw, span); !found {
+			return
+		}
+
+		if _, found := utilities.ValidateQueryStringParam("rolename", r, cl, rw, span); !found {
+			return
+		}
+
+		// Decode JSON into a map
+		var payload map[string]interface{}
+		err := json.NewDecoder(r.Body).Decode(&payload)
+		if err != nil {
+			helper.ReturnError(cl, http.StatusBadRequest, helper.ErrorInvalidJSONSchemaForParameter, err, requestid, r, &rw, span)
+			return
+		}
+
+		var p P
+
+		// Validate and wrap the payload
+		err = utilities.ValidateAndWrapPayload(payload, &p)
+		if err != nil {
+			helper.ReturnError(cl, http.StatusBadRequest, helper.ErrorInvalidJSONSchemaForParameter, err, requestid, r, &rw, span)
+			return
+		}
+
+		// add the role patch to the context
+		ctx = context.WithValue(ctx, key, p)
+		r = r.WithContext(ctx)
+
+		// Call the next handler, which can be another middleware in the chain, or the final handler.
+		next.ServeHTTP(rw, r)
+	})
+}
@@ -0,0 +1,230 @@
+package handlers
+
+import (
+	"DemoServer_ConnectionManager/configuration"
+	"DemoServer_ConnectionManager/data"
+	"DemoServer_ConnectionManager/datalayer"
+	"DemoServer_ConnectionManager/helper"
+	"DemoServer_ConnectionManager/utilities"
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+)
+
+// schedulerUser is recorded as requester of audit records written by connection test scheduler.
+const schedulerUser = "scheduler"
+
+// saveTestResult saves test result and audit record of test in single transaction. Test status of
+// connection is saved as well unless connection is nil.
+func saveTestResult(pd *datalayer.PostgresDataSource, connection *data.Connection, result *data.ConnectionTestResult, a *data.AuditRecord, ctx context.Context, tracerName string) error {
+
+	tr := otel.Tracer(tracerName)
+	ctx, span := tr.Start(ctx, utilities.GetFunctionName())
+	defer span.End()
+
+	// Begin a transaction
+	tx := pd.RWDB().Begin()
+
+	// Check if the transaction started successfully
+	if tx.Error != nil {
+		return tx.Error
+	}
+
+	// Only test status is updated so that concurrent changes to connection are not overwritten
+	if connection != nil {
+		if err := tx.Model(connection).
+			Select("test_successful", "test_error", "tested_on", "last_successful_test").
+			Updates(connection).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	if err := utilities.CreateObjectWithoutTx(tx, result, ctx, tracerName); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := utilities.CreateObjectWithoutTx(tx, a, ctx, tracerName); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// Commit the transaction
+	if err := tx.Commit().Error; err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// ConnectionTestScheduler re-tests every connection of every type on configured interval. Only default role of
+// AWSConnections is tested.
+type ConnectionTestScheduler struct {
+	l   *slog.Logger
+	cfg *configuration.Config
+	pd  *datalayer.PostgresDataSource
+	ah  *AWSConnectionHandler
+	zh  *AzureConnectionHandler
+	gh  *GCPConnectionHandler
+	dh  *DatabaseConnectionHandler
+	sh  *SSHConnectionHandler
+	ph  *PKIConnectionHandler
+}
+
+func NewConnectionTestScheduler(cfg *configuration.Config, l *slog.Logger, pd *datalayer.PostgresDataSource, ah *AWSConnectionHandler, zh *AzureConnectionHandler, gh *GCPConnectionHandler, dh *DatabaseConnectionHandler, sh *SSHConnectionHandler, ph *PKIConnectionHandler) (*ConnectionTestScheduler, error) {
+	var s ConnectionTestScheduler
+
+	s.cfg = cfg
+	s.l = l
+	s.pd = pd
+	s.ah = ah
+	s.zh = zh
+	s.gh = gh
+	s.dh = dh
+	s.sh = sh
+	s.ph = ph
+
+	return &s, nil
+}
+
+// Run tests all connections every ConnectionTest.Interval seconds until ctx is cancelled. A run which
+// takes longer than interval delays next run rather than overlapping with it.
+func (s *ConnectionTestScheduler) Run(ctx context.Context) {
+	if s.cfg.ConnectionTest.Interval <= 0 {
+		s.l.Warn("Connection test scheduler not started. connection_test interval must be greater than 0")
+		return
+	}
+
+	ticker := time.NewTicker(time.Duration(s.cfg.ConnectionTest.Interval) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.testConnections(ctx)
+		}
+	}
+}
+
+// connectionTest tests single connection and records outcome in its test history.
+type connectionTest func(requestid string, cl *slog.Logger, ctx context.Context)
+
+// fetchConnectionTests returns test of every connection of type T in datastore.
+func fetchConnectionTests[T any](pd *datalayer.PostgresDataSource, test func(c *T, requestid string, cl *slog.Logger, ctx context.Context)) ([]connectionTest, error) {
+	var connections []T
+
+	if err := pd.RODB().Preload("Connection").Find(&connections).Error; err != nil {
+		return nil, err
+	}
+
+	tests := make([]connectionTest, 0, len(connections))
+	for i := range connections {
+		c := &connections[i]
+		tests = append(tests, func(requestid string, cl *slog.Logger, ctx context.Context) {
+			test(c, requestid, cl, ctx)
+		})
+	}
+
+	return tests, nil
+}
+
+// fetchTests returns test of every connection of every type.
+func (s *ConnectionTestScheduler) fetchTests() ([]connectionTest, error) {
+	tracerName := s.cfg.Server.PrefixWorker
+
+	fetches := []func() ([]connectionTest, error){
+		func() ([]connectionTest, error) {
+			return fetchConnectionTests(s.pd, func(c *data.AWSConnection, requestid string, cl *slog.Logger, ctx context.Context) {
+				s.ah.testConnection(c, c.RoleName, requestid, schedulerUser, true, cl, ctx, tracerName)
+			})
+		},
+		func() ([]connectionTest, error) {
+			return fetchConnectionTests(s.pd, func(c *data.AzureConnection, requestid string, cl *slog.Logger, ctx context.Context) {
+				s.zh.testConnection(c, c.RoleName, requestid, schedulerUser, true, cl, ctx, tracerName)
+			})
+		},
+		func() ([]connectionTest, error) {
+			return fetchConnectionTests(s.pd, func(c *data.GCPConnection, requestid string, cl *slog.Logger, ctx context.Context) {
+				s.gh.testConnection(c, c.RoleName, requestid, schedulerUser, true, cl, ctx, tracerName)
+			})
+		},
+		func() ([]connectionTest, error) {
+			return fetchConnectionTests(s.pd, func(c *data.DatabaseConnection, requestid string, cl *slog.Logger, ctx context.Context) {
+				s.dh.testConnection(c, c.RoleName, requestid, schedulerUser, true, cl, ctx, tracerName)
+			})
+		},
+		func() ([]connectionTest, error) {
+			return fetchConnectionTests(s.pd, func(c *data.SSHConnection, requestid string, cl *slog.Logger, ctx context.Context) {
+				s.sh.testConnection(c, c.RoleName, requestid, schedulerUser, true, cl, ctx, tracerName)
+			})
+		},
+		func() ([]connectionTest, error) {
+			return fetchConnectionTests(s.pd, func(c *data.PKIConnection, requestid string, cl *slog.Logger, ctx context.Context) {
+				s.ph.testConnection(c, c.RoleName, requestid, schedulerUser, true, cl, ctx, tracerName)
+			})
+		},
+	}
+
+	var tests []connectionTest
+
+	for _, fetch := range fetches {
+		t, err := fetch()
+		if err != nil {
+			return nil, err
+		}
+		tests = append(tests, t...)
+	}
+
+	return tests, nil
+}
+
+// testConnections tests all connections with at most ConnectionTest.Concurrency tests in flight.
+func (s *ConnectionTestScheduler) testConnections(ctx context.Context) {
+
+	tr := otel.Tracer(s.cfg.Server.PrefixWorker)
+	ctx, span := tr.Start(ctx, utilities.GetFunctionName())
+	defer span.End()
+
+	tests, err := s.fetchTests()
+	if err != nil {
+		helper.LogError(s.l, helper.ErrorDatastoreRetrievalFailed, err, span)
+		return
+	}
+
+	concurrency := s.cfg.ConnectionTest.Concurrency
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+
+	for _, test := range tests {
+		if ctx.Err() != nil {
+			break
+		}
+
+		sem <- struct{}{}
+		wg.Add(1)
+
+		go func(test connectionTest) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			requestid := uuid.New().String()
+			cl := s.l.With(slog.String("requestid", requestid))
+
+			test(requestid, cl, ctx)
+		}(test)
+	}
+
+	wg.Wait()
+}
@@ -0,0 +1,551 @@
+package handlers
+
+import (
+	"DemoServer_ConnectionManager/auth"
+	"DemoServer_ConnectionManager/configuration"
+	"DemoServer_ConnectionManager/data"
+	"DemoServer_ConnectionManager/datalayer"
+	"DemoServer_ConnectionManager/helper"
+	"DemoServer_ConnectionManager/secretsmanager"
+	"DemoServer_ConnectionManager/utilities"
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+type KeyDatabaseConnectionRecord struct{}
+type KeyDatabaseConnectionPatchParamsRecord struct{}
+
+type DatabaseConnectionHandler struct {
+	connectionHandler[data.DatabaseConnection, *data.DatabaseConnection]
+}
+
+func NewDatabaseConnectionHandler(cfg *configuration.Config, l *slog.Logger, pd *datalayer.PostgresDataSource, sb secretsmanager.SecretsBackend, pe *auth.PolicyEngine) (*DatabaseConnectionHandler, error) {
+	var c DatabaseConnectionHandler
+
+	c.connectionHandler = newConnectionHandler[data.DatabaseConnection, *data.DatabaseConnection](cfg, l, pd, sb, pe, connectionEngine[data.DatabaseConnection]{
+		table:   "database_connections",
+		load:    sb.GetDatabaseSecretsEngine,
+		mount:   sb.AddDatabaseSecretsEngine,
+		update:  sb.UpdateDatabaseSecretsEngine,
+		unmount: sb.RemoveDatabaseSecretsEngine,
+		test: func(c *data.DatabaseConnection, roleName string, ctx context.Context) error {
+			return sb.TestDatabaseSecretsEngine(c.VaultPath, c.VaultNamespace, roleName, ctx)
+		},
+		mountFailed: helper.ErrorVaultSecretsEngineFailed,
+		validate:    validateDatabaseConnection,
+	})
+
+	return &c, nil
+}
+
+func (h *DatabaseConnectionHandler) GetDatabaseConnections(w http.ResponseWriter, r *http.Request) {
+
+	// swagger:operation GET /connections/database DatabaseConnection GetDatabaseConnections
+	// List Database Connections
+	//
+	// Endpoint: GET - /v1/connectionmgmt/connections/database
+	//
+	// Description: Returns list of DatabaseConnection resources. Each DatabaseConnection resource
+	// contains underlying generic Connection resource as well as DatabaseConnection
+	// specific attributes. Client secret of connection is never returned.
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: limit
+	//   in: query
+	//   description: maximum number of results to return.
+	//   required: false
+	//   type: integer
+	//   format: int32
+	// - name: skip
+	//   in: query
+	//   description: number of results to be skipped from beginning of list
+	//   required: false
+	//   type: integer
+	//   format: int32
+	// responses:
+	//   '200':
+	//     description: List of DatabaseConnection resources
+	//     schema:
+	//       type: array
+	//       items:
+	//         "$ref": "#/definitions/DatabaseConnection"
+	//   '400':
+	//     description: Issues with parameters or their value
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '500':
+	//     description: Internal server error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   default:
+	//     description: unexpected error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	ctx, span, requestid, cl := utilities.SetupTraceAndLogger(r, w, h.l, utilities.GetFunctionName(), h.cfg.Server.PrefixMain)
+	defer span.End()
+
+	connections, limit, skip, err := h.listConnections(ctx, cl, requestid, r, &w, span)
+	if err != nil {
+		return
+	}
+
+	wrapped, err := connectionResponses[data.DatabaseConnectionResponseWrapper](connections, cl, requestid, r, &w, span)
+	if err != nil {
+		return
+	}
+
+	response := data.DatabaseConnectionsResponse{
+		Total:               len(connections),
+		Skip:                skip,
+		Limit:               limit,
+		DatabaseConnections: wrapped,
+	}
+
+	utilities.WriteResponse(w, cl, response, span)
+}
+
+// GetDatabaseConnection returns DatabaseConnection resource based on connectionid parameter
+func (h *DatabaseConnectionHandler) GetDatabaseConnection(w http.ResponseWriter, r *http.Request) {
+
+	// swagger:operation GET /connection/database DatabaseConnection GetDatabaseConnection
+	// Retrieve Database Connection
+	//
+	// Endpoint: GET - /v1/connectionmgmt/connection/database/{connectionid}
+	//
+	// Description: Returns DatabaseConnection resource based on connectionid.
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: connectionid
+	//   in: query
+	//   description: id for DatabaseConnection resource to be retrieved. expected to be in uuid format i.e. XXXXXXXX-XXXX-XXXX-XXXX-XXXXXXXXXXXX
+	//   required: true
+	//   type: string
+	// responses:
+	//   '200':
+	//     description: DatabaseConnection resource
+	//     schema:
+	//         "$ref": "#/definitions/DatabaseConnection"
+	//   '400':
+	//     description: Issues with parameters or their value
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '403':
+	//     description: Caller lacks viewer role on connection
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '404':
+	//     description: Resource not found. Resources are filtered based on connectiontype = DatabaseConnectionType. If connectionid of Non-DatabaseConnection is provided ResourceNotFound error is returned.
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '500':
+	//     description: Internal server error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   default:
+	//     description: unexpected error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	ctx, span, requestID, cl := utilities.SetupTraceAndLogger(r, w, h.l, utilities.GetFunctionName(), h.cfg.Server.PrefixMain)
+	defer span.End()
+
+	connection, applications, err := h.viewConnection(ctx, cl, requestID, r, &w, span)
+	if err != nil {
+		return
+	}
+
+	response, err := connectionResponse[data.DatabaseConnectionResponseWrapper](connection, cl, requestID, r, &w, span)
+	if err != nil {
+		return
+	}
+	response.Connection.Applications = applications
+
+	utilities.WriteResponse(w, cl, response, span)
+}
+
+func (h *DatabaseConnectionHandler) GenerateCredsDatabaseConnection(w http.ResponseWriter, r *http.Request) {
+
+	// swagger:operation GET /connection/database/creds DatabaseConnection GenerateCredsDatabaseConnection
+	// Generate Database Creds
+	//
+	// Endpoint: GET - /v1/connectionmgmt/connection/database/{connectionid}/creds
+	//
+	// Description: Generate dynamic database user credentials using specified DatabaseConnection. Connection has
+	// to be tested successfully before it can be used for generating credentials. Credentials are only issued to
+	// application identified by applicationid which has to match identity of caller and be linked to connection.
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: connectionid
+	//   in: query
+	//   description: id for DatabaseConnection resource to be used for dynamic credentials generation. expected to be in uuid format i.e. XXXXXXXX-XXXX-XXXX-XXXX-XXXXXXXXXXXX
+	//   required: true
+	//   type: string
+	// - name: applicationid
+	//   in: query
+	//   description: id of application for which credentials are generated. has to identify caller and be linked to connection. recorded with lease of credentials.
+	//   required: true
+	//   type: string
+	// - name: X-Requester
+	//   in: header
+	//   description: identity of caller requesting credentials. recorded with lease of credentials. ignored in favour of subject of bearer token when authentication is enabled.
+	//   required: false
+	//   type: string
+	// responses:
+	//   '200':
+	//     description: Credentials generated successfully
+	//     schema:
+	//         "$ref": "#/definitions/CredsDatabaseConnectionResponse"
+	//   '400':
+	//     description: Issues with parameters or their value
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '404':
+	//     description: Resource not found. Resources are filtered based on connectiontype = DatabaseConnectionType. If connectionid of Non-DatabaseConnection is provided ResourceNotFound error is returned.
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '403':
+	//     description: Caller lacks credential-consumer role on connection, applicationid does not identify caller, application is not linked to connection or link does not allow role
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '409':
+	//     description: Connection has not been tested successfully yet
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '500':
+	//     description: Internal server error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   default:
+	//     description: unexpected error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	ctx, span, requestID, cl := utilities.SetupTraceAndLogger(r, w, h.l, utilities.GetFunctionName(), h.cfg.Server.PrefixMain)
+	defer span.End()
+
+	grant, err := h.grantCreds(ctx, cl, requestID, r, &w, span)
+	if err != nil {
+		return
+	}
+
+	connection := grant.connection
+
+	response, err := h.sb.GenerateCredsDatabaseSecretsEngine(connection.VaultPath, connection.VaultNamespace, grant.roleName, ctx)
+	if err != nil {
+		h.failCreds(grant, err, ctx, cl, requestID, r, &w, span)
+		return
+	}
+
+	response.ConnectionID = connection.ID.String()
+
+	lease := newCredsLease(connection.ConnectionID, grant.applicationID, requestID, requester(r), issuedLease{
+		LeaseID:       response.LeaseID,
+		LeaseDuration: response.LeaseDuration,
+		Renewable:     response.Renewable,
+		RoleName:      response.RoleName,
+	})
+
+	if err := h.recordCreds(grant, lease, fmt.Sprintf("credentials issued for role %s", response.RoleName), ctx, cl, requestID, r, &w, span); err != nil {
+		return
+	}
+
+	utilities.WriteResponse(w, cl, response, span)
+}
+
+func (h *DatabaseConnectionHandler) TestDatabaseConnection(w http.ResponseWriter, r *http.Request) {
+
+	// swagger:operation GET /connection/database/test DatabaseConnection TestDatabaseConnection
+	// Test Database Connection
+	//
+	// Endpoint: GET - /v1/connectionmgmt/connection/database/{connectionid}/test
+	//
+	// Description: Test connectivity of specified DatabaseConnection resource. Credentials are generated through role
+	// of connection and revoked right away.
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: connectionid
+	//   in: query
+	//   description: id for DatabaseConnection resource to be tested. expected to be in uuid format i.e. XXXXXXXX-XXXX-XXXX-XXXX-XXXXXXXXXXXX
+	//   required: true
+	//   type: string
+	// responses:
+	//   '200':
+	//     description: Connectivity test status
+	//     schema:
+	//         "$ref": "#/definitions/TestDatabaseConnectionResponse"
+	//   '404':
+	//     description: Resource not found. Resources are filtered based on connectiontype = DatabaseConnectionType. If connectionid of Non-DatabaseConnection is provided ResourceNotFound error is returned.
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '403':
+	//     description: Caller lacks operator role on connection
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '500':
+	//     description: Internal server error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   default:
+	//     description: unexpected error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	ctx, span, requestID, cl := utilities.SetupTraceAndLogger(r, w, h.l, utilities.GetFunctionName(), h.cfg.Server.PrefixMain)
+	defer span.End()
+
+	connection, roleName, result, err := h.runTest(ctx, cl, requestID, r, &w, span)
+	if err != nil {
+		return
+	}
+
+	var response data.TestDatabaseConnectionResponse
+	response.ID = connection.ID.String()
+	response.RoleName = roleName
+	response.TestStatus = result.TestError
+	response.TestStatusCode = result.TestSuccessful
+
+	utilities.WriteResponse(w, cl, response, span)
+}
+
+func (h *DatabaseConnectionHandler) UpdateDatabaseConnection(w http.ResponseWriter, r *http.Request) {
+
+	// swagger:operation PATCH /connection/database DatabaseConnection UpdateDatabaseConnection
+	// Update Database Connection
+	//
+	// Endpoint: PATCH - /v1/connectionmgmt/connection/database/{connectionid}
+	//
+	// Description: Update attributes of DatabaseConnection resource. Secrets engine is reconfigured in place, so
+	// credentials issued before update remain valid until their leases expire. Update operation resets Tested
+	// status of DatabaseConnection.
+	//
+	// ---
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: connectionid
+	//   in: query
+	//   description: id for DatabaseConnection resource to be updated. expected to be in uuid format i.e. XXXXXXXX-XXXX-XXXX-XXXX-XXXXXXXXXXXX
+	//   required: true
+	//   type: string
+	// - in: body
+	//   name: Body
+	//   description: JSON string defining DatabaseConnection resource. Change of connectiontype and ID attributes is not allowed.
+	//   required: true
+	//   schema:
+	//     "$ref": "#/definitions/DatabaseConnectionPatchWrapper"
+	// responses:
+	//   '200':
+	//     description: DatabaseConnection resource after updates.
+	//     schema:
+	//         "$ref": "#/definitions/DatabaseConnection"
+	//   '400':
+	//     description: Bad request or parameters
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '403':
+	//     description: Caller lacks admin role on connection
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   default:
+	//     description: unexpected error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	ctx, span, requestid, cl := utilities.SetupTraceAndLogger(r, w, h.l, utilities.GetFunctionName(), h.cfg.Server.PrefixMain)
+	defer span.End()
+
+	p := r.Context().Value(KeyDatabaseConnectionPatchParamsRecord{}).(data.DatabaseConnectionPatchWrapper)
+
+	// Attributes not part of request are kept as configured in Vault
+	connection, err := h.updateConnection(func(c *data.DatabaseConnection) error {
+		return applyConnectionPatch(c, &c.Connection, p, p.Connection)
+	}, ctx, cl, requestid, r, &w, span)
+	if err != nil {
+		return
+	}
+
+	response, err := connectionResponse[data.DatabaseConnectionResponseWrapper](connection, cl, requestid, r, &w, span)
+	if err != nil {
+		return
+	}
+
+	utilities.WriteResponse(w, cl, response, span)
+}
+
+// validateDatabaseConnection makes sure connection url references root user through templates. Vault returns
+// connection url in plain text when configuration is read, so embedded credentials would leak through Get.
+func validateDatabaseConnection(c *data.DatabaseConnection, cl *slog.Logger, requestid string, r *http.Request, w http.ResponseWriter, span trace.Span) error {
+	if !c.HasTemplatedCredentials() {
+		helper.ReturnError(cl, http.StatusBadRequest, helper.ErrorInvalidDatabaseConnectionURL, helper.ErrorDictionary[helper.ErrorInvalidDatabaseConnectionURL].Error(), requestid, r, &w, span)
+		return fmt.Errorf("invalid connection url")
+	}
+	return nil
+}
+
+// DeleteDatabaseConnection deletes a DatabaseConnection from datastore
+func (h *DatabaseConnectionHandler) DeleteDatabaseConnection(w http.ResponseWriter, r *http.Request) {
+
+	// swagger:operation DELETE /connection/database DatabaseConnection DeleteDatabaseConnection
+	// Delete Database Connection
+	//
+	// Endpoint: DELETE - /v1/connectionmgmt/connection/database/{connectionid}
+	//
+	// Description: Deletes DatabaseConnection resource based on connectionid and disables its secrets engine mount,
+	// which revokes credentials issued through connection. Deletion is refused while applications are linked to
+	// connection unless force is set, in which case leases of connection are revoked and applications are
+	// unlinked before connection is deleted.
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: connectionid
+	//   in: query
+	//   description: id for DatabaseConnection resource to be deleted. expected to be in uuid format i.e. XXXXXXXX-XXXX-XXXX-XXXX-XXXXXXXXXXXX
+	//   required: true
+	//   type: string
+	// - name: force
+	//   in: query
+	//   description: true to revoke leases and unlink applications before deletion. deletion of linked connection is refused otherwise.
+	//   required: false
+	//   type: boolean
+	// responses:
+	//   '200':
+	//     description: Resource successfully deleted.
+	//     schema:
+	//         "$ref": "#/definitions/DeleteDatabaseConnectionResponse"
+	//   '400':
+	//     description: Issues with parameters or their value
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '409':
+	//     description: Applications are linked to connection. Linked applications are listed in errorAdditionalInfo
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '404':
+	//     description: Resource not found.
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '403':
+	//     description: Caller lacks admin role on connection
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '500':
+	//     description: Internal server error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   default:
+	//     description: unexpected error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	ctx, span, requestid, cl := utilities.SetupTraceAndLogger(r, w, h.l, utilities.GetFunctionName(), h.cfg.Server.PrefixMain)
+	defer span.End()
+
+	if err := h.deleteConnection(ctx, cl, requestid, r, &w, span); err != nil {
+		return
+	}
+
+	var response data.DeleteDatabaseConnectionResponse
+	response.StatusCode = http.StatusOK
+	response.Status = http.StatusText(response.StatusCode)
+
+	utilities.WriteResponse(w, cl, response, span)
+}
+
+func (h *DatabaseConnectionHandler) AddDatabaseConnection(w http.ResponseWriter, r *http.Request) {
+
+	// swagger:operation POST /connection/database DatabaseConnection AddDatabaseConnection
+	// New Database Connection
+	//
+	// Endpoint: POST - /v1/connectionmgmt/connection/database
+	//
+	// Description: Create new DatabaseConnection resource. Database secrets engine of connection is mounted at vaultpath
+	// in vault_namespace if provided and configured with root user Vault manages database with. Otherwise path is
+	// generated from id of connection and namespace configured for service is used. Vault verifies it can connect
+	// to database before connection is created. Role of connection creates database users through
+	// creation_statements.
+	//
+	// ---
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - in: body
+	//   name: Body
+	//   description: JSON string defining DatabaseConnection resource
+	//   required: true
+	//   schema:
+	//     "$ref": "#/definitions/DatabaseConnectionPostWrapper"
+	// responses:
+	//   '200':
+	//     description: DatabaseConnection resource just created.
+	//     schema:
+	//         "$ref": "#/definitions/DatabaseConnection"
+	//   '400':
+	//     description: Bad request or parameters
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '409':
+	//     description: vaultpath is already used by another connection or overlaps with an existing secrets engine mount in vault_namespace
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '403':
+	//     description: Caller lacks admin role on all connections
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '500':
+	//     description: Internal server error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   default:
+	//     description: unexpected error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	ctx, span, requestid, cl := utilities.SetupTraceAndLogger(r, w, h.l, utilities.GetFunctionName(), h.cfg.Server.PrefixMain)
+	defer span.End()
+
+	p := r.Context().Value(KeyDatabaseConnectionRecord{}).(*data.DatabaseConnectionPostWrapper)
+
+	c := data.NewDatabaseConnection(h.cfg)
+
+	if err := h.addConnection(c, p, p.Connection, p.VaultPath, p.VaultNamespace, ctx, cl, requestid, r, &w, span); err != nil {
+		return
+	}
+
+	response, err := connectionResponse[data.DatabaseConnectionResponseWrapper](c, cl, requestid, r, &w, span)
+	if err != nil {
+		return
+	}
+
+	utilities.WriteResponse(w, cl, response, span)
+}
+
+func (h *DatabaseConnectionHandler) MiddlewareValidateDatabaseConnectionPost(next http.Handler) http.Handler {
+	return validateConnectionPost[data.DatabaseConnectionPostWrapper](h.l, h.cfg, KeyDatabaseConnectionRecord{}, next)
+}
+
+func (h *DatabaseConnectionHandler) MiddlewareValidateDatabaseConnectionUpdate(next http.Handler) http.Handler {
+	return validateConnectionPatch[data.DatabaseConnectionPatchWrapper](h.l, h.cfg, KeyDatabaseConnectionPatchParamsRecord{}, next)
+}
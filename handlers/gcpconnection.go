@@ -0,0 +1,565 @@
+package handlers
+
+import (
+	"DemoServer_ConnectionManager/auth"
+	"DemoServer_ConnectionManager/configuration"
+	"DemoServer_ConnectionManager/data"
+	"DemoServer_ConnectionManager/datalayer"
+	"DemoServer_ConnectionManager/helper"
+	"DemoServer_ConnectionManager/secretsmanager"
+	"DemoServer_ConnectionManager/utilities"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+type KeyGCPConnectionRecord struct{}
+type KeyGCPConnectionPatchParamsRecord struct{}
+
+type GCPConnectionHandler struct {
+	connectionHandler[data.GCPConnection, *data.GCPConnection]
+}
+
+func NewGCPConnectionHandler(cfg *configuration.Config, l *slog.Logger, pd *datalayer.PostgresDataSource, sb secretsmanager.SecretsBackend, pe *auth.PolicyEngine) (*GCPConnectionHandler, error) {
+	var c GCPConnectionHandler
+
+	c.connectionHandler = newConnectionHandler[data.GCPConnection, *data.GCPConnection](cfg, l, pd, sb, pe, connectionEngine[data.GCPConnection]{
+		table:   "gcp_connections",
+		load:    sb.GetGCPSecretsEngine,
+		mount:   sb.AddGCPSecretsEngine,
+		update:  sb.UpdateGCPSecretsEngine,
+		unmount: sb.RemoveGCPSecretsEngine,
+		test: func(c *data.GCPConnection, roleName string, ctx context.Context) error {
+			return sb.TestGCPSecretsEngine(c.VaultPath, c.VaultNamespace, c.RoleType, roleName, c.SecretType, ctx)
+		},
+		mountFailed: helper.ErrorVaultSecretsEngineFailed,
+		validate:    validateGCPConnection,
+	})
+
+	return &c, nil
+}
+
+func (h *GCPConnectionHandler) GetGCPConnections(w http.ResponseWriter, r *http.Request) {
+
+	// swagger:operation GET /connections/gcp GCPConnection GetGCPConnections
+	// List GCP Connections
+	//
+	// Endpoint: GET - /v1/connectionmgmt/connections/gcp
+	//
+	// Description: Returns list of GCPConnection resources. Each GCPConnection resource
+	// contains underlying generic Connection resource as well as GCPConnection
+	// specific attributes. Service account key of connection is never returned.
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: limit
+	//   in: query
+	//   description: maximum number of results to return.
+	//   required: false
+	//   type: integer
+	//   format: int32
+	// - name: skip
+	//   in: query
+	//   description: number of results to be skipped from beginning of list
+	//   required: false
+	//   type: integer
+	//   format: int32
+	// responses:
+	//   '200':
+	//     description: List of GCPConnection resources
+	//     schema:
+	//       type: array
+	//       items:
+	//         "$ref": "#/definitions/GCPConnection"
+	//   '400':
+	//     description: Issues with parameters or their value
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '500':
+	//     description: Internal server error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   default:
+	//     description: unexpected error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	ctx, span, requestid, cl := utilities.SetupTraceAndLogger(r, w, h.l, utilities.GetFunctionName(), h.cfg.Server.PrefixMain)
+	defer span.End()
+
+	connections, limit, skip, err := h.listConnections(ctx, cl, requestid, r, &w, span)
+	if err != nil {
+		return
+	}
+
+	wrapped, err := connectionResponses[data.GCPConnectionResponseWrapper](connections, cl, requestid, r, &w, span)
+	if err != nil {
+		return
+	}
+
+	response := data.GCPConnectionsResponse{
+		Total:          len(connections),
+		Skip:           skip,
+		Limit:          limit,
+		GCPConnections: wrapped,
+	}
+
+	utilities.WriteResponse(w, cl, response, span)
+}
+
+// GetGCPConnection returns GCPConnection resource based on connectionid parameter
+func (h *GCPConnectionHandler) GetGCPConnection(w http.ResponseWriter, r *http.Request) {
+
+	// swagger:operation GET /connection/gcp GCPConnection GetGCPConnection
+	// Retrieve GCP Connection
+	//
+	// Endpoint: GET - /v1/connectionmgmt/connection/gcp/{connectionid}
+	//
+	// Description: Returns GCPConnection resource based on connectionid.
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: connectionid
+	//   in: query
+	//   description: id for GCPConnection resource to be retrieved. expected to be in uuid format i.e. XXXXXXXX-XXXX-XXXX-XXXX-XXXXXXXXXXXX
+	//   required: true
+	//   type: string
+	// responses:
+	//   '200':
+	//     description: GCPConnection resource
+	//     schema:
+	//         "$ref": "#/definitions/GCPConnection"
+	//   '400':
+	//     description: Issues with parameters or their value
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '403':
+	//     description: Caller lacks viewer role on connection
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '404':
+	//     description: Resource not found. Resources are filtered based on connectiontype = GCPConnectionType. If connectionid of Non-GCPConnection is provided ResourceNotFound error is returned.
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '500':
+	//     description: Internal server error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   default:
+	//     description: unexpected error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	ctx, span, requestID, cl := utilities.SetupTraceAndLogger(r, w, h.l, utilities.GetFunctionName(), h.cfg.Server.PrefixMain)
+	defer span.End()
+
+	connection, applications, err := h.viewConnection(ctx, cl, requestID, r, &w, span)
+	if err != nil {
+		return
+	}
+
+	response, err := connectionResponse[data.GCPConnectionResponseWrapper](connection, cl, requestID, r, &w, span)
+	if err != nil {
+		return
+	}
+	response.Connection.Applications = applications
+
+	utilities.WriteResponse(w, cl, response, span)
+}
+
+func (h *GCPConnectionHandler) GenerateCredsGCPConnection(w http.ResponseWriter, r *http.Request) {
+
+	// swagger:operation GET /connection/gcp/creds GCPConnection GenerateCredsGCPConnection
+	// Generate GCP Creds
+	//
+	// Endpoint: GET - /v1/connectionmgmt/connection/gcp/{connectionid}/creds
+	//
+	// Description: Generate OAuth2 access token or service account key using specified GCPConnection, depending
+	// on secret_type of its role. Connection has to be tested successfully before it can be used for generating
+	// credentials. Only service account keys are leased. Access tokens expire on their own and can not be revoked
+	// through connection. Credentials are only issued to application identified by applicationid which has to
+	// match identity of caller and be linked to connection.
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: connectionid
+	//   in: query
+	//   description: id for GCPConnection resource to be used for dynamic credentials generation. expected to be in uuid format i.e. XXXXXXXX-XXXX-XXXX-XXXX-XXXXXXXXXXXX
+	//   required: true
+	//   type: string
+	// - name: applicationid
+	//   in: query
+	//   description: id of application for which credentials are generated. has to identify caller and be linked to connection. recorded with lease of credentials.
+	//   required: true
+	//   type: string
+	// - name: X-Requester
+	//   in: header
+	//   description: identity of caller requesting credentials. recorded with lease of credentials. ignored in favour of subject of bearer token when authentication is enabled.
+	//   required: false
+	//   type: string
+	// responses:
+	//   '200':
+	//     description: Credentials generated successfully
+	//     schema:
+	//         "$ref": "#/definitions/CredsGCPConnectionResponse"
+	//   '400':
+	//     description: Issues with parameters or their value
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '404':
+	//     description: Resource not found. Resources are filtered based on connectiontype = GCPConnectionType. If connectionid of Non-GCPConnection is provided ResourceNotFound error is returned.
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '403':
+	//     description: Caller lacks credential-consumer role on connection, applicationid does not identify caller, application is not linked to connection or link does not allow role
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '409':
+	//     description: Connection has not been tested successfully yet
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '500':
+	//     description: Internal server error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   default:
+	//     description: unexpected error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	ctx, span, requestID, cl := utilities.SetupTraceAndLogger(r, w, h.l, utilities.GetFunctionName(), h.cfg.Server.PrefixMain)
+	defer span.End()
+
+	grant, err := h.grantCreds(ctx, cl, requestID, r, &w, span)
+	if err != nil {
+		return
+	}
+
+	connection := grant.connection
+
+	response, err := h.sb.GenerateCredsGCPSecretsEngine(connection.VaultPath, connection.VaultNamespace, connection.RoleType, grant.roleName, connection.SecretType, ctx)
+	if err != nil {
+		h.failCreds(grant, err, ctx, cl, requestID, r, &w, span)
+		return
+	}
+
+	response.ConnectionID = connection.ID.String()
+
+	// Access tokens are not leased by Vault, so no lease is tracked for them and only issuance is recorded
+	lease := newCredsLease(connection.ConnectionID, grant.applicationID, requestID, requester(r), issuedLease{
+		LeaseID:       response.LeaseID,
+		LeaseDuration: response.LeaseDuration,
+		Renewable:     response.Renewable,
+		RoleName:      response.RoleName,
+	})
+
+	if err := h.recordCreds(grant, lease, fmt.Sprintf("%s issued for role %s", response.SecretType, response.RoleName), ctx, cl, requestID, r, &w, span); err != nil {
+		return
+	}
+
+	utilities.WriteResponse(w, cl, response, span)
+}
+
+func (h *GCPConnectionHandler) TestGCPConnection(w http.ResponseWriter, r *http.Request) {
+
+	// swagger:operation GET /connection/gcp/test GCPConnection TestGCPConnection
+	// Test GCP Connection
+	//
+	// Endpoint: GET - /v1/connectionmgmt/connection/gcp/{connectionid}/test
+	//
+	// Description: Test connectivity of specified GCPConnection resource. Credentials are generated through role
+	// of connection. Service account keys are revoked right away.
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: connectionid
+	//   in: query
+	//   description: id for GCPConnection resource to be tested. expected to be in uuid format i.e. XXXXXXXX-XXXX-XXXX-XXXX-XXXXXXXXXXXX
+	//   required: true
+	//   type: string
+	// responses:
+	//   '200':
+	//     description: Connectivity test status
+	//     schema:
+	//         "$ref": "#/definitions/TestGCPConnectionResponse"
+	//   '404':
+	//     description: Resource not found. Resources are filtered based on connectiontype = GCPConnectionType. If connectionid of Non-GCPConnection is provided ResourceNotFound error is returned.
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '403':
+	//     description: Caller lacks operator role on connection
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '500':
+	//     description: Internal server error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   default:
+	//     description: unexpected error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	ctx, span, requestID, cl := utilities.SetupTraceAndLogger(r, w, h.l, utilities.GetFunctionName(), h.cfg.Server.PrefixMain)
+	defer span.End()
+
+	connection, roleName, result, err := h.runTest(ctx, cl, requestID, r, &w, span)
+	if err != nil {
+		return
+	}
+
+	var response data.TestGCPConnectionResponse
+	response.ID = connection.ID.String()
+	response.RoleName = roleName
+	response.TestStatus = result.TestError
+	response.TestStatusCode = result.TestSuccessful
+
+	utilities.WriteResponse(w, cl, response, span)
+}
+
+func (h *GCPConnectionHandler) UpdateGCPConnection(w http.ResponseWriter, r *http.Request) {
+
+	// swagger:operation PATCH /connection/gcp GCPConnection UpdateGCPConnection
+	// Update GCP Connection
+	//
+	// Endpoint: PATCH - /v1/connectionmgmt/connection/gcp/{connectionid}
+	//
+	// Description: Update attributes of GCPConnection resource. Secrets engine is reconfigured in place. Vault
+	// replaces service account of roleset if its bindings change, which invalidates keys issued before update.
+	// Update operation resets Tested status of GCPConnection.
+	//
+	// ---
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: connectionid
+	//   in: query
+	//   description: id for GCPConnection resource to be updated. expected to be in uuid format i.e. XXXXXXXX-XXXX-XXXX-XXXX-XXXXXXXXXXXX
+	//   required: true
+	//   type: string
+	// - in: body
+	//   name: Body
+	//   description: JSON string defining GCPConnection resource. Change of connectiontype and ID attributes is not allowed.
+	//   required: true
+	//   schema:
+	//     "$ref": "#/definitions/GCPConnectionPatchWrapper"
+	// responses:
+	//   '200':
+	//     description: GCPConnection resource after updates.
+	//     schema:
+	//         "$ref": "#/definitions/GCPConnection"
+	//   '400':
+	//     description: Bad request or parameters
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '403':
+	//     description: Caller lacks admin role on connection
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   default:
+	//     description: unexpected error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	ctx, span, requestid, cl := utilities.SetupTraceAndLogger(r, w, h.l, utilities.GetFunctionName(), h.cfg.Server.PrefixMain)
+	defer span.End()
+
+	p := r.Context().Value(KeyGCPConnectionPatchParamsRecord{}).(data.GCPConnectionPatchWrapper)
+
+	// Attributes not part of request are kept as configured in Vault
+	connection, err := h.updateConnection(func(c *data.GCPConnection) error {
+		return applyConnectionPatch(c, &c.Connection, p, p.Connection)
+	}, ctx, cl, requestid, r, &w, span)
+	if err != nil {
+		return
+	}
+
+	response, err := connectionResponse[data.GCPConnectionResponseWrapper](connection, cl, requestid, r, &w, span)
+	if err != nil {
+		return
+	}
+
+	utilities.WriteResponse(w, cl, response, span)
+}
+
+// validateGCPConnection makes sure credentials, if provided, are key of a service account and role of connection
+// carries attributes required by its role type and secret type.
+func validateGCPConnection(c *data.GCPConnection, cl *slog.Logger, requestid string, r *http.Request, w http.ResponseWriter, span trace.Span) error {
+	if c.Credentials != "" {
+		var key struct {
+			Type string `json:"type"`
+		}
+		if err := json.Unmarshal([]byte(c.Credentials), &key); err != nil || key.Type != "service_account" {
+			helper.ReturnError(cl, http.StatusBadRequest, helper.ErrorInvalidGCPCredentials, helper.ErrorDictionary[helper.ErrorInvalidGCPCredentials].Error(), requestid, r, &w, span)
+			return fmt.Errorf("invalid gcp credentials")
+		}
+	}
+
+	if !c.HasRole() {
+		helper.ReturnError(cl, http.StatusBadRequest, helper.ErrorInvalidGCPRole, helper.ErrorDictionary[helper.ErrorInvalidGCPRole].Error(), requestid, r, &w, span)
+		return fmt.Errorf("invalid gcp role")
+	}
+	return nil
+}
+
+// DeleteGCPConnection deletes a GCPConnection from datastore
+func (h *GCPConnectionHandler) DeleteGCPConnection(w http.ResponseWriter, r *http.Request) {
+
+	// swagger:operation DELETE /connection/gcp GCPConnection DeleteGCPConnection
+	// Delete GCP Connection
+	//
+	// Endpoint: DELETE - /v1/connectionmgmt/connection/gcp/{connectionid}
+	//
+	// Description: Deletes GCPConnection resource based on connectionid and disables its secrets engine mount,
+	// which revokes service account keys issued through connection. Deletion is refused while applications are linked to
+	// connection unless force is set, in which case leases of connection are revoked and applications are
+	// unlinked before connection is deleted.
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: connectionid
+	//   in: query
+	//   description: id for GCPConnection resource to be deleted. expected to be in uuid format i.e. XXXXXXXX-XXXX-XXXX-XXXX-XXXXXXXXXXXX
+	//   required: true
+	//   type: string
+	// - name: force
+	//   in: query
+	//   description: true to revoke leases and unlink applications before deletion. deletion of linked connection is refused otherwise.
+	//   required: false
+	//   type: boolean
+	// responses:
+	//   '200':
+	//     description: Resource successfully deleted.
+	//     schema:
+	//         "$ref": "#/definitions/DeleteGCPConnectionResponse"
+	//   '400':
+	//     description: Issues with parameters or their value
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '409':
+	//     description: Applications are linked to connection. Linked applications are listed in errorAdditionalInfo
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '404':
+	//     description: Resource not found.
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '403':
+	//     description: Caller lacks admin role on connection
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '500':
+	//     description: Internal server error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   default:
+	//     description: unexpected error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	ctx, span, requestid, cl := utilities.SetupTraceAndLogger(r, w, h.l, utilities.GetFunctionName(), h.cfg.Server.PrefixMain)
+	defer span.End()
+
+	if err := h.deleteConnection(ctx, cl, requestid, r, &w, span); err != nil {
+		return
+	}
+
+	var response data.DeleteGCPConnectionResponse
+	response.StatusCode = http.StatusOK
+	response.Status = http.StatusText(response.StatusCode)
+
+	utilities.WriteResponse(w, cl, response, span)
+}
+
+func (h *GCPConnectionHandler) AddGCPConnection(w http.ResponseWriter, r *http.Request) {
+
+	// swagger:operation POST /connection/gcp GCPConnection AddGCPConnection
+	// New GCP Connection
+	//
+	// Endpoint: POST - /v1/connectionmgmt/connection/gcp
+	//
+	// Description: Create new GCPConnection resource. GCP secrets engine of connection is mounted at vaultpath
+	// in vault_namespace if provided and configured with key of service account Vault manages GCP with. Otherwise
+	// path is generated from id of connection and namespace configured for service is used. Role of connection is
+	// either a roleset, for which Vault creates a service account bound to IAM roles, or a static account using an
+	// existing service account. Either of them generates OAuth2 access tokens or service account keys.
+	//
+	// ---
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - in: body
+	//   name: Body
+	//   description: JSON string defining GCPConnection resource
+	//   required: true
+	//   schema:
+	//     "$ref": "#/definitions/GCPConnectionPostWrapper"
+	// responses:
+	//   '200':
+	//     description: GCPConnection resource just created.
+	//     schema:
+	//         "$ref": "#/definitions/GCPConnection"
+	//   '400':
+	//     description: Bad request or parameters
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '409':
+	//     description: vaultpath is already used by another connection or overlaps with an existing secrets engine mount in vault_namespace
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '403':
+	//     description: Caller lacks admin role on all connections
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '500':
+	//     description: Internal server error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   default:
+	//     description: unexpected error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	ctx, span, requestid, cl := utilities.SetupTraceAndLogger(r, w, h.l, utilities.GetFunctionName(), h.cfg.Server.PrefixMain)
+	defer span.End()
+
+	p := r.Context().Value(KeyGCPConnectionRecord{}).(*data.GCPConnectionPostWrapper)
+
+	c := data.NewGCPConnection(h.cfg)
+
+	if err := h.addConnection(c, p, p.Connection, p.VaultPath, p.VaultNamespace, ctx, cl, requestid, r, &w, span); err != nil {
+		return
+	}
+
+	response, err := connectionResponse[data.GCPConnectionResponseWrapper](c, cl, requestid, r, &w, span)
+	if err != nil {
+		return
+	}
+
+	utilities.WriteResponse(w, cl, response, span)
+}
+
+func (h *GCPConnectionHandler) MiddlewareValidateGCPConnectionPost(next http.Handler) http.Handler {
+	return validateConnectionPost[data.GCPConnectionPostWrapper](h.l, h.cfg, KeyGCPConnectionRecord{}, next)
+}
+
+func (h *GCPConnectionHandler) MiddlewareValidateGCPConnectionUpdate(next http.Handler) http.Handler {
+	return validateConnectionPatch[data.GCPConnectionPatchWrapper](h.l, h.cfg, KeyGCPConnectionPatchParamsRecord{}, next)
+}
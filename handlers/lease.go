@@ -0,0 +1,613 @@
+package handlers
+
+import (
+	"DemoServer_ConnectionManager/auth"
+	"DemoServer_ConnectionManager/configuration"
+	"DemoServer_ConnectionManager/data"
+	"DemoServer_ConnectionManager/datalayer"
+	"DemoServer_ConnectionManager/helper"
+	"DemoServer_ConnectionManager/secretsmanager"
+	"DemoServer_ConnectionManager/utilities"
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/otel"
+)
+
+type LeaseHandler struct {
+	l          *slog.Logger
+	cfg        *configuration.Config
+	pd         *datalayer.PostgresDataSource
+	sb         secretsmanager.SecretsBackend
+	pe         *auth.PolicyEngine
+	list_limit int
+}
+
+func NewLeaseHandler(cfg *configuration.Config, l *slog.Logger, pd *datalayer.PostgresDataSource, sb secretsmanager.SecretsBackend, pe *auth.PolicyEngine) (*LeaseHandler, error) {
+	var c LeaseHandler
+
+	c.cfg = cfg
+	c.l = l
+	c.pd = pd
+	c.list_limit = cfg.Server.ListLimit
+	c.sb = sb
+	c.pe = pe
+
+	return &c, nil
+}
+
+// recordLease persists lease of freshly generated credentials along with audit record of their issuance. If
+// lease can not be persisted, credentials are revoked in Vault so that no untracked credentials remain valid.
+func recordLease(pd *datalayer.PostgresDataSource, sb secretsmanager.SecretsBackend, lease *data.Lease, namespace string, audit *data.AuditRecord, ctx context.Context, tracerName string) error {
+
+	tr := otel.Tracer(tracerName)
+	ctx, span := tr.Start(ctx, utilities.GetFunctionName())
+	defer span.End()
+
+	if err := createLease(pd, lease, audit, ctx, tracerName); err != nil {
+		if revokeErr := sb.RevokeLease(lease.LeaseID, namespace, ctx); revokeErr != nil {
+			return fmt.Errorf("%w. revocation of untracked lease failed: %s", err, revokeErr.Error())
+		}
+		return err
+	}
+
+	return nil
+}
+
+func createLease(pd *datalayer.PostgresDataSource, lease *data.Lease, audit *data.AuditRecord, ctx context.Context, tracerName string) error {
+
+	// Begin a transaction
+	tx := pd.RWDB().Begin()
+
+	// Check if the transaction started successfully
+	if tx.Error != nil {
+		return tx.Error
+	}
+
+	if err := utilities.CreateObjectWithoutTx(tx, lease, ctx, tracerName); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := utilities.CreateObjectWithoutTx(tx, audit, ctx, tracerName); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// Commit the transaction
+	if err := tx.Commit().Error; err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// getMountedConnection returns secrets engine mount of connection of any type identified by ID of its generic
+// Connection resource.
+func getMountedConnection(pd *datalayer.PostgresDataSource, connectionID string) (*mountedConnection, int, helper.ErrorTypeEnum, error) {
+	for _, ce := range connectionEngines {
+		var connections []mountedConnection
+
+		result := pd.RODB().Model(ce.model).Select("connection_id", "vault_path", "vault_namespace").Where("connection_id = ?", connectionID).Limit(1).Scan(&connections)
+		if result.Error != nil {
+			return nil, http.StatusInternalServerError, helper.ErrorDatastoreRetrievalFailed, result.Error
+		}
+
+		if len(connections) > 0 {
+			connections[0].engine = ce.engine
+			return &connections[0], http.StatusOK, helper.ErrorNone, nil
+		}
+	}
+
+	return nil, http.StatusNotFound, helper.ErrorResourceNotFound, fmt.Errorf("%s", helper.ErrorDictionary[helper.ErrorResourceNotFound].Error())
+}
+
+func (h *LeaseHandler) getLease(connectionID uuid.UUID, leaseID string) (*data.Lease, int, helper.ErrorTypeEnum, error) {
+	var lease data.Lease
+
+	result := h.pd.RODB().First(&lease, "id = ? AND connection_id = ?", leaseID, connectionID)
+
+	if result.Error != nil {
+		return nil, http.StatusInternalServerError, helper.ErrorDatastoreRetrievalFailed, result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return nil, http.StatusNotFound, helper.ErrorResourceNotFound, fmt.Errorf("%s", helper.ErrorDictionary[helper.ErrorResourceNotFound].Error())
+	}
+
+	return &lease, http.StatusOK, helper.ErrorNone, nil
+}
+
+func (h *LeaseHandler) fetchActiveLeases(connectionID uuid.UUID, limit, skip int) ([]data.Lease, error) {
+	var leases []data.Lease
+
+	result := h.pd.RODB().
+		Where("connection_id = ? AND revoked = ? AND expires_at > ?", connectionID, false, time.Now().UTC()).
+		Limit(limit).
+		Offset(skip).
+		Order("expires_at").
+		Find(&leases)
+
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return leases, nil
+}
+
+func (h *LeaseHandler) GetConnectionLeases(w http.ResponseWriter, r *http.Request) {
+
+	// swagger:operation GET /connection/leases Lease GetConnectionLeases
+	// List active leases of Connection
+	//
+	// Endpoint: GET - /v1/connectionmgmt/connection/{connectionid}/leases
+	//
+	// Description: Returns list of active leases for credentials generated through specified connection of any type.
+	// Revoked and expired leases are not returned.
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: connectionid
+	//   in: query
+	//   description: id for generic Connection resource. expected to be in uuid format i.e. XXXXXXXX-XXXX-XXXX-XXXX-XXXXXXXXXXXX
+	//   required: true
+	//   type: string
+	// - name: limit
+	//   in: query
+	//   description: maximum number of results to return.
+	//   required: false
+	//   type: integer
+	//   format: int32
+	// - name: skip
+	//   in: query
+	//   description: number of results to be skipped from beginning of list
+	//   required: false
+	//   type: integer
+	//   format: int32
+	// responses:
+	//   '200':
+	//     description: List of active Lease resources
+	//     schema:
+	//         "$ref": "#/definitions/LeasesResponse"
+	//   '404':
+	//     description: Resource not found.
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '403':
+	//     description: Caller lacks viewer role on connection
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '500':
+	//     description: Internal server error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   default:
+	//     description: unexpected error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	ctx, span, requestid, cl := utilities.SetupTraceAndLogger(r, w, h.l, utilities.GetFunctionName(), h.cfg.Server.PrefixMain)
+	defer span.End()
+
+	vars := r.URL.Query()
+	limit := utilities.ParseQueryParam(vars, "limit", h.list_limit, h.cfg.DataLayer.MaxResults)
+	skip := utilities.ParseQueryParam(vars, "skip", 0, math.MaxInt32)
+
+	connection, httpStatusCode, helpError, err := getMountedConnection(h.pd, mux.Vars(r)["connectionid"])
+	if err != nil {
+		helper.ReturnError(cl, httpStatusCode, helpError, err, requestid, r, &w, span)
+		return
+	}
+
+	if err := authorize(h.pe, auth.PermissionView, connection.ConnectionID, ctx, cl, requestid, r, &w, span); err != nil {
+		return
+	}
+
+	leases, err := h.fetchActiveLeases(connection.ConnectionID, limit, skip)
+	if err != nil {
+		helper.ReturnError(cl, http.StatusInternalServerError, helper.ErrorDatastoreRetrievalFailed, err, requestid, r, &w, span)
+		return
+	}
+
+	response := data.LeasesResponse{
+		Total:  len(leases),
+		Skip:   skip,
+		Limit:  limit,
+		Leases: leases,
+	}
+
+	if response.Leases == nil {
+		response.Leases = []data.Lease{}
+	}
+
+	utilities.WriteResponse(w, cl, response, span)
+}
+
+func (h *LeaseHandler) RenewConnectionLease(w http.ResponseWriter, r *http.Request) {
+
+	// swagger:operation POST /connection/leases/{leaseid}/renew Lease RenewConnectionLease
+	// Renew lease of Connection
+	//
+	// Endpoint: POST - /v1/connectionmgmt/connection/{connectionid}/leases/{leaseid}/renew
+	//
+	// Description: Renew lease through Vault sys/leases/renew and record new expiry.
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: connectionid
+	//   in: query
+	//   description: id for generic Connection resource. expected to be in uuid format i.e. XXXXXXXX-XXXX-XXXX-XXXX-XXXXXXXXXXXX
+	//   required: true
+	//   type: string
+	// - name: leaseid
+	//   in: query
+	//   description: id for Lease resource. expected to be in uuid format i.e. XXXXXXXX-XXXX-XXXX-XXXX-XXXXXXXXXXXX
+	//   required: true
+	//   type: string
+	// - name: increment
+	//   in: query
+	//   description: requested extension of lease either in seconds or duration format i.e. 900s, 15m, 1h.
+	//   required: false
+	//   type: string
+	// responses:
+	//   '200':
+	//     description: Lease resource after renewal.
+	//     schema:
+	//         "$ref": "#/definitions/Lease"
+	//   '400':
+	//     description: Lease is revoked or not renewable
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '404':
+	//     description: Resource not found.
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '403':
+	//     description: Caller lacks admin role on connection
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '500':
+	//     description: Internal server error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   default:
+	//     description: unexpected error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	ctx, span, requestid, cl := utilities.SetupTraceAndLogger(r, w, h.l, utilities.GetFunctionName(), h.cfg.Server.PrefixMain)
+	defer span.End()
+
+	vars := mux.Vars(r)
+
+	connection, httpStatusCode, helpError, err := getMountedConnection(h.pd, vars["connectionid"])
+	if err != nil {
+		helper.ReturnError(cl, httpStatusCode, helpError, err, requestid, r, &w, span)
+		return
+	}
+
+	if err := authorize(h.pe, auth.PermissionManageLeases, connection.ConnectionID, ctx, cl, requestid, r, &w, span); err != nil {
+		return
+	}
+
+	lease, httpStatusCode, helpError, err := h.getLease(connection.ConnectionID, vars["leaseid"])
+	if err != nil {
+		helper.ReturnError(cl, httpStatusCode, helpError, err, requestid, r, &w, span)
+		return
+	}
+
+	if lease.Revoked {
+		helper.ReturnError(cl, http.StatusBadRequest, helper.ErrorLeaseAlreadyRevoked, helper.ErrLeaseAlreadyRevoked, requestid, r, &w, span)
+		return
+	}
+
+	if !lease.Renewable {
+		helper.ReturnError(cl, http.StatusBadRequest, helper.ErrorLeaseNotRenewable, helper.ErrLeaseNotRenewable, requestid, r, &w, span)
+		return
+	}
+
+	audit := data.NewAuditRecord(requestid, connection.ConnectionID, data.RenewLease, requester(r))
+
+	renewal, err := h.sb.RenewLease(lease.LeaseID, connection.VaultNamespace, r.URL.Query().Get("increment"), ctx)
+	if err != nil {
+		recordAuditFailure(h.pd, audit, err, cl, ctx, h.cfg.Server.PrefixMain)
+		helper.ReturnError(cl, http.StatusInternalServerError, helper.ErrorVaultLeaseRenewFailed, err, requestid, r, &w, span)
+		return
+	}
+
+	lease.SetRenewed(renewal.LeaseDuration, renewal.Renewable)
+	audit.SetSuccessful(fmt.Sprintf("lease %s renewed for %d seconds", lease.ID.String(), lease.LeaseDuration))
+
+	if err := saveWithAudit(h.pd, lease, audit, ctx, h.cfg.Server.PrefixMain); err != nil {
+		helper.ReturnError(cl, http.StatusInternalServerError, helper.ErrorDatastoreSaveFailed, err, requestid, r, &w, span)
+		return
+	}
+
+	utilities.WriteResponse(w, cl, lease, span)
+}
+
+func (h *LeaseHandler) RevokeConnectionLease(w http.ResponseWriter, r *http.Request) {
+
+	// swagger:operation POST /connection/leases/{leaseid}/revoke Lease RevokeConnectionLease
+	// Revoke lease of Connection
+	//
+	// Endpoint: POST - /v1/connectionmgmt/connection/{connectionid}/leases/{leaseid}/revoke
+	//
+	// Description: Revoke lease through Vault sys/leases/revoke. Credentials of lease stop working immediately.
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: connectionid
+	//   in: query
+	//   description: id for generic Connection resource. expected to be in uuid format i.e. XXXXXXXX-XXXX-XXXX-XXXX-XXXXXXXXXXXX
+	//   required: true
+	//   type: string
+	// - name: leaseid
+	//   in: query
+	//   description: id for Lease resource. expected to be in uuid format i.e. XXXXXXXX-XXXX-XXXX-XXXX-XXXXXXXXXXXX
+	//   required: true
+	//   type: string
+	// responses:
+	//   '200':
+	//     description: Lease resource after revocation.
+	//     schema:
+	//         "$ref": "#/definitions/Lease"
+	//   '400':
+	//     description: Lease already revoked
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '404':
+	//     description: Resource not found.
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '403':
+	//     description: Caller lacks admin role on connection
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '500':
+	//     description: Internal server error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   default:
+	//     description: unexpected error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	ctx, span, requestid, cl := utilities.SetupTraceAndLogger(r, w, h.l, utilities.GetFunctionName(), h.cfg.Server.PrefixMain)
+	defer span.End()
+
+	vars := mux.Vars(r)
+
+	connection, httpStatusCode, helpError, err := getMountedConnection(h.pd, vars["connectionid"])
+	if err != nil {
+		helper.ReturnError(cl, httpStatusCode, helpError, err, requestid, r, &w, span)
+		return
+	}
+
+	if err := authorize(h.pe, auth.PermissionManageLeases, connection.ConnectionID, ctx, cl, requestid, r, &w, span); err != nil {
+		return
+	}
+
+	lease, httpStatusCode, helpError, err := h.getLease(connection.ConnectionID, vars["leaseid"])
+	if err != nil {
+		helper.ReturnError(cl, httpStatusCode, helpError, err, requestid, r, &w, span)
+		return
+	}
+
+	if lease.Revoked {
+		helper.ReturnError(cl, http.StatusBadRequest, helper.ErrorLeaseAlreadyRevoked, helper.ErrLeaseAlreadyRevoked, requestid, r, &w, span)
+		return
+	}
+
+	audit := data.NewAuditRecord(requestid, connection.ConnectionID, data.RevokeLease, requester(r))
+
+	if err := h.sb.RevokeLease(lease.LeaseID, connection.VaultNamespace, ctx); err != nil {
+		recordAuditFailure(h.pd, audit, err, cl, ctx, h.cfg.Server.PrefixMain)
+		helper.ReturnError(cl, http.StatusInternalServerError, helper.ErrorVaultLeaseRevokeFailed, err, requestid, r, &w, span)
+		return
+	}
+
+	lease.SetRevoked()
+	audit.SetSuccessful(fmt.Sprintf("lease %s revoked", lease.ID.String()))
+
+	if err := saveWithAudit(h.pd, lease, audit, ctx, h.cfg.Server.PrefixMain); err != nil {
+		helper.ReturnError(cl, http.StatusInternalServerError, helper.ErrorDatastoreSaveFailed, err, requestid, r, &w, span)
+		return
+	}
+
+	utilities.WriteResponse(w, cl, lease, span)
+}
+
+func (h *LeaseHandler) RevokeConnectionLeases(w http.ResponseWriter, r *http.Request) {
+
+	// swagger:operation POST /connection/leases/revoke Lease RevokeConnectionLeases
+	// Revoke all leases of Connection
+	//
+	// Endpoint: POST - /v1/connectionmgmt/connection/{connectionid}/leases/revoke
+	//
+	// Description: Revoke every lease issued through secrets engine mount of connection of any type using Vault
+	// sys/leases/revoke-prefix. Leases which are not tracked in datastore are revoked as well.
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: connectionid
+	//   in: query
+	//   description: id for generic Connection resource. expected to be in uuid format i.e. XXXXXXXX-XXXX-XXXX-XXXX-XXXXXXXXXXXX
+	//   required: true
+	//   type: string
+	// responses:
+	//   '200':
+	//     description: Leases revoked.
+	//     schema:
+	//         "$ref": "#/definitions/RevokeLeasesResponse"
+	//   '404':
+	//     description: Resource not found.
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '403':
+	//     description: Caller lacks admin role on connection
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '500':
+	//     description: Internal server error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   default:
+	//     description: unexpected error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	ctx, span, requestid, cl := utilities.SetupTraceAndLogger(r, w, h.l, utilities.GetFunctionName(), h.cfg.Server.PrefixMain)
+	defer span.End()
+
+	connection, httpStatusCode, helpError, err := getMountedConnection(h.pd, mux.Vars(r)["connectionid"])
+	if err != nil {
+		helper.ReturnError(cl, httpStatusCode, helpError, err, requestid, r, &w, span)
+		return
+	}
+
+	if err := authorize(h.pe, auth.PermissionManageLeases, connection.ConnectionID, ctx, cl, requestid, r, &w, span); err != nil {
+		return
+	}
+
+	audit := data.NewAuditRecord(requestid, connection.ConnectionID, data.RevokeLease, requester(r))
+
+	revoked, err := revokeConnectionLeases(h.pd, h.sb, connection.ConnectionID, connection.VaultPath, connection.VaultNamespace, audit, ctx, h.cfg.Server.PrefixMain)
+	if err != nil {
+		recordAuditFailure(h.pd, audit, err, cl, ctx, h.cfg.Server.PrefixMain)
+		helper.ReturnError(cl, http.StatusInternalServerError, helper.ErrorVaultLeaseRevokeFailed, err, requestid, r, &w, span)
+		return
+	}
+
+	response := data.RevokeLeasesResponse{
+		ConnectionID: connection.ConnectionID.String(),
+		Revoked:      revoked,
+	}
+
+	utilities.WriteResponse(w, cl, response, span)
+}
+
+// revokeConnectionLeases revokes all leases under vaultPath in namespace and marks tracked leases of connection as revoked.
+func revokeConnectionLeases(pd *datalayer.PostgresDataSource, sb secretsmanager.SecretsBackend, connectionID uuid.UUID, vaultPath string, namespace string, audit *data.AuditRecord, ctx context.Context, tracerName string) (int, error) {
+
+	tr := otel.Tracer(tracerName)
+	ctx, span := tr.Start(ctx, utilities.GetFunctionName())
+	defer span.End()
+
+	if err := sb.RevokeLeasesByPrefix(vaultPath, namespace, ctx); err != nil {
+		return 0, err
+	}
+
+	now := time.Now().UTC()
+
+	// Begin a transaction
+	tx := pd.RWDB().Begin()
+
+	// Check if the transaction started successfully
+	if tx.Error != nil {
+		return 0, tx.Error
+	}
+
+	result := tx.
+		Model(&data.Lease{}).
+		Where("connection_id = ? AND revoked = ?", connectionID, false).
+		Updates(map[string]interface{}{"revoked": true, "revoked_at": now})
+
+	if result.Error != nil {
+		tx.Rollback()
+		return 0, result.Error
+	}
+
+	audit.SetSuccessful(fmt.Sprintf("all leases revoked. tracked leases revoked: %d", result.RowsAffected))
+
+	if err := utilities.CreateObjectWithoutTx(tx, audit, ctx, tracerName); err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	// Commit the transaction
+	if err := tx.Commit().Error; err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return int(result.RowsAffected), nil
+}
+
+func (h LeaseHandler) MiddlewareValidateLeasesGet(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+
+		_, span, requestid, cl := utilities.SetupTraceAndLogger(r, rw, h.l, utilities.GetFunctionName(), h.cfg.Server.PrefixMain)
+		defer span.End()
+
+		if _, found := utilities.ValidateQueryStringParam("connectionid", r, cl, rw, span); !found {
+			return
+		}
+
+		vars := r.URL.Query()
+
+		// Validate limit parameter
+		if err := utilities.ValidateQueryParam(vars.Get("limit"), 1, true, cl, r, rw, span, requestid, helper.ErrorInvalidValueForLimit); err != nil {
+			return
+		}
+
+		// Validate skip parameter
+		if err := utilities.ValidateQueryParam(vars.Get("skip"), 0, false, cl, r, rw, span, requestid, helper.ErrorInvalidValueForSkip); err != nil {
+			return
+		}
+
+		// Call the next handler, which can be another middleware in the chain, or the final handler.
+		next.ServeHTTP(rw, r)
+	})
+}
+
+func (h LeaseHandler) MiddlewareValidateLease(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+
+		_, span, requestid, cl := utilities.SetupTraceAndLogger(r, rw, h.l, utilities.GetFunctionName(), h.cfg.Server.PrefixMain)
+		defer span.End()
+
+		if _, found := utilities.ValidateQueryStringParam("connectionid", r, cl, rw, span); !found {
+			return
+		}
+
+		if _, found := utilities.ValidateQueryStringParam("leaseid", r, cl, rw, span); !found {
+			return
+		}
+
+		// Validate increment parameter
+		if err := utilities.ValidateDurationParam(r.URL.Query().Get("increment"), cl, r, rw, span, requestid, helper.ErrorInvalidValueForIncrement); err != nil {
+			return
+		}
+
+		// Call the next handler, which can be another middleware in the chain, or the final handler.
+		next.ServeHTTP(rw, r)
+	})
+}
+
+func (h LeaseHandler) MiddlewareValidateLeasesRevoke(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+
+		_, span, _, cl := utilities.SetupTraceAndLogger(r, rw, h.l, utilities.GetFunctionName(), h.cfg.Server.PrefixMain)
+		defer span.End()
+
+		if _, found := utilities.ValidateQueryStringParam("connectionid", r, cl, rw, span); !found {
+			return
+		}
+
+		// Call the next handler, which can be another middleware in the chain, or the final handler.
+		next.ServeHTTP(rw, r)
+	})
+}
@@ -0,0 +1,1351 @@
+package handlers
+
+import (
+	"DemoServer_ConnectionManager/auth"
+	"DemoServer_ConnectionManager/configuration"
+	"DemoServer_ConnectionManager/data"
+	"DemoServer_ConnectionManager/datalayer"
+	"DemoServer_ConnectionManager/helper"
+	"DemoServer_ConnectionManager/secretsmanager"
+	"DemoServer_ConnectionManager/utilities"
+	"context"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/crypto/ocsp"
+	"gorm.io/gorm"
+)
+
+type KeyPKIConnectionRecord struct{}
+type KeyPKIConnectionPatchParamsRecord struct{}
+type KeyPKIIssueRecord struct{}
+type KeyPKIOCSPRequestRecord struct{}
+
+// maxOCSPRequestSize limits body of OCSP requests, which only carry hashes and serial numbers of certificates
+const maxOCSPRequestSize = 64 * 1024
+
+type PKIConnectionHandler struct {
+	connectionHandler[data.PKIConnection, *data.PKIConnection]
+	roleHandler[data.PKIConnection, *data.PKIConnection, data.PKIRole, *data.PKIRole]
+}
+
+func NewPKIConnectionHandler(cfg *configuration.Config, l *slog.Logger, pd *datalayer.PostgresDataSource, sb secretsmanager.SecretsBackend, pe *auth.PolicyEngine) (*PKIConnectionHandler, error) {
+	var c PKIConnectionHandler
+
+	c.connectionHandler = newConnectionHandler[data.PKIConnection, *data.PKIConnection](cfg, l, pd, sb, pe, connectionEngine[data.PKIConnection]{
+		table:   "pki_connections",
+		load:    sb.GetPKISecretsEngine,
+		update:  sb.UpdatePKISecretsEngine,
+		unmount: sb.RemovePKISecretsEngine,
+		test: func(c *data.PKIConnection, roleName string, ctx context.Context) error {
+			role := data.NewPKIRole(c.ID)
+			role.RoleName = roleName
+
+			// Domains allowed by role are only kept in Vault
+			if err := sb.GetPKISecretsEngineRole(c.VaultPath, c.VaultNamespace, role, ctx); err != nil {
+				return err
+			}
+			return sb.TestPKISecretsEngine(c.VaultPath, c.VaultNamespace, roleName, role.TestCommonName(), ctx)
+		},
+		mountFailed: helper.ErrorVaultSecretsEngineFailed,
+		validate: func(c *data.PKIConnection, cl *slog.Logger, requestid string, r *http.Request, w http.ResponseWriter, span trace.Span) error {
+			return validatePKIRole(c.DefaultRole(), cl, requestid, r, w, span)
+		},
+		resolveRole: c.resolveRole,
+		records: func(c *data.PKIConnection) []interface{} {
+			return []interface{}{c.DefaultRole()}
+		},
+		deleteRecords: c.deletePKIRecords,
+		refuseDelete:  c.refuseIssuerInUse,
+	})
+
+	c.roleHandler = newRoleHandler[data.PKIConnection, *data.PKIConnection, data.PKIRole, *data.PKIRole](&c.connectionHandler, roleEngine[data.PKIConnection, data.PKIRole]{
+		column:    "pki_connection_id",
+		owner:     func(c *data.PKIConnection) uuid.UUID { return c.ID },
+		newRole:   data.NewPKIRole,
+		load:      sb.GetPKISecretsEngineRole,
+		add:       sb.AddPKISecretsEngineRole,
+		update:    sb.UpdatePKISecretsEngineRole,
+		remove:    sb.RemovePKISecretsEngineRole,
+		validName: data.IsValidPKIRoleName,
+		validate:  validatePKIRole,
+		defaults: func(c *data.PKIConnection, role *data.PKIRole, ctx context.Context) error {
+			if role.KeyType != "" {
+				return nil
+			}
+
+			// Key type of connection is only kept in Vault
+			if err := sb.GetPKISecretsEngine(c, ctx); err != nil {
+				return err
+			}
+			role.KeyType = c.KeyType
+			return nil
+		},
+	})
+
+	return &c, nil
+}
+
+func (h *PKIConnectionHandler) GetPKIConnections(w http.ResponseWriter, r *http.Request) {
+
+	// swagger:operation GET /connections/pki PKIConnection GetPKIConnections
+	// List PKI Connections
+	//
+	// Endpoint: GET - /v1/connectionmgmt/connections/pki
+	//
+	// Description: Returns list of PKIConnection resources. Each PKIConnection resource
+	// contains underlying generic Connection resource as well as PKIConnection
+	// specific attributes. Private key of CA is never returned.
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: limit
+	//   in: query
+	//   description: maximum number of results to return.
+	//   required: false
+	//   type: integer
+	//   format: int32
+	// - name: skip
+	//   in: query
+	//   description: number of results to be skipped from beginning of list
+	//   required: false
+	//   type: integer
+	//   format: int32
+	// responses:
+	//   '200':
+	//     description: List of PKIConnection resources
+	//     schema:
+	//       type: array
+	//       items:
+	//         "$ref": "#/definitions/PKIConnection"
+	//   '400':
+	//     description: Issues with parameters or their value
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '500':
+	//     description: Internal server error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   default:
+	//     description: unexpected error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	ctx, span, requestid, cl := utilities.SetupTraceAndLogger(r, w, h.l, utilities.GetFunctionName(), h.cfg.Server.PrefixMain)
+	defer span.End()
+
+	connections, limit, skip, err := h.listConnections(ctx, cl, requestid, r, &w, span)
+	if err != nil {
+		return
+	}
+
+	wrapped, err := connectionResponses[data.PKIConnectionResponseWrapper](connections, cl, requestid, r, &w, span)
+	if err != nil {
+		return
+	}
+
+	response := data.PKIConnectionsResponse{
+		Total:          len(connections),
+		Skip:           skip,
+		Limit:          limit,
+		PKIConnections: wrapped,
+	}
+
+	utilities.WriteResponse(w, cl, response, span)
+}
+
+// GetPKIConnection returns PKIConnection resource based on connectionid parameter
+func (h *PKIConnectionHandler) GetPKIConnection(w http.ResponseWriter, r *http.Request) {
+
+	// swagger:operation GET /connection/pki PKIConnection GetPKIConnection
+	// Retrieve PKI Connection
+	//
+	// Endpoint: GET - /v1/connectionmgmt/connection/pki/{connectionid}
+	//
+	// Description: Returns PKIConnection resource based on connectionid.
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: connectionid
+	//   in: query
+	//   description: id for PKIConnection resource to be retrieved. expected to be in uuid format i.e. XXXXXXXX-XXXX-XXXX-XXXX-XXXXXXXXXXXX
+	//   required: true
+	//   type: string
+	// responses:
+	//   '200':
+	//     description: PKIConnection resource
+	//     schema:
+	//         "$ref": "#/definitions/PKIConnection"
+	//   '400':
+	//     description: Issues with parameters or their value
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '403':
+	//     description: Caller lacks viewer role on connection
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '404':
+	//     description: Resource not found. Resources are filtered based on connectiontype = PKIConnectionType. If connectionid of Non-PKIConnection is provided ResourceNotFound error is returned.
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '500':
+	//     description: Internal server error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   default:
+	//     description: unexpected error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	ctx, span, requestID, cl := utilities.SetupTraceAndLogger(r, w, h.l, utilities.GetFunctionName(), h.cfg.Server.PrefixMain)
+	defer span.End()
+
+	connection, applications, err := h.viewConnection(ctx, cl, requestID, r, &w, span)
+	if err != nil {
+		return
+	}
+
+	response, err := connectionResponse[data.PKIConnectionResponseWrapper](connection, cl, requestID, r, &w, span)
+	if err != nil {
+		return
+	}
+	response.Connection.Applications = applications
+
+	utilities.WriteResponse(w, cl, response, span)
+}
+
+func (h *PKIConnectionHandler) IssuePKIConnection(w http.ResponseWriter, r *http.Request) {
+
+	// swagger:operation POST /connection/pki/issue PKIConnection IssuePKIConnection
+	// Issue Certificate
+	//
+	// Endpoint: POST - /v1/connectionmgmt/connection/pki/{connectionid}/issue
+	//
+	// Description: Issue certificate along with private key through role of specified PKIConnection. Connection has
+	// to be tested successfully before it can be used for issuance. Certificates are only issued to application
+	// identified by applicationid which has to match identity of caller and be linked to connection. Certificates
+	// are not leased, they are recorded in inventory of connection and stay valid until they expire or are revoked.
+	// Private key is neither stored by service nor by Vault.
+	//
+	// ---
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: connectionid
+	//   in: query
+	//   description: id for PKIConnection resource to be used for issuance. expected to be in uuid format i.e. XXXXXXXX-XXXX-XXXX-XXXX-XXXXXXXXXXXX
+	//   required: true
+	//   type: string
+	// - name: applicationid
+	//   in: query
+	//   description: id of application for which certificate is issued. has to identify caller and be linked to connection. recorded in inventory and audit trail.
+	//   required: true
+	//   type: string
+	// - name: role_name
+	//   in: query
+	//   description: name of role certificate is issued through. default role of connection is used if not specified. has to be among allowed_roles of link of application if link restricts roles.
+	//   required: false
+	//   type: string
+	// - name: X-Requester
+	//   in: header
+	//   description: identity of caller requesting certificate. recorded in inventory and audit trail. ignored in favour of subject of bearer token when authentication is enabled.
+	//   required: false
+	//   type: string
+	// - in: body
+	//   name: Body
+	//   description: names and validity of certificate
+	//   required: true
+	//   schema:
+	//     "$ref": "#/definitions/IssuePKIConnectionRequest"
+	// responses:
+	//   '200':
+	//     description: Certificate issued successfully
+	//     schema:
+	//         "$ref": "#/definitions/IssuePKIConnectionResponse"
+	//   '400':
+	//     description: Issues with parameters or their value
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '404':
+	//     description: Resource not found. Resources are filtered based on connectiontype = PKIConnectionType. If connectionid of Non-PKIConnection is provided ResourceNotFound error is returned.
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '403':
+	//     description: Caller lacks credential-consumer role on connection, applicationid does not identify caller, application is not linked to connection or link does not allow role
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '409':
+	//     description: Connection has not been tested successfully yet
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '500':
+	//     description: Internal server error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   default:
+	//     description: unexpected error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	ctx, span, requestID, cl := utilities.SetupTraceAndLogger(r, w, h.l, utilities.GetFunctionName(), h.cfg.Server.PrefixMain)
+	defer span.End()
+
+	p := r.Context().Value(KeyPKIIssueRecord{}).(*data.IssuePKIConnectionRequest)
+
+	grant, err := h.grantCreds(ctx, cl, requestID, r, &w, span)
+	if err != nil {
+		return
+	}
+
+	connection, roleName, applicationID, audit := grant.connection, grant.roleName, grant.applicationID, grant.audit
+
+	response, err := h.sb.IssuePKISecretsEngine(connection.VaultPath, connection.VaultNamespace, roleName, p, ctx)
+	if err != nil {
+		h.failCreds(grant, err, ctx, cl, requestID, r, &w, span)
+		return
+	}
+
+	certificate := data.NewIssuedCertificate(connection.ConnectionID, requestID)
+	certificate.ApplicationID = applicationID
+	certificate.RoleName = roleName
+	certificate.Requester = audit.UserID
+	certificate.SerialNumber = response.SerialNumber
+	certificate.CommonName = p.CommonName
+	certificate.AltNames = p.AltNames
+	certificate.ExpiresAt = response.ExpiresAt.UTC()
+
+	audit.SetSuccessful(fmt.Sprintf("certificate %s issued for role %s, application %s and common name %s, valid until %s", response.SerialNumber, response.RoleName, applicationID, p.CommonName, response.ExpiresAt.Format(time.RFC3339)))
+
+	if err := recordIssuedCertificate(h.pd, h.sb, certificate, connection.VaultPath, connection.VaultNamespace, audit, ctx, h.cfg.Server.PrefixMain); err != nil {
+		helper.ReturnError(cl, http.StatusInternalServerError, helper.ErrorDatastoreSaveFailed, err, requestID, r, &w, span)
+		return
+	}
+
+	response.ConnectionID = connection.ID.String()
+	response.CertificateID = certificate.ID.String()
+
+	utilities.WriteResponse(w, cl, response, span)
+}
+
+// recordIssuedCertificate persists freshly issued certificate in inventory along with audit record of its issuance.
+// If certificate can not be persisted, it is revoked in Vault so that no untracked certificates remain valid.
+func recordIssuedCertificate(pd *datalayer.PostgresDataSource, sb secretsmanager.SecretsBackend, certificate *data.IssuedCertificate, vaultPath string, namespace string, audit *data.AuditRecord, ctx context.Context, tracerName string) error {
+
+	tr := otel.Tracer(tracerName)
+	ctx, span := tr.Start(ctx, utilities.GetFunctionName())
+	defer span.End()
+
+	if err := createIssuedCertificate(pd, certificate, audit, ctx, tracerName); err != nil {
+		if revokeErr := sb.RevokePKICertificate(vaultPath, namespace, certificate.SerialNumber, ctx); revokeErr != nil {
+			return fmt.Errorf("%w. revocation of untracked certificate failed: %s", err, revokeErr.Error())
+		}
+		return err
+	}
+
+	return nil
+}
+
+func createIssuedCertificate(pd *datalayer.PostgresDataSource, certificate *data.IssuedCertificate, audit *data.AuditRecord, ctx context.Context, tracerName string) error {
+
+	// Begin a transaction
+	tx := pd.RWDB().Begin()
+
+	// Check if the transaction started successfully
+	if tx.Error != nil {
+		return tx.Error
+	}
+
+	if err := utilities.CreateObjectWithoutTx(tx, certificate, ctx, tracerName); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := utilities.CreateObjectWithoutTx(tx, audit, ctx, tracerName); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// Commit the transaction
+	if err := tx.Commit().Error; err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+func (h *PKIConnectionHandler) getIssuedCertificate(connectionID uuid.UUID, certificateID string) (*data.IssuedCertificate, int, helper.ErrorTypeEnum, error) {
+	var certificate data.IssuedCertificate
+
+	result := h.pd.RODB().First(&certificate, "id = ? AND connection_id = ?", certificateID, connectionID)
+
+	if result.Error != nil {
+		return nil, http.StatusInternalServerError, helper.ErrorDatastoreRetrievalFailed, result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return nil, http.StatusNotFound, helper.ErrorResourceNotFound, fmt.Errorf("%s", helper.ErrorDictionary[helper.ErrorResourceNotFound].Error())
+	}
+
+	return &certificate, http.StatusOK, helper.ErrorNone, nil
+}
+
+// fetchIssuedCertificates returns certificates of connection which have not expired yet, soonest expiring first.
+// If expiresWithin is set, only certificates which are not revoked and expire within it are returned.
+func (h *PKIConnectionHandler) fetchIssuedCertificates(connectionID uuid.UUID, expiresWithin time.Duration, limit, skip int) ([]data.IssuedCertificate, error) {
+	var certificates []data.IssuedCertificate
+
+	now := time.Now().UTC()
+
+	query := h.pd.RODB().Where("connection_id = ? AND expires_at > ?", connectionID, now)
+	if expiresWithin > 0 {
+		query = query.Where("revoked = ? AND expires_at <= ?", false, now.Add(expiresWithin))
+	}
+
+	result := query.
+		Limit(limit).
+		Offset(skip).
+		Order("expires_at").
+		Find(&certificates)
+
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return certificates, nil
+}
+
+// parseExpiresWithin converts value of expires_within parameter, either in seconds or duration format, into duration.
+// Value is expected to be validated by middleware already.
+func parseExpiresWithin(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+
+	d, _ := time.ParseDuration(value)
+	return d
+}
+
+func (h *PKIConnectionHandler) GetPKIConnectionCertificates(w http.ResponseWriter, r *http.Request) {
+
+	// swagger:operation GET /connection/pki/certificates PKIConnection GetPKIConnectionCertificates
+	// List certificates issued through PKI Connection
+	//
+	// Endpoint: GET - /v1/connectionmgmt/connection/pki/{connectionid}/certificates
+	//
+	// Description: Returns inventory of certificates issued through specified PKIConnection which have not expired
+	// yet, soonest expiring first. Revoked certificates are included and flagged as revoked unless expires_within
+	// is provided, in which case only certificates still in use which expire within it are returned.
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: connectionid
+	//   in: query
+	//   description: id for PKIConnection resource. expected to be in uuid format i.e. XXXXXXXX-XXXX-XXXX-XXXX-XXXXXXXXXXXX
+	//   required: true
+	//   type: string
+	// - name: expires_within
+	//   in: query
+	//   description: only return certificates which are not revoked and expire within this period, either in seconds or duration format i.e. 86400, 720h.
+	//   required: false
+	//   type: string
+	// - name: limit
+	//   in: query
+	//   description: maximum number of results to return.
+	//   required: false
+	//   type: integer
+	//   format: int32
+	// - name: skip
+	//   in: query
+	//   description: number of results to be skipped from beginning of list
+	//   required: false
+	//   type: integer
+	//   format: int32
+	// responses:
+	//   '200':
+	//     description: List of IssuedCertificate resources
+	//     schema:
+	//         "$ref": "#/definitions/IssuedCertificatesResponse"
+	//   '400':
+	//     description: Issues with parameters or their value
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '404':
+	//     description: Resource not found.
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '403':
+	//     description: Caller lacks viewer role on connection
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '500':
+	//     description: Internal server error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   default:
+	//     description: unexpected error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	ctx, span, requestid, cl := utilities.SetupTraceAndLogger(r, w, h.l, utilities.GetFunctionName(), h.cfg.Server.PrefixMain)
+	defer span.End()
+
+	vars := r.URL.Query()
+	limit := utilities.ParseQueryParam(vars, "limit", h.list_limit, h.cfg.DataLayer.MaxResults)
+	skip := utilities.ParseQueryParam(vars, "skip", 0, math.MaxInt32)
+
+	connection, err := h.getConnection(mux.Vars(r)["connectionid"], cl, requestid, r, &w, span)
+	if err != nil {
+		return
+	}
+
+	if err := authorize(h.pe, auth.PermissionView, connection.ConnectionID, ctx, cl, requestid, r, &w, span); err != nil {
+		return
+	}
+
+	certificates, err := h.fetchIssuedCertificates(connection.ConnectionID, parseExpiresWithin(vars.Get("expires_within")), limit, skip)
+	if err != nil {
+		helper.ReturnError(cl, http.StatusInternalServerError, helper.ErrorDatastoreRetrievalFailed, err, requestid, r, &w, span)
+		return
+	}
+
+	response := data.IssuedCertificatesResponse{
+		Total:        len(certificates),
+		Skip:         skip,
+		Limit:        limit,
+		Certificates: certificates,
+	}
+
+	if response.Certificates == nil {
+		response.Certificates = []data.IssuedCertificate{}
+	}
+
+	utilities.WriteResponse(w, cl, response, span)
+}
+
+func (h *PKIConnectionHandler) RevokePKIConnectionCertificate(w http.ResponseWriter, r *http.Request) {
+
+	// swagger:operation POST /connection/pki/certificates/revoke PKIConnection RevokePKIConnectionCertificate
+	// Revoke certificate issued through PKI Connection
+	//
+	// Endpoint: POST - /v1/connectionmgmt/connection/pki/{connectionid}/certificates/{certificateid}/revoke
+	//
+	// Description: Revoke certificate through Vault pki revoke endpoint. Certificate is listed on CRL and reported
+	// as revoked by OCSP endpoint of connection from then on. Revoking certificate which is already revoked
+	// returns it unchanged.
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: connectionid
+	//   in: query
+	//   description: id for PKIConnection resource. expected to be in uuid format i.e. XXXXXXXX-XXXX-XXXX-XXXX-XXXXXXXXXXXX
+	//   required: true
+	//   type: string
+	// - name: certificateid
+	//   in: query
+	//   description: id for IssuedCertificate resource. expected to be in uuid format i.e. XXXXXXXX-XXXX-XXXX-XXXX-XXXXXXXXXXXX
+	//   required: true
+	//   type: string
+	// responses:
+	//   '200':
+	//     description: IssuedCertificate resource after revocation.
+	//     schema:
+	//         "$ref": "#/definitions/IssuedCertificate"
+	//   '404':
+	//     description: Resource not found.
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '403':
+	//     description: Caller lacks admin role on connection
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '500':
+	//     description: Internal server error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   default:
+	//     description: unexpected error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	ctx, span, requestid, cl := utilities.SetupTraceAndLogger(r, w, h.l, utilities.GetFunctionName(), h.cfg.Server.PrefixMain)
+	defer span.End()
+
+	vars := mux.Vars(r)
+
+	connection, err := h.getConnection(vars["connectionid"], cl, requestid, r, &w, span)
+	if err != nil {
+		return
+	}
+
+	if err := authorize(h.pe, auth.PermissionManageLeases, connection.ConnectionID, ctx, cl, requestid, r, &w, span); err != nil {
+		return
+	}
+
+	certificate, httpStatusCode, helpError, err := h.getIssuedCertificate(connection.ConnectionID, vars["certificateid"])
+	if err != nil {
+		helper.ReturnError(cl, httpStatusCode, helpError, err, requestid, r, &w, span)
+		return
+	}
+
+	// Vault treats revocation of revoked certificate as no-op as well
+	if certificate.Revoked {
+		utilities.WriteResponse(w, cl, certificate, span)
+		return
+	}
+
+	audit := data.NewAuditRecord(requestid, connection.ConnectionID, data.RevokeCertificate, requester(r))
+
+	if err := h.sb.RevokePKICertificate(connection.VaultPath, connection.VaultNamespace, certificate.SerialNumber, ctx); err != nil {
+		recordAuditFailure(h.pd, audit, err, cl, ctx, h.cfg.Server.PrefixMain)
+		helper.ReturnError(cl, http.StatusInternalServerError, helper.ErrorVaultLeaseRevokeFailed, err, requestid, r, &w, span)
+		return
+	}
+
+	certificate.SetRevoked()
+	audit.SetSuccessful(fmt.Sprintf("certificate %s with serial number %s revoked", certificate.ID.String(), certificate.SerialNumber))
+
+	if err := saveWithAudit(h.pd, certificate, audit, ctx, h.cfg.Server.PrefixMain); err != nil {
+		helper.ReturnError(cl, http.StatusInternalServerError, helper.ErrorDatastoreSaveFailed, err, requestid, r, &w, span)
+		return
+	}
+
+	utilities.WriteResponse(w, cl, certificate, span)
+}
+
+// writePKIObject writes DER encoded object as is or PEM encoded under blockType if format is pem.
+func writePKIObject(w http.ResponseWriter, der []byte, format string, contentType string, blockType string, cl *slog.Logger, span trace.Span) {
+	body := der
+	if strings.EqualFold(format, "pem") {
+		body = pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})
+		contentType = "application/x-pem-file"
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(body); err != nil {
+		helper.LogError(cl, helper.ErrorJSONEncodingFailed, err, span)
+	}
+}
+
+// GetPKIConnectionCA returns certificate of CA of connection
+func (h *PKIConnectionHandler) GetPKIConnectionCA(w http.ResponseWriter, r *http.Request) {
+
+	// swagger:operation GET /connection/pki/ca PKIConnection GetPKIConnectionCA
+	// Retrieve PKI CA Certificate
+	//
+	// Endpoint: GET - /v1/connectionmgmt/connection/pki/{connectionid}/ca
+	//
+	// Description: Returns certificate of CA of PKIConnection, DER encoded unless format is pem, so that it can be
+	// added to trust stores of services as is. Issuing CA URL of issued certificates points to this endpoint when
+	// base URL of service is configured. Certificate is not secret, so endpoint is served without bearer token.
+	//
+	// ---
+	// produces:
+	// - application/pkix-cert
+	// - application/x-pem-file
+	// parameters:
+	// - name: connectionid
+	//   in: query
+	//   description: id for PKIConnection resource whose CA certificate is retrieved. expected to be in uuid format i.e. XXXXXXXX-XXXX-XXXX-XXXX-XXXXXXXXXXXX
+	//   required: true
+	//   type: string
+	// - name: format
+	//   in: query
+	//   description: encoding of certificate. one of der, pem. der if not provided.
+	//   required: false
+	//   type: string
+	// responses:
+	//   '200':
+	//     description: Certificate of CA
+	//     schema:
+	//       type: string
+	//   '400':
+	//     description: Issues with parameters or their value
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '404':
+	//     description: Resource not found. Resources are filtered based on connectiontype = PKIConnectionType. If connectionid of Non-PKIConnection is provided ResourceNotFound error is returned.
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '500':
+	//     description: Internal server error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   default:
+	//     description: unexpected error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	ctx, span, requestID, cl := utilities.SetupTraceAndLogger(r, w, h.l, utilities.GetFunctionName(), h.cfg.Server.PrefixMain)
+	defer span.End()
+
+	connection, err := h.getConnection(mux.Vars(r)["connectionid"], cl, requestID, r, &w, span)
+	if err != nil {
+		return
+	}
+
+	certificate, err := h.sb.GetPKICACertificate(connection.VaultPath, connection.VaultNamespace, ctx)
+	if err != nil {
+		helper.ReturnError(cl, http.StatusInternalServerError, helper.ErrorVaultLoadFailed, err, requestID, r, &w, span)
+		return
+	}
+
+	writePKIObject(w, certificate, r.URL.Query().Get("format"), "application/pkix-cert", "CERTIFICATE", cl, span)
+}
+
+// GetPKIConnectionCRL returns certificate revocation list of CA of connection
+func (h *PKIConnectionHandler) GetPKIConnectionCRL(w http.ResponseWriter, r *http.Request) {
+
+	// swagger:operation GET /connection/pki/crl PKIConnection GetPKIConnectionCRL
+	// Retrieve PKI CRL
+	//
+	// Endpoint: GET - /v1/connectionmgmt/connection/pki/{connectionid}/crl
+	//
+	// Description: Returns certificate revocation list of CA of PKIConnection, DER encoded unless format is pem. CRL
+	// distribution point of issued certificates points to this endpoint when base URL of service is configured, so
+	// endpoint is served without bearer token.
+	//
+	// ---
+	// produces:
+	// - application/pkix-crl
+	// - application/x-pem-file
+	// parameters:
+	// - name: connectionid
+	//   in: query
+	//   description: id for PKIConnection resource whose CRL is retrieved. expected to be in uuid format i.e. XXXXXXXX-XXXX-XXXX-XXXX-XXXXXXXXXXXX
+	//   required: true
+	//   type: string
+	// - name: format
+	//   in: query
+	//   description: encoding of CRL. one of der, pem. der if not provided.
+	//   required: false
+	//   type: string
+	// responses:
+	//   '200':
+	//     description: Certificate revocation list of CA
+	//     schema:
+	//       type: string
+	//   '400':
+	//     description: Issues with parameters or their value
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '404':
+	//     description: Resource not found. Resources are filtered based on connectiontype = PKIConnectionType. If connectionid of Non-PKIConnection is provided ResourceNotFound error is returned.
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '500':
+	//     description: Internal server error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   default:
+	//     description: unexpected error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	ctx, span, requestID, cl := utilities.SetupTraceAndLogger(r, w, h.l, utilities.GetFunctionName(), h.cfg.Server.PrefixMain)
+	defer span.End()
+
+	connection, err := h.getConnection(mux.Vars(r)["connectionid"], cl, requestID, r, &w, span)
+	if err != nil {
+		return
+	}
+
+	crl, err := h.sb.GetPKICRL(connection.VaultPath, connection.VaultNamespace, ctx)
+	if err != nil {
+		helper.ReturnError(cl, http.StatusInternalServerError, helper.ErrorVaultLoadFailed, err, requestID, r, &w, span)
+		return
+	}
+
+	writePKIObject(w, crl, r.URL.Query().Get("format"), "application/pkix-crl", "X509 CRL", cl, span)
+}
+
+// GetPKIConnectionOCSP answers OCSP request for certificates issued by CA of connection
+func (h *PKIConnectionHandler) GetPKIConnectionOCSP(w http.ResponseWriter, r *http.Request) {
+
+	// swagger:operation POST /connection/pki/ocsp PKIConnection GetPKIConnectionOCSP
+	// PKI OCSP Responder
+	//
+	// Endpoint: POST - /v1/connectionmgmt/connection/pki/{connectionid}/ocsp
+	//
+	// Description: Answers DER encoded OCSP request with status of certificates issued by CA of PKIConnection as
+	// signed by Vault. OCSP server of issued certificates points to this endpoint when base URL of service is
+	// configured, so endpoint is served without bearer token.
+	//
+	// ---
+	// consumes:
+	// - application/ocsp-request
+	// produces:
+	// - application/ocsp-response
+	// parameters:
+	// - name: connectionid
+	//   in: query
+	//   description: id for PKIConnection resource whose CA issued certificates. expected to be in uuid format i.e. XXXXXXXX-XXXX-XXXX-XXXX-XXXXXXXXXXXX
+	//   required: true
+	//   type: string
+	// - in: body
+	//   name: Body
+	//   description: DER encoded OCSP request
+	//   required: true
+	//   schema:
+	//     type: string
+	//     format: binary
+	// responses:
+	//   '200':
+	//     description: DER encoded OCSP response
+	//     schema:
+	//       type: string
+	//   '400':
+	//     description: Body is not a DER encoded OCSP request
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '404':
+	//     description: Resource not found. Resources are filtered based on connectiontype = PKIConnectionType. If connectionid of Non-PKIConnection is provided ResourceNotFound error is returned.
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '500':
+	//     description: Internal server error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   default:
+	//     description: unexpected error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	ctx, span, requestID, cl := utilities.SetupTraceAndLogger(r, w, h.l, utilities.GetFunctionName(), h.cfg.Server.PrefixMain)
+	defer span.End()
+
+	request := r.Context().Value(KeyPKIOCSPRequestRecord{}).([]byte)
+
+	connection, err := h.getConnection(mux.Vars(r)["connectionid"], cl, requestID, r, &w, span)
+	if err != nil {
+		return
+	}
+
+	response, err := h.sb.GetPKIOCSPResponse(connection.VaultPath, connection.VaultNamespace, request, ctx)
+	if err != nil {
+		helper.ReturnError(cl, http.StatusInternalServerError, helper.ErrorVaultLoadFailed, err, requestID, r, &w, span)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/ocsp-response")
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(response); err != nil {
+		helper.LogError(cl, helper.ErrorJSONEncodingFailed, err, span)
+	}
+}
+
+func (h *PKIConnectionHandler) TestPKIConnection(w http.ResponseWriter, r *http.Request) {
+
+	// swagger:operation GET /connection/pki/test PKIConnection TestPKIConnection
+	// Test PKI Connection
+	//
+	// Endpoint: GET - /v1/connectionmgmt/connection/pki/{connectionid}/test
+	//
+	// Description: Test connectivity of specified PKIConnection resource. Short lived certificate is issued through
+	// role of connection for a name it allows, verified against CA of connection and revoked right away.
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: connectionid
+	//   in: query
+	//   description: id for PKIConnection resource to be tested. expected to be in uuid format i.e. XXXXXXXX-XXXX-XXXX-XXXX-XXXXXXXXXXXX
+	//   required: true
+	//   type: string
+	// - name: role_name
+	//   in: query
+	//   description: name of role to be tested. default role of connection is tested if not specified. Test status of connection is only updated for default role. Outcome of every test is kept in test history of connection.
+	//   required: false
+	//   type: string
+	// responses:
+	//   '200':
+	//     description: Connectivity test status
+	//     schema:
+	//         "$ref": "#/definitions/TestPKIConnectionResponse"
+	//   '404':
+	//     description: Resource not found. Resources are filtered based on connectiontype = PKIConnectionType. If connectionid of Non-PKIConnection is provided ResourceNotFound error is returned.
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '403':
+	//     description: Caller lacks operator role on connection
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '500':
+	//     description: Internal server error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   default:
+	//     description: unexpected error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	ctx, span, requestID, cl := utilities.SetupTraceAndLogger(r, w, h.l, utilities.GetFunctionName(), h.cfg.Server.PrefixMain)
+	defer span.End()
+
+	connection, roleName, result, err := h.runTest(ctx, cl, requestID, r, &w, span)
+	if err != nil {
+		return
+	}
+
+	var response data.TestPKIConnectionResponse
+	response.ID = connection.ID.String()
+	response.RoleName = roleName
+	response.TestStatus = result.TestError
+	response.TestStatusCode = result.TestSuccessful
+
+	utilities.WriteResponse(w, cl, response, span)
+}
+
+func (h *PKIConnectionHandler) UpdatePKIConnection(w http.ResponseWriter, r *http.Request) {
+
+	// swagger:operation PATCH /connection/pki PKIConnection UpdatePKIConnection
+	// Update PKI Connection
+	//
+	// Endpoint: PATCH - /v1/connectionmgmt/connection/pki/{connectionid}
+	//
+	// Description: Update attributes of PKIConnection resource. Role is reconfigured in place and CA is kept, so
+	// certificates issued before update remain valid until they expire. Update operation resets Tested status of
+	// PKIConnection.
+	//
+	// ---
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: connectionid
+	//   in: query
+	//   description: id for PKIConnection resource to be updated. expected to be in uuid format i.e. XXXXXXXX-XXXX-XXXX-XXXX-XXXXXXXXXXXX
+	//   required: true
+	//   type: string
+	// - in: body
+	//   name: Body
+	//   description: JSON string defining PKIConnection resource. Change of connectiontype, ID and CA attributes is not allowed.
+	//   required: true
+	//   schema:
+	//     "$ref": "#/definitions/PKIConnectionPatchWrapper"
+	// responses:
+	//   '200':
+	//     description: PKIConnection resource after updates.
+	//     schema:
+	//         "$ref": "#/definitions/PKIConnection"
+	//   '400':
+	//     description: Bad request or parameters
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '403':
+	//     description: Caller lacks admin role on connection
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   default:
+	//     description: unexpected error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	ctx, span, requestid, cl := utilities.SetupTraceAndLogger(r, w, h.l, utilities.GetFunctionName(), h.cfg.Server.PrefixMain)
+	defer span.End()
+
+	p := r.Context().Value(KeyPKIConnectionPatchParamsRecord{}).(data.PKIConnectionPatchWrapper)
+
+	// Attributes not part of request are kept as configured in Vault
+	connection, err := h.updateConnection(func(c *data.PKIConnection) error {
+		return applyConnectionPatch(c, &c.Connection, p, p.Connection)
+	}, ctx, cl, requestid, r, &w, span)
+	if err != nil {
+		return
+	}
+
+	response, err := connectionResponse[data.PKIConnectionResponseWrapper](connection, cl, requestid, r, &w, span)
+	if err != nil {
+		return
+	}
+
+	utilities.WriteResponse(w, cl, response, span)
+}
+
+// validatePKIRole makes sure role matches names for its allowed domains. Vault would otherwise accept role and
+// refuse every certificate on issuance.
+func validatePKIRole(c *data.PKIRole, cl *slog.Logger, requestid string, r *http.Request, w http.ResponseWriter, span trace.Span) error {
+	if !data.IsValidPKIRoleName(c.RoleName) {
+		helper.ReturnError(cl, http.StatusBadRequest, helper.ErrorInvalidRoleName, helper.ErrorDictionary[helper.ErrorInvalidRoleName].Error(), requestid, r, &w, span)
+		return fmt.Errorf("invalid role name")
+	}
+
+	if !c.AllowsIssuance() {
+		helper.ReturnError(cl, http.StatusBadRequest, helper.ErrorInvalidPKIDomains, helper.ErrorDictionary[helper.ErrorInvalidPKIDomains].Error(), requestid, r, &w, span)
+		return fmt.Errorf("invalid pki domains")
+	}
+
+	return nil
+}
+
+// getPKIIssuer returns PKIConnection whose CA signs intermediate CA of connection. Intermediate CA requires
+// existing issuer, while root CA is self signed and must not reference one.
+func (h *PKIConnectionHandler) getPKIIssuer(c *data.PKIConnection, cl *slog.Logger, requestid string, r *http.Request, w http.ResponseWriter, span trace.Span) (*data.PKIConnection, error) {
+	if c.CAType == data.PKICATypeRoot {
+		if c.IssuerConnectionID != "" {
+			helper.ReturnError(cl, http.StatusBadRequest, helper.ErrorInvalidPKIIssuer, helper.ErrorDictionary[helper.ErrorInvalidPKIIssuer].Error(), requestid, r, &w, span)
+			return nil, fmt.Errorf("invalid pki issuer")
+		}
+		return nil, nil
+	}
+
+	if c.IssuerConnectionID == "" {
+		helper.ReturnError(cl, http.StatusBadRequest, helper.ErrorInvalidPKIIssuer, helper.ErrorDictionary[helper.ErrorInvalidPKIIssuer].Error(), requestid, r, &w, span)
+		return nil, fmt.Errorf("invalid pki issuer")
+	}
+
+	var issuer data.PKIConnection
+	result := h.pd.RODB().Preload("Connection").First(&issuer, "id = ?", c.IssuerConnectionID)
+	if result.Error != nil {
+		helper.ReturnError(cl, http.StatusInternalServerError, helper.ErrorDatastoreRetrievalFailed, result.Error, requestid, r, &w, span)
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		helper.ReturnError(cl, http.StatusBadRequest, helper.ErrorInvalidPKIIssuer, helper.ErrorDictionary[helper.ErrorInvalidPKIIssuer].Error(), requestid, r, &w, span)
+		return nil, fmt.Errorf("invalid pki issuer")
+	}
+
+	return &issuer, nil
+}
+
+// DeletePKIConnection deletes a PKIConnection from datastore
+func (h *PKIConnectionHandler) DeletePKIConnection(w http.ResponseWriter, r *http.Request) {
+
+	// swagger:operation DELETE /connection/pki PKIConnection DeletePKIConnection
+	// Delete PKI Connection
+	//
+	// Endpoint: DELETE - /v1/connectionmgmt/connection/pki/{connectionid}
+	//
+	// Description: Deletes PKIConnection resource based on connectionid along with its roles and inventory of its
+	// certificates and disables its secrets engine mount, which destroys CA along with its CRL. Certificates issued
+	// before deletion stay valid until they expire for services still trusting CA. Deletion is refused while
+	// applications are linked to connection unless force is set, in which case applications are unlinked before
+	// connection is deleted.
+	// Deletion is always refused while CA of connection is issuer of intermediate CA of other PKI connections.
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: connectionid
+	//   in: query
+	//   description: id for PKIConnection resource to be deleted. expected to be in uuid format i.e. XXXXXXXX-XXXX-XXXX-XXXX-XXXXXXXXXXXX
+	//   required: true
+	//   type: string
+	// - name: force
+	//   in: query
+	//   description: true to revoke leases and unlink applications before deletion. deletion of linked connection is refused otherwise.
+	//   required: false
+	//   type: boolean
+	// responses:
+	//   '200':
+	//     description: Resource successfully deleted.
+	//     schema:
+	//         "$ref": "#/definitions/DeletePKIConnectionResponse"
+	//   '400':
+	//     description: Issues with parameters or their value
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '409':
+	//     description: Applications are linked to connection or connection is issuer of intermediate PKI connections. Linked applications or intermediate connections are listed in errorAdditionalInfo
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '404':
+	//     description: Resource not found.
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '403':
+	//     description: Caller lacks admin role on connection
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '500':
+	//     description: Internal server error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   default:
+	//     description: unexpected error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	ctx, span, requestid, cl := utilities.SetupTraceAndLogger(r, w, h.l, utilities.GetFunctionName(), h.cfg.Server.PrefixMain)
+	defer span.End()
+
+	if err := h.deleteConnection(ctx, cl, requestid, r, &w, span); err != nil {
+		return
+	}
+
+	var response data.DeletePKIConnectionResponse
+	response.StatusCode = http.StatusOK
+	response.Status = http.StatusText(response.StatusCode)
+
+	utilities.WriteResponse(w, cl, response, span)
+}
+
+// refuseIssuerInUse returns error to caller if CA of connection signed intermediate CA of other PKI connections.
+// force does not override refusal, as intermediate CAs could no longer be verified up to their root.
+func (h *PKIConnectionHandler) refuseIssuerInUse(c *data.PKIConnection, cl *slog.Logger, requestid string, r *http.Request, w *http.ResponseWriter, span trace.Span) error {
+	var intermediates []uuid.UUID
+
+	result := h.pd.RODB().Model(&data.PKIConnection{}).Where("issuer_connection_id = ?", c.ID.String()).Pluck("id", &intermediates)
+	if result.Error != nil {
+		helper.ReturnError(cl, http.StatusInternalServerError, helper.ErrorDatastoreRetrievalFailed, result.Error, requestid, r, w, span)
+		return result.Error
+	}
+
+	if len(intermediates) > 0 {
+		ids := make([]string, 0, len(intermediates))
+		for _, id := range intermediates {
+			ids = append(ids, id.String())
+		}
+
+		err := fmt.Errorf("%w: %s", helper.ErrPKIIssuerInUse, strings.Join(ids, ", "))
+		helper.ReturnError(cl, http.StatusConflict, helper.ErrorPKIIssuerInUse, err, requestid, r, w, span)
+		return err
+	}
+
+	return nil
+}
+
+// deletePKIRecords deletes roles and certificate inventory of connection along with connection in transaction
+// tx. Disabling secrets engine mount destroys CA along with its CRL.
+func (h *PKIConnectionHandler) deletePKIRecords(tx *gorm.DB, c *data.PKIConnection) error {
+	if err := h.deleteRoles(tx, c); err != nil {
+		return err
+	}
+
+	return tx.Where("connection_id = ?", c.ConnectionID).Delete(&data.IssuedCertificate{}).Error
+}
+
+func (h *PKIConnectionHandler) AddPKIConnection(w http.ResponseWriter, r *http.Request) {
+
+	// swagger:operation POST /connection/pki PKIConnection AddPKIConnection
+	// New PKI Connection
+	//
+	// Endpoint: POST - /v1/connectionmgmt/connection/pki
+	//
+	// Description: Create new PKIConnection resource. PKI secrets engine of connection is mounted at vaultpath
+	// in vault_namespace if provided, otherwise path is generated from id of connection and namespace configured for
+	// service is used. Root CA is generated by Vault and self signed, intermediate CA is generated by Vault and signed
+	// by CA of issuer_connectionid. Validity of CA certificate is max_lease_ttl of mount. Issuing CA, CRL distribution
+	// point and OCSP server URLs of issued certificates point to connection management service when its base URL is
+	// configured, otherwise to Vault. Role of connection issues certificates for allowed_domains.
+	//
+	// ---
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - in: body
+	//   name: Body
+	//   description: JSON string defining PKIConnection resource
+	//   required: true
+	//   schema:
+	//     "$ref": "#/definitions/PKIConnectionPostWrapper"
+	// responses:
+	//   '200':
+	//     description: PKIConnection resource just created.
+	//     schema:
+	//         "$ref": "#/definitions/PKIConnection"
+	//   '400':
+	//     description: Bad request or parameters
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '409':
+	//     description: vaultpath is already used by another connection or overlaps with an existing secrets engine mount in vault_namespace
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '403':
+	//     description: Caller lacks admin role on all connections
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '500':
+	//     description: Internal server error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   default:
+	//     description: unexpected error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	ctx, span, requestid, cl := utilities.SetupTraceAndLogger(r, w, h.l, utilities.GetFunctionName(), h.cfg.Server.PrefixMain)
+	defer span.End()
+
+	p := r.Context().Value(KeyPKIConnectionRecord{}).(*data.PKIConnectionPostWrapper)
+
+	c := data.NewPKIConnection(h.cfg)
+
+	if err := h.prepareConnection(c, p, p.Connection, p.VaultPath, p.VaultNamespace, ctx, cl, requestid, r, &w, span); err != nil {
+		return
+	}
+
+	issuer, err := h.getPKIIssuer(c, cl, requestid, r, w, span)
+	if err != nil {
+		return
+	}
+
+	if err := h.createConnection(c, func(ctx context.Context) error {
+		return h.sb.AddPKISecretsEngine(c, issuer, ctx)
+	}, ctx, cl, requestid, r, &w, span); err != nil {
+		return
+	}
+
+	response, err := connectionResponse[data.PKIConnectionResponseWrapper](c, cl, requestid, r, &w, span)
+	if err != nil {
+		return
+	}
+
+	utilities.WriteResponse(w, cl, response, span)
+}
+
+func (h *PKIConnectionHandler) MiddlewareValidatePKIConnectionIssue(next http.Handler) http.Handler {
+	return h.MiddlewareValidateConnectionCreds(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+
+		ctx, span, _, cl := utilities.SetupTraceAndLogger(r, rw, h.l, utilities.GetFunctionName(), h.cfg.Server.PrefixMain)
+		defer span.End()
+
+		payload, valid := utilities.DecodeAndValidate[data.IssuePKIConnectionRequest](r, cl, rw, span)
+		if !valid {
+			return
+		}
+
+		// Add request to context
+		ctx = context.WithValue(ctx, KeyPKIIssueRecord{}, payload)
+		next.ServeHTTP(rw, r.WithContext(ctx))
+	}))
+}
+
+func (h *PKIConnectionHandler) MiddlewareValidatePKIConnectionCertificatesGet(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+
+		_, span, requestid, cl := utilities.SetupTraceAndLogger(r, rw, h.l, utilities.GetFunctionName(), h.cfg.Server.PrefixMain)
+		defer span.End()
+
+		if _, found := utilities.ValidateQueryStringParam("connectionid", r, cl, rw, span); !found {
+			return
+		}
+
+		vars := r.URL.Query()
+
+		// Validate limit parameter
+		if err := utilities.ValidateQueryParam(vars.Get("limit"), 1, true, cl, r, rw, span, requestid, helper.ErrorInvalidValueForLimit); err != nil {
+			return
+		}
+
+		// Validate skip parameter
+		if err := utilities.ValidateQueryParam(vars.Get("skip"), 0, false, cl, r, rw, span, requestid, helper.ErrorInvalidValueForSkip); err != nil {
+			return
+		}
+
+		// Validate expires_within parameter
+		if err := utilities.ValidateDurationParam(vars.Get("expires_within"), cl, r, rw, span, requestid, helper.ErrorInvalidValueForExpiresWithin); err != nil {
+			return
+		}
+
+		// Call the next handler, which can be another middleware in the chain, or the final handler.
+		next.ServeHTTP(rw, r)
+	})
+}
+
+func (h *PKIConnectionHandler) MiddlewareValidatePKIConnectionCertificate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+
+		_, span, _, cl := utilities.SetupTraceAndLogger(r, rw, h.l, utilities.GetFunctionName(), h.cfg.Server.PrefixMain)
+		defer span.End()
+
+		if _, found := utilities.ValidateQueryStringParam("connectionid", r, cl, rw, span); !found {
+			return
+		}
+
+		if _, found := utilities.ValidateQueryStringParam("certificateid", r, cl, rw, span); !found {
+			return
+		}
+
+		// Call the next handler, which can be another middleware in the chain, or the final handler.
+		next.ServeHTTP(rw, r)
+	})
+}
+
+func (h *PKIConnectionHandler) MiddlewareValidatePKIConnectionFormat(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+
+		_, span, requestid, cl := utilities.SetupTraceAndLogger(r, rw, h.l, utilities.GetFunctionName(), h.cfg.Server.PrefixMain)
+		defer span.End()
+
+		if _, found := utilities.ValidateQueryStringParam("connectionid", r, cl, rw, span); !found {
+			return
+		}
+
+		// Validate format parameter
+		switch format := strings.ToLower(r.URL.Query().Get("format")); format {
+		case "", "der", "pem":
+		default:
+			helper.ReturnError(cl, http.StatusBadRequest, helper.ErrorInvalidValueForFormat, fmt.Errorf("invalid format %s", format), requestid, r, &rw, span)
+			return
+		}
+
+		// Call the next handler, which can be another middleware in the chain, or the final handler.
+		next.ServeHTTP(rw, r)
+	})
+}
+
+func (h *PKIConnectionHandler) MiddlewareValidatePKIConnectionOCSP(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+
+		ctx, span, requestid, cl := utilities.SetupTraceAndLogger(r, rw, h.l, utilities.GetFunctionName(), h.cfg.Server.PrefixMain)
+		defer span.End()
+
+		if _, found := utilities.ValidateQueryStringParam("connectionid", r, cl, rw, span); !found {
+			return
+		}
+
+		request, err := io.ReadAll(io.LimitReader(r.Body, maxOCSPRequestSize))
+		if err == nil {
+			_, err = ocsp.ParseRequest(request)
+		}
+
+		// Vault answers malformed requests with a generic error, so they are rejected upfront
+		if err != nil {
+			helper.ReturnError(cl, http.StatusBadRequest, helper.ErrorInvalidOCSPRequest, err, requestid, r, &rw, span)
+			return
+		}
+
+		// Add request to context
+		ctx = context.WithValue(ctx, KeyPKIOCSPRequestRecord{}, request)
+		next.ServeHTTP(rw, r.WithContext(ctx))
+	})
+}
+
+func (h *PKIConnectionHandler) MiddlewareValidatePKIConnectionPost(next http.Handler) http.Handler {
+	return validateConnectionPost[data.PKIConnectionPostWrapper](h.l, h.cfg, KeyPKIConnectionRecord{}, next)
+}
+
+func (h *PKIConnectionHandler) MiddlewareValidatePKIConnectionUpdate(next http.Handler) http.Handler {
+	return validateConnectionPatch[data.PKIConnectionPatchWrapper](h.l, h.cfg, KeyPKIConnectionPatchParamsRecord{}, next)
+}
@@ -0,0 +1,347 @@
+package handlers
+
+import (
+	"DemoServer_ConnectionManager/data"
+	"DemoServer_ConnectionManager/utilities"
+	"net/http"
+)
+
+type KeyPKIRoleRecord struct{}
+type KeyPKIRolePatchParamsRecord struct{}
+
+func (h *PKIConnectionHandler) GetPKIConnectionRoles(w http.ResponseWriter, r *http.Request) {
+
+	// swagger:operation GET /connection/pki/roles PKIRole GetPKIConnectionRoles
+	// List roles of PKI Connection
+	//
+	// Endpoint: GET - /v1/connectionmgmt/connection/pki/{connectionid}/roles
+	//
+	// Description: Returns list of roles which can be used to issue certificates through specified PKIConnection.
+	// Default role of PKIConnection is included in the list.
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: connectionid
+	//   in: query
+	//   description: id for PKIConnection resource. expected to be in uuid format i.e. XXXXXXXX-XXXX-XXXX-XXXX-XXXXXXXXXXXX
+	//   required: true
+	//   type: string
+	// - name: limit
+	//   in: query
+	//   description: maximum number of results to return.
+	//   required: false
+	//   type: integer
+	//   format: int32
+	// - name: skip
+	//   in: query
+	//   description: number of results to be skipped from beginning of list
+	//   required: false
+	//   type: integer
+	//   format: int32
+	// responses:
+	//   '200':
+	//     description: List of PKIRole resources
+	//     schema:
+	//         "$ref": "#/definitions/PKIRolesResponse"
+	//   '404':
+	//     description: Resource not found.
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '403':
+	//     description: Caller lacks viewer role on connection
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '500':
+	//     description: Internal server error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   default:
+	//     description: unexpected error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	ctx, span, requestid, cl := utilities.SetupTraceAndLogger(r, w, h.l, utilities.GetFunctionName(), h.cfg.Server.PrefixMain)
+	defer span.End()
+
+	roles, limit, skip, err := h.listRoles(ctx, cl, requestid, r, &w, span)
+	if err != nil {
+		return
+	}
+
+	response := data.PKIRolesResponse{
+		Total: len(roles),
+		Skip:  skip,
+		Limit: limit,
+		Roles: roles,
+	}
+
+	utilities.WriteResponse(w, cl, response, span)
+}
+
+func (h *PKIConnectionHandler) GetPKIConnectionRole(w http.ResponseWriter, r *http.Request) {
+
+	// swagger:operation GET /connection/pki/roles/{rolename} PKIRole GetPKIConnectionRole
+	// Retrieve role of PKI Connection
+	//
+	// Endpoint: GET - /v1/connectionmgmt/connection/pki/{connectionid}/roles/{rolename}
+	//
+	// Description: Returns PKIRole resource based on connectionid and rolename.
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: connectionid
+	//   in: query
+	//   description: id for PKIConnection resource. expected to be in uuid format i.e. XXXXXXXX-XXXX-XXXX-XXXX-XXXXXXXXXXXX
+	//   required: true
+	//   type: string
+	// - name: rolename
+	//   in: query
+	//   description: name of role to be retrieved.
+	//   required: true
+	//   type: string
+	// responses:
+	//   '200':
+	//     description: PKIRole resource
+	//     schema:
+	//         "$ref": "#/definitions/PKIRole"
+	//   '404':
+	//     description: Resource not found.
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '403':
+	//     description: Caller lacks viewer role on connection
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '500':
+	//     description: Internal server error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   default:
+	//     description: unexpected error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	ctx, span, requestid, cl := utilities.SetupTraceAndLogger(r, w, h.l, utilities.GetFunctionName(), h.cfg.Server.PrefixMain)
+	defer span.End()
+
+	role, err := h.viewRole(ctx, cl, requestid, r, &w, span)
+	if err != nil {
+		return
+	}
+
+	utilities.WriteResponse(w, cl, role, span)
+}
+
+func (h *PKIConnectionHandler) AddPKIConnectionRole(w http.ResponseWriter, r *http.Request) {
+
+	// swagger:operation POST /connection/pki/roles PKIRole AddPKIConnectionRole
+	// New role of PKI Connection
+	//
+	// Endpoint: POST - /v1/connectionmgmt/connection/pki/{connectionid}/roles
+	//
+	// Description: Create new PKIRole resource in PKIConnection's secrets engine. Role issues certificates for its
+	// allowed_domains through CA of connection. Key type of connection is used if key_type is not provided.
+	//
+	// ---
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: connectionid
+	//   in: query
+	//   description: id for PKIConnection resource. expected to be in uuid format i.e. XXXXXXXX-XXXX-XXXX-XXXX-XXXXXXXXXXXX
+	//   required: true
+	//   type: string
+	// - in: body
+	//   name: Body
+	//   description: JSON string defining PKIRole resource
+	//   required: true
+	//   schema:
+	//     "$ref": "#/definitions/PKIRolePostWrapper"
+	// responses:
+	//   '200':
+	//     description: PKIRole resource just created.
+	//     schema:
+	//         "$ref": "#/definitions/PKIRole"
+	//   '400':
+	//     description: Bad request or parameters
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '404':
+	//     description: Resource not found.
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '409':
+	//     description: Role with same name already exists for connection
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '403':
+	//     description: Caller lacks admin role on connection
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '500':
+	//     description: Internal server error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   default:
+	//     description: unexpected error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	ctx, span, requestid, cl := utilities.SetupTraceAndLogger(r, w, h.l, utilities.GetFunctionName(), h.cfg.Server.PrefixMain)
+	defer span.End()
+
+	p := r.Context().Value(KeyPKIRoleRecord{}).(*data.PKIRolePostWrapper)
+
+	role, err := h.addRole(p, ctx, cl, requestid, r, &w, span)
+	if err != nil {
+		return
+	}
+
+	utilities.WriteResponse(w, cl, role, span)
+}
+
+func (h *PKIConnectionHandler) UpdatePKIConnectionRole(w http.ResponseWriter, r *http.Request) {
+
+	// swagger:operation PATCH /connection/pki/roles/{rolename} PKIRole UpdatePKIConnectionRole
+	// Update role of PKI Connection
+	//
+	// Endpoint: PATCH - /v1/connectionmgmt/connection/pki/{connectionid}/roles/{rolename}
+	//
+	// Description: Update attributes of PKIRole resource. Default role of PKIConnection is updated through
+	// PATCH on PKIConnection instead. Certificates issued before update remain valid.
+	//
+	// ---
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: connectionid
+	//   in: query
+	//   description: id for PKIConnection resource. expected to be in uuid format i.e. XXXXXXXX-XXXX-XXXX-XXXX-XXXXXXXXXXXX
+	//   required: true
+	//   type: string
+	// - name: rolename
+	//   in: query
+	//   description: name of role to be updated.
+	//   required: true
+	//   type: string
+	// - in: body
+	//   name: Body
+	//   description: JSON string defining PKIRole attributes to be updated. Change of role_name is not allowed.
+	//   required: true
+	//   schema:
+	//     "$ref": "#/definitions/PKIRolePatchWrapper"
+	// responses:
+	//   '200':
+	//     description: PKIRole resource after updates.
+	//     schema:
+	//         "$ref": "#/definitions/PKIRole"
+	//   '400':
+	//     description: Bad request or parameters
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '403':
+	//     description: Caller lacks admin role on connection
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '404':
+	//     description: Resource not found.
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   default:
+	//     description: unexpected error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	ctx, span, requestid, cl := utilities.SetupTraceAndLogger(r, w, h.l, utilities.GetFunctionName(), h.cfg.Server.PrefixMain)
+	defer span.End()
+
+	p := r.Context().Value(KeyPKIRolePatchParamsRecord{}).(data.PKIRolePatchWrapper)
+
+	role, err := h.updateRole(p, ctx, cl, requestid, r, &w, span)
+	if err != nil {
+		return
+	}
+
+	utilities.WriteResponse(w, cl, role, span)
+}
+
+// DeletePKIConnectionRole deletes a role of PKIConnection from datastore and Vault
+func (h *PKIConnectionHandler) DeletePKIConnectionRole(w http.ResponseWriter, r *http.Request) {
+
+	// swagger:operation DELETE /connection/pki/roles/{rolename} PKIRole DeletePKIConnectionRole
+	// Delete role of PKI Connection
+	//
+	// Endpoint: DELETE - /v1/connectionmgmt/connection/pki/{connectionid}/roles/{rolename}
+	//
+	// Description: Delete PKIRole resource. Default role of PKIConnection can not be deleted. Certificates issued
+	// through role stay valid until they expire or are revoked.
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: connectionid
+	//   in: query
+	//   description: id for PKIConnection resource. expected to be in uuid format i.e. XXXXXXXX-XXXX-XXXX-XXXX-XXXXXXXXXXXX
+	//   required: true
+	//   type: string
+	// - name: rolename
+	//   in: query
+	//   description: name of role to be deleted.
+	//   required: true
+	//   type: string
+	// responses:
+	//   '200':
+	//     description: Resource successfully deleted.
+	//     schema:
+	//         "$ref": "#/definitions/DeletePKIRoleResponse"
+	//   '400':
+	//     description: Default role of connection can not be deleted
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '404':
+	//     description: Resource not found.
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '403':
+	//     description: Caller lacks admin role on connection
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '500':
+	//     description: Internal server error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   default:
+	//     description: unexpected error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	ctx, span, requestid, cl := utilities.SetupTraceAndLogger(r, w, h.l, utilities.GetFunctionName(), h.cfg.Server.PrefixMain)
+	defer span.End()
+
+	if err := h.deleteRole(ctx, cl, requestid, r, &w, span); err != nil {
+		return
+	}
+
+	var response data.DeletePKIRoleResponse
+	response.StatusCode = http.StatusOK
+	response.Status = http.StatusText(response.StatusCode)
+
+	utilities.WriteResponse(w, cl, response, span)
+}
+
+func (h *PKIConnectionHandler) MiddlewareValidatePKIRolePost(next http.Handler) http.Handler {
+	return validateRolePost[data.PKIRolePostWrapper](h.l, h.cfg, KeyPKIRoleRecord{}, next)
+}
+
+func (h *PKIConnectionHandler) MiddlewareValidatePKIRoleUpdate(next http.Handler) http.Handler {
+	return validateRolePatch[data.PKIRolePatchWrapper](h.l, h.cfg, KeyPKIRolePatchParamsRecord{}, next)
+}
@@ -0,0 +1,491 @@
+package handlers
+
+import (
+	"DemoServer_ConnectionManager/auth"
+	"DemoServer_ConnectionManager/configuration"
+	"DemoServer_ConnectionManager/data"
+	"DemoServer_ConnectionManager/datalayer"
+	"DemoServer_ConnectionManager/helper"
+	"DemoServer_ConnectionManager/secretsmanager"
+	"DemoServer_ConnectionManager/utilities"
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+)
+
+// reconcilerUser is recorded as requester of audit records written by reconciler.
+const reconcilerUser = "reconciler"
+
+// ReconcilerHandler compares vault_path of connections of every type against secrets engine mounts of Vault
+// and optionally repairs drift between them.
+type ReconcilerHandler struct {
+	l   *slog.Logger
+	cfg *configuration.Config
+	pd  *datalayer.PostgresDataSource
+	sb  secretsmanager.SecretsBackend
+	pe  *auth.PolicyEngine
+
+	// mu serializes reconciliation passes and guards suspects and report.
+	mu sync.Mutex
+
+	// suspects holds drift observed in previous pass keyed by mount along with time it was first
+	// observed. Drift is only reported once observed in two consecutive passes so that mounts being
+	// created, remounted or deleted by in-flight requests are not mistaken for drift.
+	suspects map[vaultMount]time.Time
+
+	report data.DriftReport
+}
+
+func NewReconcilerHandler(cfg *configuration.Config, l *slog.Logger, pd *datalayer.PostgresDataSource, sb secretsmanager.SecretsBackend, pe *auth.PolicyEngine) (*ReconcilerHandler, error) {
+	var h ReconcilerHandler
+
+	h.cfg = cfg
+	h.l = l
+	h.pd = pd
+	h.sb = sb
+	h.pe = pe
+	h.suspects = map[vaultMount]time.Time{}
+	h.report = data.DriftReport{
+		Repair:        cfg.Reconciler.Repair,
+		MissingMounts: []data.MountDrift{},
+		OrphanMounts:  []data.MountDrift{},
+	}
+
+	return &h, nil
+}
+
+// Run reconciles every Server.WokerSleepTime seconds until ctx is cancelled.
+func (h *ReconcilerHandler) Run(ctx context.Context) {
+	if h.cfg.Server.WokerSleepTime <= 0 {
+		h.l.Warn("Reconciler not started. worker_sleep_time must be greater than 0")
+		return
+	}
+
+	ticker := time.NewTicker(time.Duration(h.cfg.Server.WokerSleepTime) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			_, _ = h.reconcile(ctx, h.cfg.Reconciler.Repair)
+		}
+	}
+}
+
+// vaultMount identifies secrets engine mount by its namespace and path.
+type vaultMount struct {
+	namespace string
+	path      string
+}
+
+// mount returns mount at path in namespace. Namespace configured for service is used when namespace is empty.
+func (h *ReconcilerHandler) mount(namespace string, path string) vaultMount {
+	if namespace == "" {
+		namespace = h.cfg.Vault.Namespace
+	}
+	return vaultMount{namespace: namespace, path: path}
+}
+
+// mountedConnection is connection whose secrets engine is expected to be mounted at its vault_path.
+type mountedConnection struct {
+	ConnectionID   uuid.UUID
+	VaultPath      string
+	VaultNamespace string
+
+	engine string
+}
+
+// connectionEngines lists model of every connection type along with type of secrets engine backing it.
+var connectionEngines = []struct {
+	model  interface{}
+	engine string
+}{
+	{&data.AWSConnection{}, secretsmanager.EngineAWS},
+	{&data.AzureConnection{}, secretsmanager.EngineAzure},
+	{&data.GCPConnection{}, secretsmanager.EngineGCP},
+	{&data.DatabaseConnection{}, secretsmanager.EngineDatabase},
+	{&data.SSHConnection{}, secretsmanager.EngineSSH},
+	{&data.PKIConnection{}, secretsmanager.EnginePKI},
+}
+
+func (h *ReconcilerHandler) fetchVaultPaths() (map[vaultMount]mountedConnection, error) {
+	paths := map[vaultMount]mountedConnection{}
+
+	for _, ce := range connectionEngines {
+		var connections []mountedConnection
+
+		result := h.pd.RODB().Model(ce.model).Select("connection_id", "vault_path", "vault_namespace").Scan(&connections)
+		if result.Error != nil {
+			return nil, result.Error
+		}
+
+		for _, c := range connections {
+			c.engine = ce.engine
+			paths[h.mount(c.VaultNamespace, c.VaultPath)] = c
+		}
+	}
+
+	return paths, nil
+}
+
+// listMounts returns mounts of namespace configured for service and of every namespace used by connections
+// along with type of secrets engine mounted.
+func (h *ReconcilerHandler) listMounts(connections map[vaultMount]mountedConnection, ctx context.Context) (map[vaultMount]string, error) {
+	namespaces := map[string]bool{h.cfg.Vault.Namespace: true}
+	for m := range connections {
+		namespaces[m.namespace] = true
+	}
+
+	mounts := map[vaultMount]string{}
+
+	for namespace := range namespaces {
+		paths, err := h.sb.ListSecretsEngineMounts(namespace, ctx)
+		if err != nil {
+			return nil, fmt.Errorf("namespace %q: %w", namespace, err)
+		}
+
+		for path, engine := range paths {
+			mounts[h.mount(namespace, path)] = engine
+		}
+	}
+
+	return mounts, nil
+}
+
+// reconcile runs single reconciliation pass and returns resulting drift report.
+func (h *ReconcilerHandler) reconcile(ctx context.Context, repair bool) (data.DriftReport, error) {
+
+	tr := otel.Tracer(h.cfg.Server.PrefixWorker)
+	ctx, span := tr.Start(ctx, utilities.GetFunctionName())
+	defer span.End()
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	requestid := uuid.New().String()
+	cl := h.l.With(slog.String("requestid", requestid))
+
+	now := time.Now().UTC()
+	report := data.NewDriftReport(now, repair)
+
+	connections, err := h.fetchVaultPaths()
+	if err != nil {
+		helper.LogError(cl, helper.ErrorDatastoreRetrievalFailed, err, span)
+		report.Error = err.Error()
+		h.report = *report
+		return h.report, err
+	}
+
+	mounts, err := h.listMounts(connections, ctx)
+	if err != nil {
+		helper.LogError(cl, helper.ErrorVaultLoadFailed, err, span)
+		report.Error = err.Error()
+		h.report = *report
+		return h.report, err
+	}
+
+	suspects := map[vaultMount]time.Time{}
+
+	// observe returns time drift at mount was first observed and whether it has been observed before
+	observe := func(m vaultMount) (time.Time, bool) {
+		detectedAt, found := h.suspects[m]
+		if !found {
+			detectedAt = now
+		}
+		suspects[m] = detectedAt
+		return detectedAt, found
+	}
+
+	for m, c := range connections {
+		if _, found := mounts[m]; found {
+			continue
+		}
+
+		detectedAt, confirmed := observe(m)
+		if !confirmed {
+			continue
+		}
+
+		d := data.MountDrift{ConnectionID: c.ConnectionID.String(), Engine: c.engine, VaultPath: m.path, VaultNamespace: m.namespace, DetectedAt: detectedAt}
+
+		if repair {
+			if err := h.repairMissingMount(c, requestid, ctx); err != nil {
+				helper.LogError(cl, helper.ErrorReconciliationFailed, err, span)
+				d.RepairError = err.Error()
+			} else {
+				d.Repaired = true
+				delete(suspects, m)
+			}
+		}
+
+		report.MissingMounts = append(report.MissingMounts, d)
+	}
+
+	for m, engine := range mounts {
+		if _, found := connections[m]; found {
+			continue
+		}
+
+		detectedAt, confirmed := observe(m)
+		if !confirmed {
+			continue
+		}
+
+		d := data.MountDrift{Engine: engine, VaultPath: m.path, VaultNamespace: m.namespace, DetectedAt: detectedAt}
+
+		if repair {
+			if err := h.sb.DisableSecretsEngineMount(m.path, m.namespace, ctx); err != nil {
+				helper.LogError(cl, helper.ErrorReconciliationFailed, err, span)
+				d.RepairError = err.Error()
+			} else {
+				cl.Info("Orphan secrets engine mount disabled", slog.String("vaultpath", m.path), slog.String("vault_namespace", m.namespace))
+				d.Repaired = true
+				delete(suspects, m)
+			}
+		}
+
+		report.OrphanMounts = append(report.OrphanMounts, d)
+	}
+
+	h.suspects = suspects
+	h.report = *report
+
+	return h.report, nil
+}
+
+// repairMissingMount re-creates secrets engine mount of connection. Configuration, roles and CA of secrets engine
+// were lost with the mount and are not kept in datastore, so connection is marked as failed until it is
+// configured again, additional AWS and PKI roles are dropped and tracked leases are marked revoked. Attributes of
+// default AWS role are only kept in Vault, so its row is marked missing from Vault until connection is patched.
+func (h *ReconcilerHandler) repairMissingMount(c mountedConnection, requestid string, ctx context.Context) error {
+
+	tr := otel.Tracer(h.cfg.Server.PrefixWorker)
+	ctx, span := tr.Start(ctx, utilities.GetFunctionName())
+	defer span.End()
+
+	audit := data.NewAuditRecord(requestid, c.ConnectionID, data.RepairMount, reconcilerUser)
+
+	err := h.repairMissingMountWithAudit(c, audit, ctx)
+	if err != nil {
+		recordAuditFailure(h.pd, audit, err, h.l, ctx, h.cfg.Server.PrefixWorker)
+	}
+
+	return err
+}
+
+func (h *ReconcilerHandler) repairMissingMountWithAudit(c mountedConnection, audit *data.AuditRecord, ctx context.Context) error {
+
+	tr := otel.Tracer(h.cfg.Server.PrefixWorker)
+	ctx, span := tr.Start(ctx, utilities.GetFunctionName())
+	defer span.End()
+
+	// Begin a transaction
+	tx := h.pd.RWDB().Begin()
+
+	// Check if the transaction started successfully
+	if tx.Error != nil {
+		return tx.Error
+	}
+
+	var connection data.Connection
+
+	if err := tx.Where("id = ?", c.ConnectionID).First(&connection).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	switch c.engine {
+	case secretsmanager.EngineAWS:
+		var ac data.AWSConnection
+
+		if err := tx.Where("connection_id = ?", c.ConnectionID).First(&ac).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+
+		if err := tx.Where("aws_connection_id = ? AND role_name <> ?", ac.ID, ac.RoleName).Delete(&data.AWSRole{}).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+
+		if err := tx.Model(&data.AWSRole{}).
+			Where("aws_connection_id = ? AND role_name = ?", ac.ID, ac.RoleName).
+			Update("vault_role_missing", true).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+	case secretsmanager.EnginePKI:
+		var pc data.PKIConnection
+
+		if err := tx.Where("connection_id = ?", c.ConnectionID).First(&pc).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+
+		if err := tx.Where("pki_connection_id = ? AND role_name <> ?", pc.ID, pc.RoleName).Delete(&data.PKIRole{}).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	if err := tx.Model(&data.Lease{}).
+		Where("connection_id = ? AND revoked = ?", c.ConnectionID, false).
+		Updates(map[string]interface{}{"revoked": true, "revoked_at": time.Now().UTC()}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// Key pair of CA can not be configured again, unlike root credentials of other secrets engines
+	switch c.engine {
+	case secretsmanager.EngineSSH, secretsmanager.EnginePKI:
+		connection.SetTestFailed(helper.ErrCARequired.Error())
+	default:
+		connection.SetTestFailed(helper.ErrRootCredentialsRequired.Error())
+	}
+
+	if err := utilities.UpdateObjectWithoutTx(tx, &connection, ctx, h.cfg.Server.PrefixWorker); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	audit.SetSuccessful(fmt.Sprintf("%s secrets engine mount %s re-created", c.engine, c.VaultPath))
+
+	if err := utilities.CreateObjectWithoutTx(tx, audit, ctx, h.cfg.Server.PrefixWorker); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := h.sb.EnableSecretsEngineMount(c.VaultPath, c.VaultNamespace, c.engine, ctx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// Commit the transaction
+	if err := tx.Commit().Error; err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+func (h *ReconcilerHandler) GetDrift(w http.ResponseWriter, r *http.Request) {
+
+	// swagger:operation GET /status/drift Status GetDrift
+	// GET - Drift
+	//
+	// Endpoint: GET - /v1/connectionmgmt/status/drift
+	//
+	// Description: Returns drift between connections of every type and Vault secrets engine mounts found by latest
+	// reconciliation. Drift is reported once it is observed in two consecutive reconciliations.
+	//
+	// ---
+	// produces:
+	// - application/json
+	// responses:
+	//   '200':
+	//     description: DriftReport
+	//     schema:
+	//         "$ref": "#/definitions/DriftReport"
+	//   '400':
+	//     description: Unexpected parameter
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '403':
+	//     description: Caller lacks viewer role on all connections
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   default:
+	//     description: unexpected error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	ctx, span, requestid, cl := utilities.SetupTraceAndLogger(r, w, h.l, utilities.GetFunctionName(), h.cfg.Server.PrefixMain)
+	defer span.End()
+
+	// Drift covers mounts of all connections
+	if err := authorize(h.pe, auth.PermissionView, uuid.Nil, ctx, cl, requestid, r, &w, span); err != nil {
+		return
+	}
+
+	h.mu.Lock()
+	response := h.report
+	h.mu.Unlock()
+
+	utilities.WriteResponse(w, cl, response, span)
+}
+
+func (h *ReconcilerHandler) RepairDrift(w http.ResponseWriter, r *http.Request) {
+
+	// swagger:operation POST /status/drift/repair Status RepairDrift
+	// POST - Repair Drift
+	//
+	// Endpoint: POST - /v1/connectionmgmt/status/drift/repair
+	//
+	// Description: Runs reconciliation and repairs confirmed drift regardless of reconciler repair configuration.
+	// Missing secrets engine mounts are re-created and orphan secrets engine mounts are disabled.
+	//
+	// ---
+	// produces:
+	// - application/json
+	// responses:
+	//   '200':
+	//     description: DriftReport
+	//     schema:
+	//         "$ref": "#/definitions/DriftReport"
+	//   '400':
+	//     description: Unexpected parameter
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '403':
+	//     description: Caller lacks admin role on all connections
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '500':
+	//     description: Internal server error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   default:
+	//     description: unexpected error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	ctx, span, requestid, cl := utilities.SetupTraceAndLogger(r, w, h.l, utilities.GetFunctionName(), h.cfg.Server.PrefixMain)
+	defer span.End()
+
+	if err := authorize(h.pe, auth.PermissionUpdate, uuid.Nil, ctx, cl, requestid, r, &w, span); err != nil {
+		return
+	}
+
+	response, err := h.reconcile(ctx, true)
+	if err != nil {
+		helper.ReturnError(cl, http.StatusInternalServerError, helper.ErrorReconciliationFailed, err, requestid, r, &w, span)
+		return
+	}
+
+	utilities.WriteResponse(w, cl, response, span)
+}
+
+func (h *ReconcilerHandler) MiddlewareValidateDrift(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+
+		_, span, requestid, cl := utilities.SetupTraceAndLogger(r, rw, h.l, utilities.GetFunctionName(), h.cfg.Server.PrefixMain)
+		defer span.End()
+
+		// Drift endpoints take no parameters
+		for param := range r.URL.Query() {
+			helper.ReturnError(cl, http.StatusBadRequest, helper.ErrorInvalidParameter, fmt.Errorf("unexpected parameter. parameter: %s", param), requestid, r, &rw, span)
+			return
+		}
+
+		// Call the next handler, which can be another middleware in the chain, or the final handler.
+		next.ServeHTTP(rw, r)
+	})
+}
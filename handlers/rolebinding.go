@@ -0,0 +1,453 @@
+package handlers
+
+import (
+	"DemoServer_ConnectionManager/auth"
+	"DemoServer_ConnectionManager/configuration"
+	"DemoServer_ConnectionManager/data"
+	"DemoServer_ConnectionManager/datalayer"
+	"DemoServer_ConnectionManager/helper"
+	"DemoServer_ConnectionManager/utilities"
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/otel"
+)
+
+type KeyRoleBindingRecord struct{}
+
+type RoleBindingHandler struct {
+	l          *slog.Logger
+	cfg        *configuration.Config
+	pd         *datalayer.PostgresDataSource
+	pe         *auth.PolicyEngine
+	list_limit int
+}
+
+func NewRoleBindingHandler(cfg *configuration.Config, l *slog.Logger, pd *datalayer.PostgresDataSource, pe *auth.PolicyEngine) (*RoleBindingHandler, error) {
+	var c RoleBindingHandler
+
+	c.cfg = cfg
+	c.l = l
+	c.pd = pd
+	c.pe = pe
+	c.list_limit = cfg.Server.ListLimit
+
+	return &c, nil
+}
+
+func (h *RoleBindingHandler) fetchRoleBindings(scope connectionScope, subject string, connectionID string, limit, skip int) ([]data.RoleBinding, error) {
+	var bindings []data.RoleBinding
+
+	q := scope.apply(h.pd.RODB(), "connection_id")
+
+	if subject != "" {
+		q = q.Where("subject = ?", subject)
+	}
+
+	if connectionID != "" {
+		q = q.Where("connection_id = ?", connectionID)
+	}
+
+	result := q.
+		Limit(limit).
+		Offset(skip).
+		Order("subject").
+		Order("created_at").
+		Find(&bindings)
+
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return bindings, nil
+}
+
+// bindingExists tells whether role is already bound to subject in scope of b.
+func (h *RoleBindingHandler) bindingExists(b *data.RoleBinding) (bool, error) {
+	var count int64
+
+	q := h.pd.RODB().Model(&data.RoleBinding{}).Where("subject = ? AND role = ?", b.Subject, b.Role)
+
+	if b.IsGlobal() {
+		q = q.Where("connection_id IS NULL")
+	} else {
+		q = q.Where("connection_id = ?", *b.ConnectionID)
+	}
+
+	if err := q.Count(&count).Error; err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// saveRoleBinding creates or deletes binding along with audit record of change in single transaction.
+func (h *RoleBindingHandler) saveRoleBinding(b *data.RoleBinding, remove bool, a *data.AuditRecord, ctx context.Context) error {
+
+	tr := otel.Tracer(h.cfg.Server.PrefixMain)
+	ctx, span := tr.Start(ctx, utilities.GetFunctionName())
+	defer span.End()
+
+	// Begin a transaction
+	tx := h.pd.RWDB().Begin()
+
+	// Check if the transaction started successfully
+	if tx.Error != nil {
+		return tx.Error
+	}
+
+	var err error
+	if remove {
+		err = utilities.DeleteObjectWithoutTx(tx, b, ctx, h.cfg.Server.PrefixMain)
+	} else {
+		err = utilities.CreateObjectWithoutTx(tx, b, ctx, h.cfg.Server.PrefixMain)
+	}
+
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := utilities.CreateObjectWithoutTx(tx, a, ctx, h.cfg.Server.PrefixMain); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// Commit the transaction
+	if err := tx.Commit().Error; err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+func (h *RoleBindingHandler) GetRoleBindings(w http.ResponseWriter, r *http.Request) {
+
+	// swagger:operation GET /rolebindings RoleBinding GetRoleBindings
+	// List Role Bindings
+	//
+	// Endpoint: GET - /v1/connectionmgmt/rolebindings
+	//
+	// Description: Returns role bindings which grant roles to subjects either on all connections or on single
+	// connection. Bindings scoped to connections are only returned for connections on which caller holds admin role
+	// unless caller holds admin role globally.
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: subject
+	//   in: query
+	//   description: subject to filter on
+	//   required: false
+	//   type: string
+	// - name: connectionid
+	//   in: query
+	//   description: id of generic Connection resource to filter on. expected to be in uuid format i.e. XXXXXXXX-XXXX-XXXX-XXXX-XXXXXXXXXXXX
+	//   required: false
+	//   type: string
+	// - name: limit
+	//   in: query
+	//   description: maximum number of results to return.
+	//   required: false
+	//   type: integer
+	//   format: int32
+	// - name: skip
+	//   in: query
+	//   description: number of results to be skipped from beginning of list
+	//   required: false
+	//   type: integer
+	//   format: int32
+	// responses:
+	//   '200':
+	//     description: List of RoleBinding resources
+	//     schema:
+	//         "$ref": "#/definitions/RoleBindingsResponse"
+	//   '400':
+	//     description: Issues with parameters or their value
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '500':
+	//     description: Internal server error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   default:
+	//     description: unexpected error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	ctx, span, requestid, cl := utilities.SetupTraceAndLogger(r, w, h.l, utilities.GetFunctionName(), h.cfg.Server.PrefixMain)
+	defer span.End()
+
+	scope, err := authorizedConnections(h.pe, auth.PermissionManageBindings, ctx, cl, requestid, r, &w, span)
+	if err != nil {
+		return
+	}
+
+	vars := r.URL.Query()
+	limit := utilities.ParseQueryParam(vars, "limit", h.list_limit, h.cfg.DataLayer.MaxResults)
+	skip := utilities.ParseQueryParam(vars, "skip", 0, math.MaxInt32)
+
+	bindings, err := h.fetchRoleBindings(scope, vars.Get("subject"), vars.Get("connectionid"), limit, skip)
+	if err != nil {
+		helper.ReturnError(cl, http.StatusInternalServerError, helper.ErrorDatastoreRetrievalFailed, err, requestid, r, &w, span)
+		return
+	}
+
+	response := data.RoleBindingsResponse{
+		Total:        len(bindings),
+		Skip:         skip,
+		Limit:        limit,
+		RoleBindings: bindings,
+	}
+
+	if response.RoleBindings == nil {
+		response.RoleBindings = []data.RoleBinding{}
+	}
+
+	utilities.WriteResponse(w, cl, response, span)
+}
+
+func (h *RoleBindingHandler) AddRoleBinding(w http.ResponseWriter, r *http.Request) {
+
+	// swagger:operation POST /rolebinding RoleBinding AddRoleBinding
+	// New Role Binding
+	//
+	// Endpoint: POST - /v1/connectionmgmt/rolebinding
+	//
+	// Description: Grant role to subject on all connections or, if connectionid is provided, on single connection.
+	// Caller has to hold admin role in same scope. Roles are viewer (read connections), operator (additionally test
+	// and link connections), admin (additionally create, update and delete connections, manage leases and manage role
+	// bindings) and credential-consumer (issue credentials only, so that consumer cannot revoke leases or certificates
+	// issued to other applications).
+	//
+	// ---
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - in: body
+	//   name: Body
+	//   description: JSON string defining RoleBinding resource
+	//   required: true
+	//   schema:
+	//     "$ref": "#/definitions/RoleBindingPostWrapper"
+	// responses:
+	//   '200':
+	//     description: RoleBinding resource just created.
+	//     schema:
+	//         "$ref": "#/definitions/RoleBinding"
+	//   '400':
+	//     description: Bad request or parameters
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '403':
+	//     description: Caller lacks admin role in scope of binding
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '404':
+	//     description: Connection not found.
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '409':
+	//     description: Role is already bound to subject in same scope
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '500':
+	//     description: Internal server error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   default:
+	//     description: unexpected error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	ctx, span, requestid, cl := utilities.SetupTraceAndLogger(r, w, h.l, utilities.GetFunctionName(), h.cfg.Server.PrefixMain)
+	defer span.End()
+
+	p := r.Context().Value(KeyRoleBindingRecord{}).(*data.RoleBindingPostWrapper)
+
+	var connectionID *uuid.UUID
+
+	if p.ConnectionID != "" {
+		var connection data.Connection
+
+		result := h.pd.RODB().Limit(1).Find(&connection, "id = ?", p.ConnectionID)
+		if result.Error != nil {
+			helper.ReturnError(cl, http.StatusInternalServerError, helper.ErrorDatastoreRetrievalFailed, result.Error, requestid, r, &w, span)
+			return
+		}
+		if result.RowsAffected == 0 {
+			helper.ReturnError(cl, http.StatusNotFound, helper.ErrorResourceNotFound, helper.ErrorDictionary[helper.ErrorResourceNotFound].Error(), requestid, r, &w, span)
+			return
+		}
+
+		connectionID = &connection.ID
+	}
+
+	b := data.NewRoleBinding(p.Subject, p.Role, connectionID, requester(r))
+
+	if err := authorize(h.pe, auth.PermissionManageBindings, b.AuditConnectionID(), ctx, cl, requestid, r, &w, span); err != nil {
+		return
+	}
+
+	exists, err := h.bindingExists(b)
+	if err != nil {
+		helper.ReturnError(cl, http.StatusInternalServerError, helper.ErrorDatastoreRetrievalFailed, err, requestid, r, &w, span)
+		return
+	}
+	if exists {
+		err = fmt.Errorf("role %s already bound to subject %s", b.Role, b.Subject)
+		helper.ReturnError(cl, http.StatusConflict, helper.ErrorRoleBindingAlreadyExists, err, requestid, r, &w, span)
+		return
+	}
+
+	audit := data.NewAuditRecord(requestid, b.AuditConnectionID(), data.BindRole, requester(r))
+	audit.SetSuccessful(fmt.Sprintf("role %s bound to subject %s", b.Role, b.Subject))
+
+	if err := h.saveRoleBinding(b, false, audit, ctx); err != nil {
+		recordAuditFailure(h.pd, audit, err, cl, ctx, h.cfg.Server.PrefixMain)
+		helper.ReturnError(cl, http.StatusInternalServerError, helper.ErrorDatastoreSaveFailed, err, requestid, r, &w, span)
+		return
+	}
+
+	utilities.WriteResponse(w, cl, b, span)
+}
+
+func (h *RoleBindingHandler) DeleteRoleBinding(w http.ResponseWriter, r *http.Request) {
+
+	// swagger:operation DELETE /rolebinding RoleBinding DeleteRoleBinding
+	// Delete Role Binding
+	//
+	// Endpoint: DELETE - /v1/connectionmgmt/rolebinding/{bindingid}
+	//
+	// Description: Revoke role granted through binding. Caller has to hold admin role in scope of binding.
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: bindingid
+	//   in: query
+	//   description: id of RoleBinding resource to be deleted. expected to be in uuid format i.e. XXXXXXXX-XXXX-XXXX-XXXX-XXXXXXXXXXXX
+	//   required: true
+	//   type: string
+	// responses:
+	//   '200':
+	//     description: RoleBinding resource just deleted.
+	//     schema:
+	//         "$ref": "#/definitions/RoleBinding"
+	//   '403':
+	//     description: Caller lacks admin role in scope of binding
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '404':
+	//     description: Resource not found.
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '500':
+	//     description: Internal server error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   default:
+	//     description: unexpected error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	ctx, span, requestid, cl := utilities.SetupTraceAndLogger(r, w, h.l, utilities.GetFunctionName(), h.cfg.Server.PrefixMain)
+	defer span.End()
+
+	var b data.RoleBinding
+
+	result := h.pd.RODB().Limit(1).Find(&b, "id = ?", mux.Vars(r)["bindingid"])
+	if result.Error != nil {
+		helper.ReturnError(cl, http.StatusInternalServerError, helper.ErrorDatastoreRetrievalFailed, result.Error, requestid, r, &w, span)
+		return
+	}
+	if result.RowsAffected == 0 {
+		helper.ReturnError(cl, http.StatusNotFound, helper.ErrorResourceNotFound, helper.ErrorDictionary[helper.ErrorResourceNotFound].Error(), requestid, r, &w, span)
+		return
+	}
+
+	if err := authorize(h.pe, auth.PermissionManageBindings, b.AuditConnectionID(), ctx, cl, requestid, r, &w, span); err != nil {
+		return
+	}
+
+	audit := data.NewAuditRecord(requestid, b.AuditConnectionID(), data.UnbindRole, requester(r))
+	audit.SetSuccessful(fmt.Sprintf("role %s unbound from subject %s", b.Role, b.Subject))
+
+	if err := h.saveRoleBinding(&b, true, audit, ctx); err != nil {
+		recordAuditFailure(h.pd, audit, err, cl, ctx, h.cfg.Server.PrefixMain)
+		helper.ReturnError(cl, http.StatusInternalServerError, helper.ErrorDatastoreDeleteFailed, err, requestid, r, &w, span)
+		return
+	}
+
+	utilities.WriteResponse(w, cl, b, span)
+}
+
+func (h RoleBindingHandler) MiddlewareValidateRoleBindingsGet(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+
+		_, span, requestid, cl := utilities.SetupTraceAndLogger(r, rw, h.l, utilities.GetFunctionName(), h.cfg.Server.PrefixMain)
+		defer span.End()
+
+		vars := r.URL.Query()
+
+		// Validate limit parameter
+		if err := utilities.ValidateQueryParam(vars.Get("limit"), 1, true, cl, r, rw, span, requestid, helper.ErrorInvalidValueForLimit); err != nil {
+			return
+		}
+
+		// Validate skip parameter
+		if err := utilities.ValidateQueryParam(vars.Get("skip"), 0, false, cl, r, rw, span, requestid, helper.ErrorInvalidValueForSkip); err != nil {
+			return
+		}
+
+		// Validate connectionid parameter
+		if connectionid := vars.Get("connectionid"); connectionid != "" {
+			if _, err := uuid.Parse(connectionid); err != nil {
+				helper.ReturnError(cl, http.StatusBadRequest, helper.ErrorConnectionIDInvalid, err, requestid, r, &rw, span)
+				return
+			}
+		}
+
+		// Call the next handler, which can be another middleware in the chain, or the final handler.
+		next.ServeHTTP(rw, r)
+	})
+}
+
+func (h RoleBindingHandler) MiddlewareValidateRoleBindingPost(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+
+		ctx, span, _, cl := utilities.SetupTraceAndLogger(r, rw, h.l, utilities.GetFunctionName(), h.cfg.Server.PrefixMain)
+		defer span.End()
+
+		payload, valid := utilities.DecodeAndValidate[data.RoleBindingPostWrapper](r, cl, rw, span)
+		if !valid {
+			return
+		}
+
+		ctx = context.WithValue(ctx, KeyRoleBindingRecord{}, payload)
+		next.ServeHTTP(rw, r.WithContext(ctx))
+	})
+}
+
+func (h RoleBindingHandler) MiddlewareValidateRoleBinding(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+
+		_, span, _, cl := utilities.SetupTraceAndLogger(r, rw, h.l, utilities.GetFunctionName(), h.cfg.Server.PrefixMain)
+		defer span.End()
+
+		if _, found := utilities.ValidateQueryStringParam("bindingid", r, cl, rw, span); !found {
+			return
+		}
+
+		// Call the next handler, which can be another middleware in the chain, or the final handler.
+		next.ServeHTTP(rw, r)
+	})
+}
@@ -0,0 +1,246 @@
+package handlers
+
+import (
+	"DemoServer_ConnectionManager/auth"
+	"DemoServer_ConnectionManager/configuration"
+	"DemoServer_ConnectionManager/data"
+	"DemoServer_ConnectionManager/datalayer"
+	"DemoServer_ConnectionManager/helper"
+	"DemoServer_ConnectionManager/utilities"
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/otel"
+)
+
+// saveRootRotationResult saves rotation status of connection and audit record of rotation in single transaction.
+func saveRootRotationResult(pd *datalayer.PostgresDataSource, c *data.AWSConnection, a *data.AuditRecord, ctx context.Context, tracerName string) error {
+
+	tr := otel.Tracer(tracerName)
+	ctx, span := tr.Start(ctx, utilities.GetFunctionName())
+	defer span.End()
+
+	// Begin a transaction
+	tx := pd.RWDB().Begin()
+
+	// Check if the transaction started successfully
+	if tx.Error != nil {
+		return tx.Error
+	}
+
+	// Only rotation status is updated so that concurrent changes to connection are not overwritten
+	if err := tx.Model(c).
+		Select("root_rotation_successful", "root_rotation_error", "root_rotated_on", "last_successful_root_rotation").
+		Updates(c).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := utilities.CreateObjectWithoutTx(tx, a, ctx, tracerName); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// Commit the transaction
+	if err := tx.Commit().Error; err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+func (h *AWSConnectionHandler) RotateRootAWSConnection(w http.ResponseWriter, r *http.Request) {
+
+	// swagger:operation POST /aws/rotate-root AWSConnection RotateRootAWSConnection
+	// Rotate root credentials of AWS Connection
+	//
+	// Endpoint: POST - /v1/connectionmgmt/connection/aws/{connectionid}/rotate-root
+	//
+	// Description: Rotate root credentials of specified AWSConnection resource through Vault. Vault creates new access key
+	// for IAM user of root credentials and deletes the one in use, after which root credentials are only known to Vault.
+	// Outcome of rotation is recorded on connection. Root credentials supplied through PATCH reset rotation status so that
+	// they are rotated on next run of root rotation scheduler if root_rotation_interval is set.
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: connectionid
+	//   in: query
+	//   description: id for AWSConnection resource to be rotated. expected to be in uuid format i.e. XXXXXXXX-XXXX-XXXX-XXXX-XXXXXXXXXXXX
+	//   required: true
+	//   type: string
+	// responses:
+	//   '200':
+	//     description: Root credentials rotated
+	//     schema:
+	//         "$ref": "#/definitions/RotateRootAWSConnectionResponse"
+	//   '404':
+	//     description: Resource not found.
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '403':
+	//     description: Caller lacks admin role on connection
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '500':
+	//     description: Internal server error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   default:
+	//     description: unexpected error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	ctx, span, requestID, cl := utilities.SetupTraceAndLogger(r, w, h.l, utilities.GetFunctionName(), h.cfg.Server.PrefixMain)
+	defer span.End()
+
+	connectionID := mux.Vars(r)["connectionid"]
+	connection, err := h.getConnection(connectionID, cl, requestID, r, &w, span)
+	if err != nil {
+		return
+	}
+
+	if err := authorize(h.pe, auth.PermissionUpdate, connection.ConnectionID, ctx, cl, requestID, r, &w, span); err != nil {
+		return
+	}
+
+	if err := h.rotateRootAWSConnection(connection, requestID, requester(r), cl, ctx, h.cfg.Server.PrefixMain); err != nil {
+		helper.ReturnError(cl, http.StatusInternalServerError, helper.ErrorVaultRootRotationFailed, err, requestID, r, &w, span)
+		return
+	}
+
+	var response data.RotateRootAWSConnectionResponse
+	response.ID = connection.ID.String()
+	response.RootRotatedOn = connection.RootRotatedOn
+	response.RootRotationSuccessful = connection.RootRotationSuccessful
+
+	utilities.WriteResponse(w, cl, response, span)
+}
+
+// rotateRootAWSConnection rotates root credentials of c and records outcome on connection along with audit
+// record. Error of rotation is returned after outcome is recorded.
+func (h *AWSConnectionHandler) rotateRootAWSConnection(c *data.AWSConnection, requestID string, user string, cl *slog.Logger, ctx context.Context, tracerName string) error {
+
+	tr := otel.Tracer(tracerName)
+	ctx, span := tr.Start(ctx, utilities.GetFunctionName())
+	defer span.End()
+
+	audit := data.NewAuditRecord(requestID, c.ConnectionID, data.RotateRoot, user)
+
+	accessKey, rotateErr := h.sb.RotateAWSRootCredentials(c.VaultPath, c.VaultNamespace, ctx)
+	if rotateErr != nil {
+		c.SetRootRotationFailed(rotateErr.Error())
+		audit.SetFailed(rotateErr.Error())
+	} else {
+		c.SetRootRotationPassed()
+		audit.SetSuccessful(fmt.Sprintf("root credentials rotated. new access key %s", accessKey))
+	}
+
+	if err := saveRootRotationResult(h.pd, c, audit, ctx, tracerName); err != nil {
+		helper.LogError(cl, helper.ErrorDatastoreSaveFailed, err, span)
+	}
+
+	return rotateErr
+}
+
+// RootRotationScheduler rotates root credentials of every AWSConnection once its root_rotation_interval has
+// passed since last successful rotation.
+type RootRotationScheduler struct {
+	l   *slog.Logger
+	cfg *configuration.Config
+	pd  *datalayer.PostgresDataSource
+	ah  *AWSConnectionHandler
+}
+
+func NewRootRotationScheduler(cfg *configuration.Config, l *slog.Logger, pd *datalayer.PostgresDataSource, ah *AWSConnectionHandler) (*RootRotationScheduler, error) {
+	var s RootRotationScheduler
+
+	s.cfg = cfg
+	s.l = l
+	s.pd = pd
+	s.ah = ah
+
+	return &s, nil
+}
+
+// Run checks connections for due rotations every RootRotation.Interval seconds until ctx is cancelled. Failed
+// rotations are retried on every run until they succeed.
+func (s *RootRotationScheduler) Run(ctx context.Context) {
+	if s.cfg.RootRotation.Interval <= 0 {
+		s.l.Warn("Root rotation scheduler not started. root_rotation interval must be greater than 0")
+		return
+	}
+
+	ticker := time.NewTicker(time.Duration(s.cfg.RootRotation.Interval) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.rotateConnections(ctx)
+		}
+	}
+}
+
+// rotateConnections rotates root credentials of due connections with at most RootRotation.Concurrency
+// rotations in flight.
+func (s *RootRotationScheduler) rotateConnections(ctx context.Context) {
+
+	tr := otel.Tracer(s.cfg.Server.PrefixWorker)
+	ctx, span := tr.Start(ctx, utilities.GetFunctionName())
+	defer span.End()
+
+	var connections []data.AWSConnection
+
+	result := s.pd.RODB().Preload("Connection").Where("root_rotation_interval > 0").Find(&connections)
+	if result.Error != nil {
+		helper.LogError(s.l, helper.ErrorDatastoreRetrievalFailed, result.Error, span)
+		return
+	}
+
+	concurrency := s.cfg.RootRotation.Concurrency
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+
+	now := time.Now().UTC()
+
+	for i := range connections {
+		if ctx.Err() != nil {
+			break
+		}
+
+		if !connections[i].IsRootRotationDue(now) {
+			continue
+		}
+
+		sem <- struct{}{}
+		wg.Add(1)
+
+		go func(c *data.AWSConnection) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			requestid := uuid.New().String()
+			cl := s.l.With(slog.String("requestid", requestid))
+
+			if err := s.ah.rotateRootAWSConnection(c, requestid, schedulerUser, cl, ctx, s.cfg.Server.PrefixWorker); err != nil {
+				helper.LogError(cl, helper.ErrorVaultRootRotationFailed, err, span)
+			}
+		}(&connections[i])
+	}
+
+	wg.Wait()
+}
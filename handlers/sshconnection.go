@@ -0,0 +1,671 @@
+package handlers
+
+import (
+	"DemoServer_ConnectionManager/auth"
+	"DemoServer_ConnectionManager/configuration"
+	"DemoServer_ConnectionManager/data"
+	"DemoServer_ConnectionManager/datalayer"
+	"DemoServer_ConnectionManager/helper"
+	"DemoServer_ConnectionManager/secretsmanager"
+	"DemoServer_ConnectionManager/utilities"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/crypto/ssh"
+)
+
+type KeySSHConnectionRecord struct{}
+type KeySSHConnectionPatchParamsRecord struct{}
+type KeySSHSignRecord struct{}
+
+type SSHConnectionHandler struct {
+	connectionHandler[data.SSHConnection, *data.SSHConnection]
+}
+
+func NewSSHConnectionHandler(cfg *configuration.Config, l *slog.Logger, pd *datalayer.PostgresDataSource, sb secretsmanager.SecretsBackend, pe *auth.PolicyEngine) (*SSHConnectionHandler, error) {
+	var c SSHConnectionHandler
+
+	c.connectionHandler = newConnectionHandler[data.SSHConnection, *data.SSHConnection](cfg, l, pd, sb, pe, connectionEngine[data.SSHConnection]{
+		table:   "ssh_connections",
+		load:    sb.GetSSHSecretsEngine,
+		mount:   sb.AddSSHSecretsEngine,
+		update:  sb.UpdateSSHSecretsEngine,
+		unmount: sb.RemoveSSHSecretsEngine,
+		test: func(c *data.SSHConnection, roleName string, ctx context.Context) error {
+			// Principals allowed by role are only kept in Vault
+			if err := sb.GetSSHSecretsEngine(c, ctx); err != nil {
+				return err
+			}
+			return sb.TestSSHSecretsEngine(c.VaultPath, c.VaultNamespace, roleName, c.CertType, c.TestPrincipal(), ctx)
+		},
+		mountFailed: helper.ErrorVaultSecretsEngineFailed,
+		validate:    validateSSHConnection,
+	})
+
+	return &c, nil
+}
+
+func (h *SSHConnectionHandler) GetSSHConnections(w http.ResponseWriter, r *http.Request) {
+
+	// swagger:operation GET /connections/ssh SSHConnection GetSSHConnections
+	// List SSH Connections
+	//
+	// Endpoint: GET - /v1/connectionmgmt/connections/ssh
+	//
+	// Description: Returns list of SSHConnection resources. Each SSHConnection resource
+	// contains underlying generic Connection resource as well as SSHConnection
+	// specific attributes. Client secret of connection is never returned.
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: limit
+	//   in: query
+	//   description: maximum number of results to return.
+	//   required: false
+	//   type: integer
+	//   format: int32
+	// - name: skip
+	//   in: query
+	//   description: number of results to be skipped from beginning of list
+	//   required: false
+	//   type: integer
+	//   format: int32
+	// responses:
+	//   '200':
+	//     description: List of SSHConnection resources
+	//     schema:
+	//       type: array
+	//       items:
+	//         "$ref": "#/definitions/SSHConnection"
+	//   '400':
+	//     description: Issues with parameters or their value
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '500':
+	//     description: Internal server error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   default:
+	//     description: unexpected error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	ctx, span, requestid, cl := utilities.SetupTraceAndLogger(r, w, h.l, utilities.GetFunctionName(), h.cfg.Server.PrefixMain)
+	defer span.End()
+
+	connections, limit, skip, err := h.listConnections(ctx, cl, requestid, r, &w, span)
+	if err != nil {
+		return
+	}
+
+	wrapped, err := connectionResponses[data.SSHConnectionResponseWrapper](connections, cl, requestid, r, &w, span)
+	if err != nil {
+		return
+	}
+
+	response := data.SSHConnectionsResponse{
+		Total:          len(connections),
+		Skip:           skip,
+		Limit:          limit,
+		SSHConnections: wrapped,
+	}
+
+	utilities.WriteResponse(w, cl, response, span)
+}
+
+// GetSSHConnection returns SSHConnection resource based on connectionid parameter
+func (h *SSHConnectionHandler) GetSSHConnection(w http.ResponseWriter, r *http.Request) {
+
+	// swagger:operation GET /connection/ssh SSHConnection GetSSHConnection
+	// Retrieve SSH Connection
+	//
+	// Endpoint: GET - /v1/connectionmgmt/connection/ssh/{connectionid}
+	//
+	// Description: Returns SSHConnection resource based on connectionid.
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: connectionid
+	//   in: query
+	//   description: id for SSHConnection resource to be retrieved. expected to be in uuid format i.e. XXXXXXXX-XXXX-XXXX-XXXX-XXXXXXXXXXXX
+	//   required: true
+	//   type: string
+	// responses:
+	//   '200':
+	//     description: SSHConnection resource
+	//     schema:
+	//         "$ref": "#/definitions/SSHConnection"
+	//   '400':
+	//     description: Issues with parameters or their value
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '403':
+	//     description: Caller lacks viewer role on connection
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '404':
+	//     description: Resource not found. Resources are filtered based on connectiontype = SSHConnectionType. If connectionid of Non-SSHConnection is provided ResourceNotFound error is returned.
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '500':
+	//     description: Internal server error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   default:
+	//     description: unexpected error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	ctx, span, requestID, cl := utilities.SetupTraceAndLogger(r, w, h.l, utilities.GetFunctionName(), h.cfg.Server.PrefixMain)
+	defer span.End()
+
+	connection, applications, err := h.viewConnection(ctx, cl, requestID, r, &w, span)
+	if err != nil {
+		return
+	}
+
+	response, err := connectionResponse[data.SSHConnectionResponseWrapper](connection, cl, requestID, r, &w, span)
+	if err != nil {
+		return
+	}
+	response.Connection.Applications = applications
+
+	utilities.WriteResponse(w, cl, response, span)
+}
+
+func (h *SSHConnectionHandler) SignSSHConnection(w http.ResponseWriter, r *http.Request) {
+
+	// swagger:operation POST /connection/ssh/sign SSHConnection SignSSHConnection
+	// Sign SSH Public Key
+	//
+	// Endpoint: POST - /v1/connectionmgmt/connection/ssh/{connectionid}/sign
+	//
+	// Description: Sign public key with CA of specified SSHConnection and return certificate along with its validity
+	// window. Connection has to be tested successfully before it can be used for signing. Certificates are only
+	// issued to application identified by applicationid which has to match identity of caller and be linked to
+	// connection. Certificates are not leased and stay valid until valid_before.
+	//
+	// ---
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: connectionid
+	//   in: query
+	//   description: id for SSHConnection resource to be used for signing. expected to be in uuid format i.e. XXXXXXXX-XXXX-XXXX-XXXX-XXXXXXXXXXXX
+	//   required: true
+	//   type: string
+	// - name: applicationid
+	//   in: query
+	//   description: id of application for which certificate is signed. has to identify caller and be linked to connection. recorded in audit trail.
+	//   required: true
+	//   type: string
+	// - name: X-Requester
+	//   in: header
+	//   description: identity of caller requesting certificate. recorded in audit trail. ignored in favour of subject of bearer token when authentication is enabled.
+	//   required: false
+	//   type: string
+	// - in: body
+	//   name: Body
+	//   description: public key to be signed along with principals and validity of certificate
+	//   required: true
+	//   schema:
+	//     "$ref": "#/definitions/SignSSHConnectionRequest"
+	// responses:
+	//   '200':
+	//     description: Public key signed successfully
+	//     schema:
+	//         "$ref": "#/definitions/SignSSHConnectionResponse"
+	//   '400':
+	//     description: Issues with parameters or their value
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '404':
+	//     description: Resource not found. Resources are filtered based on connectiontype = SSHConnectionType. If connectionid of Non-SSHConnection is provided ResourceNotFound error is returned.
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '403':
+	//     description: Caller lacks credential-consumer role on connection, applicationid does not identify caller, application is not linked to connection or link does not allow role
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '409':
+	//     description: Connection has not been tested successfully yet
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '500':
+	//     description: Internal server error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   default:
+	//     description: unexpected error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	ctx, span, requestID, cl := utilities.SetupTraceAndLogger(r, w, h.l, utilities.GetFunctionName(), h.cfg.Server.PrefixMain)
+	defer span.End()
+
+	p := r.Context().Value(KeySSHSignRecord{}).(*data.SignSSHConnectionRequest)
+
+	grant, err := h.grantCreds(ctx, cl, requestID, r, &w, span)
+	if err != nil {
+		return
+	}
+
+	connection := grant.connection
+
+	response, err := h.sb.SignSSHSecretsEngine(connection.VaultPath, connection.VaultNamespace, grant.roleName, connection.CertType, p, ctx)
+	if err != nil {
+		h.failCreds(grant, err, ctx, cl, requestID, r, &w, span)
+		return
+	}
+
+	response.ConnectionID = connection.ID.String()
+
+	// Certificates are not leased by Vault, so only issuance is recorded
+	issued := fmt.Sprintf("%s certificate %s signed for role %s and principals %s, valid until %s", connection.CertType, response.SerialNumber, response.RoleName, strings.Join(response.ValidPrincipals, ","), response.ValidBefore.Format(time.RFC3339))
+
+	if err := h.recordCreds(grant, nil, issued, ctx, cl, requestID, r, &w, span); err != nil {
+		return
+	}
+
+	utilities.WriteResponse(w, cl, response, span)
+}
+
+// GetSSHConnectionCAPublicKey returns public key of CA of connection in OpenSSH authorized_keys format
+func (h *SSHConnectionHandler) GetSSHConnectionCAPublicKey(w http.ResponseWriter, r *http.Request) {
+
+	// swagger:operation GET /connection/ssh/public_key SSHConnection GetSSHConnectionCAPublicKey
+	// Retrieve SSH CA Public Key
+	//
+	// Endpoint: GET - /v1/connectionmgmt/connection/ssh/{connectionid}/public_key
+	//
+	// Description: Returns public key of CA of SSHConnection as plain text in OpenSSH authorized_keys format, so
+	// that it can be written to TrustedUserCAKeys of hosts or @cert-authority entries of known_hosts as is. Public
+	// key is not secret, so endpoint is served without bearer token.
+	//
+	// ---
+	// produces:
+	// - text/plain
+	// parameters:
+	// - name: connectionid
+	//   in: query
+	//   description: id for SSHConnection resource whose CA public key is retrieved. expected to be in uuid format i.e. XXXXXXXX-XXXX-XXXX-XXXX-XXXXXXXXXXXX
+	//   required: true
+	//   type: string
+	// responses:
+	//   '200':
+	//     description: Public key of CA
+	//     schema:
+	//       type: string
+	//   '404':
+	//     description: Resource not found. Resources are filtered based on connectiontype = SSHConnectionType. If connectionid of Non-SSHConnection is provided ResourceNotFound error is returned.
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '500':
+	//     description: Internal server error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   default:
+	//     description: unexpected error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	ctx, span, requestID, cl := utilities.SetupTraceAndLogger(r, w, h.l, utilities.GetFunctionName(), h.cfg.Server.PrefixMain)
+	defer span.End()
+
+	connectionID := mux.Vars(r)["connectionid"]
+
+	connection, err := h.getConnection(connectionID, cl, requestID, r, &w, span)
+	if err != nil {
+		return
+	}
+
+	publicKey, err := h.sb.GetSSHCAPublicKey(connection.VaultPath, connection.VaultNamespace, ctx)
+	if err != nil {
+		helper.ReturnError(cl, http.StatusInternalServerError, helper.ErrorVaultLoadFailed, err, requestID, r, &w, span)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	if _, err := io.WriteString(w, publicKey+"\n"); err != nil {
+		helper.LogError(cl, helper.ErrorJSONEncodingFailed, err, span)
+	}
+}
+
+func (h *SSHConnectionHandler) TestSSHConnection(w http.ResponseWriter, r *http.Request) {
+
+	// swagger:operation GET /connection/ssh/test SSHConnection TestSSHConnection
+	// Test SSH Connection
+	//
+	// Endpoint: GET - /v1/connectionmgmt/connection/ssh/{connectionid}/test
+	//
+	// Description: Test connectivity of specified SSHConnection resource. Throwaway public key is signed through
+	// role of connection for a principal it allows and certificate is verified against CA of connection.
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: connectionid
+	//   in: query
+	//   description: id for SSHConnection resource to be tested. expected to be in uuid format i.e. XXXXXXXX-XXXX-XXXX-XXXX-XXXXXXXXXXXX
+	//   required: true
+	//   type: string
+	// responses:
+	//   '200':
+	//     description: Connectivity test status
+	//     schema:
+	//         "$ref": "#/definitions/TestSSHConnectionResponse"
+	//   '404':
+	//     description: Resource not found. Resources are filtered based on connectiontype = SSHConnectionType. If connectionid of Non-SSHConnection is provided ResourceNotFound error is returned.
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '403':
+	//     description: Caller lacks operator role on connection
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '500':
+	//     description: Internal server error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   default:
+	//     description: unexpected error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	ctx, span, requestID, cl := utilities.SetupTraceAndLogger(r, w, h.l, utilities.GetFunctionName(), h.cfg.Server.PrefixMain)
+	defer span.End()
+
+	connection, roleName, result, err := h.runTest(ctx, cl, requestID, r, &w, span)
+	if err != nil {
+		return
+	}
+
+	var response data.TestSSHConnectionResponse
+	response.ID = connection.ID.String()
+	response.RoleName = roleName
+	response.TestStatus = result.TestError
+	response.TestStatusCode = result.TestSuccessful
+
+	utilities.WriteResponse(w, cl, response, span)
+}
+
+func (h *SSHConnectionHandler) UpdateSSHConnection(w http.ResponseWriter, r *http.Request) {
+
+	// swagger:operation PATCH /connection/ssh SSHConnection UpdateSSHConnection
+	// Update SSH Connection
+	//
+	// Endpoint: PATCH - /v1/connectionmgmt/connection/ssh/{connectionid}
+	//
+	// Description: Update attributes of SSHConnection resource. Role is reconfigured in place and key pair of CA is
+	// kept, so certificates signed before update remain valid until they expire. Update operation resets Tested
+	// status of SSHConnection.
+	//
+	// ---
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: connectionid
+	//   in: query
+	//   description: id for SSHConnection resource to be updated. expected to be in uuid format i.e. XXXXXXXX-XXXX-XXXX-XXXX-XXXXXXXXXXXX
+	//   required: true
+	//   type: string
+	// - in: body
+	//   name: Body
+	//   description: JSON string defining SSHConnection resource. Change of connectiontype and ID attributes is not allowed.
+	//   required: true
+	//   schema:
+	//     "$ref": "#/definitions/SSHConnectionPatchWrapper"
+	// responses:
+	//   '200':
+	//     description: SSHConnection resource after updates.
+	//     schema:
+	//         "$ref": "#/definitions/SSHConnection"
+	//   '400':
+	//     description: Bad request or parameters
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '403':
+	//     description: Caller lacks admin role on connection
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   default:
+	//     description: unexpected error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	ctx, span, requestid, cl := utilities.SetupTraceAndLogger(r, w, h.l, utilities.GetFunctionName(), h.cfg.Server.PrefixMain)
+	defer span.End()
+
+	p := r.Context().Value(KeySSHConnectionPatchParamsRecord{}).(data.SSHConnectionPatchWrapper)
+
+	// Attributes not part of request are kept as configured in Vault
+	connection, err := h.updateConnection(func(c *data.SSHConnection) error {
+		return applyConnectionPatch(c, &c.Connection, p, p.Connection)
+	}, ctx, cl, requestid, r, &w, span)
+	if err != nil {
+		return
+	}
+
+	response, err := connectionResponse[data.SSHConnectionResponseWrapper](connection, cl, requestid, r, &w, span)
+	if err != nil {
+		return
+	}
+
+	utilities.WriteResponse(w, cl, response, span)
+}
+
+// validateSSHConnection makes sure role of connection allows principals for its certificate type and key pair of
+// CA, if provided, is a matching OpenSSH key pair. Vault would otherwise only refuse key pair on signing.
+func validateSSHConnection(c *data.SSHConnection, cl *slog.Logger, requestid string, r *http.Request, w http.ResponseWriter, span trace.Span) error {
+	if !c.HasPrincipals() {
+		helper.ReturnError(cl, http.StatusBadRequest, helper.ErrorInvalidSSHPrincipals, helper.ErrorDictionary[helper.ErrorInvalidSSHPrincipals].Error(), requestid, r, &w, span)
+		return fmt.Errorf("invalid ssh principals")
+	}
+
+	if c.PrivateKey == "" {
+		return nil
+	}
+
+	signer, err := ssh.ParsePrivateKey([]byte(c.PrivateKey))
+	if err == nil {
+		var public ssh.PublicKey
+		public, _, _, _, err = ssh.ParseAuthorizedKey([]byte(c.PublicKey))
+		if err == nil && string(public.Marshal()) != string(signer.PublicKey().Marshal()) {
+			err = fmt.Errorf("public_key does not match private_key")
+		}
+	}
+
+	if err != nil {
+		helper.ReturnError(cl, http.StatusBadRequest, helper.ErrorInvalidSSHKey, helper.ErrorDictionary[helper.ErrorInvalidSSHKey].Error(), requestid, r, &w, span)
+		return err
+	}
+
+	return nil
+}
+
+// DeleteSSHConnection deletes a SSHConnection from datastore
+func (h *SSHConnectionHandler) DeleteSSHConnection(w http.ResponseWriter, r *http.Request) {
+
+	// swagger:operation DELETE /connection/ssh SSHConnection DeleteSSHConnection
+	// Delete SSH Connection
+	//
+	// Endpoint: DELETE - /v1/connectionmgmt/connection/ssh/{connectionid}
+	//
+	// Description: Deletes SSHConnection resource based on connectionid and disables its secrets engine mount,
+	// which destroys key pair of CA. Certificates signed before deletion stay valid until they expire for hosts
+	// still trusting CA. Deletion is refused while applications are linked to connection unless force is set, in
+	// which case applications are unlinked before connection is deleted.
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: connectionid
+	//   in: query
+	//   description: id for SSHConnection resource to be deleted. expected to be in uuid format i.e. XXXXXXXX-XXXX-XXXX-XXXX-XXXXXXXXXXXX
+	//   required: true
+	//   type: string
+	// - name: force
+	//   in: query
+	//   description: true to revoke leases and unlink applications before deletion. deletion of linked connection is refused otherwise.
+	//   required: false
+	//   type: boolean
+	// responses:
+	//   '200':
+	//     description: Resource successfully deleted.
+	//     schema:
+	//         "$ref": "#/definitions/DeleteSSHConnectionResponse"
+	//   '400':
+	//     description: Issues with parameters or their value
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '409':
+	//     description: Applications are linked to connection. Linked applications are listed in errorAdditionalInfo
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '404':
+	//     description: Resource not found.
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '403':
+	//     description: Caller lacks admin role on connection
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '500':
+	//     description: Internal server error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   default:
+	//     description: unexpected error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	ctx, span, requestid, cl := utilities.SetupTraceAndLogger(r, w, h.l, utilities.GetFunctionName(), h.cfg.Server.PrefixMain)
+	defer span.End()
+
+	if err := h.deleteConnection(ctx, cl, requestid, r, &w, span); err != nil {
+		return
+	}
+
+	var response data.DeleteSSHConnectionResponse
+	response.StatusCode = http.StatusOK
+	response.Status = http.StatusText(response.StatusCode)
+
+	utilities.WriteResponse(w, cl, response, span)
+}
+
+func (h *SSHConnectionHandler) AddSSHConnection(w http.ResponseWriter, r *http.Request) {
+
+	// swagger:operation POST /connection/ssh SSHConnection AddSSHConnection
+	// New SSH Connection
+	//
+	// Endpoint: POST - /v1/connectionmgmt/connection/ssh
+	//
+	// Description: Create new SSHConnection resource. SSH secrets engine of connection is mounted at vaultpath
+	// in vault_namespace if provided and configured as CA with key pair provided or generated by Vault. Otherwise
+	// path is generated from id of connection and namespace configured for service is used. Role of connection
+	// signs either user certificates for allowed_users or host certificates for allowed_domains.
+	//
+	// ---
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - in: body
+	//   name: Body
+	//   description: JSON string defining SSHConnection resource
+	//   required: true
+	//   schema:
+	//     "$ref": "#/definitions/SSHConnectionPostWrapper"
+	// responses:
+	//   '200':
+	//     description: SSHConnection resource just created.
+	//     schema:
+	//         "$ref": "#/definitions/SSHConnection"
+	//   '400':
+	//     description: Bad request or parameters
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '409':
+	//     description: vaultpath is already used by another connection or overlaps with an existing secrets engine mount in vault_namespace
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '403':
+	//     description: Caller lacks admin role on all connections
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '500':
+	//     description: Internal server error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   default:
+	//     description: unexpected error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	ctx, span, requestid, cl := utilities.SetupTraceAndLogger(r, w, h.l, utilities.GetFunctionName(), h.cfg.Server.PrefixMain)
+	defer span.End()
+
+	p := r.Context().Value(KeySSHConnectionRecord{}).(*data.SSHConnectionPostWrapper)
+
+	c := data.NewSSHConnection(h.cfg)
+
+	if err := h.addConnection(c, p, p.Connection, p.VaultPath, p.VaultNamespace, ctx, cl, requestid, r, &w, span); err != nil {
+		return
+	}
+
+	response, err := connectionResponse[data.SSHConnectionResponseWrapper](c, cl, requestid, r, &w, span)
+	if err != nil {
+		return
+	}
+
+	utilities.WriteResponse(w, cl, response, span)
+}
+
+func (h *SSHConnectionHandler) MiddlewareValidateSSHConnectionSign(next http.Handler) http.Handler {
+	return h.MiddlewareValidateConnectionCreds(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+
+		ctx, span, requestid, cl := utilities.SetupTraceAndLogger(r, rw, h.l, utilities.GetFunctionName(), h.cfg.Server.PrefixMain)
+		defer span.End()
+
+		payload, valid := utilities.DecodeAndValidate[data.SignSSHConnectionRequest](r, cl, rw, span)
+		if !valid {
+			return
+		}
+
+		// Vault refuses invalid keys with a generic error, so they are rejected upfront
+		if _, _, _, _, err := ssh.ParseAuthorizedKey([]byte(payload.PublicKey)); err != nil {
+			helper.ReturnError(cl, http.StatusBadRequest, helper.ErrorInvalidSSHKey, err, requestid, r, &rw, span)
+			return
+		}
+
+		// Add request to context
+		ctx = context.WithValue(ctx, KeySSHSignRecord{}, payload)
+		next.ServeHTTP(rw, r.WithContext(ctx))
+	}))
+}
+
+func (h *SSHConnectionHandler) MiddlewareValidateSSHConnectionPost(next http.Handler) http.Handler {
+	return validateConnectionPost[data.SSHConnectionPostWrapper](h.l, h.cfg, KeySSHConnectionRecord{}, next)
+}
+
+func (h *SSHConnectionHandler) MiddlewareValidateSSHConnectionUpdate(next http.Handler) http.Handler {
+	return validateConnectionPatch[data.SSHConnectionPatchWrapper](h.l, h.cfg, KeySSHConnectionPatchParamsRecord{}, next)
+}
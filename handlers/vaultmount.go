@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"DemoServer_ConnectionManager/configuration"
+	"DemoServer_ConnectionManager/data"
+	"DemoServer_ConnectionManager/datalayer"
+	"DemoServer_ConnectionManager/helper"
+	"DemoServer_ConnectionManager/secretsmanager"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// validateVaultMount validates caller supplied path and namespace of secrets engine mount and makes sure that path
+// is neither used by another connection of model's type nor overlaps with an existing mount in namespace. Mounts
+// of connections of other types are caught by the overlap check against Vault.
+func validateVaultMount(pd *datalayer.PostgresDataSource, sb secretsmanager.SecretsBackend, cfg *configuration.Config, model interface{}, path string, namespace string, ctx context.Context, cl *slog.Logger, requestid string, r *http.Request, w http.ResponseWriter, span trace.Span) error {
+	if !data.IsValidVaultNamespace(namespace) {
+		helper.ReturnError(cl, http.StatusBadRequest, helper.ErrorInvalidVaultNamespace, helper.ErrorDictionary[helper.ErrorInvalidVaultNamespace].Error(), requestid, r, &w, span)
+		return fmt.Errorf("invalid vault namespace")
+	}
+
+	if !data.IsValidVaultPath(path, cfg) {
+		helper.ReturnError(cl, http.StatusBadRequest, helper.ErrorInvalidVaultPath, helper.ErrorDictionary[helper.ErrorInvalidVaultPath].Error(), requestid, r, &w, span)
+		return fmt.Errorf("invalid vault path")
+	}
+
+	// Connections created before namespaces were supported use namespace configured for service
+	namespaces := []string{namespace}
+	if namespace == cfg.Vault.Namespace {
+		namespaces = append(namespaces, "")
+	}
+
+	var count int64
+	if err := pd.RODB().Model(model).Where("vault_path = ? AND vault_namespace IN ?", path, namespaces).Count(&count).Error; err != nil {
+		helper.ReturnError(cl, http.StatusInternalServerError, helper.ErrorDatastoreRetrievalFailed, err, requestid, r, &w, span)
+		return err
+	}
+
+	if count > 0 {
+		helper.ReturnError(cl, http.StatusConflict, helper.ErrorVaultPathAlreadyInUse, helper.ErrorDictionary[helper.ErrorVaultPathAlreadyInUse].Error(), requestid, r, &w, span)
+		return fmt.Errorf("vault path already in use")
+	}
+
+	if err := sb.CheckMountPathAvailable(path, namespace, ctx); err != nil {
+		if errors.Is(err, helper.ErrVaultMountPathInUse) {
+			helper.ReturnError(cl, http.StatusConflict, helper.ErrorVaultPathAlreadyInUse, err, requestid, r, &w, span)
+			return err
+		}
+
+		helper.ReturnError(cl, http.StatusInternalServerError, helper.ErrorVaultLoadFailed, err, requestid, r, &w, span)
+		return err
+	}
+
+	return nil
+}
@@ -68,6 +68,114 @@ var (
 
 	//ErrVaultFailToRetrieveAWSEngineRoleName failed to retrieve role name from Vault's AWS secrets engine
 	ErrVaultFailToRetrieveAWSEngineRoleName = errors.New("failed to retrieve role name from AWS Secrets Engine")
+
+	//ErrVaultTTLNotSupportedForCredentialType ttl can only be requested for session_token credentials
+	ErrVaultTTLNotSupportedForCredentialType = errors.New("ttl can only be requested for session_token credential type")
+
+	//ErrVaultUnsupportedCredentialType credential type not supported by Vault's AWS secrets engine
+	ErrVaultUnsupportedCredentialType = errors.New("credential type not supported by AWS Secrets Engine")
+
+	//ErrVaultFailToRenewLease failed to renew lease through Vault
+	ErrVaultFailToRenewLease = errors.New("failed to renew lease")
+
+	//ErrVaultFailToRevokeLease failed to revoke lease through Vault
+	ErrVaultFailToRevokeLease = errors.New("failed to revoke lease")
+
+	//ErrLeaseNotRenewable lease is not renewable
+	ErrLeaseNotRenewable = errors.New("lease is not renewable")
+
+	//ErrLeaseAlreadyRevoked lease has already been revoked
+	ErrLeaseAlreadyRevoked = errors.New("lease has already been revoked")
+
+	//ErrVaultFailToRetrieveAWSEngineRole failed to retrieve role from Vault's AWS secrets engine
+	ErrVaultFailToRetrieveAWSEngineRole = errors.New("failed to retrieve role from AWS Secrets Engine")
+
+	//ErrVaultAWSEngineRoleNotFound role does not exist in Vault's AWS secrets engine
+	ErrVaultAWSEngineRoleNotFound = errors.New("role not found in AWS Secrets Engine")
+
+	//ErrVaultFailToRemoveAWSEngineRole failed to remove role from Vault's AWS secrets engine
+	ErrVaultFailToRemoveAWSEngineRole = errors.New("failed to remove role from AWS Secrets Engine")
+
+	//ErrVaultFailToListSecretsEngines failed to list secrets engine mounts of Vault
+	ErrVaultFailToListSecretsEngines = errors.New("failed to list secrets engine mounts")
+
+	//ErrVaultUnsupportedAuthMethod auth method configured for Vault is not supported
+	ErrVaultUnsupportedAuthMethod = errors.New("unsupported Vault auth method")
+
+	//ErrVaultMountPathInUse secrets engine mount path overlaps with path of an existing mount
+	ErrVaultMountPathInUse = errors.New("path is already used by a secrets engine mount")
+
+	//ErrVaultFailToWrapResponse failed to wrap response through Vault
+	ErrVaultFailToWrapResponse = errors.New("failed to wrap response")
+
+	//ErrVaultFailToRotateAWSRootCredentials failed to rotate root credentials of Vault's AWS secrets engine
+	ErrVaultFailToRotateAWSRootCredentials = errors.New("failed to rotate root credentials of AWS Secrets Engine")
+
+	//ErrRootCredentialsRequired secrets engine mount was re-created and needs to be configured again
+	ErrRootCredentialsRequired = errors.New("secrets engine mount was re-created by reconciler. root credentials and default role need to be configured again through PATCH")
+
+	//ErrCARequired secrets engine mount was re-created and key pair of its CA was lost with previous mount
+	ErrCARequired = errors.New("secrets engine mount was re-created by reconciler. key pair of CA was lost with previous mount, connection needs to be deleted and added again")
+
+	//ErrJWKSLoadFailed failed to load JSON Web Key Set used to verify bearer tokens
+	ErrJWKSLoadFailed = errors.New("failed to load JSON Web Key Set")
+
+	//ErrInvalidToken bearer token could not be verified
+	ErrInvalidToken = errors.New("invalid bearer token")
+
+	//ErrPermissionDenied caller lacks permission for operation
+	ErrPermissionDenied = errors.New("permission denied")
+
+	//ErrAuthorizationRequiresAuthentication authorization is enabled while callers are not authenticated
+	ErrAuthorizationRequiresAuthentication = errors.New("authorization requires authentication to be enabled. callers can not be identified by unverified X-Requester header")
+
+	//ErrAuthenticationRequired request did not carry bearer token
+	ErrAuthenticationRequired = errors.New("bearer token required")
+
+	//ErrApplicationIDRequired credentials were requested without applicationid
+	ErrApplicationIDRequired = errors.New("applicationid is required")
+
+	//ErrApplicationIdentityMismatch applicationid does not identify caller
+	ErrApplicationIdentityMismatch = errors.New("applicationid does not match identity of caller")
+
+	//ErrApplicationNotLinked application is not linked to connection
+	ErrApplicationNotLinked = errors.New("application is not linked to connection")
+
+	//ErrRoleNotAllowedForApplication link of application does not allow role
+	ErrRoleNotAllowedForApplication = errors.New("role is not allowed for application")
+
+	//ErrConnectionLinked connection can not be deleted while applications are linked to it
+	ErrConnectionLinked = errors.New("connection is linked to applications")
+
+	//ErrPKIIssuerInUse PKI connection can not be deleted while its CA is issuer of intermediate CA of other PKI connections
+	ErrPKIIssuerInUse = errors.New("connection is issuer of intermediate PKI connections")
+
+	//ErrVaultFailToEnableSecretsEngine failed to enable Vault secrets engine
+	ErrVaultFailToEnableSecretsEngine = errors.New("failed to enable secrets engine")
+
+	//ErrVaultFailToDisableSecretsEngine failed to disable Vault secrets engine
+	ErrVaultFailToDisableSecretsEngine = errors.New("failed to disable secrets engine")
+
+	//ErrVaultFailToConfigureSecretsEngine failed to write configuration or role of Vault secrets engine
+	ErrVaultFailToConfigureSecretsEngine = errors.New("failed to configure secrets engine")
+
+	//ErrVaultFailToReadSecretsEngine failed to read configuration or role of Vault secrets engine
+	ErrVaultFailToReadSecretsEngine = errors.New("failed to read configuration of secrets engine")
+
+	//ErrVaultFailToGenerateAzureCredentials failed to generate credentials through Vault's Azure secrets engine
+	ErrVaultFailToGenerateAzureCredentials = errors.New("failed to generate credentials through Azure Secrets Engine")
+
+	//ErrVaultFailToGenerateGCPCredentials failed to generate credentials through Vault's GCP secrets engine
+	ErrVaultFailToGenerateGCPCredentials = errors.New("failed to generate credentials through GCP Secrets Engine")
+
+	//ErrVaultFailToGenerateDatabaseCredentials failed to generate credentials through Vault's database secrets engine
+	ErrVaultFailToGenerateDatabaseCredentials = errors.New("failed to generate credentials through Database Secrets Engine")
+
+	//ErrVaultFailToSignSSHKey failed to sign public key through Vault's ssh secrets engine
+	ErrVaultFailToSignSSHKey = errors.New("failed to sign public key through SSH Secrets Engine")
+
+	//ErrVaultFailToIssuePKICertificate failed to issue or revoke certificate through Vault's pki secrets engine
+	ErrVaultFailToIssuePKICertificate = errors.New("failed to issue certificate through PKI Secrets Engine")
 )
 
 // ErrorTypeEnum is the type enum log dictionary for microservice.
@@ -217,6 +325,168 @@ const (
 
 	//ErrorInvalidParameter represents generic invalid parameter error
 	ErrorInvalidParameter
+
+	//ErrorInvalidValueForTTL represents invalid value for ttl parameter
+	ErrorInvalidValueForTTL
+
+	//ErrorVaultCredsGenerationFailed represents credentials generation through Vault failed
+	ErrorVaultCredsGenerationFailed
+
+	//ErrorVaultLeaseRenewFailed represents lease renewal through Vault failed
+	ErrorVaultLeaseRenewFailed
+
+	//ErrorVaultLeaseRevokeFailed represents lease revocation through Vault failed
+	ErrorVaultLeaseRevokeFailed
+
+	//ErrorLeaseNotRenewable represents lease which can not be renewed
+	ErrorLeaseNotRenewable
+
+	//ErrorLeaseAlreadyRevoked represents lease which has already been revoked
+	ErrorLeaseAlreadyRevoked
+
+	//ErrorInvalidValueForIncrement represents invalid value for increment parameter
+	ErrorInvalidValueForIncrement
+
+	//ErrorInvalidRoleARNs represents invalid role arns passed in
+	ErrorInvalidRoleARNs
+
+	//ErrorInvalidPolicyDocument represents invalid policy document passed in
+	ErrorInvalidPolicyDocument
+
+	//ErrorAWSConnectionAttributeNotSupported represents attribute not supported for requested credential type
+	ErrorAWSConnectionAttributeNotSupported
+
+	//ErrorInvalidRoleName represents invalid role name passed in
+	ErrorInvalidRoleName
+
+	//ErrorAWSRoleAlreadyExists represents role with same name already exists for connection
+	ErrorAWSRoleAlreadyExists
+
+	//ErrorAWSRoleDeleteNotAllowed represents attempt to delete default role of connection
+	ErrorAWSRoleDeleteNotAllowed
+
+	//ErrorVaultRoleConfigurationFailed represents failure to configure role in Vault
+	ErrorVaultRoleConfigurationFailed
+
+	//ErrorAWSRoleUpdateNotAllowed represents attempt to update default role of connection through roles endpoint
+	ErrorAWSRoleUpdateNotAllowed
+
+	//ErrorInvalidValueForAction represents invalid value for action parameter
+	ErrorInvalidValueForAction
+
+	//ErrorInvalidValueForStatus represents invalid value for status parameter
+	ErrorInvalidValueForStatus
+
+	//ErrorInvalidValueForTimeRange represents invalid value for from or to parameter
+	ErrorInvalidValueForTimeRange
+
+	//ErrorReconciliationFailed represents failure to reconcile connections with Vault secrets engine mounts
+	ErrorReconciliationFailed
+
+	//ErrorInvalidVaultPath represents invalid path of secrets engine mount passed in
+	ErrorInvalidVaultPath
+
+	//ErrorInvalidVaultNamespace represents invalid Vault namespace passed in
+	ErrorInvalidVaultNamespace
+
+	//ErrorVaultPathAlreadyInUse represents path of secrets engine mount which is already in use
+	ErrorVaultPathAlreadyInUse
+
+	//ErrorInvalidValueForWrapTTL represents invalid value for wrap_ttl parameter
+	ErrorInvalidValueForWrapTTL
+
+	//ErrorVaultResponseWrapFailed represents failure to wrap response through Vault
+	ErrorVaultResponseWrapFailed
+
+	//ErrorVaultRootRotationFailed represents failure to rotate root credentials of AWS secrets engine
+	ErrorVaultRootRotationFailed
+
+	//ErrorInvalidValueForReveal represents invalid value for reveal parameter
+	ErrorInvalidValueForReveal
+
+	//ErrorRevealNotPermitted represents reveal of redacted fields requested without privileged scope
+	ErrorRevealNotPermitted
+
+	//ErrorAuthenticationRequired represents request without bearer token
+	ErrorAuthenticationRequired
+
+	//ErrorInvalidToken represents bearer token which failed verification
+	ErrorInvalidToken
+
+	//ErrorPermissionDenied represents caller without role granting permission for operation
+	ErrorPermissionDenied
+
+	//ErrorRoleBindingAlreadyExists represents role already bound to subject in same scope
+	ErrorRoleBindingAlreadyExists
+
+	//ErrorApplicationIDRequired represents credentials request without applicationid
+	ErrorApplicationIDRequired
+
+	//ErrorApplicationIdentityMismatch represents applicationid which does not identify caller
+	ErrorApplicationIdentityMismatch
+
+	//ErrorApplicationNotLinked represents application which is not linked to connection
+	ErrorApplicationNotLinked
+
+	//ErrorInvalidValueForExpiresAt represents expiry of link which is not in future
+	ErrorInvalidValueForExpiresAt
+
+	//ErrorRoleNotAllowedForApplication represents role which link of application does not allow
+	ErrorRoleNotAllowedForApplication
+
+	//ErrorInvalidValueForEnvironment represents invalid environment parameter
+	ErrorInvalidValueForEnvironment
+
+	//ErrorConnectionLinked represents deletion of connection which applications are still linked to
+	ErrorConnectionLinked
+
+	//ErrorInvalidValueForForce represents invalid force parameter
+	ErrorInvalidValueForForce
+
+	//DebugConnectionTestFailed represents debug message for connection test failed.
+	DebugConnectionTestFailed
+
+	//DebugCredsGenerationFailed represents debug message for creds generation failed.
+	DebugCredsGenerationFailed
+
+	//ErrorVaultSecretsEngineFailed represents failure to enable or configure secrets engine of connection through Vault
+	ErrorVaultSecretsEngineFailed
+
+	//ErrorInvalidAzureRoles represents Azure connection without either azure_roles or application_object_id
+	ErrorInvalidAzureRoles
+
+	//ErrorInvalidGCPCredentials represents GCP connection with credentials which are not a service account key
+	ErrorInvalidGCPCredentials
+
+	//ErrorInvalidGCPRole represents GCP connection missing attributes required by its role type or secret type
+	ErrorInvalidGCPRole
+
+	//ErrorInvalidDatabaseConnectionURL represents database connection url embedding credentials instead of templates
+	ErrorInvalidDatabaseConnectionURL
+
+	//ErrorInvalidSSHPrincipals represents SSH role allowing no principals for its certificate type
+	ErrorInvalidSSHPrincipals
+
+	//ErrorInvalidSSHKey represents SSH key which can not be parsed or CA key pair whose keys do not match
+	ErrorInvalidSSHKey
+
+	//ErrorInvalidPKIIssuer represents intermediate CA without existing PKI connection as issuer or root CA with one
+	ErrorInvalidPKIIssuer
+
+	//ErrorInvalidValueForExpiresWithin represents invalid value for expires_within parameter
+	ErrorInvalidValueForExpiresWithin
+
+	//ErrorInvalidValueForFormat represents invalid value for format parameter
+	ErrorInvalidValueForFormat
+
+	//ErrorInvalidOCSPRequest represents body which is not a DER encoded OCSP request
+	ErrorInvalidOCSPRequest
+
+	//ErrorInvalidPKIDomains represents PKI role whose allowed domains match no name
+	ErrorInvalidPKIDomains
+
+	//ErrorPKIIssuerInUse represents deletion of PKI connection whose CA signed intermediate CA of other PKI connections
+	ErrorPKIIssuerInUse
 )
 
 // Error represent the details of error occurred.
@@ -239,6 +509,8 @@ var ErrorDictionary = map[ErrorTypeEnum]Error{
 	DebugAWSConnectionTestFailed:  {"ConnectionManager_Debug_000001", "AWSConnection Test Failed", ""},
 	DebugDatastoreConnectionUP:    {"ConnectionManager_Debug_000002", "Datastore connection UP", ""},
 	DebugAWSCredsGenerationFailed: {"ConnectionManager_Debug_000003", "AWSConnection Credentials Generation Failed", ""},
+	DebugConnectionTestFailed:     {"ConnectionManager_Debug_000004", "Connection Test Failed", ""},
+	DebugCredsGenerationFailed:    {"ConnectionManager_Debug_000005", "Connection Credentials Generation Failed", ""},
 
 	ErrorNone:                                            {"ConnectionManager_Err_000000", "No error", ""},
 	ErrorConnectionIDInvalid:                             {"ConnectionManager_Err_000001", "ConnectionID is Invalid", ""},
@@ -277,6 +549,59 @@ var ErrorDictionary = map[ErrorTypeEnum]Error{
 	ErrorLinkNotFound:                                    {"ConnectionManager_Err_000036", "application id link to the connection not found", ""},
 	ErrorJSONDecodingFailed:                              {"ConnectionManager_Err_000037", "json decoding failed", ""},
 	ErrorInvalidParameter:                                {"ConnectionManager_Err_000038", "invalid parameter", ""},
+	ErrorInvalidValueForTTL:                              {"ConnectionManager_Err_000039", "Invalid value for ttl parameter", ""},
+	ErrorVaultCredsGenerationFailed:                      {"ConnectionManager_Err_000040", "Failed to generate credentials through Vault", ""},
+	ErrorVaultLeaseRenewFailed:                           {"ConnectionManager_Err_000041", "Failed to renew lease through Vault", ""},
+	ErrorVaultLeaseRevokeFailed:                          {"ConnectionManager_Err_000042", "Failed to revoke lease through Vault", ""},
+	ErrorLeaseNotRenewable:                               {"ConnectionManager_Err_000043", "Lease is not renewable", ""},
+	ErrorLeaseAlreadyRevoked:                             {"ConnectionManager_Err_000044", "Lease has already been revoked", ""},
+	ErrorInvalidValueForIncrement:                        {"ConnectionManager_Err_000045", "Invalid value for increment parameter", ""},
+	ErrorInvalidRoleARNs:                                 {"ConnectionManager_Err_000046", "invalid role arns value", ""},
+	ErrorInvalidPolicyDocument:                           {"ConnectionManager_Err_000047", "invalid policy document value", ""},
+	ErrorAWSConnectionAttributeNotSupported:              {"ConnectionManager_Err_000048", "attribute not supported for credential type", ""},
+	ErrorInvalidRoleName:                                 {"ConnectionManager_Err_000049", "invalid role name value", ""},
+	ErrorAWSRoleAlreadyExists:                            {"ConnectionManager_Err_000050", "role with same name already exists for connection", ""},
+	ErrorAWSRoleDeleteNotAllowed:                         {"ConnectionManager_Err_000051", "default role of connection can not be deleted", ""},
+	ErrorVaultRoleConfigurationFailed:                    {"ConnectionManager_Err_000052", "Failed to configure role through Vault", ""},
+	ErrorAWSRoleUpdateNotAllowed:                         {"ConnectionManager_Err_000053", "default role of connection can only be updated through connection", ""},
+	ErrorInvalidValueForAction:                           {"ConnectionManager_Err_000054", "Invalid value for action parameter", ""},
+	ErrorInvalidValueForStatus:                           {"ConnectionManager_Err_000055", "Invalid value for status parameter", ""},
+	ErrorInvalidValueForTimeRange:                        {"ConnectionManager_Err_000056", "Invalid value for from or to parameter. RFC3339 format expected", ""},
+	ErrorReconciliationFailed:                            {"ConnectionManager_Err_000057", "Failed to reconcile connections with Vault secrets engine mounts", ""},
+	ErrorInvalidVaultPath:                                {"ConnectionManager_Err_000058", "invalid vaultpath value. path has to be under path prefix configured for service", ""},
+	ErrorInvalidVaultNamespace:                           {"ConnectionManager_Err_000059", "invalid vault_namespace value", ""},
+	ErrorVaultPathAlreadyInUse:                           {"ConnectionManager_Err_000060", "vaultpath is already used by another connection or secrets engine mount", ""},
+	ErrorInvalidValueForWrapTTL:                          {"ConnectionManager_Err_000061", "Invalid value for wrap_ttl parameter", ""},
+	ErrorVaultResponseWrapFailed:                         {"ConnectionManager_Err_000062", "Failed to wrap response through Vault. Issued credentials were revoked", ""},
+	ErrorVaultRootRotationFailed:                         {"ConnectionManager_Err_000063", "Failed to rotate root credentials of AWS secrets engine through Vault", ""},
+	ErrorInvalidValueForReveal:                           {"ConnectionManager_Err_000064", "Invalid value for reveal parameter", ""},
+	ErrorRevealNotPermitted:                              {"ConnectionManager_Err_000065", "Reveal of redacted fields requires connectionmgmt:reveal scope", ""},
+	ErrorAuthenticationRequired:                          {"ConnectionManager_Err_000066", "Authentication required. Bearer token missing in Authorization header", ""},
+	ErrorInvalidToken:                                    {"ConnectionManager_Err_000067", "Bearer token is invalid or expired", ""},
+	ErrorPermissionDenied:                                {"ConnectionManager_Err_000068", "Permission denied. Caller has no role granting permission for operation", ""},
+	ErrorRoleBindingAlreadyExists:                        {"ConnectionManager_Err_000069", "Role is already bound to subject in same scope", ""},
+	ErrorApplicationIDRequired:                           {"ConnectionManager_Err_000070", "applicationid is required to issue credentials", ""},
+	ErrorApplicationIdentityMismatch:                     {"ConnectionManager_Err_000071", "applicationid does not match identity of caller", ""},
+	ErrorApplicationNotLinked:                            {"ConnectionManager_Err_000072", "Application is not linked to connection. Credentials are only issued to linked applications", ""},
+	ErrorInvalidValueForExpiresAt:                        {"ConnectionManager_Err_000073", "Invalid value for expiresat. Expiry of link has to be in future", ""},
+	ErrorRoleNotAllowedForApplication:                    {"ConnectionManager_Err_000074", "Role is not allowed for application by its link to connection", ""},
+	ErrorInvalidValueForEnvironment:                      {"ConnectionManager_Err_000075", "Invalid value for environment parameter. One of dev, stage, prod expected", ""},
+	ErrorConnectionLinked:                                {"ConnectionManager_Err_000076", "Connection is linked to applications. Unlink applications or delete with force=true", ""},
+	ErrorInvalidValueForForce:                            {"ConnectionManager_Err_000077", "Invalid value for force parameter", ""},
+	ErrorVaultSecretsEngineFailed:                        {"ConnectionManager_Err_000078", "Failed to enable or configure secrets engine through Vault", ""},
+	ErrorInvalidAzureRoles:                               {"ConnectionManager_Err_000079", "Either azure_roles or application_object_id is required, not both", ""},
+	ErrorInvalidGCPCredentials:                           {"ConnectionManager_Err_000080", "credentials has to be JSON key of a service account", ""},
+	ErrorInvalidGCPRole:                                  {"ConnectionManager_Err_000081", "project and bindings are required for roleset, service_account_email for static_account and token_scopes for access_token", ""},
+	ErrorInvalidDatabaseConnectionURL:                    {"ConnectionManager_Err_000082", "connection_url has to reference credentials through {{username}} and {{password}} templates", ""},
+	ErrorInvalidSSHPrincipals:                            {"ConnectionManager_Err_000083", "allowed_users are required for user certificates and allowed_domains for host certificates", ""},
+	ErrorInvalidSSHKey:                                   {"ConnectionManager_Err_000084", "key is not a valid OpenSSH key or private_key does not match public_key", ""},
+	ErrorInvalidPKIIssuer:                                {"ConnectionManager_Err_000085", "issuer_connectionid has to reference existing PKI connection for intermediate CA and is not allowed for root CA", ""},
+	ErrorInvalidValueForExpiresWithin:                    {"ConnectionManager_Err_000086", "Invalid value for expires_within parameter. Duration i.e. 720h expected", ""},
+	ErrorInvalidValueForFormat:                           {"ConnectionManager_Err_000087", "Invalid value for format parameter. One of der, pem expected", ""},
+	ErrorInvalidOCSPRequest:                              {"ConnectionManager_Err_000088", "Body has to be DER encoded OCSP request", ""},
+	ErrorInvalidPKIDomains:                               {"ConnectionManager_Err_000089", "allow_subdomains or allow_bare_domains is required for certificates to be issued for allowed_domains", ""},
+	ErrorPKIIssuerInUse:                                  {"ConnectionManager_Err_000090", "CA of connection signed intermediate CA of other PKI connections. Delete intermediate connections first", ""},
+	ErrorVaultDeleteFailed:                               {"ConnectionManager_Err_000091", "Failed to disable secrets engine through Vault. Connection was not deleted", ""},
 }
 
 // ErrorResponse represents information returned by Microservice endpoints in case that was an error
@@ -336,7 +661,7 @@ func GetErrorResponse(status int, err ErrorTypeEnum, r *http.Request, requestid
 		Status:              status,
 		ErrorCode:           ErrorDictionary[err].Code,
 		ErrorDescription:    ErrorDictionary[err].Description,
-		ErrorAdditionalInfo: e.Error(),
+		ErrorAdditionalInfo: Scrub(e.Error()),
 		ErrorHelp:           ErrorDictionary[err].Help,
 		Endpoint:            r.URL.EscapedPath(),
 		Method:              r.Method,
@@ -348,14 +673,14 @@ func LogDebug(cl *slog.Logger, err ErrorTypeEnum, e error, span trace.Span) {
 	cl.Debug(ErrorDictionary[err].Description,
 		slog.String("code", ErrorDictionary[err].Code),
 		slog.String("description", ErrorDictionary[err].Description),
-		slog.String("originalError", e.Error()))
+		slog.String("originalError", Scrub(e.Error())))
 
 	if cl.Handler().Enabled(context.Background(), slog.LevelDebug) {
 		span.AddEvent(ErrorDictionary[err].Description, trace.WithAttributes(
 			attribute.String("level", "debug"),
 			attribute.String("code", ErrorDictionary[err].Code),
 			attribute.String("description", ErrorDictionary[err].Description),
-			attribute.String("originalError", e.Error()),
+			attribute.String("originalError", Scrub(e.Error())),
 		))
 	}
 }
@@ -365,14 +690,14 @@ func LogError(cl *slog.Logger, err ErrorTypeEnum, e error, span trace.Span) {
 	cl.Error(ErrorDictionary[err].Description,
 		slog.String("code", ErrorDictionary[err].Code),
 		slog.String("description", ErrorDictionary[err].Description),
-		slog.String("originalError", e.Error()))
+		slog.String("originalError", Scrub(e.Error())))
 
 	if cl.Handler().Enabled(context.Background(), slog.LevelError) {
 		span.AddEvent(ErrorDictionary[err].Description, trace.WithAttributes(
 			attribute.String("level", "info"),
 			attribute.String("code", ErrorDictionary[err].Code),
 			attribute.String("description", ErrorDictionary[err].Description),
-			attribute.String("originalError", e.Error()),
+			attribute.String("originalError", Scrub(e.Error())),
 		))
 	}
 }
@@ -382,19 +707,20 @@ func LogInfo(cl *slog.Logger, err ErrorTypeEnum, e error, span trace.Span) {
 	cl.Info(ErrorDictionary[err].Description,
 		slog.String("code", ErrorDictionary[err].Code),
 		slog.String("description", ErrorDictionary[err].Description),
-		slog.String("originalError", e.Error()))
+		slog.String("originalError", Scrub(e.Error())))
 
 	if cl.Handler().Enabled(context.Background(), slog.LevelInfo) {
 		span.AddEvent(ErrorDictionary[err].Description, trace.WithAttributes(
 			attribute.String("level", "info"),
 			attribute.String("code", ErrorDictionary[err].Code),
 			attribute.String("description", ErrorDictionary[err].Description),
-			attribute.String("originalError", e.Error()),
+			attribute.String("originalError", Scrub(e.Error())),
 		))
 	}
 }
 
-// ReturnError prepares error json to be returned to caller with additional context.
+// ReturnError prepares error json to be returned to caller with additional context. Response is written with
+// HTTP status reported in error json.
 func ReturnError(cl *slog.Logger, status int, err ErrorTypeEnum, internalError error, requestid string, r *http.Request, rw *http.ResponseWriter, span trace.Span) {
 	LogError(cl, err, internalError, span)
 
@@ -405,7 +731,7 @@ func ReturnError(cl *slog.Logger, status int, err ErrorTypeEnum, internalError e
 		requestid,
 		internalError)
 
-	http.Error(*rw, "", http.StatusBadRequest)
+	http.Error(*rw, "", status)
 
 	e := json.NewEncoder(*rw).Encode(errorResponse)
 
@@ -0,0 +1,32 @@
+package helper
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestReturnError_StatusMatchesBody(t *testing.T) {
+	cl := slog.New(slog.NewTextHandler(io.Discard, nil))
+	req := httptest.NewRequest(http.MethodDelete, "/v1/connectionmgmt/connection/aws/1", nil)
+
+	rec := httptest.NewRecorder()
+	var w http.ResponseWriter = rec
+
+	ReturnError(cl, http.StatusInternalServerError, ErrorVaultDeleteFailed, errors.New("mount not disabled"), "request-1", req, &w, trace.SpanFromContext(context.Background()))
+
+	require.Equal(t, http.StatusInternalServerError, rec.Code)
+
+	var response ErrorResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	require.Equal(t, http.StatusInternalServerError, response.Status)
+	require.Equal(t, "ConnectionManager_Err_000091", response.ErrorCode)
+}
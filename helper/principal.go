@@ -0,0 +1,49 @@
+package helper
+
+import (
+	"context"
+	"net/http"
+)
+
+// Principal represents caller authenticated through bearer token.
+type Principal struct {
+	// Subject sub claim of token identifying caller
+	Subject string
+
+	// Issuer iss claim of token
+	Issuer string
+
+	// Scopes granted to caller through scope or scp claim of token
+	Scopes []string
+
+	// Claims all claims of token
+	Claims map[string]interface{}
+}
+
+// HasScope tells whether scope was granted to principal.
+func (p *Principal) HasScope(scope string) bool {
+	for _, s := range p.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// Claim returns value of string claim of token, or empty string if claim is missing or not a string.
+func (p *Principal) Claim(name string) string {
+	v, _ := p.Claims[name].(string)
+	return v
+}
+
+// WithPrincipal returns copy of r with principal stored in its context.
+func WithPrincipal(r *http.Request, p *Principal) *http.Request {
+	ctx := context.WithValue(r.Context(), ContextKeyPrincipal{}, p)
+	return r.WithContext(ctx)
+}
+
+// PrincipalFromContext returns principal stored in ctx by authentication middleware, if any.
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(ContextKeyPrincipal{}).(*Principal)
+	return p, ok && p != nil
+}
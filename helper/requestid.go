@@ -11,6 +11,9 @@ import (
 // ContextKeyRequestLogger used for indexing in HTTP request context.
 type ContextKeyRequestLogger struct{}
 
+// ContextKeyPrincipal used for indexing authenticated Principal in HTTP request context.
+type ContextKeyPrincipal struct{}
+
 // PrepareContext used to prepare X-Request-Id tag in HTTP response and provides context aware logger.
 func PrepareContext(r *http.Request, rw *http.ResponseWriter, l *slog.Logger) (string, *slog.Logger) {
 	requestid := r.Header.Get("X-Request-Id")
@@ -42,7 +45,9 @@ func PrepareContext(r *http.Request, rw *http.ResponseWriter, l *slog.Logger) (s
 			"endpoint", r.URL.EscapedPath(),
 			"method", r.Method)
 
-		cl := l.With(logAttrGroup)
+		// Values such as access keys which end up in errors of Vault are masked in request logs. Logger of service
+		// already scrubs records, so it is only wrapped if it was built elsewhere.
+		cl := slog.New(NewScrubbingHandler(l.Handler())).With(logAttrGroup)
 
 		ctx := context.WithValue(r.Context(), ContextKeyRequestLogger{}, cl)
 		(*r) = *r.WithContext(ctx)
@@ -13,9 +13,11 @@ import (
 	"syscall"
 	"time"
 
+	"DemoServer_ConnectionManager/auth"
 	"DemoServer_ConnectionManager/configuration"
 	"DemoServer_ConnectionManager/datalayer"
 	"DemoServer_ConnectionManager/handlers"
+	"DemoServer_ConnectionManager/helper"
 	"DemoServer_ConnectionManager/otel"
 	"DemoServer_ConnectionManager/secretsmanager"
 
@@ -73,7 +75,8 @@ func main() {
 		},*/
 	}
 
-	sl := slog.New(slog.NewJSONHandler(w, loggerOpts))
+	// Secrets are masked in every log of service, including startup and worker logs, not only in request logs
+	sl := helper.NewScrubbingJSONLogger(w, loggerOpts)
 
 	logAttrGroup := slog.Group(
 		"common",
@@ -105,6 +108,16 @@ func main() {
 
 	//r.Use(otelmux.Middleware(cfg.Server.PrefixMain))
 
+	if cfg.Auth.Enabled {
+		authn, err := auth.NewAuthenticator(&cfg, l)
+		if err != nil {
+			l.Error("Authenticator initialization failed. Error: " + err.Error())
+			os.Exit(2)
+		}
+
+		r.Use(authn.Middleware)
+	}
+
 	pd, err := datalayer.NewPostgresDataSource(&cfg, l)
 	if err != nil {
 		l.Error("PostgresDataSource initialization failed. Error: " + err.Error())
@@ -117,13 +130,19 @@ func main() {
 		os.Exit(2)
 	}
 
-	vh, err := secretsmanager.NewVaultHandler(&cfg, l)
+	pe, err := auth.NewPolicyEngine(&cfg, l, pd)
+	if err != nil {
+		l.Error("PolicyEngine initialization failed. Error: " + err.Error())
+		os.Exit(2)
+	}
+
+	sb, err := secretsmanager.NewSecretsBackend(&cfg, l)
 	if err != nil {
-		l.Error("Vault Handler initialization failed. Error: " + err.Error())
+		l.Error("Secrets Backend initialization failed. Error: " + err.Error())
 		os.Exit(2)
 	}
 
-	ch, err := handlers.NewConnectionsHandler(&cfg, l, pd)
+	ch, err := handlers.NewConnectionsHandler(&cfg, l, pd, pe)
 	if err != nil {
 		l.Error("Connections Handler initialization failed. Error: " + err.Error())
 		os.Exit(2)
@@ -150,7 +169,17 @@ func main() {
 	cUnlinkRouter.Use(otelhttp.NewMiddleware("POST /connection/unlink"))
 	cUnlinkRouter.Use(ch.MiddlewareValidateConnectionUnlink)
 
-	jch, err := handlers.NewAWSConnectionHandler(&cfg, l, pd, vh)
+	cAppRouter := r.Methods(http.MethodGet).Subrouter()
+	cAppRouter.HandleFunc("/v1/connectionmgmt/applications/{applicationid:[a-fA-F0-9]{8}-[a-fA-F0-9]{4}-4[a-fA-F0-9]{3}-[8|9|aA|bB][a-fA-F0-9]{3}-[a-fA-F0-9]{12}}/connections", ch.GetApplicationConnections)
+	cAppRouter.Use(otelhttp.NewMiddleware("GET /applications/connections"))
+	cAppRouter.Use(ch.MiddlewareValidateApplicationConnectionsGet)
+
+	cTestsRouter := r.Methods(http.MethodGet).Subrouter()
+	cTestsRouter.HandleFunc("/v1/connectionmgmt/connection/{connectionid:[a-fA-F0-9]{8}-[a-fA-F0-9]{4}-4[a-fA-F0-9]{3}-[8|9|aA|bB][a-fA-F0-9]{3}-[a-fA-F0-9]{12}}/tests", ch.GetConnectionTests)
+	cTestsRouter.Use(otelhttp.NewMiddleware("GET /connection/tests"))
+	cTestsRouter.Use(ch.MiddlewareValidateConnectionTestsGet)
+
+	jch, err := handlers.NewAWSConnectionHandler(&cfg, l, pd, sb, pe)
 	if err != nil {
 		l.Error("AWSConnectionHandler initialization failed. Error: " + err.Error())
 		os.Exit(2)
@@ -159,22 +188,78 @@ func main() {
 	jcGetConnectionsRouter := r.Methods(http.MethodGet).Subrouter()
 	jcGetConnectionsRouter.HandleFunc("/v1/connectionmgmt/connections/aws", jch.GetAWSConnections)
 	jcGetConnectionsRouter.Use(otelhttp.NewMiddleware("GET /connections/aws"))
-	jcGetConnectionsRouter.Use(jch.MiddlewareValidateAWSConnectionsGet)
+	jcGetConnectionsRouter.Use(jch.MiddlewareValidateConnectionsGet)
 
 	jcGetRouterWithID := r.Methods(http.MethodGet).Subrouter()
 	jcGetRouterWithID.HandleFunc("/v1/connectionmgmt/connection/aws/{connectionid:[a-fA-F0-9]{8}-[a-fA-F0-9]{4}-4[a-fA-F0-9]{3}-[8|9|aA|bB][a-fA-F0-9]{3}-[a-fA-F0-9]{12}}", jch.GetAWSConnection)
 	jcGetRouterWithID.Use(otelhttp.NewMiddleware("GET /connection/aws"))
-	jcGetRouterWithID.Use(jch.MiddlewareValidateAWSConnection)
+	jcGetRouterWithID.Use(jch.MiddlewareValidateConnection)
 
 	jcTestRouterWithID := r.Methods(http.MethodGet).Subrouter()
 	jcTestRouterWithID.HandleFunc("/v1/connectionmgmt/connection/aws/{connectionid:[a-fA-F0-9]{8}-[a-fA-F0-9]{4}-4[a-fA-F0-9]{3}-[8|9|aA|bB][a-fA-F0-9]{3}-[a-fA-F0-9]{12}}/test", jch.TestAWSConnection)
 	jcTestRouterWithID.Use(otelhttp.NewMiddleware("GET /connection/aws/test"))
-	jcTestRouterWithID.Use(jch.MiddlewareValidateAWSConnection)
+	jcTestRouterWithID.Use(jch.MiddlewareValidateConnection)
 
-	/*jcGenerateCredsRouter := r.Methods(http.MethodGet).Subrouter()
+	jcGenerateCredsRouter := r.Methods(http.MethodGet).Subrouter()
 	jcGenerateCredsRouter.HandleFunc("/v1/connectionmgmt/connection/aws/{connectionid:[a-fA-F0-9]{8}-[a-fA-F0-9]{4}-4[a-fA-F0-9]{3}-[8|9|aA|bB][a-fA-F0-9]{3}-[a-fA-F0-9]{12}}/creds", jch.GenerateCredsAWSConnection)
 	jcGenerateCredsRouter.Use(otelhttp.NewMiddleware("GET /connection/aws/creds"))
-	jcGenerateCredsRouter.Use(jch.MiddlewareValidateAWSConnection)*/
+	jcGenerateCredsRouter.Use(jch.MiddlewareValidateAWSConnectionCreds)
+
+	jcRotateRootRouter := r.Methods(http.MethodPost).Subrouter()
+	jcRotateRootRouter.HandleFunc("/v1/connectionmgmt/connection/aws/{connectionid:[a-fA-F0-9]{8}-[a-fA-F0-9]{4}-4[a-fA-F0-9]{3}-[8|9|aA|bB][a-fA-F0-9]{3}-[a-fA-F0-9]{12}}/rotate-root", jch.RotateRootAWSConnection)
+	jcRotateRootRouter.Use(otelhttp.NewMiddleware("POST /connection/aws/rotate-root"))
+	jcRotateRootRouter.Use(jch.MiddlewareValidateConnection)
+
+	jcGetRolesRouter := r.Methods(http.MethodGet).Subrouter()
+	jcGetRolesRouter.HandleFunc("/v1/connectionmgmt/connection/aws/{connectionid:[a-fA-F0-9]{8}-[a-fA-F0-9]{4}-4[a-fA-F0-9]{3}-[8|9|aA|bB][a-fA-F0-9]{3}-[a-fA-F0-9]{12}}/roles", jch.GetAWSConnectionRoles)
+	jcGetRolesRouter.Use(otelhttp.NewMiddleware("GET /connection/aws/roles"))
+	jcGetRolesRouter.Use(jch.MiddlewareValidateRolesGet)
+
+	jcGetRoleRouter := r.Methods(http.MethodGet).Subrouter()
+	jcGetRoleRouter.HandleFunc("/v1/connectionmgmt/connection/aws/{connectionid:[a-fA-F0-9]{8}-[a-fA-F0-9]{4}-4[a-fA-F0-9]{3}-[8|9|aA|bB][a-fA-F0-9]{3}-[a-fA-F0-9]{12}}/roles/{rolename:[a-zA-Z0-9_-]+}", jch.GetAWSConnectionRole)
+	jcGetRoleRouter.Use(otelhttp.NewMiddleware("GET /connection/aws/roles/rolename"))
+	jcGetRoleRouter.Use(jch.MiddlewareValidateRole)
+
+	jcPostRoleRouter := r.Methods(http.MethodPost).Subrouter()
+	jcPostRoleRouter.HandleFunc("/v1/connectionmgmt/connection/aws/{connectionid:[a-fA-F0-9]{8}-[a-fA-F0-9]{4}-4[a-fA-F0-9]{3}-[8|9|aA|bB][a-fA-F0-9]{3}-[a-fA-F0-9]{12}}/roles", jch.AddAWSConnectionRole)
+	jcPostRoleRouter.Use(otelhttp.NewMiddleware("POST /connection/aws/roles"))
+	jcPostRoleRouter.Use(jch.MiddlewareValidateAWSRolePost)
+
+	jcPatchRoleRouter := r.Methods(http.MethodPatch).Subrouter()
+	jcPatchRoleRouter.HandleFunc("/v1/connectionmgmt/connection/aws/{connectionid:[a-fA-F0-9]{8}-[a-fA-F0-9]{4}-4[a-fA-F0-9]{3}-[8|9|aA|bB][a-fA-F0-9]{3}-[a-fA-F0-9]{12}}/roles/{rolename:[a-zA-Z0-9_-]+}", jch.UpdateAWSConnectionRole)
+	jcPatchRoleRouter.Use(otelhttp.NewMiddleware("PATCH /connection/aws/roles/rolename"))
+	jcPatchRoleRouter.Use(jch.MiddlewareValidateAWSRoleUpdate)
+
+	jcDeleteRoleRouter := r.Methods(http.MethodDelete).Subrouter()
+	jcDeleteRoleRouter.HandleFunc("/v1/connectionmgmt/connection/aws/{connectionid:[a-fA-F0-9]{8}-[a-fA-F0-9]{4}-4[a-fA-F0-9]{3}-[8|9|aA|bB][a-fA-F0-9]{3}-[a-fA-F0-9]{12}}/roles/{rolename:[a-zA-Z0-9_-]+}", jch.DeleteAWSConnectionRole)
+	jcDeleteRoleRouter.Use(otelhttp.NewMiddleware("DELETE /connection/aws/roles/rolename"))
+	jcDeleteRoleRouter.Use(jch.MiddlewareValidateRole)
+
+	lh, err := handlers.NewLeaseHandler(&cfg, l, pd, sb, pe)
+	if err != nil {
+		l.Error("LeaseHandler initialization failed. Error: " + err.Error())
+		os.Exit(2)
+	}
+
+	lGetLeasesRouter := r.Methods(http.MethodGet).Subrouter()
+	lGetLeasesRouter.HandleFunc("/v1/connectionmgmt/connection/{connectionid:[a-fA-F0-9]{8}-[a-fA-F0-9]{4}-4[a-fA-F0-9]{3}-[8|9|aA|bB][a-fA-F0-9]{3}-[a-fA-F0-9]{12}}/leases", lh.GetConnectionLeases)
+	lGetLeasesRouter.Use(otelhttp.NewMiddleware("GET /connection/leases"))
+	lGetLeasesRouter.Use(lh.MiddlewareValidateLeasesGet)
+
+	lRevokeLeasesRouter := r.Methods(http.MethodPost).Subrouter()
+	lRevokeLeasesRouter.HandleFunc("/v1/connectionmgmt/connection/{connectionid:[a-fA-F0-9]{8}-[a-fA-F0-9]{4}-4[a-fA-F0-9]{3}-[8|9|aA|bB][a-fA-F0-9]{3}-[a-fA-F0-9]{12}}/leases/revoke", lh.RevokeConnectionLeases)
+	lRevokeLeasesRouter.Use(otelhttp.NewMiddleware("POST /connection/leases/revoke"))
+	lRevokeLeasesRouter.Use(lh.MiddlewareValidateLeasesRevoke)
+
+	lRenewLeaseRouter := r.Methods(http.MethodPost).Subrouter()
+	lRenewLeaseRouter.HandleFunc("/v1/connectionmgmt/connection/{connectionid:[a-fA-F0-9]{8}-[a-fA-F0-9]{4}-4[a-fA-F0-9]{3}-[8|9|aA|bB][a-fA-F0-9]{3}-[a-fA-F0-9]{12}}/leases/{leaseid:[a-fA-F0-9]{8}-[a-fA-F0-9]{4}-4[a-fA-F0-9]{3}-[8|9|aA|bB][a-fA-F0-9]{3}-[a-fA-F0-9]{12}}/renew", lh.RenewConnectionLease)
+	lRenewLeaseRouter.Use(otelhttp.NewMiddleware("POST /connection/leases/renew"))
+	lRenewLeaseRouter.Use(lh.MiddlewareValidateLease)
+
+	lRevokeLeaseRouter := r.Methods(http.MethodPost).Subrouter()
+	lRevokeLeaseRouter.HandleFunc("/v1/connectionmgmt/connection/{connectionid:[a-fA-F0-9]{8}-[a-fA-F0-9]{4}-4[a-fA-F0-9]{3}-[8|9|aA|bB][a-fA-F0-9]{3}-[a-fA-F0-9]{12}}/leases/{leaseid:[a-fA-F0-9]{8}-[a-fA-F0-9]{4}-4[a-fA-F0-9]{3}-[8|9|aA|bB][a-fA-F0-9]{3}-[a-fA-F0-9]{12}}/revoke", lh.RevokeConnectionLease)
+	lRevokeLeaseRouter.Use(otelhttp.NewMiddleware("POST /connection/leases/revoke"))
+	lRevokeLeaseRouter.Use(lh.MiddlewareValidateLease)
 
 	jcPostRouter := r.Methods(http.MethodPost).Subrouter()
 	jcPostRouter.HandleFunc("/v1/connectionmgmt/connection/aws", jch.AddAWSConnection)
@@ -189,7 +274,349 @@ func main() {
 	jcDeleteRouter := r.Methods(http.MethodDelete).Subrouter()
 	jcDeleteRouter.HandleFunc("/v1/connectionmgmt/connection/aws/{connectionid:[a-fA-F0-9]{8}-[a-fA-F0-9]{4}-4[a-fA-F0-9]{3}-[8|9|aA|bB][a-fA-F0-9]{3}-[a-fA-F0-9]{12}}", jch.DeleteAWSConnection)
 	jcDeleteRouter.Use(otelhttp.NewMiddleware("DELETE /connection/aws"))
-	jcDeleteRouter.Use(jch.MiddlewareValidateAWSConnection)
+	jcDeleteRouter.Use(jch.MiddlewareValidateConnectionDelete)
+
+	zch, err := handlers.NewAzureConnectionHandler(&cfg, l, pd, sb, pe)
+	if err != nil {
+		l.Error("AzureConnectionHandler initialization failed. Error: " + err.Error())
+		os.Exit(2)
+	}
+
+	zcGetConnectionsRouter := r.Methods(http.MethodGet).Subrouter()
+	zcGetConnectionsRouter.HandleFunc("/v1/connectionmgmt/connections/azure", zch.GetAzureConnections)
+	zcGetConnectionsRouter.Use(otelhttp.NewMiddleware("GET /connections/azure"))
+	zcGetConnectionsRouter.Use(zch.MiddlewareValidateConnectionsGet)
+
+	zcGetRouterWithID := r.Methods(http.MethodGet).Subrouter()
+	zcGetRouterWithID.HandleFunc("/v1/connectionmgmt/connection/azure/{connectionid:[a-fA-F0-9]{8}-[a-fA-F0-9]{4}-4[a-fA-F0-9]{3}-[8|9|aA|bB][a-fA-F0-9]{3}-[a-fA-F0-9]{12}}", zch.GetAzureConnection)
+	zcGetRouterWithID.Use(otelhttp.NewMiddleware("GET /connection/azure"))
+	zcGetRouterWithID.Use(zch.MiddlewareValidateConnection)
+
+	zcTestRouterWithID := r.Methods(http.MethodGet).Subrouter()
+	zcTestRouterWithID.HandleFunc("/v1/connectionmgmt/connection/azure/{connectionid:[a-fA-F0-9]{8}-[a-fA-F0-9]{4}-4[a-fA-F0-9]{3}-[8|9|aA|bB][a-fA-F0-9]{3}-[a-fA-F0-9]{12}}/test", zch.TestAzureConnection)
+	zcTestRouterWithID.Use(otelhttp.NewMiddleware("GET /connection/azure/test"))
+	zcTestRouterWithID.Use(zch.MiddlewareValidateConnection)
+
+	zcGenerateCredsRouter := r.Methods(http.MethodGet).Subrouter()
+	zcGenerateCredsRouter.HandleFunc("/v1/connectionmgmt/connection/azure/{connectionid:[a-fA-F0-9]{8}-[a-fA-F0-9]{4}-4[a-fA-F0-9]{3}-[8|9|aA|bB][a-fA-F0-9]{3}-[a-fA-F0-9]{12}}/creds", zch.GenerateCredsAzureConnection)
+	zcGenerateCredsRouter.Use(otelhttp.NewMiddleware("GET /connection/azure/creds"))
+	zcGenerateCredsRouter.Use(zch.MiddlewareValidateConnectionCreds)
+
+	zcPostRouter := r.Methods(http.MethodPost).Subrouter()
+	zcPostRouter.HandleFunc("/v1/connectionmgmt/connection/azure", zch.AddAzureConnection)
+	zcPostRouter.Use(otelhttp.NewMiddleware("POST /connection/azure"))
+	zcPostRouter.Use(zch.MiddlewareValidateAzureConnectionPost)
+
+	zcPatchRouter := r.Methods(http.MethodPatch).Subrouter()
+	zcPatchRouter.HandleFunc("/v1/connectionmgmt/connection/azure/{connectionid:[a-fA-F0-9]{8}-[a-fA-F0-9]{4}-4[a-fA-F0-9]{3}-[8|9|aA|bB][a-fA-F0-9]{3}-[a-fA-F0-9]{12}}", zch.UpdateAzureConnection)
+	zcPatchRouter.Use(otelhttp.NewMiddleware("PATCH /connection/azure"))
+	zcPatchRouter.Use(zch.MiddlewareValidateAzureConnectionUpdate)
+
+	zcDeleteRouter := r.Methods(http.MethodDelete).Subrouter()
+	zcDeleteRouter.HandleFunc("/v1/connectionmgmt/connection/azure/{connectionid:[a-fA-F0-9]{8}-[a-fA-F0-9]{4}-4[a-fA-F0-9]{3}-[8|9|aA|bB][a-fA-F0-9]{3}-[a-fA-F0-9]{12}}", zch.DeleteAzureConnection)
+	zcDeleteRouter.Use(otelhttp.NewMiddleware("DELETE /connection/azure"))
+	zcDeleteRouter.Use(zch.MiddlewareValidateConnectionDelete)
+
+	gch, err := handlers.NewGCPConnectionHandler(&cfg, l, pd, sb, pe)
+	if err != nil {
+		l.Error("GCPConnectionHandler initialization failed. Error: " + err.Error())
+		os.Exit(2)
+	}
+
+	gcGetConnectionsRouter := r.Methods(http.MethodGet).Subrouter()
+	gcGetConnectionsRouter.HandleFunc("/v1/connectionmgmt/connections/gcp", gch.GetGCPConnections)
+	gcGetConnectionsRouter.Use(otelhttp.NewMiddleware("GET /connections/gcp"))
+	gcGetConnectionsRouter.Use(gch.MiddlewareValidateConnectionsGet)
+
+	gcGetRouterWithID := r.Methods(http.MethodGet).Subrouter()
+	gcGetRouterWithID.HandleFunc("/v1/connectionmgmt/connection/gcp/{connectionid:[a-fA-F0-9]{8}-[a-fA-F0-9]{4}-4[a-fA-F0-9]{3}-[8|9|aA|bB][a-fA-F0-9]{3}-[a-fA-F0-9]{12}}", gch.GetGCPConnection)
+	gcGetRouterWithID.Use(otelhttp.NewMiddleware("GET /connection/gcp"))
+	gcGetRouterWithID.Use(gch.MiddlewareValidateConnection)
+
+	gcTestRouterWithID := r.Methods(http.MethodGet).Subrouter()
+	gcTestRouterWithID.HandleFunc("/v1/connectionmgmt/connection/gcp/{connectionid:[a-fA-F0-9]{8}-[a-fA-F0-9]{4}-4[a-fA-F0-9]{3}-[8|9|aA|bB][a-fA-F0-9]{3}-[a-fA-F0-9]{12}}/test", gch.TestGCPConnection)
+	gcTestRouterWithID.Use(otelhttp.NewMiddleware("GET /connection/gcp/test"))
+	gcTestRouterWithID.Use(gch.MiddlewareValidateConnection)
+
+	gcGenerateCredsRouter := r.Methods(http.MethodGet).Subrouter()
+	gcGenerateCredsRouter.HandleFunc("/v1/connectionmgmt/connection/gcp/{connectionid:[a-fA-F0-9]{8}-[a-fA-F0-9]{4}-4[a-fA-F0-9]{3}-[8|9|aA|bB][a-fA-F0-9]{3}-[a-fA-F0-9]{12}}/creds", gch.GenerateCredsGCPConnection)
+	gcGenerateCredsRouter.Use(otelhttp.NewMiddleware("GET /connection/gcp/creds"))
+	gcGenerateCredsRouter.Use(gch.MiddlewareValidateConnectionCreds)
+
+	gcPostRouter := r.Methods(http.MethodPost).Subrouter()
+	gcPostRouter.HandleFunc("/v1/connectionmgmt/connection/gcp", gch.AddGCPConnection)
+	gcPostRouter.Use(otelhttp.NewMiddleware("POST /connection/gcp"))
+	gcPostRouter.Use(gch.MiddlewareValidateGCPConnectionPost)
+
+	gcPatchRouter := r.Methods(http.MethodPatch).Subrouter()
+	gcPatchRouter.HandleFunc("/v1/connectionmgmt/connection/gcp/{connectionid:[a-fA-F0-9]{8}-[a-fA-F0-9]{4}-4[a-fA-F0-9]{3}-[8|9|aA|bB][a-fA-F0-9]{3}-[a-fA-F0-9]{12}}", gch.UpdateGCPConnection)
+	gcPatchRouter.Use(otelhttp.NewMiddleware("PATCH /connection/gcp"))
+	gcPatchRouter.Use(gch.MiddlewareValidateGCPConnectionUpdate)
+
+	gcDeleteRouter := r.Methods(http.MethodDelete).Subrouter()
+	gcDeleteRouter.HandleFunc("/v1/connectionmgmt/connection/gcp/{connectionid:[a-fA-F0-9]{8}-[a-fA-F0-9]{4}-4[a-fA-F0-9]{3}-[8|9|aA|bB][a-fA-F0-9]{3}-[a-fA-F0-9]{12}}", gch.DeleteGCPConnection)
+	gcDeleteRouter.Use(otelhttp.NewMiddleware("DELETE /connection/gcp"))
+	gcDeleteRouter.Use(gch.MiddlewareValidateConnectionDelete)
+
+	sch, err := handlers.NewSSHConnectionHandler(&cfg, l, pd, sb, pe)
+	if err != nil {
+		l.Error("SSHConnectionHandler initialization failed. Error: " + err.Error())
+		os.Exit(2)
+	}
+
+	scGetConnectionsRouter := r.Methods(http.MethodGet).Subrouter()
+	scGetConnectionsRouter.HandleFunc("/v1/connectionmgmt/connections/ssh", sch.GetSSHConnections)
+	scGetConnectionsRouter.Use(otelhttp.NewMiddleware("GET /connections/ssh"))
+	scGetConnectionsRouter.Use(sch.MiddlewareValidateConnectionsGet)
+
+	scGetRouterWithID := r.Methods(http.MethodGet).Subrouter()
+	scGetRouterWithID.HandleFunc("/v1/connectionmgmt/connection/ssh/{connectionid:[a-fA-F0-9]{8}-[a-fA-F0-9]{4}-4[a-fA-F0-9]{3}-[8|9|aA|bB][a-fA-F0-9]{3}-[a-fA-F0-9]{12}}", sch.GetSSHConnection)
+	scGetRouterWithID.Use(otelhttp.NewMiddleware("GET /connection/ssh"))
+	scGetRouterWithID.Use(sch.MiddlewareValidateConnection)
+
+	scTestRouterWithID := r.Methods(http.MethodGet).Subrouter()
+	scTestRouterWithID.HandleFunc("/v1/connectionmgmt/connection/ssh/{connectionid:[a-fA-F0-9]{8}-[a-fA-F0-9]{4}-4[a-fA-F0-9]{3}-[8|9|aA|bB][a-fA-F0-9]{3}-[a-fA-F0-9]{12}}/test", sch.TestSSHConnection)
+	scTestRouterWithID.Use(otelhttp.NewMiddleware("GET /connection/ssh/test"))
+	scTestRouterWithID.Use(sch.MiddlewareValidateConnection)
+
+	scSignRouter := r.Methods(http.MethodPost).Subrouter()
+	scSignRouter.HandleFunc("/v1/connectionmgmt/connection/ssh/{connectionid:[a-fA-F0-9]{8}-[a-fA-F0-9]{4}-4[a-fA-F0-9]{3}-[8|9|aA|bB][a-fA-F0-9]{3}-[a-fA-F0-9]{12}}/sign", sch.SignSSHConnection)
+	scSignRouter.Use(otelhttp.NewMiddleware("POST /connection/ssh/sign"))
+	scSignRouter.Use(sch.MiddlewareValidateSSHConnectionSign)
+
+	scPublicKeyRouter := r.Methods(http.MethodGet).Subrouter()
+	scPublicKeyRouter.HandleFunc("/v1/connectionmgmt/connection/ssh/{connectionid:[a-fA-F0-9]{8}-[a-fA-F0-9]{4}-4[a-fA-F0-9]{3}-[8|9|aA|bB][a-fA-F0-9]{3}-[a-fA-F0-9]{12}}/public_key", sch.GetSSHConnectionCAPublicKey)
+	scPublicKeyRouter.Use(otelhttp.NewMiddleware("GET /connection/ssh/public_key"))
+	scPublicKeyRouter.Use(sch.MiddlewareValidateConnection)
+
+	scPostRouter := r.Methods(http.MethodPost).Subrouter()
+	scPostRouter.HandleFunc("/v1/connectionmgmt/connection/ssh", sch.AddSSHConnection)
+	scPostRouter.Use(otelhttp.NewMiddleware("POST /connection/ssh"))
+	scPostRouter.Use(sch.MiddlewareValidateSSHConnectionPost)
+
+	scPatchRouter := r.Methods(http.MethodPatch).Subrouter()
+	scPatchRouter.HandleFunc("/v1/connectionmgmt/connection/ssh/{connectionid:[a-fA-F0-9]{8}-[a-fA-F0-9]{4}-4[a-fA-F0-9]{3}-[8|9|aA|bB][a-fA-F0-9]{3}-[a-fA-F0-9]{12}}", sch.UpdateSSHConnection)
+	scPatchRouter.Use(otelhttp.NewMiddleware("PATCH /connection/ssh"))
+	scPatchRouter.Use(sch.MiddlewareValidateSSHConnectionUpdate)
+
+	scDeleteRouter := r.Methods(http.MethodDelete).Subrouter()
+	scDeleteRouter.HandleFunc("/v1/connectionmgmt/connection/ssh/{connectionid:[a-fA-F0-9]{8}-[a-fA-F0-9]{4}-4[a-fA-F0-9]{3}-[8|9|aA|bB][a-fA-F0-9]{3}-[a-fA-F0-9]{12}}", sch.DeleteSSHConnection)
+	scDeleteRouter.Use(otelhttp.NewMiddleware("DELETE /connection/ssh"))
+	scDeleteRouter.Use(sch.MiddlewareValidateConnectionDelete)
+
+	dch, err := handlers.NewDatabaseConnectionHandler(&cfg, l, pd, sb, pe)
+	if err != nil {
+		l.Error("DatabaseConnectionHandler initialization failed. Error: " + err.Error())
+		os.Exit(2)
+	}
+
+	dbcGetConnectionsRouter := r.Methods(http.MethodGet).Subrouter()
+	dbcGetConnectionsRouter.HandleFunc("/v1/connectionmgmt/connections/database", dch.GetDatabaseConnections)
+	dbcGetConnectionsRouter.Use(otelhttp.NewMiddleware("GET /connections/database"))
+	dbcGetConnectionsRouter.Use(dch.MiddlewareValidateConnectionsGet)
+
+	dbcGetRouterWithID := r.Methods(http.MethodGet).Subrouter()
+	dbcGetRouterWithID.HandleFunc("/v1/connectionmgmt/connection/database/{connectionid:[a-fA-F0-9]{8}-[a-fA-F0-9]{4}-4[a-fA-F0-9]{3}-[8|9|aA|bB][a-fA-F0-9]{3}-[a-fA-F0-9]{12}}", dch.GetDatabaseConnection)
+	dbcGetRouterWithID.Use(otelhttp.NewMiddleware("GET /connection/database"))
+	dbcGetRouterWithID.Use(dch.MiddlewareValidateConnection)
+
+	dbcTestRouterWithID := r.Methods(http.MethodGet).Subrouter()
+	dbcTestRouterWithID.HandleFunc("/v1/connectionmgmt/connection/database/{connectionid:[a-fA-F0-9]{8}-[a-fA-F0-9]{4}-4[a-fA-F0-9]{3}-[8|9|aA|bB][a-fA-F0-9]{3}-[a-fA-F0-9]{12}}/test", dch.TestDatabaseConnection)
+	dbcTestRouterWithID.Use(otelhttp.NewMiddleware("GET /connection/database/test"))
+	dbcTestRouterWithID.Use(dch.MiddlewareValidateConnection)
+
+	dbcGenerateCredsRouter := r.Methods(http.MethodGet).Subrouter()
+	dbcGenerateCredsRouter.HandleFunc("/v1/connectionmgmt/connection/database/{connectionid:[a-fA-F0-9]{8}-[a-fA-F0-9]{4}-4[a-fA-F0-9]{3}-[8|9|aA|bB][a-fA-F0-9]{3}-[a-fA-F0-9]{12}}/creds", dch.GenerateCredsDatabaseConnection)
+	dbcGenerateCredsRouter.Use(otelhttp.NewMiddleware("GET /connection/database/creds"))
+	dbcGenerateCredsRouter.Use(dch.MiddlewareValidateConnectionCreds)
+
+	dbcPostRouter := r.Methods(http.MethodPost).Subrouter()
+	dbcPostRouter.HandleFunc("/v1/connectionmgmt/connection/database", dch.AddDatabaseConnection)
+	dbcPostRouter.Use(otelhttp.NewMiddleware("POST /connection/database"))
+	dbcPostRouter.Use(dch.MiddlewareValidateDatabaseConnectionPost)
+
+	dbcPatchRouter := r.Methods(http.MethodPatch).Subrouter()
+	dbcPatchRouter.HandleFunc("/v1/connectionmgmt/connection/database/{connectionid:[a-fA-F0-9]{8}-[a-fA-F0-9]{4}-4[a-fA-F0-9]{3}-[8|9|aA|bB][a-fA-F0-9]{3}-[a-fA-F0-9]{12}}", dch.UpdateDatabaseConnection)
+	dbcPatchRouter.Use(otelhttp.NewMiddleware("PATCH /connection/database"))
+	dbcPatchRouter.Use(dch.MiddlewareValidateDatabaseConnectionUpdate)
+
+	dbcDeleteRouter := r.Methods(http.MethodDelete).Subrouter()
+	dbcDeleteRouter.HandleFunc("/v1/connectionmgmt/connection/database/{connectionid:[a-fA-F0-9]{8}-[a-fA-F0-9]{4}-4[a-fA-F0-9]{3}-[8|9|aA|bB][a-fA-F0-9]{3}-[a-fA-F0-9]{12}}", dch.DeleteDatabaseConnection)
+	dbcDeleteRouter.Use(otelhttp.NewMiddleware("DELETE /connection/database"))
+	dbcDeleteRouter.Use(dch.MiddlewareValidateConnectionDelete)
+
+	pkh, err := handlers.NewPKIConnectionHandler(&cfg, l, pd, sb, pe)
+	if err != nil {
+		l.Error("PKIConnectionHandler initialization failed. Error: " + err.Error())
+		os.Exit(2)
+	}
+
+	pkGetConnectionsRouter := r.Methods(http.MethodGet).Subrouter()
+	pkGetConnectionsRouter.HandleFunc("/v1/connectionmgmt/connections/pki", pkh.GetPKIConnections)
+	pkGetConnectionsRouter.Use(otelhttp.NewMiddleware("GET /connections/pki"))
+	pkGetConnectionsRouter.Use(pkh.MiddlewareValidateConnectionsGet)
+
+	pkGetRouterWithID := r.Methods(http.MethodGet).Subrouter()
+	pkGetRouterWithID.HandleFunc("/v1/connectionmgmt/connection/pki/{connectionid:[a-fA-F0-9]{8}-[a-fA-F0-9]{4}-4[a-fA-F0-9]{3}-[8|9|aA|bB][a-fA-F0-9]{3}-[a-fA-F0-9]{12}}", pkh.GetPKIConnection)
+	pkGetRouterWithID.Use(otelhttp.NewMiddleware("GET /connection/pki"))
+	pkGetRouterWithID.Use(pkh.MiddlewareValidateConnection)
+
+	pkTestRouterWithID := r.Methods(http.MethodGet).Subrouter()
+	pkTestRouterWithID.HandleFunc("/v1/connectionmgmt/connection/pki/{connectionid:[a-fA-F0-9]{8}-[a-fA-F0-9]{4}-4[a-fA-F0-9]{3}-[8|9|aA|bB][a-fA-F0-9]{3}-[a-fA-F0-9]{12}}/test", pkh.TestPKIConnection)
+	pkTestRouterWithID.Use(otelhttp.NewMiddleware("GET /connection/pki/test"))
+	pkTestRouterWithID.Use(pkh.MiddlewareValidateConnection)
+
+	pkIssueRouter := r.Methods(http.MethodPost).Subrouter()
+	pkIssueRouter.HandleFunc("/v1/connectionmgmt/connection/pki/{connectionid:[a-fA-F0-9]{8}-[a-fA-F0-9]{4}-4[a-fA-F0-9]{3}-[8|9|aA|bB][a-fA-F0-9]{3}-[a-fA-F0-9]{12}}/issue", pkh.IssuePKIConnection)
+	pkIssueRouter.Use(otelhttp.NewMiddleware("POST /connection/pki/issue"))
+	pkIssueRouter.Use(pkh.MiddlewareValidatePKIConnectionIssue)
+
+	pkCertificatesRouter := r.Methods(http.MethodGet).Subrouter()
+	pkCertificatesRouter.HandleFunc("/v1/connectionmgmt/connection/pki/{connectionid:[a-fA-F0-9]{8}-[a-fA-F0-9]{4}-4[a-fA-F0-9]{3}-[8|9|aA|bB][a-fA-F0-9]{3}-[a-fA-F0-9]{12}}/certificates", pkh.GetPKIConnectionCertificates)
+	pkCertificatesRouter.Use(otelhttp.NewMiddleware("GET /connection/pki/certificates"))
+	pkCertificatesRouter.Use(pkh.MiddlewareValidatePKIConnectionCertificatesGet)
+
+	pkRevokeCertificateRouter := r.Methods(http.MethodPost).Subrouter()
+	pkRevokeCertificateRouter.HandleFunc("/v1/connectionmgmt/connection/pki/{connectionid:[a-fA-F0-9]{8}-[a-fA-F0-9]{4}-4[a-fA-F0-9]{3}-[8|9|aA|bB][a-fA-F0-9]{3}-[a-fA-F0-9]{12}}/certificates/{certificateid:[a-fA-F0-9]{8}-[a-fA-F0-9]{4}-4[a-fA-F0-9]{3}-[8|9|aA|bB][a-fA-F0-9]{3}-[a-fA-F0-9]{12}}/revoke", pkh.RevokePKIConnectionCertificate)
+	pkRevokeCertificateRouter.Use(otelhttp.NewMiddleware("POST /connection/pki/certificates/revoke"))
+	pkRevokeCertificateRouter.Use(pkh.MiddlewareValidatePKIConnectionCertificate)
+
+	pkCARouter := r.Methods(http.MethodGet).Subrouter()
+	pkCARouter.HandleFunc("/v1/connectionmgmt/connection/pki/{connectionid:[a-fA-F0-9]{8}-[a-fA-F0-9]{4}-4[a-fA-F0-9]{3}-[8|9|aA|bB][a-fA-F0-9]{3}-[a-fA-F0-9]{12}}/ca", pkh.GetPKIConnectionCA)
+	pkCARouter.Use(otelhttp.NewMiddleware("GET /connection/pki/ca"))
+	pkCARouter.Use(pkh.MiddlewareValidatePKIConnectionFormat)
+
+	pkCRLRouter := r.Methods(http.MethodGet).Subrouter()
+	pkCRLRouter.HandleFunc("/v1/connectionmgmt/connection/pki/{connectionid:[a-fA-F0-9]{8}-[a-fA-F0-9]{4}-4[a-fA-F0-9]{3}-[8|9|aA|bB][a-fA-F0-9]{3}-[a-fA-F0-9]{12}}/crl", pkh.GetPKIConnectionCRL)
+	pkCRLRouter.Use(otelhttp.NewMiddleware("GET /connection/pki/crl"))
+	pkCRLRouter.Use(pkh.MiddlewareValidatePKIConnectionFormat)
+
+	pkOCSPRouter := r.Methods(http.MethodPost).Subrouter()
+	pkOCSPRouter.HandleFunc("/v1/connectionmgmt/connection/pki/{connectionid:[a-fA-F0-9]{8}-[a-fA-F0-9]{4}-4[a-fA-F0-9]{3}-[8|9|aA|bB][a-fA-F0-9]{3}-[a-fA-F0-9]{12}}/ocsp", pkh.GetPKIConnectionOCSP)
+	pkOCSPRouter.Use(otelhttp.NewMiddleware("POST /connection/pki/ocsp"))
+	pkOCSPRouter.Use(pkh.MiddlewareValidatePKIConnectionOCSP)
+
+	pkPostRouter := r.Methods(http.MethodPost).Subrouter()
+	pkPostRouter.HandleFunc("/v1/connectionmgmt/connection/pki", pkh.AddPKIConnection)
+	pkPostRouter.Use(otelhttp.NewMiddleware("POST /connection/pki"))
+	pkPostRouter.Use(pkh.MiddlewareValidatePKIConnectionPost)
+
+	pkPatchRouter := r.Methods(http.MethodPatch).Subrouter()
+	pkPatchRouter.HandleFunc("/v1/connectionmgmt/connection/pki/{connectionid:[a-fA-F0-9]{8}-[a-fA-F0-9]{4}-4[a-fA-F0-9]{3}-[8|9|aA|bB][a-fA-F0-9]{3}-[a-fA-F0-9]{12}}", pkh.UpdatePKIConnection)
+	pkPatchRouter.Use(otelhttp.NewMiddleware("PATCH /connection/pki"))
+	pkPatchRouter.Use(pkh.MiddlewareValidatePKIConnectionUpdate)
+
+	pkDeleteRouter := r.Methods(http.MethodDelete).Subrouter()
+	pkDeleteRouter.HandleFunc("/v1/connectionmgmt/connection/pki/{connectionid:[a-fA-F0-9]{8}-[a-fA-F0-9]{4}-4[a-fA-F0-9]{3}-[8|9|aA|bB][a-fA-F0-9]{3}-[a-fA-F0-9]{12}}", pkh.DeletePKIConnection)
+	pkDeleteRouter.Use(otelhttp.NewMiddleware("DELETE /connection/pki"))
+	pkDeleteRouter.Use(pkh.MiddlewareValidateConnectionDelete)
+
+	pkGetRolesRouter := r.Methods(http.MethodGet).Subrouter()
+	pkGetRolesRouter.HandleFunc("/v1/connectionmgmt/connection/pki/{connectionid:[a-fA-F0-9]{8}-[a-fA-F0-9]{4}-4[a-fA-F0-9]{3}-[8|9|aA|bB][a-fA-F0-9]{3}-[a-fA-F0-9]{12}}/roles", pkh.GetPKIConnectionRoles)
+	pkGetRolesRouter.Use(otelhttp.NewMiddleware("GET /connection/pki/roles"))
+	pkGetRolesRouter.Use(pkh.MiddlewareValidateRolesGet)
+
+	pkGetRoleRouter := r.Methods(http.MethodGet).Subrouter()
+	pkGetRoleRouter.HandleFunc("/v1/connectionmgmt/connection/pki/{connectionid:[a-fA-F0-9]{8}-[a-fA-F0-9]{4}-4[a-fA-F0-9]{3}-[8|9|aA|bB][a-fA-F0-9]{3}-[a-fA-F0-9]{12}}/roles/{rolename:[a-zA-Z0-9_-]+}", pkh.GetPKIConnectionRole)
+	pkGetRoleRouter.Use(otelhttp.NewMiddleware("GET /connection/pki/roles/rolename"))
+	pkGetRoleRouter.Use(pkh.MiddlewareValidateRole)
+
+	pkPostRoleRouter := r.Methods(http.MethodPost).Subrouter()
+	pkPostRoleRouter.HandleFunc("/v1/connectionmgmt/connection/pki/{connectionid:[a-fA-F0-9]{8}-[a-fA-F0-9]{4}-4[a-fA-F0-9]{3}-[8|9|aA|bB][a-fA-F0-9]{3}-[a-fA-F0-9]{12}}/roles", pkh.AddPKIConnectionRole)
+	pkPostRoleRouter.Use(otelhttp.NewMiddleware("POST /connection/pki/roles"))
+	pkPostRoleRouter.Use(pkh.MiddlewareValidatePKIRolePost)
+
+	pkPatchRoleRouter := r.Methods(http.MethodPatch).Subrouter()
+	pkPatchRoleRouter.HandleFunc("/v1/connectionmgmt/connection/pki/{connectionid:[a-fA-F0-9]{8}-[a-fA-F0-9]{4}-4[a-fA-F0-9]{3}-[8|9|aA|bB][a-fA-F0-9]{3}-[a-fA-F0-9]{12}}/roles/{rolename:[a-zA-Z0-9_-]+}", pkh.UpdatePKIConnectionRole)
+	pkPatchRoleRouter.Use(otelhttp.NewMiddleware("PATCH /connection/pki/roles/rolename"))
+	pkPatchRoleRouter.Use(pkh.MiddlewareValidatePKIRoleUpdate)
+
+	pkDeleteRoleRouter := r.Methods(http.MethodDelete).Subrouter()
+	pkDeleteRoleRouter.HandleFunc("/v1/connectionmgmt/connection/pki/{connectionid:[a-fA-F0-9]{8}-[a-fA-F0-9]{4}-4[a-fA-F0-9]{3}-[8|9|aA|bB][a-fA-F0-9]{3}-[a-fA-F0-9]{12}}/roles/{rolename:[a-zA-Z0-9_-]+}", pkh.DeletePKIConnectionRole)
+	pkDeleteRoleRouter.Use(otelhttp.NewMiddleware("DELETE /connection/pki/roles/rolename"))
+	pkDeleteRoleRouter.Use(pkh.MiddlewareValidateRole)
+
+	ah, err := handlers.NewAuditHandler(&cfg, l, pd, pe)
+	if err != nil {
+		l.Error("AuditHandler initialization failed. Error: " + err.Error())
+		os.Exit(2)
+	}
+
+	aGetRouter := r.Methods(http.MethodGet).Subrouter()
+	aGetRouter.HandleFunc("/v1/connectionmgmt/audit", ah.GetAuditRecords)
+	aGetRouter.Use(otelhttp.NewMiddleware("GET /audit"))
+	aGetRouter.Use(ah.MiddlewareValidateAuditGet)
+
+	bh, err := handlers.NewRoleBindingHandler(&cfg, l, pd, pe)
+	if err != nil {
+		l.Error("RoleBindingHandler initialization failed. Error: " + err.Error())
+		os.Exit(2)
+	}
+
+	bGetRouter := r.Methods(http.MethodGet).Subrouter()
+	bGetRouter.HandleFunc("/v1/connectionmgmt/rolebindings", bh.GetRoleBindings)
+	bGetRouter.Use(otelhttp.NewMiddleware("GET /rolebindings"))
+	bGetRouter.Use(bh.MiddlewareValidateRoleBindingsGet)
+
+	bPostRouter := r.Methods(http.MethodPost).Subrouter()
+	bPostRouter.HandleFunc("/v1/connectionmgmt/rolebinding", bh.AddRoleBinding)
+	bPostRouter.Use(otelhttp.NewMiddleware("POST /rolebinding"))
+	bPostRouter.Use(bh.MiddlewareValidateRoleBindingPost)
+
+	bDeleteRouter := r.Methods(http.MethodDelete).Subrouter()
+	bDeleteRouter.HandleFunc("/v1/connectionmgmt/rolebinding/{bindingid:[a-fA-F0-9]{8}-[a-fA-F0-9]{4}-4[a-fA-F0-9]{3}-[8|9|aA|bB][a-fA-F0-9]{3}-[a-fA-F0-9]{12}}", bh.DeleteRoleBinding)
+	bDeleteRouter.Use(otelhttp.NewMiddleware("DELETE /rolebinding"))
+	bDeleteRouter.Use(bh.MiddlewareValidateRoleBinding)
+
+	rh, err := handlers.NewReconcilerHandler(&cfg, l, pd, sb, pe)
+	if err != nil {
+		l.Error("ReconcilerHandler initialization failed. Error: " + err.Error())
+		os.Exit(2)
+	}
+
+	rGetDriftRouter := r.Methods(http.MethodGet).Subrouter()
+	rGetDriftRouter.HandleFunc("/v1/connectionmgmt/status/drift", rh.GetDrift)
+	rGetDriftRouter.Use(otelhttp.NewMiddleware("GET /status/drift"))
+	rGetDriftRouter.Use(rh.MiddlewareValidateDrift)
+
+	rRepairDriftRouter := r.Methods(http.MethodPost).Subrouter()
+	rRepairDriftRouter.HandleFunc("/v1/connectionmgmt/status/drift/repair", rh.RepairDrift)
+	rRepairDriftRouter.Use(otelhttp.NewMiddleware("POST /status/drift/repair"))
+	rRepairDriftRouter.Use(rh.MiddlewareValidateDrift)
+
+	if cfg.Reconciler.Enabled {
+		go rh.Run(ctx)
+	}
+
+	ts, err := handlers.NewConnectionTestScheduler(&cfg, l, pd, jch, zch, gch, dch, sch, pkh)
+	if err != nil {
+		l.Error("ConnectionTestScheduler initialization failed. Error: " + err.Error())
+		os.Exit(2)
+	}
+
+	if cfg.ConnectionTest.Enabled {
+		go ts.Run(ctx)
+	}
+
+	rs, err := handlers.NewRootRotationScheduler(&cfg, l, pd, jch)
+	if err != nil {
+		l.Error("RootRotationScheduler initialization failed. Error: " + err.Error())
+		os.Exit(2)
+	}
+
+	if cfg.RootRotation.Enabled {
+		go rs.Run(ctx)
+	}
+
+	ls, err := handlers.NewLinkSweeper(&cfg, l, pd, sb)
+	if err != nil {
+		l.Error("LinkSweeper initialization failed. Error: " + err.Error())
+		os.Exit(2)
+	}
+
+	if cfg.LinkSweeper.Enabled {
+		go ls.Run(ctx)
+	}
 
 	opts := middleware.RedocOpts{SpecURL: "/swagger.yaml"}
 	docs_sh := middleware.Redoc(opts, nil)
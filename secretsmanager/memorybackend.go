@@ -0,0 +1,1792 @@
+package secretsmanager
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"DemoServer_ConnectionManager/configuration"
+	"DemoServer_ConnectionManager/data"
+	"DemoServer_ConnectionManager/helper"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/ocsp"
+	"golang.org/x/crypto/ssh"
+)
+
+// Character sets of generated access keys and secrets
+const (
+	charsetAccessKey = "ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+	charsetSecretKey = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/"
+)
+
+// vaultSystemDefaultLeaseTTL is lease ttl Vault applies when neither mount nor role sets one i.e. 768h
+const vaultSystemDefaultLeaseTTL = 2764800
+
+type memoryAWSEngine struct {
+	accessKey       string
+	secretKey       string
+	region          string
+	defaultLeaseTTL string
+	maxLeaseTTL     string
+	roles           map[string]data.AWSRole
+}
+
+type memoryAzureEngine struct {
+	subscriptionID      string
+	tenantID            string
+	clientID            string
+	clientSecret        string
+	environment         string
+	defaultLeaseTTL     string
+	maxLeaseTTL         string
+	roleName            string
+	azureRoles          []data.AzureRoleAssignment
+	applicationObjectID string
+}
+
+type memoryGCPEngine struct {
+	credentials         string
+	defaultLeaseTTL     string
+	maxLeaseTTL         string
+	roleType            string
+	roleName            string
+	secretType          string
+	project             string
+	serviceAccountEmail string
+	bindings            []data.GCPBinding
+	tokenScopes         []string
+}
+
+type memoryDatabaseEngine struct {
+	pluginName           string
+	connectionURL        string
+	username             string
+	password             string
+	defaultLeaseTTL      string
+	maxLeaseTTL          string
+	roleName             string
+	creationStatements   []string
+	revocationStatements []string
+}
+
+type memorySSHEngine struct {
+	signer            ssh.Signer
+	defaultLeaseTTL   string
+	maxLeaseTTL       string
+	roleName          string
+	certType          string
+	allowedUsers      []string
+	defaultUser       string
+	allowedDomains    []string
+	allowSubdomains   bool
+	defaultExtensions []string
+}
+
+type memoryPKIEngine struct {
+	certificate     *x509.Certificate
+	signer          crypto.Signer
+	chain           []*x509.Certificate
+	defaultLeaseTTL string
+	maxLeaseTTL     string
+	keyType         string
+	roles           map[string]data.PKIRole
+	issuingURL      string
+	crlURL          string
+	ocspURL         string
+	issued          map[string]*big.Int
+	revoked         map[string]time.Time
+	crlNumber       int64
+}
+
+// memoryEngine is secrets engine of any type mounted in MemoryBackend.
+type memoryEngine interface {
+	engineType() string
+}
+
+func (e *memoryAWSEngine) engineType() string      { return EngineAWS }
+func (e *memoryAzureEngine) engineType() string    { return EngineAzure }
+func (e *memoryGCPEngine) engineType() string      { return EngineGCP }
+func (e *memoryDatabaseEngine) engineType() string { return EngineDatabase }
+func (e *memorySSHEngine) engineType() string      { return EngineSSH }
+func (e *memoryPKIEngine) engineType() string      { return EnginePKI }
+
+// newMemoryEngine returns unconfigured secrets engine of engineType.
+func newMemoryEngine(engineType string) (memoryEngine, error) {
+	switch engineType {
+	case EngineAWS:
+		return &memoryAWSEngine{roles: map[string]data.AWSRole{}}, nil
+	case EngineAzure:
+		return &memoryAzureEngine{}, nil
+	case EngineGCP:
+		return &memoryGCPEngine{}, nil
+	case EngineDatabase:
+		return &memoryDatabaseEngine{}, nil
+	case EngineSSH:
+		return &memorySSHEngine{}, nil
+	case EnginePKI:
+		return &memoryPKIEngine{roles: map[string]data.PKIRole{}, issued: map[string]*big.Int{}, revoked: map[string]time.Time{}}, nil
+	}
+	return nil, fmt.Errorf("%w: unsupported secrets engine type %s", helper.ErrVaultFailToEnableSecretsEngine, engineType)
+}
+
+type memoryLease struct {
+	duration  int
+	renewable bool
+}
+
+// MemoryBackend is a SecretsBackend keeping secrets engines and leases in process memory. It mimics behaviour
+// of Vault closely enough for handlers to work, but generated credentials are not usable with cloud providers.
+type MemoryBackend struct {
+	c      *configuration.Config
+	l      *slog.Logger
+	mu     sync.Mutex
+	mounts map[string]memoryEngine
+	leases map[string]*memoryLease
+}
+
+func NewMemoryBackend(c *configuration.Config, l *slog.Logger) *MemoryBackend {
+	return &MemoryBackend{
+		c:      c,
+		l:      l,
+		mounts: map[string]memoryEngine{},
+		leases: map[string]*memoryLease{},
+	}
+}
+
+// durationToSeconds converts duration accepted by Vault i.e. 900, 900s, 15m to seconds.
+func durationToSeconds(d string) (int, error) {
+	if d == "" {
+		return 0, nil
+	}
+
+	if seconds, err := strconv.Atoi(d); err == nil {
+		return seconds, nil
+	}
+
+	value, err := time.ParseDuration(d)
+	if err != nil {
+		return 0, err
+	}
+
+	return int(value.Seconds()), nil
+}
+
+func randomString(charset string, length int) string {
+	b := make([]byte, length)
+	for i := range b {
+		n, _ := rand.Int(rand.Reader, big.NewInt(int64(len(charset))))
+		b[i] = charset[n.Int64()]
+	}
+	return string(b)
+}
+
+// key qualifies path of mount or lease with namespace it belongs to. Namespace configured for service is
+// used when namespace is empty the same way as for Vault.
+func (mb *MemoryBackend) key(namespace string, path string) string {
+	if namespace == "" {
+		namespace = mb.c.Vault.Namespace
+	}
+	return namespace + ":" + path
+}
+
+// mountedEngine returns secrets engine of type E mounted at path in namespace. Engines of other types mounted
+// at path are not found. Caller must hold mu.
+func mountedEngine[E memoryEngine](mb *MemoryBackend, path string, namespace string) (E, error) {
+	e, found := mb.mounts[mb.key(namespace, path)].(E)
+	if !found {
+		return e, fmt.Errorf("%w: no secrets engine mounted at %s", helper.ErrNotFound, path)
+	}
+	return e, nil
+}
+
+// mountEngine mounts engine e configured through configure at path in namespace. Path must neither be used nor
+// overlap with another mount in namespace. Caller must hold mu.
+func (mb *MemoryBackend) mountEngine(path string, namespace string, e memoryEngine, enableFailed error, configure func() error) error {
+	if err := mb.checkMountPathAvailable(path, namespace); err != nil {
+		return fmt.Errorf("%w: %s", enableFailed, err.Error())
+	}
+
+	if err := configure(); err != nil {
+		return err
+	}
+
+	mb.mounts[mb.key(namespace, path)] = e
+
+	return nil
+}
+
+// reconfigureEngine configures secrets engine of type E mounted at path in namespace through configure.
+func reconfigureEngine[E memoryEngine](mb *MemoryBackend, path string, namespace string, configure func(e E) error) error {
+	mb.mu.Lock()
+	defer mb.mu.Unlock()
+
+	e, err := mountedEngine[E](mb, path, namespace)
+	if err != nil {
+		return fmt.Errorf("%w: %s", helper.ErrVaultFailToConfigureSecretsEngine, err.Error())
+	}
+
+	return configure(e)
+}
+
+// validateLeaseTTLs makes sure lease ttls of secrets engine mount are durations Vault accepts.
+func validateLeaseTTLs(configureFailed error, ttls ...string) error {
+	for _, ttl := range ttls {
+		if _, err := durationToSeconds(ttl); err != nil {
+			return fmt.Errorf("%w: %s", configureFailed, err.Error())
+		}
+	}
+	return nil
+}
+
+// issueLease records lease of credentials issued under leaseID in namespace. Caller must hold mu.
+func (mb *MemoryBackend) issueLease(leaseID string, namespace string, duration int, renewable bool) {
+	mb.leases[mb.key(namespace, leaseID)] = &memoryLease{duration: duration, renewable: renewable}
+}
+
+// role returns role of secrets engine mounted at path in namespace. First role of engine is returned if name is empty
+// the same way as for Vault. Caller must hold mu.
+func (mb *MemoryBackend) role(path string, namespace string, name string) (data.AWSRole, error) {
+	e, err := mountedEngine[*memoryAWSEngine](mb, path, namespace)
+	if err != nil {
+		return data.AWSRole{}, err
+	}
+
+	if name == "" {
+		names := make([]string, 0, len(e.roles))
+		for n := range e.roles {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+
+		if len(names) == 0 {
+			return data.AWSRole{}, helper.ErrVaultFailToRetrieveAWSEngineRoleName
+		}
+		name = names[0]
+	}
+
+	r, found := e.roles[name]
+	if !found {
+		return data.AWSRole{}, helper.ErrVaultAWSEngineRoleNotFound
+	}
+
+	return r, nil
+}
+
+// revokeLeasesByPrefix removes leases issued under prefix in namespace. Caller must hold mu.
+func (mb *MemoryBackend) revokeLeasesByPrefix(prefix string, namespace string) {
+	prefix = mb.key(namespace, prefix)
+
+	for id := range mb.leases {
+		if strings.HasPrefix(id, prefix) {
+			delete(mb.leases, id)
+		}
+	}
+}
+
+func (mb *MemoryBackend) Ping(ctx context.Context) error {
+	return nil
+}
+
+func (mb *MemoryBackend) GetAWSSecretsEngine(c *data.AWSConnection, ctx context.Context) error {
+	mb.mu.Lock()
+	defer mb.mu.Unlock()
+
+	e, err := mountedEngine[*memoryAWSEngine](mb, c.VaultPath, c.VaultNamespace)
+	if err != nil {
+		return err
+	}
+
+	// Default role is missing from mounts re-created by reconciler until connection is patched
+	r, err := mb.role(c.VaultPath, c.VaultNamespace, c.RoleName)
+	if err != nil && !errors.Is(err, helper.ErrVaultAWSEngineRoleNotFound) {
+		return err
+	}
+	if r.RoleName == "" {
+		r.RoleName = c.RoleName
+	}
+
+	c.AccessKey = e.accessKey
+	c.DefaultLeaseTTL = e.defaultLeaseTTL
+	c.DefaultRegion = e.region
+	c.MaxLeaseTTL = e.maxLeaseTTL
+	c.RoleName = r.RoleName
+	c.CredentialType = r.CredentialType
+	c.PolicyARNs = r.PolicyARNs
+	c.PolicyDocument = r.PolicyDocument
+	c.RoleARNs = r.RoleARNs
+	c.SessionTags = r.SessionTags
+	c.ExternalID = r.ExternalID
+
+	return nil
+}
+
+func (mb *MemoryBackend) AddAWSSecretsEngine(c *data.AWSConnection, ctx context.Context) error {
+	mb.mu.Lock()
+	defer mb.mu.Unlock()
+
+	e := &memoryAWSEngine{roles: map[string]data.AWSRole{}}
+
+	return mb.mountEngine(c.VaultPath, c.VaultNamespace, e, helper.ErrVaultFailToEnableAWSSecretsEngine, func() error {
+		return mb.configureAWSSecretsEngine(e, c)
+	})
+}
+
+func (mb *MemoryBackend) UpdateAWSSecretsEngine(c *data.AWSConnection, ctx context.Context) error {
+	return reconfigureEngine(mb, c.VaultPath, c.VaultNamespace, func(e *memoryAWSEngine) error {
+		return mb.configureAWSSecretsEngine(e, c)
+	})
+}
+
+// configureAWSSecretsEngine sets root credentials, lease settings and default role of engine. Caller must hold mu.
+func (mb *MemoryBackend) configureAWSSecretsEngine(e *memoryAWSEngine, c *data.AWSConnection) error {
+	if err := validateLeaseTTLs(helper.ErrVaultFailToConfigureAWSSecretsEngine, c.DefaultLeaseTTL, c.MaxLeaseTTL); err != nil {
+		return err
+	}
+
+	e.accessKey = c.AccessKey
+	e.secretKey = c.SecretAccessKey
+	e.region = c.DefaultRegion
+	e.defaultLeaseTTL = c.DefaultLeaseTTL
+	e.maxLeaseTTL = c.MaxLeaseTTL
+	e.roles[c.RoleName] = *c.DefaultRole()
+
+	return nil
+}
+
+func (mb *MemoryBackend) RemoveAWSSecretsEngine(c *data.AWSConnection, ctx context.Context) error {
+	return mb.DisableSecretsEngineMount(c.VaultPath, c.VaultNamespace, ctx)
+}
+
+func (mb *MemoryBackend) TestAWSSecretsEngine(path string, namespace string, role string, ctx context.Context) error {
+	mb.mu.Lock()
+	defer mb.mu.Unlock()
+
+	if _, err := mb.role(path, namespace, role); err != nil {
+		return err
+	}
+
+	if e, _ := mountedEngine[*memoryAWSEngine](mb, path, namespace); e.accessKey == "" || e.secretKey == "" {
+		return helper.ErrAWSConnectionTestFailed
+	}
+
+	return nil
+}
+
+func (mb *MemoryBackend) GenerateCredsAWSSecretsEngine(path string, namespace string, role string, roleARN string, ttl string, ctx context.Context) (*data.CredsAWSConnectionResponse, error) {
+	mb.mu.Lock()
+	defer mb.mu.Unlock()
+
+	r, err := mb.role(path, namespace, role)
+	if err != nil {
+		return nil, err
+	}
+
+	e, _ := mountedEngine[*memoryAWSEngine](mb, path, namespace)
+	if e.accessKey == "" || e.secretKey == "" {
+		return nil, fmt.Errorf("%w: root credentials not configured", helper.ErrVaultFailToGenerateAWSCredentials)
+	}
+
+	iamUser := strings.ToLower(r.CredentialType) == strIAMUser
+
+	if iamUser && ttl != "" {
+		return nil, helper.ErrVaultTTLNotSupportedForCredentialType
+	}
+
+	var credsResponse data.CredsAWSConnectionResponse
+	var duration int
+
+	switch {
+	case ttl != "":
+		duration, err = durationToSeconds(ttl)
+	case iamUser && e.defaultLeaseTTL != "":
+		duration, err = durationToSeconds(e.defaultLeaseTTL)
+	case iamUser:
+		duration = mb.c.AWS.DefaultLeaseTTL
+	default:
+		duration = mb.c.AWS.DefaultStsTTL
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", helper.ErrVaultFailToGenerateAWSCredentials, err.Error())
+	}
+
+	credsResponse.LeaseID = path + "/creds/" + r.RoleName + "/" + uuid.New().String()
+	credsResponse.LeaseDuration = duration
+	credsResponse.RoleName = r.RoleName
+	credsResponse.Data.SecretKey = randomString(charsetSecretKey, 40)
+
+	if iamUser {
+		credsResponse.Renewable = true
+		credsResponse.Latency = mb.c.AWS.IAMUserLatency
+		credsResponse.Data.AccessKey = "AKIA" + randomString(charsetAccessKey, 16)
+	} else {
+		credsResponse.Data.AccessKey = "ASIA" + randomString(charsetAccessKey, 16)
+		credsResponse.Data.SessionToken = randomString(charsetSecretKey, 64)
+	}
+
+	mb.issueLease(credsResponse.LeaseID, namespace, duration, credsResponse.Renewable)
+
+	return &credsResponse, nil
+}
+
+// RotateAWSRootCredentials replaces root credentials of engine with generated ones the same way as Vault
+// does through config/rotate-root.
+func (mb *MemoryBackend) RotateAWSRootCredentials(path string, namespace string, ctx context.Context) (string, error) {
+	mb.mu.Lock()
+	defer mb.mu.Unlock()
+
+	e, err := mountedEngine[*memoryAWSEngine](mb, path, namespace)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", helper.ErrVaultFailToRotateAWSRootCredentials, err.Error())
+	}
+
+	if e.accessKey == "" || e.secretKey == "" {
+		return "", fmt.Errorf("%w: root credentials not configured", helper.ErrVaultFailToRotateAWSRootCredentials)
+	}
+
+	e.accessKey = "AKIA" + randomString(charsetAccessKey, 16)
+	e.secretKey = randomString(charsetSecretKey, 40)
+
+	return e.accessKey, nil
+}
+
+// leaseDuration returns duration of lease issued by engine with defaultLeaseTTL, falling back to system default
+// of Vault.
+func leaseDuration(defaultLeaseTTL string) (int, error) {
+	if defaultLeaseTTL == "" {
+		return vaultSystemDefaultLeaseTTL, nil
+	}
+
+	duration, err := durationToSeconds(defaultLeaseTTL)
+	if err != nil || duration > 0 {
+		return duration, err
+	}
+
+	return vaultSystemDefaultLeaseTTL, nil
+}
+
+func (mb *MemoryBackend) GetAzureSecretsEngine(c *data.AzureConnection, ctx context.Context) error {
+	mb.mu.Lock()
+	defer mb.mu.Unlock()
+
+	e, err := mountedEngine[*memoryAzureEngine](mb, c.VaultPath, c.VaultNamespace)
+	if err != nil {
+		return err
+	}
+
+	c.SubscriptionID = e.subscriptionID
+	c.TenantID = e.tenantID
+	c.ClientID = e.clientID
+	c.Environment = e.environment
+	c.DefaultLeaseTTL = e.defaultLeaseTTL
+	c.MaxLeaseTTL = e.maxLeaseTTL
+	c.AzureRoles = e.azureRoles
+	c.ApplicationObjectID = e.applicationObjectID
+
+	return nil
+}
+
+func (mb *MemoryBackend) AddAzureSecretsEngine(c *data.AzureConnection, ctx context.Context) error {
+	mb.mu.Lock()
+	defer mb.mu.Unlock()
+
+	e := &memoryAzureEngine{}
+
+	return mb.mountEngine(c.VaultPath, c.VaultNamespace, e, helper.ErrVaultFailToEnableSecretsEngine, func() error {
+		return mb.configureAzureSecretsEngine(e, c)
+	})
+}
+
+func (mb *MemoryBackend) UpdateAzureSecretsEngine(c *data.AzureConnection, ctx context.Context) error {
+	return reconfigureEngine(mb, c.VaultPath, c.VaultNamespace, func(e *memoryAzureEngine) error {
+		return mb.configureAzureSecretsEngine(e, c)
+	})
+}
+
+// configureAzureSecretsEngine sets configuration, lease settings and role of engine. Stored client secret is kept
+// if c does not carry one the same way as for Vault. Caller must hold mu.
+func (mb *MemoryBackend) configureAzureSecretsEngine(e *memoryAzureEngine, c *data.AzureConnection) error {
+	if err := validateLeaseTTLs(helper.ErrVaultFailToConfigureSecretsEngine, c.DefaultLeaseTTL, c.MaxLeaseTTL); err != nil {
+		return err
+	}
+
+	if !c.HasRole() {
+		return fmt.Errorf("%w: either azure_roles or application_object_id is required", helper.ErrVaultFailToConfigureSecretsEngine)
+	}
+
+	e.subscriptionID = c.SubscriptionID
+	e.tenantID = c.TenantID
+	e.clientID = c.ClientID
+	if c.ClientSecret != "" {
+		e.clientSecret = c.ClientSecret
+	}
+	e.environment = c.Environment
+	e.defaultLeaseTTL = c.DefaultLeaseTTL
+	e.maxLeaseTTL = c.MaxLeaseTTL
+	e.roleName = c.RoleName
+	e.azureRoles = c.AzureRoles
+	e.applicationObjectID = c.ApplicationObjectID
+
+	return nil
+}
+
+func (mb *MemoryBackend) RemoveAzureSecretsEngine(c *data.AzureConnection, ctx context.Context) error {
+	return mb.DisableSecretsEngineMount(c.VaultPath, c.VaultNamespace, ctx)
+}
+
+func (mb *MemoryBackend) TestAzureSecretsEngine(path string, namespace string, role string, ctx context.Context) error {
+	creds, err := mb.GenerateCredsAzureSecretsEngine(path, namespace, role, ctx)
+	if err != nil {
+		return err
+	}
+
+	return mb.RevokeLease(creds.LeaseID, namespace, ctx)
+}
+
+func (mb *MemoryBackend) GenerateCredsAzureSecretsEngine(path string, namespace string, role string, ctx context.Context) (*data.CredsAzureConnectionResponse, error) {
+	mb.mu.Lock()
+	defer mb.mu.Unlock()
+
+	e, err := mountedEngine[*memoryAzureEngine](mb, path, namespace)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", helper.ErrVaultFailToGenerateAzureCredentials, err.Error())
+	}
+
+	if e.roleName != role {
+		return nil, fmt.Errorf("%w: role %s not found", helper.ErrVaultFailToGenerateAzureCredentials, role)
+	}
+
+	if e.clientID == "" || e.clientSecret == "" {
+		return nil, fmt.Errorf("%w: credentials of service principal not configured", helper.ErrVaultFailToGenerateAzureCredentials)
+	}
+
+	duration, err := leaseDuration(e.defaultLeaseTTL)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", helper.ErrVaultFailToGenerateAzureCredentials, err.Error())
+	}
+
+	var credsResponse data.CredsAzureConnectionResponse
+
+	credsResponse.LeaseID = path + "/creds/" + role + "/" + uuid.New().String()
+	credsResponse.LeaseDuration = duration
+	credsResponse.Renewable = true
+	credsResponse.RoleName = role
+	credsResponse.Data.ClientID = uuid.New().String()
+	credsResponse.Data.ClientSecret = randomString(charsetSecretKey, 40)
+
+	mb.issueLease(credsResponse.LeaseID, namespace, duration, credsResponse.Renewable)
+
+	return &credsResponse, nil
+}
+
+func (mb *MemoryBackend) GetGCPSecretsEngine(c *data.GCPConnection, ctx context.Context) error {
+	mb.mu.Lock()
+	defer mb.mu.Unlock()
+
+	e, err := mountedEngine[*memoryGCPEngine](mb, c.VaultPath, c.VaultNamespace)
+	if err != nil {
+		return err
+	}
+
+	if e.roleType != c.RoleType || e.roleName != c.RoleName {
+		return fmt.Errorf("%w: role %s not found", helper.ErrVaultFailToReadSecretsEngine, c.RoleName)
+	}
+
+	c.DefaultLeaseTTL = e.defaultLeaseTTL
+	c.MaxLeaseTTL = e.maxLeaseTTL
+	c.SecretType = e.secretType
+	c.Project = e.project
+	c.ServiceAccountEmail = e.serviceAccountEmail
+	c.Bindings = e.bindings
+	c.TokenScopes = e.tokenScopes
+
+	return nil
+}
+
+func (mb *MemoryBackend) AddGCPSecretsEngine(c *data.GCPConnection, ctx context.Context) error {
+	mb.mu.Lock()
+	defer mb.mu.Unlock()
+
+	e := &memoryGCPEngine{}
+
+	return mb.mountEngine(c.VaultPath, c.VaultNamespace, e, helper.ErrVaultFailToEnableSecretsEngine, func() error {
+		return mb.configureGCPSecretsEngine(e, c)
+	})
+}
+
+func (mb *MemoryBackend) UpdateGCPSecretsEngine(c *data.GCPConnection, ctx context.Context) error {
+	return reconfigureEngine(mb, c.VaultPath, c.VaultNamespace, func(e *memoryGCPEngine) error {
+		return mb.configureGCPSecretsEngine(e, c)
+	})
+}
+
+// configureGCPSecretsEngine sets configuration, lease settings and role of engine. Stored credentials are kept if
+// c does not carry any the same way as for Vault. Caller must hold mu.
+func (mb *MemoryBackend) configureGCPSecretsEngine(e *memoryGCPEngine, c *data.GCPConnection) error {
+	if err := validateLeaseTTLs(helper.ErrVaultFailToConfigureSecretsEngine, c.DefaultLeaseTTL, c.MaxLeaseTTL); err != nil {
+		return err
+	}
+
+	if c.Credentials != "" && !json.Valid([]byte(c.Credentials)) {
+		return fmt.Errorf("%w: credentials are not valid JSON", helper.ErrVaultFailToConfigureSecretsEngine)
+	}
+
+	if !c.HasRole() {
+		return fmt.Errorf("%w: role %s is missing attributes required for its type", helper.ErrVaultFailToConfigureSecretsEngine, c.RoleName)
+	}
+
+	if c.Credentials != "" {
+		e.credentials = c.Credentials
+	}
+	e.defaultLeaseTTL = c.DefaultLeaseTTL
+	e.maxLeaseTTL = c.MaxLeaseTTL
+	e.roleType = c.RoleType
+	e.roleName = c.RoleName
+	e.secretType = c.SecretType
+	e.project = c.Project
+	e.serviceAccountEmail = c.ServiceAccountEmail
+	e.bindings = c.Bindings
+	e.tokenScopes = c.TokenScopes
+
+	// Vault creates service account of roleset in project of roleset
+	if c.RoleType == data.GCPRoleTypeRoleset {
+		e.serviceAccountEmail = fmt.Sprintf("vault%s-%s@%s.iam.gserviceaccount.com", c.RoleName, strings.ToLower(randomString(charsetAccessKey, 8)), c.Project)
+	}
+
+	return nil
+}
+
+func (mb *MemoryBackend) RemoveGCPSecretsEngine(c *data.GCPConnection, ctx context.Context) error {
+	return mb.DisableSecretsEngineMount(c.VaultPath, c.VaultNamespace, ctx)
+}
+
+func (mb *MemoryBackend) TestGCPSecretsEngine(path string, namespace string, roleType string, role string, secretType string, ctx context.Context) error {
+	creds, err := mb.GenerateCredsGCPSecretsEngine(path, namespace, roleType, role, secretType, ctx)
+	if err != nil {
+		return err
+	}
+
+	if creds.LeaseID == "" {
+		return nil
+	}
+
+	return mb.RevokeLease(creds.LeaseID, namespace, ctx)
+}
+
+func (mb *MemoryBackend) GenerateCredsGCPSecretsEngine(path string, namespace string, roleType string, role string, secretType string, ctx context.Context) (*data.CredsGCPConnectionResponse, error) {
+	mb.mu.Lock()
+	defer mb.mu.Unlock()
+
+	e, err := mountedEngine[*memoryGCPEngine](mb, path, namespace)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", helper.ErrVaultFailToGenerateGCPCredentials, err.Error())
+	}
+
+	if e.roleType != roleType || e.roleName != role {
+		return nil, fmt.Errorf("%w: role %s not found", helper.ErrVaultFailToGenerateGCPCredentials, role)
+	}
+
+	if e.secretType != secretType {
+		return nil, fmt.Errorf("%w: role %s does not generate %s", helper.ErrVaultFailToGenerateGCPCredentials, role, secretType)
+	}
+
+	if e.credentials == "" {
+		return nil, fmt.Errorf("%w: credentials of service account not configured", helper.ErrVaultFailToGenerateGCPCredentials)
+	}
+
+	var credsResponse data.CredsGCPConnectionResponse
+
+	credsResponse.RoleName = role
+	credsResponse.SecretType = secretType
+
+	// Access tokens are valid for an hour and not leased by Vault
+	if secretType == data.GCPSecretTypeAccessToken {
+		credsResponse.Data.Token = "ya29." + randomString(charsetSecretKey, 60)
+		credsResponse.Data.TokenTTL = 3599
+		credsResponse.Data.ExpiresAtSeconds = time.Now().Add(3599 * time.Second).Unix()
+		return &credsResponse, nil
+	}
+
+	duration, err := leaseDuration(e.defaultLeaseTTL)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", helper.ErrVaultFailToGenerateGCPCredentials, err.Error())
+	}
+
+	credsResponse.LeaseID = path + "/" + strings.ReplaceAll(roleType, "_", "-") + "/" + role + "/key/" + uuid.New().String()
+	credsResponse.LeaseDuration = duration
+	credsResponse.Renewable = true
+	credsResponse.Data.PrivateKeyData = randomString(charsetSecretKey, 64)
+	credsResponse.Data.KeyAlgorithm = "KEY_ALG_RSA_2048"
+	credsResponse.Data.KeyType = "TYPE_GOOGLE_CREDENTIALS_FILE"
+
+	mb.issueLease(credsResponse.LeaseID, namespace, duration, credsResponse.Renewable)
+
+	return &credsResponse, nil
+}
+
+func (mb *MemoryBackend) GetDatabaseSecretsEngine(c *data.DatabaseConnection, ctx context.Context) error {
+	mb.mu.Lock()
+	defer mb.mu.Unlock()
+
+	e, err := mountedEngine[*memoryDatabaseEngine](mb, c.VaultPath, c.VaultNamespace)
+	if err != nil {
+		return err
+	}
+
+	if e.roleName != c.RoleName {
+		return fmt.Errorf("%w: role %s not found", helper.ErrVaultFailToReadSecretsEngine, c.RoleName)
+	}
+
+	c.PluginName = e.pluginName
+	c.ConnectionURL = e.connectionURL
+	c.Username = e.username
+	c.DefaultLeaseTTL = e.defaultLeaseTTL
+	c.MaxLeaseTTL = e.maxLeaseTTL
+	c.CreationStatements = e.creationStatements
+	c.RevocationStatements = e.revocationStatements
+
+	return nil
+}
+
+func (mb *MemoryBackend) AddDatabaseSecretsEngine(c *data.DatabaseConnection, ctx context.Context) error {
+	mb.mu.Lock()
+	defer mb.mu.Unlock()
+
+	e := &memoryDatabaseEngine{}
+
+	return mb.mountEngine(c.VaultPath, c.VaultNamespace, e, helper.ErrVaultFailToEnableSecretsEngine, func() error {
+		return mb.configureDatabaseSecretsEngine(e, c)
+	})
+}
+
+func (mb *MemoryBackend) UpdateDatabaseSecretsEngine(c *data.DatabaseConnection, ctx context.Context) error {
+	return reconfigureEngine(mb, c.VaultPath, c.VaultNamespace, func(e *memoryDatabaseEngine) error {
+		return mb.configureDatabaseSecretsEngine(e, c)
+	})
+}
+
+// configureDatabaseSecretsEngine sets configuration, lease settings and role of engine. Stored password is kept if
+// c does not carry one the same way as for Vault. Database is not connected to, so configuration is never
+// refused for being unreachable. Caller must hold mu.
+func (mb *MemoryBackend) configureDatabaseSecretsEngine(e *memoryDatabaseEngine, c *data.DatabaseConnection) error {
+	if err := validateLeaseTTLs(helper.ErrVaultFailToConfigureSecretsEngine, c.DefaultLeaseTTL, c.MaxLeaseTTL); err != nil {
+		return err
+	}
+
+	if len(c.CreationStatements) == 0 {
+		return fmt.Errorf("%w: creation_statements are required", helper.ErrVaultFailToConfigureSecretsEngine)
+	}
+
+	e.pluginName = c.PluginName
+	e.connectionURL = c.ConnectionURL
+	e.username = c.Username
+	if c.Password != "" {
+		e.password = c.Password
+	}
+	e.defaultLeaseTTL = c.DefaultLeaseTTL
+	e.maxLeaseTTL = c.MaxLeaseTTL
+	e.roleName = c.RoleName
+	e.creationStatements = c.CreationStatements
+	e.revocationStatements = c.RevocationStatements
+
+	return nil
+}
+
+func (mb *MemoryBackend) RemoveDatabaseSecretsEngine(c *data.DatabaseConnection, ctx context.Context) error {
+	return mb.DisableSecretsEngineMount(c.VaultPath, c.VaultNamespace, ctx)
+}
+
+func (mb *MemoryBackend) TestDatabaseSecretsEngine(path string, namespace string, role string, ctx context.Context) error {
+	creds, err := mb.GenerateCredsDatabaseSecretsEngine(path, namespace, role, ctx)
+	if err != nil {
+		return err
+	}
+
+	return mb.RevokeLease(creds.LeaseID, namespace, ctx)
+}
+
+func (mb *MemoryBackend) GenerateCredsDatabaseSecretsEngine(path string, namespace string, role string, ctx context.Context) (*data.CredsDatabaseConnectionResponse, error) {
+	mb.mu.Lock()
+	defer mb.mu.Unlock()
+
+	e, err := mountedEngine[*memoryDatabaseEngine](mb, path, namespace)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", helper.ErrVaultFailToGenerateDatabaseCredentials, err.Error())
+	}
+
+	if e.roleName != role {
+		return nil, fmt.Errorf("%w: role %s not found", helper.ErrVaultFailToGenerateDatabaseCredentials, role)
+	}
+
+	if e.username == "" || e.password == "" {
+		return nil, fmt.Errorf("%w: credentials of root user not configured", helper.ErrVaultFailToGenerateDatabaseCredentials)
+	}
+
+	duration, err := leaseDuration(e.defaultLeaseTTL)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", helper.ErrVaultFailToGenerateDatabaseCredentials, err.Error())
+	}
+
+	var credsResponse data.CredsDatabaseConnectionResponse
+
+	credsResponse.LeaseID = path + "/creds/" + role + "/" + uuid.New().String()
+	credsResponse.LeaseDuration = duration
+	credsResponse.Renewable = true
+	credsResponse.RoleName = role
+	// Username follows default username template of Vault i.e. v-<display name>-<role>-<random>-<unix time>
+	credsResponse.Data.Username = fmt.Sprintf("v-token-%s-%s-%d", role, randomString(charsetSecretKey[26:62], 20), time.Now().Unix())
+	credsResponse.Data.Password = "A1a-" + randomString(charsetSecretKey[:62], 20)
+
+	mb.issueLease(credsResponse.LeaseID, namespace, duration, credsResponse.Renewable)
+
+	return &credsResponse, nil
+}
+
+// sshEngine returns SSH secrets engine mounted at path in namespace. Caller must hold mu.
+func (mb *MemoryBackend) sshEngine(path string, namespace string) (*memorySSHEngine, error) {
+	e, err := mountedEngine[*memorySSHEngine](mb, path, namespace)
+	if err != nil {
+		return nil, err
+	}
+	if e.signer == nil {
+		return nil, fmt.Errorf("%w: no CA configured for secrets engine mounted at %s", helper.ErrNotFound, path)
+	}
+	return e, nil
+}
+
+func (mb *MemoryBackend) GetSSHSecretsEngine(c *data.SSHConnection, ctx context.Context) error {
+	mb.mu.Lock()
+	defer mb.mu.Unlock()
+
+	e, err := mb.sshEngine(c.VaultPath, c.VaultNamespace)
+	if err != nil {
+		return err
+	}
+
+	if e.roleName != c.RoleName {
+		return fmt.Errorf("%w: role %s not found", helper.ErrVaultFailToReadSecretsEngine, c.RoleName)
+	}
+
+	c.PublicKey = strings.TrimSpace(string(ssh.MarshalAuthorizedKey(e.signer.PublicKey())))
+	c.DefaultLeaseTTL = e.defaultLeaseTTL
+	c.MaxLeaseTTL = e.maxLeaseTTL
+	c.AllowedUsers = e.allowedUsers
+	c.DefaultUser = e.defaultUser
+	c.AllowedDomains = e.allowedDomains
+	c.AllowSubdomains = e.allowSubdomains
+	c.DefaultExtensions = e.defaultExtensions
+
+	return nil
+}
+
+// AddSSHSecretsEngine mounts engine signing with key pair of connection or, if it carries none, with generated
+// ed25519 key pair.
+func (mb *MemoryBackend) AddSSHSecretsEngine(c *data.SSHConnection, ctx context.Context) error {
+	mb.mu.Lock()
+	defer mb.mu.Unlock()
+
+	e := &memorySSHEngine{}
+
+	return mb.mountEngine(c.VaultPath, c.VaultNamespace, e, helper.ErrVaultFailToEnableSecretsEngine, func() error {
+		return mb.configureSSHSigner(e, c)
+	})
+}
+
+// configureSSHSigner configures engine to sign with key pair of connection or, if it carries none, with generated
+// ed25519 key pair. Caller must hold mu.
+func (mb *MemoryBackend) configureSSHSigner(e *memorySSHEngine, c *data.SSHConnection) error {
+	if c.PrivateKey != "" {
+		signer, err := ssh.ParsePrivateKey([]byte(c.PrivateKey))
+		if err != nil {
+			return fmt.Errorf("%w: %s", helper.ErrVaultFailToConfigureSecretsEngine, err.Error())
+		}
+		e.signer = signer
+	} else {
+		_, private, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return fmt.Errorf("%w: %s", helper.ErrVaultFailToConfigureSecretsEngine, err.Error())
+		}
+		if e.signer, err = ssh.NewSignerFromKey(private); err != nil {
+			return fmt.Errorf("%w: %s", helper.ErrVaultFailToConfigureSecretsEngine, err.Error())
+		}
+	}
+
+	if err := mb.configureSSHSecretsEngine(e, c); err != nil {
+		return err
+	}
+
+	c.PublicKey = strings.TrimSpace(string(ssh.MarshalAuthorizedKey(e.signer.PublicKey())))
+
+	return nil
+}
+
+func (mb *MemoryBackend) UpdateSSHSecretsEngine(c *data.SSHConnection, ctx context.Context) error {
+	return reconfigureEngine(mb, c.VaultPath, c.VaultNamespace, func(e *memorySSHEngine) error {
+		return mb.configureSSHSecretsEngine(e, c)
+	})
+}
+
+// configureSSHSecretsEngine sets lease settings and role of engine. Caller must hold mu.
+func (mb *MemoryBackend) configureSSHSecretsEngine(e *memorySSHEngine, c *data.SSHConnection) error {
+	if err := validateLeaseTTLs(helper.ErrVaultFailToConfigureSecretsEngine, c.DefaultLeaseTTL, c.MaxLeaseTTL); err != nil {
+		return err
+	}
+
+	if c.CertType != data.SSHCertTypeUser && c.CertType != data.SSHCertTypeHost {
+		return fmt.Errorf("%w: unsupported certificate type %s", helper.ErrVaultFailToConfigureSecretsEngine, c.CertType)
+	}
+
+	e.defaultLeaseTTL = c.DefaultLeaseTTL
+	e.maxLeaseTTL = c.MaxLeaseTTL
+	e.roleName = c.RoleName
+	e.certType = c.CertType
+	e.allowedUsers = c.AllowedUsers
+	e.defaultUser = c.DefaultUser
+	e.allowedDomains = c.AllowedDomains
+	e.allowSubdomains = c.AllowSubdomains
+	e.defaultExtensions = c.DefaultExtensions
+
+	return nil
+}
+
+func (mb *MemoryBackend) RemoveSSHSecretsEngine(c *data.SSHConnection, ctx context.Context) error {
+	return mb.DisableSecretsEngineMount(c.VaultPath, c.VaultNamespace, ctx)
+}
+
+func (mb *MemoryBackend) GetSSHCAPublicKey(path string, namespace string, ctx context.Context) (string, error) {
+	mb.mu.Lock()
+	defer mb.mu.Unlock()
+
+	e, err := mb.sshEngine(path, namespace)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(string(ssh.MarshalAuthorizedKey(e.signer.PublicKey()))), nil
+}
+
+func (mb *MemoryBackend) TestSSHSecretsEngine(path string, namespace string, role string, certType string, principal string, ctx context.Context) error {
+	caPublicKey, err := mb.GetSSHCAPublicKey(path, namespace, ctx)
+	if err != nil {
+		return err
+	}
+
+	return testSSHSigning(func(r *data.SignSSHConnectionRequest) (*data.SignSSHConnectionResponse, error) {
+		return mb.SignSSHSecretsEngine(path, namespace, role, certType, r, ctx)
+	}, caPublicKey, principal)
+}
+
+// allowsPrincipal tells whether role of engine allows signing certificate for principal the same way as Vault.
+func (e *memorySSHEngine) allowsPrincipal(principal string) bool {
+	if e.certType == data.SSHCertTypeUser {
+		for _, u := range e.allowedUsers {
+			if u == "*" || u == principal {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, d := range e.allowedDomains {
+		if d == principal || (e.allowSubdomains && strings.HasSuffix(principal, "."+d)) {
+			return true
+		}
+	}
+	return false
+}
+
+func (mb *MemoryBackend) SignSSHSecretsEngine(path string, namespace string, role string, certType string, r *data.SignSSHConnectionRequest, ctx context.Context) (*data.SignSSHConnectionResponse, error) {
+	mb.mu.Lock()
+	defer mb.mu.Unlock()
+
+	e, err := mb.sshEngine(path, namespace)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", helper.ErrVaultFailToSignSSHKey, err.Error())
+	}
+
+	if e.roleName != role {
+		return nil, fmt.Errorf("%w: role %s not found", helper.ErrVaultFailToSignSSHKey, role)
+	}
+
+	if e.certType != certType {
+		return nil, fmt.Errorf("%w: role %s does not sign %s certificates", helper.ErrVaultFailToSignSSHKey, role, certType)
+	}
+
+	key, _, _, _, err := ssh.ParseAuthorizedKey([]byte(r.PublicKey))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", helper.ErrVaultFailToSignSSHKey, err.Error())
+	}
+
+	principals := r.ValidPrincipals
+	if len(principals) == 0 && e.defaultUser != "" {
+		principals = []string{e.defaultUser}
+	}
+
+	for _, p := range principals {
+		if !e.allowsPrincipal(p) {
+			return nil, fmt.Errorf("%w: %s is not a valid value for valid_principals", helper.ErrVaultFailToSignSSHKey, p)
+		}
+	}
+
+	ttl := r.TTL
+	if ttl == "" {
+		ttl = e.defaultLeaseTTL
+	}
+
+	duration, err := leaseDuration(ttl)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", helper.ErrVaultFailToSignSSHKey, err.Error())
+	}
+
+	serial, err := rand.Int(rand.Reader, new(big.Int).SetUint64(^uint64(0)))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", helper.ErrVaultFailToSignSSHKey, err.Error())
+	}
+
+	cert := &ssh.Certificate{
+		Key:             key,
+		Serial:          serial.Uint64(),
+		CertType:        ssh.UserCert,
+		KeyId:           "vault-" + role + "-" + uuid.New().String(),
+		ValidPrincipals: principals,
+		// Vault backdates certificates by 30 seconds to allow for clock skew
+		ValidAfter:  uint64(time.Now().Add(-30 * time.Second).Unix()),
+		ValidBefore: uint64(time.Now().Add(time.Duration(duration) * time.Second).Unix()),
+	}
+
+	if certType == data.SSHCertTypeHost {
+		cert.CertType = ssh.HostCert
+	} else {
+		cert.Permissions.Extensions = map[string]string{}
+		for _, ext := range e.defaultExtensions {
+			cert.Permissions.Extensions[ext] = ""
+		}
+	}
+
+	if err := cert.SignCert(rand.Reader, e.signer); err != nil {
+		return nil, fmt.Errorf("%w: %s", helper.ErrVaultFailToSignSSHKey, err.Error())
+	}
+
+	return sshSignResponse(string(ssh.MarshalAuthorizedKey(cert)), "", role)
+}
+
+// pkiEngine returns PKI secrets engine mounted at path in namespace. Caller must hold mu.
+func (mb *MemoryBackend) pkiEngine(path string, namespace string) (*memoryPKIEngine, error) {
+	e, err := mountedEngine[*memoryPKIEngine](mb, path, namespace)
+	if err != nil {
+		return nil, err
+	}
+	if e.certificate == nil {
+		return nil, fmt.Errorf("%w: no CA configured for secrets engine mounted at %s", helper.ErrNotFound, path)
+	}
+	return e, nil
+}
+
+// pkiSerialNumber formats serial number of certificate as colon separated hex the same way as Vault.
+func pkiSerialNumber(serial *big.Int) string {
+	b := serial.Bytes()
+	parts := make([]string, len(b))
+	for i, v := range b {
+		parts[i] = fmt.Sprintf("%02x", v)
+	}
+	return strings.Join(parts, ":")
+}
+
+// newPKISerialNumber returns random positive serial number of 159 bits, which fits into 20 bytes RFC 5280 allows.
+func newPKISerialNumber() (*big.Int, error) {
+	return rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 159))
+}
+
+// generatePKIKey generates private key of type the same way as Vault, which uses rsa if type is empty.
+func generatePKIKey(keyType string) (crypto.Signer, error) {
+	switch keyType {
+	case "", "rsa":
+		return rsa.GenerateKey(rand.Reader, 2048)
+	case "ec":
+		return ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case "ed25519":
+		_, private, err := ed25519.GenerateKey(rand.Reader)
+		return private, err
+	default:
+		return nil, fmt.Errorf("unsupported key type %s", keyType)
+	}
+}
+
+func pemCertificate(cert *x509.Certificate) string {
+	return strings.TrimSpace(string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})))
+}
+
+func (mb *MemoryBackend) GetPKISecretsEngine(c *data.PKIConnection, ctx context.Context) error {
+	mb.mu.Lock()
+	defer mb.mu.Unlock()
+
+	e, err := mb.pkiEngine(c.VaultPath, c.VaultNamespace)
+	if err != nil {
+		return err
+	}
+
+	role, found := e.roles[c.RoleName]
+	if !found {
+		return fmt.Errorf("%w: role %s not found", helper.ErrVaultFailToReadSecretsEngine, c.RoleName)
+	}
+
+	c.Certificate = pemCertificate(e.certificate)
+	c.CommonName = e.certificate.Subject.CommonName
+	c.KeyType = role.KeyType
+	c.DefaultLeaseTTL = e.defaultLeaseTTL
+	c.MaxLeaseTTL = e.maxLeaseTTL
+	c.AllowedDomains = role.AllowedDomains
+	c.AllowSubdomains = role.AllowSubdomains
+	c.AllowBareDomains = role.AllowBareDomains
+
+	return nil
+}
+
+// AddPKISecretsEngine mounts engine with CA valid for max lease ttl. CA is self signed unless issuer is provided,
+// in which case it is signed by CA of issuer the same way as Vault signs intermediate CA.
+func (mb *MemoryBackend) AddPKISecretsEngine(c *data.PKIConnection, issuer *data.PKIConnection, ctx context.Context) error {
+	mb.mu.Lock()
+	defer mb.mu.Unlock()
+
+	e := &memoryPKIEngine{roles: map[string]data.PKIRole{}, issued: map[string]*big.Int{}, revoked: map[string]time.Time{}}
+
+	return mb.mountEngine(c.VaultPath, c.VaultNamespace, e, helper.ErrVaultFailToEnableSecretsEngine, func() error {
+		return mb.configurePKICA(e, c, issuer)
+	})
+}
+
+// configurePKICA configures engine with CA valid for max lease ttl, self signed or signed by CA of issuer.
+// Caller must hold mu.
+func (mb *MemoryBackend) configurePKICA(e *memoryPKIEngine, c *data.PKIConnection, issuer *data.PKIConnection) error {
+	if err := mb.configurePKISecretsEngine(e, c); err != nil {
+		return err
+	}
+
+	duration, err := leaseDuration(c.MaxLeaseTTL)
+	if err != nil {
+		return fmt.Errorf("%w: %s", helper.ErrVaultFailToConfigureSecretsEngine, err.Error())
+	}
+
+	if e.signer, err = generatePKIKey(e.keyType); err != nil {
+		return fmt.Errorf("%w: %s", helper.ErrVaultFailToConfigureSecretsEngine, err.Error())
+	}
+
+	serial, err := newPKISerialNumber()
+	if err != nil {
+		return fmt.Errorf("%w: %s", helper.ErrVaultFailToConfigureSecretsEngine, err.Error())
+	}
+
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: c.CommonName},
+		NotBefore:             now.Add(-30 * time.Second),
+		NotAfter:              now.Add(time.Duration(duration) * time.Second),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature,
+	}
+
+	parent, parentSigner := template, e.signer
+
+	if issuer != nil {
+		ie, err := mb.pkiEngine(issuer.VaultPath, issuer.VaultNamespace)
+		if err != nil {
+			return fmt.Errorf("%w: %s", helper.ErrVaultFailToConfigureSecretsEngine, err.Error())
+		}
+
+		if template.NotAfter.After(ie.certificate.NotAfter) {
+			return fmt.Errorf("%w: cannot satisfy request, as TTL would result in notAfter %s that is beyond the expiration of the CA certificate at %s", helper.ErrVaultFailToConfigureSecretsEngine, template.NotAfter.UTC().Format(time.RFC3339), ie.certificate.NotAfter.UTC().Format(time.RFC3339))
+		}
+
+		ie.setDistributionURLs(template)
+		parent, parentSigner = ie.certificate, ie.signer
+		e.chain = append([]*x509.Certificate{ie.certificate}, ie.chain...)
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, e.signer.Public(), parentSigner)
+	if err != nil {
+		return fmt.Errorf("%w: %s", helper.ErrVaultFailToConfigureSecretsEngine, err.Error())
+	}
+
+	if e.certificate, err = x509.ParseCertificate(der); err != nil {
+		return fmt.Errorf("%w: %s", helper.ErrVaultFailToConfigureSecretsEngine, err.Error())
+	}
+
+	e.issuingURL, e.crlURL, e.ocspURL = pkiDistributionURLs(mb.c, "", c)
+
+	c.Certificate = pemCertificate(e.certificate)
+
+	return nil
+}
+
+func (mb *MemoryBackend) UpdatePKISecretsEngine(c *data.PKIConnection, ctx context.Context) error {
+	return reconfigureEngine(mb, c.VaultPath, c.VaultNamespace, func(e *memoryPKIEngine) error {
+		return mb.configurePKISecretsEngine(e, c)
+	})
+}
+
+// configurePKISecretsEngine sets lease settings and default role of engine. Caller must hold mu.
+func (mb *MemoryBackend) configurePKISecretsEngine(e *memoryPKIEngine, c *data.PKIConnection) error {
+	if err := validateLeaseTTLs(helper.ErrVaultFailToConfigureSecretsEngine, c.DefaultLeaseTTL, c.MaxLeaseTTL); err != nil {
+		return err
+	}
+
+	if err := e.setRole(c.DefaultRole()); err != nil {
+		return err
+	}
+
+	e.defaultLeaseTTL = c.DefaultLeaseTTL
+	e.maxLeaseTTL = c.MaxLeaseTTL
+
+	// Key of CA is generated only once, later updates only change default role
+	if e.keyType == "" {
+		e.keyType = e.roles[c.RoleName].KeyType
+	}
+
+	return nil
+}
+
+// setRole writes role with key type Vault defaults to when it is not set.
+func (e *memoryPKIEngine) setRole(r *data.PKIRole) error {
+	role := *r
+	if role.KeyType == "" {
+		role.KeyType = "rsa"
+	}
+
+	if role.KeyType != "rsa" && role.KeyType != "ec" && role.KeyType != "ed25519" {
+		return fmt.Errorf("%w: unsupported key type %s", helper.ErrVaultFailToConfigureSecretsEngine, role.KeyType)
+	}
+
+	e.roles[role.RoleName] = role
+
+	return nil
+}
+
+func (mb *MemoryBackend) RemovePKISecretsEngine(c *data.PKIConnection, ctx context.Context) error {
+	return mb.DisableSecretsEngineMount(c.VaultPath, c.VaultNamespace, ctx)
+}
+
+func (mb *MemoryBackend) TestPKISecretsEngine(path string, namespace string, role string, commonName string, ctx context.Context) error {
+	caCertificate, err := mb.GetPKICACertificate(path, namespace, ctx)
+	if err != nil {
+		return err
+	}
+
+	return testPKIIssuance(func(r *data.IssuePKIConnectionRequest) (*data.IssuePKIConnectionResponse, error) {
+		return mb.IssuePKISecretsEngine(path, namespace, role, r, ctx)
+	}, func(serialNumber string) error {
+		return mb.RevokePKICertificate(path, namespace, serialNumber, ctx)
+	}, caCertificate, commonName)
+}
+
+// pkiRoleAllowsName tells whether role allows issuing certificate for name the same way as Vault.
+func pkiRoleAllowsName(role data.PKIRole, name string) bool {
+	for _, d := range role.AllowedDomains {
+		if (role.AllowBareDomains && name == d) || (role.AllowSubdomains && strings.HasSuffix(name, "."+d)) {
+			return true
+		}
+	}
+	return false
+}
+
+// setDistributionURLs sets URLs of CA certificate, CRL and OCSP responder of engine in certificate it issues.
+func (e *memoryPKIEngine) setDistributionURLs(template *x509.Certificate) {
+	if e.issuingURL != "" {
+		template.IssuingCertificateURL = []string{e.issuingURL}
+	}
+	if e.crlURL != "" {
+		template.CRLDistributionPoints = []string{e.crlURL}
+	}
+	if e.ocspURL != "" {
+		template.OCSPServer = []string{e.ocspURL}
+	}
+}
+
+func (mb *MemoryBackend) IssuePKISecretsEngine(path string, namespace string, role string, r *data.IssuePKIConnectionRequest, ctx context.Context) (*data.IssuePKIConnectionResponse, error) {
+	mb.mu.Lock()
+	defer mb.mu.Unlock()
+
+	e, err := mb.pkiEngine(path, namespace)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", helper.ErrVaultFailToIssuePKICertificate, err.Error())
+	}
+
+	pkiRole, found := e.roles[role]
+	if !found {
+		return nil, fmt.Errorf("%w: role %s not found", helper.ErrVaultFailToIssuePKICertificate, role)
+	}
+
+	if !pkiRoleAllowsName(pkiRole, r.CommonName) {
+		return nil, fmt.Errorf("%w: common name %s not allowed by this role", helper.ErrVaultFailToIssuePKICertificate, r.CommonName)
+	}
+
+	names := []string{r.CommonName}
+	for _, n := range r.AltNames {
+		if !pkiRoleAllowsName(pkiRole, n) {
+			return nil, fmt.Errorf("%w: subject alternate name %s not allowed by this role", helper.ErrVaultFailToIssuePKICertificate, n)
+		}
+		if n != r.CommonName {
+			names = append(names, n)
+		}
+	}
+
+	var ips []net.IP
+	for _, s := range r.IPSANs {
+		ip := net.ParseIP(s)
+		if ip == nil {
+			return nil, fmt.Errorf("%w: the value %q is not a valid IP address", helper.ErrVaultFailToIssuePKICertificate, s)
+		}
+		ips = append(ips, ip)
+	}
+
+	ttl := r.TTL
+	if ttl == "" {
+		ttl = e.defaultLeaseTTL
+	}
+
+	duration, err := leaseDuration(ttl)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", helper.ErrVaultFailToIssuePKICertificate, err.Error())
+	}
+
+	// Vault caps validity at max lease ttl of mount rather than refusing request
+	if maxDuration, _ := durationToSeconds(e.maxLeaseTTL); maxDuration > 0 && duration > maxDuration {
+		duration = maxDuration
+	}
+
+	// Certificates encode validity with second precision, compare in same precision as CA certificate
+	now := time.Now()
+	notAfter := now.Add(time.Duration(duration) * time.Second).Truncate(time.Second)
+
+	if notAfter.After(e.certificate.NotAfter) {
+		return nil, fmt.Errorf("%w: cannot satisfy request, as TTL would result in notAfter %s that is beyond the expiration of the CA certificate at %s", helper.ErrVaultFailToIssuePKICertificate, notAfter.UTC().Format(time.RFC3339), e.certificate.NotAfter.UTC().Format(time.RFC3339))
+	}
+
+	key, err := generatePKIKey(pkiRole.KeyType)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", helper.ErrVaultFailToIssuePKICertificate, err.Error())
+	}
+
+	serial, err := newPKISerialNumber()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", helper.ErrVaultFailToIssuePKICertificate, err.Error())
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: r.CommonName},
+		DNSNames:     names,
+		IPAddresses:  ips,
+		// Vault backdates certificates by 30 seconds to allow for clock skew
+		NotBefore:             now.Add(-30 * time.Second),
+		NotAfter:              notAfter,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment | x509.KeyUsageKeyAgreement,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+	}
+	e.setDistributionURLs(template)
+
+	der, err := x509.CreateCertificate(rand.Reader, template, e.certificate, key.Public(), e.signer)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", helper.ErrVaultFailToIssuePKICertificate, err.Error())
+	}
+
+	privateKey, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", helper.ErrVaultFailToIssuePKICertificate, err.Error())
+	}
+
+	var response data.IssuePKIConnectionResponse
+
+	response.RoleName = role
+	response.SerialNumber = pkiSerialNumber(serial)
+	response.Certificate = strings.TrimSpace(string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})))
+	response.IssuingCA = pemCertificate(e.certificate)
+	response.CAChain = []string{response.IssuingCA}
+	for _, cert := range e.chain {
+		response.CAChain = append(response.CAChain, pemCertificate(cert))
+	}
+	response.PrivateKey = strings.TrimSpace(string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateKey})))
+	response.PrivateKeyType = pkiRole.KeyType
+	response.ExpiresAt = notAfter.Truncate(time.Second).UTC()
+
+	e.issued[response.SerialNumber] = serial
+
+	return &response, nil
+}
+
+// RevokePKICertificate revokes certificate issued by engine. Serial number is accepted in colon or hyphen
+// separated format the same way as Vault, and revoking revoked certificate is no error.
+func (mb *MemoryBackend) RevokePKICertificate(path string, namespace string, serialNumber string, ctx context.Context) error {
+	mb.mu.Lock()
+	defer mb.mu.Unlock()
+
+	e, err := mb.pkiEngine(path, namespace)
+	if err != nil {
+		return fmt.Errorf("%w: %s", helper.ErrVaultFailToIssuePKICertificate, err.Error())
+	}
+
+	serialNumber = strings.ToLower(strings.ReplaceAll(serialNumber, "-", ":"))
+
+	if _, found := e.issued[serialNumber]; !found {
+		return fmt.Errorf("%w: certificate with serial %s not found", helper.ErrVaultFailToIssuePKICertificate, serialNumber)
+	}
+
+	if _, revoked := e.revoked[serialNumber]; !revoked {
+		e.revoked[serialNumber] = time.Now().UTC()
+	}
+
+	return nil
+}
+
+func (mb *MemoryBackend) GetPKICACertificate(path string, namespace string, ctx context.Context) ([]byte, error) {
+	mb.mu.Lock()
+	defer mb.mu.Unlock()
+
+	e, err := mb.pkiEngine(path, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte(nil), e.certificate.Raw...), nil
+}
+
+// GetPKICRL builds CRL of revoked certificates of engine. CRL is valid for 72h, which is default expiry of CRLs
+// of Vault.
+func (mb *MemoryBackend) GetPKICRL(path string, namespace string, ctx context.Context) ([]byte, error) {
+	mb.mu.Lock()
+	defer mb.mu.Unlock()
+
+	e, err := mb.pkiEngine(path, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	serials := make([]string, 0, len(e.revoked))
+	for s := range e.revoked {
+		serials = append(serials, s)
+	}
+	sort.Strings(serials)
+
+	var entries []x509.RevocationListEntry
+	for _, s := range serials {
+		entries = append(entries, x509.RevocationListEntry{SerialNumber: e.issued[s], RevocationTime: e.revoked[s]})
+	}
+
+	e.crlNumber++
+
+	now := time.Now()
+	template := &x509.RevocationList{
+		Number:                    big.NewInt(e.crlNumber),
+		ThisUpdate:                now,
+		NextUpdate:                now.Add(72 * time.Hour),
+		RevokedCertificateEntries: entries,
+	}
+
+	crl, err := x509.CreateRevocationList(rand.Reader, template, e.certificate, e.signer)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", helper.ErrVaultFailToReadSecretsEngine, err.Error())
+	}
+
+	return crl, nil
+}
+
+// GetPKIOCSPResponse answers OCSP request with status of certificate signed by CA of engine. Certificates engine
+// did not issue are reported as unknown.
+func (mb *MemoryBackend) GetPKIOCSPResponse(path string, namespace string, request []byte, ctx context.Context) ([]byte, error) {
+	mb.mu.Lock()
+	defer mb.mu.Unlock()
+
+	e, err := mb.pkiEngine(path, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := ocsp.ParseRequest(request)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", helper.ErrVaultFailToReadSecretsEngine, err.Error())
+	}
+
+	serial := pkiSerialNumber(req.SerialNumber)
+
+	now := time.Now()
+	template := ocsp.Response{
+		SerialNumber: req.SerialNumber,
+		Status:       ocsp.Unknown,
+		ThisUpdate:   now,
+		NextUpdate:   now.Add(12 * time.Hour),
+	}
+
+	if _, found := e.issued[serial]; found {
+		template.Status = ocsp.Good
+	}
+
+	if revokedAt, revoked := e.revoked[serial]; revoked {
+		template.Status = ocsp.Revoked
+		template.RevokedAt = revokedAt
+		template.RevocationReason = ocsp.Unspecified
+	}
+
+	response, err := ocsp.CreateResponse(e.certificate, e.certificate, template, e.signer)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", helper.ErrVaultFailToReadSecretsEngine, err.Error())
+	}
+
+	return response, nil
+}
+
+func (mb *MemoryBackend) GetAWSSecretsEngineRole(path string, namespace string, r *data.AWSRole, ctx context.Context) error {
+	mb.mu.Lock()
+	defer mb.mu.Unlock()
+
+	role, err := mb.role(path, namespace, r.RoleName)
+	if err != nil {
+		return err
+	}
+
+	r.CredentialType = role.CredentialType
+	r.PolicyARNs = role.PolicyARNs
+	r.PolicyDocument = role.PolicyDocument
+	r.RoleARNs = role.RoleARNs
+	r.SessionTags = role.SessionTags
+	r.ExternalID = role.ExternalID
+
+	return nil
+}
+
+func (mb *MemoryBackend) AddAWSSecretsEngineRole(path string, namespace string, r *data.AWSRole, ctx context.Context) error {
+	return mb.UpdateAWSSecretsEngineRole(path, namespace, r, ctx)
+}
+
+func (mb *MemoryBackend) UpdateAWSSecretsEngineRole(path string, namespace string, r *data.AWSRole, ctx context.Context) error {
+	mb.mu.Lock()
+	defer mb.mu.Unlock()
+
+	e, err := mountedEngine[*memoryAWSEngine](mb, path, namespace)
+	if err != nil {
+		return fmt.Errorf("%w: %s", helper.ErrVaultFailToConfigureAWSSecretsEngine, err.Error())
+	}
+
+	e.roles[r.RoleName] = *r
+
+	return nil
+}
+
+func (mb *MemoryBackend) RemoveAWSSecretsEngineRole(path string, namespace string, roleName string, ctx context.Context) error {
+	mb.mu.Lock()
+	defer mb.mu.Unlock()
+
+	e, err := mountedEngine[*memoryAWSEngine](mb, path, namespace)
+	if err != nil {
+		return fmt.Errorf("%w: %s", helper.ErrVaultFailToRemoveAWSEngineRole, err.Error())
+	}
+
+	delete(e.roles, roleName)
+
+	return nil
+}
+
+func (mb *MemoryBackend) GetPKISecretsEngineRole(path string, namespace string, r *data.PKIRole, ctx context.Context) error {
+	mb.mu.Lock()
+	defer mb.mu.Unlock()
+
+	e, err := mb.pkiEngine(path, namespace)
+	if err != nil {
+		return fmt.Errorf("%w: %s", helper.ErrVaultFailToReadSecretsEngine, err.Error())
+	}
+
+	role, found := e.roles[r.RoleName]
+	if !found {
+		return fmt.Errorf("%w: role %s not found", helper.ErrVaultFailToReadSecretsEngine, r.RoleName)
+	}
+
+	r.AllowedDomains = role.AllowedDomains
+	r.AllowSubdomains = role.AllowSubdomains
+	r.AllowBareDomains = role.AllowBareDomains
+	r.KeyType = role.KeyType
+
+	return nil
+}
+
+func (mb *MemoryBackend) AddPKISecretsEngineRole(path string, namespace string, r *data.PKIRole, ctx context.Context) error {
+	return mb.UpdatePKISecretsEngineRole(path, namespace, r, ctx)
+}
+
+func (mb *MemoryBackend) UpdatePKISecretsEngineRole(path string, namespace string, r *data.PKIRole, ctx context.Context) error {
+	mb.mu.Lock()
+	defer mb.mu.Unlock()
+
+	e, err := mb.pkiEngine(path, namespace)
+	if err != nil {
+		return fmt.Errorf("%w: %s", helper.ErrVaultFailToConfigureSecretsEngine, err.Error())
+	}
+
+	return e.setRole(r)
+}
+
+func (mb *MemoryBackend) RemovePKISecretsEngineRole(path string, namespace string, roleName string, ctx context.Context) error {
+	mb.mu.Lock()
+	defer mb.mu.Unlock()
+
+	e, err := mb.pkiEngine(path, namespace)
+	if err != nil {
+		return fmt.Errorf("%w: %s", helper.ErrVaultFailToConfigureSecretsEngine, err.Error())
+	}
+
+	delete(e.roles, roleName)
+
+	return nil
+}
+
+func (mb *MemoryBackend) RenewLease(leaseID string, namespace string, increment string, ctx context.Context) (*data.VaultLeaseRenewal, error) {
+	mb.mu.Lock()
+	defer mb.mu.Unlock()
+
+	lease, found := mb.leases[mb.key(namespace, leaseID)]
+	if !found {
+		return nil, fmt.Errorf("%w: lease not found", helper.ErrVaultFailToRenewLease)
+	}
+
+	if !lease.renewable {
+		return nil, fmt.Errorf("%w: lease is not renewable", helper.ErrVaultFailToRenewLease)
+	}
+
+	if increment != "" {
+		duration, err := durationToSeconds(increment)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", helper.ErrVaultFailToRenewLease, err.Error())
+		}
+		lease.duration = duration
+	}
+
+	return &data.VaultLeaseRenewal{LeaseID: leaseID, LeaseDuration: lease.duration, Renewable: lease.renewable}, nil
+}
+
+func (mb *MemoryBackend) RevokeLease(leaseID string, namespace string, ctx context.Context) error {
+	mb.mu.Lock()
+	defer mb.mu.Unlock()
+
+	delete(mb.leases, mb.key(namespace, leaseID))
+
+	return nil
+}
+
+func (mb *MemoryBackend) RevokeLeasesByPrefix(prefix string, namespace string, ctx context.Context) error {
+	mb.mu.Lock()
+	defer mb.mu.Unlock()
+
+	mb.revokeLeasesByPrefix(prefix, namespace)
+
+	return nil
+}
+
+func (mb *MemoryBackend) ListSecretsEngineMounts(namespace string, ctx context.Context) (map[string]string, error) {
+	mb.mu.Lock()
+	defer mb.mu.Unlock()
+
+	prefix := mb.key(namespace, mb.c.Vault.PathPrefix+"/")
+
+	paths := map[string]string{}
+
+	for key, e := range mb.mounts {
+		if strings.HasPrefix(key, prefix) {
+			paths[strings.TrimPrefix(key, mb.key(namespace, ""))] = e.engineType()
+		}
+	}
+
+	return paths, nil
+}
+
+// EnableSecretsEngineMount mounts unconfigured secrets engine of engineType at path in namespace. SSH and PKI
+// engines have no CA until they are configured again.
+func (mb *MemoryBackend) EnableSecretsEngineMount(path string, namespace string, engineType string, ctx context.Context) error {
+	mb.mu.Lock()
+	defer mb.mu.Unlock()
+
+	e, err := newMemoryEngine(engineType)
+	if err != nil {
+		return err
+	}
+
+	enableFailed := helper.ErrVaultFailToEnableSecretsEngine
+	if engineType == EngineAWS {
+		enableFailed = helper.ErrVaultFailToEnableAWSSecretsEngine
+	}
+
+	return mb.mountEngine(path, namespace, e, enableFailed, func() error { return nil })
+}
+
+// DisableSecretsEngineMount disables secrets engine of any type mounted at path in namespace.
+func (mb *MemoryBackend) DisableSecretsEngineMount(path string, namespace string, ctx context.Context) error {
+	mb.mu.Lock()
+	defer mb.mu.Unlock()
+
+	delete(mb.mounts, mb.key(namespace, path))
+
+	// Disabling secrets engine revokes all leases issued through it
+	mb.revokeLeasesByPrefix(path+"/", namespace)
+
+	return nil
+}
+
+func (mb *MemoryBackend) CheckMountPathAvailable(path string, namespace string, ctx context.Context) error {
+	mb.mu.Lock()
+	defer mb.mu.Unlock()
+
+	return mb.checkMountPathAvailable(path, namespace)
+}
+
+// checkMountPathAvailable checks path against engines of every type mounted in namespace. Caller must hold mu.
+func (mb *MemoryBackend) checkMountPathAvailable(path string, namespace string) error {
+	prefix := mb.key(namespace, "")
+
+	var mounted []string
+
+	for key := range mb.mounts {
+		if strings.HasPrefix(key, prefix) {
+			mounted = append(mounted, strings.TrimPrefix(key, prefix))
+		}
+	}
+
+	return checkMountPathOverlap(path, mounted)
+}
+
+// WrapResponse returns wrapping token for payload. There is no Vault to unwrap it with, so payload is
+// only validated and discarded.
+func (mb *MemoryBackend) WrapResponse(payload interface{}, ttl string, ctx context.Context) (*data.WrapInfo, error) {
+	duration, err := durationToSeconds(ttl)
+	if err != nil || duration <= 0 {
+		return nil, fmt.Errorf("%w: invalid wrap ttl %s", helper.ErrVaultFailToWrapResponse, ttl)
+	}
+
+	if _, err := json.Marshal(payload); err != nil {
+		return nil, fmt.Errorf("%w: %s", helper.ErrVaultFailToWrapResponse, err.Error())
+	}
+
+	return &data.WrapInfo{
+		Token:        "hvs." + randomString("ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789", 24),
+		Accessor:     randomString("abcdefghijklmnopqrstuvwxyz0123456789", 24),
+		TTL:          duration,
+		CreationTime: time.Now().UTC(),
+		Namespace:    mb.c.Vault.Namespace,
+	}, nil
+}
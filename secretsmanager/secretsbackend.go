@@ -0,0 +1,154 @@
+package secretsmanager
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"DemoServer_ConnectionManager/configuration"
+	"DemoServer_ConnectionManager/data"
+	"DemoServer_ConnectionManager/helper"
+)
+
+var (
+	strBackendVault  = "vault"
+	strBackendMemory = "memory"
+)
+
+// Types of secrets engines backing connections as reported by Vault for their mounts
+const (
+	EngineAWS      = "aws"
+	EngineAzure    = "azure"
+	EngineGCP      = "gcp"
+	EngineDatabase = "database"
+	EngineSSH      = "ssh"
+	EnginePKI      = "pki"
+)
+
+// SecretsBackend manages secrets engines which back connections, their roles and leases of credentials
+// generated through them. Every operation is executed in Vault namespace passed in, or
+// namespace configured for service if it is empty. VaultHandler is the production implementation. MemoryBackend
+// keeps everything in process memory for local development and tests.
+type SecretsBackend interface {
+	Ping(ctx context.Context) error
+
+	// AWS secrets engine of a connection
+	GetAWSSecretsEngine(c *data.AWSConnection, ctx context.Context) error
+	AddAWSSecretsEngine(c *data.AWSConnection, ctx context.Context) error
+	UpdateAWSSecretsEngine(c *data.AWSConnection, ctx context.Context) error
+	RemoveAWSSecretsEngine(c *data.AWSConnection, ctx context.Context) error
+	TestAWSSecretsEngine(path string, namespace string, role string, ctx context.Context) error
+	GenerateCredsAWSSecretsEngine(path string, namespace string, role string, roleARN string, ttl string, ctx context.Context) (*data.CredsAWSConnectionResponse, error)
+	RotateAWSRootCredentials(path string, namespace string, ctx context.Context) (string, error)
+
+	// Azure secrets engine of a connection
+	GetAzureSecretsEngine(c *data.AzureConnection, ctx context.Context) error
+	AddAzureSecretsEngine(c *data.AzureConnection, ctx context.Context) error
+	UpdateAzureSecretsEngine(c *data.AzureConnection, ctx context.Context) error
+	RemoveAzureSecretsEngine(c *data.AzureConnection, ctx context.Context) error
+	TestAzureSecretsEngine(path string, namespace string, role string, ctx context.Context) error
+	GenerateCredsAzureSecretsEngine(path string, namespace string, role string, ctx context.Context) (*data.CredsAzureConnectionResponse, error)
+
+	// GCP secrets engine of a connection
+	GetGCPSecretsEngine(c *data.GCPConnection, ctx context.Context) error
+	AddGCPSecretsEngine(c *data.GCPConnection, ctx context.Context) error
+	UpdateGCPSecretsEngine(c *data.GCPConnection, ctx context.Context) error
+	RemoveGCPSecretsEngine(c *data.GCPConnection, ctx context.Context) error
+	TestGCPSecretsEngine(path string, namespace string, roleType string, role string, secretType string, ctx context.Context) error
+	GenerateCredsGCPSecretsEngine(path string, namespace string, roleType string, role string, secretType string, ctx context.Context) (*data.CredsGCPConnectionResponse, error)
+
+	// Database secrets engine of a connection
+	GetDatabaseSecretsEngine(c *data.DatabaseConnection, ctx context.Context) error
+	AddDatabaseSecretsEngine(c *data.DatabaseConnection, ctx context.Context) error
+	UpdateDatabaseSecretsEngine(c *data.DatabaseConnection, ctx context.Context) error
+	RemoveDatabaseSecretsEngine(c *data.DatabaseConnection, ctx context.Context) error
+	TestDatabaseSecretsEngine(path string, namespace string, role string, ctx context.Context) error
+	GenerateCredsDatabaseSecretsEngine(path string, namespace string, role string, ctx context.Context) (*data.CredsDatabaseConnectionResponse, error)
+
+	// SSH secrets engine of a connection
+	GetSSHSecretsEngine(c *data.SSHConnection, ctx context.Context) error
+	AddSSHSecretsEngine(c *data.SSHConnection, ctx context.Context) error
+	UpdateSSHSecretsEngine(c *data.SSHConnection, ctx context.Context) error
+	RemoveSSHSecretsEngine(c *data.SSHConnection, ctx context.Context) error
+	TestSSHSecretsEngine(path string, namespace string, role string, certType string, principal string, ctx context.Context) error
+	SignSSHSecretsEngine(path string, namespace string, role string, certType string, r *data.SignSSHConnectionRequest, ctx context.Context) (*data.SignSSHConnectionResponse, error)
+	GetSSHCAPublicKey(path string, namespace string, ctx context.Context) (string, error)
+
+	// PKI secrets engine of a connection. Intermediate CA is signed by CA of issuer, which is nil for root CA
+	GetPKISecretsEngine(c *data.PKIConnection, ctx context.Context) error
+	AddPKISecretsEngine(c *data.PKIConnection, issuer *data.PKIConnection, ctx context.Context) error
+	UpdatePKISecretsEngine(c *data.PKIConnection, ctx context.Context) error
+	RemovePKISecretsEngine(c *data.PKIConnection, ctx context.Context) error
+	TestPKISecretsEngine(path string, namespace string, role string, commonName string, ctx context.Context) error
+	IssuePKISecretsEngine(path string, namespace string, role string, r *data.IssuePKIConnectionRequest, ctx context.Context) (*data.IssuePKIConnectionResponse, error)
+	RevokePKICertificate(path string, namespace string, serialNumber string, ctx context.Context) error
+	GetPKICACertificate(path string, namespace string, ctx context.Context) ([]byte, error)
+	GetPKICRL(path string, namespace string, ctx context.Context) ([]byte, error)
+	GetPKIOCSPResponse(path string, namespace string, request []byte, ctx context.Context) ([]byte, error)
+
+	// Roles of AWS secrets engine
+	GetAWSSecretsEngineRole(path string, namespace string, r *data.AWSRole, ctx context.Context) error
+	AddAWSSecretsEngineRole(path string, namespace string, r *data.AWSRole, ctx context.Context) error
+	UpdateAWSSecretsEngineRole(path string, namespace string, r *data.AWSRole, ctx context.Context) error
+	RemoveAWSSecretsEngineRole(path string, namespace string, roleName string, ctx context.Context) error
+
+	// Roles of PKI secrets engine
+	GetPKISecretsEngineRole(path string, namespace string, r *data.PKIRole, ctx context.Context) error
+	AddPKISecretsEngineRole(path string, namespace string, r *data.PKIRole, ctx context.Context) error
+	UpdatePKISecretsEngineRole(path string, namespace string, r *data.PKIRole, ctx context.Context) error
+	RemovePKISecretsEngineRole(path string, namespace string, roleName string, ctx context.Context) error
+
+	// Leases of generated credentials
+	RenewLease(leaseID string, namespace string, increment string, ctx context.Context) (*data.VaultLeaseRenewal, error)
+	RevokeLease(leaseID string, namespace string, ctx context.Context) error
+	RevokeLeasesByPrefix(prefix string, namespace string, ctx context.Context) error
+
+	// Mounts of secrets engines of every type
+	ListSecretsEngineMounts(namespace string, ctx context.Context) (map[string]string, error)
+	EnableSecretsEngineMount(path string, namespace string, engineType string, ctx context.Context) error
+	DisableSecretsEngineMount(path string, namespace string, ctx context.Context) error
+	CheckMountPathAvailable(path string, namespace string, ctx context.Context) error
+
+	// Response wrapping
+	WrapResponse(payload interface{}, ttl string, ctx context.Context) (*data.WrapInfo, error)
+}
+
+var (
+	_ SecretsBackend = (*VaultHandler)(nil)
+	_ SecretsBackend = (*MemoryBackend)(nil)
+)
+
+// NewSecretsBackend returns secrets backend selected by SecretsBackend.Type configuration. Vault is used
+// when type is not set.
+func NewSecretsBackend(c *configuration.Config, l *slog.Logger) (SecretsBackend, error) {
+	switch strings.ToLower(c.SecretsBackend.Type) {
+	case "", strBackendVault:
+		vh, err := NewVaultHandler(c, l)
+		if err != nil {
+			return nil, err
+		}
+		return vh, nil
+	case strBackendMemory:
+		l.Warn("In-memory secrets backend selected. Secrets engines and credentials are not persisted and credentials are not usable with AWS")
+		return NewMemoryBackend(c, l), nil
+	default:
+		return nil, fmt.Errorf("unsupported secrets backend type: %s", c.SecretsBackend.Type)
+	}
+}
+
+// checkMountPathOverlap returns ErrVaultMountPathInUse if path equals one of mounted paths, is nested in
+// one of them or contains one of them.
+func checkMountPathOverlap(path string, mounted []string) error {
+	path = strings.Trim(path, "/") + "/"
+
+	for _, m := range mounted {
+		m = strings.Trim(m, "/") + "/"
+
+		if strings.HasPrefix(path, m) || strings.HasPrefix(m, path) {
+			return fmt.Errorf("%w: %s", helper.ErrVaultMountPathInUse, strings.TrimSuffix(m, "/"))
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,106 @@
+package secretsmanager
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+
+	"DemoServer_ConnectionManager/data"
+	"DemoServer_ConnectionManager/helper"
+	"DemoServer_ConnectionManager/utilities"
+
+	"go.opentelemetry.io/otel"
+)
+
+func (vh *VaultHandler) GetAWSSecretsEngineRole(path string, namespace string, r *data.AWSRole, ctx context.Context) error {
+
+	tr := otel.Tracer(vh.c.Server.PrefixMain)
+	ctx, span := tr.Start(ctx, utilities.GetFunctionName())
+	defer span.End()
+
+	var awsConfig vaultAWSConfig
+
+	token, err := vh.GetToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	awsConfig.Data.Role = r.RoleName
+
+	err = vh.getAWSSecretsEngineRole(token, path, namespace, &awsConfig, ctx)
+	if err != nil {
+		return err
+	}
+
+	r.CredentialType = awsConfig.Data.CredentialType
+	r.PolicyARNs = awsConfig.Data.PolicyARNs
+	r.PolicyDocument = awsConfig.Data.PolicyDocument
+	r.RoleARNs = awsConfig.Data.RoleARNs
+	r.SessionTags = awsConfig.Data.SessionTags
+	r.ExternalID = awsConfig.Data.ExternalID
+
+	return nil
+}
+
+func (vh *VaultHandler) AddAWSSecretsEngineRole(path string, namespace string, r *data.AWSRole, ctx context.Context) error {
+
+	tr := otel.Tracer(vh.c.Server.PrefixMain)
+	ctx, span := tr.Start(ctx, utilities.GetFunctionName())
+	defer span.End()
+
+	token, err := vh.GetToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	return vh.configureAWSIAMRole(token, path, namespace, r, ctx)
+}
+
+func (vh *VaultHandler) UpdateAWSSecretsEngineRole(path string, namespace string, r *data.AWSRole, ctx context.Context) error {
+
+	tr := otel.Tracer(vh.c.Server.PrefixMain)
+	ctx, span := tr.Start(ctx, utilities.GetFunctionName())
+	defer span.End()
+
+	token, err := vh.GetToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	return vh.configureAWSIAMRole(token, path, namespace, r, ctx)
+}
+
+func (vh *VaultHandler) RemoveAWSSecretsEngineRole(path string, namespace string, roleName string, ctx context.Context) error {
+
+	tr := otel.Tracer(vh.c.Server.PrefixMain)
+	ctx, span := tr.Start(ctx, utilities.GetFunctionName())
+	defer span.End()
+
+	token, err := vh.GetToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	url := fmt.Sprintf("%s/v1/%s/roles/%s", vh.vaultAddress, path, roleName)
+
+	req, err := http.NewRequest("DELETE", url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-Vault-Token", token)
+	vh.setNamespace(req, namespace)
+
+	resp, err := vh.do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusNoContent {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("%w: %s", helper.ErrVaultFailToRemoveAWSEngineRole, string(body))
+	}
+
+	return nil
+}
@@ -0,0 +1,244 @@
+package secretsmanager
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"DemoServer_ConnectionManager/data"
+	"DemoServer_ConnectionManager/helper"
+	"DemoServer_ConnectionManager/utilities"
+
+	"go.opentelemetry.io/otel"
+)
+
+var strEngineAzure = EngineAzure
+
+type vaultAzureConfig struct {
+	Data struct {
+		SubscriptionID string `json:"subscription_id"`
+		TenantID       string `json:"tenant_id"`
+		ClientID       string `json:"client_id"`
+		Environment    string `json:"environment"`
+	} `json:"data"`
+}
+
+type vaultAzureRole struct {
+	Data struct {
+		AzureRoles          []data.AzureRoleAssignment `json:"azure_roles"`
+		ApplicationObjectID string                     `json:"application_object_id"`
+	} `json:"data"`
+}
+
+type vaultAzureCred struct {
+	LeaseID       string `json:"lease_id"`
+	LeaseDuration int    `json:"lease_duration"`
+	Renewable     bool   `json:"renewable"`
+	Data          struct {
+		ClientID     string `json:"client_id"`
+		ClientSecret string `json:"client_secret"`
+	} `json:"data"`
+}
+
+func (vh *VaultHandler) GetAzureSecretsEngine(c *data.AzureConnection, ctx context.Context) error {
+
+	tr := otel.Tracer(vh.c.Server.PrefixMain)
+	ctx, span := tr.Start(ctx, utilities.GetFunctionName())
+	defer span.End()
+
+	token, err := vh.GetToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	body, err := vh.request(token, http.MethodGet, c.VaultPath+"/config", c.VaultNamespace, nil, helper.ErrVaultFailToReadSecretsEngine)
+	if err != nil {
+		return err
+	}
+
+	var config vaultAzureConfig
+
+	if err := json.Unmarshal(body, &config); err != nil {
+		return err
+	}
+
+	body, err = vh.request(token, http.MethodGet, c.VaultPath+"/roles/"+c.RoleName, c.VaultNamespace, nil, helper.ErrVaultFailToReadSecretsEngine)
+	if err != nil {
+		return err
+	}
+
+	var role vaultAzureRole
+
+	if err := json.Unmarshal(body, &role); err != nil {
+		return err
+	}
+
+	c.DefaultLeaseTTL, c.MaxLeaseTTL, err = vh.readSecretsEngineTune(token, c.VaultPath, c.VaultNamespace, ctx)
+	if err != nil {
+		return err
+	}
+
+	c.SubscriptionID = config.Data.SubscriptionID
+	c.TenantID = config.Data.TenantID
+	c.ClientID = config.Data.ClientID
+	c.Environment = config.Data.Environment
+	c.AzureRoles = role.Data.AzureRoles
+	c.ApplicationObjectID = role.Data.ApplicationObjectID
+
+	return nil
+}
+
+func (vh *VaultHandler) AddAzureSecretsEngine(c *data.AzureConnection, ctx context.Context) error {
+
+	tr := otel.Tracer(vh.c.Server.PrefixMain)
+	ctx, span := tr.Start(ctx, utilities.GetFunctionName())
+	defer span.End()
+
+	token, err := vh.GetToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = vh.enableSecretsEngine(token, c.VaultPath, c.VaultNamespace, strEngineAzure, c.DefaultLeaseTTL, c.MaxLeaseTTL, ctx)
+	if err != nil {
+		return err
+	}
+
+	return vh.configureAzureSecretsEngine(token, c, ctx)
+}
+
+// UpdateAzureSecretsEngine configures secrets engine in place. Unlike AWS secrets engine, it is not remounted,
+// so credentials issued before update remain valid until their leases expire.
+func (vh *VaultHandler) UpdateAzureSecretsEngine(c *data.AzureConnection, ctx context.Context) error {
+
+	tr := otel.Tracer(vh.c.Server.PrefixMain)
+	ctx, span := tr.Start(ctx, utilities.GetFunctionName())
+	defer span.End()
+
+	token, err := vh.GetToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = vh.tuneSecretsEngine(token, c.VaultPath, c.VaultNamespace, c.DefaultLeaseTTL, c.MaxLeaseTTL, ctx)
+	if err != nil {
+		return err
+	}
+
+	return vh.configureAzureSecretsEngine(token, c, ctx)
+}
+
+func (vh *VaultHandler) RemoveAzureSecretsEngine(c *data.AzureConnection, ctx context.Context) error {
+
+	tr := otel.Tracer(vh.c.Server.PrefixMain)
+	ctx, span := tr.Start(ctx, utilities.GetFunctionName())
+	defer span.End()
+
+	token, err := vh.GetToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	return vh.disableSecretsEngine(token, c.VaultPath, c.VaultNamespace, ctx)
+}
+
+// configureAzureSecretsEngine writes credentials of service principal Vault manages Azure with and role of
+// connection. Client secret is only sent when set, as Vault keeps stored secret otherwise and never returns it.
+func (vh *VaultHandler) configureAzureSecretsEngine(token string, c *data.AzureConnection, ctx context.Context) error {
+
+	tr := otel.Tracer(vh.c.Server.PrefixMain)
+	_, span := tr.Start(ctx, utilities.GetFunctionName())
+	defer span.End()
+
+	config := map[string]interface{}{
+		"subscription_id": c.SubscriptionID,
+		"tenant_id":       c.TenantID,
+		"client_id":       c.ClientID,
+	}
+	if c.ClientSecret != "" {
+		config["client_secret"] = c.ClientSecret
+	}
+	if c.Environment != "" {
+		config["environment"] = c.Environment
+	}
+
+	if _, err := vh.request(token, http.MethodPost, c.VaultPath+"/config", c.VaultNamespace, config, helper.ErrVaultFailToConfigureSecretsEngine); err != nil {
+		return err
+	}
+
+	// Both attributes are sent so that switching between role assignments and existing application
+	// does not keep the other one configured
+	azureRoles := c.AzureRoles
+	if azureRoles == nil {
+		azureRoles = []data.AzureRoleAssignment{}
+	}
+
+	azureRolesJSON, err := json.Marshal(azureRoles)
+	if err != nil {
+		return err
+	}
+
+	role := map[string]interface{}{
+		"azure_roles":           string(azureRolesJSON),
+		"application_object_id": c.ApplicationObjectID,
+	}
+
+	_, err = vh.request(token, http.MethodPost, c.VaultPath+"/roles/"+c.RoleName, c.VaultNamespace, role, helper.ErrVaultFailToConfigureSecretsEngine)
+
+	return err
+}
+
+// TestAzureSecretsEngine generates credentials through role and revokes them right away. Service principals are
+// only created in Azure if Vault is able to manage it, so successful generation proves connectivity.
+func (vh *VaultHandler) TestAzureSecretsEngine(path string, namespace string, role string, ctx context.Context) error {
+
+	tr := otel.Tracer(vh.c.Server.PrefixMain)
+	ctx, span := tr.Start(ctx, utilities.GetFunctionName())
+	defer span.End()
+
+	creds, err := vh.GenerateCredsAzureSecretsEngine(path, namespace, role, ctx)
+	if err != nil {
+		return err
+	}
+
+	if creds.Data.ClientID == "" || creds.Data.ClientSecret == "" {
+		return helper.ErrVaultFailToGenerateAzureCredentials
+	}
+
+	return vh.RevokeLease(creds.LeaseID, namespace, ctx)
+}
+
+func (vh *VaultHandler) GenerateCredsAzureSecretsEngine(path string, namespace string, role string, ctx context.Context) (*data.CredsAzureConnectionResponse, error) {
+
+	tr := otel.Tracer(vh.c.Server.PrefixMain)
+	ctx, span := tr.Start(ctx, utilities.GetFunctionName())
+	defer span.End()
+
+	token, err := vh.GetToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := vh.request(token, http.MethodGet, fmt.Sprintf("%s/creds/%s", path, role), namespace, nil, helper.ErrVaultFailToGenerateAzureCredentials)
+	if err != nil {
+		return nil, err
+	}
+
+	var cred vaultAzureCred
+
+	if err := json.Unmarshal(body, &cred); err != nil {
+		return nil, err
+	}
+
+	var credsResponse data.CredsAzureConnectionResponse
+
+	credsResponse.LeaseID = cred.LeaseID
+	credsResponse.LeaseDuration = cred.LeaseDuration
+	credsResponse.Renewable = cred.Renewable
+	credsResponse.RoleName = role
+	credsResponse.Data.ClientID = cred.Data.ClientID
+	credsResponse.Data.ClientSecret = cred.Data.ClientSecret
+
+	return &credsResponse, nil
+}
@@ -0,0 +1,252 @@
+package secretsmanager
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"DemoServer_ConnectionManager/data"
+	"DemoServer_ConnectionManager/helper"
+	"DemoServer_ConnectionManager/utilities"
+
+	"go.opentelemetry.io/otel"
+)
+
+var strEngineDatabase = EngineDatabase
+
+// strDatabaseConfigName name of database configuration of secrets engine. Every mount backs a single database,
+// so configuration is stored under fixed name.
+var strDatabaseConfigName = "connection"
+
+type vaultDatabaseConfig struct {
+	Data struct {
+		PluginName        string `json:"plugin_name"`
+		ConnectionDetails struct {
+			ConnectionURL string `json:"connection_url"`
+			Username      string `json:"username"`
+		} `json:"connection_details"`
+	} `json:"data"`
+}
+
+type vaultDatabaseRole struct {
+	Data struct {
+		CreationStatements   []string `json:"creation_statements"`
+		RevocationStatements []string `json:"revocation_statements"`
+	} `json:"data"`
+}
+
+type vaultDatabaseCred struct {
+	LeaseID       string `json:"lease_id"`
+	LeaseDuration int    `json:"lease_duration"`
+	Renewable     bool   `json:"renewable"`
+	Data          struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+	} `json:"data"`
+}
+
+func (vh *VaultHandler) GetDatabaseSecretsEngine(c *data.DatabaseConnection, ctx context.Context) error {
+
+	tr := otel.Tracer(vh.c.Server.PrefixMain)
+	ctx, span := tr.Start(ctx, utilities.GetFunctionName())
+	defer span.End()
+
+	token, err := vh.GetToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	body, err := vh.request(token, http.MethodGet, c.VaultPath+"/config/"+strDatabaseConfigName, c.VaultNamespace, nil, helper.ErrVaultFailToReadSecretsEngine)
+	if err != nil {
+		return err
+	}
+
+	var config vaultDatabaseConfig
+
+	if err := json.Unmarshal(body, &config); err != nil {
+		return err
+	}
+
+	body, err = vh.request(token, http.MethodGet, c.VaultPath+"/roles/"+c.RoleName, c.VaultNamespace, nil, helper.ErrVaultFailToReadSecretsEngine)
+	if err != nil {
+		return err
+	}
+
+	var role vaultDatabaseRole
+
+	if err := json.Unmarshal(body, &role); err != nil {
+		return err
+	}
+
+	c.DefaultLeaseTTL, c.MaxLeaseTTL, err = vh.readSecretsEngineTune(token, c.VaultPath, c.VaultNamespace, ctx)
+	if err != nil {
+		return err
+	}
+
+	c.PluginName = config.Data.PluginName
+	c.ConnectionURL = config.Data.ConnectionDetails.ConnectionURL
+	c.Username = config.Data.ConnectionDetails.Username
+	c.CreationStatements = role.Data.CreationStatements
+	c.RevocationStatements = role.Data.RevocationStatements
+
+	return nil
+}
+
+func (vh *VaultHandler) AddDatabaseSecretsEngine(c *data.DatabaseConnection, ctx context.Context) error {
+
+	tr := otel.Tracer(vh.c.Server.PrefixMain)
+	ctx, span := tr.Start(ctx, utilities.GetFunctionName())
+	defer span.End()
+
+	token, err := vh.GetToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = vh.enableSecretsEngine(token, c.VaultPath, c.VaultNamespace, strEngineDatabase, c.DefaultLeaseTTL, c.MaxLeaseTTL, ctx)
+	if err != nil {
+		return err
+	}
+
+	// Configuration is refused if Vault can not connect to database, which is far more common than for cloud
+	// providers, so mount is not left behind for path to stay available on retry
+	if err := vh.configureDatabaseSecretsEngine(token, c, ctx); err != nil {
+		if disableErr := vh.disableSecretsEngine(token, c.VaultPath, c.VaultNamespace, ctx); disableErr != nil {
+			helper.LogError(vh.l, helper.ErrorVaultSecretsEngineFailed, disableErr, span)
+		}
+		return err
+	}
+
+	return nil
+}
+
+// UpdateDatabaseSecretsEngine configures secrets engine in place. Database users created before update are kept
+// until their leases expire, and are dropped with revocation statements in effect at that time.
+func (vh *VaultHandler) UpdateDatabaseSecretsEngine(c *data.DatabaseConnection, ctx context.Context) error {
+
+	tr := otel.Tracer(vh.c.Server.PrefixMain)
+	ctx, span := tr.Start(ctx, utilities.GetFunctionName())
+	defer span.End()
+
+	token, err := vh.GetToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = vh.tuneSecretsEngine(token, c.VaultPath, c.VaultNamespace, c.DefaultLeaseTTL, c.MaxLeaseTTL, ctx)
+	if err != nil {
+		return err
+	}
+
+	return vh.configureDatabaseSecretsEngine(token, c, ctx)
+}
+
+func (vh *VaultHandler) RemoveDatabaseSecretsEngine(c *data.DatabaseConnection, ctx context.Context) error {
+
+	tr := otel.Tracer(vh.c.Server.PrefixMain)
+	ctx, span := tr.Start(ctx, utilities.GetFunctionName())
+	defer span.End()
+
+	token, err := vh.GetToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	return vh.disableSecretsEngine(token, c.VaultPath, c.VaultNamespace, ctx)
+}
+
+// configureDatabaseSecretsEngine writes database configuration and role of connection. Vault verifies it can
+// connect to database before storing configuration. Password is only sent when set, as Vault merges
+// configuration on update and never returns stored password.
+func (vh *VaultHandler) configureDatabaseSecretsEngine(token string, c *data.DatabaseConnection, ctx context.Context) error {
+
+	tr := otel.Tracer(vh.c.Server.PrefixMain)
+	_, span := tr.Start(ctx, utilities.GetFunctionName())
+	defer span.End()
+
+	config := map[string]interface{}{
+		"plugin_name":       c.PluginName,
+		"connection_url":    c.ConnectionURL,
+		"username":          c.Username,
+		"allowed_roles":     []string{c.RoleName},
+		"verify_connection": true,
+	}
+	if c.Password != "" {
+		config["password"] = c.Password
+	}
+
+	if _, err := vh.request(token, http.MethodPost, c.VaultPath+"/config/"+strDatabaseConfigName, c.VaultNamespace, config, helper.ErrVaultFailToConfigureSecretsEngine); err != nil {
+		return err
+	}
+
+	role := map[string]interface{}{
+		"db_name":             strDatabaseConfigName,
+		"creation_statements": c.CreationStatements,
+	}
+
+	// Revocation statements are always sent so that removing them falls back to default of plugin
+	revocationStatements := c.RevocationStatements
+	if revocationStatements == nil {
+		revocationStatements = []string{}
+	}
+	role["revocation_statements"] = revocationStatements
+
+	_, err := vh.request(token, http.MethodPost, c.VaultPath+"/roles/"+c.RoleName, c.VaultNamespace, role, helper.ErrVaultFailToConfigureSecretsEngine)
+
+	return err
+}
+
+// TestDatabaseSecretsEngine generates credentials through role and revokes them right away, which creates and drops
+// a database user. Success proves both connectivity and validity of creation and revocation statements.
+func (vh *VaultHandler) TestDatabaseSecretsEngine(path string, namespace string, role string, ctx context.Context) error {
+
+	tr := otel.Tracer(vh.c.Server.PrefixMain)
+	ctx, span := tr.Start(ctx, utilities.GetFunctionName())
+	defer span.End()
+
+	creds, err := vh.GenerateCredsDatabaseSecretsEngine(path, namespace, role, ctx)
+	if err != nil {
+		return err
+	}
+
+	if creds.Data.Username == "" || creds.Data.Password == "" {
+		return helper.ErrVaultFailToGenerateDatabaseCredentials
+	}
+
+	return vh.RevokeLease(creds.LeaseID, namespace, ctx)
+}
+
+func (vh *VaultHandler) GenerateCredsDatabaseSecretsEngine(path string, namespace string, role string, ctx context.Context) (*data.CredsDatabaseConnectionResponse, error) {
+
+	tr := otel.Tracer(vh.c.Server.PrefixMain)
+	ctx, span := tr.Start(ctx, utilities.GetFunctionName())
+	defer span.End()
+
+	token, err := vh.GetToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := vh.request(token, http.MethodGet, fmt.Sprintf("%s/creds/%s", path, role), namespace, nil, helper.ErrVaultFailToGenerateDatabaseCredentials)
+	if err != nil {
+		return nil, err
+	}
+
+	var cred vaultDatabaseCred
+
+	if err := json.Unmarshal(body, &cred); err != nil {
+		return nil, err
+	}
+
+	var credsResponse data.CredsDatabaseConnectionResponse
+
+	credsResponse.LeaseID = cred.LeaseID
+	credsResponse.LeaseDuration = cred.LeaseDuration
+	credsResponse.Renewable = cred.Renewable
+	credsResponse.RoleName = role
+	credsResponse.Data.Username = cred.Data.Username
+	credsResponse.Data.Password = cred.Data.Password
+
+	return &credsResponse, nil
+}
@@ -0,0 +1,306 @@
+package secretsmanager
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"DemoServer_ConnectionManager/data"
+	"DemoServer_ConnectionManager/helper"
+	"DemoServer_ConnectionManager/utilities"
+
+	"go.opentelemetry.io/otel"
+)
+
+var strEngineGCP = EngineGCP
+
+type vaultGCPRole struct {
+	Data struct {
+		SecretType            string              `json:"secret_type"`
+		Project               string              `json:"project"`
+		ServiceAccountEmail   string              `json:"service_account_email"`
+		ServiceAccountProject string              `json:"service_account_project"`
+		Bindings              map[string][]string `json:"bindings"`
+		TokenScopes           []string            `json:"token_scopes"`
+	} `json:"data"`
+}
+
+type vaultGCPToken struct {
+	Data struct {
+		Token            string `json:"token"`
+		TokenTTL         int    `json:"token_ttl"`
+		ExpiresAtSeconds int64  `json:"expires_at_seconds"`
+	} `json:"data"`
+}
+
+type vaultGCPKey struct {
+	LeaseID       string `json:"lease_id"`
+	LeaseDuration int    `json:"lease_duration"`
+	Renewable     bool   `json:"renewable"`
+	Data          struct {
+		PrivateKeyData string `json:"private_key_data"`
+		KeyAlgorithm   string `json:"key_algorithm"`
+		KeyType        string `json:"key_type"`
+	} `json:"data"`
+}
+
+// gcpRolePath returns path of roleset or static account relative to mount of secrets engine.
+func gcpRolePath(roleType string, role string) string {
+	if roleType == data.GCPRoleTypeStaticAccount {
+		return "static-account/" + role
+	}
+	return "roleset/" + role
+}
+
+// gcpBindingsToVault renders bindings in JSON flavour of HCL bindings document Vault expects i.e.
+// {"resource": {"//cloudresourcemanager.googleapis.com/projects/p": {"roles": ["roles/viewer"]}}}
+func gcpBindingsToVault(bindings []data.GCPBinding) (string, error) {
+	resources := map[string]map[string][]string{}
+	for _, b := range bindings {
+		resources[b.Resource] = map[string][]string{"roles": b.Roles}
+	}
+
+	document, err := json.Marshal(map[string]interface{}{"resource": resources})
+	if err != nil {
+		return "", err
+	}
+	return string(document), nil
+}
+
+// gcpBindingsFromVault converts bindings returned by Vault, keyed by resource, to bindings of connection
+// ordered by resource.
+func gcpBindingsFromVault(bindings map[string][]string) []data.GCPBinding {
+	var resources []string
+	for resource := range bindings {
+		resources = append(resources, resource)
+	}
+	sort.Strings(resources)
+
+	var result []data.GCPBinding
+	for _, resource := range resources {
+		result = append(result, data.GCPBinding{Resource: resource, Roles: bindings[resource]})
+	}
+	return result
+}
+
+func (vh *VaultHandler) GetGCPSecretsEngine(c *data.GCPConnection, ctx context.Context) error {
+
+	tr := otel.Tracer(vh.c.Server.PrefixMain)
+	ctx, span := tr.Start(ctx, utilities.GetFunctionName())
+	defer span.End()
+
+	token, err := vh.GetToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	body, err := vh.request(token, http.MethodGet, c.VaultPath+"/"+gcpRolePath(c.RoleType, c.RoleName), c.VaultNamespace, nil, helper.ErrVaultFailToReadSecretsEngine)
+	if err != nil {
+		return err
+	}
+
+	var role vaultGCPRole
+
+	if err := json.Unmarshal(body, &role); err != nil {
+		return err
+	}
+
+	c.DefaultLeaseTTL, c.MaxLeaseTTL, err = vh.readSecretsEngineTune(token, c.VaultPath, c.VaultNamespace, ctx)
+	if err != nil {
+		return err
+	}
+
+	c.SecretType = role.Data.SecretType
+	c.Project = role.Data.Project
+	if c.Project == "" {
+		c.Project = role.Data.ServiceAccountProject
+	}
+	c.ServiceAccountEmail = role.Data.ServiceAccountEmail
+	c.Bindings = gcpBindingsFromVault(role.Data.Bindings)
+	c.TokenScopes = role.Data.TokenScopes
+
+	return nil
+}
+
+func (vh *VaultHandler) AddGCPSecretsEngine(c *data.GCPConnection, ctx context.Context) error {
+
+	tr := otel.Tracer(vh.c.Server.PrefixMain)
+	ctx, span := tr.Start(ctx, utilities.GetFunctionName())
+	defer span.End()
+
+	token, err := vh.GetToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = vh.enableSecretsEngine(token, c.VaultPath, c.VaultNamespace, strEngineGCP, c.DefaultLeaseTTL, c.MaxLeaseTTL, ctx)
+	if err != nil {
+		return err
+	}
+
+	return vh.configureGCPSecretsEngine(token, c, ctx)
+}
+
+// UpdateGCPSecretsEngine configures secrets engine in place. Service account of roleset is only replaced by Vault
+// if bindings change, in which case keys generated before update stop working.
+func (vh *VaultHandler) UpdateGCPSecretsEngine(c *data.GCPConnection, ctx context.Context) error {
+
+	tr := otel.Tracer(vh.c.Server.PrefixMain)
+	ctx, span := tr.Start(ctx, utilities.GetFunctionName())
+	defer span.End()
+
+	token, err := vh.GetToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = vh.tuneSecretsEngine(token, c.VaultPath, c.VaultNamespace, c.DefaultLeaseTTL, c.MaxLeaseTTL, ctx)
+	if err != nil {
+		return err
+	}
+
+	return vh.configureGCPSecretsEngine(token, c, ctx)
+}
+
+func (vh *VaultHandler) RemoveGCPSecretsEngine(c *data.GCPConnection, ctx context.Context) error {
+
+	tr := otel.Tracer(vh.c.Server.PrefixMain)
+	ctx, span := tr.Start(ctx, utilities.GetFunctionName())
+	defer span.End()
+
+	token, err := vh.GetToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	return vh.disableSecretsEngine(token, c.VaultPath, c.VaultNamespace, ctx)
+}
+
+// configureGCPSecretsEngine writes key of service account Vault manages GCP with and roleset or static account of
+// connection. Credentials are only sent when set, as Vault keeps stored key otherwise and never returns it.
+func (vh *VaultHandler) configureGCPSecretsEngine(token string, c *data.GCPConnection, ctx context.Context) error {
+
+	tr := otel.Tracer(vh.c.Server.PrefixMain)
+	_, span := tr.Start(ctx, utilities.GetFunctionName())
+	defer span.End()
+
+	if c.Credentials != "" {
+		config := map[string]interface{}{
+			"credentials": c.Credentials,
+		}
+
+		if _, err := vh.request(token, http.MethodPost, c.VaultPath+"/config", c.VaultNamespace, config, helper.ErrVaultFailToConfigureSecretsEngine); err != nil {
+			return err
+		}
+	}
+
+	role := map[string]interface{}{
+		"secret_type": c.SecretType,
+	}
+
+	if len(c.TokenScopes) > 0 {
+		role["token_scopes"] = c.TokenScopes
+	}
+
+	if len(c.Bindings) > 0 {
+		bindings, err := gcpBindingsToVault(c.Bindings)
+		if err != nil {
+			return err
+		}
+		role["bindings"] = bindings
+	}
+
+	switch c.RoleType {
+	case data.GCPRoleTypeStaticAccount:
+		role["service_account_email"] = c.ServiceAccountEmail
+	default:
+		role["project"] = c.Project
+	}
+
+	_, err := vh.request(token, http.MethodPost, c.VaultPath+"/"+gcpRolePath(c.RoleType, c.RoleName), c.VaultNamespace, role, helper.ErrVaultFailToConfigureSecretsEngine)
+
+	return err
+}
+
+// TestGCPSecretsEngine generates credentials through roleset or static account. Generated service account keys
+// are revoked right away, while access tokens are left to expire as Vault does not lease them.
+func (vh *VaultHandler) TestGCPSecretsEngine(path string, namespace string, roleType string, role string, secretType string, ctx context.Context) error {
+
+	tr := otel.Tracer(vh.c.Server.PrefixMain)
+	ctx, span := tr.Start(ctx, utilities.GetFunctionName())
+	defer span.End()
+
+	creds, err := vh.GenerateCredsGCPSecretsEngine(path, namespace, roleType, role, secretType, ctx)
+	if err != nil {
+		return err
+	}
+
+	if creds.Data.Token == "" && creds.Data.PrivateKeyData == "" {
+		return helper.ErrVaultFailToGenerateGCPCredentials
+	}
+
+	if creds.LeaseID == "" {
+		return nil
+	}
+
+	return vh.RevokeLease(creds.LeaseID, namespace, ctx)
+}
+
+func (vh *VaultHandler) GenerateCredsGCPSecretsEngine(path string, namespace string, roleType string, role string, secretType string, ctx context.Context) (*data.CredsGCPConnectionResponse, error) {
+
+	tr := otel.Tracer(vh.c.Server.PrefixMain)
+	ctx, span := tr.Start(ctx, utilities.GetFunctionName())
+	defer span.End()
+
+	token, err := vh.GetToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var credsResponse data.CredsGCPConnectionResponse
+
+	credsResponse.RoleName = role
+	credsResponse.SecretType = secretType
+
+	switch secretType {
+	case data.GCPSecretTypeAccessToken:
+		body, err := vh.request(token, http.MethodGet, fmt.Sprintf("%s/%s/token", path, gcpRolePath(roleType, role)), namespace, nil, helper.ErrVaultFailToGenerateGCPCredentials)
+		if err != nil {
+			return nil, err
+		}
+
+		var cred vaultGCPToken
+
+		if err := json.Unmarshal(body, &cred); err != nil {
+			return nil, err
+		}
+
+		credsResponse.Data.Token = cred.Data.Token
+		credsResponse.Data.TokenTTL = cred.Data.TokenTTL
+		credsResponse.Data.ExpiresAtSeconds = cred.Data.ExpiresAtSeconds
+	case data.GCPSecretTypeServiceAccountKey:
+		body, err := vh.request(token, http.MethodGet, fmt.Sprintf("%s/%s/key", path, gcpRolePath(roleType, role)), namespace, nil, helper.ErrVaultFailToGenerateGCPCredentials)
+		if err != nil {
+			return nil, err
+		}
+
+		var cred vaultGCPKey
+
+		if err := json.Unmarshal(body, &cred); err != nil {
+			return nil, err
+		}
+
+		credsResponse.LeaseID = cred.LeaseID
+		credsResponse.LeaseDuration = cred.LeaseDuration
+		credsResponse.Renewable = cred.Renewable
+		credsResponse.Data.PrivateKeyData = cred.Data.PrivateKeyData
+		credsResponse.Data.KeyAlgorithm = cred.Data.KeyAlgorithm
+		credsResponse.Data.KeyType = cred.Data.KeyType
+	default:
+		return nil, fmt.Errorf("%w: unsupported secret type %s", helper.ErrVaultFailToGenerateGCPCredentials, secretType)
+	}
+
+	return &credsResponse, nil
+}
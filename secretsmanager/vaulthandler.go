@@ -5,6 +5,7 @@ import (
 	"context"
 	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
@@ -22,8 +23,10 @@ import (
 )
 
 var (
-	strIAMUser      = "iam_user"
-	strSessionToken = "session_token"
+	strIAMUser         = "iam_user"
+	strSessionToken    = "session_token"
+	strAssumedRole     = "assumed_role"
+	strFederationToken = "federation_token"
 )
 
 type VaultHandler struct {
@@ -31,17 +34,22 @@ type VaultHandler struct {
 	l            *slog.Logger
 	hc           *http.Client
 	vaultAddress string
+	tk           vaultToken
 }
 
 type vaultAWSConfig struct {
 	Data struct {
-		AccessKey       string   `json:"access_key"`
-		Role            string   `json:"role"`
-		Region          string   `json:"region"`
-		DefaultLeaseTTL int      `json:"default_lease_ttl"`
-		MaxLeaseTTL     int      `json:"max_lease_ttl"`
-		CredentialType  string   `json:"credential_type"`
-		PolicyARNs      []string `json:"policy_arns"`
+		AccessKey       string            `json:"access_key"`
+		Role            string            `json:"role"`
+		Region          string            `json:"region"`
+		DefaultLeaseTTL int               `json:"default_lease_ttl"`
+		MaxLeaseTTL     int               `json:"max_lease_ttl"`
+		CredentialType  string            `json:"credential_type"`
+		PolicyARNs      []string          `json:"policy_arns"`
+		PolicyDocument  string            `json:"policy_document"`
+		RoleARNs        []string          `json:"role_arns"`
+		SessionTags     map[string]string `json:"session_tags"`
+		ExternalID      string            `json:"external_id"`
 	} `json:"data"`
 }
 
@@ -51,65 +59,15 @@ type vaultAWSConfigRolesList struct {
 	} `json:"data"`
 }
 
-/*
 type vaultAWSCred struct {
-	Data struct {
-		AccessKey string `json:"access_key"`
-		SecretKey string `json:"secret_key"`
+	LeaseID       string `json:"lease_id"`
+	LeaseDuration int    `json:"lease_duration"`
+	Renewable     bool   `json:"renewable"`
+	Data          struct {
+		AccessKey     string `json:"access_key"`
+		SecretKey     string `json:"secret_key"`
+		SecurityToken string `json:"security_token"`
 	} `json:"data"`
-}*/
-
-func (vh *VaultHandler) GetToken(ctx context.Context) (string, error) {
-
-	tr := otel.Tracer(vh.c.Server.PrefixMain)
-	_, span := tr.Start(ctx, utilities.GetFunctionName())
-	defer span.End()
-
-	// Create the authentication payload
-	authData := map[string]string{
-		"role_id":   vh.c.Vault.RoleID,
-		"secret_id": vh.c.Vault.SecretID,
-	}
-	authDataJSON, err := json.Marshal(authData)
-	if err != nil {
-		return "", err
-	}
-
-	// Construct the authentication request
-	url := fmt.Sprintf("%s/v1/%s", vh.vaultAddress, "auth/approle/login")
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(authDataJSON))
-	if err != nil {
-		return "", err
-	}
-	req.Header.Set("Content-Type", "application/json")
-
-	// Execute the HTTP request
-	resp, err := vh.hc.Do(req)
-	if err != nil {
-		return "", err
-	}
-
-	defer func() { _ = resp.Body.Close() }()
-
-	// Check for HTTP errors
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("failed to enable secrets engine: %s", string(body))
-		//return "", helper.ErrVaultAuthenticationFailed
-	}
-
-	// Parse the response
-	var respData struct {
-		Auth struct {
-			ClientToken string `json:"client_token"`
-		} `json:"auth"`
-	}
-	err = json.NewDecoder(resp.Body).Decode(&respData)
-	if err != nil {
-		return "", fmt.Errorf("failed to parse response: %v", err)
-	}
-
-	return respData.Auth.ClientToken, nil
 }
 
 func NewVaultHandler(c *configuration.Config, l *slog.Logger) (*VaultHandler, error) {
@@ -137,17 +95,89 @@ func NewVaultHandler(c *configuration.Config, l *slog.Logger) (*VaultHandler, er
 		Transport: transport,
 	}
 
-	vh := &VaultHandler{c, l, hc, vaultAddress}
+	vh := &VaultHandler{c: c, l: l, hc: hc, vaultAddress: vaultAddress}
 
 	err := vh.Ping(context.Background())
 	if err != nil {
 		return nil, err
 	}
 
+	// Fail fast on auth method misconfiguration rather than on first request
+	if _, _, err := vh.loginPayload(); err != nil {
+		return nil, err
+	}
+
 	return vh, nil
 }
 
-func (vh *VaultHandler) getAWSSecretsEngineConfig(token string, path string, r *vaultAWSConfig, ctx context.Context) error {
+// setNamespace sets Vault namespace request is executed in. Namespace configured for service is used when
+// namespace is empty, which is the case for connections created before namespaces were supported.
+func (vh *VaultHandler) setNamespace(req *http.Request, namespace string) {
+	if namespace == "" {
+		namespace = vh.c.Vault.Namespace
+	}
+
+	if namespace != "" {
+		req.Header.Set("X-Vault-Namespace", namespace)
+	}
+}
+
+// request sends payload as JSON to endpoint of Vault API in namespace and returns body of response. Body
+// is returned wrapped in failure if Vault does not respond with a success status.
+func (vh *VaultHandler) request(token string, method string, endpoint string, namespace string, payload interface{}, failure error) ([]byte, error) {
+
+	if payload == nil {
+		return vh.requestRaw(token, method, endpoint, namespace, "", nil, failure)
+	}
+
+	payloadJSON, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	return vh.requestRaw(token, method, endpoint, namespace, "application/json", payloadJSON, failure)
+}
+
+// requestRaw sends payload of content type as is to endpoint of Vault API in namespace and returns body of
+// response, for endpoints which do not speak JSON such as OCSP responder of PKI secrets engine.
+func (vh *VaultHandler) requestRaw(token string, method string, endpoint string, namespace string, contentType string, payload []byte, failure error) ([]byte, error) {
+
+	url := fmt.Sprintf("%s/v1/%s", vh.vaultAddress, endpoint)
+
+	var reqBody io.Reader
+	if payload != nil {
+		reqBody = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequest(method, url, reqBody)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Vault-Token", token)
+	vh.setNamespace(req, namespace)
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	resp, err := vh.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return nil, fmt.Errorf("%w: %s", failure, string(body))
+	}
+
+	return body, nil
+}
+
+func (vh *VaultHandler) getAWSSecretsEngineConfig(token string, path string, namespace string, r *vaultAWSConfig, ctx context.Context) error {
 
 	tr := otel.Tracer(vh.c.Server.PrefixMain)
 	_, span := tr.Start(ctx, utilities.GetFunctionName())
@@ -164,9 +194,10 @@ func (vh *VaultHandler) getAWSSecretsEngineConfig(token string, path string, r *
 
 	// Add the Vault token in the Authorization header
 	req.Header.Add("X-Vault-Token", token)
+	vh.setNamespace(req, namespace)
 
 	// Send the request
-	resp, err := vh.hc.Do(req)
+	resp, err := vh.do(req)
 	if err != nil {
 		return err
 	}
@@ -191,7 +222,7 @@ func (vh *VaultHandler) getAWSSecretsEngineConfig(token string, path string, r *
 	return err
 }
 
-func (vh *VaultHandler) getAWSSecretsEngineLease(token string, path string, r *vaultAWSConfig, ctx context.Context) error {
+func (vh *VaultHandler) getAWSSecretsEngineLease(token string, path string, namespace string, r *vaultAWSConfig, ctx context.Context) error {
 
 	tr := otel.Tracer(vh.c.Server.PrefixMain)
 	_, span := tr.Start(ctx, utilities.GetFunctionName())
@@ -208,9 +239,10 @@ func (vh *VaultHandler) getAWSSecretsEngineLease(token string, path string, r *v
 
 	// Add the Vault token in the Authorization header
 	req.Header.Add("X-Vault-Token", token)
+	vh.setNamespace(req, namespace)
 
 	// Send the request
-	resp, err := vh.hc.Do(req)
+	resp, err := vh.do(req)
 	if err != nil {
 		return err
 	}
@@ -235,7 +267,7 @@ func (vh *VaultHandler) getAWSSecretsEngineLease(token string, path string, r *v
 	return err
 }
 
-func (vh *VaultHandler) getAWSSecretsEngineRole(token string, path string, r *vaultAWSConfig, ctx context.Context) error {
+func (vh *VaultHandler) getAWSSecretsEngineRole(token string, path string, namespace string, r *vaultAWSConfig, ctx context.Context) error {
 
 	tr := otel.Tracer(vh.c.Server.PrefixMain)
 	_, span := tr.Start(ctx, utilities.GetFunctionName())
@@ -252,17 +284,22 @@ func (vh *VaultHandler) getAWSSecretsEngineRole(token string, path string, r *va
 
 	// Add the Vault token in the Authorization header
 	req.Header.Add("X-Vault-Token", token)
+	vh.setNamespace(req, namespace)
 
 	// Send the request
-	resp, err := vh.hc.Do(req)
+	resp, err := vh.do(req)
 	if err != nil {
 		return err
 	}
 	defer func() { _ = resp.Body.Close() }()
 
 	// Check if the response status code is OK (200)
+	if resp.StatusCode == http.StatusNotFound {
+		return helper.ErrVaultAWSEngineRoleNotFound
+	}
+
 	if resp.StatusCode != http.StatusOK {
-		return err
+		return helper.ErrVaultFailToRetrieveAWSEngineRole
 	}
 
 	body, err := io.ReadAll(resp.Body)
@@ -278,7 +315,7 @@ func (vh *VaultHandler) getAWSSecretsEngineRole(token string, path string, r *va
 	return err
 }
 
-func (vh *VaultHandler) getAWSSecretsEngineRoleName(token string, path string, r *vaultAWSConfig, ctx context.Context) error {
+func (vh *VaultHandler) getAWSSecretsEngineRoleName(token string, path string, namespace string, r *vaultAWSConfig, ctx context.Context) error {
 
 	tr := otel.Tracer(vh.c.Server.PrefixMain)
 	_, span := tr.Start(ctx, utilities.GetFunctionName())
@@ -295,9 +332,10 @@ func (vh *VaultHandler) getAWSSecretsEngineRoleName(token string, path string, r
 
 	// Add the Vault token in the Authorization header
 	req.Header.Add("X-Vault-Token", token)
+	vh.setNamespace(req, namespace)
 
 	// Send the request
-	resp, err := vh.hc.Do(req)
+	resp, err := vh.do(req)
 	if err != nil {
 		return err
 	}
@@ -320,39 +358,41 @@ func (vh *VaultHandler) getAWSSecretsEngineRoleName(token string, path string, r
 		return err
 	}
 
+	if len(rl.Data.Keys) == 0 {
+		return helper.ErrVaultAWSEngineRoleNotFound
+	}
+
 	r.Data.Role = rl.Data.Keys[0]
 	//r.Data.CredentialType = rl.Data.Keys[1]
 	return err
 }
 
-func (vh *VaultHandler) generateCredsAWSSecretsEngine(token string, path string, role string, credential_type string, r *data.CredsAWSConnectionResponse, ctx context.Context) error {
+func (vh *VaultHandler) generateCredsAWSSecretsEngine(token string, path string, namespace string, role string, credential_type string, roleARN string, ttl string, r *data.CredsAWSConnectionResponse, ctx context.Context) error {
 
 	tr := otel.Tracer(vh.c.Server.PrefixMain)
 	_, span := tr.Start(ctx, utilities.GetFunctionName())
 	defer span.End()
 
-	// Prepare the request URL
+	var req *http.Request
+	var err error
 
-	var endpoint string
-	if strings.ToLower(credential_type) == strIAMUser {
-		endpoint = "creds"
-	} else if strings.ToLower(credential_type) == strSessionToken {
-		endpoint = "sts"
+	// Prepare the request. iam_user credentials are read from creds endpoint and their lease
+	// is governed by mount's tune settings. sts endpoint accepts ttl for each request.
+	if strings.ToLower(credential_type) == strIAMUser && ttl != "" {
+		return helper.ErrVaultTTLNotSupportedForCredentialType
 	}
 
-	url := fmt.Sprintf("%s/v1/%s/%s/%s", vh.vaultAddress, path, endpoint, role)
-
-	// Create the request with appropriate headers
-	req, err := http.NewRequest("GET", url, nil)
+	req, err = vh.newAWSCredsRequest(path, role, credential_type, roleARN, ttl)
 	if err != nil {
 		return err
 	}
 
 	// Add the Vault token in the Authorization header
 	req.Header.Add("X-Vault-Token", token)
+	vh.setNamespace(req, namespace)
 
 	// Send the request
-	resp, err := vh.hc.Do(req)
+	resp, err := vh.do(req)
 	if err != nil {
 		return err
 	}
@@ -360,7 +400,8 @@ func (vh *VaultHandler) generateCredsAWSSecretsEngine(token string, path string,
 
 	// Check if the response status code is OK (200)
 	if resp.StatusCode != http.StatusOK {
-		return helper.ErrVaultFailToGenerateAWSCredentials
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("%w: %s", helper.ErrVaultFailToGenerateAWSCredentials, string(body))
 	}
 
 	body, err := io.ReadAll(resp.Body)
@@ -368,46 +409,90 @@ func (vh *VaultHandler) generateCredsAWSSecretsEngine(token string, path string,
 		return err
 	}
 
-	err = json.Unmarshal(body, r)
+	var cred vaultAWSCred
+
+	err = json.Unmarshal(body, &cred)
 	if err != nil {
 		return err
 	}
 
-	if strings.ToLower(credential_type) == "iam_user" {
+	r.LeaseID = cred.LeaseID
+	r.LeaseDuration = cred.LeaseDuration
+	r.Renewable = cred.Renewable
+	r.Data.AccessKey = cred.Data.AccessKey
+	r.Data.SecretKey = cred.Data.SecretKey
+	r.Data.SessionToken = cred.Data.SecurityToken
+
+	if strings.ToLower(credential_type) == strIAMUser {
 		r.Latency = vh.c.AWS.IAMUserLatency
 	}
 
 	return nil
 }
 
-func (vh *VaultHandler) testAWSSecretsEngine(token string, path string, role string, credential_type string, ctx context.Context) error {
+// newAWSCredsRequest prepares request for credentials endpoint matching credential type of role.
+// iam_user credentials are read from creds endpoint, all STS based credential types are requested
+// from sts endpoint.
+func (vh *VaultHandler) newAWSCredsRequest(path string, role string, credential_type string, roleARN string, ttl string) (*http.Request, error) {
+
+	switch strings.ToLower(credential_type) {
+	case strIAMUser:
+		url := fmt.Sprintf("%s/v1/%s/creds/%s", vh.vaultAddress, path, role)
+
+		return http.NewRequest("GET", url, nil)
+	case strSessionToken, strAssumedRole, strFederationToken:
+		url := fmt.Sprintf("%s/v1/%s/sts/%s", vh.vaultAddress, path, role)
+
+		payload := map[string]interface{}{}
+		if ttl != "" {
+			payload["ttl"] = ttl
+		}
+		if roleARN != "" && strings.ToLower(credential_type) == strAssumedRole {
+			payload["role_arn"] = roleARN
+		}
+
+		payloadJSON, err := json.Marshal(payload)
+		if err != nil {
+			return nil, err
+		}
+
+		req, err := http.NewRequest("POST", url, bytes.NewBuffer(payloadJSON))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+
+		return req, nil
+	default:
+		return nil, helper.ErrVaultUnsupportedCredentialType
+	}
+}
+
+func (vh *VaultHandler) testAWSSecretsEngine(token string, path string, namespace string, role string, credential_type string, roleARNs []string, ctx context.Context) error {
 
 	tr := otel.Tracer(vh.c.Server.PrefixMain)
 	_, span := tr.Start(ctx, utilities.GetFunctionName())
 	defer span.End()
 
-	var url string
-	var requestType string
-	if strings.ToLower(credential_type) == "iam_user" {
-		url = fmt.Sprintf("%s/v1/%s/creds/%s", vh.vaultAddress, path, role)
-		requestType = "GET"
-
-	} else if strings.ToLower(credential_type) == "session_token" {
-		url = fmt.Sprintf("%s/v1/%s/sts/%s", vh.vaultAddress, path, role)
-		requestType = "POST"
+	// assumed_role test is performed against first role arn of the role. Vault requires role_arn
+	// to be specified only when role has more than one role arn.
+	var roleARN string
+	if len(roleARNs) > 0 {
+		roleARN = roleARNs[0]
 	}
 
 	// Create the request with appropriate headers
-	req, err := http.NewRequest(requestType, url, nil)
+	req, err := vh.newAWSCredsRequest(path, role, credential_type, roleARN, "")
 	if err != nil {
 		return err
 	}
 
 	// Add the Vault token in the Authorization header
 	req.Header.Add("X-Vault-Token", token)
+	vh.setNamespace(req, namespace)
 
 	// Send the request
-	resp, err := vh.hc.Do(req)
+	resp, err := vh.do(req)
 	if err != nil {
 		return err
 	}
@@ -423,7 +508,7 @@ func (vh *VaultHandler) testAWSSecretsEngine(token string, path string, role str
 		return err
 	}
 
-	var cred data.CredsAWSConnectionResponse
+	var cred vaultAWSCred
 
 	err = json.Unmarshal(body, &cred)
 	if err != nil {
@@ -451,23 +536,28 @@ func (vh *VaultHandler) GetAWSSecretsEngine(c *data.AWSConnection, ctx context.C
 		return err
 	}
 
-	err = vh.getAWSSecretsEngineConfig(token, c.VaultPath, &awsConfig, ctx)
+	err = vh.getAWSSecretsEngineConfig(token, c.VaultPath, c.VaultNamespace, &awsConfig, ctx)
 	if err != nil {
 		return err
 	}
 
-	err = vh.getAWSSecretsEngineLease(token, c.VaultPath, &awsConfig, ctx)
+	err = vh.getAWSSecretsEngineLease(token, c.VaultPath, c.VaultNamespace, &awsConfig, ctx)
 	if err != nil {
 		return err
 	}
 
-	err = vh.getAWSSecretsEngineRoleName(token, c.VaultPath, &awsConfig, ctx)
-	if err != nil {
-		return err
+	// Connections created before role name was persisted fall back to first role of the mount
+	awsConfig.Data.Role = c.RoleName
+	if awsConfig.Data.Role == "" {
+		err = vh.getAWSSecretsEngineRoleName(token, c.VaultPath, c.VaultNamespace, &awsConfig, ctx)
+		if err != nil {
+			return err
+		}
 	}
 
-	err = vh.getAWSSecretsEngineRole(token, c.VaultPath, &awsConfig, ctx)
-	if err != nil {
+	// Default role is missing from mounts re-created by reconciler until connection is patched
+	err = vh.getAWSSecretsEngineRole(token, c.VaultPath, c.VaultNamespace, &awsConfig, ctx)
+	if err != nil && !errors.Is(err, helper.ErrVaultAWSEngineRoleNotFound) {
 		return err
 	}
 
@@ -478,6 +568,10 @@ func (vh *VaultHandler) GetAWSSecretsEngine(c *data.AWSConnection, ctx context.C
 	c.PolicyARNs = awsConfig.Data.PolicyARNs
 	c.RoleName = awsConfig.Data.Role
 	c.CredentialType = awsConfig.Data.CredentialType
+	c.PolicyDocument = awsConfig.Data.PolicyDocument
+	c.RoleARNs = awsConfig.Data.RoleARNs
+	c.SessionTags = awsConfig.Data.SessionTags
+	c.ExternalID = awsConfig.Data.ExternalID
 
 	return nil
 }
@@ -493,22 +587,22 @@ func (vh *VaultHandler) AddAWSSecretsEngine(c *data.AWSConnection, ctx context.C
 		return err
 	}
 
-	err = vh.enableAWSSecretsEngine(token, c.VaultPath, ctx)
+	err = vh.enableAWSSecretsEngine(token, c.VaultPath, c.VaultNamespace, ctx)
 	if err != nil {
 		return err
 	}
 
-	err = vh.configureAWSSecretsEngine(token, c.VaultPath, c.DefaultLeaseTTL, c.MaxLeaseTTL, ctx)
+	err = vh.configureAWSSecretsEngine(token, c.VaultPath, c.VaultNamespace, c.DefaultLeaseTTL, c.MaxLeaseTTL, ctx)
 	if err != nil {
 		return err
 	}
 
-	err = vh.configureAWSRootCredentials(token, c.VaultPath, c.AccessKey, c.SecretAccessKey, c.DefaultRegion, ctx)
+	err = vh.configureAWSRootCredentials(token, c.VaultPath, c.VaultNamespace, c.AccessKey, c.SecretAccessKey, c.DefaultRegion, ctx)
 	if err != nil {
 		return err
 	}
 
-	err = vh.configureAWSIAMRole(token, c.VaultPath, c.RoleName, c.PolicyARNs, c.CredentialType, ctx)
+	err = vh.configureAWSIAMRole(token, c.VaultPath, c.VaultNamespace, c.DefaultRole(), ctx)
 	if err != nil {
 		return err
 	}
@@ -527,17 +621,17 @@ func (vh *VaultHandler) UpdateAWSSecretsEngine(c *data.AWSConnection, ctx contex
 		return err
 	}
 
-	err = vh.configureAWSSecretsEngine(token, c.VaultPath, c.DefaultLeaseTTL, c.MaxLeaseTTL, ctx)
+	err = vh.configureAWSSecretsEngine(token, c.VaultPath, c.VaultNamespace, c.DefaultLeaseTTL, c.MaxLeaseTTL, ctx)
 	if err != nil {
 		return err
 	}
 
-	err = vh.configureAWSRootCredentials(token, c.VaultPath, c.AccessKey, c.SecretAccessKey, c.DefaultRegion, ctx)
+	err = vh.configureAWSRootCredentials(token, c.VaultPath, c.VaultNamespace, c.AccessKey, c.SecretAccessKey, c.DefaultRegion, ctx)
 	if err != nil {
 		return err
 	}
 
-	err = vh.configureAWSIAMRole(token, c.VaultPath, c.RoleName, c.PolicyARNs, c.CredentialType, ctx)
+	err = vh.configureAWSIAMRole(token, c.VaultPath, c.VaultNamespace, c.DefaultRole(), ctx)
 	if err != nil {
 		return err
 	}
@@ -556,7 +650,7 @@ func (vh *VaultHandler) RemoveAWSSecretsEngine(c *data.AWSConnection, ctx contex
 		return err
 	}
 
-	err = vh.disableAWSSecretsEngine(token, c.VaultPath, ctx)
+	err = vh.disableAWSSecretsEngine(token, c.VaultPath, c.VaultNamespace, ctx)
 	if err != nil {
 		return err
 	}
@@ -564,7 +658,7 @@ func (vh *VaultHandler) RemoveAWSSecretsEngine(c *data.AWSConnection, ctx contex
 	return nil
 }
 
-func (vh *VaultHandler) enableAWSSecretsEngine(token string, path string, ctx context.Context) error {
+func (vh *VaultHandler) enableAWSSecretsEngine(token string, path string, namespace string, ctx context.Context) error {
 
 	tr := otel.Tracer(vh.c.Server.PrefixMain)
 	_, span := tr.Start(ctx, utilities.GetFunctionName())
@@ -572,7 +666,7 @@ func (vh *VaultHandler) enableAWSSecretsEngine(token string, path string, ctx co
 
 	url := fmt.Sprintf("%s/v1/sys/mounts/%s", vh.vaultAddress, path)
 	data := map[string]interface{}{
-		"type": "aws",
+		"type": EngineAWS,
 	}
 	payload, err := json.Marshal(data)
 	if err != nil {
@@ -584,9 +678,10 @@ func (vh *VaultHandler) enableAWSSecretsEngine(token string, path string, ctx co
 		return err
 	}
 	req.Header.Set("X-Vault-Token", token)
+	vh.setNamespace(req, namespace)
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := vh.hc.Do(req)
+	resp, err := vh.do(req)
 	if err != nil {
 		return err
 	}
@@ -599,7 +694,7 @@ func (vh *VaultHandler) enableAWSSecretsEngine(token string, path string, ctx co
 	return nil
 }
 
-func (vh *VaultHandler) disableAWSSecretsEngine(token string, path string, ctx context.Context) error {
+func (vh *VaultHandler) disableAWSSecretsEngine(token string, path string, namespace string, ctx context.Context) error {
 
 	tr := otel.Tracer(vh.c.Server.PrefixMain)
 	_, span := tr.Start(ctx, utilities.GetFunctionName())
@@ -619,9 +714,10 @@ func (vh *VaultHandler) disableAWSSecretsEngine(token string, path string, ctx c
 		return err
 	}
 	req.Header.Set("X-Vault-Token", token)
+	vh.setNamespace(req, namespace)
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := vh.hc.Do(req)
+	resp, err := vh.do(req)
 	if err != nil {
 		return err
 	}
@@ -634,7 +730,7 @@ func (vh *VaultHandler) disableAWSSecretsEngine(token string, path string, ctx c
 	return nil
 }
 
-func (vh *VaultHandler) configureAWSRootCredentials(token string, path string, accessKey string, secretKey string, defaultRegion string, ctx context.Context) error {
+func (vh *VaultHandler) configureAWSRootCredentials(token string, path string, namespace string, accessKey string, secretKey string, defaultRegion string, ctx context.Context) error {
 
 	tr := otel.Tracer(vh.c.Server.PrefixMain)
 	_, span := tr.Start(ctx, utilities.GetFunctionName())
@@ -656,9 +752,10 @@ func (vh *VaultHandler) configureAWSRootCredentials(token string, path string, a
 		return err
 	}
 	req.Header.Set("X-Vault-Token", token)
+	vh.setNamespace(req, namespace)
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := vh.hc.Do(req)
+	resp, err := vh.do(req)
 	if err != nil {
 		return err
 	}
@@ -671,7 +768,7 @@ func (vh *VaultHandler) configureAWSRootCredentials(token string, path string, a
 	return nil
 }
 
-func (vh *VaultHandler) configureAWSSecretsEngine(token string, path string, defaultTTL string, maxTTL string, ctx context.Context) error {
+func (vh *VaultHandler) configureAWSSecretsEngine(token string, path string, namespace string, defaultTTL string, maxTTL string, ctx context.Context) error {
 
 	tr := otel.Tracer(vh.c.Server.PrefixMain)
 	_, span := tr.Start(ctx, utilities.GetFunctionName())
@@ -692,9 +789,10 @@ func (vh *VaultHandler) configureAWSSecretsEngine(token string, path string, def
 		return err
 	}
 	req.Header.Set("X-Vault-Token", token)
+	vh.setNamespace(req, namespace)
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := vh.hc.Do(req)
+	resp, err := vh.do(req)
 	if err != nil {
 		return err
 	}
@@ -707,18 +805,35 @@ func (vh *VaultHandler) configureAWSSecretsEngine(token string, path string, def
 	return nil
 }
 
-func (vh *VaultHandler) configureAWSIAMRole(token string, path string, roleName string, policyARNs []string, credentialType string, ctx context.Context) error {
+func (vh *VaultHandler) configureAWSIAMRole(token string, path string, namespace string, r *data.AWSRole, ctx context.Context) error {
 
 	tr := otel.Tracer(vh.c.Server.PrefixMain)
 	_, span := tr.Start(ctx, utilities.GetFunctionName())
 	defer span.End()
 
-	url := fmt.Sprintf("%s/v1/%s/roles/%s", vh.vaultAddress, path, roleName)
+	url := fmt.Sprintf("%s/v1/%s/roles/%s", vh.vaultAddress, path, r.RoleName)
 
+	// Every attribute is sent so that updating an existing role does not keep values of
+	// attributes which are not supported by its new credential type.
 	data := map[string]interface{}{
-		"policy_arns":     policyARNs,
-		"credential_type": credentialType,
+		"policy_arns":     r.PolicyARNs,
+		"credential_type": r.CredentialType,
+		"policy_document": r.PolicyDocument,
+		"role_arns":       []string{},
+		"session_tags":    map[string]string{},
+		"external_id":     "",
+	}
+
+	if strings.ToLower(r.CredentialType) == strAssumedRole {
+		data["role_arns"] = r.RoleARNs
+
+		if len(r.SessionTags) > 0 {
+			data["session_tags"] = r.SessionTags
+		}
+
+		data["external_id"] = r.ExternalID
 	}
+
 	payload, err := json.Marshal(data)
 	if err != nil {
 		return err
@@ -729,9 +844,10 @@ func (vh *VaultHandler) configureAWSIAMRole(token string, path string, roleName
 		return err
 	}
 	req.Header.Set("X-Vault-Token", token)
+	vh.setNamespace(req, namespace)
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := vh.hc.Do(req)
+	resp, err := vh.do(req)
 	if err != nil {
 		return err
 	}
@@ -776,7 +892,7 @@ func (vh *VaultHandler) Ping(ctx context.Context) error {
 	}
 }
 
-func (vh *VaultHandler) GenerateCredsAWSSecretsEngine(path string, ctx context.Context) (*data.CredsAWSConnectionResponse, error) {
+func (vh *VaultHandler) GenerateCredsAWSSecretsEngine(path string, namespace string, role string, roleARN string, ttl string, ctx context.Context) (*data.CredsAWSConnectionResponse, error) {
 
 	tr := otel.Tracer(vh.c.Server.PrefixMain)
 	ctx, span := tr.Start(ctx, utilities.GetFunctionName())
@@ -790,25 +906,30 @@ func (vh *VaultHandler) GenerateCredsAWSSecretsEngine(path string, ctx context.C
 		return nil, err
 	}
 
-	err = vh.getAWSSecretsEngineRoleName(token, path, &awsConfig, ctx)
-	if err != nil {
-		return nil, err
+	awsConfig.Data.Role = role
+	if awsConfig.Data.Role == "" {
+		err = vh.getAWSSecretsEngineRoleName(token, path, namespace, &awsConfig, ctx)
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	err = vh.getAWSSecretsEngineRole(token, path, &awsConfig, ctx)
+	err = vh.getAWSSecretsEngineRole(token, path, namespace, &awsConfig, ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	err = vh.generateCredsAWSSecretsEngine(token, path, awsConfig.Data.Role, awsConfig.Data.CredentialType, &credsResponse, ctx)
+	err = vh.generateCredsAWSSecretsEngine(token, path, namespace, awsConfig.Data.Role, awsConfig.Data.CredentialType, roleARN, ttl, &credsResponse, ctx)
 	if err != nil {
 		return nil, err
 	}
 
+	credsResponse.RoleName = awsConfig.Data.Role
+
 	return &credsResponse, nil
 }
 
-func (vh *VaultHandler) TestAWSSecretsEngine(path string, ctx context.Context) error {
+func (vh *VaultHandler) TestAWSSecretsEngine(path string, namespace string, role string, ctx context.Context) error {
 
 	tr := otel.Tracer(vh.c.Server.PrefixMain)
 	ctx, span := tr.Start(ctx, utilities.GetFunctionName())
@@ -821,17 +942,20 @@ func (vh *VaultHandler) TestAWSSecretsEngine(path string, ctx context.Context) e
 		return err
 	}
 
-	err = vh.getAWSSecretsEngineRoleName(token, path, &awsConfig, ctx)
-	if err != nil {
-		return err
+	awsConfig.Data.Role = role
+	if awsConfig.Data.Role == "" {
+		err = vh.getAWSSecretsEngineRoleName(token, path, namespace, &awsConfig, ctx)
+		if err != nil {
+			return err
+		}
 	}
 
-	err = vh.getAWSSecretsEngineRole(token, path, &awsConfig, ctx)
+	err = vh.getAWSSecretsEngineRole(token, path, namespace, &awsConfig, ctx)
 	if err != nil {
 		return err
 	}
 
-	err = vh.testAWSSecretsEngine(token, path, awsConfig.Data.Role, awsConfig.Data.CredentialType, ctx)
+	err = vh.testAWSSecretsEngine(token, path, namespace, awsConfig.Data.Role, awsConfig.Data.CredentialType, awsConfig.Data.RoleARNs, ctx)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,74 @@
+package secretsmanager
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"DemoServer_ConnectionManager/helper"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fakeAWSVault serves AWS secrets engine mounted at aws with single role of credentialType and records payload
+// of credentials requests.
+type fakeAWSVault struct {
+	credentialType string
+	requests       atomic.Int32
+	payload        map[string]interface{}
+}
+
+func (f *fakeAWSVault) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/v1/auth/approle/login":
+		_, _ = fmt.Fprint(w, `{"auth":{"client_token":"token-1","lease_duration":3600,"renewable":true}}`)
+	case "/v1/aws/roles/default":
+		_, _ = fmt.Fprintf(w, `{"data":{"credential_type":"%s"}}`, f.credentialType)
+	case "/v1/aws/sts/default", "/v1/aws/creds/default":
+		f.requests.Add(1)
+		if r.Method == http.MethodPost {
+			_ = json.NewDecoder(r.Body).Decode(&f.payload)
+		}
+		_, _ = fmt.Fprint(w, `{"lease_id":"aws/sts/default/abc","lease_duration":900,"data":{"access_key":"AKIA","secret_key":"secret","security_token":"token"}}`)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestGenerateCredsAWSSecretsEngine_TTLSentToSTS(t *testing.T) {
+	f := &fakeAWSVault{credentialType: "session_token"}
+	srv := httptest.NewServer(f)
+	defer srv.Close()
+
+	vh := newTestVaultHandler(srv.URL)
+
+	creds, err := vh.GenerateCredsAWSSecretsEngine("aws", "", "default", "", "15m", context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "aws/sts/default/abc", creds.LeaseID)
+
+	require.Equal(t, int32(1), f.requests.Load())
+	require.Equal(t, "15m", f.payload["ttl"])
+}
+
+func TestGenerateCredsAWSSecretsEngine_IAMUserRejectsTTL(t *testing.T) {
+	f := &fakeAWSVault{credentialType: "iam_user"}
+	srv := httptest.NewServer(f)
+	defer srv.Close()
+
+	vh := newTestVaultHandler(srv.URL)
+
+	_, err := vh.GenerateCredsAWSSecretsEngine("aws", "", "default", "", "15m", context.Background())
+	require.ErrorIs(t, err, helper.ErrVaultTTLNotSupportedForCredentialType)
+
+	// Credentials are never requested, so no IAM user is created in vain
+	require.Equal(t, int32(0), f.requests.Load())
+
+	creds, err := vh.GenerateCredsAWSSecretsEngine("aws", "", "default", "", "", context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "AKIA", creds.Data.AccessKey)
+	require.Equal(t, int32(1), f.requests.Load())
+}
@@ -0,0 +1,122 @@
+package secretsmanager
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"DemoServer_ConnectionManager/data"
+	"DemoServer_ConnectionManager/helper"
+	"DemoServer_ConnectionManager/utilities"
+
+	"go.opentelemetry.io/otel"
+)
+
+func (vh *VaultHandler) RenewLease(leaseID string, namespace string, increment string, ctx context.Context) (*data.VaultLeaseRenewal, error) {
+
+	tr := otel.Tracer(vh.c.Server.PrefixMain)
+	ctx, span := tr.Start(ctx, utilities.GetFunctionName())
+	defer span.End()
+
+	token, err := vh.GetToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	payload := map[string]interface{}{
+		"lease_id": leaseID,
+	}
+	if increment != "" {
+		payload["increment"] = increment
+	}
+
+	body, err := vh.putSysLeases(token, namespace, "renew", payload, http.StatusOK, helper.ErrVaultFailToRenewLease)
+	if err != nil {
+		return nil, err
+	}
+
+	var renewal data.VaultLeaseRenewal
+
+	err = json.Unmarshal(body, &renewal)
+	if err != nil {
+		return nil, err
+	}
+
+	return &renewal, nil
+}
+
+func (vh *VaultHandler) RevokeLease(leaseID string, namespace string, ctx context.Context) error {
+
+	tr := otel.Tracer(vh.c.Server.PrefixMain)
+	ctx, span := tr.Start(ctx, utilities.GetFunctionName())
+	defer span.End()
+
+	token, err := vh.GetToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	payload := map[string]interface{}{
+		"lease_id": leaseID,
+	}
+
+	_, err = vh.putSysLeases(token, namespace, "revoke", payload, http.StatusNoContent, helper.ErrVaultFailToRevokeLease)
+
+	return err
+}
+
+// RevokeLeasesByPrefix revokes all leases issued under prefix. It is used to revoke every credential
+// generated through a secrets engine mount, including the ones not tracked in datastore.
+func (vh *VaultHandler) RevokeLeasesByPrefix(prefix string, namespace string, ctx context.Context) error {
+
+	tr := otel.Tracer(vh.c.Server.PrefixMain)
+	ctx, span := tr.Start(ctx, utilities.GetFunctionName())
+	defer span.End()
+
+	token, err := vh.GetToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = vh.putSysLeases(token, namespace, "revoke-prefix/"+prefix, map[string]interface{}{}, http.StatusNoContent, helper.ErrVaultFailToRevokeLease)
+
+	return err
+}
+
+func (vh *VaultHandler) putSysLeases(token string, namespace string, endpoint string, payload map[string]interface{}, expectedStatus int, failure error) ([]byte, error) {
+
+	url := fmt.Sprintf("%s/v1/sys/leases/%s", vh.vaultAddress, endpoint)
+
+	payloadJSON, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("PUT", url, bytes.NewBuffer(payloadJSON))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Vault-Token", token)
+	vh.setNamespace(req, namespace)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := vh.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != expectedStatus {
+		return nil, fmt.Errorf("%w: %s", failure, string(body))
+	}
+
+	return body, nil
+}
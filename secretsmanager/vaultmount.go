@@ -0,0 +1,221 @@
+package secretsmanager
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"DemoServer_ConnectionManager/helper"
+	"DemoServer_ConnectionManager/utilities"
+
+	"go.opentelemetry.io/otel"
+)
+
+type vaultMountsList struct {
+	Data map[string]struct {
+		Type string `json:"type"`
+	} `json:"data"`
+}
+
+// listMounts returns all secrets engine mounts of namespace keyed by their path with trailing slash.
+func (vh *VaultHandler) listMounts(namespace string, ctx context.Context) (*vaultMountsList, error) {
+
+	tr := otel.Tracer(vh.c.Server.PrefixMain)
+	ctx, span := tr.Start(ctx, utilities.GetFunctionName())
+	defer span.End()
+
+	token, err := vh.GetToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	url := fmt.Sprintf("%s/v1/sys/mounts", vh.vaultAddress)
+
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Vault-Token", token)
+	vh.setNamespace(req, namespace)
+
+	resp, err := vh.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, helper.ErrVaultFailToListSecretsEngines
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var mounts vaultMountsList
+
+	err = json.Unmarshal(body, &mounts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &mounts, nil
+}
+
+// ListSecretsEngineMounts returns secrets engine mounts of namespace managed by connection manager, i.e. mounts
+// under configured path prefix, along with type of secrets engine mounted at each path.
+func (vh *VaultHandler) ListSecretsEngineMounts(namespace string, ctx context.Context) (map[string]string, error) {
+
+	tr := otel.Tracer(vh.c.Server.PrefixMain)
+	ctx, span := tr.Start(ctx, utilities.GetFunctionName())
+	defer span.End()
+
+	mounts, err := vh.listMounts(namespace, ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := vh.c.Vault.PathPrefix + "/"
+
+	paths := map[string]string{}
+
+	for path, mount := range mounts.Data {
+		if strings.HasPrefix(path, prefix) {
+			paths[strings.TrimSuffix(path, "/")] = mount.Type
+		}
+	}
+
+	return paths, nil
+}
+
+// CheckMountPathAvailable returns ErrVaultMountPathInUse if a secrets engine of any type is mounted at path
+// in namespace, or at a path nested in it or containing it, as Vault does not allow overlapping mounts.
+func (vh *VaultHandler) CheckMountPathAvailable(path string, namespace string, ctx context.Context) error {
+
+	tr := otel.Tracer(vh.c.Server.PrefixMain)
+	ctx, span := tr.Start(ctx, utilities.GetFunctionName())
+	defer span.End()
+
+	mounts, err := vh.listMounts(namespace, ctx)
+	if err != nil {
+		return err
+	}
+
+	paths := make([]string, 0, len(mounts.Data))
+	for mounted := range mounts.Data {
+		paths = append(paths, mounted)
+	}
+
+	return checkMountPathOverlap(path, paths)
+}
+
+// EnableSecretsEngineMount enables an unconfigured secrets engine of engineType at path.
+func (vh *VaultHandler) EnableSecretsEngineMount(path string, namespace string, engineType string, ctx context.Context) error {
+
+	tr := otel.Tracer(vh.c.Server.PrefixMain)
+	ctx, span := tr.Start(ctx, utilities.GetFunctionName())
+	defer span.End()
+
+	token, err := vh.GetToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	return vh.enableSecretsEngine(token, path, namespace, engineType, "", "", ctx)
+}
+
+// DisableSecretsEngineMount disables secrets engine of any type at path. Vault revokes all leases issued through it.
+func (vh *VaultHandler) DisableSecretsEngineMount(path string, namespace string, ctx context.Context) error {
+
+	tr := otel.Tracer(vh.c.Server.PrefixMain)
+	ctx, span := tr.Start(ctx, utilities.GetFunctionName())
+	defer span.End()
+
+	token, err := vh.GetToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	return vh.disableSecretsEngine(token, path, namespace, ctx)
+}
+
+type vaultMountTune struct {
+	Data struct {
+		DefaultLeaseTTL int `json:"default_lease_ttl"`
+		MaxLeaseTTL     int `json:"max_lease_ttl"`
+	} `json:"data"`
+}
+
+// enableSecretsEngine enables secrets engine of engineType at path and sets its lease ttls. Empty ttls keep
+// system defaults of Vault.
+func (vh *VaultHandler) enableSecretsEngine(token string, path string, namespace string, engineType string, defaultTTL string, maxTTL string, ctx context.Context) error {
+
+	tr := otel.Tracer(vh.c.Server.PrefixMain)
+	_, span := tr.Start(ctx, utilities.GetFunctionName())
+	defer span.End()
+
+	payload := map[string]interface{}{
+		"type": engineType,
+	}
+
+	if _, err := vh.request(token, http.MethodPost, "sys/mounts/"+path, namespace, payload, helper.ErrVaultFailToEnableSecretsEngine); err != nil {
+		return err
+	}
+
+	return vh.tuneSecretsEngine(token, path, namespace, defaultTTL, maxTTL, ctx)
+}
+
+// tuneSecretsEngine sets lease ttls of secrets engine mounted at path.
+func (vh *VaultHandler) tuneSecretsEngine(token string, path string, namespace string, defaultTTL string, maxTTL string, ctx context.Context) error {
+
+	tr := otel.Tracer(vh.c.Server.PrefixMain)
+	_, span := tr.Start(ctx, utilities.GetFunctionName())
+	defer span.End()
+
+	payload := map[string]interface{}{
+		"default_lease_ttl": defaultTTL,
+		"max_lease_ttl":     maxTTL,
+	}
+
+	_, err := vh.request(token, http.MethodPost, "sys/mounts/"+path+"/tune", namespace, payload, helper.ErrVaultFailToConfigureSecretsEngine)
+
+	return err
+}
+
+// readSecretsEngineTune returns lease ttls of secrets engine mounted at path in duration format i.e. 3600s.
+func (vh *VaultHandler) readSecretsEngineTune(token string, path string, namespace string, ctx context.Context) (string, string, error) {
+
+	tr := otel.Tracer(vh.c.Server.PrefixMain)
+	_, span := tr.Start(ctx, utilities.GetFunctionName())
+	defer span.End()
+
+	body, err := vh.request(token, http.MethodGet, "sys/mounts/"+path+"/tune", namespace, nil, helper.ErrVaultFailToReadSecretsEngine)
+	if err != nil {
+		return "", "", err
+	}
+
+	var tune vaultMountTune
+
+	if err := json.Unmarshal(body, &tune); err != nil {
+		return "", "", err
+	}
+
+	return strconv.Itoa(tune.Data.DefaultLeaseTTL) + "s", strconv.Itoa(tune.Data.MaxLeaseTTL) + "s", nil
+}
+
+// disableSecretsEngine disables secrets engine mounted at path. Vault revokes all leases issued through it.
+func (vh *VaultHandler) disableSecretsEngine(token string, path string, namespace string, ctx context.Context) error {
+
+	tr := otel.Tracer(vh.c.Server.PrefixMain)
+	_, span := tr.Start(ctx, utilities.GetFunctionName())
+	defer span.End()
+
+	_, err := vh.request(token, http.MethodDelete, "sys/mounts/"+path, namespace, nil, helper.ErrVaultFailToDisableSecretsEngine)
+
+	return err
+}
@@ -0,0 +1,577 @@
+package secretsmanager
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"DemoServer_ConnectionManager/configuration"
+	"DemoServer_ConnectionManager/data"
+	"DemoServer_ConnectionManager/helper"
+	"DemoServer_ConnectionManager/utilities"
+
+	"go.opentelemetry.io/otel"
+)
+
+var strEnginePKI = EnginePKI
+
+// pkiTestTTL validity of throwaway certificate issued when testing connection
+var pkiTestTTL = "5m"
+
+type vaultPKICA struct {
+	Data struct {
+		Certificate string `json:"certificate"`
+	} `json:"data"`
+}
+
+type vaultPKICSR struct {
+	Data struct {
+		CSR string `json:"csr"`
+	} `json:"data"`
+}
+
+type vaultPKISignedIntermediate struct {
+	Data struct {
+		Certificate string   `json:"certificate"`
+		IssuingCA   string   `json:"issuing_ca"`
+		CAChain     []string `json:"ca_chain"`
+	} `json:"data"`
+}
+
+type vaultPKIRole struct {
+	Data struct {
+		KeyType          string   `json:"key_type"`
+		AllowedDomains   []string `json:"allowed_domains"`
+		AllowSubdomains  bool     `json:"allow_subdomains"`
+		AllowBareDomains bool     `json:"allow_bare_domains"`
+	} `json:"data"`
+}
+
+type vaultPKICertificate struct {
+	Data struct {
+		SerialNumber   string   `json:"serial_number"`
+		Certificate    string   `json:"certificate"`
+		IssuingCA      string   `json:"issuing_ca"`
+		CAChain        []string `json:"ca_chain"`
+		PrivateKey     string   `json:"private_key"`
+		PrivateKeyType string   `json:"private_key_type"`
+		Expiration     int64    `json:"expiration"`
+	} `json:"data"`
+}
+
+// pkiDistributionURLs returns URLs of CA certificate, CRL and OCSP responder embedded in certificates issued by
+// connection. Endpoints of service are used when its external URL is configured, as relying parties often can not
+// reach Vault. Endpoints of Vault are used otherwise, unless vaultAddress is empty.
+func pkiDistributionURLs(cfg *configuration.Config, vaultAddress string, c *data.PKIConnection) (string, string, string) {
+	var base string
+
+	switch {
+	case cfg.PKI.BaseURL != "":
+		base = strings.TrimSuffix(cfg.PKI.BaseURL, "/") + "/v1/connectionmgmt/connection/pki/" + c.ID.String()
+	case vaultAddress != "":
+		namespace := c.VaultNamespace
+		if namespace == "" {
+			namespace = cfg.Vault.Namespace
+		}
+		if namespace != "" {
+			namespace = strings.Trim(namespace, "/") + "/"
+		}
+		base = vaultAddress + "/v1/" + namespace + c.VaultPath
+	default:
+		return "", "", ""
+	}
+
+	return base + "/ca", base + "/crl", base + "/ocsp"
+}
+
+// parsePEMCertificate parses first certificate of PEM bundle.
+func parsePEMCertificate(bundle string) (*x509.Certificate, error) {
+	block, _ := pem.Decode([]byte(bundle))
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, fmt.Errorf("no certificate found in PEM data")
+	}
+	return x509.ParseCertificate(block.Bytes)
+}
+
+// testPKIIssuance issues throwaway certificate for common name, verifies it is signed by CA of secrets engine and
+// revokes it. Private key of certificate is discarded, so certificate is of no use to anyone in the meantime.
+func testPKIIssuance(issue func(r *data.IssuePKIConnectionRequest) (*data.IssuePKIConnectionResponse, error), revoke func(serialNumber string) error, caCertificate []byte, commonName string) error {
+	response, err := issue(&data.IssuePKIConnectionRequest{
+		CommonName: commonName,
+		TTL:        pkiTestTTL,
+	})
+	if err != nil {
+		return err
+	}
+
+	ca, err := x509.ParseCertificate(caCertificate)
+	if err != nil {
+		return fmt.Errorf("%w: certificate of CA is invalid: %s", helper.ErrVaultFailToIssuePKICertificate, err.Error())
+	}
+
+	cert, err := parsePEMCertificate(response.Certificate)
+	if err != nil {
+		return fmt.Errorf("%w: %s", helper.ErrVaultFailToIssuePKICertificate, err.Error())
+	}
+
+	if err := cert.CheckSignatureFrom(ca); err != nil {
+		return fmt.Errorf("%w: certificate is not signed by CA of secrets engine: %s", helper.ErrVaultFailToIssuePKICertificate, err.Error())
+	}
+
+	return revoke(response.SerialNumber)
+}
+
+func (vh *VaultHandler) GetPKISecretsEngine(c *data.PKIConnection, ctx context.Context) error {
+
+	tr := otel.Tracer(vh.c.Server.PrefixMain)
+	ctx, span := tr.Start(ctx, utilities.GetFunctionName())
+	defer span.End()
+
+	token, err := vh.GetToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	body, err := vh.request(token, http.MethodGet, c.VaultPath+"/cert/ca", c.VaultNamespace, nil, helper.ErrVaultFailToReadSecretsEngine)
+	if err != nil {
+		return err
+	}
+
+	var ca vaultPKICA
+
+	if err := json.Unmarshal(body, &ca); err != nil {
+		return err
+	}
+
+	cert, err := parsePEMCertificate(ca.Data.Certificate)
+	if err != nil {
+		return fmt.Errorf("%w: %s", helper.ErrVaultFailToReadSecretsEngine, err.Error())
+	}
+
+	role := c.DefaultRole()
+
+	if err := vh.readPKIRole(token, c.VaultPath, c.VaultNamespace, role); err != nil {
+		return err
+	}
+
+	c.DefaultLeaseTTL, c.MaxLeaseTTL, err = vh.readSecretsEngineTune(token, c.VaultPath, c.VaultNamespace, ctx)
+	if err != nil {
+		return err
+	}
+
+	c.Certificate = strings.TrimSpace(ca.Data.Certificate)
+	c.CommonName = cert.Subject.CommonName
+	c.KeyType = role.KeyType
+	c.AllowedDomains = role.AllowedDomains
+	c.AllowSubdomains = role.AllowSubdomains
+	c.AllowBareDomains = role.AllowBareDomains
+
+	return nil
+}
+
+func (vh *VaultHandler) AddPKISecretsEngine(c *data.PKIConnection, issuer *data.PKIConnection, ctx context.Context) error {
+
+	tr := otel.Tracer(vh.c.Server.PrefixMain)
+	ctx, span := tr.Start(ctx, utilities.GetFunctionName())
+	defer span.End()
+
+	token, err := vh.GetToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = vh.enableSecretsEngine(token, c.VaultPath, c.VaultNamespace, strEnginePKI, c.DefaultLeaseTTL, c.MaxLeaseTTL, ctx)
+	if err != nil {
+		return err
+	}
+
+	// Signing by issuer is refused when validity of intermediate CA exceeds validity of its CA, so mount is not
+	// left behind without CA for path to stay available on retry
+	if err := vh.setupPKICA(token, c, issuer, ctx); err != nil {
+		if disableErr := vh.disableSecretsEngine(token, c.VaultPath, c.VaultNamespace, ctx); disableErr != nil {
+			helper.LogError(vh.l, helper.ErrorVaultSecretsEngineFailed, disableErr, span)
+		}
+		return err
+	}
+
+	return nil
+}
+
+// setupPKICA generates key pair and certificate of CA, configures distribution URLs embedded in issued certificates
+// and writes role of connection. Key of CA is generated inside Vault and never leaves it.
+func (vh *VaultHandler) setupPKICA(token string, c *data.PKIConnection, issuer *data.PKIConnection, ctx context.Context) error {
+
+	tr := otel.Tracer(vh.c.Server.PrefixMain)
+	ctx, span := tr.Start(ctx, utilities.GetFunctionName())
+	defer span.End()
+
+	ca := map[string]interface{}{
+		"common_name": c.CommonName,
+	}
+	if c.KeyType != "" {
+		ca["key_type"] = c.KeyType
+	}
+
+	if issuer == nil {
+		if c.MaxLeaseTTL != "" {
+			ca["ttl"] = c.MaxLeaseTTL
+		}
+
+		body, err := vh.request(token, http.MethodPost, c.VaultPath+"/root/generate/internal", c.VaultNamespace, ca, helper.ErrVaultFailToConfigureSecretsEngine)
+		if err != nil {
+			return err
+		}
+
+		var root vaultPKICA
+
+		if err := json.Unmarshal(body, &root); err != nil {
+			return err
+		}
+		c.Certificate = strings.TrimSpace(root.Data.Certificate)
+	} else {
+		body, err := vh.request(token, http.MethodPost, c.VaultPath+"/intermediate/generate/internal", c.VaultNamespace, ca, helper.ErrVaultFailToConfigureSecretsEngine)
+		if err != nil {
+			return err
+		}
+
+		var csr vaultPKICSR
+
+		if err := json.Unmarshal(body, &csr); err != nil {
+			return err
+		}
+
+		sign := map[string]interface{}{
+			"csr":         csr.Data.CSR,
+			"common_name": c.CommonName,
+			"format":      "pem_bundle",
+		}
+		if c.MaxLeaseTTL != "" {
+			sign["ttl"] = c.MaxLeaseTTL
+		}
+
+		body, err = vh.request(token, http.MethodPost, issuer.VaultPath+"/root/sign-intermediate", issuer.VaultNamespace, sign, helper.ErrVaultFailToConfigureSecretsEngine)
+		if err != nil {
+			return err
+		}
+
+		var signed vaultPKISignedIntermediate
+
+		if err := json.Unmarshal(body, &signed); err != nil {
+			return err
+		}
+
+		// Chain of issuer is imported along with certificate, so that issued certificates carry complete chain
+		chain := signed.Data.CAChain
+		if len(chain) == 0 {
+			chain = []string{signed.Data.IssuingCA}
+		}
+
+		intermediate := map[string]interface{}{
+			"certificate": strings.Join(append([]string{strings.TrimSpace(signed.Data.Certificate)}, chain...), "\n"),
+		}
+
+		if _, err := vh.request(token, http.MethodPost, c.VaultPath+"/intermediate/set-signed", c.VaultNamespace, intermediate, helper.ErrVaultFailToConfigureSecretsEngine); err != nil {
+			return err
+		}
+		c.Certificate = strings.TrimSpace(signed.Data.Certificate)
+	}
+
+	issuingURL, crlURL, ocspURL := pkiDistributionURLs(vh.c, vh.vaultAddress, c)
+
+	urls := map[string]interface{}{
+		"issuing_certificates":    []string{issuingURL},
+		"crl_distribution_points": []string{crlURL},
+		"ocsp_servers":            []string{ocspURL},
+	}
+
+	if _, err := vh.request(token, http.MethodPost, c.VaultPath+"/config/urls", c.VaultNamespace, urls, helper.ErrVaultFailToConfigureSecretsEngine); err != nil {
+		return err
+	}
+
+	return vh.configurePKISecretsEngine(token, c, ctx)
+}
+
+// UpdatePKISecretsEngine configures role of secrets engine in place. CA is kept, so certificates issued before
+// update remain trusted until they expire.
+func (vh *VaultHandler) UpdatePKISecretsEngine(c *data.PKIConnection, ctx context.Context) error {
+
+	tr := otel.Tracer(vh.c.Server.PrefixMain)
+	ctx, span := tr.Start(ctx, utilities.GetFunctionName())
+	defer span.End()
+
+	token, err := vh.GetToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = vh.tuneSecretsEngine(token, c.VaultPath, c.VaultNamespace, c.DefaultLeaseTTL, c.MaxLeaseTTL, ctx)
+	if err != nil {
+		return err
+	}
+
+	return vh.configurePKISecretsEngine(token, c, ctx)
+}
+
+func (vh *VaultHandler) RemovePKISecretsEngine(c *data.PKIConnection, ctx context.Context) error {
+
+	tr := otel.Tracer(vh.c.Server.PrefixMain)
+	ctx, span := tr.Start(ctx, utilities.GetFunctionName())
+	defer span.End()
+
+	token, err := vh.GetToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	return vh.disableSecretsEngine(token, c.VaultPath, c.VaultNamespace, ctx)
+}
+
+// configurePKISecretsEngine writes default role of connection.
+func (vh *VaultHandler) configurePKISecretsEngine(token string, c *data.PKIConnection, ctx context.Context) error {
+
+	tr := otel.Tracer(vh.c.Server.PrefixMain)
+	_, span := tr.Start(ctx, utilities.GetFunctionName())
+	defer span.End()
+
+	return vh.writePKIRole(token, c.VaultPath, c.VaultNamespace, c.DefaultRole())
+}
+
+// writePKIRole writes role issuing certificates for allowed domains. Certificates are usable for both server and
+// client authentication, as both ends of mTLS hold one.
+func (vh *VaultHandler) writePKIRole(token string, path string, namespace string, r *data.PKIRole) error {
+	role := map[string]interface{}{
+		"allowed_domains":    r.AllowedDomains,
+		"allow_subdomains":   r.AllowSubdomains,
+		"allow_bare_domains": r.AllowBareDomains,
+		"server_flag":        true,
+		"client_flag":        true,
+	}
+	if r.KeyType != "" {
+		role["key_type"] = r.KeyType
+	}
+
+	_, err := vh.request(token, http.MethodPost, path+"/roles/"+r.RoleName, namespace, role, helper.ErrVaultFailToConfigureSecretsEngine)
+
+	return err
+}
+
+// readPKIRole reads attributes of role from Vault into r.
+func (vh *VaultHandler) readPKIRole(token string, path string, namespace string, r *data.PKIRole) error {
+	body, err := vh.request(token, http.MethodGet, path+"/roles/"+r.RoleName, namespace, nil, helper.ErrVaultFailToReadSecretsEngine)
+	if err != nil {
+		return err
+	}
+
+	var role vaultPKIRole
+
+	if err := json.Unmarshal(body, &role); err != nil {
+		return err
+	}
+
+	r.KeyType = role.Data.KeyType
+	r.AllowedDomains = role.Data.AllowedDomains
+	r.AllowSubdomains = role.Data.AllowSubdomains
+	r.AllowBareDomains = role.Data.AllowBareDomains
+
+	return nil
+}
+
+func (vh *VaultHandler) GetPKISecretsEngineRole(path string, namespace string, r *data.PKIRole, ctx context.Context) error {
+
+	tr := otel.Tracer(vh.c.Server.PrefixMain)
+	ctx, span := tr.Start(ctx, utilities.GetFunctionName())
+	defer span.End()
+
+	token, err := vh.GetToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	return vh.readPKIRole(token, path, namespace, r)
+}
+
+func (vh *VaultHandler) AddPKISecretsEngineRole(path string, namespace string, r *data.PKIRole, ctx context.Context) error {
+
+	tr := otel.Tracer(vh.c.Server.PrefixMain)
+	ctx, span := tr.Start(ctx, utilities.GetFunctionName())
+	defer span.End()
+
+	token, err := vh.GetToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	return vh.writePKIRole(token, path, namespace, r)
+}
+
+func (vh *VaultHandler) UpdatePKISecretsEngineRole(path string, namespace string, r *data.PKIRole, ctx context.Context) error {
+
+	tr := otel.Tracer(vh.c.Server.PrefixMain)
+	ctx, span := tr.Start(ctx, utilities.GetFunctionName())
+	defer span.End()
+
+	token, err := vh.GetToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	return vh.writePKIRole(token, path, namespace, r)
+}
+
+// RemovePKISecretsEngineRole deletes role. Certificates issued through role stay valid until they expire or are
+// revoked, as they are signed by CA of secrets engine.
+func (vh *VaultHandler) RemovePKISecretsEngineRole(path string, namespace string, roleName string, ctx context.Context) error {
+
+	tr := otel.Tracer(vh.c.Server.PrefixMain)
+	ctx, span := tr.Start(ctx, utilities.GetFunctionName())
+	defer span.End()
+
+	token, err := vh.GetToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = vh.request(token, http.MethodDelete, path+"/roles/"+roleName, namespace, nil, helper.ErrVaultFailToConfigureSecretsEngine)
+
+	return err
+}
+
+// TestPKISecretsEngine issues throwaway certificate for common name through role, verifies it is signed by CA of
+// secrets engine and revokes it. Revoked test certificates are listed in CRL until they expire.
+func (vh *VaultHandler) TestPKISecretsEngine(path string, namespace string, role string, commonName string, ctx context.Context) error {
+
+	tr := otel.Tracer(vh.c.Server.PrefixMain)
+	ctx, span := tr.Start(ctx, utilities.GetFunctionName())
+	defer span.End()
+
+	caCertificate, err := vh.GetPKICACertificate(path, namespace, ctx)
+	if err != nil {
+		return err
+	}
+
+	return testPKIIssuance(func(r *data.IssuePKIConnectionRequest) (*data.IssuePKIConnectionResponse, error) {
+		return vh.IssuePKISecretsEngine(path, namespace, role, r, ctx)
+	}, func(serialNumber string) error {
+		return vh.RevokePKICertificate(path, namespace, serialNumber, ctx)
+	}, caCertificate, commonName)
+}
+
+func (vh *VaultHandler) IssuePKISecretsEngine(path string, namespace string, role string, r *data.IssuePKIConnectionRequest, ctx context.Context) (*data.IssuePKIConnectionResponse, error) {
+
+	tr := otel.Tracer(vh.c.Server.PrefixMain)
+	ctx, span := tr.Start(ctx, utilities.GetFunctionName())
+	defer span.End()
+
+	token, err := vh.GetToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	payload := map[string]interface{}{
+		"common_name": r.CommonName,
+	}
+	if len(r.AltNames) > 0 {
+		payload["alt_names"] = strings.Join(r.AltNames, ",")
+	}
+	if len(r.IPSANs) > 0 {
+		payload["ip_sans"] = strings.Join(r.IPSANs, ",")
+	}
+	if r.TTL != "" {
+		payload["ttl"] = r.TTL
+	}
+
+	body, err := vh.request(token, http.MethodPost, fmt.Sprintf("%s/issue/%s", path, role), namespace, payload, helper.ErrVaultFailToIssuePKICertificate)
+	if err != nil {
+		return nil, err
+	}
+
+	var cert vaultPKICertificate
+
+	if err := json.Unmarshal(body, &cert); err != nil {
+		return nil, err
+	}
+
+	var response data.IssuePKIConnectionResponse
+
+	response.RoleName = role
+	response.SerialNumber = cert.Data.SerialNumber
+	response.Certificate = cert.Data.Certificate
+	response.IssuingCA = cert.Data.IssuingCA
+	response.CAChain = cert.Data.CAChain
+	response.PrivateKey = cert.Data.PrivateKey
+	response.PrivateKeyType = cert.Data.PrivateKeyType
+	response.ExpiresAt = time.Unix(cert.Data.Expiration, 0).UTC()
+
+	return &response, nil
+}
+
+// RevokePKICertificate revokes certificate with serial number, which lists it in CRL and OCSP responses of CA.
+func (vh *VaultHandler) RevokePKICertificate(path string, namespace string, serialNumber string, ctx context.Context) error {
+
+	tr := otel.Tracer(vh.c.Server.PrefixMain)
+	ctx, span := tr.Start(ctx, utilities.GetFunctionName())
+	defer span.End()
+
+	token, err := vh.GetToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	payload := map[string]interface{}{
+		"serial_number": serialNumber,
+	}
+
+	_, err = vh.request(token, http.MethodPost, path+"/revoke", namespace, payload, helper.ErrVaultFailToIssuePKICertificate)
+
+	return err
+}
+
+// GetPKICACertificate returns certificate of CA of secrets engine in DER format.
+func (vh *VaultHandler) GetPKICACertificate(path string, namespace string, ctx context.Context) ([]byte, error) {
+
+	tr := otel.Tracer(vh.c.Server.PrefixMain)
+	ctx, span := tr.Start(ctx, utilities.GetFunctionName())
+	defer span.End()
+
+	token, err := vh.GetToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return vh.request(token, http.MethodGet, path+"/ca", namespace, nil, helper.ErrVaultFailToReadSecretsEngine)
+}
+
+// GetPKICRL returns CRL of CA of secrets engine in DER format.
+func (vh *VaultHandler) GetPKICRL(path string, namespace string, ctx context.Context) ([]byte, error) {
+
+	tr := otel.Tracer(vh.c.Server.PrefixMain)
+	ctx, span := tr.Start(ctx, utilities.GetFunctionName())
+	defer span.End()
+
+	token, err := vh.GetToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return vh.request(token, http.MethodGet, path+"/crl", namespace, nil, helper.ErrVaultFailToReadSecretsEngine)
+}
+
+// GetPKIOCSPResponse passes DER encoded OCSP request to responder of secrets engine and returns its DER encoded response.
+func (vh *VaultHandler) GetPKIOCSPResponse(path string, namespace string, request []byte, ctx context.Context) ([]byte, error) {
+
+	tr := otel.Tracer(vh.c.Server.PrefixMain)
+	ctx, span := tr.Start(ctx, utilities.GetFunctionName())
+	defer span.End()
+
+	token, err := vh.GetToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return vh.requestRaw(token, http.MethodPost, path+"/ocsp", namespace, "application/ocsp-request", request, helper.ErrVaultFailToReadSecretsEngine)
+}
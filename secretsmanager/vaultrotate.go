@@ -0,0 +1,70 @@
+package secretsmanager
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"DemoServer_ConnectionManager/helper"
+	"DemoServer_ConnectionManager/utilities"
+
+	"go.opentelemetry.io/otel"
+)
+
+type vaultRotateRootResponse struct {
+	Data struct {
+		AccessKey string `json:"access_key"`
+	} `json:"data"`
+}
+
+// RotateAWSRootCredentials replaces root credentials of AWS secrets engine at path through config/rotate-root.
+// Vault creates new access key for IAM user of root credentials and deletes the old one, so root credentials
+// are only known to Vault afterwards. New access key id is returned.
+func (vh *VaultHandler) RotateAWSRootCredentials(path string, namespace string, ctx context.Context) (string, error) {
+
+	tr := otel.Tracer(vh.c.Server.PrefixMain)
+	ctx, span := tr.Start(ctx, utilities.GetFunctionName())
+	defer span.End()
+
+	token, err := vh.GetToken(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	url := fmt.Sprintf("%s/v1/%s/config/rotate-root", vh.vaultAddress, path)
+
+	req, err := http.NewRequest("POST", url, bytes.NewBufferString("{}"))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", token)
+	req.Header.Set("Content-Type", "application/json")
+	vh.setNamespace(req, namespace)
+
+	resp, err := vh.do(req)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%w: %s", helper.ErrVaultFailToRotateAWSRootCredentials, string(body))
+	}
+
+	var rotated vaultRotateRootResponse
+
+	err = json.Unmarshal(body, &rotated)
+	if err != nil {
+		return "", err
+	}
+
+	return rotated.Data.AccessKey, nil
+}
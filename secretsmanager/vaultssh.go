@@ -0,0 +1,369 @@
+package secretsmanager
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"DemoServer_ConnectionManager/data"
+	"DemoServer_ConnectionManager/helper"
+	"DemoServer_ConnectionManager/utilities"
+
+	"go.opentelemetry.io/otel"
+	"golang.org/x/crypto/ssh"
+)
+
+var strEngineSSH = EngineSSH
+
+type vaultSSHCA struct {
+	Data struct {
+		PublicKey string `json:"public_key"`
+	} `json:"data"`
+}
+
+type vaultSSHRole struct {
+	Data struct {
+		AllowedUsers      string            `json:"allowed_users"`
+		DefaultUser       string            `json:"default_user"`
+		AllowedDomains    string            `json:"allowed_domains"`
+		AllowSubdomains   bool              `json:"allow_subdomains"`
+		DefaultExtensions map[string]string `json:"default_extensions"`
+	} `json:"data"`
+}
+
+type vaultSSHSignature struct {
+	Data struct {
+		SerialNumber string `json:"serial_number"`
+		SignedKey    string `json:"signed_key"`
+	} `json:"data"`
+}
+
+// splitSSHList splits comma separated list Vault returns principals of role in.
+func splitSSHList(list string) []string {
+	var result []string
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// sshExtensionsToVault converts extensions of connection to map of extension to value Vault expects. Extensions
+// OpenSSH knows take no value.
+func sshExtensionsToVault(extensions []string) map[string]string {
+	result := map[string]string{}
+	for _, e := range extensions {
+		result[e] = ""
+	}
+	return result
+}
+
+// sshExtensionsFromVault converts extensions returned by Vault to extensions of connection ordered by name.
+func sshExtensionsFromVault(extensions map[string]string) []string {
+	var result []string
+	for e := range extensions {
+		result = append(result, e)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// sshSignResponse builds response of signing from certificate in OpenSSH format. Validity window and principals are
+// taken from certificate itself rather than from request, as Vault may adjust both.
+func sshSignResponse(signedKey string, serialNumber string, role string) (*data.SignSSHConnectionResponse, error) {
+	key, _, _, _, err := ssh.ParseAuthorizedKey([]byte(signedKey))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", helper.ErrVaultFailToSignSSHKey, err.Error())
+	}
+
+	cert, ok := key.(*ssh.Certificate)
+	if !ok {
+		return nil, fmt.Errorf("%w: signed key is not a certificate", helper.ErrVaultFailToSignSSHKey)
+	}
+
+	if serialNumber == "" {
+		serialNumber = fmt.Sprintf("%016x", cert.Serial)
+	}
+
+	var response data.SignSSHConnectionResponse
+
+	response.RoleName = role
+	response.SerialNumber = serialNumber
+	response.SignedKey = strings.TrimSpace(signedKey)
+	response.ValidPrincipals = cert.ValidPrincipals
+	response.ValidAfter = time.Unix(int64(cert.ValidAfter), 0).UTC()
+	response.ValidBefore = time.Unix(int64(cert.ValidBefore), 0).UTC()
+
+	return &response, nil
+}
+
+// testSSHSigning signs freshly generated public key for principal and verifies certificate is signed by CA of
+// secrets engine. Generated private key is discarded, so certificate is of no use to anyone.
+func testSSHSigning(sign func(r *data.SignSSHConnectionRequest) (*data.SignSSHConnectionResponse, error), caPublicKey string, principal string) error {
+	public, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return err
+	}
+
+	sshPublic, err := ssh.NewPublicKey(public)
+	if err != nil {
+		return err
+	}
+
+	response, err := sign(&data.SignSSHConnectionRequest{
+		PublicKey:       string(ssh.MarshalAuthorizedKey(sshPublic)),
+		ValidPrincipals: []string{principal},
+	})
+	if err != nil {
+		return err
+	}
+
+	ca, _, _, _, err := ssh.ParseAuthorizedKey([]byte(caPublicKey))
+	if err != nil {
+		return fmt.Errorf("%w: public key of CA is invalid: %s", helper.ErrVaultFailToSignSSHKey, err.Error())
+	}
+
+	key, _, _, _, err := ssh.ParseAuthorizedKey([]byte(response.SignedKey))
+	if err != nil {
+		return fmt.Errorf("%w: %s", helper.ErrVaultFailToSignSSHKey, err.Error())
+	}
+
+	cert, ok := key.(*ssh.Certificate)
+	if !ok || string(cert.SignatureKey.Marshal()) != string(ca.Marshal()) {
+		return fmt.Errorf("%w: certificate is not signed by CA of secrets engine", helper.ErrVaultFailToSignSSHKey)
+	}
+
+	return nil
+}
+
+func (vh *VaultHandler) GetSSHSecretsEngine(c *data.SSHConnection, ctx context.Context) error {
+
+	tr := otel.Tracer(vh.c.Server.PrefixMain)
+	ctx, span := tr.Start(ctx, utilities.GetFunctionName())
+	defer span.End()
+
+	token, err := vh.GetToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	c.PublicKey, err = vh.getSSHCAPublicKey(token, c.VaultPath, c.VaultNamespace, ctx)
+	if err != nil {
+		return err
+	}
+
+	body, err := vh.request(token, http.MethodGet, c.VaultPath+"/roles/"+c.RoleName, c.VaultNamespace, nil, helper.ErrVaultFailToReadSecretsEngine)
+	if err != nil {
+		return err
+	}
+
+	var role vaultSSHRole
+
+	if err := json.Unmarshal(body, &role); err != nil {
+		return err
+	}
+
+	c.DefaultLeaseTTL, c.MaxLeaseTTL, err = vh.readSecretsEngineTune(token, c.VaultPath, c.VaultNamespace, ctx)
+	if err != nil {
+		return err
+	}
+
+	c.AllowedUsers = splitSSHList(role.Data.AllowedUsers)
+	c.DefaultUser = role.Data.DefaultUser
+	c.AllowedDomains = splitSSHList(role.Data.AllowedDomains)
+	c.AllowSubdomains = role.Data.AllowSubdomains
+	c.DefaultExtensions = sshExtensionsFromVault(role.Data.DefaultExtensions)
+
+	return nil
+}
+
+func (vh *VaultHandler) AddSSHSecretsEngine(c *data.SSHConnection, ctx context.Context) error {
+
+	tr := otel.Tracer(vh.c.Server.PrefixMain)
+	ctx, span := tr.Start(ctx, utilities.GetFunctionName())
+	defer span.End()
+
+	token, err := vh.GetToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = vh.enableSecretsEngine(token, c.VaultPath, c.VaultNamespace, strEngineSSH, c.DefaultLeaseTTL, c.MaxLeaseTTL, ctx)
+	if err != nil {
+		return err
+	}
+
+	// Vault generates key pair of CA unless one is provided
+	ca := map[string]interface{}{
+		"generate_signing_key": c.PrivateKey == "",
+	}
+	if c.PrivateKey != "" {
+		ca["private_key"] = c.PrivateKey
+		ca["public_key"] = c.PublicKey
+	}
+
+	body, err := vh.request(token, http.MethodPost, c.VaultPath+"/config/ca", c.VaultNamespace, ca, helper.ErrVaultFailToConfigureSecretsEngine)
+	if err != nil {
+		return err
+	}
+
+	var config vaultSSHCA
+
+	if err := json.Unmarshal(body, &config); err == nil && config.Data.PublicKey != "" {
+		c.PublicKey = config.Data.PublicKey
+	}
+
+	return vh.configureSSHSecretsEngine(token, c, ctx)
+}
+
+// UpdateSSHSecretsEngine configures role of secrets engine in place. Key pair of CA is kept, so certificates signed
+// before update remain trusted until they expire.
+func (vh *VaultHandler) UpdateSSHSecretsEngine(c *data.SSHConnection, ctx context.Context) error {
+
+	tr := otel.Tracer(vh.c.Server.PrefixMain)
+	ctx, span := tr.Start(ctx, utilities.GetFunctionName())
+	defer span.End()
+
+	token, err := vh.GetToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = vh.tuneSecretsEngine(token, c.VaultPath, c.VaultNamespace, c.DefaultLeaseTTL, c.MaxLeaseTTL, ctx)
+	if err != nil {
+		return err
+	}
+
+	return vh.configureSSHSecretsEngine(token, c, ctx)
+}
+
+func (vh *VaultHandler) RemoveSSHSecretsEngine(c *data.SSHConnection, ctx context.Context) error {
+
+	tr := otel.Tracer(vh.c.Server.PrefixMain)
+	ctx, span := tr.Start(ctx, utilities.GetFunctionName())
+	defer span.End()
+
+	token, err := vh.GetToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	return vh.disableSecretsEngine(token, c.VaultPath, c.VaultNamespace, ctx)
+}
+
+// configureSSHSecretsEngine writes role of connection signing certificates of its type with key of CA.
+func (vh *VaultHandler) configureSSHSecretsEngine(token string, c *data.SSHConnection, ctx context.Context) error {
+
+	tr := otel.Tracer(vh.c.Server.PrefixMain)
+	_, span := tr.Start(ctx, utilities.GetFunctionName())
+	defer span.End()
+
+	role := map[string]interface{}{
+		"key_type":                "ca",
+		"allow_user_certificates": c.CertType == data.SSHCertTypeUser,
+		"allow_host_certificates": c.CertType == data.SSHCertTypeHost,
+		"allowed_users":           strings.Join(c.AllowedUsers, ","),
+		"default_user":            c.DefaultUser,
+		"allowed_domains":         strings.Join(c.AllowedDomains, ","),
+		"allow_subdomains":        c.AllowSubdomains,
+		"default_extensions":      sshExtensionsToVault(c.DefaultExtensions),
+	}
+
+	_, err := vh.request(token, http.MethodPost, c.VaultPath+"/roles/"+c.RoleName, c.VaultNamespace, role, helper.ErrVaultFailToConfigureSecretsEngine)
+
+	return err
+}
+
+func (vh *VaultHandler) getSSHCAPublicKey(token string, path string, namespace string, ctx context.Context) (string, error) {
+
+	tr := otel.Tracer(vh.c.Server.PrefixMain)
+	_, span := tr.Start(ctx, utilities.GetFunctionName())
+	defer span.End()
+
+	body, err := vh.request(token, http.MethodGet, path+"/config/ca", namespace, nil, helper.ErrVaultFailToReadSecretsEngine)
+	if err != nil {
+		return "", err
+	}
+
+	var config vaultSSHCA
+
+	if err := json.Unmarshal(body, &config); err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(config.Data.PublicKey), nil
+}
+
+// GetSSHCAPublicKey returns public key of CA of secrets engine in OpenSSH authorized_keys format.
+func (vh *VaultHandler) GetSSHCAPublicKey(path string, namespace string, ctx context.Context) (string, error) {
+
+	tr := otel.Tracer(vh.c.Server.PrefixMain)
+	ctx, span := tr.Start(ctx, utilities.GetFunctionName())
+	defer span.End()
+
+	token, err := vh.GetToken(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return vh.getSSHCAPublicKey(token, path, namespace, ctx)
+}
+
+// TestSSHSecretsEngine signs throwaway public key for principal through role and verifies certificate is signed
+// by CA of secrets engine. Certificates are not leased, so there is nothing to revoke.
+func (vh *VaultHandler) TestSSHSecretsEngine(path string, namespace string, role string, certType string, principal string, ctx context.Context) error {
+
+	tr := otel.Tracer(vh.c.Server.PrefixMain)
+	ctx, span := tr.Start(ctx, utilities.GetFunctionName())
+	defer span.End()
+
+	caPublicKey, err := vh.GetSSHCAPublicKey(path, namespace, ctx)
+	if err != nil {
+		return err
+	}
+
+	return testSSHSigning(func(r *data.SignSSHConnectionRequest) (*data.SignSSHConnectionResponse, error) {
+		return vh.SignSSHSecretsEngine(path, namespace, role, certType, r, ctx)
+	}, caPublicKey, principal)
+}
+
+func (vh *VaultHandler) SignSSHSecretsEngine(path string, namespace string, role string, certType string, r *data.SignSSHConnectionRequest, ctx context.Context) (*data.SignSSHConnectionResponse, error) {
+
+	tr := otel.Tracer(vh.c.Server.PrefixMain)
+	ctx, span := tr.Start(ctx, utilities.GetFunctionName())
+	defer span.End()
+
+	token, err := vh.GetToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	payload := map[string]interface{}{
+		"public_key":       r.PublicKey,
+		"valid_principals": strings.Join(r.ValidPrincipals, ","),
+		"cert_type":        certType,
+	}
+	if r.TTL != "" {
+		payload["ttl"] = r.TTL
+	}
+
+	body, err := vh.request(token, http.MethodPost, fmt.Sprintf("%s/sign/%s", path, role), namespace, payload, helper.ErrVaultFailToSignSSHKey)
+	if err != nil {
+		return nil, err
+	}
+
+	var signature vaultSSHSignature
+
+	if err := json.Unmarshal(body, &signature); err != nil {
+		return nil, err
+	}
+
+	return sshSignResponse(signature.Data.SignedKey, signature.Data.SerialNumber, role)
+}
@@ -0,0 +1,307 @@
+package secretsmanager
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"DemoServer_ConnectionManager/helper"
+	"DemoServer_ConnectionManager/utilities"
+
+	"go.opentelemetry.io/otel"
+)
+
+var (
+	strAuthAppRole    = "approle"
+	strAuthKubernetes = "kubernetes"
+	strAuthJWT        = "jwt"
+
+	// defaultJWTPath is where Kubernetes mounts token of pod's service account
+	defaultJWTPath = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+)
+
+// tokenExpiryMargin is how long before expiry a cached token is no longer handed out.
+const tokenExpiryMargin = 10 * time.Second
+
+// vaultToken caches client token obtained through login with configured auth method. It is shared by
+// concurrent handler goroutines and guarded by mu.
+type vaultToken struct {
+	mu        sync.Mutex
+	token     string
+	ttl       time.Duration
+	expiresAt time.Time
+	renewable bool
+}
+
+type vaultAuthResponse struct {
+	Auth struct {
+		ClientToken   string `json:"client_token"`
+		LeaseDuration int    `json:"lease_duration"`
+		Renewable     bool   `json:"renewable"`
+	} `json:"auth"`
+}
+
+// GetToken returns cached Vault token. Token is renewed once less than a third of its ttl remains and
+// login is done again if there is no token yet, it can not be renewed or it is about to expire.
+func (vh *VaultHandler) GetToken(ctx context.Context) (string, error) {
+
+	tr := otel.Tracer(vh.c.Server.PrefixMain)
+	ctx, span := tr.Start(ctx, utilities.GetFunctionName())
+	defer span.End()
+
+	vh.tk.mu.Lock()
+	defer vh.tk.mu.Unlock()
+
+	if vh.tk.token != "" {
+		// Tokens without ttl do not expire
+		if vh.tk.ttl == 0 {
+			return vh.tk.token, nil
+		}
+
+		remaining := time.Until(vh.tk.expiresAt)
+
+		if remaining > vh.tk.ttl/3 {
+			return vh.tk.token, nil
+		}
+
+		if remaining > tokenExpiryMargin && vh.tk.renewable {
+			err := vh.renewToken(ctx)
+			if err == nil {
+				return vh.tk.token, nil
+			}
+
+			helper.LogError(vh.l, helper.ErrorVaultAuthenticationFailed, err, span)
+		}
+	}
+
+	if err := vh.login(ctx); err != nil {
+		return "", err
+	}
+
+	return vh.tk.token, nil
+}
+
+// invalidateToken discards cached token if it is still token. Caller must not hold tk.mu.
+func (vh *VaultHandler) invalidateToken(token string) {
+	vh.tk.mu.Lock()
+	defer vh.tk.mu.Unlock()
+
+	if vh.tk.token == token {
+		vh.tk.token = ""
+	}
+}
+
+// loginPayload returns mount path of configured auth method and payload for its login endpoint. JWT is read
+// from file on every login as service account tokens projected by Kubernetes are rotated.
+func (vh *VaultHandler) loginPayload() (string, map[string]string, error) {
+	authMethod := strings.ToLower(vh.c.Vault.AuthMethod.Type)
+	if authMethod == "" {
+		authMethod = strAuthAppRole
+	}
+
+	mount := vh.c.Vault.AuthMethod.Mount
+	if mount == "" {
+		mount = authMethod
+	}
+
+	switch authMethod {
+	case strAuthAppRole:
+		return mount, map[string]string{
+			"role_id":   vh.c.Vault.RoleID,
+			"secret_id": vh.c.Vault.SecretID,
+		}, nil
+	case strAuthKubernetes, strAuthJWT:
+		jwtPath := vh.c.Vault.AuthMethod.JWTPath
+		if jwtPath == "" {
+			jwtPath = defaultJWTPath
+		}
+
+		jwt, err := os.ReadFile(jwtPath)
+		if err != nil {
+			return "", nil, fmt.Errorf("%w: %s", helper.ErrVaultAuthenticationFailed, err.Error())
+		}
+
+		return mount, map[string]string{
+			"role": vh.c.Vault.AuthMethod.Role,
+			"jwt":  strings.TrimSpace(string(jwt)),
+		}, nil
+	default:
+		return "", nil, fmt.Errorf("%w: %s", helper.ErrVaultUnsupportedAuthMethod, vh.c.Vault.AuthMethod.Type)
+	}
+}
+
+// login authenticates with Vault through configured auth method and caches returned token. Caller must hold tk.mu.
+func (vh *VaultHandler) login(ctx context.Context) error {
+
+	tr := otel.Tracer(vh.c.Server.PrefixMain)
+	_, span := tr.Start(ctx, utilities.GetFunctionName())
+	defer span.End()
+
+	vh.tk.token = ""
+
+	// Create the authentication payload
+	mount, authData, err := vh.loginPayload()
+	if err != nil {
+		return err
+	}
+	authDataJSON, err := json.Marshal(authData)
+	if err != nil {
+		return err
+	}
+
+	// Construct the authentication request
+	url := fmt.Sprintf("%s/v1/auth/%s/login", vh.vaultAddress, mount)
+	req, err := http.NewRequest("POST", url, bytes.NewBuffer(authDataJSON))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	vh.setNamespace(req, "")
+
+	auth, err := vh.authenticate(req)
+	if err != nil {
+		return err
+	}
+
+	vh.tk.token = auth.Auth.ClientToken
+	vh.tk.ttl = time.Duration(auth.Auth.LeaseDuration) * time.Second
+	vh.tk.expiresAt = time.Now().Add(vh.tk.ttl)
+	vh.tk.renewable = auth.Auth.Renewable
+
+	return nil
+}
+
+// renewToken extends ttl of cached token through renew-self. Token close to its max ttl can not be
+// extended meaningfully, in which case error is returned so that caller logs in again. Caller must hold tk.mu.
+func (vh *VaultHandler) renewToken(ctx context.Context) error {
+
+	tr := otel.Tracer(vh.c.Server.PrefixMain)
+	_, span := tr.Start(ctx, utilities.GetFunctionName())
+	defer span.End()
+
+	url := fmt.Sprintf("%s/v1/%s", vh.vaultAddress, "auth/token/renew-self")
+	req, err := http.NewRequest("POST", url, bytes.NewBufferString("{}"))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-Vault-Token", vh.tk.token)
+	req.Header.Set("Content-Type", "application/json")
+	vh.setNamespace(req, "")
+
+	auth, err := vh.authenticate(req)
+	if err != nil {
+		return err
+	}
+
+	ttl := time.Duration(auth.Auth.LeaseDuration) * time.Second
+	if ttl <= vh.tk.ttl/3 {
+		return fmt.Errorf("token renewed for %s only. max ttl reached", ttl)
+	}
+
+	vh.tk.expiresAt = time.Now().Add(ttl)
+	vh.tk.renewable = auth.Auth.Renewable
+
+	return nil
+}
+
+// authenticate executes login or renewal request and parses auth section of response.
+func (vh *VaultHandler) authenticate(req *http.Request) (*vaultAuthResponse, error) {
+
+	// Execute the HTTP request
+	resp, err := vh.hc.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	// Check for HTTP errors
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("%w: %s", helper.ErrVaultAuthenticationFailed, string(body))
+	}
+
+	// Parse the response
+	var auth vaultAuthResponse
+
+	err = json.NewDecoder(resp.Body).Decode(&auth)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse response: %v", err)
+	}
+
+	return &auth, nil
+}
+
+// do executes request authenticated with cached token. Vault answers 403 once token has been revoked or
+// has expired, in which case token is discarded and request is retried once with token of a fresh login.
+// Vault answers 403 as well when its policies deny request, so login is only done again if Vault no longer
+// accepts token.
+func (vh *VaultHandler) do(req *http.Request) (*http.Response, error) {
+	resp, err := vh.hc.Do(req)
+	if err != nil || resp.StatusCode != http.StatusForbidden {
+		return resp, err
+	}
+
+	// Request body can not be sent again
+	if req.Body != nil && req.GetBody == nil {
+		return resp, nil
+	}
+
+	// Body is kept for caller in case request is not retried
+	body, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	token := req.Header.Get("X-Vault-Token")
+
+	if !strings.Contains(string(body), "permission denied") || vh.tokenValid(req.Context(), token) {
+		return resp, nil
+	}
+
+	vh.invalidateToken(token)
+
+	token, err = vh.GetToken(req.Context())
+	if err != nil {
+		return nil, err
+	}
+
+	retry := req.Clone(req.Context())
+	if req.GetBody != nil {
+		retry.Body, err = req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+	}
+	retry.Header.Set("X-Vault-Token", token)
+
+	return vh.hc.Do(retry)
+}
+
+// tokenValid tells whether Vault still accepts token, i.e. whether token can look itself up.
+func (vh *VaultHandler) tokenValid(ctx context.Context, token string) bool {
+	url := fmt.Sprintf("%s/v1/%s", vh.vaultAddress, "auth/token/lookup-self")
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return false
+	}
+	req.Header.Set("X-Vault-Token", token)
+	vh.setNamespace(req, "")
+
+	resp, err := vh.hc.Do(req)
+	if err != nil {
+		return false
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	return resp.StatusCode == http.StatusOK
+}
@@ -0,0 +1,228 @@
+package secretsmanager
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"DemoServer_ConnectionManager/configuration"
+	"DemoServer_ConnectionManager/helper"
+
+	"github.com/stretchr/testify/require"
+)
+
+type fakeVault struct {
+	logins   atomic.Int32
+	renewals atomic.Int32
+	ttl      int
+	valid    sync.Map
+}
+
+// permissionDenied is body of Vault responses to requests with invalid token as well as to requests denied by
+// policies.
+const permissionDenied = `{"errors":["permission denied"]}`
+
+func (f *fakeVault) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/v1/auth/approle/login":
+		n := f.logins.Add(1)
+		token := fmt.Sprintf("token-%d", n)
+		f.valid.Store(token, true)
+		_, _ = fmt.Fprintf(w, `{"auth":{"client_token":"%s","lease_duration":%d,"renewable":true}}`, token, f.ttl)
+	case "/v1/auth/token/renew-self":
+		f.renewals.Add(1)
+		_, _ = fmt.Fprintf(w, `{"auth":{"client_token":"%s","lease_duration":%d,"renewable":true}}`, r.Header.Get("X-Vault-Token"), f.ttl)
+	default:
+		if _, ok := f.valid.Load(r.Header.Get("X-Vault-Token")); !ok {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = fmt.Fprint(w, permissionDenied)
+			return
+		}
+
+		// Policies of token do not grant access to sys/policies
+		if strings.HasPrefix(r.URL.Path, "/v1/sys/policies") {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = fmt.Fprint(w, permissionDenied)
+			return
+		}
+
+		_, _ = fmt.Fprint(w, `{"data":{}}`)
+	}
+}
+
+func newTestVaultHandler(url string) *VaultHandler {
+	var cfg configuration.Config
+
+	return &VaultHandler{c: &cfg, l: slog.New(slog.NewTextHandler(os.Stderr, nil)), hc: http.DefaultClient, vaultAddress: url}
+}
+
+func TestVaultToken_CachedAcrossConcurrentCalls(t *testing.T) {
+	f := &fakeVault{ttl: 3600}
+	srv := httptest.NewServer(f)
+	defer srv.Close()
+
+	vh := newTestVaultHandler(srv.URL)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			token, err := vh.GetToken(context.Background())
+			require.NoError(t, err)
+			require.Equal(t, "token-1", token)
+		}()
+	}
+	wg.Wait()
+
+	require.Equal(t, int32(1), f.logins.Load())
+}
+
+func TestVaultToken_RenewedBeforeExpiry(t *testing.T) {
+	f := &fakeVault{ttl: 60}
+	srv := httptest.NewServer(f)
+	defer srv.Close()
+
+	vh := newTestVaultHandler(srv.URL)
+
+	_, err := vh.GetToken(context.Background())
+	require.NoError(t, err)
+
+	// Simulate passage of time so that less than a third of ttl remains
+	vh.tk.expiresAt = vh.tk.expiresAt.Add(-vh.tk.ttl + tokenExpiryMargin + time.Second)
+
+	token, err := vh.GetToken(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "token-1", token)
+	require.Equal(t, int32(1), f.logins.Load())
+	require.Equal(t, int32(1), f.renewals.Load())
+}
+
+func TestVaultToken_LoginAgainOnForbidden(t *testing.T) {
+	f := &fakeVault{ttl: 3600}
+	srv := httptest.NewServer(f)
+	defer srv.Close()
+
+	vh := newTestVaultHandler(srv.URL)
+
+	token, err := vh.GetToken(context.Background())
+	require.NoError(t, err)
+
+	// Token revoked on Vault side
+	f.valid.Delete(token)
+
+	req, err := http.NewRequest("GET", srv.URL+"/v1/sys/mounts", nil)
+	require.NoError(t, err)
+	req.Header.Set("X-Vault-Token", token)
+
+	resp, err := vh.do(req)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, int32(2), f.logins.Load())
+}
+
+func TestVaultToken_NoLoginOnPolicyDenied(t *testing.T) {
+	f := &fakeVault{ttl: 3600}
+	srv := httptest.NewServer(f)
+	defer srv.Close()
+
+	vh := newTestVaultHandler(srv.URL)
+
+	token, err := vh.GetToken(context.Background())
+	require.NoError(t, err)
+
+	req, err := http.NewRequest("GET", srv.URL+"/v1/sys/policies/acl", nil)
+	require.NoError(t, err)
+	req.Header.Set("X-Vault-Token", token)
+
+	resp, err := vh.do(req)
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+
+	// Denial is passed on to caller and token is kept
+	require.Equal(t, http.StatusForbidden, resp.StatusCode)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.JSONEq(t, permissionDenied, string(body))
+
+	require.Equal(t, int32(1), f.logins.Load())
+
+	cached, err := vh.GetToken(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, token, cached)
+}
+
+func TestVaultToken_LoginPayload(t *testing.T) {
+	vh := newTestVaultHandler("")
+	vh.c.Vault.RoleID = "roleid"
+	vh.c.Vault.SecretID = "secretid"
+
+	mount, payload, err := vh.loginPayload()
+	require.NoError(t, err)
+	require.Equal(t, "approle", mount)
+	require.Equal(t, map[string]string{"role_id": "roleid", "secret_id": "secretid"}, payload)
+
+	jwtPath := t.TempDir() + "/token"
+	require.NoError(t, os.WriteFile(jwtPath, []byte("eyJhbGciOi.payload.signature\n"), 0600))
+
+	vh.c.Vault.AuthMethod.Type = "kubernetes"
+	vh.c.Vault.AuthMethod.Role = "connectionmanager"
+	vh.c.Vault.AuthMethod.JWTPath = jwtPath
+
+	mount, payload, err = vh.loginPayload()
+	require.NoError(t, err)
+	require.Equal(t, "kubernetes", mount)
+	require.Equal(t, map[string]string{"role": "connectionmanager", "jwt": "eyJhbGciOi.payload.signature"}, payload)
+
+	vh.c.Vault.AuthMethod.Type = "jwt"
+	vh.c.Vault.AuthMethod.Mount = "oidc-ci"
+
+	mount, _, err = vh.loginPayload()
+	require.NoError(t, err)
+	require.Equal(t, "oidc-ci", mount)
+
+	vh.c.Vault.AuthMethod.Type = "userpass"
+
+	_, _, err = vh.loginPayload()
+	require.ErrorIs(t, err, helper.ErrVaultUnsupportedAuthMethod)
+}
+
+func TestVaultToken_NamespaceHeader(t *testing.T) {
+	namespaces := map[string]string{}
+	var mu sync.Mutex
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		namespaces[r.URL.Path] = r.Header.Get("X-Vault-Namespace")
+		mu.Unlock()
+
+		switch r.URL.Path {
+		case "/v1/auth/approle/login":
+			_, _ = fmt.Fprint(w, `{"auth":{"client_token":"token","lease_duration":3600,"renewable":true}}`)
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer srv.Close()
+
+	vh := newTestVaultHandler(srv.URL)
+	vh.c.Vault.Namespace = "bu1"
+
+	require.NoError(t, vh.RemoveAWSSecretsEngineRole("demoserver/aws_1", "bu1/team", "readonly", context.Background()))
+	require.NoError(t, vh.RemoveAWSSecretsEngineRole("demoserver/aws_2", "", "readonly", context.Background()))
+
+	require.Equal(t, "bu1", namespaces["/v1/auth/approle/login"])
+	require.Equal(t, "bu1/team", namespaces["/v1/demoserver/aws_1/roles/readonly"])
+	require.Equal(t, "bu1", namespaces["/v1/demoserver/aws_2/roles/readonly"])
+}
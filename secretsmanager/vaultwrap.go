@@ -0,0 +1,90 @@
+package secretsmanager
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"DemoServer_ConnectionManager/data"
+	"DemoServer_ConnectionManager/helper"
+	"DemoServer_ConnectionManager/utilities"
+
+	"go.opentelemetry.io/otel"
+)
+
+type vaultWrapResponse struct {
+	WrapInfo struct {
+		Token        string    `json:"token"`
+		Accessor     string    `json:"accessor"`
+		TTL          int       `json:"ttl"`
+		CreationTime time.Time `json:"creation_time"`
+	} `json:"wrap_info"`
+}
+
+// WrapResponse wraps payload in a single-use token valid for ttl through sys/wrapping/wrap. Token is created
+// in namespace configured for service.
+func (vh *VaultHandler) WrapResponse(payload interface{}, ttl string, ctx context.Context) (*data.WrapInfo, error) {
+
+	tr := otel.Tracer(vh.c.Server.PrefixMain)
+	ctx, span := tr.Start(ctx, utilities.GetFunctionName())
+	defer span.End()
+
+	token, err := vh.GetToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	payloadJSON, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	url := fmt.Sprintf("%s/v1/sys/wrapping/wrap", vh.vaultAddress)
+
+	req, err := http.NewRequest("POST", url, bytes.NewBuffer(payloadJSON))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Vault-Token", token)
+	req.Header.Set("X-Vault-Wrap-TTL", ttl)
+	req.Header.Set("Content-Type", "application/json")
+	vh.setNamespace(req, "")
+
+	resp, err := vh.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: %s", helper.ErrVaultFailToWrapResponse, string(body))
+	}
+
+	var wrapped vaultWrapResponse
+
+	err = json.Unmarshal(body, &wrapped)
+	if err != nil {
+		return nil, err
+	}
+
+	if wrapped.WrapInfo.Token == "" {
+		return nil, fmt.Errorf("%w: no wrapping token returned", helper.ErrVaultFailToWrapResponse)
+	}
+
+	return &data.WrapInfo{
+		Token:        wrapped.WrapInfo.Token,
+		Accessor:     wrapped.WrapInfo.Accessor,
+		TTL:          wrapped.WrapInfo.TTL,
+		CreationTime: wrapped.WrapInfo.CreationTime,
+		Namespace:    vh.c.Vault.Namespace,
+	}, nil
+}
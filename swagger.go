@@ -18,5 +18,14 @@
 //	Produces:
 //	- application/json
 //
+//	SecurityDefinitions:
+//	bearer:
+//	  type: apiKey
+//	  name: Authorization
+//	  in: header
+//
+//	Security:
+//	- bearer: []
+//
 // swagger:meta
 package main
@@ -15,6 +15,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/go-playground/validator"
 	"github.com/gorilla/mux"
@@ -172,6 +173,11 @@ func CopyMatchingFields(src, tgt interface{}) error {
 				}
 			}
 		} else {
+			// nil slices and maps in src are treated as absent so that they do not wipe tgt
+			if (srcField.Kind() == reflect.Slice || srcField.Kind() == reflect.Map) && srcField.IsNil() {
+				continue
+			}
+
 			// Both src and tgt are non-pointers
 			if tgtFieldVal.Kind() == srcField.Kind() {
 				tgtFieldVal.Set(srcField)
@@ -251,12 +257,37 @@ func ParseQueryParam(vars url.Values, key string, defaultValue, maxValue int) in
 	return defaultValue
 }
 
+// WriteResponse writes data as json with fields tagged for redaction masked.
 func WriteResponse(w http.ResponseWriter, cl *slog.Logger, data interface{}, span trace.Span) {
+	WriteResponseWithReveal(w, cl, data, false, span)
+}
+
+// WriteResponseWithReveal writes data as json. Fields tagged for redaction are masked unless reveal is set,
+// which caller has to authorize. Slices and pointers in data are shared with caller and masked in place.
+func WriteResponseWithReveal(w http.ResponseWriter, cl *slog.Logger, data interface{}, reveal bool, span trace.Span) {
+	if !reveal {
+		data = redacted(data)
+	}
+
 	if err := json.NewEncoder(w).Encode(data); err != nil {
 		helper.LogError(cl, helper.ErrorJSONEncodingFailed, err, span)
 	}
 }
 
+// redacted returns copy of data with fields tagged for redaction masked.
+func redacted(data interface{}) interface{} {
+	v := reflect.ValueOf(data)
+	if !v.IsValid() {
+		return data
+	}
+
+	c := reflect.New(v.Type())
+	c.Elem().Set(v)
+	helper.Redact(c.Interface())
+
+	return c.Elem().Interface()
+}
+
 func ValidateQueryParam(
 	param string,
 	minValue int,
@@ -285,6 +316,42 @@ func ValidateQueryParam(
 	return nil
 }
 
+// ValidateDurationParam validates optional duration parameter. Value is accepted either as number of seconds
+// or in duration format understood by Vault i.e. 900s, 15m, 1h.
+func ValidateDurationParam(
+	param string,
+	cl *slog.Logger,
+	r *http.Request,
+	rw http.ResponseWriter,
+	span trace.Span,
+	requestid string,
+	helperError helper.ErrorTypeEnum) error {
+	if param == "" {
+		return nil
+	}
+
+	if value, err := strconv.Atoi(param); err == nil {
+		if value <= 0 {
+			helper.ReturnError(cl, http.StatusBadRequest, helperError, fmt.Errorf("no internal error"), requestid, r, &rw, span)
+			return fmt.Errorf("invalid value for parameter")
+		}
+		return nil
+	}
+
+	value, err := time.ParseDuration(param)
+	if err != nil {
+		helper.ReturnError(cl, http.StatusBadRequest, helperError, err, requestid, r, &rw, span)
+		return err
+	}
+
+	if value <= 0 {
+		helper.ReturnError(cl, http.StatusBadRequest, helperError, fmt.Errorf("no internal error"), requestid, r, &rw, span)
+		return fmt.Errorf("invalid value for parameter")
+	}
+
+	return nil
+}
+
 func UpdateObject[T any](db *gorm.DB, obj *T, ctx context.Context, tracerName string) error {
 
 	tr := otel.Tracer(tracerName)